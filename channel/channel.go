@@ -17,6 +17,10 @@ type OnChainData struct {
 	Holdings  types.Funds
 	Outcome   outcome.Exit
 	StateHash common.Hash
+	// ChallengeBlock is the block at which the latest challenge of the channel was registered, or zero if it has not been challenged or the challenge was cleared
+	ChallengeBlock uint64 `json:",omitempty"`
+	// FinalizesAt is when the latest challenge finalizes the channel, in the units of chainservice.ChallengeRegisteredEvent.FinalizesAt
+	FinalizesAt uint64 `json:",omitempty"`
 }
 
 type OffChainData struct {
@@ -47,6 +51,32 @@ func (c *Channel) isNewChainEvent(event chainservice.Event) bool {
 		(event.BlockNum() == c.LastChainUpdate.BlockNum && event.TxIndex() > c.LastChainUpdate.TxIndex)
 }
 
+// ComputeChannelId returns the id of the channel with the given fixed part, computed in the same way as the adjudicator's NitroUtils.getChannelId.
+// It lets a channel's id be predicted before the channel is opened.
+// The chain id is not part of a channel's id, so a channel has the same id on every chain.
+func ComputeChannelId(participants []types.Address, nonce uint64, appDefinition types.Address, challengeDuration uint32) types.Destination {
+	return state.FixedPart{
+		Participants:      participants,
+		ChannelNonce:      nonce,
+		AppDefinition:     appDefinition,
+		ChallengeDuration: challengeDuration,
+	}.ChannelId()
+}
+
+// ErrNotParticipant is returned when an address is not among a channel's participants
+var ErrNotParticipant = errors.New("address is not a participant")
+
+// RoleFor returns the index of me in participants, and whether me is Alice, the first participant.
+// When a virtual channel is funded, Alice is the only participant who is never the Right of a guarantee.
+func RoleFor(me types.Address, participants []types.Address) (index int, isAlice bool, err error) {
+	for i, p := range participants {
+		if p == me {
+			return i, i == 0, nil
+		}
+	}
+	return 0, false, fmt.Errorf("%s: %w", me, ErrNotParticipant)
+}
+
 // New constructs a new Channel from the supplied state.
 func New(s state.State, myIndex uint) (*Channel, error) {
 	c := Channel{}
@@ -227,6 +257,14 @@ func (c Channel) LatestSupportedState() (state.State, error) {
 	return c.OffChain.SignedStateForTurnNum[c.OffChain.LatestSupportedStateTurnNum].State(), nil
 }
 
+// LatestSupportedSignedState returns the latest supported state, with the signatures of all participants.
+func (c Channel) LatestSupportedSignedState() (state.SignedState, error) {
+	if c.OffChain.LatestSupportedStateTurnNum == MaxTurnNum {
+		return state.SignedState{}, errors.New(`no state is yet supported`)
+	}
+	return c.OffChain.SignedStateForTurnNum[c.OffChain.LatestSupportedStateTurnNum], nil
+}
+
 // LatestSignedState fetches the state with the largest turn number signed by at least one participant.
 func (c Channel) LatestSignedState() (state.SignedState, error) {
 	if len(c.OffChain.SignedStateForTurnNum) == 0 {
@@ -353,11 +391,16 @@ func (c *Channel) UpdateWithChainEvent(event chainservice.Event) (*Channel, erro
 		}
 		c.OnChain.StateHash = h
 		c.OnChain.Outcome = e.Outcome()
+		c.OnChain.ChallengeBlock = e.BlockNum()
+		c.OnChain.FinalizesAt = e.FinalizesAt()
 		ss, err := e.SignedState(c.FixedPart)
 		if err != nil {
 			return nil, err
 		}
 		c.AddSignedState(ss)
+	case chainservice.ChallengeClearedEvent:
+		c.OnChain.ChallengeBlock = 0
+		c.OnChain.FinalizesAt = 0
 	default:
 		return &Channel{}, fmt.Errorf("channel %+v cannot handle event %+v", c, event)
 	}
@@ -287,7 +287,7 @@ func TestChannel(t *testing.T) {
 		}
 	}
 	testUpdateWithChallengeRegisteredEvent := func(t *testing.T) {
-		event := chainservice.NewChallengeRegisteredEvent(c.ChannelId(), 99999, 0, state.TestState.VariablePart(), []state.Signature{sigA, sigB})
+		event := chainservice.NewChallengeRegisteredEvent(c.ChannelId(), 99999, 0, state.TestState.VariablePart(), []state.Signature{sigA, sigB}, 100059)
 
 		_, err := c.UpdateWithChainEvent(event)
 		if err != nil {
@@ -310,16 +310,30 @@ func TestChannel(t *testing.T) {
 		if diff := cmp.Diff(want2, got2); diff != "" {
 			t.Fatalf("mismatch (-want +got):\n%s", diff)
 		}
+
+		if c.OnChain.ChallengeBlock != 99999 || c.OnChain.FinalizesAt != 100059 {
+			t.Fatalf("expected the challenge at block 99999 to finalize at 100059, got block %d finalizing at %d", c.OnChain.ChallengeBlock, c.OnChain.FinalizesAt)
+		}
 	}
 
 	testUpdateWithChainEventRejected := func(t *testing.T) {
-		event := chainservice.NewChallengeRegisteredEvent(c.ChannelId(), 99999, 0, state.TestState.VariablePart(), []state.Signature{sigA, sigB})
+		event := chainservice.NewChallengeRegisteredEvent(c.ChannelId(), 99999, 0, state.TestState.VariablePart(), []state.Signature{sigA, sigB}, 100059)
 		_, err := c.UpdateWithChainEvent(event)
 		if err == nil {
 			t.Fatal("chain event should be rejected when blockNum/txIndex is not higher than last update")
 		}
 	}
 
+	testUpdateWithChallengeClearedEvent := func(t *testing.T) {
+		_, err := c.UpdateWithChainEvent(chainservice.NewChallengeClearedEvent(c.ChannelId(), 100000, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.OnChain.ChallengeBlock != 0 || c.OnChain.FinalizesAt != 0 {
+			t.Fatalf("expected the cleared challenge to be forgotten, got block %d finalizing at %d", c.OnChain.ChallengeBlock, c.OnChain.FinalizesAt)
+		}
+	}
+
 	t.Run(`TestNewChannel`, testNewChannel)
 	t.Run(`TestClone`, testClone)
 	t.Run(`TestPreFund`, testPreFund)
@@ -335,6 +349,7 @@ func TestChannel(t *testing.T) {
 	t.Run(`TestAddSignedState`, testAddSignedState)
 	t.Run(`TestUpdateWithChallengeRegisteredEvent`, testUpdateWithChallengeRegisteredEvent)
 	t.Run(`TestUpdateWithChainEventRejected`, testUpdateWithChainEventRejected)
+	t.Run(`TestUpdateWithChallengeClearedEvent`, testUpdateWithChallengeClearedEvent)
 }
 
 func TestVirtualChannel(t *testing.T) {
@@ -420,3 +435,81 @@ func TestSerde(t *testing.T) {
 		t.Fatalf("incorrect json unmarshaling (-want +got):\n%s", diff)
 	}
 }
+
+func TestComputeChannelId(t *testing.T) {
+	testCases := []struct {
+		name              string
+		participants      []types.Address
+		nonce             uint64
+		appDefinition     types.Address
+		challengeDuration uint32
+		want              types.Destination
+	}{
+		{
+			// Generated from our ts nitro-protocol package
+			name: "test state",
+			participants: []types.Address{
+				common.HexToAddress(`0xF5A1BB5607C9D079E46d1B3Dc33f257d937b43BD`),
+				common.HexToAddress(`0x760bf27cd45036a6C486802D30B5D90CfFBE31FE`),
+			},
+			nonce:             37140676580,
+			appDefinition:     common.HexToAddress(`0x5e29E5Ab8EF33F050c7cc10B5a0456D975C5F88d`),
+			challengeDuration: 60,
+			want:              types.Destination(common.HexToHash(`3f9dfeabcc41d1618dd0711102018ab6c1a1d7c25111a425401c2e524eb073a2`)),
+		},
+		{
+			name:              "test state fixed part",
+			participants:      state.TestState.Participants,
+			nonce:             state.TestState.ChannelNonce,
+			appDefinition:     state.TestState.AppDefinition,
+			challengeDuration: state.TestState.ChallengeDuration,
+			want:              state.TestState.ChannelId(),
+		},
+	}
+	for _, tc := range testCases {
+		got := ComputeChannelId(tc.participants, tc.nonce, tc.appDefinition, tc.challengeDuration)
+		if got != tc.want {
+			t.Errorf("%s: expected channel id %s, got %s", tc.name, tc.want, got)
+		}
+	}
+
+	// Any part of the fixed part changes the id
+	base := ComputeChannelId(state.TestState.Participants, 1, state.TestState.AppDefinition, 60)
+	if ComputeChannelId(state.TestState.Participants, 2, state.TestState.AppDefinition, 60) == base {
+		t.Error("expected the nonce to change the channel id")
+	}
+	if ComputeChannelId(state.TestState.Participants, 1, state.TestState.AppDefinition, 61) == base {
+		t.Error("expected the challenge duration to change the channel id")
+	}
+	if ComputeChannelId(state.TestState.Participants, 1, types.Address{}, 60) == base {
+		t.Error("expected the app definition to change the channel id")
+	}
+	reversed := []types.Address{state.TestState.Participants[1], state.TestState.Participants[0]}
+	if ComputeChannelId(reversed, 1, state.TestState.AppDefinition, 60) == base {
+		t.Error("expected the order of participants to change the channel id")
+	}
+}
+
+func TestRoleFor(t *testing.T) {
+	alice := common.HexToAddress("0xAAA6628Ec44A8a742987EF3A114dDFE2D4F7aDCE")
+	irene := common.HexToAddress("0x111A00868581f73AB42FEEF67D235Ca09ca1E8db")
+	bob := common.HexToAddress("0xBBB676f9cFF8D242e9eaC39D063848807d3D1D94")
+	participants := []types.Address{alice, irene, bob}
+
+	for wantIndex, p := range participants {
+		index, isAlice, err := RoleFor(p, participants)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index != wantIndex {
+			t.Errorf("expected %s to have index %d, got %d", p, wantIndex, index)
+		}
+		if isAlice != (p == alice) {
+			t.Errorf("expected only Alice to have Alice's role, but %s has isAlice %t", p, isAlice)
+		}
+	}
+
+	if _, _, err := RoleFor(common.HexToAddress("0x1"), participants); !errors.Is(err, ErrNotParticipant) {
+		t.Errorf("expected %v for a non participant, got %v", ErrNotParticipant, err)
+	}
+}
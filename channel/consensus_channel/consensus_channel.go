@@ -39,6 +39,9 @@ type ConsensusChannel struct {
 	Id             types.Destination
 	MyIndex        ledgerIndex
 	OnChainFunding types.Funds
+	// ChallengeBlock and FinalizesAt record the latest challenge of the channel on chain, if any. See channel.OnChainData.
+	ChallengeBlock uint64
+	FinalizesAt    uint64
 	fp             state.FixedPart
 
 	// variables
@@ -893,6 +896,7 @@ func (c *ConsensusChannel) Clone() *ConsensusChannel {
 	d := ConsensusChannel{
 		MyIndex: c.MyIndex, fp: c.fp.Clone(),
 		Id: c.Id, OnChainFunding: c.OnChainFunding.Clone(), current: c.current.clone(), proposalQueue: clonedProposalQueue,
+		ChallengeBlock: c.ChallengeBlock, FinalizesAt: c.FinalizesAt,
 	}
 	return &d
 }
@@ -207,6 +207,8 @@ func (l *LedgerOutcome) UnmarshalJSON(data []byte) error {
 type jsonConsensusChannel struct {
 	Id             types.Destination
 	OnChainFunding types.Funds
+	ChallengeBlock uint64 `json:",omitempty"`
+	FinalizesAt    uint64 `json:",omitempty"`
 	MyIndex        ledgerIndex
 	FP             state.FixedPart
 	Current        SignedVars
@@ -220,6 +222,8 @@ func (c ConsensusChannel) MarshalJSON() ([]byte, error) {
 		FP:             c.fp,
 		Id:             c.Id,
 		OnChainFunding: c.OnChainFunding,
+		ChallengeBlock: c.ChallengeBlock,
+		FinalizesAt:    c.FinalizesAt,
 		Current:        c.current,
 		ProposalQueue:  c.proposalQueue,
 	}
@@ -237,6 +241,8 @@ func (c *ConsensusChannel) UnmarshalJSON(data []byte) error {
 
 	c.Id = jsonCh.Id
 	c.OnChainFunding = jsonCh.OnChainFunding
+	c.ChallengeBlock = jsonCh.ChallengeBlock
+	c.FinalizesAt = jsonCh.FinalizesAt
 	c.MyIndex = jsonCh.MyIndex
 	c.fp = jsonCh.FP
 	c.current = jsonCh.Current
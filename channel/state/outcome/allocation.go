@@ -3,7 +3,9 @@ package outcome
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"math/big"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/statechannels/go-nitro/types"
@@ -30,6 +32,35 @@ func (a Allocation) Equal(b Allocation) bool {
 	return a.Destination == b.Destination && a.AllocationType == b.AllocationType && a.Amount.Cmp(b.Amount) == 0 && bytes.Equal(a.Metadata, b.Metadata)
 }
 
+// Validate returns an error if the allocation has no amount, or a negative amount.
+func (a Allocation) Validate() error {
+	if a.Amount == nil {
+		return fmt.Errorf("%w: to %s", ErrMissingAmount, a.Destination)
+	}
+	if a.Amount.Sign() < 0 {
+		return fmt.Errorf("%w: %s to %s", ErrNegativeAllocation, a.Amount, a.Destination)
+	}
+	return nil
+}
+
+// diff describes each way in which b differs from a, prefixing each description with the given location
+func (a Allocation) diff(b Allocation, at string) []string {
+	var diffs []string
+	if a.Destination != b.Destination {
+		diffs = append(diffs, fmt.Sprintf("%s: destination %s != %s", at, a.Destination, b.Destination))
+	}
+	if a.Amount.Cmp(b.Amount) != 0 {
+		diffs = append(diffs, fmt.Sprintf("%s: amount %s != %s", at, a.Amount, b.Amount))
+	}
+	if a.AllocationType != b.AllocationType {
+		diffs = append(diffs, fmt.Sprintf("%s: allocation type %d != %d", at, a.AllocationType, b.AllocationType))
+	}
+	if !bytes.Equal(a.Metadata, b.Metadata) {
+		diffs = append(diffs, fmt.Sprintf("%s: metadata %#x != %#x", at, a.Metadata, b.Metadata))
+	}
+	return diffs
+}
+
 // Clone returns a deep copy of the receiver.
 func (a Allocation) Clone() Allocation {
 	return Allocation{
@@ -74,6 +105,23 @@ func (a Allocations) Total() *big.Int {
 	return total
 }
 
+// Merge returns a copy of the receiver in which allocations of the same type, to the same destination and with the same metadata, are combined.
+// The merged allocation takes the position of the first allocation it combines.
+func (a Allocations) Merge() Allocations {
+	merged := make(Allocations, 0, len(a))
+	for _, allocation := range a {
+		i := slices.IndexFunc(merged, func(m Allocation) bool {
+			return m.Destination == allocation.Destination && m.AllocationType == allocation.AllocationType && bytes.Equal(m.Metadata, allocation.Metadata)
+		})
+		if i == -1 {
+			merged = append(merged, allocation.Clone())
+			continue
+		}
+		merged[i].Amount.Add(merged[i].Amount, allocation.Amount)
+	}
+	return merged
+}
+
 // TotalFor returns the total amount allocated to the given dest (regardless of AllocationType)
 func (a Allocations) TotalFor(dest types.Destination) *big.Int {
 	total := big.NewInt(0)
@@ -1,6 +1,7 @@
 package outcome
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 
@@ -99,3 +100,53 @@ func TestAllocationsClone(t *testing.T) {
 		t.Fatalf("Clone: mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestMergeAllocations(t *testing.T) {
+	alice := types.Destination(common.HexToHash("0x0a"))
+	bob := types.Destination(common.HexToHash("0x0b"))
+
+	a := Allocations{
+		{Destination: alice, Amount: big.NewInt(1)},
+		{Destination: bob, Amount: big.NewInt(2)},
+		{Destination: alice, Amount: big.NewInt(3)},
+		{Destination: alice, Amount: big.NewInt(4), AllocationType: GuaranteeAllocationType, Metadata: []byte{1}},
+		{Destination: alice, Amount: big.NewInt(5), AllocationType: GuaranteeAllocationType, Metadata: []byte{1}},
+		{Destination: alice, Amount: big.NewInt(6), AllocationType: GuaranteeAllocationType, Metadata: []byte{2}},
+	}
+	want := Allocations{
+		{Destination: alice, Amount: big.NewInt(4)},
+		{Destination: bob, Amount: big.NewInt(2)},
+		{Destination: alice, Amount: big.NewInt(9), AllocationType: GuaranteeAllocationType, Metadata: []byte{1}},
+		{Destination: alice, Amount: big.NewInt(6), AllocationType: GuaranteeAllocationType, Metadata: []byte{2}},
+	}
+
+	got := a.Merge()
+	if !got.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got.Total().Cmp(a.Total()) != 0 {
+		t.Errorf("expected merging to preserve the total of %s, got %s", a.Total(), got.Total())
+	}
+	if a[0].Amount.Cmp(big.NewInt(1)) != 0 {
+		t.Error("expected merging not to modify the receiver")
+	}
+}
+
+func TestAllocationValidate(t *testing.T) {
+	alice := types.Destination(common.HexToHash("0x0a"))
+	testCases := []struct {
+		name   string
+		amount *big.Int
+		want   error
+	}{
+		{"positive", big.NewInt(2), nil},
+		{"zero", big.NewInt(0), nil},
+		{"negative", big.NewInt(-2), ErrNegativeAllocation},
+		{"missing", nil, ErrMissingAmount},
+	}
+	for _, tc := range testCases {
+		if err := (Allocation{Destination: alice, Amount: tc.amount}).Validate(); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
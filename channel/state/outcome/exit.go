@@ -2,11 +2,16 @@ package outcome
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"slices"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -52,9 +57,143 @@ func (sae SingleAssetExit) TotalAllocatedFor(dest types.Destination) *big.Int {
 	return sae.Allocations.TotalFor(dest)
 }
 
+// Validate returns an error if any allocation is invalid, or if the allocations sum to more than the uint256 a channel can hold of the asset.
+func (sae SingleAssetExit) Validate() error {
+	for _, allocation := range sae.Allocations {
+		if err := allocation.Validate(); err != nil {
+			return fmt.Errorf("asset %s: %w", sae.Asset, err)
+		}
+	}
+	if total := sae.TotalAllocated(); total.Cmp(math.MaxBig256) > 0 {
+		return fmt.Errorf("%w: %s of asset %s", ErrAllocationOverflow, total, sae.Asset)
+	}
+	return nil
+}
+
 // Exit is an ordered list of SingleAssetExits
 type Exit []SingleAssetExit
 
+// MaxAllocations is the largest number of allocations, across all assets, that a decoded Exit may contain.
+// It bounds the work done on outcomes received from counterparties.
+const MaxAllocations = 1024
+
+var (
+	ErrTooManyAllocations = errors.New("exit has too many allocations")
+	ErrMalformedAsset     = errors.New("exit has a malformed asset address")
+)
+
+// Validate returns an error if any of the exit's SingleAssetExits is invalid.
+func (e Exit) Validate() error {
+	for _, sae := range e {
+		if err := sae.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateTotals returns an error unless the exit allocates exactly the expected total of each asset.
+func (e Exit) ValidateTotals(expected types.Funds) error {
+	if total := e.TotalAllocated(); !total.Equal(expected) {
+		return fmt.Errorf("%w: %s were expected but %s are allocated", ErrFundsNotConserved, expected, total)
+	}
+	return nil
+}
+
+// checkAllocationCount returns an error if the exit has more than MaxAllocations allocations
+func (e Exit) checkAllocationCount() error {
+	count := 0
+	for _, sae := range e {
+		count += len(sae.Allocations)
+	}
+	if count > MaxAllocations {
+		return fmt.Errorf("%w: %d exceeds the maximum of %d", ErrTooManyAllocations, count, MaxAllocations)
+	}
+	return nil
+}
+
+// checkAssetAddress returns an error if the hex encoded asset address is mixed case, but does not match its EIP-55 checksum.
+// An address in a single case carries no checksum, and is accepted.
+func checkAssetAddress(hex string) error {
+	digits := strings.TrimPrefix(hex, "0x")
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return nil
+	}
+	if checksummed := common.HexToAddress(hex).Hex(); checksummed != hex {
+		return fmt.Errorf("%w: %s does not match its checksum %s", ErrMalformedAsset, hex, checksummed)
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes an Exit, rejecting exits with more than MaxAllocations allocations, or with an asset address whose checksum is wrong.
+// The allocations are counted as they are decoded, so that no more than MaxAllocations of them are ever decoded.
+func (e *Exit) UnmarshalJSON(data []byte) error {
+	var decoded []struct {
+		Asset         checkedAsset
+		AssetMetadata AssetMetadata
+		Allocations   json.RawMessage
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded == nil {
+		*e = nil
+		return nil
+	}
+	exit := make(Exit, len(decoded))
+	remaining := MaxAllocations
+	for i, sae := range decoded {
+		allocations, err := decodeAllocations(sae.Allocations, remaining)
+		if err != nil {
+			return err
+		}
+		remaining -= len(allocations)
+		exit[i] = SingleAssetExit{Asset: types.Address(sae.Asset), AssetMetadata: sae.AssetMetadata, Allocations: allocations}
+	}
+	*e = exit
+	return nil
+}
+
+// decodeAllocations decodes a json array of allocations one at a time, and fails as soon as it finds more than max of them
+func decodeAllocations(data json.RawMessage, max int) (Allocations, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('[') {
+		return nil, fmt.Errorf("expected an array of allocations, found %v", t)
+	}
+	allocations := Allocations{}
+	for dec.More() {
+		if len(allocations) == max {
+			return nil, fmt.Errorf("%w: more than the maximum of %d", ErrTooManyAllocations, MaxAllocations)
+		}
+		var a Allocation
+		if err := dec.Decode(&a); err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, a)
+	}
+	return allocations, nil
+}
+
+// checkedAsset is an asset address whose checksum is verified as it is decoded
+type checkedAsset types.Address
+
+func (a *checkedAsset) UnmarshalJSON(data []byte) error {
+	if err := (*types.Address)(a).UnmarshalJSON(data); err != nil {
+		return err
+	}
+	// The address decoded without error, so it is a well formed hex string, which need only have its checksum verified
+	var hex string
+	if err := json.Unmarshal(data, &hex); err != nil {
+		return err
+	}
+	return checkAssetAddress(hex)
+}
+
 // Equal returns true if the supplied Exit is deeply equal to the receiver.
 func (a Exit) Equal(b Exit) bool {
 	if len(a) != len(b) {
@@ -68,6 +207,40 @@ func (a Exit) Equal(b Exit) bool {
 	return true
 }
 
+// Diff returns a description of each way in which the supplied Exit differs from the receiver, one per line.
+// It returns the empty string if the Exits are Equal.
+func (e Exit) Diff(other Exit) string {
+	var diffs []string
+	if len(e) != len(other) {
+		diffs = append(diffs, fmt.Sprintf("exit has %d assets, other has %d", len(e), len(other)))
+	}
+	for i := 0; i < len(e) && i < len(other); i++ {
+		diffs = append(diffs, e[i].diff(other[i], fmt.Sprintf("asset %d", i))...)
+	}
+	return strings.Join(diffs, "\n")
+}
+
+// diff describes each way in which r differs from s, prefixing each description with the given location
+func (s SingleAssetExit) diff(r SingleAssetExit, at string) []string {
+	var diffs []string
+	if s.Asset != r.Asset {
+		diffs = append(diffs, fmt.Sprintf("%s: asset %s != %s", at, s.Asset, r.Asset))
+	}
+	if s.AssetMetadata.AssetType != r.AssetMetadata.AssetType {
+		diffs = append(diffs, fmt.Sprintf("%s: asset type %d != %d", at, s.AssetMetadata.AssetType, r.AssetMetadata.AssetType))
+	}
+	if !bytes.Equal(s.AssetMetadata.Metadata, r.AssetMetadata.Metadata) {
+		diffs = append(diffs, fmt.Sprintf("%s: asset metadata %#x != %#x", at, s.AssetMetadata.Metadata, r.AssetMetadata.Metadata))
+	}
+	if len(s.Allocations) != len(r.Allocations) {
+		diffs = append(diffs, fmt.Sprintf("%s: %d allocations != %d", at, len(s.Allocations), len(r.Allocations)))
+	}
+	for j := 0; j < len(s.Allocations) && j < len(r.Allocations); j++ {
+		diffs = append(diffs, s.Allocations[j].diff(r.Allocations[j], fmt.Sprintf("%s, allocation %d", at, j))...)
+	}
+	return diffs
+}
+
 // Clone returns a deep clone of the receiver.
 func (e Exit) Clone() Exit {
 	clone := make(Exit, len(e))
@@ -77,6 +250,26 @@ func (e Exit) Clone() Exit {
 	return clone
 }
 
+// Merge returns a copy of the receiver in which SingleAssetExits for the same asset are combined, and their allocations merged with Allocations.Merge.
+// SingleAssetExits are only combined if their AssetMetadata is equal.
+func (e Exit) Merge() Exit {
+	merged := make(Exit, 0, len(e))
+	for _, sae := range e {
+		i := slices.IndexFunc(merged, func(m SingleAssetExit) bool {
+			return m.Asset == sae.Asset && m.AssetMetadata.AssetType == sae.AssetMetadata.AssetType && bytes.Equal(m.AssetMetadata.Metadata, sae.AssetMetadata.Metadata)
+		})
+		if i == -1 {
+			merged = append(merged, sae.Clone())
+			continue
+		}
+		merged[i].Allocations = append(merged[i].Allocations, sae.Allocations...)
+	}
+	for i := range merged {
+		merged[i].Allocations = merged[i].Allocations.Merge()
+	}
+	return merged
+}
+
 // TotalAllocated returns the sum of all Funds that are allocated by the outcome.
 //
 // NOTE that these Funds are potentially different from a channel's capacity to
@@ -85,7 +278,7 @@ func (e Exit) TotalAllocated() types.Funds {
 	fullValue := types.Funds{}
 
 	for _, assetExit := range e {
-		fullValue[assetExit.Asset] = assetExit.TotalAllocated()
+		fullValue = fullValue.Add(types.Funds{assetExit.Asset: assetExit.TotalAllocated()})
 	}
 
 	return fullValue
@@ -96,7 +289,7 @@ func (e Exit) TotalAllocatedFor(dest types.Destination) types.Funds {
 	total := types.Funds{}
 
 	for _, assetAllocation := range e {
-		total[assetAllocation.Asset] = assetAllocation.TotalAllocatedFor(dest)
+		total = total.Add(types.Funds{assetAllocation.Asset: assetAllocation.TotalAllocatedFor(dest)})
 	}
 
 	return total
@@ -174,13 +367,71 @@ func (e *Exit) Encode() (types.Bytes, error) {
 	return abi.Arguments{{Type: ExitTy}}.Pack(e)
 }
 
-// Decode returns an Exit from an abi encoding
+// Decode returns an Exit from an abi encoding, rejecting exits with more than MaxAllocations allocations.
+// The allocations are counted from the encoding's array lengths before it is unpacked.
 func Decode(data types.Bytes) (Exit, error) {
+	if count, ok := encodedAllocationCount(data); ok && count > MaxAllocations {
+		return nil, fmt.Errorf("%w: %d exceeds the maximum of %d", ErrTooManyAllocations, count, MaxAllocations)
+	}
 	unpacked, err := abi.Arguments{{Type: ExitTy}}.Unpack(data)
 	if err != nil {
 		return nil, err
 	}
-	return convertToExit(unpacked[0].(rawExitType)), nil
+	e := convertToExit(unpacked[0].(rawExitType))
+	if err := e.checkAllocationCount(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// encodedAllocationCount reads the number of allocations from the array lengths of an abi encoded Exit, without unpacking it.
+// It stops counting once the count exceeds MaxAllocations. It returns false if the lengths cannot be read, in which case unpacking fails too.
+func encodedAllocationCount(data []byte) (int, bool) {
+	// word returns the word at the offset, if it is in the data and small enough to be a length or an offset
+	word := func(offset uint64) (uint64, bool) {
+		if offset > uint64(len(data)) || uint64(len(data))-offset < 32 {
+			return 0, false
+		}
+		w := data[offset : offset+32]
+		for _, b := range w[:28] {
+			if b != 0 {
+				return 0, false
+			}
+		}
+		return uint64(w[28])<<24 | uint64(w[29])<<16 | uint64(w[30])<<8 | uint64(w[31]), true
+	}
+
+	// The encoding is the offset of the array of SingleAssetExits, whose length is followed by the offset of each of them.
+	// Each SingleAssetExit is its asset, the offset of its metadata and the offset of its array of allocations, relative to its start.
+	array, ok := word(0)
+	if !ok {
+		return 0, false
+	}
+	assets, ok := word(array)
+	if !ok {
+		return 0, false
+	}
+	head := array + 32
+	count := 0
+	for i := uint64(0); i < assets; i++ {
+		offset, ok := word(head + 32*i)
+		if !ok {
+			return 0, false
+		}
+		allocationsOffset, ok := word(head + offset + 64)
+		if !ok {
+			return 0, false
+		}
+		allocations, ok := word(head + offset + allocationsOffset)
+		if !ok {
+			return 0, false
+		}
+		count += int(allocations)
+		if count > MaxAllocations {
+			return count, true
+		}
+	}
+	return count, true
 }
 
 // Hash returns the keccak256 hash of the Exit
@@ -2,8 +2,11 @@ package outcome
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -414,3 +417,238 @@ func TestClone(t *testing.T) {
 		t.Fatalf("Clone: mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestERC20ExitEncodeDecode(t *testing.T) {
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	erc20Exit := Exit{
+		SingleAssetExit{
+			Asset: token,
+			Allocations: Allocations{{
+				Destination: types.Destination(common.HexToHash("0x0a")),
+				Amount:      big.NewInt(1_000_000),
+			}},
+		},
+		SingleAssetExit{
+			Asset: types.Address{},
+			Allocations: Allocations{{
+				Destination: types.Destination(common.HexToHash("0x0b")),
+				Amount:      big.NewInt(2),
+			}},
+		},
+	}
+
+	encodedExit, err := erc20Exit.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(encodedExit, common.LeftPadBytes(token.Bytes(), 32)) {
+		t.Fatalf("expected encoding %x to contain the asset address %s", encodedExit, token)
+	}
+
+	decodedExit, err := Decode(encodedExit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !erc20Exit.Equal(decodedExit) {
+		t.Fatalf("decoded exit does not match expectation: %+v", decodedExit)
+	}
+	if decodedExit[0].Asset != token {
+		t.Fatalf("expected asset %s, got %s", token, decodedExit[0].Asset)
+	}
+}
+
+func TestExitDiff(t *testing.T) {
+	alice := types.Destination(common.HexToHash("0x0a"))
+	bob := types.Destination(common.HexToHash("0x0b"))
+	exit := func(allocations ...Allocation) Exit {
+		return Exit{{Asset: common.HexToAddress("0x00"), AssetMetadata: nullMetadata, Allocations: allocations}}
+	}
+
+	testCases := []struct {
+		name     string
+		a, b     Exit
+		wantDiff string
+	}{
+		{
+			"equal",
+			exit(Allocation{Destination: alice, Amount: big.NewInt(2)}, Allocation{Destination: bob, Amount: big.NewInt(3)}),
+			exit(Allocation{Destination: alice, Amount: big.NewInt(2)}, Allocation{Destination: bob, Amount: big.NewInt(3), Metadata: make(types.Bytes, 0)}),
+			"",
+		},
+		{
+			// Allocations are paid out in order, so reordering them changes the outcome
+			"reordered allocations",
+			exit(Allocation{Destination: alice, Amount: big.NewInt(2)}, Allocation{Destination: bob, Amount: big.NewInt(2)}),
+			exit(Allocation{Destination: bob, Amount: big.NewInt(2)}, Allocation{Destination: alice, Amount: big.NewInt(2)}),
+			fmt.Sprintf("asset 0, allocation 0: destination %s != %s\nasset 0, allocation 1: destination %s != %s", alice, bob, bob, alice),
+		},
+		{
+			"differing amount",
+			exit(Allocation{Destination: alice, Amount: big.NewInt(2)}),
+			exit(Allocation{Destination: alice, Amount: big.NewInt(5)}),
+			"asset 0, allocation 0: amount 2 != 5",
+		},
+		{
+			"differing allocation metadata",
+			exit(Allocation{Destination: alice, Amount: big.NewInt(2), Metadata: []byte{1}}),
+			exit(Allocation{Destination: alice, Amount: big.NewInt(2), Metadata: []byte{2}}),
+			"asset 0, allocation 0: metadata 0x01 != 0x02",
+		},
+		{
+			"differing number of assets",
+			exit(Allocation{Destination: alice, Amount: big.NewInt(2)}),
+			Exit{},
+			"exit has 1 assets, other has 0",
+		},
+	}
+	for _, tc := range testCases {
+		if got := tc.a.Diff(tc.b); got != tc.wantDiff {
+			t.Errorf("%s: expected diff %q, got %q", tc.name, tc.wantDiff, got)
+		}
+		if got, want := tc.a.Equal(tc.b), tc.wantDiff == ""; got != want {
+			t.Errorf("%s: expected Equal to return %t, got %t", tc.name, want, got)
+		}
+	}
+}
+
+func TestExitMerge(t *testing.T) {
+	alice := types.Destination(common.HexToHash("0x0a"))
+	bob := types.Destination(common.HexToHash("0x0b"))
+	eth := common.HexToAddress("0x00")
+	token := common.HexToAddress("0x01")
+
+	e := Exit{
+		{Asset: eth, Allocations: Allocations{{Destination: alice, Amount: big.NewInt(1)}, {Destination: bob, Amount: big.NewInt(2)}}},
+		{Asset: token, Allocations: Allocations{{Destination: alice, Amount: big.NewInt(3)}}},
+		{Asset: eth, Allocations: Allocations{{Destination: bob, Amount: big.NewInt(4)}, {Destination: alice, Amount: big.NewInt(5)}}},
+		{Asset: token, AssetMetadata: AssetMetadata{AssetType: 1}, Allocations: Allocations{{Destination: alice, Amount: big.NewInt(6)}}},
+	}
+	want := Exit{
+		{Asset: eth, Allocations: Allocations{{Destination: alice, Amount: big.NewInt(6)}, {Destination: bob, Amount: big.NewInt(6)}}},
+		{Asset: token, Allocations: Allocations{{Destination: alice, Amount: big.NewInt(3)}}},
+		{Asset: token, AssetMetadata: AssetMetadata{AssetType: 1}, Allocations: Allocations{{Destination: alice, Amount: big.NewInt(6)}}},
+	}
+
+	got := e.Merge()
+	if diff := want.Diff(got); diff != "" {
+		t.Fatalf("unexpected merged exit:\n%s", diff)
+	}
+	if !got.TotalAllocated().Equal(e.TotalAllocated()) {
+		t.Errorf("expected merging to preserve the total allocated of %v, got %v", e.TotalAllocated(), got.TotalAllocated())
+	}
+	if e[0].Allocations[0].Amount.Cmp(big.NewInt(1)) != 0 || len(e[0].Allocations) != 2 {
+		t.Error("expected merging not to modify the receiver")
+	}
+}
+
+func TestDecodeRejectsTooManyAllocations(t *testing.T) {
+	exitWith := func(n int) Exit {
+		allocations := make(Allocations, n)
+		for i := range allocations {
+			allocations[i] = Allocation{Destination: types.Destination{1}, Amount: big.NewInt(1), Metadata: make(types.Bytes, 0)}
+		}
+		return Exit{{Asset: types.Address{}, AssetMetadata: nullMetadata, Allocations: allocations}}
+	}
+
+	for _, tc := range []struct {
+		allocations int
+		wantErr     error
+	}{
+		{MaxAllocations, nil},
+		{MaxAllocations + 1, ErrTooManyAllocations},
+	} {
+		e := exitWith(tc.allocations)
+
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fromJson Exit
+		if err := json.Unmarshal(data, &fromJson); !errors.Is(err, tc.wantErr) {
+			t.Errorf("json decoding %d allocations: expected error %v, got %v", tc.allocations, tc.wantErr, err)
+		}
+
+		encoded, err := e.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The allocations are counted from the encoding, before it is unpacked
+		if count, ok := encodedAllocationCount(encoded); !ok || count != tc.allocations {
+			t.Errorf("expected %d allocations to be counted from the encoding, got %d (ok: %v)", tc.allocations, count, ok)
+		}
+		if _, err := Decode(encoded); !errors.Is(err, tc.wantErr) {
+			t.Errorf("abi decoding %d allocations: expected error %v, got %v", tc.allocations, tc.wantErr, err)
+		}
+	}
+}
+
+func TestUnmarshalRejectsMalformedAssets(t *testing.T) {
+	exitJson := func(asset string) []byte {
+		return []byte(`[{"Asset":"` + asset + `","AssetMetadata":{"AssetType":0,"Metadata":null},"Allocations":[]}]`)
+	}
+	checksummed := "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+
+	for _, tc := range []struct {
+		name    string
+		asset   string
+		wantErr bool
+	}{
+		{"checksummed", checksummed, false},
+		{"lower case", strings.ToLower(checksummed), false},
+		{"upper case", "0x" + strings.ToUpper(checksummed[2:]), false},
+		{"native token", "0x0000000000000000000000000000000000000000", false},
+		{"wrong checksum", "0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", true},
+		{"too short", checksummed[:40], true},
+		{"not hex", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeg", true},
+	} {
+		var e Exit
+		err := json.Unmarshal(exitJson(tc.asset), &e)
+		if tc.wantErr && err == nil {
+			t.Errorf("%s: expected asset %s to be rejected", tc.name, tc.asset)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("%s: expected asset %s to be accepted, got %v", tc.name, tc.asset, err)
+		}
+	}
+
+	var e Exit
+	if err := json.Unmarshal(exitJson("0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"), &e); !errors.Is(err, ErrMalformedAsset) {
+		t.Errorf("expected a wrong checksum to be reported as %v, got %v", ErrMalformedAsset, err)
+	}
+}
+
+func TestSingleAssetExitValidate(t *testing.T) {
+	alice, bob := types.Destination{0x0a}, types.Destination{0x0b}
+	sae := func(aliceAmount, bobAmount *big.Int) SingleAssetExit {
+		return SingleAssetExit{Allocations: Allocations{
+			{Destination: alice, Amount: aliceAmount},
+			{Destination: bob, Amount: bobAmount},
+		}}
+	}
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	testCases := []struct {
+		name string
+		sae  SingleAssetExit
+		want error
+	}{
+		{"valid", sae(big.NewInt(5), big.NewInt(5)), nil},
+		{"the most a channel can hold", sae(max, big.NewInt(0)), nil},
+		{"a negative allocation", sae(big.NewInt(-1), big.NewInt(11)), ErrNegativeAllocation},
+		{"a missing amount", sae(nil, big.NewInt(5)), ErrMissingAmount},
+		{"allocations which overflow a uint256", sae(max, big.NewInt(1)), ErrAllocationOverflow},
+	}
+	for _, tc := range testCases {
+		if err := tc.sae.Validate(); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	e := Exit{sae(big.NewInt(5), big.NewInt(5))}
+	if err := e.ValidateTotals(types.Funds{types.Address{}: big.NewInt(10)}); err != nil {
+		t.Errorf("expected the totals to match, got %v", err)
+	}
+	if err := e.ValidateTotals(types.Funds{types.Address{}: big.NewInt(11)}); !errors.Is(err, ErrFundsNotConserved) {
+		t.Errorf("expected %v, got %v", ErrFundsNotConserved, err)
+	}
+}
@@ -3,6 +3,7 @@ package outcome
 import (
 	"bytes"
 	"encoding/hex"
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -33,3 +34,54 @@ func TestGuaranteeMetadataDecode(t *testing.T) {
 		t.Fatalf("incorrect encoding. Got %x, wanted %x", g, encodedGuaranteeMetadata)
 	}
 }
+
+func TestLedgerOutcomeWithGuaranteeEncodeDecode(t *testing.T) {
+	alice := types.AddressToDestination(common.HexToAddress("0x0a"))
+	bob := types.AddressToDestination(common.HexToAddress("0x0b"))
+	virtualChannel := types.Destination(common.HexToHash("0x0c"))
+
+	metadata, err := GuaranteeMetadata{Left: alice, Right: bob}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A ledger channel between Alice and Bob, which funds a virtual channel between them
+	ledger := Exit{{
+		Asset: common.Address{},
+		Allocations: Allocations{
+			{Destination: alice, Amount: big.NewInt(3)},
+			{Destination: bob, Amount: big.NewInt(5)},
+			{Destination: virtualChannel, Amount: big.NewInt(2), AllocationType: GuaranteeAllocationType, Metadata: metadata},
+		},
+	}}
+
+	encoded, err := ledger.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := ledger.Diff(decoded); diff != "" {
+		t.Fatalf("outcome did not survive encoding:\n%s", diff)
+	}
+
+	allocations := decoded[0].Allocations
+	if allocations[0].AllocationType != NormalAllocationType || allocations[1].AllocationType != NormalAllocationType {
+		t.Error("expected the participants' allocations to remain normal allocations")
+	}
+	if allocations[2].AllocationType != GuaranteeAllocationType {
+		t.Fatal("expected the virtual channel's allocation to remain a guarantee")
+	}
+	gotMetadata, err := DecodeIntoGuaranteeMetadata(allocations[2].Metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotMetadata.Left != alice || gotMetadata.Right != bob {
+		t.Errorf("expected guarantee metadata {%s %s}, got %+v", alice, bob, gotMetadata)
+	}
+	// Ledger channels encode guarantee metadata by concatenating the destinations, which must match the abi encoding
+	if !bytes.Equal(allocations[2].Metadata, append(alice.Bytes(), bob.Bytes()...)) {
+		t.Errorf("expected guarantee metadata to be the concatenation of the left and right destinations, got %#x", allocations[2].Metadata)
+	}
+}
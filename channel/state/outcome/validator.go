@@ -0,0 +1,29 @@
+package outcome
+
+import (
+	"errors"
+)
+
+var (
+	ErrFundsNotConserved  = errors.New("outcome does not conserve funds")
+	ErrNegativeAllocation = errors.New("outcome has a negative allocation")
+	ErrMissingAmount      = errors.New("outcome has an allocation without an amount")
+	ErrAllocationOverflow = errors.New("outcome allocates more than a channel can hold")
+)
+
+// Validator checks that an outcome proposed by a counterparty is acceptable, given the channel's current outcome.
+// Applications may supply their own Validator to enforce additional invariants on the outcomes of their channels.
+type Validator interface {
+	Validate(current, proposed Exit) error
+}
+
+// ConservationValidator accepts outcomes which allocate the same total amount of each asset as the current outcome,
+// and which do not allocate a negative amount to anyone.
+type ConservationValidator struct{}
+
+func (ConservationValidator) Validate(current, proposed Exit) error {
+	if err := proposed.Validate(); err != nil {
+		return err
+	}
+	return proposed.ValidateTotals(current.TotalAllocated())
+}
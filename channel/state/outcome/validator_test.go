@@ -0,0 +1,40 @@
+package outcome
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestConservationValidator(t *testing.T) {
+	alice, bob := types.Destination{0x0a}, types.Destination{0x0b}
+	asset := types.Address{0x01}
+	exit := func(aliceAmount, bobAmount int64) Exit {
+		return Exit{SingleAssetExit{Asset: asset, Allocations: Allocations{
+			{Destination: alice, Amount: big.NewInt(aliceAmount)},
+			{Destination: bob, Amount: big.NewInt(bobAmount)},
+		}}}
+	}
+	current := exit(5, 5)
+
+	testCases := []struct {
+		name     string
+		proposed Exit
+		want     error
+	}{
+		{"reallocation", exit(2, 8), nil},
+		{"unchanged", exit(5, 5), nil},
+		{"more funds", exit(5, 6), ErrFundsNotConserved},
+		{"fewer funds", exit(5, 4), ErrFundsNotConserved},
+		{"negative allocation", exit(-1, 11), ErrNegativeAllocation},
+		{"different asset", Exit{SingleAssetExit{Asset: types.Address{0x02}, Allocations: current[0].Allocations}}, ErrFundsNotConserved},
+	}
+	for _, tc := range testCases {
+		err := ConservationValidator{}.Validate(current, tc.proposed)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
@@ -9,6 +9,11 @@ import (
 	"github.com/statechannels/go-nitro/types"
 )
 
+var (
+	ErrMissingSignature = errors.New("signed state has a missing or empty signature")
+	ErrSignerMismatch   = errors.New("signature is not from the participant it is recorded for")
+)
+
 type SignedState struct {
 	state State
 	sigs  map[uint]Signature // keyed by participant index
@@ -46,6 +51,31 @@ func (ss SignedState) AddSignature(sig Signature) error {
 	return errors.New("signature does not match any participant")
 }
 
+// VerifySignatures recovers the signer of each of the SignedState's signatures, and returns an error unless it is the participant the signature is recorded for.
+// A SignedState with no signatures, or with an empty signature, does not verify.
+// Signatures added with AddSignature always verify, but those unmarshalled from a peer's message may not.
+func (ss SignedState) VerifySignatures() error {
+	if len(ss.sigs) == 0 {
+		return ErrMissingSignature
+	}
+	for i, sig := range ss.sigs {
+		if i >= uint(len(ss.state.Participants)) {
+			return fmt.Errorf("%w: no participant %d", ErrSignerMismatch, i)
+		}
+		if sig.Equal(Signature{}) {
+			return fmt.Errorf("%w: participant %d", ErrMissingSignature, i)
+		}
+		signer, err := ss.state.RecoverSigner(sig)
+		if err != nil {
+			return fmt.Errorf("%w: participant %d: %w", ErrSignerMismatch, i, err)
+		}
+		if signer != ss.state.Participants[i] {
+			return fmt.Errorf("%w: participant %d is %s, but the signature is from %s", ErrSignerMismatch, i, ss.state.Participants[i], signer)
+		}
+	}
+	return nil
+}
+
 // State returns the State part of the SignedState.
 func (ss SignedState) State() State {
 	return ss.state
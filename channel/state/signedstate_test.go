@@ -2,6 +2,7 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"math/big"
 	"reflect"
 	"testing"
@@ -69,6 +70,32 @@ func TestMerge(t *testing.T) {
 	}
 }
 
+func TestVerifySignatures(t *testing.T) {
+	sigA, _ := TestState.Sign(common.Hex2Bytes(`caab404f975b4620747174a75f08d98b4e5a7053b691b41bcfc0d839d48b7634`))
+	sigB, _ := TestState.Sign(common.Hex2Bytes(`62ecd49c4ccb41a70ad46532aed63cf815de15864bc415c87d507afd6a5e8da2`))
+
+	testCases := []struct {
+		name string
+		sigs map[uint]Signature
+		want error
+	}{
+		{"signed by every participant", map[uint]Signature{0: sigA, 1: sigB}, nil},
+		{"signed by one participant", map[uint]Signature{1: sigB}, nil},
+		{"signed by the wrong key", map[uint]Signature{0: sigA, 1: sigA}, ErrSignerMismatch},
+		{"signed by a non participant", map[uint]Signature{2: sigB}, ErrSignerMismatch},
+		{"empty signature", map[uint]Signature{0: sigA, 1: {}}, ErrMissingSignature},
+		{"no signatures", map[uint]Signature{}, ErrMissingSignature},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ss := SignedState{TestState, tc.sigs}
+			if err := ss.VerifySignatures(); !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
 func TestJSON(t *testing.T) {
 	ss1 := NewSignedState(TestState)
 	sigA, _ := TestState.Sign(common.Hex2Bytes(`caab404f975b4620747174a75f08d98b4e5a7053b691b41bcfc0d839d48b7634`))
@@ -144,6 +144,12 @@ func TestClone(t *testing.T) {
 	if TestState.ChannelNonce != 37140676580 || TestState.Outcome[0].Allocations[0].Amount.Cmp(big.NewInt(5)) != 0 {
 		t.Fatalf(`State.Clone(): original is modified when clone is modified `)
 	}
+
+	withAppData := TestState.Clone()
+	withAppData.AppData = []byte{1, 2, 3}
+	if diff := cmp.Diff(withAppData, withAppData.Clone()); diff != "" {
+		t.Fatalf("Clone: mismatch of app data (-want +got):\n%s", diff)
+	}
 }
 
 func TestRecoverSigner(t *testing.T) {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -12,7 +13,7 @@ import (
 	"github.com/statechannels/go-nitro/cmd/utils"
 	"github.com/statechannels/go-nitro/internal/chain"
 	"github.com/statechannels/go-nitro/internal/testhelpers"
-	"github.com/statechannels/go-nitro/types"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
 	"github.com/urfave/cli/v2"
 )
 
@@ -62,6 +63,8 @@ const (
 	DEPLOYER_PK      = "chainpk"
 	START_ANVIL      = "startanvil"
 	HOST_UI          = "hostui"
+	ENABLE_METRICS   = "enablemetrics"
+	MANIFEST         = "deploymentmanifest"
 )
 
 func main() {
@@ -97,6 +100,18 @@ func main() {
 			Value:   false,
 			Aliases: []string{"ui"},
 		},
+		&cli.BoolFlag{
+			Name:    ENABLE_METRICS,
+			Usage:   "Specifies whether each server serves Prometheus metrics on the metrics port in its config",
+			Value:   false,
+			Aliases: []string{"m"},
+		},
+		&cli.StringFlag{
+			Name:    MANIFEST,
+			Usage:   "Specifies a JSON file of contract addresses to reuse. Only missing contracts are deployed, and the file is updated with the resulting addresses. If not specified, all contracts are deployed.",
+			Value:   "",
+			Aliases: []string{"dm"},
+		},
 	}
 
 	app := &cli.App{
@@ -122,16 +137,23 @@ func main() {
 			chainUrl := cCtx.String(CHAIN_URL)
 			chainPk := cCtx.String(DEPLOYER_PK)
 
-			naAddress, vpaAddress, caAddress, err := chain.DeployContracts(context.Background(), chainUrl, chainAuthToken, chainPk)
+			var contractAddresses chainservice.ContractAddresses
+			var err error
+			if manifest := cCtx.String(MANIFEST); manifest != "" {
+				contractAddresses, err = chain.DeployMissingContracts(context.Background(), chainUrl, chainAuthToken, chainPk, manifest, chain.TxOpts{})
+			} else {
+				contractAddresses, err = chain.DeployContracts(context.Background(), chainUrl, chainAuthToken, chainPk, chain.TxOpts{})
+			}
 			if err != nil {
 				utils.StopCommands(running...)
 				panic(err)
 			}
 
 			hostUI := cCtx.Bool(HOST_UI)
+			enableMetrics := cCtx.Bool(ENABLE_METRICS)
 
 			// Setup Ivan first, he is the DHT boot peer
-			client, err := setupRPCServer(ivan, participants[ivan].color, naAddress, vpaAddress, caAddress, chainUrl, chainAuthToken, dataFolder, hostUI)
+			client, err := setupRPCServer(ivan, participants[ivan].color, contractAddresses, chainUrl, chainAuthToken, dataFolder, hostUI, enableMetrics)
 			if err != nil {
 				utils.StopCommands(running...)
 				panic(err)
@@ -147,7 +169,7 @@ func main() {
 			for _, participantName := range []name{alice, bob, irene} {
 				p := participants[participantName]
 				fmt.Println("participantName: " + participantName)
-				client, err := setupRPCServer(participantName, p.color, naAddress, vpaAddress, caAddress, chainUrl, chainAuthToken, dataFolder, hostUI)
+				client, err := setupRPCServer(participantName, p.color, contractAddresses, chainUrl, chainAuthToken, dataFolder, hostUI, enableMetrics)
 				if err != nil {
 					utils.StopCommands(running...)
 					panic(err)
@@ -166,7 +188,7 @@ func main() {
 }
 
 // setupRPCServer starts up an RPC server for the given participant
-func setupRPCServer(n name, c color, na, vpa, ca types.Address, chainUrl, chainAuthToken string, dataFolder string, hostUI bool) (*exec.Cmd, error) {
+func setupRPCServer(n name, c color, contractAddresses chainservice.ContractAddresses, chainUrl, chainAuthToken string, dataFolder string, hostUI bool, enableMetrics bool) (*exec.Cmd, error) {
 	args := []string{"run"}
 
 	if hostUI {
@@ -174,20 +196,26 @@ func setupRPCServer(n name, c color, na, vpa, ca types.Address, chainUrl, chainA
 	}
 
 	args = append(args, ".")
-	args = append(args, "-naaddress", na.String())
-	args = append(args, "-vpaaddress", vpa.String())
-	args = append(args, "-caaddress", ca.String())
+	args = append(args, "-naaddress", contractAddresses.NitroAdjudicator.String())
+	args = append(args, "-vpaaddress", contractAddresses.VirtualPaymentApp.String())
+	args = append(args, "-caaddress", contractAddresses.ConsensusApp.String())
 
 	args = append(args, "-chainauthtoken", chainAuthToken)
 	args = append(args, "-chainurl", chainUrl)
 
 	args = append(args, "-durablestorefolder", dataFolder)
 
+	if enableMetrics {
+		args = append(args, "-enablemetrics")
+	}
+
 	args = append(args, "-config", fmt.Sprintf("./cmd/test-configs/%s.toml", n))
 
 	cmd := exec.Command("go", args...)
-	cmd.Stdout = newColorWriter(c, os.Stdout)
-	cmd.Stderr = newColorWriter(c, os.Stderr)
+	cmd.Stdout = newColorWriter(n, c, os.Stdout)
+	cmd.Stderr = newColorWriter(n, c, os.Stderr)
+	// Run each server in its own process group so that it receives the SIGTERM sent by utils.StopCommands, and can close its store
+	utils.UseOwnProcessGroup(cmd)
 	err := cmd.Start()
 	if err != nil {
 		return nil, err
@@ -195,24 +223,71 @@ func setupRPCServer(n name, c color, na, vpa, ca types.Address, chainUrl, chainA
 	return cmd, nil
 }
 
-// colorWriter is a writer that writes to the underlying writer with the given color
+// colorWriter is a writer that writes to the underlying writer with the given color.
+// If the underlying writer is not a terminal, each line is instead prefixed with the participant's name.
 type colorWriter struct {
-	writer io.Writer
-	color  color
+	writer      io.Writer
+	color       color
+	prefix      string
+	useColor    bool
+	atLineStart bool
 }
 
-func (cw colorWriter) Write(p []byte) (n int, err error) {
-	_, err = cw.writer.Write([]byte("\033" + string(cw.color) + string(p) + "\033[0m"))
+// Write colors (or prefixes) each line of p separately, so that colors never span a newline.
+func (cw *colorWriter) Write(p []byte) (n int, err error) {
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		content := bytes.TrimSuffix(line, []byte("\n"))
+		endsLine := len(content) < len(line)
+
+		if cw.useColor {
+			if len(content) > 0 {
+				out.WriteString("\033" + string(cw.color))
+				out.Write(content)
+				out.WriteString("\033[0m")
+			}
+		} else {
+			if cw.atLineStart && len(line) > 0 {
+				out.WriteString(cw.prefix)
+			}
+			out.Write(content)
+		}
+		if endsLine {
+			out.WriteString("\n")
+		}
+		if len(line) > 0 {
+			cw.atLineStart = endsLine
+		}
+	}
+
+	_, err = cw.writer.Write(out.Bytes())
 	if err != nil {
 		return 0, err
 	}
 	return len(p), nil
 }
 
-// newColorWriter creates a writer that colors the output with the given color
-func newColorWriter(c color, w io.Writer) colorWriter {
-	return colorWriter{
-		writer: w,
-		color:  c,
+// newColorWriter creates a writer that colors the output with the given color,
+// or prefixes it with the participant's name if w is not a terminal
+func newColorWriter(n name, c color, w io.Writer) *colorWriter {
+	return &colorWriter{
+		writer:      w,
+		color:       c,
+		prefix:      "[" + string(n) + "] ",
+		useColor:    isTerminal(w),
+		atLineStart: true,
+	}
+}
+
+// isTerminal returns true if w is a file attached to a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
 	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
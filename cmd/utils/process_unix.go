@@ -0,0 +1,28 @@
+//go:build !windows
+
+package utils
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalCommand sends sig to the command, or to its whole process group if it runs in its own group.
+func signalCommand(cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		return syscall.Kill(-cmd.Process.Pid, sig)
+	}
+	return cmd.Process.Signal(sig)
+}
+
+func terminate(cmd *exec.Cmd) error {
+	return signalCommand(cmd, syscall.SIGTERM)
+}
+
+func kill(cmd *exec.Cmd) error {
+	return signalCommand(cmd, syscall.SIGKILL)
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package utils
+
+import "os/exec"
+
+// Process groups and SIGTERM are not available on windows, so commands are killed outright.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func terminate(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+func kill(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
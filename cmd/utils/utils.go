@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -13,7 +15,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/rand"
 	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/serde"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -25,17 +30,55 @@ func WaitForKillSignal() {
 	fmt.Printf("Received signal %s, exiting..\n", sig)
 }
 
-// StopCommands stops the given executing commands
+// StopTimeout is how long StopCommands waits for a command to exit after asking it to terminate.
+const StopTimeout = 10 * time.Second
+
+// UseOwnProcessGroup configures cmd to run in its own process group, so that StopCommands signals
+// any processes it spawns as well. This matters for commands run with `go run`, which does not forward signals to the program it runs.
+// It must be called before the command is started.
+func UseOwnProcessGroup(cmd *exec.Cmd) {
+	setProcessGroup(cmd)
+}
+
+// StopCommands stops the given executing commands.
+// Each command is sent SIGTERM so that it can shut down cleanly. Any command which has not exited after StopTimeout is killed.
 func StopCommands(cmds ...*exec.Cmd) {
-	for _, cmd := range cmds {
+	exited := make([]chan struct{}, len(cmds))
+	for i, cmd := range cmds {
+		exited[i] = make(chan struct{})
+		if cmd.Process == nil {
+			close(exited[i])
+			continue
+		}
 		fmt.Printf("Stopping process %v\n", cmd.Args)
-		err := cmd.Process.Signal(syscall.SIGINT)
-		if err != nil {
-			panic(err)
+		if err := terminate(cmd); err != nil {
+			fmt.Printf("Could not terminate process %v: %v\n", cmd.Args, err)
+		}
+		go func(cmd *exec.Cmd, done chan struct{}) {
+			_ = cmd.Wait()
+			close(done)
+		}(cmd, exited[i])
+	}
+
+	timer := time.NewTimer(StopTimeout)
+	defer timer.Stop()
+	timedOut := false
+	for i, cmd := range cmds {
+		if !timedOut {
+			select {
+			case <-exited[i]:
+				continue
+			case <-timer.C:
+				timedOut = true
+			}
 		}
-		err = cmd.Process.Kill()
-		if err != nil {
-			panic(err)
+		select {
+		case <-exited[i]:
+		default:
+			fmt.Printf("Process %v did not exit within %s, killing it\n", cmd.Args, StopTimeout)
+			if err := kill(cmd); err != nil {
+				fmt.Printf("Could not kill process %v: %v\n", cmd.Args, err)
+			}
 		}
 	}
 }
@@ -56,8 +99,9 @@ func CreateLedgerChannel(client rpc.RpcClientApi, counterPartyAddress common.Add
 	return nil
 }
 
-// waitForRpcClient waits for an RPC to be available at the given url
-// It does this by performing a GET request to the url until it receives a response
+// WaitForRpcClient waits for an RPC server at the given url to report that its node is ready.
+// It does this by repeatedly requesting the server's health until it reports Ready.
+// A server which is not yet listening is waited for, but any other failure to get its health is returned.
 func WaitForRpcClient(rpcClientUrl string, interval, timeout time.Duration) error {
 	fmt.Printf("Waiting for client: %s\n", rpcClientUrl)
 	timeoutTicker := time.NewTicker(timeout)
@@ -71,14 +115,39 @@ func WaitForRpcClient(rpcClientUrl string, interval, timeout time.Duration) erro
 		case <-timeoutTicker.C:
 			return errors.New("polling timed out")
 		case <-intervalTicker.C:
-			resp, err := client.Get(rpcClientUrl)
+			health, err := getHealth(client, rpcClientUrl)
 			if err != nil && !errors.Is(err, syscall.ECONNREFUSED) {
-				panic(err)
+				return fmt.Errorf("could not get the health of %s: %w", rpcClientUrl, err)
 			}
-			if resp != nil {
+			if err == nil && health.Ready {
 				fmt.Printf("Success! Client ready: %s\n", rpcClientUrl)
 				return nil
 			}
 		}
 	}
 }
+
+// getHealth requests the health of the RPC server at the given url
+func getHealth(client *http.Client, rpcClientUrl string) (query.HealthInfo, error) {
+	req, err := json.Marshal(serde.NewJsonRpcSpecificRequest(rand.Uint64(), serde.HealthMethod, serde.NoPayloadRequest{}, ""))
+	if err != nil {
+		return query.HealthInfo{}, err
+	}
+	resp, err := client.Post(rpcClientUrl, "application/json", bytes.NewReader(req))
+	if err != nil {
+		return query.HealthInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Result query.HealthInfo    `json:"result"`
+		Error  *serde.JsonRpcError `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return query.HealthInfo{}, fmt.Errorf("could not decode health response: %w", err)
+	}
+	if res.Error != nil {
+		return query.HealthInfo{}, res.Error
+	}
+	return res.Result, nil
+}
@@ -3,6 +3,7 @@ package crypto
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -20,13 +21,28 @@ type Signature struct {
 	V byte
 }
 
+// ErrInvalidV is returned when recovering the signer of a signature whose recovery id V is not 0, 1, 27 or 28.
+var ErrInvalidV = errors.New("signature has an invalid V")
+
+// The version bytes of the EIP-191 signed data schemes, see https://eips.ethereum.org/EIPS/eip-191
+const (
+	EIP191IntendedValidator byte = 0x00 // data with an intended validator
+	EIP191StructuredData    byte = 0x01 // structured data, see EIP-712
+	EIP191PersonalSign      byte = 0x45 // personal_sign messages, prefixed with "\x19Ethereum Signed Message:\n" + len(message)
+)
+
 // SignEthereumMessage accepts an arbitrary message, prepends a known message,
 // hashes the result using keccak256 and calculates the secp256k1 signature
 // of the hash using the provided secret key. The known message added to the input before hashing is
 // "\x19Ethereum Signed Message:\n" + len(message).
 // See https://github.com/ethereum/go-ethereum/pull/2940 and EIPs 191, 721.
 func SignEthereumMessage(message []byte, secretKey []byte) (Signature, error) {
-	digest := computeEthereumSignedMessageDigest(message)
+	return SignEIP191Message(EIP191PersonalSign, common.Address{}, message, secretKey)
+}
+
+// SignEIP191Message signs the EIP-191 digest of the message under the given version byte, see ComputeEIP191Digest.
+func SignEIP191Message(version byte, validator common.Address, message []byte, secretKey []byte) (Signature, error) {
+	digest := ComputeEIP191Digest(version, validator, message)
 	concatenatedSignature, error := secp256k1.Sign(digest, secretKey)
 	if error != nil {
 		return Signature{}, error
@@ -44,13 +60,23 @@ func SignEthereumMessage(message []byte, secretKey []byte) (Signature, error) {
 // RecoverEthereumMessageSigner accepts a message (bytestring) and signature generated by SignEthereumMessage.
 // It reconstructs the appropriate digest and recovers an address via secp256k1 public key recovery
 func RecoverEthereumMessageSigner(message []byte, signature Signature) (common.Address, error) {
+	return RecoverEIP191MessageSigner(EIP191PersonalSign, common.Address{}, message, signature)
+}
+
+// RecoverEIP191MessageSigner accepts a message and a signature generated by SignEIP191Message with the same version and validator,
+// and recovers the signer's address.
+func RecoverEIP191MessageSigner(version byte, validator common.Address, message []byte, signature Signature) (common.Address, error) {
 	// This step is necessary to remain compatible with the ecrecover precompile
 	sig := signature
-	if int(sig.V) >= 27 {
-		sig.V = byte(int(sig.V - 27))
+	switch sig.V {
+	case 0, 1:
+	case 27, 28:
+		sig.V -= 27
+	default:
+		return types.Address{}, fmt.Errorf("%w: %d", ErrInvalidV, signature.V)
 	}
 
-	digest := computeEthereumSignedMessageDigest(message)
+	digest := ComputeEIP191Digest(version, validator, message)
 	pubKey, error := secp256k1.RecoverPubkey(digest, joinSignature(sig))
 	if error != nil {
 		return types.Address{}, error
@@ -63,15 +89,22 @@ func RecoverEthereumMessageSigner(message []byte, signature Signature) (common.A
 	return crypto.PubkeyToAddress(*ecdsaPubKey), error
 }
 
-// computeEthereumSignedMessageDigest accepts an arbitrary message, prepends a known message,
-// and hashes the result using keccak256. The known message added to the input before hashing is
-// "\x19Ethereum Signed Message:\n" + len(message).
-func computeEthereumSignedMessageDigest(message []byte) []byte {
-	return crypto.Keccak256(
-		[]byte(
-			fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), string(message)),
-		),
-	)
+// ComputeEIP191Digest returns the keccak256 hash of the EIP-191 signed data 0x19 || version || version specific data || message.
+//   - For EIP191IntendedValidator, the version specific data is the validator's address.
+//   - For EIP191PersonalSign, it is "thereum Signed Message:\n" + len(message), so that the signed data begins "\x19Ethereum Signed Message:\n".
+//   - For any other version, such as EIP191StructuredData, the version specific data must be included at the start of the message:
+//     for structured data, the message is the EIP-712 domain separator followed by the hash of the struct.
+//
+// The validator is ignored unless the version is EIP191IntendedValidator.
+func ComputeEIP191Digest(version byte, validator common.Address, message []byte) []byte {
+	var versionData []byte
+	switch version {
+	case EIP191IntendedValidator:
+		versionData = validator.Bytes()
+	case EIP191PersonalSign:
+		versionData = []byte(fmt.Sprintf("thereum Signed Message:\n%d", len(message)))
+	}
+	return crypto.Keccak256([]byte{0x19, version}, versionData, message)
 }
 
 // splitSignature takes a 65 bytes signature in the [R||S||V] format and returns the individual components
@@ -101,6 +134,10 @@ func (s1 Signature) Equal(s2 Signature) bool {
 
 func (s Signature) MarshalJSON() ([]byte, error) {
 	joined := joinSignature(s)
+	// An all zero signature is decoded as the empty signature, so it is encoded as one too
+	if allZero(joined) {
+		joined = []byte{0}
+	}
 	hex := hexutil.Encode(joined)
 	return json.Marshal(hex)
 }
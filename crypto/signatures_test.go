@@ -0,0 +1,133 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestComputeEIP191Digest(t *testing.T) {
+	validator := common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+	// The EIP-712 example's domain separator and the hash of its Mail struct
+	structuredData := hexutil.MustDecode("0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f" + "c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e")
+
+	testCases := []struct {
+		name      string
+		version   byte
+		validator common.Address
+		message   []byte
+		want      []byte
+	}{
+		{
+			"intended validator", EIP191IntendedValidator, validator, []byte("hello"),
+			crypto.Keccak256(hexutil.MustDecode("0x1900" + "cccccccccccccccccccccccccccccccccccccccc" + "68656c6c6f")),
+		},
+		{
+			"structured data", EIP191StructuredData, common.Address{}, structuredData,
+			hexutil.MustDecode("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"),
+		},
+		{
+			"personal sign", EIP191PersonalSign, common.Address{}, []byte("hello"),
+			hexutil.MustDecode("0x50b2c43fd39106bafbba0da34fc430e1f91e3c96ea2acee2bc34119f92b37750"),
+		},
+		{
+			"personal sign is the scheme used by go-ethereum's signer", EIP191PersonalSign, validator, []byte("a longer message to sign"),
+			accounts.TextHash([]byte("a longer message to sign")),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ComputeEIP191Digest(tc.version, tc.validator, tc.message); !bytes.Equal(got, tc.want) {
+				t.Errorf("expected digest %s, got %s", hexutil.Encode(tc.want), hexutil.Encode(got))
+			}
+		})
+	}
+}
+
+func TestEIP191SignAndRecover(t *testing.T) {
+	// The signer and signature of the EIP-712 example
+	secretKey := crypto.Keccak256([]byte("cow"))
+	signer := common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	structuredData := hexutil.MustDecode("0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f" + "c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e")
+
+	sig, err := SignEIP191Message(EIP191StructuredData, common.Address{}, structuredData, secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Signature{
+		R: hexutil.MustDecode("0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d"),
+		S: hexutil.MustDecode("0x07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562"),
+		V: 28,
+	}
+	if !sig.Equal(want) {
+		t.Errorf("expected signature %s, got %s", want.ToHexString(), sig.ToHexString())
+	}
+
+	validator := common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+	for _, version := range []byte{EIP191IntendedValidator, EIP191StructuredData, EIP191PersonalSign} {
+		sig, err := SignEIP191Message(version, validator, structuredData, secretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered, err := RecoverEIP191MessageSigner(version, validator, structuredData, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recovered != signer {
+			t.Errorf("version %#x: expected to recover %s, got %s", version, signer, recovered)
+		}
+		// A signature for one version does not recover the signer under another
+		other := EIP191PersonalSign
+		if version == EIP191PersonalSign {
+			other = EIP191StructuredData
+		}
+		if recovered, _ := RecoverEIP191MessageSigner(other, validator, structuredData, sig); recovered == signer {
+			t.Errorf("version %#x: expected not to recover the signer under version %#x", version, other)
+		}
+	}
+
+	// SignEthereumMessage signs with the personal sign scheme
+	sig, err = SignEthereumMessage(structuredData, secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered, _ := RecoverEIP191MessageSigner(EIP191PersonalSign, common.Address{}, structuredData, sig); recovered != signer {
+		t.Errorf("expected SignEthereumMessage to use the personal sign scheme, but recovered %s", recovered)
+	}
+}
+
+func TestRecoverRejectsInvalidV(t *testing.T) {
+	secretKey := crypto.Keccak256([]byte("cow"))
+	signer := common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	message := []byte("hello")
+
+	sig, err := SignEthereumMessage(message, secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Recovery ids may be given either way
+	for _, v := range []byte{sig.V, sig.V - 27} {
+		valid := sig
+		valid.V = v
+		if recovered, err := RecoverEthereumMessageSigner(message, valid); err != nil || recovered != signer {
+			t.Errorf("V=%d: expected to recover %s, got %s, %v", v, signer, recovered, err)
+		}
+	}
+
+	for _, v := range []byte{2, 26, 29, 30, 35, 200, 255} {
+		invalid := sig
+		invalid.V = v
+		recovered, err := RecoverEthereumMessageSigner(message, invalid)
+		if !errors.Is(err, ErrInvalidV) {
+			t.Errorf("V=%d: expected %v, got %v", v, ErrInvalidV, err)
+		}
+		if recovered != (common.Address{}) {
+			t.Errorf("V=%d: expected no address to be recovered, got %s", v, recovered)
+		}
+	}
+}
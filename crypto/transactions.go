@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrNoChainId          = errors.New("no chain id")
+	ErrNotReplayProtected = errors.New("transaction is not replay protected")
+	ErrWrongChainId       = errors.New("transaction is signed for a different chain")
+)
+
+// SignTransaction signs the transaction with the provided secret key. The chain id is included in the signature (see EIP-155),
+// so that the signed transaction cannot be replayed on another chain.
+func SignTransaction(tx *ethTypes.Transaction, chainId *big.Int, secretKey []byte) (*ethTypes.Transaction, error) {
+	if chainId == nil {
+		return nil, ErrNoChainId
+	}
+	key, err := crypto.ToECDSA(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	return ethTypes.SignTx(tx, ethTypes.LatestSignerForChainID(chainId), key)
+}
+
+// NewTransactor returns transact options which sign transactions from the secret key's address with SignTransaction.
+func NewTransactor(secretKey []byte, chainId *big.Int) (*bind.TransactOpts, error) {
+	if chainId == nil {
+		return nil, ErrNoChainId
+	}
+	key, err := crypto.ToECDSA(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *ethTypes.Transaction) (*ethTypes.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return SignTransaction(tx, chainId, secretKey)
+		},
+	}, nil
+}
+
+// VerifyTransactionChain returns an error unless the signed transaction is replay protected, and is only valid on the chain with the given id.
+func VerifyTransactionChain(tx *ethTypes.Transaction, chainId *big.Int) error {
+	if !tx.Protected() {
+		return ErrNotReplayProtected
+	}
+	if tx.ChainId().Cmp(chainId) != 0 {
+		return fmt.Errorf("%w: signed for chain %s, expected chain %s", ErrWrongChainId, tx.ChainId(), chainId)
+	}
+	return nil
+}
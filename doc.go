@@ -34,6 +34,8 @@
 //	      Specifies whether to use a durable store or an in-memory store.
 //	-usenats
 //	      Specifies whether to use NATS or http/ws for the rpc server.
+//	-usewebsocket
+//	      Specifies whether to serve rpc requests and notifications over a single websocket connection, rather than http/ws. Ignored if usenats is set.
 //
 // You can make remote procedure calls like so:
 //
@@ -1,16 +1,22 @@
 package chain
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"os"
 	"os/exec"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
 	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
 	ConsensusApp "github.com/statechannels/go-nitro/node/engine/chainservice/consensusapp"
 	chainutils "github.com/statechannels/go-nitro/node/engine/chainservice/utils"
@@ -31,29 +37,160 @@ func StartAnvil() (*exec.Cmd, error) {
 	return chainCmd, nil
 }
 
+// TxOpts configures the transactions sent when deploying contracts.
+type TxOpts struct {
+	// GasPrice is the gas price to use. If nil, the price suggested by the chain is used.
+	GasPrice *big.Int
+	// GasLimit is the gas limit to use. If 0, the limit is estimated.
+	GasLimit uint64
+}
+
 // DeployContracts deploys the NitroAdjudicator, VirtualPaymentApp and ConsensusApp contracts.
-func DeployContracts(ctx context.Context, chainUrl, chainAuthToken, chainPk string) (na common.Address, vpa common.Address, ca common.Address, err error) {
-	ethClient, txSubmitter, err := chainutils.ConnectToChain(context.Background(), chainUrl, chainAuthToken, common.Hex2Bytes(chainPk))
+// If ctx is cancelled part way through, the addresses of the contracts deployed so far are returned along with the error,
+// so that a caller can retry deploying only the remaining contracts.
+func DeployContracts(ctx context.Context, chainUrl, chainAuthToken, chainPk string, txOpts TxOpts) (chainservice.ContractAddresses, error) {
+	addresses := chainservice.ContractAddresses{}
+	if err := ctx.Err(); err != nil {
+		return addresses, err
+	}
+
+	ethClient, txSubmitter, err := connect(ctx, chainUrl, chainAuthToken, chainPk, txOpts)
+	if err != nil {
+		return addresses, err
+	}
+
+	err = runDeployments(ctx, []contractDeployment{
+		{"NitroAdjudicator", &addresses.NitroAdjudicator, func(ctx context.Context) (types.Address, error) {
+			return deployContract(ctx, "NitroAdjudicator", ethClient, txSubmitter, NitroAdjudicator.DeployNitroAdjudicator)
+		}},
+		{"VirtualPaymentApp", &addresses.VirtualPaymentApp, func(ctx context.Context) (types.Address, error) {
+			return deployContract(ctx, "VirtualPaymentApp", ethClient, txSubmitter, VirtualPaymentApp.DeployVirtualPaymentApp)
+		}},
+		{"ConsensusApp", &addresses.ConsensusApp, func(ctx context.Context) (types.Address, error) {
+			return deployContract(ctx, "ConsensusApp", ethClient, txSubmitter, ConsensusApp.DeployConsensusApp)
+		}},
+	})
+	return addresses, err
+}
+
+// DeployMissingContracts deploys only those contracts which are not already deployed at the addresses recorded
+// in the JSON manifest at manifestPath. An address is reused only if the code deployed there matches the expected bytecode.
+// The resulting addresses are written back to the manifest, so that subsequent runs reuse them.
+// This includes the addresses of contracts deployed before ctx was cancelled.
+func DeployMissingContracts(ctx context.Context, chainUrl, chainAuthToken, chainPk, manifestPath string, txOpts TxOpts) (chainservice.ContractAddresses, error) {
+	addresses, err := readManifest(manifestPath)
 	if err != nil {
-		return types.Address{}, types.Address{}, types.Address{}, err
+		return chainservice.ContractAddresses{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return addresses, err
+	}
+
+	ethClient, txSubmitter, err := connect(ctx, chainUrl, chainAuthToken, chainPk, txOpts)
+	if err != nil {
+		return addresses, err
+	}
+
+	err = runDeployments(ctx, []contractDeployment{
+		{"NitroAdjudicator", &addresses.NitroAdjudicator, func(ctx context.Context) (types.Address, error) {
+			return deployContractIfMissing(ctx, "NitroAdjudicator", addresses.NitroAdjudicator, NitroAdjudicator.NitroAdjudicatorMetaData.Bin, ethClient, txSubmitter, NitroAdjudicator.DeployNitroAdjudicator)
+		}},
+		{"VirtualPaymentApp", &addresses.VirtualPaymentApp, func(ctx context.Context) (types.Address, error) {
+			return deployContractIfMissing(ctx, "VirtualPaymentApp", addresses.VirtualPaymentApp, VirtualPaymentApp.VirtualPaymentAppMetaData.Bin, ethClient, txSubmitter, VirtualPaymentApp.DeployVirtualPaymentApp)
+		}},
+		{"ConsensusApp", &addresses.ConsensusApp, func(ctx context.Context) (types.Address, error) {
+			return deployContractIfMissing(ctx, "ConsensusApp", addresses.ConsensusApp, ConsensusApp.ConsensusAppMetaData.Bin, ethClient, txSubmitter, ConsensusApp.DeployConsensusApp)
+		}},
+	})
+
+	if manifestErr := writeManifest(manifestPath, addresses); manifestErr != nil {
+		return addresses, errors.Join(err, manifestErr)
 	}
+	return addresses, err
+}
 
-	na, err = deployContract(ctx, "NitroAdjudicator", ethClient, txSubmitter, NitroAdjudicator.DeployNitroAdjudicator)
+// connect connects to the chain and returns a transactor configured with txOpts, which is bound to ctx.
+func connect(ctx context.Context, chainUrl, chainAuthToken, chainPk string, txOpts TxOpts) (*ethclient.Client, *bind.TransactOpts, error) {
+	ethClient, txSubmitter, err := chainutils.ConnectToChain(ctx, chainUrl, chainAuthToken, common.Hex2Bytes(chainPk))
 	if err != nil {
-		return types.Address{}, types.Address{}, types.Address{}, err
+		return nil, nil, err
+	}
+	txSubmitter.Context = ctx
+	txSubmitter.GasPrice = txOpts.GasPrice
+	txSubmitter.GasLimit = txOpts.GasLimit
+	return ethClient, txSubmitter, nil
+}
+
+// contractDeployment deploys a single contract, recording its address
+type contractDeployment struct {
+	name    string
+	address *types.Address
+	deploy  func(ctx context.Context) (types.Address, error)
+}
+
+// runDeployments runs each deployment in turn, stopping before the next deployment if ctx has been cancelled.
+// The addresses of contracts deployed before stopping are kept.
+func runDeployments(ctx context.Context, deployments []contractDeployment) error {
+	deployed := []string{}
+	for _, d := range deployments {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("deployment stopped after deploying %v: %w", deployed, err)
+		}
+		address, err := d.deploy(ctx)
+		if err != nil {
+			return fmt.Errorf("could not deploy %s after deploying %v: %w", d.name, deployed, err)
+		}
+		*d.address = address
+		deployed = append(deployed, d.name)
 	}
+	return nil
+}
 
-	vpa, err = deployContract(ctx, "VirtualPaymentApp", ethClient, txSubmitter, VirtualPaymentApp.DeployVirtualPaymentApp)
+// readManifest reads contract addresses from the JSON manifest at path. A missing manifest yields no addresses.
+func readManifest(path string) (chainservice.ContractAddresses, error) {
+	addresses := chainservice.ContractAddresses{}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return addresses, nil
+	}
 	if err != nil {
-		return types.Address{}, types.Address{}, types.Address{}, err
+		return addresses, fmt.Errorf("could not read deployment manifest: %w", err)
+	}
+	if err := json.Unmarshal(raw, &addresses); err != nil {
+		return addresses, fmt.Errorf("could not parse deployment manifest %s: %w", path, err)
 	}
+	return addresses, nil
+}
 
-	ca, err = deployContract(ctx, "ConsensusApp", ethClient, txSubmitter, ConsensusApp.DeployConsensusApp)
+// writeManifest writes contract addresses to the JSON manifest at path.
+func writeManifest(path string, addresses chainservice.ContractAddresses) error {
+	raw, err := json.MarshalIndent(addresses, "", "  ")
 	if err != nil {
-		return types.Address{}, types.Address{}, types.Address{}, err
+		return err
 	}
+	return os.WriteFile(path, raw, 0o644)
+}
 
-	return
+// deployContractIfMissing returns address if the code deployed there matches the runtime bytecode produced by bin.
+// Otherwise it deploys the contract and returns the new address.
+func deployContractIfMissing[T contractBackend](ctx context.Context, name string, address types.Address, bin string, ethClient *ethclient.Client, txSubmitter *bind.TransactOpts, deploy deployFunc[T]) (types.Address, error) {
+	if address != (types.Address{}) {
+		deployed, err := ethClient.CodeAt(ctx, address, nil)
+		if err != nil {
+			return types.Address{}, err
+		}
+		// Executing the creation bytecode without a recipient returns the runtime bytecode it would deploy
+		expected, err := ethClient.CallContract(ctx, ethereum.CallMsg{From: txSubmitter.From, Data: common.FromHex(bin)}, nil)
+		if err != nil {
+			return types.Address{}, err
+		}
+		if len(deployed) > 0 && bytes.Equal(deployed, expected) {
+			fmt.Printf("Reusing %s deployed at %s\n", name, address.String())
+			return address, nil
+		}
+		fmt.Printf("Code at %s does not match %s, redeploying\n", address.String(), name)
+	}
+	return deployContract(ctx, name, ethClient, txSubmitter, deploy)
 }
 
 type contractBackend interface {
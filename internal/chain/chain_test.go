@@ -0,0 +1,57 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestDeployContractsWithCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	addresses, err := DeployContracts(ctx, "ws://127.0.0.1:0", "", "", TxOpts{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	if addresses != (chainservice.ContractAddresses{}) {
+		t.Errorf("expected no contracts to be deployed, got %+v", addresses)
+	}
+}
+
+func TestRunDeploymentsStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deployedAddress := types.Address(common.HexToAddress(`a`))
+	addresses := chainservice.ContractAddresses{}
+	calls := 0
+
+	err := runDeployments(ctx, []contractDeployment{
+		{"NitroAdjudicator", &addresses.NitroAdjudicator, func(ctx context.Context) (types.Address, error) {
+			calls++
+			// Cancel while the first contract is being deployed
+			cancel()
+			return deployedAddress, nil
+		}},
+		{"VirtualPaymentApp", &addresses.VirtualPaymentApp, func(ctx context.Context) (types.Address, error) {
+			calls++
+			return types.Address(common.HexToAddress(`b`)), nil
+		}},
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected only the first contract to be deployed, got %d deployments", calls)
+	}
+	want := chainservice.ContractAddresses{NitroAdjudicator: deployedAddress}
+	if addresses != want {
+		t.Errorf("expected partial addresses %+v, got %+v", want, addresses)
+	}
+}
@@ -39,6 +39,7 @@ const (
 
 	CHANNEL_ID_LOG_KEY   = "channel-id"
 	OBJECTIVE_ID_LOG_KEY = "objective-id"
+	WAITING_FOR_LOG_KEY  = "waiting-for"
 	ADDRESS_LOG_KEY      = "address"
 )
 
@@ -52,6 +53,11 @@ func WithObjectiveIdAttribute(o protocols.ObjectiveId) slog.Attr {
 	return slog.String(OBJECTIVE_ID_LOG_KEY, string(o))
 }
 
+// WithWaitingForAttribute returns a logging attribute for the given WaitingFor value
+func WithWaitingForAttribute(w protocols.WaitingFor) slog.Attr {
+	return slog.String(WAITING_FOR_LOG_KEY, w.String())
+}
+
 // LoggerWithAddress returns a logger with the address attribute set to the given address
 func LoggerWithAddress(logger *slog.Logger, a types.Address) *slog.Logger {
 	return logger.With(slog.String(ADDRESS_LOG_KEY, a.String()))
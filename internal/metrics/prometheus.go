@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the name of every metric served by PrometheusMetrics
+const Namespace = "nitro"
+
+// PrometheusMetrics is an engine.MetricsApi which records metrics as Prometheus counters, gauges and histograms.
+// Each metric is created the first time it is recorded. Durations are recorded in seconds, in histograms named with a "_seconds" suffix.
+type PrometheusMetrics struct {
+	registry *prometheus.Registry
+
+	mu         sync.Mutex
+	counters   map[string]prometheus.Counter
+	gauges     map[string]prometheus.Gauge
+	histograms map[string]prometheus.Histogram
+}
+
+// NewPrometheusMetrics returns a PrometheusMetrics with no metrics recorded yet.
+// The metrics of the Go runtime and of the process are served alongside the recorded metrics.
+func NewPrometheusMetrics() *PrometheusMetrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGoCollector(), prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	return &PrometheusMetrics{
+		registry:   registry,
+		counters:   make(map[string]prometheus.Counter),
+		gauges:     make(map[string]prometheus.Gauge),
+		histograms: make(map[string]prometheus.Histogram),
+	}
+}
+
+// IncrementCounter increments the counter with the given name by one
+func (pm *PrometheusMetrics) IncrementCounter(name string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	c, ok := pm.counters[name]
+	if !ok {
+		c = prometheus.NewCounter(prometheus.CounterOpts{Namespace: Namespace, Name: name + "_total", Help: "Number of " + name})
+		pm.register(c)
+		pm.counters[name] = c
+	}
+	c.Inc()
+}
+
+// SetGauge sets the gauge with the given name to the given value
+func (pm *PrometheusMetrics) SetGauge(name string, value float64) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	g, ok := pm.gauges[name]
+	if !ok {
+		g = prometheus.NewGauge(prometheus.GaugeOpts{Namespace: Namespace, Name: name, Help: "Current " + name})
+		pm.register(g)
+		pm.gauges[name] = g
+	}
+	g.Set(value)
+}
+
+// RecordDuration adds the duration to the histogram with the given name
+func (pm *PrometheusMetrics) RecordDuration(name string, d time.Duration) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	h, ok := pm.histograms[name]
+	if !ok {
+		h = prometheus.NewHistogram(prometheus.HistogramOpts{Namespace: Namespace, Name: name + "_seconds", Help: "Distribution of " + name, Buckets: prometheus.DefBuckets})
+		pm.register(h)
+		pm.histograms[name] = h
+	}
+	h.Observe(d.Seconds())
+}
+
+// register registers the collector, logging rather than panicking if it cannot be, since a metric should never take the node down
+func (pm *PrometheusMetrics) register(c prometheus.Collector) {
+	if err := pm.registry.Register(c); err != nil {
+		slog.Error("Could not register metric", "error", err)
+	}
+}
+
+// Handler returns an http.Handler which serves the metrics in the Prometheus text format
+func (pm *PrometheusMetrics) Handler() http.Handler {
+	return promhttp.HandlerFor(pm.registry, promhttp.HandlerOpts{})
+}
+
+// MetricsServer serves the metrics of a PrometheusMetrics at /metrics
+type MetricsServer struct {
+	server *http.Server
+	port   int
+}
+
+// Serve starts serving the metrics at /metrics on the given port
+func (pm *PrometheusMetrics) Serve(port int) (*MetricsServer, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", pm.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server stopped", "error", err)
+		}
+	}()
+	ms := &MetricsServer{server: server, port: listener.Addr().(*net.TCPAddr).Port}
+	slog.Info("Serving metrics", "url", ms.Url())
+	return ms, nil
+}
+
+// Url returns the url the metrics are served at. If the server was started on port 0, the url contains the port it was assigned.
+func (ms *MetricsServer) Url() string {
+	return fmt.Sprintf("http://127.0.0.1:%d/metrics", ms.port)
+}
+
+// Close stops serving metrics
+func (ms *MetricsServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return ms.server.Shutdown(ctx)
+}
@@ -1,18 +1,23 @@
 package node
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"github.com/statechannels/go-nitro/node"
 	"github.com/statechannels/go-nitro/node/engine"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
 	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/types"
 
 	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
 )
 
-func InitializeNode(chainOpts chainservice.ChainOpts, storeOpts store.StoreOpts, messageOpts p2pms.MessageOpts) (*node.Node, *store.Store, *p2pms.P2PMessageService, chainservice.ChainService, error) {
+// InitializeNode starts a node with the given chain, store and message options, which records its metrics to the metricsApi unless it is nil.
+// If allowedPeers is not empty, the node drops the messages of any other peer.
+func InitializeNode(chainOpts chainservice.ChainOpts, storeOpts store.StoreOpts, messageOpts p2pms.MessageOpts, allowedPeers []types.Address, metricsApi engine.MetricsApi) (*node.Node, *store.Store, *p2pms.P2PMessageService, chainservice.ChainService, error) {
 	ourStore, err := store.NewStore(storeOpts)
 	if err != nil {
 		return nil, nil, nil, nil, err
@@ -26,7 +31,7 @@ func InitializeNode(chainOpts chainservice.ChainOpts, storeOpts store.StoreOpts,
 	// gets passed as an argument when creating NewEthChainService
 	storeBlockNum, err := ourStore.GetLastBlockNumSeen()
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return nil, nil, nil, nil, errors.Join(err, messageService.Close(), ourStore.Close())
 	}
 	if storeBlockNum > chainOpts.ChainStartBlock {
 		chainOpts.ChainStartBlock = storeBlockNum
@@ -35,14 +40,26 @@ func InitializeNode(chainOpts chainservice.ChainOpts, storeOpts store.StoreOpts,
 	slog.Info("Initializing chain service...")
 	ourChain, err := chainservice.NewEthChainService(chainOpts)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		// The store's files would otherwise stay open, and its last writes unsynced, until the process exits
+		return nil, nil, nil, nil, errors.Join(err, messageService.Close(), ourStore.Close())
+	}
+
+	var ms messageservice.MessageService = messageService
+	if len(allowedPeers) > 0 {
+		var counter messageservice.Counter
+		if metricsApi != nil {
+			counter = metricsApi
+		}
+		ms = messageservice.NewAllowlistMessageService(messageService, counter, allowedPeers...)
 	}
 
 	node := node.New(
-		messageService,
+		ms,
 		ourChain,
 		ourStore,
 		&engine.PermissivePolicy{},
+		metricsApi,
+		nil,
 	)
 
 	return &node, &ourStore, messageService, ourChain, nil
@@ -6,28 +6,37 @@ import (
 	"log/slog"
 
 	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
 	"github.com/statechannels/go-nitro/rpc"
 	"github.com/statechannels/go-nitro/rpc/transport"
 	httpTransport "github.com/statechannels/go-nitro/rpc/transport/http"
 	"github.com/statechannels/go-nitro/rpc/transport/nats"
+	"github.com/statechannels/go-nitro/rpc/transport/ws"
 )
 
-func InitializeRpcServer(node *node.Node, rpcPort int, useNats bool, cert *tls.Certificate) (*rpc.RpcServer, error) {
-	var transport transport.Responder
+// InitializeRpcServer starts an rpc server for the node over the given transport type, which records its metrics to the metricsApi unless it is nil.
+func InitializeRpcServer(node *node.Node, rpcPort int, transportType transport.TransportType, cert *tls.Certificate, metricsApi engine.MetricsApi) (*rpc.RpcServer, error) {
+	var responder transport.Responder
 	var err error
 
-	if useNats {
+	switch transportType {
+	case transport.Nats:
 		slog.Info("Initializing NATS RPC transport...")
-		transport, err = nats.NewNatsTransportAsServer(rpcPort)
-	} else {
+		responder, err = nats.NewNatsTransportAsServer(rpcPort, nats.DefaultMaxMessageSize)
+	case transport.Http:
 		slog.Info("Initializing Http RPC transport...")
-		transport, err = httpTransport.NewHttpTransportAsServer(fmt.Sprint(rpcPort), cert)
+		responder, err = httpTransport.NewHttpTransportAsServer(fmt.Sprint(rpcPort), cert)
+	case transport.Ws:
+		slog.Info("Initializing WebSocket RPC transport...")
+		responder, err = ws.NewWsTransportAsServer(fmt.Sprint(rpcPort), cert)
+	default:
+		err = fmt.Errorf("unknown transport type %v", transportType)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	rpcServer, err := rpc.NewRpcServer(node, transport)
+	rpcServer, err := rpc.NewRpcServerWithMetrics(node, responder, metricsApi)
 	if err != nil {
 		return nil, err
 	}
@@ -9,12 +9,15 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/internal/testactors"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/rand"
 )
 
 // Copied from https://github.com/benbjohnson/testing
@@ -126,3 +129,34 @@ func GenerateTempStoreFolder() (dataFolder string, cleanup func()) {
 
 	return
 }
+
+// DestroyOnCleanup destroys the store, deleting its data, once the test has finished
+func DestroyOnCleanup(t testing.TB, s interface{ Destroy() error }) {
+	t.Cleanup(func() {
+		if err := s.Destroy(); err != nil {
+			t.Errorf("could not destroy the store: %v", err)
+		}
+	})
+}
+
+// SeedEnvVar names the environment variable which fixes the seed returned by Seed and NewRand, to replay a failing test run
+const SeedEnvVar = "NITRO_TEST_SEED"
+
+// Seed returns the seed of a test run, which is read from SeedEnvVar if it is set, and is otherwise based on the current time.
+func Seed() int64 {
+	seed := time.Now().UnixNano()
+	if fixed, ok := os.LookupEnv(SeedEnvVar); ok {
+		var err error
+		seed, err = strconv.ParseInt(fixed, 10, 64)
+		if err != nil {
+			panic(fmt.Errorf("invalid %s: %w", SeedEnvVar, err))
+		}
+	}
+	return seed
+}
+
+// NewRand returns a random number generator seeded by Seed, along with its seed.
+func NewRand() (rand.Generator, int64) {
+	seed := Seed()
+	return rand.NewSeeded(seed), seed
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"crypto/tls"
+	"fmt"
 	"log"
 	"log/slog"
+	"math/big"
 	"os"
 	"os/signal"
 	"strings"
@@ -11,11 +13,15 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/metrics"
 	"github.com/statechannels/go-nitro/internal/node"
 	"github.com/statechannels/go-nitro/internal/rpc"
+	"github.com/statechannels/go-nitro/node/engine"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
 	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
 	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	"github.com/statechannels/go-nitro/types"
 	"github.com/urfave/cli/v2"
 	"github.com/urfave/cli/v2/altsrc"
 )
@@ -27,9 +33,11 @@ func main() {
 		// Connectivity
 		CONNECTIVITY_CATEGORY = "Connectivity:"
 		USE_NATS              = "usenats"
+		USE_WEBSOCKET         = "usewebsocket"
 		CHAIN_URL             = "chainurl"
 		CHAIN_START_BLOCK     = "chainstartblock"
 		CHAIN_AUTH_TOKEN      = "chainauthtoken"
+		MAX_FEE_PER_GAS       = "maxfeepergas"
 		NA_ADDRESS            = "naaddress"
 		VPA_ADDRESS           = "vpaaddress"
 		CA_ADDRESS            = "caaddress"
@@ -38,6 +46,7 @@ func main() {
 		RPC_PORT              = "rpcport"
 		GUI_PORT              = "guiport"
 		BOOT_PEERS            = "bootpeers"
+		ALLOWED_PEERS         = "allowedpeers"
 
 		// Keys
 		KEYS_CATEGORY = "Keys:"
@@ -53,11 +62,16 @@ func main() {
 		TLS_CATEGORY      = "TLS:"
 		TLS_CERT_FILEPATH = "tlscertfilepath"
 		TLS_KEY_FILEPATH  = "tlskeyfilepath"
+
+		// Metrics
+		METRICS_CATEGORY = "Metrics:"
+		ENABLE_METRICS   = "enablemetrics"
+		METRICS_PORT     = "metricsport"
 	)
-	var pkString, chainUrl, chainAuthToken, naAddress, vpaAddress, caAddress, chainPk, durableStoreFolder, bootPeers, publicIp string
-	var msgPort, rpcPort, guiPort int
-	var chainStartBlock uint64
-	var useNats, useDurableStore bool
+	var pkString, chainUrl, chainAuthToken, naAddress, vpaAddress, caAddress, chainPk, durableStoreFolder, bootPeers, allowedPeers, publicIp string
+	var msgPort, rpcPort, guiPort, metricsPort int
+	var chainStartBlock, maxFeePerGas uint64
+	var useNats, useWebsocket, useDurableStore, enableMetrics bool
 
 	var tlsCertFilepath, tlsKeyFilepath string
 
@@ -80,6 +94,13 @@ func main() {
 			Category:    CONNECTIVITY_CATEGORY,
 			Destination: &useNats,
 		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        USE_WEBSOCKET,
+			Usage:       "Specifies whether to serve rpc requests and notifications over a single websocket connection, rather than http/ws. Ignored if usenats is set.",
+			Value:       false,
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &useWebsocket,
+		}),
 		altsrc.NewBoolFlag(&cli.BoolFlag{
 			Name:        USE_DURABLE_STORE,
 			Usage:       "Specifies whether to use a durable store or an in-memory store.",
@@ -126,6 +147,14 @@ func main() {
 			Destination: &chainStartBlock,
 			EnvVars:     []string{"CHAIN_START_BLOCK"},
 		}),
+		altsrc.NewUint64Flag(&cli.Uint64Flag{
+			Name:        MAX_FEE_PER_GAS,
+			Usage:       "Specifies the maximum fee in wei to pay per unit of gas for chain transactions. Zero means fees are not capped.",
+			Value:       0,
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &maxFeePerGas,
+			EnvVars:     []string{"MAX_FEE_PER_GAS"},
+		}),
 		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:        NA_ADDRESS,
 			Usage:       "Specifies the address of the nitro adjudicator contract.",
@@ -187,6 +216,13 @@ func main() {
 			Category:    CONNECTIVITY_CATEGORY,
 			Destination: &bootPeers,
 		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        ALLOWED_PEERS,
+			Usage:       "Comma-delimited list of the addresses of the only peers whose messages are accepted. If not specified, messages from any peer are accepted.",
+			Value:       "",
+			Category:    CONNECTIVITY_CATEGORY,
+			Destination: &allowedPeers,
+		}),
 		altsrc.NewStringFlag(&cli.StringFlag{
 			Name:        TLS_CERT_FILEPATH,
 			Usage:       "Filepath to the TLS certificate. If not specified, TLS will not be used with the RPC transport.",
@@ -201,6 +237,20 @@ func main() {
 			Category:    TLS_CATEGORY,
 			Destination: &tlsKeyFilepath,
 		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        ENABLE_METRICS,
+			Usage:       "Specifies whether to serve Prometheus metrics about the node and the rpc server at /metrics.",
+			Value:       false,
+			Category:    METRICS_CATEGORY,
+			Destination: &enableMetrics,
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        METRICS_PORT,
+			Usage:       "Specifies the tcp port for the metrics endpoint.",
+			Value:       6005,
+			Category:    METRICS_CATEGORY,
+			Destination: &metricsPort,
+		}),
 	}
 	app := &cli.App{
 		Name:   "go-nitro",
@@ -213,9 +263,14 @@ func main() {
 				ChainStartBlock: chainStartBlock,
 				ChainAuthToken:  chainAuthToken,
 				ChainPk:         chainPk,
-				NaAddress:       common.HexToAddress(naAddress),
-				VpaAddress:      common.HexToAddress(vpaAddress),
-				CaAddress:       common.HexToAddress(caAddress),
+				ContractAddresses: chainservice.ContractAddresses{
+					NitroAdjudicator:  common.HexToAddress(naAddress),
+					VirtualPaymentApp: common.HexToAddress(vpaAddress),
+					ConsensusApp:      common.HexToAddress(caAddress),
+				},
+			}
+			if maxFeePerGas > 0 {
+				chainOpts.Fees.MaxFeePerGas = new(big.Int).SetUint64(maxFeePerGas)
 			}
 
 			storeOpts := store.StoreOpts{
@@ -229,6 +284,16 @@ func main() {
 				peerSlice = strings.Split(bootPeers, ",")
 			}
 
+			var allowedSlice []types.Address
+			if allowedPeers != "" {
+				for _, a := range strings.Split(allowedPeers, ",") {
+					if !common.IsHexAddress(a) {
+						return fmt.Errorf("invalid allowed peer address %q", a)
+					}
+					allowedSlice = append(allowedSlice, common.HexToAddress(a))
+				}
+			}
+
 			messageOpts := p2pms.MessageOpts{
 				PkBytes:   common.Hex2Bytes(pkString),
 				Port:      msgPort,
@@ -238,7 +303,18 @@ func main() {
 
 			logging.SetupDefaultLogger(os.Stdout, slog.LevelDebug)
 
-			node, _, _, _, err := node.InitializeNode(chainOpts, storeOpts, messageOpts)
+			var metricsApi engine.MetricsApi
+			if enableMetrics {
+				prometheusMetrics := metrics.NewPrometheusMetrics()
+				metricsServer, err := prometheusMetrics.Serve(metricsPort)
+				if err != nil {
+					return err
+				}
+				defer metricsServer.Close()
+				metricsApi = prometheusMetrics
+			}
+
+			node, _, _, _, err := node.InitializeNode(chainOpts, storeOpts, messageOpts, allowedSlice, metricsApi)
 			if err != nil {
 				return err
 			}
@@ -251,7 +327,13 @@ func main() {
 				}
 			}
 
-			rpcServer, err := rpc.InitializeRpcServer(node, rpcPort, useNats, &cert)
+			rpcTransport := transport.Http
+			if useNats {
+				rpcTransport = transport.Nats
+			} else if useWebsocket {
+				rpcTransport = transport.Ws
+			}
+			rpcServer, err := rpc.InitializeRpcServer(node, rpcPort, rpcTransport, &cert, metricsApi)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,284 @@
+// Package clienttest provides a harness for integration testing applications built on go-nitro nodes.
+//
+// A Cluster runs several nodes in one process, connected by an in-memory message broker and sharing a mock chain:
+//
+//	cluster, err := clienttest.NewCluster(3)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer cluster.Cleanup()
+//
+//	ledgerId, err := cluster.OpenLedgerChannel(0, 1, types.Address{}, 1000)
+package clienttest
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+// DefaultTimeout is how long a Cluster waits for an objective to complete, unless configured WithTimeout.
+const DefaultTimeout = 10 * time.Second
+
+var (
+	ErrTimeout     = errors.New("timed out waiting for objective")
+	ErrNoSuchNode  = errors.New("no such node in cluster")
+	ErrNotManual   = errors.New("deposits are only settled by a cluster with manual funding")
+	ErrInvalidSize = errors.New("a cluster needs at least one node")
+)
+
+// Option configures a Cluster.
+type Option func(*config)
+
+type config struct {
+	messageDelay  time.Duration
+	policy        func(i int) engine.PolicyMaker
+	keys          [][]byte
+	durable       bool
+	seed          *int64
+	manualFunding bool
+	timeout       time.Duration
+	logFile       string
+	logLevel      slog.Level
+}
+
+// WithMessageDelay delays each message between the nodes by a random duration with the given mean.
+func WithMessageDelay(mean time.Duration) Option {
+	return func(c *config) { c.messageDelay = mean }
+}
+
+// WithPolicy gives the i-th node the policy returned by newPolicy, in place of a permissive policy.
+func WithPolicy(newPolicy func(i int) engine.PolicyMaker) Option {
+	return func(c *config) { c.policy = newPolicy }
+}
+
+// WithPrivateKeys gives the nodes the supplied private keys, in order, in place of freshly generated ones.
+// Nodes beyond the supplied keys are given generated keys.
+func WithPrivateKeys(keys ...[]byte) Option {
+	return func(c *config) { c.keys = keys }
+}
+
+// WithDurableStores backs the nodes with durable stores in a temporary folder, which is removed by Cleanup, in place of memory stores.
+func WithDurableStores() Option {
+	return func(c *config) { c.durable = true }
+}
+
+// WithSeed makes each node draw its nonces from its own generator, seeded from seed and the node's index, so that a run can be replayed.
+func WithSeed(seed int64) Option {
+	return func(c *config) { c.seed = &seed }
+}
+
+// WithManualFunding makes the chain record deposits without acting on them.
+// The deposits are then credited, and DepositedEvents broadcast, when the test calls SettleDeposits.
+func WithManualFunding() Option {
+	return func(c *config) { c.manualFunding = true }
+}
+
+// WithTimeout sets how long the cluster waits for an objective to complete.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) { c.timeout = timeout }
+}
+
+// WithLogFile writes the nodes' logs to the named file, at the given level.
+func WithLogFile(filename string, level slog.Level) Option {
+	return func(c *config) {
+		c.logFile = filename
+		c.logLevel = level
+	}
+}
+
+// Member is a node of the cluster, along with its identity and store.
+type Member struct {
+	Node       *node.Node
+	PrivateKey []byte
+	Address    types.Address
+	Store      store.Store
+}
+
+// Cluster is a set of nodes which message each other through a shared broker, and fund their channels on a shared mock chain.
+type Cluster struct {
+	Members []Member
+	Chain   *chainservice.MockChain
+	Broker  messageservice.Broker
+
+	manualFunding bool
+	timeout       time.Duration
+	settled       int                               // the number of submitted transactions settled by SettleDeposits
+	holdings      map[types.Destination]types.Funds // the holdings credited by SettleDeposits
+	dataFolder    string
+}
+
+// NewCluster starts n interconnected nodes. The caller should defer Cleanup to stop them.
+func NewCluster(n int, opts ...Option) (*Cluster, error) {
+	if n < 1 {
+		return nil, ErrInvalidSize
+	}
+	cfg := config{
+		policy:  func(int) engine.PolicyMaker { return &engine.PermissivePolicy{} },
+		timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.logFile != "" {
+		logging.SetupDefaultFileLogger(cfg.logFile, cfg.logLevel)
+	}
+
+	c := &Cluster{
+		Broker:        messageservice.NewBroker(),
+		manualFunding: cfg.manualFunding,
+		timeout:       cfg.timeout,
+		holdings:      map[types.Destination]types.Funds{},
+	}
+	if cfg.manualFunding {
+		c.Chain = chainservice.NewManualMockChain()
+	} else {
+		c.Chain = chainservice.NewMockChain()
+	}
+	if cfg.durable {
+		dataFolder, err := os.MkdirTemp("", "nitro-cluster-*")
+		if err != nil {
+			return nil, err
+		}
+		c.dataFolder = dataFolder
+	}
+
+	for i := 0; i < n; i++ {
+		var pk []byte
+		if i < len(cfg.keys) {
+			pk = cfg.keys[i]
+		} else {
+			pk, _ = crypto.GeneratePrivateKeyAndAddress()
+		}
+		address := crypto.GetAddressFromSecretKeyBytes(pk)
+
+		var s store.Store
+		if cfg.durable {
+			var err error
+			s, err = store.NewDurableStore(pk, c.dataFolder, buntdb.Config{})
+			if err != nil {
+				c.Cleanup()
+				return nil, fmt.Errorf("could not create store for node %d: %w", i, err)
+			}
+		} else {
+			s = store.NewMemStore(pk)
+		}
+
+		ms := messageservice.NewTestMessageService(address, c.Broker, cfg.messageDelay)
+		cs := chainservice.NewMockChainService(c.Chain, address)
+		nd := node.New(ms, cs, s, cfg.policy(i), nil, nil)
+		if cfg.seed != nil {
+			nd.SetRandomness(rand.NewSeeded(rand.DeriveSeed(*cfg.seed, fmt.Sprint("node ", i))))
+		}
+		c.Members = append(c.Members, Member{Node: &nd, PrivateKey: pk, Address: address, Store: s})
+	}
+	return c, nil
+}
+
+// Cleanup stops every node of the cluster, and removes any durable stores.
+func (c *Cluster) Cleanup() {
+	for _, m := range c.Members {
+		if err := m.Node.Close(); err != nil {
+			slog.Error("could not close node", "address", m.Address, "error", err)
+		}
+	}
+	if c.dataFolder != "" {
+		if err := os.RemoveAll(c.dataFolder); err != nil {
+			slog.Error("could not remove durable stores", "folder", c.dataFolder, "error", err)
+		}
+	}
+}
+
+// Node returns the i-th node of the cluster.
+func (c *Cluster) Node(i int) *node.Node {
+	return c.Members[i].Node
+}
+
+// Address returns the address of the i-th node of the cluster.
+func (c *Cluster) Address(i int) types.Address {
+	return c.Members[i].Address
+}
+
+// WaitForObjective blocks until the objective has completed on each of the given nodes, or the cluster's timeout elapses.
+func (c *Cluster) WaitForObjective(id protocols.ObjectiveId, nodes ...int) error {
+	deadline := time.After(c.timeout)
+	for _, i := range nodes {
+		if i < 0 || i >= len(c.Members) {
+			return fmt.Errorf("%w: %d", ErrNoSuchNode, i)
+		}
+		select {
+		case <-c.Node(i).ObjectiveCompleteChan(id):
+		case <-deadline:
+			return fmt.Errorf("%w %s on node %d", ErrTimeout, id, i)
+		}
+	}
+	return nil
+}
+
+// OpenLedgerChannel opens a ledger channel between nodes a and b, into which each deposits amount of the asset, and returns its id.
+// With manual funding, the objective only completes once the deposits are settled, so use CreateLedgerChannel and SettleDeposits instead.
+func (c *Cluster) OpenLedgerChannel(a, b int, asset types.Address, amount uint64) (types.Destination, error) {
+	response, err := c.CreateLedgerChannel(a, b, asset, amount)
+	if err != nil {
+		return types.Destination{}, err
+	}
+	if err := c.WaitForObjective(response.Id, a, b); err != nil {
+		return types.Destination{}, err
+	}
+	return response.ChannelId, nil
+}
+
+// CreateLedgerChannel asks node a to open a ledger channel with node b, into which each deposits amount of the asset, without waiting for it to open.
+func (c *Cluster) CreateLedgerChannel(a, b int, asset types.Address, amount uint64) (directfund.ObjectiveResponse, error) {
+	if a < 0 || a >= len(c.Members) || b < 0 || b >= len(c.Members) {
+		return directfund.ObjectiveResponse{}, fmt.Errorf("%w: %d or %d", ErrNoSuchNode, a, b)
+	}
+	exit := outcome.Exit{outcome.SingleAssetExit{
+		Asset: asset,
+		Allocations: outcome.Allocations{
+			outcome.Allocation{Destination: types.AddressToDestination(c.Address(a)), Amount: new(big.Int).SetUint64(amount)},
+			outcome.Allocation{Destination: types.AddressToDestination(c.Address(b)), Amount: new(big.Int).SetUint64(amount)},
+		},
+	}}
+	return c.Node(a).CreateLedgerChannel(c.Address(b), 0, exit)
+}
+
+// SettleDeposits simulates the chain mining the deposits submitted since it was last called.
+// Each deposit is credited to its channel in a new block, and broadcast to the nodes as DepositedEvents.
+// It returns the number of deposits settled.
+func (c *Cluster) SettleDeposits() (int, error) {
+	if !c.manualFunding {
+		return 0, ErrNotManual
+	}
+	txs := c.Chain.SubmittedTransactions()
+	settled := 0
+	for _, tx := range txs[c.settled:] {
+		deposit, ok := tx.(protocols.DepositTransaction)
+		if !ok {
+			continue
+		}
+		h := c.holdings[deposit.ChannelId()].Add(deposit.Deposit)
+		c.holdings[deposit.ChannelId()] = h
+		c.Chain.EmitDeposit(deposit.ChannelId(), h, c.Chain.AdvanceBlock())
+		settled++
+	}
+	c.settled = len(txs)
+	return settled, nil
+}
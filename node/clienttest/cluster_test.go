@@ -0,0 +1,108 @@
+package clienttest_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/clienttest"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestCluster(t *testing.T) {
+	cluster, err := clienttest.NewCluster(3, clienttest.WithSeed(testhelpers.Seed()), clienttest.WithDurableStores())
+	testhelpers.Ok(t, err)
+	defer cluster.Cleanup()
+
+	// Node 1 is the intermediary between nodes 0 and 2
+	for _, peer := range []int{0, 2} {
+		ledgerId, err := cluster.OpenLedgerChannel(peer, 1, types.Address{}, 1000)
+		testhelpers.Ok(t, err)
+		ledger, err := cluster.Node(peer).GetLedgerChannel(ledgerId)
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, query.Open, ledger.Status)
+	}
+
+	paymentOutcome := outcome.Exit{outcome.SingleAssetExit{
+		Allocations: outcome.Allocations{
+			outcome.Allocation{Destination: types.AddressToDestination(cluster.Address(0)), Amount: big.NewInt(100)},
+			outcome.Allocation{Destination: types.AddressToDestination(cluster.Address(2)), Amount: big.NewInt(0)},
+		},
+	}}
+	response, err := cluster.Node(0).CreatePaymentChannel([]types.Address{cluster.Address(1)}, cluster.Address(2), 0, paymentOutcome)
+	testhelpers.Ok(t, err)
+	testhelpers.Ok(t, cluster.WaitForObjective(response.Id, 0, 1, 2))
+
+	if _, err := cluster.SettleDeposits(); !errors.Is(err, clienttest.ErrNotManual) {
+		t.Errorf("expected %v, got %v", clienttest.ErrNotManual, err)
+	}
+}
+
+func TestClusterWithManualFunding(t *testing.T) {
+	cluster, err := clienttest.NewCluster(2, clienttest.WithManualFunding(), clienttest.WithTimeout(200*time.Millisecond))
+	testhelpers.Ok(t, err)
+	defer cluster.Cleanup()
+
+	response, err := cluster.CreateLedgerChannel(0, 1, types.Address{}, 1000)
+	testhelpers.Ok(t, err)
+
+	// The channel stays unfunded until its deposits are settled
+	_, err = cluster.Chain.WaitForTransactions(1, clienttest.DefaultTimeout)
+	testhelpers.Ok(t, err)
+	if err := cluster.WaitForObjective(response.Id, 0, 1); !errors.Is(err, clienttest.ErrTimeout) {
+		t.Fatalf("expected %v before the deposits are settled, got %v", clienttest.ErrTimeout, err)
+	}
+
+	// Each participant deposits in turn, after seeing the deposit of the one before
+	for i := 1; i <= 2; i++ {
+		_, err = cluster.Chain.WaitForTransactions(i, clienttest.DefaultTimeout)
+		testhelpers.Ok(t, err)
+		settled, err := cluster.SettleDeposits()
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 1, settled)
+	}
+	testhelpers.Ok(t, cluster.WaitForObjective(response.Id, 0, 1))
+
+	ledger, err := cluster.Node(1).GetLedgerChannel(response.ChannelId)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, query.Open, ledger.Status)
+}
+
+func TestSeededClustersAreReproducible(t *testing.T) {
+	seed := testhelpers.Seed()
+	channelIds := make([]types.Destination, 2)
+	for i := range channelIds {
+		cluster, err := clienttest.NewCluster(3, clienttest.WithSeed(seed), clienttest.WithPrivateKeys(testactors.Alice.PrivateKey, testactors.Irene.PrivateKey, testactors.Bob.PrivateKey))
+		testhelpers.Ok(t, err)
+		defer cluster.Cleanup()
+
+		// Node 1 opens a ledger channel with each of the others concurrently, so the order in which it draws its nonces varies
+		errs := make(chan error, 2)
+		for _, peer := range []int{0, 2} {
+			go func(peer int) {
+				_, err := cluster.OpenLedgerChannel(1, peer, types.Address{}, 1000)
+				errs <- err
+			}(peer)
+		}
+		for j := 0; j < 2; j++ {
+			testhelpers.Ok(t, <-errs)
+		}
+
+		response, err := cluster.CreateLedgerChannel(0, 2, types.Address{}, 1000)
+		testhelpers.Ok(t, err)
+		channelIds[i] = response.ChannelId
+	}
+	// Node 0's nonce is drawn from its own generator, so it is unaffected by the order in which node 1 drew its nonces
+	testhelpers.Equals(t, channelIds[0], channelIds[1])
+}
+
+func TestClusterNeedsANode(t *testing.T) {
+	if _, err := clienttest.NewCluster(0); !errors.Is(err, clienttest.ErrInvalidSize) {
+		t.Fatalf("expected %v, got %v", clienttest.ErrInvalidSize, err)
+	}
+}
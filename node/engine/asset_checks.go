@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var ErrCannotReadCode = errors.New("chain service cannot read the code deployed at an address")
+
+// SetAssetCodeCheck decides whether channels proposed by counterparties are rejected unless each of their assets, other than the native token, has code deployed on chain.
+// Without the check, a counterparty may propose a channel in an asset which does not exist, which the node would try to fund.
+// It is disabled by default, so that offline chain services need not read code. It returns ErrCannotReadCode if a chain service cannot do so.
+// It is safe to call while the engine is running.
+func (e *Engine) SetAssetCodeCheck(enabled bool) error {
+	if enabled {
+		for _, cs := range e.chains.all() {
+			if _, ok := cs.(chainservice.CodeReader); !ok {
+				return fmt.Errorf("%w: %T", ErrCannotReadCode, cs)
+			}
+		}
+	}
+	e.checkAssetCode.Store(enabled)
+	return nil
+}
+
+// assetsDeployed decides whether each asset of the channel that o funds has code deployed on the channel's chain, and if not, gives the reason for rejecting o.
+// Every objective is approved if the check is disabled, as are objectives which do not fund a channel.
+func (e *Engine) assetsDeployed(o protocols.Objective) (bool, string) {
+	if !e.checkAssetCode.Load() {
+		return true, ""
+	}
+	c, ok := fundedChannel(o)
+	if !ok {
+		return true, ""
+	}
+	reader, ok := e.chains.forObjective(o).(chainservice.CodeReader)
+	if !ok {
+		return false, ErrCannotReadCode.Error()
+	}
+	for _, sae := range c.PreFundState().Outcome {
+		if sae.Asset == (types.Address{}) {
+			continue
+		}
+		deployed, err := reader.HasCode(sae.Asset)
+		if err != nil {
+			return false, fmt.Sprintf("could not check asset %s: %v", sae.Asset, err)
+		}
+		if !deployed {
+			return false, fmt.Sprintf("asset %s has no code deployed", sae.Asset)
+		}
+	}
+	return true, ""
+}
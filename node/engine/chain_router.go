@@ -0,0 +1,226 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/statechannels/go-nitro/internal/safesync"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var ErrUnknownChain = errors.New("no chain service for chain")
+
+// chainRouter holds a chain service for each chain the engine operates channels on, keyed by chain id.
+//
+// The chain of each channel is recorded when the objective funding it is created: a ledger channel is on the chain named by the
+// request or payload which proposed it, and a virtual channel is on the chain of the ledger channels funding it.
+// Channels on the default chain are not recorded, so the default chain must stay the same across restarts.
+type chainRouter struct {
+	defaultChain   chainservice.ChainService
+	defaultChainId string // the id of the default chain, or empty if the chain service could not report it
+	others         []routedChain
+	channelChains  *safesync.Map[string] // the id of the chain of each channel which is not on the default chain
+	store          store.MultiChainStore
+	wg             *sync.WaitGroup // tracks the goroutines forwarding the events of the other chains
+}
+
+// routedChain is a chain service other than the default, along with the id of its chain
+type routedChain struct {
+	chainId string
+	chain   chainservice.ChainService
+}
+
+// chainEvent is an event from one of the chains other than the default
+type chainEvent struct {
+	chainservice.Event
+	chainId string
+}
+
+// newSingleChainRouter returns a chainRouter which routes everything to the supplied chain service
+func newSingleChainRouter(chain chainservice.ChainService) *chainRouter {
+	r := &chainRouter{defaultChain: chain, channelChains: &safesync.Map[string]{}, wg: &sync.WaitGroup{}}
+	if chainId, err := chain.GetChainId(); err == nil {
+		r.defaultChainId = chainId.String()
+	}
+	return r
+}
+
+// newChainRouter returns a chainRouter for the supplied chain services, the first of which is the default.
+// It fails if two chain services are connected to the same chain, since routing would be ambiguous.
+func newChainRouter(chains []chainservice.ChainService) (*chainRouter, error) {
+	if len(chains) == 0 {
+		return nil, errors.New("at least one chain service is required")
+	}
+
+	r := &chainRouter{defaultChain: chains[0], channelChains: &safesync.Map[string]{}, wg: &sync.WaitGroup{}}
+	seen := map[string]bool{}
+	for i, cs := range chains {
+		chainId, err := cs.GetChainId()
+		if err != nil {
+			return nil, fmt.Errorf("could not get chain id from chain service: %w", err)
+		}
+		id := chainId.String()
+		if seen[id] {
+			return nil, fmt.Errorf("more than one chain service for chain %s", chainId)
+		}
+		seen[id] = true
+		if i == 0 {
+			r.defaultChainId = id
+		} else {
+			r.others = append(r.others, routedChain{chainId: id, chain: cs})
+		}
+	}
+	return r, nil
+}
+
+// loadChannelChains restores the chains of the channels recorded in the store, and records the chains of later channels there
+func (r *chainRouter) loadChannelChains(s store.MultiChainStore) error {
+	r.store = s
+	chains, err := s.GetChannelChains()
+	if err != nil {
+		return err
+	}
+	for channelId, chainId := range chains {
+		r.channelChains.Store(channelId.String(), chainId)
+	}
+	return nil
+}
+
+// all returns every chain service, starting with the default
+func (r *chainRouter) all() []chainservice.ChainService {
+	all := []chainservice.ChainService{r.defaultChain}
+	for _, other := range r.others {
+		all = append(all, other.chain)
+	}
+	return all
+}
+
+// forChain returns the chain service for the chain with the given id. The empty id names the default chain.
+func (r *chainRouter) forChain(chainId string) (chainservice.ChainService, error) {
+	if chainId == "" || chainId == r.defaultChainId {
+		return r.defaultChain, nil
+	}
+	for _, other := range r.others {
+		if other.chainId == chainId {
+			return other.chain, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %s", ErrUnknownChain, chainId)
+}
+
+// chainOf returns the id of the chain of the channel, which is empty if the channel is on a default chain whose id is not known
+func (r *chainRouter) chainOf(channelId types.Destination) string {
+	if chainId, ok := r.channelChains.Load(channelId.String()); ok {
+		return chainId
+	}
+	return r.defaultChainId
+}
+
+// forChannel returns the chain service for the chain of the channel with the supplied id
+func (r *chainRouter) forChannel(channelId types.Destination) chainservice.ChainService {
+	if cs, err := r.forChain(r.chainOf(channelId)); err == nil {
+		return cs
+	}
+	return r.defaultChain
+}
+
+// forObjective returns the chain service for the chain of the channel the objective owns
+func (r *chainRouter) forObjective(o protocols.Objective) chainservice.ChainService {
+	return r.forChannel(o.OwnsChannel())
+}
+
+// assign records that the channel is on the chain with the given id. The empty id names the default chain.
+func (r *chainRouter) assign(channelId types.Destination, chainId string) error {
+	if _, err := r.forChain(chainId); err != nil {
+		return err
+	}
+	if chainId == "" || chainId == r.defaultChainId {
+		return nil
+	}
+	if r.store != nil {
+		if err := r.store.SetChannelChain(channelId, chainId); err != nil {
+			return fmt.Errorf("could not record the chain of channel %s: %w", channelId, err)
+		}
+	}
+	r.channelChains.Store(channelId.String(), chainId)
+	return nil
+}
+
+// assignFundedChannel records the chain of the channel that a directfund or virtualfund objective funds.
+// A directfund objective's channel is on the chain with the given id, and a virtualfund objective's channel is on the chain of its ledger channels.
+func (r *chainRouter) assignFundedChannel(o protocols.Objective, chainId string) error {
+	switch o := o.(type) {
+	case *directfund.Objective:
+		return r.assign(o.OwnsChannel(), chainId)
+	case *virtualfund.Objective:
+		ledgers := []types.Destination{}
+		for _, ledger := range []*virtualfund.Connection{o.ToMyLeft, o.ToMyRight} {
+			if ledger != nil {
+				ledgers = append(ledgers, ledger.Channel.Id)
+			}
+		}
+		ledgerChain := r.chainOf(ledgers[0])
+		for _, ledger := range ledgers[1:] {
+			if r.chainOf(ledger) != ledgerChain {
+				return fmt.Errorf("the ledger channels funding virtual channel %s are on different chains", o.OwnsChannel())
+			}
+		}
+		return r.assign(o.OwnsChannel(), ledgerChain)
+	default:
+		return nil
+	}
+}
+
+// annotate names the chain of a directfund objective's channel in the payloads it sends, so that its counterparty opens the channel on the same chain.
+// The payloads of an engine operating on a single chain are left unchanged.
+func (r *chainRouter) annotate(o protocols.Objective, msgs []protocols.Message) {
+	if _, ok := o.(*directfund.Objective); !ok || len(r.others) == 0 {
+		return
+	}
+	chainId := r.chainOf(o.OwnsChannel())
+	for _, msg := range msgs {
+		for i := range msg.ObjectivePayloads {
+			if msg.ObjectivePayloads[i].ObjectiveId == o.Id() {
+				msg.ObjectivePayloads[i].ChainId = chainId
+			}
+		}
+	}
+}
+
+// forwardEvents merges the event feeds of the non-default chain services into out, until ctx is cancelled.
+// The goroutines forwarding the events are joined by wait.
+func (r *chainRouter) forwardEvents(ctx context.Context, out chan<- chainEvent) {
+	for _, other := range r.others {
+		r.wg.Add(1)
+		go func(chainId string, feed <-chan chainservice.Event) {
+			defer r.wg.Done()
+			for {
+				select {
+				case event, ok := <-feed:
+					if !ok {
+						return
+					}
+					select {
+					case out <- chainEvent{Event: event, chainId: chainId}:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(other.chainId, other.chain.EventFeed())
+	}
+}
+
+// wait blocks until the goroutines started by forwardEvents have returned
+func (r *chainRouter) wait() {
+	r.wg.Wait()
+}
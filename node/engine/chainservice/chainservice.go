@@ -2,6 +2,7 @@
 package chainservice // import "github.com/statechannels/go-nitro/node/chainservice"
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -12,6 +13,30 @@ import (
 	"github.com/statechannels/go-nitro/types"
 )
 
+// ContractAddresses holds the addresses of the contracts a chain service interacts with.
+type ContractAddresses struct {
+	NitroAdjudicator  types.Address
+	VirtualPaymentApp types.Address
+	ConsensusApp      types.Address
+}
+
+// Validate returns an error if any of the addresses is unset, or if the app addresses are the same.
+func (ca ContractAddresses) Validate() error {
+	if ca.NitroAdjudicator == (types.Address{}) {
+		return fmt.Errorf("nitro adjudicator address must be set")
+	}
+	if ca.VirtualPaymentApp == (types.Address{}) {
+		return fmt.Errorf("virtual payment app address must be set")
+	}
+	if ca.ConsensusApp == (types.Address{}) {
+		return fmt.Errorf("consensus app address must be set")
+	}
+	if ca.VirtualPaymentApp == ca.ConsensusApp {
+		return fmt.Errorf("virtual payment app address and consensus app address cannot be the same: %s", ca.VirtualPaymentApp.String())
+	}
+	return nil
+}
+
 // Event dictates which methods all chain events must implement
 type Event interface {
 	ChannelID() types.Destination
@@ -78,10 +103,44 @@ func (ce ConcludedEvent) String() string {
 	return "Channel " + ce.channelID.String() + " concluded at Block " + fmt.Sprint(ce.blockNum)
 }
 
+var (
+	ErrTransactionReverted = errors.New("chain transaction reverted")
+	ErrTransactionNotMined = errors.New("chain transaction was not mined")
+)
+
+// TransactionMinedEvent reports the outcome of a transaction submitted by the chain service, once its receipt is available.
+// If no receipt is available within RECEIPT_TIMEOUT, it is reported with a block number of zero and Success false.
+type TransactionMinedEvent struct {
+	commonEvent
+	Transaction protocols.ChainTransaction // the chain transaction that the submission was made for
+	TxHash      common.Hash
+	Success     bool
+}
+
+// Err returns nil if the transaction succeeded, or why it did not
+func (tme TransactionMinedEvent) Err() error {
+	switch {
+	case tme.Success:
+		return nil
+	case tme.blockNum == 0:
+		return ErrTransactionNotMined
+	default:
+		return ErrTransactionReverted
+	}
+}
+
+func (tme TransactionMinedEvent) String() string {
+	if tme.blockNum == 0 {
+		return "Transaction " + tme.TxHash.String() + " for channel " + tme.channelID.String() + " was not mined"
+	}
+	return "Transaction " + tme.TxHash.String() + " for channel " + tme.channelID.String() + " mined at Block " + fmt.Sprint(tme.blockNum) + " with success " + fmt.Sprint(tme.Success)
+}
+
 type ChallengeRegisteredEvent struct {
 	commonEvent
 	candidate           state.VariablePart
 	candidateSignatures []state.Signature
+	finalizesAt         uint64
 }
 
 // NewChallengeRegisteredEvent constructs a ChallengeRegisteredEvent
@@ -91,6 +150,7 @@ func NewChallengeRegisteredEvent(
 	txIndex uint,
 	variablePart state.VariablePart,
 	sigs []state.Signature,
+	finalizesAt uint64,
 ) ChallengeRegisteredEvent {
 	return ChallengeRegisteredEvent{
 		commonEvent: commonEvent{channelID: channelId, blockNum: blockNum, txIndex: txIndex},
@@ -100,9 +160,17 @@ func NewChallengeRegisteredEvent(
 			TurnNum: variablePart.TurnNum,
 			IsFinal: variablePart.IsFinal,
 		}, candidateSignatures: sigs,
+		finalizesAt: finalizesAt,
 	}
 }
 
+// FinalizesAt returns when the challenged channel becomes finalizable, unless the challenge is cleared first: the time the challenge was
+// registered plus the channel's challenge duration. On Ethereum chains this is a unix timestamp in seconds, as computed by the adjudicator.
+// The MockChain has no clock, and measures challenge durations in blocks.
+func (cr ChallengeRegisteredEvent) FinalizesAt() uint64 {
+	return cr.finalizesAt
+}
+
 // StateHash returns the statehash stored on chain at the time of the ChallengeRegistered Event firing.
 func (cr ChallengeRegisteredEvent) StateHash(fp state.FixedPart) (common.Hash, error) {
 	return state.StateFromFixedAndVariablePart(fp, cr.candidate).Hash()
@@ -127,7 +195,7 @@ func (cr ChallengeRegisteredEvent) SignedState(fp state.FixedPart) (state.Signed
 }
 
 func (cr ChallengeRegisteredEvent) String() string {
-	return "CHALLENGE registered for Channel " + cr.channelID.String() + " at Block " + fmt.Sprint(cr.blockNum)
+	return "CHALLENGE registered for Channel " + cr.channelID.String() + " at Block " + fmt.Sprint(cr.blockNum) + " finalizing at " + fmt.Sprint(cr.finalizesAt)
 }
 
 func NewDepositedEvent(channelId types.Destination, blockNum uint64, txIndex uint, assetAddress common.Address, nowHeld *big.Int) DepositedEvent {
@@ -138,8 +206,18 @@ func NewAllocationUpdatedEvent(channelId types.Destination, blockNum uint64, txI
 	return AllocationUpdatedEvent{commonEvent{channelId, blockNum, txIndex}, assetAndAmount{AssetAddress: assetAddress, AssetAmount: assetAmount}}
 }
 
-// todo implement other event types
-// ChallengeCleared
+// ChallengeClearedEvent is emitted when a challenge of the channel is cleared, e.g. by a checkpoint, before it finalizes the channel
+type ChallengeClearedEvent struct {
+	commonEvent
+}
+
+func NewChallengeClearedEvent(channelId types.Destination, blockNum uint64, txIndex uint) ChallengeClearedEvent {
+	return ChallengeClearedEvent{commonEvent{channelID: channelId, blockNum: blockNum, txIndex: txIndex}}
+}
+
+func (cc ChallengeClearedEvent) String() string {
+	return "CHALLENGE cleared for Channel " + cc.channelID.String() + " at Block " + fmt.Sprint(cc.blockNum)
+}
 
 // ChainEventHandler describes an objective that can handle chain events
 type ChainEventHandler interface {
@@ -159,6 +237,17 @@ type ChainService interface {
 	GetChainId() (*big.Int, error)
 	// GetLastConfirmedBlockNum returns the highest blockNum that satisfies the chainservice's REQUIRED_BLOCK_CONFIRMATIONS
 	GetLastConfirmedBlockNum() uint64
+	// Connected returns false while the chain service has lost its connection to the chain, during which chain events may be delayed
+	Connected() bool
+	// WatchChannel registers a channel this node participates in. Implementations may drop events for channels which have not been registered.
+	WatchChannel(channelId types.Destination)
 	// Close closes the ChainService
 	Close() error
 }
+
+// CodeReader is implemented by chain services which can read whether code is deployed at an address.
+// Chain services for offline contexts need not implement it, in which case the assets of proposed channels cannot be checked.
+type CodeReader interface {
+	// HasCode returns true if a contract is deployed at the address
+	HasCode(address types.Address) (bool, error)
+}
@@ -0,0 +1,39 @@
+package chainservice
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestContractAddressesValidate(t *testing.T) {
+	valid := ContractAddresses{
+		NitroAdjudicator:  types.Address(common.HexToAddress(`a`)),
+		VirtualPaymentApp: types.Address(common.HexToAddress(`b`)),
+		ConsensusApp:      types.Address(common.HexToAddress(`c`)),
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected addresses to be valid, got %v", err)
+	}
+
+	noAdjudicator := valid
+	noAdjudicator.NitroAdjudicator = types.Address{}
+	noVirtualPaymentApp := valid
+	noVirtualPaymentApp.VirtualPaymentApp = types.Address{}
+	noConsensusApp := valid
+	noConsensusApp.ConsensusApp = types.Address{}
+	sameApps := valid
+	sameApps.ConsensusApp = sameApps.VirtualPaymentApp
+
+	for name, addresses := range map[string]ContractAddresses{
+		"missing adjudicator":         noAdjudicator,
+		"missing virtual payment app": noVirtualPaymentApp,
+		"missing consensus app":       noConsensusApp,
+		"same app addresses":          sameApps,
+	} {
+		if err := addresses.Validate(); err == nil {
+			t.Errorf("%s: expected addresses to be invalid", name)
+		}
+	}
+}
@@ -2,10 +2,12 @@ package chainservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -14,7 +16,9 @@ import (
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/statechannels/go-nitro/channel/state"
+	nc "github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/safesync"
 	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
 	Token "github.com/statechannels/go-nitro/node/engine/chainservice/erc20"
 	chainutils "github.com/statechannels/go-nitro/node/engine/chainservice/utils"
@@ -23,13 +27,12 @@ import (
 )
 
 type ChainOpts struct {
-	ChainUrl        string
-	ChainStartBlock uint64
-	ChainAuthToken  string
-	ChainPk         string
-	NaAddress       common.Address
-	VpaAddress      common.Address
-	CaAddress       common.Address
+	ChainUrl          string
+	ChainStartBlock   uint64
+	ChainAuthToken    string
+	ChainPk           string
+	ContractAddresses ContractAddresses
+	Fees              FeeOpts
 }
 
 var (
@@ -63,20 +66,27 @@ type ethChain interface {
 
 // eventTracker holds on to events in memory and dispatches an event after required number of confirmations
 type EthChainService struct {
-	chain                    ethChain
-	na                       *NitroAdjudicator.NitroAdjudicator
-	naAddress                common.Address
-	consensusAppAddress      common.Address
-	virtualPaymentAppAddress common.Address
-	txSigner                 *bind.TransactOpts
-	out                      chan Event
-	logger                   *slog.Logger
-	ctx                      context.Context
-	cancel                   context.CancelFunc
-	wg                       *sync.WaitGroup
-	eventTracker             *eventTracker
-	eventSub                 ethereum.Subscription
-	newBlockSub              ethereum.Subscription
+	chain        ethChain
+	na           *NitroAdjudicator.NitroAdjudicator
+	addresses    ContractAddresses
+	txSigner     *bind.TransactOpts
+	chainId      *big.Int // the id of the chain that transactions must be signed for
+	out          chan Event
+	logger       *slog.Logger
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           *sync.WaitGroup
+	eventTracker *eventTracker
+	eventSub     ethereum.Subscription
+	newBlockSub  ethereum.Subscription
+	nonces       *nonceManager
+	gasOracle    GasOracle
+	maxFeePerGas *big.Int            // caps the fees of dynamic fee transactions, if set
+	watched      *safesync.Map[bool] // the channels whose events are delivered on the EventFeed
+	deployed     *safesync.Map[bool] // the addresses which are known to have code deployed
+
+	eventSubDown    atomic.Bool // whether the subscription to adjudicator events has dropped and not yet been re-established
+	newBlockSubDown atomic.Bool // whether the subscription to new blocks has dropped and not yet been re-established
 }
 
 // MAX_QUERY_BLOCK_RANGE is the maximum range of blocks we query for events at once.
@@ -95,6 +105,15 @@ const RESUB_INTERVAL = 15 * time.Second
 // REQUIRED_BLOCK_CONFIRMATIONS is how many blocks must be mined before an emitted event is processed
 const REQUIRED_BLOCK_CONFIRMATIONS = 2
 
+// RECEIPT_POLL_INTERVAL is how often the receipt of a submitted transaction is polled for
+var RECEIPT_POLL_INTERVAL = time.Second
+
+// RECEIPT_TIMEOUT is how long a submitted transaction may go without a receipt before it is reported as not mined
+var RECEIPT_TIMEOUT = 10 * time.Minute
+
+// CODE_READ_TIMEOUT is how long reading the code deployed at an address may take before it is abandoned
+var CODE_READ_TIMEOUT = 5 * time.Second
+
 // MAX_EPOCHS is the maximum range of old epochs we can query with a single "FilterLogs" request
 // This is a restriction enforced by the rpc provider
 const MAX_EPOCHS = 60480
@@ -104,8 +123,8 @@ func NewEthChainService(chainOpts ChainOpts) (ChainService, error) {
 	if chainOpts.ChainPk == "" {
 		return nil, fmt.Errorf("chainpk must be set")
 	}
-	if chainOpts.VpaAddress == chainOpts.CaAddress {
-		return nil, fmt.Errorf("virtual payment app address and consensus app address cannot be the same: %s", chainOpts.VpaAddress.String())
+	if err := chainOpts.ContractAddresses.Validate(); err != nil {
+		return nil, err
 	}
 
 	ethClient, txSigner, err := chainutils.ConnectToChain(
@@ -118,18 +137,18 @@ func NewEthChainService(chainOpts ChainOpts) (ChainService, error) {
 		panic(err)
 	}
 
-	na, err := NitroAdjudicator.NewNitroAdjudicator(chainOpts.NaAddress, ethClient)
+	na, err := NitroAdjudicator.NewNitroAdjudicator(chainOpts.ContractAddresses.NitroAdjudicator, ethClient)
 	if err != nil {
 		panic(err)
 	}
 
-	return newEthChainService(ethClient, chainOpts.ChainStartBlock, na, chainOpts.NaAddress, chainOpts.CaAddress, chainOpts.VpaAddress, txSigner)
+	return newEthChainService(ethClient, chainOpts.ChainStartBlock, na, chainOpts.ContractAddresses, txSigner, chainOpts.Fees)
 }
 
 // newEthChainService constructs a chain service that submits transactions to a NitroAdjudicator
 // and listens to events from an eventSource
 func newEthChainService(chain ethChain, startBlock uint64, na *NitroAdjudicator.NitroAdjudicator,
-	naAddress, caAddress, vpaAddress common.Address, txSigner *bind.TransactOpts,
+	addresses ContractAddresses, txSigner *bind.TransactOpts, fees FeeOpts,
 ) (*EthChainService, error) {
 	ctx, cancelCtx := context.WithCancel(context.Background())
 
@@ -137,7 +156,34 @@ func newEthChainService(chain ethChain, startBlock uint64, na *NitroAdjudicator.
 	tracker := NewEventTracker(startBlock)
 
 	// Use a buffered channel so we don't have to worry about blocking on writing to the channel.
-	ecs := EthChainService{chain, na, naAddress, caAddress, vpaAddress, txSigner, make(chan Event, 10), logger, ctx, cancelCtx, &sync.WaitGroup{}, tracker, nil, nil}
+	if fees.Oracle == nil {
+		fees.Oracle = nodeGasOracle{chain}
+	}
+
+	chainId, err := chain.ChainID(ctx)
+	if err != nil {
+		cancelCtx()
+		return nil, fmt.Errorf("could not get chain id: %w", err)
+	}
+
+	ecs := EthChainService{
+		chain:        chain,
+		na:           na,
+		addresses:    addresses,
+		txSigner:     txSigner,
+		chainId:      chainId,
+		out:          make(chan Event, 10),
+		logger:       logger,
+		ctx:          ctx,
+		cancel:       cancelCtx,
+		wg:           &sync.WaitGroup{},
+		eventTracker: tracker,
+		nonces:       newNonceManager(chain, txSigner.From),
+		gasOracle:    fees.Oracle,
+		maxFeePerGas: fees.MaxFeePerGas,
+		watched:      &safesync.Map[bool]{},
+		deployed:     &safesync.Map[bool]{},
+	}
 	errChan, newBlockChan, eventChan, eventQuery, err := ecs.subscribeForLogs()
 	if err != nil {
 		return nil, err
@@ -182,7 +228,7 @@ func (ecs *EthChainService) checkForMissedEvents(startBlock uint64) error {
 		query := ethereum.FilterQuery{
 			FromBlock: big.NewInt(int64(currentStart)),
 			ToBlock:   big.NewInt(int64(currentEnd)),
-			Addresses: []common.Address{ecs.naAddress},
+			Addresses: []common.Address{ecs.addresses.NitroAdjudicator},
 			Topics:    [][]common.Hash{topicsToWatch},
 		}
 
@@ -222,34 +268,95 @@ func (ecs *EthChainService) listenForErrors(errChan <-chan error) {
 	}
 }
 
-// defaultTxOpts returns transaction options suitable for most transaction submissions
-func (ecs *EthChainService) defaultTxOpts() *bind.TransactOpts {
-	return &bind.TransactOpts{
+// defaultTxOpts returns transaction options suitable for most transaction submissions, with the next nonce for the account.
+// Unless the signer specifies its own gas price or fees, transactions pay dynamic fees suggested by the gas oracle, capped at maxFeePerGas.
+// It must be called with ecs.nonces.mu held.
+func (ecs *EthChainService) defaultTxOpts() (*bind.TransactOpts, error) {
+	txOpts := &bind.TransactOpts{
 		From:      ecs.txSigner.From,
-		Nonce:     ecs.txSigner.Nonce,
 		Signer:    ecs.txSigner.Signer,
 		GasFeeCap: ecs.txSigner.GasFeeCap,
 		GasTipCap: ecs.txSigner.GasTipCap,
 		GasLimit:  ecs.txSigner.GasLimit,
 		GasPrice:  ecs.txSigner.GasPrice,
 	}
+	if txOpts.GasPrice == nil && txOpts.GasFeeCap == nil {
+		maxFee, tip, err := ecs.gasOracle.SuggestFees(ecs.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not suggest transaction fees: %w", err)
+		}
+		// Without dynamic fee support, the bindings fall back to the node's suggested gas price
+		if maxFee != nil {
+			txOpts.GasFeeCap, txOpts.GasTipCap = capFees(maxFee, tip, ecs.maxFeePerGas)
+		}
+	}
+
+	nonce, err := ecs.nonces.next(ecs.ctx)
+	if err != nil {
+		return nil, err
+	}
+	txOpts.Nonce = nonce
+	return txOpts, nil
 }
 
 // SendTransaction sends the transaction and blocks until it has been submitted.
+// Submissions are made one at a time, so that each chain transaction is assigned the next nonce for the account.
 func (ecs *EthChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	ecs.nonces.mu.Lock()
+	defer ecs.nonces.mu.Unlock()
+
+	err := ecs.sendTransaction(tx)
+	if err != nil {
+		// A nonce may have been reserved for a transaction which was never submitted
+		ecs.nonces.resync()
+	}
+	return err
+}
+
+// submit sends a chain transaction built by the supplied contract binding call for tx, and tracks it until it is mined.
+// It must be called with ecs.nonces.mu held.
+func (ecs *EthChainService) submit(tx protocols.ChainTransaction, send func(*bind.TransactOpts) (*ethTypes.Transaction, error), configure ...func(*bind.TransactOpts)) error {
+	txOpts, err := ecs.defaultTxOpts()
+	if err != nil {
+		return err
+	}
+	for _, c := range configure {
+		c(txOpts)
+	}
+	// Sign without sending, so that the transaction can be checked before it is broadcast
+	txOpts.NoSend = true
+	ethTx, err := send(txOpts)
+	if err != nil {
+		return newTransactionError(txOpts, err)
+	}
+	err = nc.VerifyTransactionChain(ethTx, ecs.chainId)
+	if err != nil {
+		return newTransactionError(txOpts, err)
+	}
+	err = ecs.chain.SendTransaction(ecs.ctx, ethTx)
+	if err != nil {
+		return newTransactionError(txOpts, err)
+	}
+	ecs.logger.Debug("submitted chain transaction", "tx-hash", ethTx.Hash(), "nonce", ethTx.Nonce(), "max-fee-per-gas", ethTx.GasFeeCap(), "max-priority-fee-per-gas", ethTx.GasTipCap())
+	p := ecs.nonces.track(ethTx)
+	ecs.wg.Add(1)
+	go ecs.awaitReceipt(tx, p)
+	return nil
+}
+
+func (ecs *EthChainService) sendTransaction(tx protocols.ChainTransaction) error {
 	switch tx := tx.(type) {
 	case protocols.DepositTransaction:
 		for tokenAddress, amount := range tx.Deposit {
-			txOpts := ecs.defaultTxOpts()
 			ethTokenAddress := common.Address{}
-			if tokenAddress == ethTokenAddress {
-				txOpts.Value = amount
-			} else {
+			if tokenAddress != ethTokenAddress {
 				tokenTransactor, err := Token.NewTokenTransactor(tokenAddress, ecs.chain)
 				if err != nil {
 					return err
 				}
-				_, err = tokenTransactor.Approve(ecs.defaultTxOpts(), ecs.naAddress, amount)
+				err = ecs.submit(tx, func(txOpts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+					return tokenTransactor.Approve(txOpts, ecs.addresses.NitroAdjudicator, amount)
+				})
 				if err != nil {
 					return err
 				}
@@ -262,7 +369,13 @@ func (ecs *EthChainService) SendTransaction(tx protocols.ChainTransaction) error
 				return err
 			}
 
-			_, err = ecs.na.Deposit(txOpts, tokenAddress, tx.ChannelId(), holdings, amount)
+			err = ecs.submit(tx, func(txOpts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+				return ecs.na.Deposit(txOpts, tokenAddress, tx.ChannelId(), holdings, amount)
+			}, func(txOpts *bind.TransactOpts) {
+				if tokenAddress == ethTokenAddress {
+					txOpts.Value = amount
+				}
+			})
 			if err != nil {
 				return err
 			}
@@ -279,23 +392,158 @@ func (ecs *EthChainService) SendTransaction(tx protocols.ChainTransaction) error
 			VariablePart: nitroVariablePart,
 			Sigs:         nitroSignatures,
 		}
-		_, err := ecs.na.ConcludeAndTransferAllAssets(ecs.defaultTxOpts(), nitroFixedPart, candidate)
-		return err
+		return ecs.submit(tx, func(txOpts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.ConcludeAndTransferAllAssets(txOpts, nitroFixedPart, candidate)
+		})
 	case protocols.ChallengeTransaction:
 		fp, candidate := NitroAdjudicator.ConvertSignedStateToFixedPartAndSignedVariablePart(tx.Candidate)
 		proof := NitroAdjudicator.ConvertSignedStatesToProof(tx.Proof)
 		challengerSig := NitroAdjudicator.ConvertSignature(tx.ChallengerSig)
-		_, err := ecs.na.Challenge(ecs.defaultTxOpts(), fp, proof, candidate, challengerSig)
-		return err
+		return ecs.submit(tx, func(txOpts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.Challenge(txOpts, fp, proof, candidate, challengerSig)
+		})
 	default:
 		return fmt.Errorf("unexpected transaction type %T", tx)
 	}
 }
 
+// resubmitStuckTransactions resubmits transactions which have not been mined within STUCK_TRANSACTION_TIMEOUT,
+// with the same nonce and a higher gas price.
+// The chain is queried and the replacements sent without holding the nonce manager's lock, so that new submissions are not held up.
+func (ecs *EthChainService) resubmitStuckTransactions() {
+	stuck, err := ecs.checkPendingTransactions()
+	if err != nil {
+		ecs.logger.Warn("could not check for stuck transactions", "error", err)
+		return
+	}
+	for _, s := range stuck {
+		bumped := bumpGasPrice(s.latest)
+		if ecs.maxFeePerGas != nil && bumped.GasFeeCap().Cmp(ecs.maxFeePerGas) > 0 {
+			ecs.logger.Warn("not resubmitting stuck transaction, since a higher fee would exceed the fee cap", "nonce", s.latest.Nonce(), "max-fee-per-gas", s.latest.GasFeeCap(), "fee-cap", ecs.maxFeePerGas)
+			continue
+		}
+		replacement, err := ecs.txSigner.Signer(ecs.txSigner.From, bumped)
+		if err != nil {
+			ecs.logger.Warn("could not sign replacement transaction", "nonce", s.latest.Nonce(), "error", err)
+			continue
+		}
+		// A failed resubmission usually means an earlier submission has just been mined, which is picked up on the next block
+		err = ecs.chain.SendTransaction(ecs.ctx, replacement)
+		if err != nil {
+			ecs.logger.Warn("could not resubmit stuck transaction", "nonce", replacement.Nonce(), "error", err)
+			continue
+		}
+		ecs.logger.Info("resubmitted stuck transaction with a higher gas price", "nonce", replacement.Nonce(), "tx-hash", replacement.Hash())
+		ecs.nonces.mu.Lock()
+		s.p.replaced(replacement)
+		ecs.nonces.mu.Unlock()
+	}
+}
+
+// checkPendingTransactions forgets the pending transactions which have been mined, and returns those which have been pending for longer than STUCK_TRANSACTION_TIMEOUT.
+// The nonce manager's lock is only held to copy the pending transactions and to forget the mined ones, not while the chain is queried.
+func (ecs *EthChainService) checkPendingTransactions() ([]pendingSubmissions, error) {
+	ecs.nonces.mu.Lock()
+	pending := ecs.nonces.snapshot()
+	ecs.nonces.mu.Unlock()
+
+	stuck, mined, err := ecs.nonces.stuck(ecs.ctx, pending, STUCK_TRANSACTION_TIMEOUT)
+	if err != nil {
+		return nil, err
+	}
+
+	ecs.nonces.mu.Lock()
+	ecs.nonces.forget(mined)
+	ecs.nonces.mu.Unlock()
+	return stuck, nil
+}
+
+// awaitReceipt polls for the receipt of any submission of a pending transaction until one is available or RECEIPT_TIMEOUT elapses,
+// and reports the outcome on the event feed as a TransactionMinedEvent.
+func (ecs *EthChainService) awaitReceipt(tx protocols.ChainTransaction, p *pendingTransaction) {
+	defer ecs.wg.Done()
+
+	deadline := time.Now().Add(RECEIPT_TIMEOUT)
+	for {
+		receipt, err := ecs.receipt(p)
+		if err != nil {
+			ecs.logger.Warn("could not fetch transaction receipt", "nonce", p.nonce, "error", err)
+		}
+		if receipt != nil {
+			ecs.reportMined(TransactionMinedEvent{
+				commonEvent: commonEvent{channelID: tx.ChannelId(), blockNum: receipt.BlockNumber.Uint64(), txIndex: receipt.TransactionIndex},
+				Transaction: tx,
+				TxHash:      receipt.TxHash,
+				Success:     receipt.Status == ethTypes.ReceiptStatusSuccessful,
+			})
+			return
+		}
+		if time.Now().After(deadline) {
+			latest, _ := ecs.submissions(p)
+			ecs.logger.Warn("chain transaction was not mined in time", "nonce", p.nonce, "tx-hash", latest.Hash(), "timeout", RECEIPT_TIMEOUT)
+			ecs.reportMined(TransactionMinedEvent{commonEvent: commonEvent{channelID: tx.ChannelId()}, Transaction: tx, TxHash: latest.Hash()})
+			return
+		}
+		if !ecs.sleep(RECEIPT_POLL_INTERVAL) {
+			return
+		}
+	}
+}
+
+// submissions returns the latest submission of the pending transaction, and the hashes of every submission so far
+func (ecs *EthChainService) submissions(p *pendingTransaction) (*ethTypes.Transaction, []common.Hash) {
+	// The pending transaction is replaced by gas bumps under the nonce manager's lock, even once the nonce manager has forgotten it
+	ecs.nonces.mu.Lock()
+	defer ecs.nonces.mu.Unlock()
+	return p.latest, append([]common.Hash{}, p.hashes...)
+}
+
+// receipt returns the receipt of whichever submission of the pending transaction was mined, or nil if none has been.
+// If the latest submission has been dropped from the mempool, it is resubmitted.
+func (ecs *EthChainService) receipt(p *pendingTransaction) (*ethTypes.Receipt, error) {
+	latest, hashes := ecs.submissions(p)
+
+	for _, hash := range hashes {
+		receipt, err := ecs.chain.TransactionReceipt(ecs.ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+	}
+
+	_, _, err := ecs.chain.TransactionByHash(ecs.ctx, latest.Hash())
+	if !errors.Is(err, ethereum.NotFound) {
+		return nil, err
+	}
+	ecs.logger.Info("chain transaction was dropped from the mempool, resubmitting it", "nonce", latest.Nonce(), "tx-hash", latest.Hash())
+	return nil, ecs.chain.SendTransaction(ecs.ctx, latest)
+}
+
+// reportMined sends the event on the event feed, unless the chain service is closed in the meantime
+func (ecs *EthChainService) reportMined(event TransactionMinedEvent) {
+	ecs.logger.Debug("reporting the outcome of a chain transaction", "tx-hash", event.TxHash, "block-num", event.BlockNum(), "success", event.Success)
+	select {
+	case ecs.out <- event:
+	case <-ecs.ctx.Done():
+	}
+}
+
 // dispatchChainEvents takes in a collection of event logs from the chain
 // and dispatches events to the out channel
 func (ecs *EthChainService) dispatchChainEvents(logs []ethTypes.Log) error {
 	for _, l := range logs {
+		// Every adjudicator event is indexed by channel id
+		if len(l.Topics) < 2 {
+			continue
+		}
+		channelId := types.Destination(l.Topics[1])
+		if _, ok := ecs.watched.Load(channelId.String()); !ok {
+			ecs.logger.Debug("dropping event for a channel this node does not participate in", logging.WithChannelIdAttribute(channelId))
+			continue
+		}
+
 		switch l.Topics[0] {
 		case depositedTopic:
 			ecs.logger.Debug("Processing Deposited event")
@@ -351,10 +599,15 @@ func (ecs *EthChainService) dispatchChainEvents(logs []ethTypes.Log) error {
 				Outcome: NitroAdjudicator.ConvertBindingsExitToExit(cr.Candidate.VariablePart.Outcome),
 				TurnNum: cr.Candidate.VariablePart.TurnNum.Uint64(),
 				IsFinal: cr.Candidate.VariablePart.IsFinal,
-			}, NitroAdjudicator.ConvertBindingsSignaturesToSignatures(cr.Candidate.Sigs))
+			}, NitroAdjudicator.ConvertBindingsSignaturesToSignatures(cr.Candidate.Sigs), cr.FinalizesAt.Uint64())
 			ecs.out <- event
 		case challengeClearedTopic:
-			ecs.logger.Info("Ignoring Challenge Cleared event")
+			cc, err := ecs.na.ParseChallengeCleared(l)
+			if err != nil {
+				return fmt.Errorf("error in ParseChallengeCleared: %w", err)
+			}
+			event := NewChallengeClearedEvent(cc.ChannelId, l.BlockNumber, l.TxIndex)
+			ecs.out <- event
 		default:
 			ecs.logger.Info("Ignoring unknown chain event topic", "topic", l.Topics[0].String())
 
@@ -372,49 +625,53 @@ func (ecs *EthChainService) listenForEventLogs(errorChan chan<- error, eventChan
 			return
 
 		case err := <-ecs.eventSub.Err():
-			// Use helper function block to ensure "defer" statement is called for all exit paths
-			func() {
-				latestBlockNum := ecs.GetLastConfirmedBlockNum()
+			if err != nil {
+				ecs.logger.Warn("error in chain event subscription: " + err.Error())
+				ecs.eventSub.Unsubscribe()
+				ecs.eventSubDown.Store(true)
+			} else {
+				ecs.logger.Warn("chain event subscription closed")
+			}
 
-				ecs.eventTracker.mu.Lock()
-				defer ecs.eventTracker.mu.Unlock()
+			// Events emitted while the subscription was down are replayed from the last confirmed block
+			latestBlockNum := ecs.GetLastConfirmedBlockNum()
 
+			// Use exponential backoff loop to attempt to re-establish subscription.
+			// For websocket endpoints, subscribing again re-dials a dropped connection.
+			resubscribed := false
+			for backoffTime := MIN_BACKOFF_TIME; backoffTime < MAX_BACKOFF_TIME; backoffTime *= 2 {
+				eventSub, err := ecs.chain.SubscribeFilterLogs(ecs.ctx, eventQuery, eventChan)
 				if err != nil {
-					ecs.logger.Warn("error in chain event subscription: " + err.Error())
-					ecs.eventSub.Unsubscribe()
-				} else {
-					ecs.logger.Warn("chain event subscription closed")
-				}
-
-				resubscribed := false // Flag to indicate whether resubscription was successful
-
-				// Use exponential backoff loop to attempt to re-establish subscription
-				for backoffTime := MIN_BACKOFF_TIME; backoffTime < MAX_BACKOFF_TIME; backoffTime *= 2 {
-					eventSub, err := ecs.chain.SubscribeFilterLogs(ecs.ctx, eventQuery, eventChan)
-					if err != nil {
-						ecs.logger.Warn("failed to resubscribe to chain events, retrying", "backoffTime", backoffTime)
-						time.Sleep(backoffTime)
-						continue
-					}
-
-					ecs.eventSub = eventSub
-					ecs.logger.Debug("resubscribed to chain events")
-					err = ecs.checkForMissedEvents(latestBlockNum)
-					if err != nil {
-						errorChan <- fmt.Errorf("subscribeFilterLogs failed during checkForMissedEvents: " + err.Error())
+					ecs.logger.Warn("failed to resubscribe to chain events, retrying", "backoffTime", backoffTime)
+					if !ecs.sleep(backoffTime) {
+						ecs.wg.Done()
 						return
 					}
-
-					resubscribed = true
-					break
+					continue
 				}
 
-				if !resubscribed {
-					ecs.logger.Error("subscribeFilterLogs failed to resubscribe")
-					errorChan <- fmt.Errorf("subscribeFilterLogs failed to resubscribe")
-					return
-				}
-			}()
+				ecs.eventSub = eventSub
+				ecs.logger.Debug("resubscribed to chain events")
+				resubscribed = true
+				break
+			}
+
+			if !resubscribed {
+				ecs.logger.Error("subscribeFilterLogs failed to resubscribe")
+				errorChan <- fmt.Errorf("subscribeFilterLogs failed to resubscribe")
+				ecs.wg.Done()
+				return
+			}
+
+			ecs.eventTracker.mu.Lock()
+			err = ecs.checkForMissedEvents(latestBlockNum)
+			ecs.eventTracker.mu.Unlock()
+			if err != nil {
+				errorChan <- fmt.Errorf("subscribeFilterLogs failed during checkForMissedEvents: " + err.Error())
+				ecs.wg.Done()
+				return
+			}
+			ecs.eventSubDown.Store(false)
 
 		case <-time.After(RESUB_INTERVAL):
 			// Due to https://github.com/ethereum/go-ethereum/issues/23845 we can't rely on a long running subscription.
@@ -443,29 +700,40 @@ func (ecs *EthChainService) listenForNewBlocks(errorChan chan<- error, newBlockC
 			} else {
 				ecs.logger.Warn("chain new block subscription closed")
 			}
+			ecs.newBlockSubDown.Store(true)
 
 			// Use exponential backoff loop to attempt to re-establish subscription
+			resubscribed := false
 			for backoffTime := MIN_BACKOFF_TIME; backoffTime < MAX_BACKOFF_TIME; backoffTime *= 2 {
 				newBlockSub, err := ecs.chain.SubscribeNewHead(ecs.ctx, newBlockChan)
 				if err != nil {
-					errorChan <- fmt.Errorf("subscribeNewHead failed to resubscribe: %w", err)
-					time.Sleep(backoffTime)
+					ecs.logger.Warn("failed to resubscribe to chain new blocks, retrying", "backoffTime", backoffTime, "error", err)
+					if !ecs.sleep(backoffTime) {
+						ecs.wg.Done()
+						return
+					}
 					continue
 				}
 
 				ecs.newBlockSub = newBlockSub
 				ecs.logger.Debug("resubscribed to chain new blocks")
+				resubscribed = true
 				break
 			}
 
-			ecs.logger.Error("subscribeNewHead failed to resubscribe")
-			errorChan <- fmt.Errorf("subscribeNewHead failed to resubscribe")
-			return
+			if !resubscribed {
+				ecs.logger.Error("subscribeNewHead failed to resubscribe")
+				errorChan <- fmt.Errorf("subscribeNewHead failed to resubscribe")
+				ecs.wg.Done()
+				return
+			}
+			ecs.newBlockSubDown.Store(false)
 
 		case newBlock := <-newBlockChan:
 			newBlockNum := newBlock.Number.Uint64()
 			ecs.logger.Log(ecs.ctx, logging.LevelTrace, "detected new block", "block-num", newBlockNum)
 			ecs.updateEventTracker(errorChan, &newBlockNum, nil)
+			ecs.resubmitStuckTransactions()
 		}
 	}
 }
@@ -477,6 +745,7 @@ func (ecs *EthChainService) updateEventTracker(errorChan chan<- error, blockNumb
 
 	if blockNumber != nil && *blockNumber > ecs.eventTracker.latestBlockNum {
 		ecs.eventTracker.latestBlockNum = *blockNumber
+		ecs.eventTracker.forgetOldEvents()
 	}
 
 	if chainEvent != nil {
@@ -492,7 +761,7 @@ func (ecs *EthChainService) updateEventTracker(errorChan chan<- error, blockNumb
 		// Ensure event & associated tx is still in the chain before adding to eventsToDispatch
 		oldBlock, err := ecs.chain.BlockByNumber(context.Background(), new(big.Int).SetUint64(chainEvent.BlockNumber))
 		if err != nil {
-			ecs.logger.Error("failed to fetch block: %v", err)
+			ecs.logger.Error("failed to fetch block", "error", err)
 			errorChan <- fmt.Errorf("failed to fetch block: %v", err)
 			return
 		}
@@ -518,7 +787,7 @@ func (ecs *EthChainService) updateEventTracker(errorChan chan<- error, blockNumb
 func (ecs *EthChainService) subscribeForLogs() (chan error, chan *ethTypes.Header, chan ethTypes.Log, ethereum.FilterQuery, error) {
 	// Subscribe to Adjudicator events
 	eventQuery := ethereum.FilterQuery{
-		Addresses: []common.Address{ecs.naAddress},
+		Addresses: []common.Address{ecs.addresses.NitroAdjudicator},
 		Topics:    [][]common.Hash{topicsToWatch},
 	}
 	eventChan := make(chan ethTypes.Log)
@@ -545,17 +814,35 @@ func (ecs *EthChainService) EventFeed() <-chan Event {
 }
 
 func (ecs *EthChainService) GetConsensusAppAddress() types.Address {
-	return ecs.consensusAppAddress
+	return ecs.addresses.ConsensusApp
 }
 
 func (ecs *EthChainService) GetVirtualPaymentAppAddress() types.Address {
-	return ecs.virtualPaymentAppAddress
+	return ecs.addresses.VirtualPaymentApp
 }
 
 func (ecs *EthChainService) GetChainId() (*big.Int, error) {
 	return ecs.chain.ChainID(ecs.ctx)
 }
 
+// HasCode returns true if a contract is deployed at the address, as of the latest block.
+// Addresses found to have code are remembered, so that they are not read again. Reading code takes at most CODE_READ_TIMEOUT.
+func (ecs *EthChainService) HasCode(address types.Address) (bool, error) {
+	if _, ok := ecs.deployed.Load(address.String()); ok {
+		return true, nil
+	}
+	ctx, cancel := context.WithTimeout(ecs.ctx, CODE_READ_TIMEOUT)
+	defer cancel()
+	code, err := ecs.chain.CodeAt(ctx, address, nil)
+	if err != nil {
+		return false, fmt.Errorf("could not read the code at %s: %w", address, err)
+	}
+	if len(code) > 0 {
+		ecs.deployed.Store(address.String(), true)
+	}
+	return len(code) > 0, nil
+}
+
 func (ecs *EthChainService) GetLastConfirmedBlockNum() uint64 {
 	var confirmedBlockNum uint64
 
@@ -572,6 +859,28 @@ func (ecs *EthChainService) GetLastConfirmedBlockNum() uint64 {
 	return confirmedBlockNum
 }
 
+// Connected returns false while a subscription to the chain has dropped. Chain events may be delayed until it is re-established,
+// at which point any events emitted in the meantime are replayed.
+func (ecs *EthChainService) Connected() bool {
+	return !ecs.eventSubDown.Load() && !ecs.newBlockSubDown.Load()
+}
+
+// sleep waits for d, returning false if the chain service is closed in the meantime
+func (ecs *EthChainService) sleep(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ecs.ctx.Done():
+		return false
+	}
+}
+
+// WatchChannel registers a channel this node participates in. Adjudicator events for channels which have not been registered are dropped,
+// but the outcome of every transaction the chain service submits is reported.
+func (ecs *EthChainService) WatchChannel(channelId types.Destination) {
+	ecs.watched.Store(channelId.String(), true)
+}
+
 func (ecs *EthChainService) Close() error {
 	ecs.cancel()
 	ecs.wg.Wait()
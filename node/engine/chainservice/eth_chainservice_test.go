@@ -0,0 +1,607 @@
+package chainservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	nc "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestMissedEventsAreReplayedOnStartup(t *testing.T) {
+	logging.SetupDefaultFileLogger("ethChainService.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses := ContractAddresses{
+		NitroAdjudicator:  bindings.Adjudicator.Address,
+		VirtualPaymentApp: bindings.VirtualPaymentApp.Address,
+		ConsensusApp:      bindings.ConsensusApp.Address,
+	}
+
+	// Deposit into three channels while no chain service is running, the last two in the same block
+	channelIds := []types.Destination{{1}, {2}, {3}}
+	deposit := func(channelId types.Destination, amount int64) {
+		txOpts := *ethAccounts[0]
+		txOpts.Value = big.NewInt(amount)
+		_, err := bindings.Adjudicator.Contract.Deposit(&txOpts, common.Address{}, channelId, big.NewInt(0), big.NewInt(amount))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	deposit(channelIds[0], 1)
+	sim.Commit()
+	deposit(channelIds[1], 2)
+	deposit(channelIds[2], 3)
+	sim.Commit()
+
+	// The block holding the last two deposits
+	latest, err := sim.BlockByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	laterBlockNum := latest.NumberU64()
+
+	// expectDeposits starts a chain service from startBlock, and checks that it emits deposits into the expected channels in order
+	expectDeposits := func(startBlock uint64, expected []types.Destination) {
+		cs, err := newEthChainService(sim, startBlock, bindings.Adjudicator.Contract, addresses, ethAccounts[0], FeeOpts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cs.Close()
+		for _, channelId := range channelIds {
+			cs.WatchChannel(channelId)
+		}
+
+		// Mine enough blocks for the replayed events to be confirmed
+		for i := 0; i <= REQUIRED_BLOCK_CONFIRMATIONS; i++ {
+			sim.Commit()
+		}
+
+		for i, channelId := range expected {
+			select {
+			case event := <-cs.EventFeed():
+				deposited, ok := event.(DepositedEvent)
+				if !ok {
+					t.Fatalf("expected a DepositedEvent, got %+v", event)
+				}
+				if deposited.ChannelID() != channelId {
+					t.Errorf("starting from block %d, event %d: expected a deposit into %s, got %s", startBlock, i, channelId, deposited.ChannelID())
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("starting from block %d: timed out waiting for event %d", startBlock, i)
+			}
+		}
+		select {
+		case event := <-cs.EventFeed():
+			t.Errorf("starting from block %d: unexpected event %+v", startBlock, event)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	expectDeposits(0, channelIds)
+	// Restarting from a later block only replays the events emitted since
+	expectDeposits(laterBlockNum, channelIds[1:])
+}
+
+func TestEventsForUnwatchedChannelsAreDropped(t *testing.T) {
+	logging.SetupDefaultFileLogger("ethChainService.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	defer closeChainService(t, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local, foreign := types.Destination{1}, types.Destination{2}
+	cs.WatchChannel(local)
+	feed := adjudicatorEvents(t, cs.EventFeed())
+
+	for _, channelId := range []types.Destination{foreign, local} {
+		deposit := protocols.NewDepositTransaction(channelId, types.Funds{common.Address{}: big.NewInt(1)})
+		if err := cs.SendTransaction(deposit); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case event := <-feed:
+		if event.ChannelID() != local {
+			t.Errorf("expected only events for %s to be delivered, got %+v", local, event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the deposit into the local channel")
+	}
+	select {
+	case event := <-feed:
+		t.Errorf("unexpected event %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// droppableChain wraps a simulated chain, so that tests can drop its subscriptions as if the websocket connection was lost
+type droppableChain struct {
+	SimulatedChain
+	down atomic.Bool // while down, new subscriptions are refused
+	mu   sync.Mutex
+	subs []*droppableSubscription
+}
+
+type droppableSubscription struct {
+	ethereum.Subscription
+	err  chan error
+	once sync.Once
+}
+
+func (ds *droppableSubscription) Err() <-chan error { return ds.err }
+
+func (ds *droppableSubscription) Unsubscribe() {
+	ds.Subscription.Unsubscribe()
+	ds.once.Do(func() { close(ds.err) })
+}
+
+func (dc *droppableChain) track(sub ethereum.Subscription, err error) (ethereum.Subscription, error) {
+	if err != nil {
+		return nil, err
+	}
+	ds := &droppableSubscription{Subscription: sub, err: make(chan error, 1)}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.subs = append(dc.subs, ds)
+	return ds, nil
+}
+
+func (dc *droppableChain) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- ethTypes.Log) (ethereum.Subscription, error) {
+	if dc.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return dc.track(dc.SimulatedChain.SubscribeFilterLogs(ctx, q, ch))
+}
+
+func (dc *droppableChain) SubscribeNewHead(ctx context.Context, ch chan<- *ethTypes.Header) (ethereum.Subscription, error) {
+	if dc.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return dc.track(dc.SimulatedChain.SubscribeNewHead(ctx, ch))
+}
+
+// drop fails every open subscription, and refuses new ones until the connection is restored
+func (dc *droppableChain) drop() {
+	dc.down.Store(true)
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	for _, sub := range dc.subs {
+		sub.err <- errors.New("websocket: close 1006 (abnormal closure)")
+	}
+	dc.subs = nil
+}
+
+func TestEventsMissedWhileDisconnectedAreReplayed(t *testing.T) {
+	logging.SetupDefaultFileLogger("ethChainService.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := &droppableChain{SimulatedChain: sim}
+	cs, err := newEthChainService(chain, 0, bindings.Adjudicator.Contract, ContractAddresses{
+		NitroAdjudicator:  bindings.Adjudicator.Address,
+		VirtualPaymentApp: bindings.VirtualPaymentApp.Address,
+		ConsensusApp:      bindings.ConsensusApp.Address,
+	}, ethAccounts[0], FeeOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	channelId := types.Destination{1}
+	cs.WatchChannel(channelId)
+
+	waitForConnected := func(want bool) {
+		deadline := time.After(5 * time.Second)
+		for cs.Connected() != want {
+			select {
+			case <-deadline:
+				t.Fatalf("timed out waiting for Connected() to return %t", want)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+	waitForConnected(true)
+
+	chain.drop()
+	waitForConnected(false)
+
+	// Deposit while the chain service is disconnected
+	txOpts := *ethAccounts[0]
+	txOpts.Value = big.NewInt(1)
+	_, err = bindings.Adjudicator.Contract.Deposit(&txOpts, common.Address{}, channelId, big.NewInt(0), big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	chain.down.Store(false)
+	waitForConnected(true)
+
+	// Mine enough blocks for the replayed deposit to be confirmed
+	for i := 0; i < REQUIRED_BLOCK_CONFIRMATIONS; i++ {
+		sim.Commit()
+	}
+
+	select {
+	case event := <-cs.EventFeed():
+		if _, ok := event.(DepositedEvent); !ok || event.ChannelID() != channelId {
+			t.Errorf("expected a deposit into %s, got %+v", channelId, event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the deposit made while disconnected")
+	}
+}
+
+func TestSubmittedTransactionsAreReported(t *testing.T) {
+	logging.SetupDefaultFileLogger("ethChainService.log", slog.LevelDebug)
+
+	pollInterval, timeout := RECEIPT_POLL_INTERVAL, RECEIPT_TIMEOUT
+	RECEIPT_POLL_INTERVAL, RECEIPT_TIMEOUT = 10*time.Millisecond, time.Second
+	defer func() { RECEIPT_POLL_INTERVAL, RECEIPT_TIMEOUT = pollInterval, timeout }()
+
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Use the EthChainService directly, so that blocks are only mined when the test commits them
+	cs, err := newEthChainService(sim, 0, bindings.Adjudicator.Contract, ContractAddresses{
+		NitroAdjudicator:  bindings.Adjudicator.Address,
+		VirtualPaymentApp: bindings.VirtualPaymentApp.Address,
+		ConsensusApp:      bindings.ConsensusApp.Address,
+	}, ethAccounts[0], FeeOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	expectReport := func(channelId types.Destination, wantErr error) TransactionMinedEvent {
+		t.Helper()
+		for {
+			select {
+			case event := <-cs.EventFeed():
+				mined, ok := event.(TransactionMinedEvent)
+				if !ok {
+					continue
+				}
+				if mined.ChannelID() != channelId || mined.Transaction.ChannelId() != channelId {
+					t.Fatalf("expected a report on the transaction for %s, got %s", channelId, mined)
+				}
+				if !errors.Is(mined.Err(), wantErr) {
+					t.Fatalf("expected the transaction to be reported with error %v, got %v", wantErr, mined.Err())
+				}
+				return mined
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for the transaction for %s to be reported", channelId)
+			}
+		}
+	}
+
+	t.Run("mined-success", func(t *testing.T) {
+		channelId := types.Destination{1}
+		if err := cs.SendTransaction(protocols.NewDepositTransaction(channelId, types.Funds{common.Address{}: big.NewInt(1)})); err != nil {
+			t.Fatal(err)
+		}
+		sim.Commit()
+
+		mined := expectReport(channelId, nil)
+		if !mined.Success || mined.BlockNum() == 0 {
+			t.Errorf("expected the transaction to be reported as mined successfully, got %s", mined)
+		}
+	})
+
+	t.Run("mined-reverted", func(t *testing.T) {
+		channelId := types.Destination{2}
+		deposit := protocols.NewDepositTransaction(channelId, types.Funds{common.Address{}: big.NewInt(1)})
+
+		// Claim the channel already holds funds, and skip gas estimation so that the reverting deposit is still mined
+		cs.nonces.mu.Lock()
+		err := cs.submit(deposit, func(txOpts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return cs.na.Deposit(txOpts, common.Address{}, channelId, big.NewInt(5), big.NewInt(1))
+		}, func(txOpts *bind.TransactOpts) {
+			txOpts.Value = big.NewInt(1)
+			txOpts.GasLimit = 100_000
+		})
+		cs.nonces.mu.Unlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sim.Commit()
+
+		mined := expectReport(channelId, ErrTransactionReverted)
+		if mined.BlockNum() == 0 {
+			t.Errorf("expected the reverted transaction to be reported with its block, got %s", mined)
+		}
+	})
+
+	t.Run("never-mined", func(t *testing.T) {
+		channelId := types.Destination{3}
+		if err := cs.SendTransaction(protocols.NewDepositTransaction(channelId, types.Funds{common.Address{}: big.NewInt(1)})); err != nil {
+			t.Fatal(err)
+		}
+
+		expectReport(channelId, ErrTransactionNotMined)
+	})
+}
+
+// stallingChain wraps a simulated chain, so that tests can accept a transaction into the mempool without it ever being mined
+type stallingChain struct {
+	SimulatedChain
+	stall atomic.Bool // while stalling, sent transactions are held rather than forwarded to the chain
+	mu    sync.Mutex
+	held  map[common.Hash]*ethTypes.Transaction
+}
+
+func (sc *stallingChain) SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error {
+	if !sc.stall.Load() {
+		return sc.SimulatedChain.SendTransaction(ctx, tx)
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.held[tx.Hash()] = tx
+	return nil
+}
+
+func (sc *stallingChain) TransactionByHash(ctx context.Context, hash common.Hash) (*ethTypes.Transaction, bool, error) {
+	sc.mu.Lock()
+	tx, ok := sc.held[hash]
+	sc.mu.Unlock()
+	if ok {
+		return tx, true, nil
+	}
+	return sc.SimulatedChain.TransactionByHash(ctx, hash)
+}
+
+func TestMinedReplacementsAreReported(t *testing.T) {
+	logging.SetupDefaultFileLogger("ethChainService.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := &stallingChain{SimulatedChain: sim, held: map[common.Hash]*ethTypes.Transaction{}}
+	cs, err := newEthChainService(chain, 0, bindings.Adjudicator.Contract, ContractAddresses{
+		NitroAdjudicator:  bindings.Adjudicator.Address,
+		VirtualPaymentApp: bindings.VirtualPaymentApp.Address,
+		ConsensusApp:      bindings.ConsensusApp.Address,
+	}, ethAccounts[0], FeeOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	// The original submission sits in the mempool, and is never mined
+	channelId := types.Destination{1}
+	chain.stall.Store(true)
+	if err := cs.SendTransaction(protocols.NewDepositTransaction(channelId, types.Funds{common.Address{}: big.NewInt(1)})); err != nil {
+		t.Fatal(err)
+	}
+	chain.stall.Store(false)
+
+	// Resubmit it with a higher gas price, as if it had got stuck, and mine the replacement
+	cs.nonces.mu.Lock()
+	if len(cs.nonces.pending) != 1 {
+		t.Fatalf("expected a single pending transaction, got %d", len(cs.nonces.pending))
+	}
+	var replacement *ethTypes.Transaction
+	for _, p := range cs.nonces.pending {
+		replacement, err = cs.txSigner.Signer(cs.txSigner.From, bumpGasPrice(p.latest))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sim.SendTransaction(context.Background(), replacement); err != nil {
+			t.Fatal(err)
+		}
+		p.replaced(replacement)
+	}
+	cs.nonces.mu.Unlock()
+	sim.Commit()
+
+	// Once mined, the nonce manager forgets the transaction, but its receipt must still be found
+	_, err = cs.checkPendingTransactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs.nonces.mu.Lock()
+	remaining := len(cs.nonces.pending)
+	cs.nonces.mu.Unlock()
+	if remaining != 0 {
+		t.Fatalf("expected the mined transaction to be forgotten, but %d remain pending", remaining)
+	}
+
+	for {
+		select {
+		case event := <-cs.EventFeed():
+			mined, ok := event.(TransactionMinedEvent)
+			if !ok {
+				continue
+			}
+			if !mined.Success || mined.TxHash != replacement.Hash() {
+				t.Fatalf("expected the replacement %s to be reported as mined, got %s", replacement.Hash(), mined)
+			}
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the replacement to be reported")
+		}
+	}
+}
+
+func TestTransactionsSignedForAnotherChainAreRejected(t *testing.T) {
+	logging.SetupDefaultFileLogger("ethChainService.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	chainA, chainB := big.NewInt(TEST_CHAIN_ID), big.NewInt(TEST_CHAIN_ID+1)
+
+	secretKey, address := nc.GeneratePrivateKeyAndAddress()
+	transfer := func(nonce uint64, to common.Address, value *big.Int) *ethTypes.Transaction {
+		gasPrice, err := sim.SuggestGasPrice(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ethTypes.NewTx(&ethTypes.LegacyTx{Nonce: nonce, GasPrice: gasPrice, Gas: 21000, To: &to, Value: value})
+	}
+
+	// Fund an account whose secret key the test controls
+	fundingNonce, err := sim.PendingNonceAt(ctx, ethAccounts[0].From)
+	if err != nil {
+		t.Fatal(err)
+	}
+	funding, err := ethAccounts[0].Signer(ethAccounts[0].From, transfer(fundingNonce, address, big.NewInt(1_000_000_000_000_000_000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.SendTransaction(ctx, funding); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	signedForB, err := nc.SignTransaction(transfer(0, ethAccounts[0].From, big.NewInt(1)), chainB, secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nc.VerifyTransactionChain(signedForB, chainA); !errors.Is(err, nc.ErrWrongChainId) {
+		t.Errorf("expected a transaction signed for chain %s not to verify for chain %s, got %v", chainB, chainA, err)
+	}
+	if err := sim.SendTransaction(ctx, signedForB); err == nil {
+		t.Errorf("expected a transaction signed for chain %s to be rejected by chain %s", chainB, chainA)
+	}
+
+	signedForA, err := nc.SignTransaction(transfer(0, ethAccounts[0].From, big.NewInt(1)), chainA, secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nc.VerifyTransactionChain(signedForA, chainA); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.SendTransaction(ctx, signedForA); err != nil {
+		t.Fatalf("expected a transaction signed for chain %s to be accepted, got %v", chainA, err)
+	}
+	sim.Commit()
+
+	// A chain service whose transactor signs for the wrong chain refuses to submit anything
+	wrongChainSigner, err := nc.NewTransactor(secretKey, chainB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := newEthChainService(sim, 0, bindings.Adjudicator.Contract, ContractAddresses{
+		NitroAdjudicator:  bindings.Adjudicator.Address,
+		VirtualPaymentApp: bindings.VirtualPaymentApp.Address,
+		ConsensusApp:      bindings.ConsensusApp.Address,
+	}, wrongChainSigner, FeeOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	deposit := protocols.NewDepositTransaction(types.Destination{1}, types.Funds{common.Address{}: big.NewInt(1)})
+	if err := cs.SendTransaction(deposit); !errors.Is(err, nc.ErrWrongChainId) {
+		t.Errorf("expected the deposit to be refused with %v, got %v", nc.ErrWrongChainId, err)
+	}
+	pendingNonce, err := sim.PendingNonceAt(ctx, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pendingNonce != 1 {
+		t.Errorf("expected no transaction to be broadcast, but the pending nonce is %d", pendingNonce)
+	}
+}
+
+// codeCountingChain wraps a simulated chain, counting the reads of deployed code, and never answering reads of the code at hangingAddress
+type codeCountingChain struct {
+	SimulatedChain
+	reads atomic.Int32
+}
+
+var hangingAddress = common.Address{0xff}
+
+func (cc *codeCountingChain) CodeAt(ctx context.Context, address common.Address, blockNumber *big.Int) ([]byte, error) {
+	cc.reads.Add(1)
+	if address == hangingAddress {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return cc.SimulatedChain.CodeAt(ctx, address, blockNumber)
+}
+
+func TestDeployedCodeIsRemembered(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := &codeCountingChain{SimulatedChain: sim}
+	cs, err := newEthChainService(chain, 0, bindings.Adjudicator.Contract, ContractAddresses{
+		NitroAdjudicator:  bindings.Adjudicator.Address,
+		VirtualPaymentApp: bindings.VirtualPaymentApp.Address,
+		ConsensusApp:      bindings.ConsensusApp.Address,
+	}, ethAccounts[0], FeeOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	for i := 0; i < 2; i++ {
+		deployed, err := cs.HasCode(bindings.Adjudicator.Address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !deployed {
+			t.Fatal("expected the adjudicator to have code deployed")
+		}
+	}
+	if reads := chain.reads.Load(); reads != 1 {
+		t.Errorf("expected deployed code to be read once, got %d reads", reads)
+	}
+
+	// An address without code may have code deployed later, so it is read every time
+	for i := 0; i < 2; i++ {
+		if deployed, err := cs.HasCode(common.Address{1}); err != nil || deployed {
+			t.Fatalf("expected no code to be deployed, got %t and error %v", deployed, err)
+		}
+	}
+	if reads := chain.reads.Load(); reads != 3 {
+		t.Errorf("expected an address without code to be read each time, got %d reads in total", reads)
+	}
+
+	// A read which gets no answer is abandoned
+	defer func(timeout time.Duration) { CODE_READ_TIMEOUT = timeout }(CODE_READ_TIMEOUT)
+	CODE_READ_TIMEOUT = 50 * time.Millisecond
+	if _, err := cs.HasCode(hangingAddress); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
@@ -4,22 +4,40 @@ import (
 	"container/heap"
 	"sync"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
+// SEEN_EVENT_RETENTION_BLOCKS is how many blocks the event tracker remembers queued events for.
+// Events from recent blocks can be delivered twice, once when missed events are replayed and once by the live subscription.
+const SEEN_EVENT_RETENTION_BLOCKS = 128
+
+// eventKey identifies a log emitted on chain
+type eventKey struct {
+	blockHash common.Hash
+	index     uint
+}
+
 type eventTracker struct {
 	latestBlockNum uint64
 	events         eventQueue
+	seen           map[eventKey]uint64 // the block number of each recently queued event
 	mu             sync.Mutex
 }
 
 func NewEventTracker(startBlock uint64) *eventTracker {
 	eventQueue := eventQueue{}
 	heap.Init(&eventQueue)
-	return &eventTracker{latestBlockNum: startBlock, events: eventQueue}
+	return &eventTracker{latestBlockNum: startBlock, events: eventQueue, seen: make(map[eventKey]uint64)}
 }
 
+// Push queues the event, unless it has already been queued
 func (eT *eventTracker) Push(l types.Log) {
+	key := eventKey{l.BlockHash, l.Index}
+	if _, ok := eT.seen[key]; ok {
+		return
+	}
+	eT.seen[key] = l.BlockNumber
 	heap.Push(&eT.events, (l))
 }
 
@@ -27,12 +45,23 @@ func (eT *eventTracker) Pop() types.Log {
 	return heap.Pop(&eT.events).(types.Log)
 }
 
+// forgetOldEvents stops remembering events which were emitted more than SEEN_EVENT_RETENTION_BLOCKS before the latest block
+func (eT *eventTracker) forgetOldEvents() {
+	for key, blockNum := range eT.seen {
+		if blockNum+SEEN_EVENT_RETENTION_BLOCKS < eT.latestBlockNum {
+			delete(eT.seen, key)
+		}
+	}
+}
+
 type eventQueue []types.Log
 
 func (q eventQueue) Len() int { return len(q) }
+
+// Less orders events as they were emitted on chain: by block, and then by position within the block
 func (q eventQueue) Less(i, j int) bool {
 	if q[i].BlockNumber == q[j].BlockNumber {
-		return i < j
+		return q[i].Index < q[j].Index
 	}
 	return q[i].BlockNumber < q[j].BlockNumber
 }
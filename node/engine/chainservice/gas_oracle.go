@@ -0,0 +1,89 @@
+package chainservice
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// GasOracle suggests the fees for dynamic fee (EIP-1559) transactions
+type GasOracle interface {
+	// SuggestFees returns the maximum total fee and the maximum priority fee to pay per unit of gas.
+	// Both are nil if the chain does not support dynamic fee transactions.
+	SuggestFees(ctx context.Context) (maxFeePerGas, maxPriorityFeePerGas *big.Int, err error)
+}
+
+// FeeOpts configures how a chain service prices its transactions
+type FeeOpts struct {
+	// Oracle suggests fees for dynamic fee transactions. If nil, fees are based on the chain node's suggestions.
+	Oracle GasOracle
+	// MaxFeePerGas caps the total fee paid per unit of gas, to avoid overpaying during gas spikes. If nil, fees are not capped.
+	MaxFeePerGas *big.Int
+}
+
+// nodeGasOracle suggests fees from the chain node's suggested priority fee and the latest block's base fee
+type nodeGasOracle struct {
+	chain ethChain
+}
+
+// SuggestFees allows for the base fee doubling before the transaction is mined, as go-ethereum's bindings do
+func (o nodeGasOracle) SuggestFees(ctx context.Context) (*big.Int, *big.Int, error) {
+	head, err := o.chain.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if head.BaseFee == nil {
+		return nil, nil, nil
+	}
+	tip, err := o.chain.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	maxFee := new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+	if tip != nil {
+		maxFee.Add(maxFee, tip)
+	}
+	return maxFee, tip, nil
+}
+
+// capFees lowers the suggested fees so that the total fee per unit of gas does not exceed maxFeePerGas.
+// The priority fee can never exceed the total fee. A nil priority fee is left for the bindings to suggest.
+func capFees(suggestedMaxFee, suggestedTip, maxFeePerGas *big.Int) (*big.Int, *big.Int) {
+	maxFee, tip := suggestedMaxFee, suggestedTip
+	if maxFeePerGas != nil && maxFee.Cmp(maxFeePerGas) > 0 {
+		maxFee = maxFeePerGas
+	}
+	if tip != nil && tip.Cmp(maxFee) > 0 {
+		tip = maxFee
+	}
+	return maxFee, tip
+}
+
+// TransactionError is returned when a chain transaction could not be submitted.
+// It records the fees the transaction was priced with, to help diagnose transactions which are rejected or never mined.
+type TransactionError struct {
+	GasPrice             *big.Int // set for legacy transactions
+	MaxFeePerGas         *big.Int // set for dynamic fee transactions
+	MaxPriorityFeePerGas *big.Int // set for dynamic fee transactions
+	Err                  error
+}
+
+func newTransactionError(txOpts *bind.TransactOpts, err error) *TransactionError {
+	return &TransactionError{GasPrice: txOpts.GasPrice, MaxFeePerGas: txOpts.GasFeeCap, MaxPriorityFeePerGas: txOpts.GasTipCap, Err: err}
+}
+
+func (e *TransactionError) Error() string {
+	if e.MaxFeePerGas != nil {
+		return fmt.Sprintf("transaction with max fee per gas %s and max priority fee per gas %s failed: %v", e.MaxFeePerGas, e.MaxPriorityFeePerGas, e.Err)
+	}
+	if e.GasPrice != nil {
+		return fmt.Sprintf("transaction with gas price %s failed: %v", e.GasPrice, e.Err)
+	}
+	return fmt.Sprintf("transaction failed: %v", e.Err)
+}
+
+func (e *TransactionError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,114 @@
+package chainservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// fixedGasOracle always suggests the same fees
+type fixedGasOracle struct {
+	maxFeePerGas, maxPriorityFeePerGas *big.Int
+}
+
+func (o fixedGasOracle) SuggestFees(context.Context) (*big.Int, *big.Int, error) {
+	return o.maxFeePerGas, o.maxPriorityFeePerGas, nil
+}
+
+func TestCapFees(t *testing.T) {
+	testCases := []struct {
+		name                      string
+		cap                       *big.Int
+		wantMaxFee, wantMaxTipFee int64
+	}{
+		{"no cap", nil, 100, 10},
+		{"cap above the suggested fee", big.NewInt(200), 100, 10},
+		{"cap below the suggested fee", big.NewInt(50), 50, 10},
+		{"cap below the suggested priority fee", big.NewInt(5), 5, 5},
+	}
+	for _, tc := range testCases {
+		maxFee, tip := capFees(big.NewInt(100), big.NewInt(10), tc.cap)
+		if maxFee.Int64() != tc.wantMaxFee || tip.Int64() != tc.wantMaxTipFee {
+			t.Errorf("%s: expected fees (%d, %d), got (%s, %s)", tc.name, tc.wantMaxFee, tc.wantMaxTipFee, maxFee, tip)
+		}
+	}
+
+	// An oracle may suggest no priority fee
+	maxFee, tip := capFees(big.NewInt(100), nil, big.NewInt(50))
+	if maxFee.Int64() != 50 || tip != nil {
+		t.Errorf("expected fees (50, <nil>) without a suggested priority fee, got (%s, %s)", maxFee, tip)
+	}
+}
+
+func TestTransactionsPayCappedDynamicFees(t *testing.T) {
+	logging.SetupDefaultFileLogger("gasOracle.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := sim.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The oracle suggests far more than the cap, which still leaves room above the base fee for the transaction to be mined
+	feeCap := new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+	tip := big.NewInt(1)
+	oracle := fixedGasOracle{maxFeePerGas: new(big.Int).Mul(feeCap, big.NewInt(10)), maxPriorityFeePerGas: tip}
+	cs, err := newEthChainService(sim, 0, bindings.Adjudicator.Contract, ContractAddresses{
+		NitroAdjudicator:  bindings.Adjudicator.Address,
+		VirtualPaymentApp: bindings.VirtualPaymentApp.Address,
+		ConsensusApp:      bindings.ConsensusApp.Address,
+	}, ethAccounts[0], FeeOpts{Oracle: oracle, MaxFeePerGas: feeCap})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	deposit := protocols.NewDepositTransaction(types.Destination{1}, types.Funds{common.Address{}: big.NewInt(1)})
+	if err := cs.SendTransaction(deposit); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	block, err := sim.BlockByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Transactions()) != 1 {
+		t.Fatalf("expected the deposit to be mined, got %d transactions", len(block.Transactions()))
+	}
+	tx := block.Transactions()[0]
+	if tx.Type() != ethTypes.DynamicFeeTxType {
+		t.Fatalf("expected a dynamic fee transaction, got type %d", tx.Type())
+	}
+	if tx.GasFeeCap().Cmp(feeCap) != 0 {
+		t.Errorf("expected max fee per gas to be capped at %s, got %s", feeCap, tx.GasFeeCap())
+	}
+	if tx.GasTipCap().Cmp(tip) != 0 {
+		t.Errorf("expected max priority fee per gas %s, got %s", tip, tx.GasTipCap())
+	}
+}
+
+func TestTransactionErrorRecordsFees(t *testing.T) {
+	reverted := errors.New("execution reverted")
+	err := error(&TransactionError{MaxFeePerGas: big.NewInt(100), MaxPriorityFeePerGas: big.NewInt(10), Err: reverted})
+
+	if !errors.Is(err, reverted) {
+		t.Errorf("expected the error to wrap %v", reverted)
+	}
+	want := "transaction with max fee per gas 100 and max priority fee per gas 10 failed: execution reverted"
+	if err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
@@ -1,8 +1,12 @@
 package chainservice
 
 import (
+	"bytes"
 	"fmt"
+	"math/big"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/internal/safesync"
@@ -12,6 +16,9 @@ import (
 
 // MockChain mimics the Ethereum blockchain by keeping track of block numbers and account balances in memory.
 // MockChain accepts transactions and broadcasts events.
+//
+// Tests which drive a specific sequence of chain events can use a manual MockChain, which records transactions without acting on them.
+// The events are then broadcast on demand, at chosen blocks, with EmitDeposit, EmitAllocationUpdated and EmitConcluded.
 type MockChain struct {
 	BlockNum   uint64
 	blockNumMu sync.Mutex
@@ -20,6 +27,12 @@ type MockChain struct {
 	// out maps addresses to an Event channel. Given that MockChainServices only subscribe
 	// (and never unsubscribe) to events, this can be converted to a list.
 	out safesync.Map[chan Event]
+
+	manual      bool                         // if true, transactions are recorded but do not change holdings or emit events
+	emitted     map[uint64]uint              // the number of events emitted at each block
+	txs         []protocols.ChainTransaction // every transaction submitted, in order
+	txSubmitted chan struct{}                // closed, and replaced, whenever a transaction is submitted
+	code        map[types.Address]bool       // the addresses at which code has been deployed
 }
 
 // NewMockChain creates a new MockChain
@@ -28,14 +41,32 @@ func NewMockChain() *MockChain {
 	chain.BlockNum = 1
 	chain.holdings = map[types.Destination]types.Funds{}
 	chain.out = safesync.Map[chan Event]{}
+	chain.txSubmitted = make(chan struct{})
+	chain.emitted = map[uint64]uint{}
+	chain.code = map[types.Address]bool{}
 	return &chain
 }
 
+// NewManualMockChain creates a new MockChain which records the transactions submitted to it, but does not act on them:
+// the chain only changes when the test advances it or emits events.
+func NewManualMockChain() *MockChain {
+	chain := NewMockChain()
+	chain.manual = true
+	return chain
+}
+
 // SubmitTransaction updates internal state and broadcasts events
 // unlike an ethereum blockchain, MockChain accepts go-nitro protocols.ChainTransaction
 func (mc *MockChain) SubmitTransaction(tx protocols.ChainTransaction) error {
 	eventsToBroadcast := []Event{}
 	mc.blockNumMu.Lock()
+	mc.txs = append(mc.txs, tx)
+	close(mc.txSubmitted)
+	mc.txSubmitted = make(chan struct{})
+	if mc.manual {
+		mc.blockNumMu.Unlock()
+		return nil
+	}
 	mc.BlockNum++
 	h := mc.holdings[tx.ChannelId()] // ignore `ok` because the returned zero-value is what we want
 	switch tx := tx.(type) {
@@ -54,6 +85,12 @@ func (mc *MockChain) SubmitTransaction(tx protocols.ChainTransaction) error {
 			eventsToBroadcast = append(eventsToBroadcast, event)
 		}
 		mc.holdings[tx.ChannelId()] = types.Funds{}
+	case protocols.ChallengeTransaction:
+		// The mock chain runs no adjudicator logic, so every challenge is registered. Having no clock, it measures the challenge duration in blocks.
+		candidate := tx.Candidate.State()
+		finalizesAt := mc.BlockNum + uint64(candidate.ChallengeDuration)
+		event := NewChallengeRegisteredEvent(tx.ChannelId(), mc.BlockNum, 0, candidate.VariablePart(), tx.Candidate.Signatures(), finalizesAt)
+		eventsToBroadcast = append(eventsToBroadcast, event)
 	default:
 		return fmt.Errorf("unexpected transaction type %T", tx)
 	}
@@ -64,6 +101,126 @@ func (mc *MockChain) SubmitTransaction(tx protocols.ChainTransaction) error {
 	return nil
 }
 
+// AdvanceBlock mines an empty block, and returns its number.
+func (mc *MockChain) AdvanceBlock() uint64 {
+	mc.blockNumMu.Lock()
+	defer mc.blockNumMu.Unlock()
+	mc.BlockNum++
+	return mc.BlockNum
+}
+
+// EmitDeposit broadcasts a DepositedEvent for each asset of holdings, which are the amounts held for the channel after the deposit, at the given block.
+func (mc *MockChain) EmitDeposit(channelId types.Destination, holdings types.Funds, blockNum uint64) {
+	mc.blockNumMu.Lock()
+	h := mc.holdings[channelId].Clone()
+	events := []Event{}
+	for _, asset := range sortedAssets(holdings) {
+		h[asset] = new(big.Int).Set(holdings[asset])
+		events = append(events, NewDepositedEvent(channelId, blockNum, mc.nextTxIndex(blockNum), asset, holdings[asset]))
+	}
+	mc.holdings[channelId] = h
+	mc.blockNumMu.Unlock()
+	mc.emit(blockNum, events...)
+}
+
+// EmitAllocationUpdated broadcasts an AllocationUpdatedEvent, which leaves amount of the asset held for the channel, at the given block.
+func (mc *MockChain) EmitAllocationUpdated(channelId types.Destination, asset common.Address, amount *big.Int, blockNum uint64) {
+	mc.blockNumMu.Lock()
+	h := mc.holdings[channelId].Clone()
+	h[asset] = new(big.Int).Set(amount)
+	mc.holdings[channelId] = h
+	event := NewAllocationUpdatedEvent(channelId, blockNum, mc.nextTxIndex(blockNum), asset, amount)
+	mc.blockNumMu.Unlock()
+	mc.emit(blockNum, event)
+}
+
+// EmitConcluded broadcasts a ConcludedEvent for the channel at the given block.
+func (mc *MockChain) EmitConcluded(channelId types.Destination, blockNum uint64) {
+	mc.blockNumMu.Lock()
+	event := ConcludedEvent{commonEvent{channelID: channelId, blockNum: blockNum, txIndex: mc.nextTxIndex(blockNum)}}
+	mc.blockNumMu.Unlock()
+	mc.emit(blockNum, event)
+}
+
+// EmitChallengeCleared broadcasts a ChallengeClearedEvent for the channel at the given block.
+// The mock chain has no checkpoint transaction, so this stands in for a checkpoint clearing a challenge.
+func (mc *MockChain) EmitChallengeCleared(channelId types.Destination, blockNum uint64) {
+	mc.blockNumMu.Lock()
+	event := NewChallengeClearedEvent(channelId, blockNum, mc.nextTxIndex(blockNum))
+	mc.blockNumMu.Unlock()
+	mc.emit(blockNum, event)
+}
+
+// DeployCode records that a contract, such as an ERC20 token, is deployed at the address.
+// The mock chain runs no contracts, so this only affects MockChainService.HasCode.
+func (mc *MockChain) DeployCode(address types.Address) {
+	mc.blockNumMu.Lock()
+	defer mc.blockNumMu.Unlock()
+	mc.code[address] = true
+}
+
+func (mc *MockChain) hasCode(address types.Address) bool {
+	mc.blockNumMu.Lock()
+	defer mc.blockNumMu.Unlock()
+	return mc.code[address]
+}
+
+// SubmittedTransactions returns every transaction submitted to the chain so far, in the order they were submitted.
+func (mc *MockChain) SubmittedTransactions() []protocols.ChainTransaction {
+	mc.blockNumMu.Lock()
+	defer mc.blockNumMu.Unlock()
+	return slices.Clone(mc.txs)
+}
+
+// WaitForTransactions blocks until at least n transactions have been submitted to the chain, and returns them.
+// It returns an error if fewer than n have been submitted before the timeout.
+func (mc *MockChain) WaitForTransactions(n int, timeout time.Duration) ([]protocols.ChainTransaction, error) {
+	deadline := time.After(timeout)
+	for {
+		mc.blockNumMu.Lock()
+		txs, submitted := slices.Clone(mc.txs), mc.txSubmitted
+		mc.blockNumMu.Unlock()
+		if len(txs) >= n {
+			return txs, nil
+		}
+		select {
+		case <-submitted:
+		case <-deadline:
+			return txs, fmt.Errorf("expected %d transactions to be submitted within %s, got %d", n, timeout, len(txs))
+		}
+	}
+}
+
+// nextTxIndex returns the transaction index of the next event emitted at the given block, so that
+// events emitted at the same block are ordered. It must be called with blockNumMu held.
+func (mc *MockChain) nextTxIndex(blockNum uint64) uint {
+	txIndex := mc.emitted[blockNum]
+	mc.emitted[blockNum]++
+	return txIndex
+}
+
+// emit advances the chain to blockNum, if it is behind, and broadcasts the events.
+func (mc *MockChain) emit(blockNum uint64, events ...Event) {
+	mc.blockNumMu.Lock()
+	if blockNum > mc.BlockNum {
+		mc.BlockNum = blockNum
+	}
+	mc.blockNumMu.Unlock()
+	for _, event := range events {
+		mc.broadcastEvent(event)
+	}
+}
+
+// sortedAssets returns the assets of the funds in a deterministic order
+func sortedAssets(f types.Funds) []common.Address {
+	assets := make([]common.Address, 0, len(f))
+	for asset := range f {
+		assets = append(assets, asset)
+	}
+	slices.SortFunc(assets, func(a, b common.Address) int { return bytes.Compare(a.Bytes(), b.Bytes()) })
+	return assets
+}
+
 func (mc *MockChain) broadcastEvent(event Event) {
 	mc.out.Range(func(_ string, channel chan Event) bool {
 		channel <- event
@@ -44,6 +44,11 @@ func (mc *MockChainService) GetChainId() (*big.Int, error) {
 	return big.NewInt(TEST_CHAIN_ID), nil
 }
 
+// HasCode returns true if code has been deployed at the address with MockChain.DeployCode
+func (mc *MockChainService) HasCode(address types.Address) (bool, error) {
+	return mc.chain.hasCode(address), nil
+}
+
 func (mc *MockChainService) GetLastConfirmedBlockNum() uint64 {
 	mc.chain.blockNumMu.Lock()
 	blockNum := mc.chain.BlockNum
@@ -52,6 +57,14 @@ func (mc *MockChainService) GetLastConfirmedBlockNum() uint64 {
 	return blockNum
 }
 
+// Connected returns true, since the mock chain is in memory.
+func (mc *MockChainService) Connected() bool {
+	return true
+}
+
+// WatchChannel is a no-op, since the mock chain only emits events for channels under test.
+func (mc *MockChainService) WatchChannel(channelId types.Destination) {}
+
 func (mc *MockChainService) Close() error {
 	return nil
 }
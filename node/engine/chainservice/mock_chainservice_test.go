@@ -2,7 +2,9 @@ package chainservice
 
 import (
 	"math/big"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/protocols"
@@ -59,6 +61,62 @@ func TestDeposit(t *testing.T) {
 	checkReceivedEventIsValid(t, eventB, expectedHoldings, testTx.ChannelId())
 }
 
+func TestManualMockChain(t *testing.T) {
+	a := types.Address(common.HexToAddress(`a`))
+	b := types.Address(common.HexToAddress(`b`))
+	channelId := types.Destination(common.HexToHash(`4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d`))
+	asset := common.HexToAddress("0x00")
+
+	chain := NewManualMockChain()
+	chainServiceA := NewMockChainService(chain, a)
+	chainServiceB := NewMockChainService(chain, b)
+
+	// Submitted transactions are recorded, but do not cause any events
+	deposit := protocols.NewDepositTransaction(channelId, types.Funds{asset: big.NewInt(1)})
+	go func() {
+		if err := chainServiceA.SendTransaction(deposit); err != nil {
+			t.Error(err)
+		}
+	}()
+	txs, err := chain.WaitForTransactions(1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(txs, []protocols.ChainTransaction{deposit}) {
+		t.Fatalf("expected the deposit to be submitted, got %v", txs)
+	}
+	select {
+	case event := <-chainServiceA.EventFeed():
+		t.Fatalf("expected no event until one is emitted, got %v", event)
+	default:
+	}
+
+	// The test decides when, and at which block, the deposit lands
+	if got := chain.AdvanceBlock(); got != 2 {
+		t.Fatalf("expected to advance to block 2, got %d", got)
+	}
+	chain.EmitDeposit(channelId, types.Funds{asset: big.NewInt(1)}, 5)
+	chain.EmitConcluded(channelId, 5)
+	for _, feed := range []<-chan Event{chainServiceA.EventFeed(), chainServiceB.EventFeed()} {
+		event := <-feed
+		checkReceivedEventIsValid(t, event, types.Funds{asset: big.NewInt(1)}, channelId)
+		if event.BlockNum() != 5 || event.TxIndex() != 0 {
+			t.Fatalf("expected the deposit at block 5, index 0, got block %d, index %d", event.BlockNum(), event.TxIndex())
+		}
+		event = <-feed
+		if _, ok := event.(ConcludedEvent); !ok || event.BlockNum() != 5 || event.TxIndex() != 1 {
+			t.Fatalf("expected the channel to conclude at block 5, index 1, got %v", event)
+		}
+	}
+	if got := chainServiceB.GetLastConfirmedBlockNum(); got != 5 {
+		t.Fatalf("expected the chain to be at block 5, got %d", got)
+	}
+
+	if _, err := chain.WaitForTransactions(2, 10*time.Millisecond); err == nil {
+		t.Fatal("expected an error waiting for a transaction which is never submitted")
+	}
+}
+
 func checkReceivedEventIsValid(t *testing.T, receivedEvent Event, holdings types.Funds, channelId types.Destination) {
 	if receivedEvent.ChannelID() != channelId {
 		t.Fatalf(`channelId mismatch: expected %v but got %v`, channelId, receivedEvent.ChannelID())
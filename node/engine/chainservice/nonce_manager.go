@@ -0,0 +1,176 @@
+package chainservice
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// STUCK_TRANSACTION_TIMEOUT is how long a submitted transaction may remain unmined before it is resubmitted with a higher gas price
+var STUCK_TRANSACTION_TIMEOUT = 2 * time.Minute
+
+// GAS_PRICE_BUMP_PERCENT is how much the gas price of a stuck transaction is raised by when it is resubmitted.
+// Most nodes reject replacement transactions which raise the gas price by less than 10%.
+const GAS_PRICE_BUMP_PERCENT = 20
+
+// pendingTransaction is a submitted transaction which has not yet been seen in a block
+type pendingTransaction struct {
+	nonce       uint64
+	latest      *ethTypes.Transaction // the most recent submission with this nonce
+	hashes      []common.Hash         // the hashes of every submission with this nonce, any of which may be mined
+	submittedAt time.Time
+}
+
+// pendingSubmissions is a copy of the submissions of a pending transaction, which can be checked against the chain without holding the nonce manager's lock
+type pendingSubmissions struct {
+	p           *pendingTransaction
+	latest      *ethTypes.Transaction
+	hashes      []common.Hash
+	submittedAt time.Time
+}
+
+// nonceManager assigns sequential nonces to the transactions submitted from a single account,
+// and keeps track of those transactions until they are mined.
+//
+// Callers must hold mu for the whole of a submission, so that nonces are assigned in the order that submissions are made.
+// Checking pending transactions against the chain does not need the lock, so that it does not hold up submissions.
+type nonceManager struct {
+	mu      sync.Mutex
+	chain   ethChain
+	from    common.Address
+	synced  bool   // whether nonce reflects the account's pending nonce on chain
+	nonce   uint64 // the nonce to assign to the next transaction
+	pending map[uint64]*pendingTransaction
+}
+
+func newNonceManager(chain ethChain, from common.Address) *nonceManager {
+	return &nonceManager{chain: chain, from: from, pending: make(map[uint64]*pendingTransaction)}
+}
+
+// next reserves and returns the nonce for the next transaction, fetching the account's pending nonce from the chain if necessary.
+func (nm *nonceManager) next(ctx context.Context) (*big.Int, error) {
+	if !nm.synced {
+		nonce, err := nm.chain.PendingNonceAt(ctx, nm.from)
+		if err != nil {
+			return nil, err
+		}
+		nm.nonce = nonce
+		nm.synced = true
+	}
+	nonce := nm.nonce
+	nm.nonce++
+	return new(big.Int).SetUint64(nonce), nil
+}
+
+// resync discards the locally tracked nonce, so that the next one is fetched from the chain.
+// It is used after a failed submission, which may have left a reserved nonce unused.
+func (nm *nonceManager) resync() {
+	nm.synced = false
+}
+
+// track records a submitted transaction so that it can be resubmitted if it gets stuck.
+// The returned record keeps every hash submitted with the transaction's nonce, even once the transaction is mined and forgotten.
+func (nm *nonceManager) track(tx *ethTypes.Transaction) *pendingTransaction {
+	p := &pendingTransaction{nonce: tx.Nonce(), latest: tx, hashes: []common.Hash{tx.Hash()}, submittedAt: time.Now()}
+	nm.pending[tx.Nonce()] = p
+	return p
+}
+
+// snapshot returns a copy of the submissions of every pending transaction. Callers must hold mu.
+func (nm *nonceManager) snapshot() []pendingSubmissions {
+	snapshot := make([]pendingSubmissions, 0, len(nm.pending))
+	for _, p := range nm.pending {
+		snapshot = append(snapshot, pendingSubmissions{p: p, latest: p.latest, hashes: append([]common.Hash{}, p.hashes...), submittedAt: p.submittedAt})
+	}
+	return snapshot
+}
+
+// stuck checks the snapshotted transactions against the chain, and returns those which have been mined
+// and those which have been pending for longer than timeout. It does not need mu.
+func (nm *nonceManager) stuck(ctx context.Context, pending []pendingSubmissions, timeout time.Duration) (stuck, mined []pendingSubmissions, err error) {
+	for _, s := range pending {
+		isMined, err := nm.isMined(ctx, s.hashes)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isMined {
+			mined = append(mined, s)
+			continue
+		}
+		if time.Since(s.submittedAt) > timeout {
+			stuck = append(stuck, s)
+		}
+	}
+	return stuck, mined, nil
+}
+
+// forget stops tracking the mined transactions. Callers must hold mu.
+func (nm *nonceManager) forget(mined []pendingSubmissions) {
+	for _, s := range mined {
+		if nm.pending[s.p.nonce] == s.p {
+			delete(nm.pending, s.p.nonce)
+		}
+	}
+}
+
+// isMined returns true if any of the submissions with the given hashes has been included in a block
+func (nm *nonceManager) isMined(ctx context.Context, hashes []common.Hash) (bool, error) {
+	for _, hash := range hashes {
+		_, err := nm.chain.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// replaced records a resubmission of a pending transaction
+func (p *pendingTransaction) replaced(tx *ethTypes.Transaction) {
+	p.latest = tx
+	p.hashes = append(p.hashes, tx.Hash())
+	p.submittedAt = time.Now()
+}
+
+// bumpGasPrice returns an unsigned copy of tx with the same nonce and payload, whose gas price is raised by GAS_PRICE_BUMP_PERCENT
+func bumpGasPrice(tx *ethTypes.Transaction) *ethTypes.Transaction {
+	bump := func(price *big.Int) *big.Int {
+		bumped := new(big.Int).Mul(price, big.NewInt(100+GAS_PRICE_BUMP_PERCENT))
+		bumped.Div(bumped, big.NewInt(100))
+		// Ensure tiny prices still increase
+		if bumped.Cmp(price) <= 0 {
+			bumped.Add(price, big.NewInt(1))
+		}
+		return bumped
+	}
+
+	if tx.Type() == ethTypes.DynamicFeeTxType {
+		return ethTypes.NewTx(&ethTypes.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  bump(tx.GasTipCap()),
+			GasFeeCap:  bump(tx.GasFeeCap()),
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		})
+	}
+	return ethTypes.NewTx(&ethTypes.LegacyTx{
+		Nonce:    tx.Nonce(),
+		GasPrice: bump(tx.GasPrice()),
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	})
+}
@@ -0,0 +1,90 @@
+package chainservice
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestBackToBackTransactionsHaveSequentialNonces(t *testing.T) {
+	logging.SetupDefaultFileLogger("nonceManager.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := ethAccounts[0]
+
+	// Use the EthChainService directly, so that no blocks are mined between submissions
+	cs, err := newEthChainService(sim, 0, bindings.Adjudicator.Contract, ContractAddresses{
+		NitroAdjudicator:  bindings.Adjudicator.Address,
+		VirtualPaymentApp: bindings.VirtualPaymentApp.Address,
+		ConsensusApp:      bindings.ConsensusApp.Address,
+	}, account, FeeOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	startNonce, err := sim.PendingNonceAt(context.Background(), account.From)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		// Channel ids must not look like external destinations, which have 12 leading zero bytes
+		channelId := types.Destination{byte(i)}
+		deposit := protocols.NewDepositTransaction(channelId, types.Funds{common.Address{}: big.NewInt(i)})
+		if err := cs.SendTransaction(deposit); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sim.Commit()
+
+	block, err := sim.BlockByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs := block.Transactions()
+	if len(txs) != 3 {
+		t.Fatalf("expected all 3 transactions to be mined in the same block, got %d", len(txs))
+	}
+	for i, tx := range txs {
+		if want := startNonce + uint64(i); tx.Nonce() != want {
+			t.Errorf("transaction %d: expected nonce %d, got %d", i, want, tx.Nonce())
+		}
+		receipt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if receipt.Status != ethTypes.ReceiptStatusSuccessful {
+			t.Errorf("transaction %d reverted", i)
+		}
+	}
+}
+
+func TestBumpGasPrice(t *testing.T) {
+	to := common.HexToAddress(`a`)
+	legacy := ethTypes.NewTx(&ethTypes.LegacyTx{Nonce: 7, GasPrice: big.NewInt(100), Gas: 21000, To: &to, Value: big.NewInt(1), Data: []byte{1}})
+	bumped := bumpGasPrice(legacy)
+	if bumped.Nonce() != 7 || bumped.Gas() != 21000 || *bumped.To() != to || bumped.Value().Cmp(big.NewInt(1)) != 0 || string(bumped.Data()) != string([]byte{1}) {
+		t.Errorf("expected the replacement to keep the nonce and payload, got %+v", bumped)
+	}
+	if bumped.GasPrice().Cmp(big.NewInt(120)) != 0 {
+		t.Errorf("expected gas price 120, got %s", bumped.GasPrice())
+	}
+
+	dynamic := ethTypes.NewTx(&ethTypes.DynamicFeeTx{ChainID: big.NewInt(TEST_CHAIN_ID), Nonce: 7, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(50), Gas: 21000, To: &to})
+	bumped = bumpGasPrice(dynamic)
+	if bumped.GasTipCap().Cmp(big.NewInt(2)) != 0 || bumped.GasFeeCap().Cmp(big.NewInt(60)) != 0 {
+		t.Errorf("expected tip cap 2 and fee cap 60, got %s and %s", bumped.GasTipCap(), bumped.GasFeeCap())
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/crypto"
+	nc "github.com/statechannels/go-nitro/crypto"
 	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
 	ConsensusApp "github.com/statechannels/go-nitro/node/engine/chainservice/consensusapp"
 	Token "github.com/statechannels/go-nitro/node/engine/chainservice/erc20"
@@ -63,10 +64,12 @@ func NewSimulatedBackendChainService(sim SimulatedChain, bindings Bindings,
 ) (ChainService, error) {
 	ethChainService, err := newEthChainService(sim, 0,
 		bindings.Adjudicator.Contract,
-		bindings.Adjudicator.Address,
-		bindings.ConsensusApp.Address,
-		bindings.VirtualPaymentApp.Address,
-		txSigner)
+		ContractAddresses{
+			NitroAdjudicator:  bindings.Adjudicator.Address,
+			VirtualPaymentApp: bindings.VirtualPaymentApp.Address,
+			ConsensusApp:      bindings.ConsensusApp.Address,
+		},
+		txSigner, FeeOpts{})
 	if err != nil {
 		return &SimulatedBackendChainService{}, err
 	}
@@ -102,7 +105,7 @@ func SetupSimulatedBackend(numAccounts uint64) (SimulatedChain, Bindings, []*bin
 	for i := range accounts {
 		// Setup transacting EOA
 		key, _ := crypto.GenerateKey()
-		accounts[i], err = bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337)) // 1337 according to docs on SimulatedBackend
+		accounts[i], err = nc.NewTransactor(crypto.FromECDSA(key), big.NewInt(TEST_CHAIN_ID)) // 1337 according to docs on SimulatedBackend
 		if err != nil {
 			return nil, contractBindings, accounts, err
 		}
@@ -159,10 +162,10 @@ func SetupSimulatedBackend(numAccounts uint64) (SimulatedChain, Bindings, []*bin
 }
 
 func (sbcs *SimulatedBackendChainService) GetConsensusAppAddress() types.Address {
-	return sbcs.consensusAppAddress
+	return sbcs.addresses.ConsensusApp
 }
 
 // GetVirtualPaymentAppAddress returns the address of a deployed VirtualPaymentApp
 func (sbcs *SimulatedBackendChainService) GetVirtualPaymentAppAddress() types.Address {
-	return sbcs.virtualPaymentAppAddress
+	return sbcs.addresses.VirtualPaymentApp
 }
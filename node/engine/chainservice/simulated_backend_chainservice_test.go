@@ -2,6 +2,7 @@ package chainservice
 
 import (
 	"bytes"
+	"context"
 	"log/slog"
 	"math/big"
 	"testing"
@@ -95,7 +96,8 @@ func TestSimulatedBackendChainService(t *testing.T) {
 
 	challengeTx := protocols.NewChallengeTransaction(concludeState.ChannelId(), concludeSignedState, make([]state.SignedState, 0), challengerSig)
 
-	out := cs.EventFeed()
+	cs.WatchChannel(concludeState.ChannelId())
+	out := adjudicatorEvents(t, cs.EventFeed())
 	err = cs.SendTransaction(challengeTx)
 	if err != nil {
 		t.Fatal(err)
@@ -104,7 +106,13 @@ func TestSimulatedBackendChainService(t *testing.T) {
 	// Check that the received events matches the expected event
 	receivedEvent = <-out
 	crEvent := receivedEvent.(ChallengeRegisteredEvent)
-	expectedChallengeRegisteredEvent := NewChallengeRegisteredEvent(concludeState.ChannelId(), challengeBlockNum, crEvent.TxIndex(), crEvent.candidate, crEvent.candidateSignatures)
+	// The adjudicator finalizes the channel once the challenge duration has elapsed from the time of the challenge block
+	challengeBlock, err := sim.HeaderByNumber(context.Background(), new(big.Int).SetUint64(challengeBlockNum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	finalizesAt := challengeBlock.Time + uint64(CHALLENGE_DURATION)
+	expectedChallengeRegisteredEvent := NewChallengeRegisteredEvent(concludeState.ChannelId(), challengeBlockNum, crEvent.TxIndex(), crEvent.candidate, crEvent.candidateSignatures, finalizesAt)
 	if diff := cmp.Diff(expectedChallengeRegisteredEvent, crEvent, cmp.AllowUnexported(ChallengeRegisteredEvent{}, commonEvent{}, big.Int{})); diff != "" {
 		t.Fatalf("Received event did not match expectation; (-want +got):\n%s", diff)
 	}
@@ -154,6 +162,7 @@ func TestSimulatedBackendChainService(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	cs2.WatchChannel(cId)
 
 	concludeTx := protocols.NewWithdrawAllTransaction(cId, signedConcludeState)
 	err = cs.SendTransaction(concludeTx)
@@ -190,7 +199,7 @@ func TestSimulatedBackendChainService(t *testing.T) {
 	// Check events from cs2 to ensure they match the expected values
 	receivedEvent = <-cs2.EventFeed()
 	crEvent = receivedEvent.(ChallengeRegisteredEvent)
-	expectedChallengeRegisteredEvent = NewChallengeRegisteredEvent(concludeState.ChannelId(), challengeBlockNum, crEvent.TxIndex(), crEvent.candidate, crEvent.candidateSignatures)
+	expectedChallengeRegisteredEvent = NewChallengeRegisteredEvent(concludeState.ChannelId(), challengeBlockNum, crEvent.TxIndex(), crEvent.candidate, crEvent.candidateSignatures, finalizesAt)
 	if diff := cmp.Diff(expectedChallengeRegisteredEvent, crEvent, cmp.AllowUnexported(ChallengeRegisteredEvent{}, commonEvent{}, big.Int{})); diff != "" {
 		t.Fatalf("Received event did not match expectation; (-want +got):\n%s", diff)
 	}
@@ -224,6 +233,31 @@ func TestSimulatedBackendChainService(t *testing.T) {
 	}
 }
 
+// adjudicatorEvents forwards the events on the feed until the test finishes, skipping the TransactionMinedEvents which report on submissions
+func adjudicatorEvents(t *testing.T, feed <-chan Event) <-chan Event {
+	out := make(chan Event, 10)
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		for {
+			select {
+			case event := <-feed:
+				if _, ok := event.(TransactionMinedEvent); ok {
+					continue
+				}
+				select {
+				case out <- event:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
 func closeChainService(t *testing.T, cs ChainService) {
 	if err := cs.Close(); err != nil {
 		t.Fatal(err)
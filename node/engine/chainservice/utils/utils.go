@@ -6,9 +6,10 @@ import (
 	"log/slog"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
+
+	nc "github.com/statechannels/go-nitro/crypto"
 )
 
 // ConnectToChain connects to the chain at the given url and returns a client and a transactor.
@@ -36,11 +37,7 @@ func ConnectToChain(ctx context.Context, chainUrl, chainAuthToken string, chainP
 	}
 	slog.Info("Found chain id", "chainId", foundChainId)
 
-	key, err := ethcrypto.ToECDSA(chainPK)
-	if err != nil {
-		return nil, nil, err
-	}
-	txSubmitter, err := bind.NewKeyedTransactorWithChainID(key, foundChainId)
+	txSubmitter, err := nc.NewTransactor(chainPK, foundChainId)
 	if err != nil {
 		return nil, nil, err
 	}
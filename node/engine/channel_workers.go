@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+	"sync"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// channelWorkerPool runs jobs keyed by channel id.
+// Jobs for the same channel are run serially, in the order they were submitted,
+// while jobs for different channels are run concurrently.
+type channelWorkerPool struct {
+	ctx    context.Context
+	queues map[types.Destination][]func(context.Context) // pending jobs for each channel with a running worker
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+}
+
+// newChannelWorkerPool creates a channelWorkerPool whose jobs receive the supplied context.
+func newChannelWorkerPool(ctx context.Context) *channelWorkerPool {
+	return &channelWorkerPool{ctx: ctx, queues: make(map[types.Destination][]func(context.Context))}
+}
+
+// submit queues the job to run once all previously submitted jobs for the channel have finished.
+func (p *channelWorkerPool) submit(channelId types.Destination, job func(context.Context)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.wg.Add(1)
+	queue, running := p.queues[channelId]
+	p.queues[channelId] = append(queue, job)
+	if !running {
+		go p.work(channelId)
+	}
+}
+
+// work runs the queued jobs for the channel until there are none left.
+func (p *channelWorkerPool) work(channelId types.Destination) {
+	for {
+		p.mu.Lock()
+		queue := p.queues[channelId]
+		if len(queue) == 0 {
+			delete(p.queues, channelId)
+			p.mu.Unlock()
+			return
+		}
+		job := queue[0]
+		p.queues[channelId] = queue[1:]
+		p.mu.Unlock()
+
+		job(p.ctx)
+		p.wg.Done()
+	}
+}
+
+// wait blocks until every submitted job has finished.
+func (p *channelWorkerPool) wait() {
+	p.wg.Wait()
+}
@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestChannelWorkerPool(t *testing.T) {
+	pool := newChannelWorkerPool(context.Background())
+
+	blocked := types.Destination{1}
+	free := types.Destination{2}
+
+	release := make(chan struct{})
+	freeDone := make(chan struct{})
+	order := make(chan int, 3)
+
+	pool.submit(blocked, func(context.Context) { <-release; order <- 1 })
+	pool.submit(blocked, func(context.Context) { order <- 2 })
+	pool.submit(free, func(context.Context) { close(freeDone) })
+
+	select {
+	case <-freeDone:
+	case <-time.After(time.Second):
+		t.Fatal("job for a different channel was blocked")
+	}
+
+	select {
+	case <-order:
+		t.Fatal("job ran before the job submitted ahead of it for the same channel")
+	default:
+	}
+
+	close(release)
+	pool.wait()
+	close(order)
+
+	got := []int{}
+	for i := range order {
+		got = append(got, i)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("expected jobs to run in submission order, got %v", got)
+	}
+}
@@ -9,13 +9,18 @@ import (
 	"fmt"
 	"log/slog"
 	"math/big"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/crypto/secp256k1"
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
 	"github.com/statechannels/go-nitro/node/engine/messageservice"
 	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
@@ -23,11 +28,16 @@ import (
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/appupdate"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgerrecycle"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/types"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ErrUnhandledChainEvent is an engine error when the the engine cannot process a chain event
@@ -55,6 +65,25 @@ var nonFatalErrors = []error{
 	&ErrGetObjective{},
 	store.ErrLoadVouchers,
 	directfund.ErrLedgerChannelExists,
+	directdefund.ErrNotEmpty,
+	ledgertopup.ErrNotEmpty,
+	ledgertopup.ErrChannelUpdateInProgress,
+	ledgertopup.ErrInvalidAmount,
+	ledgerrecycle.ErrNotEmpty,
+	ledgerrecycle.ErrChannelUpdateInProgress,
+	ledgerrecycle.ErrInvalidOutcome,
+	ledgerrecycle.ErrTotalChanged,
+	ledgerrecycle.ErrNotFunded,
+	appupdate.ErrNoSuchChannel,
+	appupdate.ErrPaymentChannel,
+	appupdate.ErrNotFunded,
+	appupdate.ErrChannelFinalized,
+	appupdate.ErrStaleTurnNum,
+	appupdate.ErrFinalState,
+	appupdate.ErrFundingChanged,
+	protocols.ErrNotCancellable,
+	protocols.ErrInvalidSignatures,
+	ErrObjectiveStalled,
 }
 
 // Engine is the imperative part of the core business logic of a go-nitro Node
@@ -64,21 +93,54 @@ type Engine struct {
 	// From API
 	ObjectiveRequestsFromAPI chan protocols.ObjectiveRequest
 	PaymentRequestsFromAPI   chan PaymentRequest
+	CancelRequestsFromAPI    chan CancelRequest
 
-	fromChain    <-chan chainservice.Event
-	fromMsg      <-chan protocols.Message
-	fromLedger   chan consensus_channel.Proposal
-	signRequests <-chan p2pms.SignatureRequest
+	fromChain       <-chan chainservice.Event // events from the default chain
+	fromOtherChains chan chainEvent           // events from every other chain
+	fromMsg         <-chan protocols.Message
+	fromLedger      chan consensus_channel.Proposal
+	signRequests    <-chan p2pms.SignatureRequest
+	failedTxs       chan failedTransaction // chain transactions whose submission failed
+	txRetries       chan types.Destination // channels whose failed transactions are due to be retried
 
 	eventHandler func(EngineEvent)
 
-	msg   messageservice.MessageService
-	chain chainservice.ChainService
+	msg    messageservice.MessageService
+	chains *chainRouter // the chain service for each chain the engine's channels live on
 
 	store       store.Store // A Store for persisting and restoring important data
-	policymaker PolicyMaker // A PolicyMaker decides whether to approve or reject objectives
-	logger      *slog.Logger
-	vm          *payments.VoucherManager
+	policymaker PolicyMaker // The PolicyMaker the engine was created with, which decides whether to close idle channels, retry spawned objectives and enforce deadlines
+	// approvals holds the PolicyMaker which decides whether to approve or reject objectives. It is replaced by SetPolicy.
+	approvals *atomic.Pointer[PolicyMaker]
+	// outcomeValidator decides whether to accept channel outcomes proposed by counterparties
+	outcomeValidator outcome.Validator
+	// checkAssetCode is true if proposed channels are rejected unless each of their assets is deployed on chain. It is set by SetAssetCodeCheck.
+	checkAssetCode *atomic.Bool
+	logger         *slog.Logger
+	metrics        *MetricsRecorder
+	vm             *payments.VoucherManager
+
+	// txWorkers submits chain transactions off the run loop, so that an objective blocked on a chain submission does not stall other objectives.
+	// Transactions for the same channel are submitted in order.
+	txWorkers *channelWorkerPool
+
+	// tracer records a span for the lifecycle of each objective
+	tracer *objectiveTracer
+
+	// pausedObjectives depend on chain events, and were not cranked while the chain service was disconnected
+	pausedObjectives *safesync.Map[bool]
+
+	// channelActivity records when each payment channel was last cranked by an objective or had a voucher sent or received.
+	// It is only tracked if the policy maker closes idle channels, is written through to the store, and is only accessed from the run loop.
+	channelActivity map[types.Destination]time.Time
+
+	// spawnedObjectives are the objectives requested through the API which have not yet progressed past their first step.
+	// They are only tracked if the policy maker retries spawned objectives, and are only accessed from the run loop.
+	spawnedObjectives map[protocols.ObjectiveId]*spawnedObjective
+
+	// objectiveDeadlines record when each approved objective must next progress before its channels are challenged on chain.
+	// They are only tracked if the policy maker sets objective deadlines, are written through to the store, and are only accessed from the run loop.
+	objectiveDeadlines map[protocols.ObjectiveId]store.ObjectiveDeadline
 
 	wg     *sync.WaitGroup
 	cancel context.CancelFunc
@@ -90,6 +152,22 @@ type PaymentRequest struct {
 	Amount    *big.Int
 }
 
+// CancelRequest represents a request from the API to abandon an objective which is in progress
+type CancelRequest struct {
+	ObjectiveId protocols.ObjectiveId
+	result      chan error
+}
+
+// NewCancelRequest creates a request to cancel the objective with the given id
+func NewCancelRequest(id protocols.ObjectiveId) CancelRequest {
+	return CancelRequest{ObjectiveId: id, result: make(chan error, 1)}
+}
+
+// WaitForCancellation blocks until the engine has handled the request, and returns the reason the objective could not be cancelled, if any
+func (r CancelRequest) WaitForCancellation() error {
+	return <-r.result
+}
+
 // EngineEvent is a struct that contains a list of changes caused by handling a message/chain event/api event
 type EngineEvent struct {
 	// These are objectives that are now completed
@@ -103,6 +181,25 @@ type EngineEvent struct {
 	LedgerChannelUpdates []query.LedgerChannelInfo
 	// PaymentChannelUpdates contains channel info for payment channels that have been updated
 	PaymentChannelUpdates []query.PaymentChannelInfo
+	// ObjectiveProgress records what each cranked objective is now waiting for, in the order the objectives were cranked
+	ObjectiveProgress []ObjectiveProgressEvent
+	// RejectedObjectives explains why objectives were rejected, either by our policymaker or by a counterparty
+	RejectedObjectives []ObjectiveRejection
+}
+
+// ObjectiveRejection records why an objective was rejected. The reason is empty if the rejecting party gave none.
+type ObjectiveRejection struct {
+	ObjectiveId protocols.ObjectiveId
+	Reason      string
+	// By is the address of the party who rejected the objective
+	By types.Address
+}
+
+// ObjectiveProgressEvent records what an objective was waiting for after it was cranked
+type ObjectiveProgressEvent struct {
+	ObjectiveId protocols.ObjectiveId
+	WaitingFor  protocols.WaitingFor
+	Timestamp   time.Time
 }
 
 // IsEmpty returns true if the EngineEvent contains no changes
@@ -111,7 +208,9 @@ func (ee *EngineEvent) IsEmpty() bool {
 		len(ee.FailedObjectives) == 0 &&
 		len(ee.ReceivedVouchers) == 0 &&
 		len(ee.LedgerChannelUpdates) == 0 &&
-		len(ee.PaymentChannelUpdates) == 0
+		len(ee.PaymentChannelUpdates) == 0 &&
+		len(ee.ObjectiveProgress) == 0 &&
+		len(ee.RejectedObjectives) == 0
 }
 
 func (ee *EngineEvent) Merge(other EngineEvent) {
@@ -120,6 +219,8 @@ func (ee *EngineEvent) Merge(other EngineEvent) {
 	ee.ReceivedVouchers = append(ee.ReceivedVouchers, other.ReceivedVouchers...)
 	ee.LedgerChannelUpdates = append(ee.LedgerChannelUpdates, other.LedgerChannelUpdates...)
 	ee.PaymentChannelUpdates = append(ee.PaymentChannelUpdates, other.PaymentChannelUpdates...)
+	ee.ObjectiveProgress = append(ee.ObjectiveProgress, other.ObjectiveProgress...)
+	ee.RejectedObjectives = append(ee.RejectedObjectives, other.RejectedObjectives...)
 }
 
 type CompletedObjectiveEvent struct {
@@ -129,8 +230,31 @@ type CompletedObjectiveEvent struct {
 // Response is the return type that asynchronous API calls "resolve to". Such a call returns a go channel of type Response.
 type Response struct{}
 
+// SetTracerProvider replaces the provider of the tracer which records a span for the lifecycle of each objective.
+// Spans are discarded by default. It must be called before the engine is used.
+func (e *Engine) SetTracerProvider(provider trace.TracerProvider) {
+	*e.tracer = *newObjectiveTracer(provider)
+}
+
 // NewEngine is the constructor for an Engine
-func New(vm *payments.VoucherManager, msg messageservice.MessageService, chain chainservice.ChainService, store store.Store, policymaker PolicyMaker, eventHandler func(EngineEvent)) Engine {
+// If metricsApi is nil, engine metrics are discarded.
+// If outcomeValidator is nil, proposed outcomes are accepted if they conserve the channel's funds.
+func New(vm *payments.VoucherManager, msg messageservice.MessageService, chain chainservice.ChainService, store store.Store, policymaker PolicyMaker, eventHandler func(EngineEvent), metricsApi MetricsApi, outcomeValidator outcome.Validator) Engine {
+	return newEngine(vm, msg, newSingleChainRouter(chain), store, policymaker, eventHandler, metricsApi, outcomeValidator)
+}
+
+// NewMultiChain constructs an Engine which operates channels on several chains, routing each channel's transactions and events
+// to the chain it was opened on. The first chain service is the default, which opens the channels whose requests name no chain.
+// The last block seen on each other chain is recorded with SetLastBlockNumSeenOnChain, so its chain service can resume from there.
+func NewMultiChain(vm *payments.VoucherManager, msg messageservice.MessageService, chains []chainservice.ChainService, store store.Store, policymaker PolicyMaker, eventHandler func(EngineEvent), metricsApi MetricsApi, outcomeValidator outcome.Validator) (Engine, error) {
+	router, err := newChainRouter(chains)
+	if err != nil {
+		return Engine{}, err
+	}
+	return newEngine(vm, msg, router, store, policymaker, eventHandler, metricsApi, outcomeValidator), nil
+}
+
+func newEngine(vm *payments.VoucherManager, msg messageservice.MessageService, chains *chainRouter, store store.Store, policymaker PolicyMaker, eventHandler func(EngineEvent), metricsApi MetricsApi, outcomeValidator outcome.Validator) Engine {
 	e := Engine{}
 	e.logger = logging.LoggerWithAddress(slog.Default(), *store.GetAddress())
 	e.store = store
@@ -139,26 +263,52 @@ func New(vm *payments.VoucherManager, msg messageservice.MessageService, chain c
 	// bind to inbound chans
 	e.ObjectiveRequestsFromAPI = make(chan protocols.ObjectiveRequest)
 	e.PaymentRequestsFromAPI = make(chan PaymentRequest)
+	e.CancelRequestsFromAPI = make(chan CancelRequest)
 
-	e.fromChain = chain.EventFeed()
+	e.fromChain = chains.defaultChain.EventFeed()
+	e.fromOtherChains = make(chan chainEvent)
 	e.fromMsg = msg.P2PMessages()
 	e.signRequests = msg.SignRequests()
+	e.failedTxs = make(chan failedTransaction, 100)
+	e.txRetries = make(chan types.Destination, 100)
+	e.pausedObjectives = &safesync.Map[bool]{}
+	e.spawnedObjectives = make(map[protocols.ObjectiveId]*spawnedObjective)
 
-	e.chain = chain
+	e.chains = chains
 	e.msg = msg
 
 	e.eventHandler = eventHandler
 
 	e.policymaker = policymaker
+	e.approvals = &atomic.Pointer[PolicyMaker]{}
+	e.approvals.Store(&policymaker)
+
+	if outcomeValidator == nil {
+		outcomeValidator = outcome.ConservationValidator{}
+	}
+	e.outcomeValidator = outcomeValidator
+	e.checkAssetCode = &atomic.Bool{}
 
 	e.vm = vm
 
+	e.metrics = NewMetricsRecorder(metricsApi)
+
+	if err := e.chains.loadChannelChains(store); err != nil {
+		e.logger.Error("Could not load the chains of channels", "error", err)
+	}
+	e.watchKnownChannels()
+	e.loadObjectiveDeadlines()
+	e.loadChannelActivity()
+
 	e.logger.Info("Constructed Engine")
 
 	e.wg = &sync.WaitGroup{}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	e.cancel = cancel
+	e.txWorkers = newChannelWorkerPool(ctx)
+	e.tracer = newObjectiveTracer(nil)
+	e.chains.forwardEvents(ctx, e.fromOtherChains)
 
 	e.wg.Add(1)
 	go e.run(ctx)
@@ -166,45 +316,158 @@ func New(vm *payments.VoucherManager, msg messageservice.MessageService, chain c
 	return e
 }
 
+// watchKnownChannels registers the channels in the store with the chain service, so that their chain events are delivered.
+// Channels opened later are registered as their objectives are cranked.
+func (e *Engine) watchKnownChannels() {
+	channels, err := e.store.GetChannelsByParticipant(*e.store.GetAddress())
+	if err != nil {
+		e.logger.Error("Could not load channels to watch on chain", "error", err)
+	}
+	for _, c := range channels {
+		e.chains.forChannel(c.Id).WatchChannel(c.Id)
+	}
+
+	consensusChannels, err := e.store.GetAllConsensusChannels()
+	if err != nil {
+		e.logger.Error("Could not load ledger channels to watch on chain", "error", err)
+	}
+	for _, cc := range consensusChannels {
+		e.chains.forChannel(cc.Id).WatchChannel(cc.Id)
+	}
+}
+
+// SetPolicy replaces the PolicyMaker which decides whether to approve or reject objectives proposed by counterparties.
+// Objectives received after SetPolicy returns are judged by the new policy. It is safe to call while the engine is running.
+// Closing idle channels, retrying spawned objectives and enforcing deadlines remain decided by the PolicyMaker the engine was created with.
+func (e *Engine) SetPolicy(policymaker PolicyMaker) {
+	e.approvals.Store(&policymaker)
+}
+
+// policy returns the PolicyMaker which currently decides whether to approve or reject objectives
+func (e *Engine) policy() PolicyMaker {
+	return *e.approvals.Load()
+}
+
 func (e *Engine) Close() error {
 	e.cancel()
 	e.wg.Wait()
+	e.chains.wait()
+	// Transactions already handed to a chain service finish submitting before the chain services are closed
+	e.txWorkers.wait()
 	if err := e.msg.Close(); err != nil {
 		return err
 	}
 
-	return e.chain.Close()
+	var err error
+	for _, chain := range e.chains.all() {
+		err = errors.Join(err, chain.Close())
+	}
+	return err
 }
 
 // run kicks of an infinite loop that waits for communications on the supplied channels, and handles them accordingly
 // The loop exits when the context is cancelled.
 func (e *Engine) run(ctx context.Context) {
+	// Chain events are only delivered for this node's channels, so the last block seen is also recorded periodically
+	blockTicker := time.NewTicker(blockNumSaveInterval)
+	defer blockTicker.Stop()
+	chainConnectionTicker := time.NewTicker(chainConnectionCheckInterval)
+	defer chainConnectionTicker.Stop()
+	// Payment channels are only checked for inactivity if the policy maker closes idle channels
+	var idleChannelChecks <-chan time.Time
+	if timeout := e.idleChannelTimeout(); timeout > 0 {
+		idleChannelTicker := time.NewTicker(idleChannelCheckInterval(timeout))
+		defer idleChannelTicker.Stop()
+		idleChannelChecks = idleChannelTicker.C
+	}
+	// Spawned objectives are only checked for stalling if the policy maker retries them
+	var spawnRetryChecks <-chan time.Time
+	if timeout, _ := e.spawnRetryPolicy(); timeout > 0 {
+		spawnRetryTicker := time.NewTicker(spawnRetryCheckInterval(timeout))
+		defer spawnRetryTicker.Stop()
+		spawnRetryChecks = spawnRetryTicker.C
+	}
+	// Objectives are only checked against their deadlines if the policy maker sets them
+	var deadlineChecks <-chan time.Time
+	if deadline := e.objectiveDeadline(); deadline > 0 {
+		deadlineTicker := time.NewTicker(deadlineCheckInterval(deadline))
+		defer deadlineTicker.Stop()
+		deadlineChecks = deadlineTicker.C
+	}
+
 	for {
 		var res EngineEvent
 		var err error
 
-		blockTicker := time.NewTicker(15 * time.Second)
-
+		// API requests are user initiated, so they are serviced ahead of any other pending input.
+		// Otherwise a flood of messages or chain events could delay them indefinitely.
 		select {
-
 		case or := <-e.ObjectiveRequestsFromAPI:
-			res, err = e.handleObjectiveRequest(or)
+			res, err = e.handleAPIRequest(or)
 		case pr := <-e.PaymentRequestsFromAPI:
-			res, err = e.handlePaymentRequest(pr)
-		case chainEvent := <-e.fromChain:
-			res, err = e.handleChainEvent(chainEvent)
-		case message := <-e.fromMsg:
-			res, err = e.handleMessage(message)
-		case proposal := <-e.fromLedger:
-			res, err = e.handleProposal(proposal)
-		case signReq := <-e.signRequests:
-			err = e.handleSignRequest(signReq)
-		case <-blockTicker.C:
-			blockNum := e.chain.GetLastConfirmedBlockNum()
-			err = e.store.SetLastBlockNumSeen(blockNum)
-		case <-ctx.Done():
-			e.wg.Done()
-			return
+			res, err = e.handleAPIRequest(pr)
+		case cr := <-e.CancelRequestsFromAPI:
+			res, err = e.handleAPIRequest(cr)
+		default:
+			select {
+			case or := <-e.ObjectiveRequestsFromAPI:
+				res, err = e.handleAPIRequest(or)
+			case pr := <-e.PaymentRequestsFromAPI:
+				res, err = e.handleAPIRequest(pr)
+			case cr := <-e.CancelRequestsFromAPI:
+				res, err = e.handleAPIRequest(cr)
+			case chainEvent := <-e.fromChain:
+				stopTimer := e.metrics.RecordHandlerDuration("handle_chain_event")
+				// The store's last block seen refers to the default chain, and the other chains have their own.
+				// Mined transactions are reported before their block is confirmed, so they do not advance it.
+				if _, mined := chainEvent.(chainservice.TransactionMinedEvent); !mined {
+					err = e.store.SetLastBlockNumSeen(chainEvent.BlockNum())
+				}
+				if err == nil {
+					res, err = e.handleChainEvent(chainEvent)
+				}
+				stopTimer()
+			case chainEvent := <-e.fromOtherChains:
+				stopTimer := e.metrics.RecordHandlerDuration("handle_chain_event")
+				if _, mined := chainEvent.Event.(chainservice.TransactionMinedEvent); !mined {
+					err = e.store.SetLastBlockNumSeenOnChain(chainEvent.chainId, chainEvent.BlockNum())
+				}
+				if err == nil {
+					res, err = e.handleChainEvent(chainEvent.Event)
+				}
+				stopTimer()
+			case message := <-e.fromMsg:
+				stopTimer := e.metrics.RecordHandlerDuration("handle_message")
+				res, err = e.handleMessage(message)
+				stopTimer()
+			case proposal := <-e.fromLedger:
+				stopTimer := e.metrics.RecordHandlerDuration("handle_proposal")
+				res, err = e.handleProposal(proposal)
+				stopTimer()
+			case signReq := <-e.signRequests:
+				stopTimer := e.metrics.RecordHandlerDuration("handle_sign_request")
+				err = e.handleSignRequest(signReq)
+				stopTimer()
+			case failed := <-e.failedTxs:
+				err = e.handleFailedTransaction(ctx, failed)
+			case channelId := <-e.txRetries:
+				stopTimer := e.metrics.RecordHandlerDuration("handle_transaction_retry")
+				res, err = e.retryTransactions(channelId)
+				stopTimer()
+			case <-chainConnectionTicker.C:
+				res, err = e.resumePausedObjectives()
+			case <-idleChannelChecks:
+				res = e.closeIdleChannels()
+			case <-spawnRetryChecks:
+				res, err = e.retryStalledObjectives()
+			case <-deadlineChecks:
+				err = e.escalateStalledObjectives()
+			case <-blockTicker.C:
+				err = e.recordLastBlocksSeen()
+			case <-ctx.Done():
+				e.wg.Done()
+				return
+			}
 		}
 
 		// Handle errors
@@ -222,14 +485,35 @@ func (e *Engine) run(ctx context.Context) {
 	}
 }
 
+// handleAPIRequest handles an objective, payment or cancel request from the API, recording how long it took.
+// The run loop receives API requests in two places, so that they are serviced first, and both hand them to this handler.
+func (e *Engine) handleAPIRequest(request any) (EngineEvent, error) {
+	switch r := request.(type) {
+	case PaymentRequest:
+		defer e.metrics.RecordHandlerDuration("handle_payment_request")()
+		return e.handlePaymentRequest(r)
+	case CancelRequest:
+		defer e.metrics.RecordHandlerDuration("handle_cancel_request")()
+		return e.handleCancelRequest(r)
+	case protocols.ObjectiveRequest:
+		defer e.metrics.RecordHandlerDuration("handle_objective_request")()
+		return e.handleObjectiveRequest(r)
+	default:
+		return EngineEvent{}, fmt.Errorf("unknown api request %T", request)
+	}
+}
+
 // handleProposal handles a Proposal returned to the engine from
 // a running ledger channel by pulling its corresponding objective
 // from the store and attempting progress.
 func (e *Engine) handleProposal(proposal consensus_channel.Proposal) (EngineEvent, error) {
 	id := getProposalObjectiveId(proposal)
 
+	e.logger.Debug("Handling proposal", logging.WithObjectiveIdAttribute(id), logging.WithChannelIdAttribute(proposal.LedgerID), "proposal-type", proposal.Type())
+
 	obj, err := e.store.GetObjectiveById(id)
 	if err != nil {
+		e.logger.Error("Could not get objective for proposal", logging.WithObjectiveIdAttribute(id), "error", err)
 		return EngineEvent{}, err
 	}
 	if obj.GetStatus() == protocols.Completed {
@@ -264,36 +548,82 @@ func (e *Engine) handleSignRequest(sigReq p2pms.SignatureRequest) error {
 //   - attempts progress on related objectives which may have become unblocked.
 func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 	e.logMessage(message, Incoming)
+	e.metrics.RecordMessageReceived()
 	allCompleted := EngineEvent{}
 
+	// A message carrying a signature which is not from the participant it claims to be from is rejected as a whole
+	for _, payload := range message.ObjectivePayloads {
+		if err := protocols.VerifyPayloadSignatures(payload); err != nil {
+			e.logger.Error("Rejecting message with invalid signatures", logging.WithObjectiveIdAttribute(payload.ObjectiveId), "from", message.From.String(), "error", err)
+			return EngineEvent{}, fmt.Errorf("message from %s: %w", message.From, err)
+		}
+	}
+
 	for _, payload := range message.ObjectivePayloads {
+		e.logger.Debug("Handling objective payload", logging.WithObjectiveIdAttribute(payload.ObjectiveId), "payload-type", payload.Type, "from", message.From.String())
+
+		if admit, reason := e.admitObjective(payload.ObjectiveId, message.From); !admit {
+			e.logger.Info("Policymaker turned away objective", logging.WithObjectiveIdAttribute(payload.ObjectiveId), "reason", reason)
+			messages := protocols.CreateRejectionNoticeMessage(payload.ObjectiveId, message.From)
+			protocols.AddRejectionReason(messages, payload.ObjectiveId, reason)
+			e.metrics.RecordObjectiveRejected(payload.ObjectiveId)
+			// The objective was never stored, so there is nothing else to do for it
+			if err := e.executeSideEffects(protocols.SideEffects{MessagesToSend: messages}); err != nil {
+				return allCompleted, err
+			}
+			continue
+		}
 
 		objective, err := e.getOrCreateObjective(payload)
 		if err != nil {
+			e.logger.Error("Could not get or create objective from payload", logging.WithObjectiveIdAttribute(payload.ObjectiveId), "error", err)
 			return EngineEvent{}, err
 		}
+		// A span opened for a completed or rejected objective, such as one receiving a duplicate message, would never be ended
+		if status := objective.GetStatus(); status == protocols.Unapproved || status == protocols.Approved {
+			e.tracer.startObjective(objective.Id(), message.TraceContext[objective.Id()])
+		}
+		e.tracer.addEvent(objective.Id(), MessageReceivedEventName, PeerAttribute.String(message.From.String()))
 
 		if objective.GetStatus() == protocols.Unapproved {
-			e.logger.Info("Policymaker for objective", "policy-maker", e.policymaker, logging.WithObjectiveIdAttribute(objective.Id()))
-			if e.policymaker.ShouldApprove(objective) {
+			// The policy is read once, so that the objective is judged by a single policy even if it is replaced meanwhile
+			policy := e.policy()
+			e.logger.Info("Policymaker for objective", "policy-maker", policy, logging.WithObjectiveIdAttribute(objective.Id()))
+			approve, reason := e.assetsDeployed(objective)
+			if approve {
+				if policymaker, ok := policy.(PeerPolicyMaker); ok {
+					approve, reason = policymaker.ShouldApproveFrom(objective, message.From)
+				} else {
+					approve, reason = policy.ShouldApprove(objective)
+				}
+			}
+			if approve {
+				approve, reason = approveReallocation(policy, objective)
+			}
+			if approve {
 				objective = objective.Approve()
 
-				ddfo, ok := objective.(*directdefund.Objective)
-				if ok {
-					// If we just approved a direct defund objective, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
-					err := e.store.DestroyConsensusChannel(ddfo.C.Id)
+				switch o := objective.(type) {
+				case *directdefund.Objective, *ledgertopup.Objective, *ledgerrecycle.Objective:
+					// If we just approved a direct defund, top up or recycle objective, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
+					err := e.store.DestroyConsensusChannel(o.OwnsChannel())
 					if err != nil {
 						return EngineEvent{}, err
 					}
 				}
 			} else {
+				e.logger.Info("Policymaker rejected objective", logging.WithObjectiveIdAttribute(objective.Id()), "reason", reason)
 				objective, sideEffects := objective.Reject()
+				protocols.AddRejectionReason(sideEffects.MessagesToSend, objective.Id(), reason)
 				err = e.store.SetObjective(objective)
 				if err != nil {
 					return EngineEvent{}, err
 				}
+				e.metrics.RecordObjectiveRejected(objective.Id())
+				e.tracer.rejectObjective(objective.Id())
 
 				allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, objective)
+				allCompleted.RejectedObjectives = append(allCompleted.RejectedObjectives, ObjectiveRejection{ObjectiveId: objective.Id(), Reason: reason, By: *e.store.GetAddress()})
 
 				err = e.executeSideEffects(sideEffects)
 				// An error would mean we failed to send a message. But the objective is still "completed".
@@ -312,8 +642,15 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 			continue
 		}
 
+		if err := e.validateProposedOutcome(objective, payload); err != nil {
+			// The proposed state is not accepted, so the objective waits for an acceptable one
+			e.logger.Error("Ignoring payload with unacceptable outcome", logging.WithObjectiveIdAttribute(objective.Id()), "error", err)
+			continue
+		}
+
 		updatedObjective, err := objective.Update(payload)
 		if err != nil {
+			e.logger.Error("Could not update objective with payload", logging.WithObjectiveIdAttribute(objective.Id()), "error", err)
 			return EngineEvent{}, err
 		}
 
@@ -333,9 +670,11 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 	for _, entry := range message.LedgerProposals { // The ledger protocol requires us to process these proposals in turnNum order.
 		// Here we rely on the sender having packed them into the message in that order, and do not apply any checks or sorting of our own.
 		id := getProposalObjectiveId(entry.Proposal)
+		e.logger.Debug("Handling ledger proposal", logging.WithObjectiveIdAttribute(id), logging.WithChannelIdAttribute(entry.Proposal.LedgerID), "turn-num", entry.TurnNum)
 
 		o, err := e.store.GetObjectiveById(id)
 		if err != nil {
+			e.logger.Error("Could not get objective for ledger proposal", logging.WithObjectiveIdAttribute(id), "error", err)
 			return EngineEvent{}, err
 		}
 		if o.GetStatus() == protocols.Completed {
@@ -343,6 +682,10 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 
 			continue
 		}
+		if o.GetStatus() == protocols.Rejected {
+			e.logger.Info("Ignoring proposal for rejected objective", logging.WithObjectiveIdAttribute(id))
+			continue
+		}
 		objective, isProposalReceiver := o.(protocols.ProposalReceiver)
 		if !isProposalReceiver {
 			return EngineEvent{}, fmt.Errorf("received a proposal for an objective which cannot receive proposals %s", objective.Id())
@@ -350,6 +693,7 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 
 		updatedObjective, err := objective.ReceiveProposal(entry)
 		if err != nil {
+			e.logger.Error("Could not receive ledger proposal", logging.WithObjectiveIdAttribute(id), "error", err)
 			return EngineEvent{}, err
 		}
 
@@ -363,8 +707,11 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 	}
 
 	for _, entry := range message.RejectedObjectives {
+		reason := message.RejectionReasons[entry]
+		e.logger.Info("Counterparty rejected objective", logging.WithObjectiveIdAttribute(entry), "from", message.From.String(), "reason", reason)
 		objective, err := e.store.GetObjectiveById(entry)
 		if err != nil {
+			e.logger.Error("Could not get rejected objective", logging.WithObjectiveIdAttribute(entry), "error", err)
 			return EngineEvent{}, err
 		}
 		if objective.GetStatus() == protocols.Rejected {
@@ -381,8 +728,17 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 		if err != nil {
 			return EngineEvent{}, err
 		}
+		if restorer, ok := objective.(protocols.ConsensusChannelRestorer); ok {
+			// The objective never took effect, so the ledger channel is governed by its consensus channel again
+			if err := e.restoreConsensusChannel(restorer); err != nil {
+				return EngineEvent{}, err
+			}
+		}
+		e.metrics.RecordObjectiveRejected(objective.Id())
+		e.tracer.rejectObjective(objective.Id())
 
 		allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, objective)
+		allCompleted.RejectedObjectives = append(allCompleted.RejectedObjectives, ObjectiveRejection{ObjectiveId: objective.Id(), Reason: reason, By: message.From})
 	}
 
 	for _, voucher := range message.Payments {
@@ -390,10 +746,19 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 		// TODO: return the amount we paid?
 		_, _, err := e.vm.Receive(voucher)
 
-		allCompleted.ReceivedVouchers = append(allCompleted.ReceivedVouchers, voucher)
+		if errors.Is(err, payments.ErrStaleVoucher) {
+			// Vouchers from a peer may arrive out of order, so a stale voucher is neither credited nor reported, but is not an error either
+			e.logger.Debug("Ignoring stale payment voucher", logging.WithChannelIdAttribute(voucher.ChannelId), "amount", voucher.Amount)
+			continue
+		}
 		if err != nil {
+			e.logger.Error("Could not accept payment voucher", logging.WithChannelIdAttribute(voucher.ChannelId), "error", err)
 			return EngineEvent{}, fmt.Errorf("error accepting payment voucher: %w", err)
 		}
+		allCompleted.ReceivedVouchers = append(allCompleted.ReceivedVouchers, voucher)
+		if err := e.recordChannelActivity(voucher.ChannelId); err != nil {
+			return EngineEvent{}, err
+		}
 		c, ok := e.store.GetChannelById(voucher.ChannelId)
 		if !ok {
 			return EngineEvent{}, fmt.Errorf("could not fetch channel for voucher %+v", voucher)
@@ -417,28 +782,59 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 	return allCompleted, nil
 }
 
+// approveReallocation rejects a ledger recycle proposed by a counterparty which lowers our allocation,
+// unless the policy is a ReallocationPolicyMaker which approves it
+func approveReallocation(policy PolicyMaker, objective protocols.Objective) (bool, string) {
+	lro, ok := objective.(*ledgerrecycle.Objective)
+	if !ok || !lro.LowersMyAllocation() {
+		return true, ""
+	}
+	if policymaker, ok := policy.(ReallocationPolicyMaker); ok {
+		return policymaker.ShouldApproveReallocation(objective)
+	}
+	return false, ledgerrecycle.ErrAllocationLowered.Error()
+}
+
+// validateProposedOutcome checks any channel outcome proposed by the payload with the engine's outcome validator
+func (e *Engine) validateProposedOutcome(objective protocols.Objective, payload protocols.ObjectivePayload) error {
+	receiver, ok := objective.(protocols.OutcomeReceiver)
+	if !ok {
+		return nil
+	}
+	current, proposed, ok, err := receiver.ProposedOutcome(payload)
+	if err != nil || !ok {
+		// Malformed payloads are reported when the objective is updated
+		return nil
+	}
+	return e.outcomeValidator.Validate(current, proposed)
+}
+
 // handleChainEvent handles a Chain Event from the blockchain.
 // It:
 //   - reads an objective from the store,
 //   - generates an updated objective, and
 //   - attempts progress.
 func (e *Engine) handleChainEvent(chainEvent chainservice.Event) (EngineEvent, error) {
-	e.logger.Info("Handling chain event", "blockNum", chainEvent.BlockNum(), "event", chainEvent)
-	err := e.store.SetLastBlockNumSeen(chainEvent.BlockNum())
-	if err != nil {
-		return EngineEvent{}, err
+	e.logger.Info("Handling chain event", logging.WithChannelIdAttribute(chainEvent.ChannelID()), "blockNum", chainEvent.BlockNum(), "event", chainEvent)
+	e.metrics.RecordChainEventHandled()
+	if mined, ok := chainEvent.(chainservice.TransactionMinedEvent); ok {
+		e.handleTransactionMined(mined)
+		return EngineEvent{}, nil
 	}
-
 	c, ok := e.store.GetChannelById(chainEvent.ChannelID())
 	if !ok {
-		// TODO: Right now the chain service returns chain events for ALL channels even those we aren't involved in
-		// for now we can ignore channels we aren't involved in
-		// in the future the chain service should allow us to register for specific channels
+		// Ledger channels are governed by a ConsensusChannel once funded, but their holdings can still change (e.g. when topped up)
+		if cc, err := e.store.GetConsensusChannelById(chainEvent.ChannelID()); err == nil {
+			return EngineEvent{}, e.updateConsensusChannelHoldings(cc, chainEvent)
+		}
+		// Chain services may deliver events for channels which have not been stored yet or have since been destroyed
+		e.logger.Debug("Ignoring chain event for unknown channel", logging.WithChannelIdAttribute(chainEvent.ChannelID()))
 		return EngineEvent{}, nil
 	}
 
 	updatedChannel, err := c.UpdateWithChainEvent(chainEvent)
 	if err != nil {
+		e.logger.Error("Could not update channel with chain event", logging.WithChannelIdAttribute(c.Id), "error", err)
 		return EngineEvent{}, err
 	}
 
@@ -455,12 +851,40 @@ func (e *Engine) handleChainEvent(chainEvent chainservice.Event) (EngineEvent, e
 	return EngineEvent{}, nil
 }
 
+// updateConsensusChannelHoldings records any change to the on chain holdings of the consensus channel described by the chain event,
+// and any challenge registered against it or cleared.
+func (e *Engine) updateConsensusChannelHoldings(cc *consensus_channel.ConsensusChannel, chainEvent chainservice.Event) error {
+	if cc.OnChainFunding == nil {
+		cc.OnChainFunding = types.Funds{}
+	}
+	switch ev := chainEvent.(type) {
+	case chainservice.DepositedEvent:
+		cc.OnChainFunding[ev.Asset] = ev.NowHeld
+	case chainservice.AllocationUpdatedEvent:
+		cc.OnChainFunding[ev.AssetAddress] = ev.AssetAmount
+	case chainservice.ChallengeRegisteredEvent:
+		cc.ChallengeBlock = ev.BlockNum()
+		cc.FinalizesAt = ev.FinalizesAt()
+		e.logger.Warn("Consensus channel has been challenged", logging.WithChannelIdAttribute(cc.Id), "finalizes-at", cc.FinalizesAt)
+		return e.store.SetConsensusChannel(cc)
+	case chainservice.ChallengeClearedEvent:
+		cc.ChallengeBlock = 0
+		cc.FinalizesAt = 0
+		e.logger.Info("Challenge of consensus channel has been cleared", logging.WithChannelIdAttribute(cc.Id))
+		return e.store.SetConsensusChannel(cc)
+	default:
+		return nil
+	}
+	e.logger.Debug("Updating holdings of consensus channel", logging.WithChannelIdAttribute(cc.Id), "holdings", cc.OnChainFunding.String())
+	return e.store.SetConsensusChannel(cc)
+}
+
 // handleObjectiveRequest handles an ObjectiveRequest (triggered by a client API call).
 // It will attempt to spawn a new, approved objective.
 func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEvent, error) {
 	myAddress := *e.store.GetAddress()
 
-	chainId, err := e.chain.GetChainId()
+	chainId, err := e.chains.defaultChain.GetChainId()
 	if err != nil {
 		return EngineEvent{}, fmt.Errorf("could not get chain id from chain service: %w", err)
 	}
@@ -469,6 +893,9 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 	failedEngineEvent := EngineEvent{FailedObjectives: []protocols.ObjectiveId{objectiveId}}
 	e.logger.Info("handling new objective request", logging.WithObjectiveIdAttribute(objectiveId))
 	defer or.SignalObjectiveStarted()
+	// Objectives which fund a new channel are retried if they stall on their first step
+	var objective protocols.Objective
+	retried := false
 	switch request := or.(type) {
 
 	case virtualfund.ObjectiveRequest:
@@ -476,6 +903,9 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create virtualfund objective for %+v: %w", request, err)
 		}
+		if err := e.chains.assignFundedChannel(&vfo, ""); err != nil {
+			return failedEngineEvent, err
+		}
 		// Only Alice or Bob care about registering the objective and keeping track of vouchers
 		lastParticipant := uint(len(vfo.V.Participants) - 1)
 		if vfo.MyRole == lastParticipant || vfo.MyRole == payments.PAYER_INDEX {
@@ -488,7 +918,7 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("could not register channel with payment/receipt manager: %w", err)
 		}
-		return e.attemptProgress(&vfo)
+		objective, retried = &vfo, true
 
 	case virtualdefund.ObjectiveRequest:
 		minAmount := big.NewInt(0)
@@ -503,14 +933,24 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create virtualdefund objective for %+v: %w", request, err)
 		}
-		return e.attemptProgress(&vdfo)
+		objective = &vdfo
 
 	case directfund.ObjectiveRequest:
+		channelChain := ""
+		if request.ChainId != nil {
+			channelChain = request.ChainId.String()
+		}
+		if _, err := e.chains.forChain(channelChain); err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create directfund objective for %+v: %w", request, err)
+		}
 		dfo, err := directfund.NewObjective(request, true, myAddress, chainId, e.store.GetChannelsByParticipant, e.store.GetConsensusChannel)
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create directfund objective for %+v: %w", request, err)
 		}
-		return e.attemptProgress(&dfo)
+		if err := e.chains.assign(dfo.OwnsChannel(), channelChain); err != nil {
+			return failedEngineEvent, err
+		}
+		objective, retried = &dfo, true
 
 	case directdefund.ObjectiveRequest:
 		ddfo, err := directdefund.NewObjective(request, true, e.store.GetConsensusChannelById)
@@ -522,11 +962,51 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not destroy consensus channel for %+v: %w", request, err)
 		}
-		return e.attemptProgress(&ddfo)
+		objective = &ddfo
+
+	case ledgertopup.ObjectiveRequest:
+		lto, err := ledgertopup.NewObjective(request, true, e.store.GetConsensusChannelById)
+		if err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create ledgertopup objective for %+v: %w", request, err)
+		}
+		// If lto creation was successful, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
+		err = e.store.DestroyConsensusChannel(request.ChannelId)
+		if err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not destroy consensus channel for %+v: %w", request, err)
+		}
+		objective = &lto
+
+	case ledgerrecycle.ObjectiveRequest:
+		lro, err := ledgerrecycle.NewObjective(request, true, e.store.GetConsensusChannelById)
+		if err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create ledgerrecycle objective for %+v: %w", request, err)
+		}
+		// If lro creation was successful, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
+		err = e.store.DestroyConsensusChannel(request.ChannelId)
+		if err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not destroy consensus channel for %+v: %w", request, err)
+		}
+		objective = &lro
+
+	case appupdate.ObjectiveRequest:
+		paymentApp := e.chains.forChannel(request.State.ChannelId()).GetVirtualPaymentAppAddress()
+		auo, err := appupdate.NewObjective(request, true, paymentApp, e.store.GetChannelById)
+		if err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create appupdate objective for %+v: %w", request, err)
+		}
+		objective = &auo
 
 	default:
 		return failedEngineEvent, fmt.Errorf("handleAPIEvent: Unknown objective type %T", request)
 	}
+
+	// The objective is only in flight once it has been constructed, as a request which fails is never finished
+	e.metrics.RecordObjectiveStarted(objectiveId)
+	e.tracer.startObjective(objectiveId, "")
+	if retried {
+		return e.attemptSpawnedProgress(objective)
+	}
+	return e.attemptProgress(objective)
 }
 
 // handlePaymentRequest handles an PaymentRequest (triggered by a client API call).
@@ -537,6 +1017,7 @@ func (e *Engine) handlePaymentRequest(request PaymentRequest) (EngineEvent, erro
 		return ee, fmt.Errorf("handleAPIEvent: Empty payment request")
 	}
 	cId := request.ChannelId
+	e.logger.Debug("Handling payment request", logging.WithChannelIdAttribute(cId), "amount", request.Amount)
 	voucher, err := e.vm.Pay(
 		cId,
 		request.Amount,
@@ -544,6 +1025,9 @@ func (e *Engine) handlePaymentRequest(request PaymentRequest) (EngineEvent, erro
 	if err != nil {
 		return ee, fmt.Errorf("handleAPIEvent: Error making payment: %w", err)
 	}
+	if err := e.recordChannelActivity(cId); err != nil {
+		return ee, err
+	}
 	c, ok := e.store.GetChannelById(cId)
 	if !ok {
 		return ee, fmt.Errorf("handleAPIEvent: Could not get channel from the store %s", cId)
@@ -562,18 +1046,247 @@ func (e *Engine) handlePaymentRequest(request PaymentRequest) (EngineEvent, erro
 	return ee, e.executeSideEffects(se)
 }
 
+// handleCancelRequest handles a CancelRequest (triggered by a client API call).
+// If the objective is still cancellable, it is rejected, its counterparties are notified and it is returned as completed.
+func (e *Engine) handleCancelRequest(request CancelRequest) (ee EngineEvent, err error) {
+	defer func() { request.result <- err }()
+
+	objective, err := e.store.GetObjectiveById(request.ObjectiveId)
+	if err != nil {
+		return EngineEvent{}, fmt.Errorf("could not get objective %s: %w: %w", request.ObjectiveId, protocols.ErrNotCancellable, err)
+	}
+	if status := objective.GetStatus(); status == protocols.Completed || status == protocols.Rejected {
+		return EngineEvent{}, fmt.Errorf("objective %s is no longer in progress: %w", objective.Id(), protocols.ErrNotCancellable)
+	}
+	if c, ok := objective.(protocols.Cancellable); !ok || !c.IsCancellable() {
+		return EngineEvent{}, fmt.Errorf("objective %s is past the point of no return: %w", objective.Id(), protocols.ErrNotCancellable)
+	}
+
+	e.logger.Info("Cancelling objective", logging.WithObjectiveIdAttribute(objective.Id()))
+	rejected, sideEffects, err := e.abandonObjective(objective)
+	if err != nil {
+		return EngineEvent{}, err
+	}
+
+	ee.CompletedObjectives = append(ee.CompletedObjectives, rejected)
+	// An error would mean we failed to notify a counterparty. But the objective is still cancelled.
+	err = e.executeSideEffects(sideEffects)
+	return ee, err
+}
+
+// abandonObjective rejects the objective and releases the channel it owns.
+// It returns the side effects which notify the objective's counterparties.
+func (e *Engine) abandonObjective(objective protocols.Objective) (protocols.Objective, protocols.SideEffects, error) {
+	rejected, sideEffects := objective.Reject()
+	if err := e.store.SetObjective(rejected); err != nil {
+		return nil, protocols.SideEffects{}, err
+	}
+	if err := e.store.ReleaseChannelFromOwnership(rejected.OwnsChannel()); err != nil {
+		return nil, protocols.SideEffects{}, err
+	}
+	if restorer, ok := rejected.(protocols.ConsensusChannelRestorer); ok {
+		if err := e.restoreConsensusChannel(restorer); err != nil {
+			return nil, protocols.SideEffects{}, err
+		}
+	}
+	if e.vm.ChannelRegistered(rejected.OwnsChannel()) {
+		if err := e.vm.Remove(rejected.OwnsChannel()); err != nil {
+			return nil, protocols.SideEffects{}, err
+		}
+	}
+	e.pausedObjectives.Delete(string(rejected.Id()))
+	delete(e.spawnedObjectives, rejected.Id())
+	if err := e.forgetObjectiveDeadline(rejected.Id()); err != nil {
+		return nil, protocols.SideEffects{}, err
+	}
+	e.metrics.RecordObjectiveRejected(rejected.Id())
+	e.tracer.rejectObjective(rejected.Id())
+	return rejected, sideEffects, nil
+}
+
 // sendMessages sends out the messages and records the metrics.
 func (e *Engine) sendMessages(msgs []protocols.Message) {
 	for _, message := range msgs {
-		message.From = *e.store.GetAddress()
-		err := e.msg.Send(message)
+		e.sendMessage(message)
+	}
+	e.wg.Done()
+}
+
+// sendMessage sends the message from us
+func (e *Engine) sendMessage(message protocols.Message) {
+	message.From = *e.store.GetAddress()
+	err := e.msg.Send(message)
+	if err != nil {
+		e.logger.Error("Could not send message", "to", message.To.String(), "error", err)
+		panic(err)
+	}
+	e.logMessage(message, Outgoing)
+	e.metrics.RecordMessageSent()
+}
+
+// sendTransaction submits the transaction to the chain.
+// A failed submission is reported back to the run loop, unless the engine is shutting down, and returned.
+func (e *Engine) sendTransaction(ctx context.Context, tx protocols.ChainTransaction) error {
+	e.logger.Info("Sending chain transaction", logging.WithChannelIdAttribute(tx.ChannelId()), "transaction-type", fmt.Sprintf("%T", tx))
+
+	err := e.chains.forChannel(tx.ChannelId()).SendTransaction(tx)
+	if err != nil {
+		select {
+		case e.failedTxs <- failedTransaction{tx: tx, err: err}:
+		case <-ctx.Done():
+		}
+	}
+	return err
+}
+
+// executeSequence executes the effects one after another.
+// If a transaction cannot be submitted the remaining effects are dropped: retrying the transaction cranks the objective again, which declares them again.
+func (e *Engine) executeSequence(ctx context.Context, effects []protocols.SideEffect) {
+	defer e.wg.Done()
+	for _, effect := range effects {
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case effect.Transaction != nil:
+			// Each transaction is only marked as submitted once it has been sent,
+			// so that the transactions dropped after a failure are declared and submitted again on the retry
+			if _, err := e.submitTransaction(ctx, effect.Transaction); err != nil {
+				e.logger.Warn("Dropping the side effects which follow a failed chain transaction", logging.WithChannelIdAttribute(effect.Transaction.ChannelId()), "error", err)
+				return
+			}
+		case effect.Message != nil:
+			e.sendMessage(*effect.Message)
+		}
+	}
+}
+
+// handleTransactionMined reports a transaction which reverted or was never mined as failed, so that it is retried.
+// Objectives only advance on the adjudicator events that a successful transaction emits.
+func (e *Engine) handleTransactionMined(mined chainservice.TransactionMinedEvent) {
+	err := mined.Err()
+	if err == nil {
+		return
+	}
+	select {
+	case e.failedTxs <- failedTransaction{tx: mined.Transaction, err: err}:
+	default:
+		e.logger.Error("Too many failed transactions, not retrying", logging.WithChannelIdAttribute(mined.ChannelID()), "tx-hash", mined.TxHash, "error", err)
+	}
+}
+
+// failedTransaction is a chain transaction which could not be submitted, for example because it reverted
+type failedTransaction struct {
+	tx  protocols.ChainTransaction
+	err error
+}
+
+// chainConnectionCheckInterval is how often the engine checks whether a disconnected chain service has recovered
+const chainConnectionCheckInterval = time.Second
+
+// blockNumSaveInterval is how often the engine records the chain service's last confirmed block in the store
+const blockNumSaveInterval = 15 * time.Second
+
+// transactionRetryDelay is how long the engine waits before retrying a failed chain transaction
+var transactionRetryDelay = 5 * time.Second
+
+// handleFailedTransaction forgets that the failed transaction was submitted, by the store and by the objective which declared it,
+// so that the objective has not progressed past the submission. It then schedules that objective to be cranked again after transactionRetryDelay.
+func (e *Engine) handleFailedTransaction(ctx context.Context, failed failedTransaction) error {
+	channelId := failed.tx.ChannelId()
+	txKey := transactionKey(failed.tx)
+	e.logger.Warn("Chain transaction failed, scheduling a retry", logging.WithChannelIdAttribute(channelId), "transaction", txKey, "error", failed.err, "retry-delay", transactionRetryDelay)
+
+	err := e.store.UnsetTransactionSubmitted(channelId, txKey)
+	if err != nil {
+		return err
+	}
+	if obj, ok := e.store.GetObjectiveByChannelId(channelId); ok {
+		if retrier, ok := obj.(protocols.TransactionRetrier); ok {
+			err = e.store.SetObjective(retrier.RetryTransaction(failed.tx))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	time.AfterFunc(transactionRetryDelay, func() {
+		select {
+		case e.txRetries <- channelId:
+		case <-ctx.Done():
+		}
+	})
+	return nil
+}
+
+// retryTransactions cranks the objective which owns the channel, so that it declares any transactions which failed again.
+func (e *Engine) retryTransactions(channelId types.Destination) (EngineEvent, error) {
+	obj, ok := e.store.GetObjectiveByChannelId(channelId)
+	if !ok || obj.GetStatus() == protocols.Completed {
+		e.logger.Info("No objective owns the channel, not retrying its failed transaction", logging.WithChannelIdAttribute(channelId))
+		return EngineEvent{}, nil
+	}
+	return e.attemptProgress(obj)
+}
+
+// dependsOnChain returns true for objectives which are progressed by chain events, and may act on stale chain data while the chain service is disconnected
+func dependsOnChain(objective protocols.Objective) bool {
+	switch objective.(type) {
+	case *directfund.Objective, *directdefund.Objective, *ledgertopup.Objective:
+		return true
+	default:
+		return false
+	}
+}
+
+// resumePausedObjectives cranks the objectives which were paused while their chain service was disconnected, once it has reconnected
+func (e *Engine) resumePausedObjectives() (EngineEvent, error) {
+	paused := []protocols.ObjectiveId{}
+	e.pausedObjectives.Range(func(id string, _ bool) bool {
+		paused = append(paused, protocols.ObjectiveId(id))
+		return true
+	})
+
+	allCompleted := EngineEvent{}
+	for _, id := range paused {
+		objective, err := e.store.GetObjectiveById(id)
 		if err != nil {
-			e.logger.Error(err.Error())
-			panic(err)
+			return allCompleted, err
 		}
-		e.logMessage(message, Outgoing)
+		if !e.chains.forObjective(objective).Connected() {
+			continue
+		}
+		e.pausedObjectives.Delete(string(id))
+		if objective.GetStatus() != protocols.Approved {
+			continue
+		}
+		e.logger.Info("Chain service reconnected, resuming objective", logging.WithObjectiveIdAttribute(id))
+		progressEvent, err := e.attemptProgress(objective)
+		if err != nil {
+			return allCompleted, err
+		}
+		allCompleted.Merge(progressEvent)
+	}
+	return allCompleted, nil
+}
+
+// transactionKey returns the logical identity of a chain transaction: its purpose and a nonce
+// which distinguishes transactions with the same purpose for the same channel.
+func transactionKey(tx protocols.ChainTransaction) string {
+	switch tx := tx.(type) {
+	case protocols.DepositTransaction:
+		assets := make([]string, 0, len(tx.Deposit))
+		for asset := range tx.Deposit {
+			assets = append(assets, asset.Hex())
+		}
+		sort.Strings(assets)
+		return fmt.Sprintf("deposit-%d-%s", tx.TurnNum, strings.Join(assets, ","))
+	case protocols.WithdrawAllTransaction:
+		return fmt.Sprintf("withdrawAll-%d", tx.SignedState.State().TurnNum)
+	case protocols.ChallengeTransaction:
+		return fmt.Sprintf("challenge-%d", tx.Candidate.State().TurnNum)
+	default:
+		return fmt.Sprintf("%T", tx)
 	}
-	e.wg.Done()
 }
 
 // executeSideEffects executes the SideEffects declared by cranking an Objective or handling a payment request.
@@ -583,19 +1296,84 @@ func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) error {
 	go e.sendMessages(sideEffects.MessagesToSend)
 
 	for _, tx := range sideEffects.TransactionsToSubmit {
-		e.logger.Info("Sending chain transaction", "channel", tx.ChannelId().String())
+		tx := tx
+		e.txWorkers.submit(tx.ChannelId(), func(ctx context.Context) { _, _ = e.submitTransaction(ctx, tx) })
+	}
+	if err := e.executeSequenceOf(sideEffects); err != nil {
+		return err
+	}
+	for _, proposal := range sideEffects.ProposalsToProcess {
+		e.fromLedger <- proposal
+	}
+	return nil
+}
 
-		err := e.chain.SendTransaction(tx)
+// executeSequenceOf starts executing the sequenced side effects, skipping any transactions which have already been submitted.
+// The transactions are marked as submitted as the sequence reaches them.
+// The sequence is run by the worker of the channel of its first transaction, so that it is ordered with the channel's other transactions.
+func (e *Engine) executeSequenceOf(sideEffects protocols.SideEffects) error {
+	effects := make([]protocols.SideEffect, 0, len(sideEffects.Sequence))
+	var messages []protocols.Message
+	var channelId *types.Destination
+	for _, effect := range sideEffects.Sequence {
+		if effect.Transaction == nil {
+			if effect.Message != nil {
+				messages = append(messages, *effect.Message)
+			}
+			effects = append(effects, effect)
+			continue
+		}
+		submitted, err := e.store.IsTransactionSubmitted(effect.Transaction.ChannelId(), transactionKey(effect.Transaction))
 		if err != nil {
 			return err
 		}
+		if submitted {
+			continue
+		}
+		if channelId == nil {
+			id := effect.Transaction.ChannelId()
+			channelId = &id
+		}
+		effects = append(effects, effect)
 	}
-	for _, proposal := range sideEffects.ProposalsToProcess {
-		e.fromLedger <- proposal
+	if len(effects) == 0 {
+		return nil
 	}
+
+	e.wg.Add(1)
+	if channelId == nil {
+		// Without transactions to wait for, the sequence is just a list of messages
+		go e.sendMessages(messages)
+		return nil
+	}
+	e.txWorkers.submit(*channelId, func(ctx context.Context) { e.executeSequence(ctx, effects) })
 	return nil
 }
 
+// submitTransaction sends the transaction unless it has already been submitted, and records it as submitted once the chain service has accepted it.
+// It returns false if the transaction was skipped. It must run on the worker of the transaction's channel,
+// so that no other submission of the same transaction runs between the check and the record.
+func (e *Engine) submitTransaction(ctx context.Context, tx protocols.ChainTransaction) (bool, error) {
+	txKey := transactionKey(tx)
+	submitted, err := e.store.IsTransactionSubmitted(tx.ChannelId(), txKey)
+	if err != nil {
+		e.logger.Error("Could not check whether chain transaction was submitted", logging.WithChannelIdAttribute(tx.ChannelId()), "transaction", txKey, "error", err)
+		return false, err
+	}
+	if submitted {
+		e.logger.Info("Skipping chain transaction which has already been submitted", logging.WithChannelIdAttribute(tx.ChannelId()), "transaction", txKey)
+		return false, nil
+	}
+	if err := e.sendTransaction(ctx, tx); err != nil {
+		return false, err
+	}
+	if err := e.store.SetTransactionSubmitted(tx.ChannelId(), txKey); err != nil {
+		e.logger.Error("Could not record chain transaction as submitted", logging.WithChannelIdAttribute(tx.ChannelId()), "transaction", txKey, "error", err)
+		return true, err
+	}
+	return true, nil
+}
+
 // attemptProgress takes a "live" objective in memory and performs the following actions:
 //
 //  1. It pulls the secret key from the store
@@ -604,20 +1382,37 @@ func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) error {
 //  4. It executes any side effects that were declared during cranking
 //  5. It updates progress metadata in the store
 func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing EngineEvent, err error) {
+	if dependsOnChain(objective) && !e.chains.forObjective(objective).Connected() {
+		e.logger.Info("Chain service is disconnected, pausing objective", logging.WithObjectiveIdAttribute(objective.Id()))
+		e.pausedObjectives.Store(string(objective.Id()), true)
+		return EngineEvent{}, e.store.SetObjective(objective)
+	}
+
 	secretKey := e.store.GetChannelSecretKey()
 	var crankedObjective protocols.Objective
 	var sideEffects protocols.SideEffects
 	var waitingFor protocols.WaitingFor
 
+	crankSpan := e.tracer.startCrank(objective.Id())
+	defer crankSpan.End()
+	crankStart := time.Now()
 	crankedObjective, sideEffects, waitingFor, err = objective.Crank(secretKey)
+	e.metrics.RecordCrankDuration(time.Since(crankStart))
 	if err != nil {
+		e.logger.Error("Could not crank objective", logging.WithObjectiveIdAttribute(objective.Id()), logging.WithChannelIdAttribute(objective.OwnsChannel()), "error", err)
+		crankSpan.RecordError(err)
+		crankSpan.SetStatus(codes.Error, "crank failed")
 		return
 	}
+	crankSpan.SetAttributes(WaitingForAttribute.String(string(waitingFor)))
+	e.tracer.recordSideEffects(crankedObjective.Id(), &sideEffects)
+	e.chains.annotate(crankedObjective, sideEffects.MessagesToSend)
 
 	err = e.store.SetObjective(crankedObjective)
 	if err != nil {
 		return EngineEvent{}, err
 	}
+	e.chains.forObjective(crankedObjective).WatchChannel(crankedObjective.OwnsChannel())
 
 	notifEvents, err := e.generateNotifications(crankedObjective)
 	if err != nil {
@@ -625,18 +1420,36 @@ func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing Engine
 	}
 	outgoing.Merge(notifEvents)
 
-	e.logger.Info("Objective cranked", logging.WithObjectiveIdAttribute(objective.Id()), "waiting-for", string(waitingFor))
+	e.logger.Info("Objective cranked", logging.WithObjectiveIdAttribute(objective.Id()), logging.WithChannelIdAttribute(objective.OwnsChannel()), logging.WithWaitingForAttribute(waitingFor))
+	outgoing.ObjectiveProgress = append(outgoing.ObjectiveProgress, ObjectiveProgressEvent{ObjectiveId: crankedObjective.Id(), WaitingFor: waitingFor, Timestamp: time.Now()})
+	e.recordSpawnedProgress(crankedObjective.Id(), waitingFor, sideEffects.MessagesToSend)
+	if err := e.recordDeadlineProgress(crankedObjective.Id(), waitingFor); err != nil {
+		return EngineEvent{}, err
+	}
+	if err := e.recordObjectiveActivity(crankedObjective); err != nil {
+		return EngineEvent{}, err
+	}
 
 	// If our protocol is waiting for nothing then we know the objective is complete
 	// TODO: If attemptProgress is called on a completed objective CompletedObjectives would include that objective id
 	// Probably should have a better check that only adds it to CompletedObjectives if it was completed in this crank
-	if waitingFor == "WaitingForNothing" {
+	if waitingFor == protocols.WaitingForNothing {
 		outgoing.CompletedObjectives = append(outgoing.CompletedObjectives, crankedObjective)
+		e.metrics.RecordObjectiveCompleted(crankedObjective.Id())
+		e.tracer.completeObjective(crankedObjective.Id())
 		err = e.store.ReleaseChannelFromOwnership(crankedObjective.OwnsChannel())
 		if err != nil {
 			return
 		}
-		err = e.spawnConsensusChannelIfDirectFundObjective(crankedObjective) // Here we assume that every directfund.Objective is for a ledger channel.
+		err = e.store.RemoveSubmittedTransactions(crankedObjective.OwnsChannel())
+		if err != nil {
+			return
+		}
+		err = e.spawnConsensusChannelIfLedgerObjective(crankedObjective) // Here we assume that every directfund.Objective is for a ledger channel.
+		if err != nil {
+			return
+		}
+		err = e.archiveChannel(crankedObjective)
 		if err != nil {
 			return
 		}
@@ -700,27 +1513,118 @@ func (e Engine) registerPaymentChannel(vfo virtualfund.Objective) error {
 	return e.vm.Register(vfo.V.Id, payments.GetPayer(postfund.Participants), payments.GetPayee(postfund.Participants), startingBalance)
 }
 
-// spawnConsensusChannelIfDirectFundObjective will attempt to create and store a ConsensusChannel derived from the supplied Objective if it is a directfund.Objective.
-// The associated Channel will remain in the store.
-func (e Engine) spawnConsensusChannelIfDirectFundObjective(crankedObjective protocols.Objective) error {
-	if dfo, isDfo := crankedObjective.(*directfund.Objective); isDfo {
-		c, err := dfo.CreateConsensusChannel()
-		if err != nil {
-			return fmt.Errorf("could not create consensus channel for objective %s: %w", crankedObjective.Id(), err)
-		}
-		err = e.store.SetConsensusChannel(c)
+// spawnConsensusChannelIfLedgerObjective will attempt to create and store a ConsensusChannel derived from the supplied Objective
+// if it is a directfund.Objective, a ledgertopup.Objective or a ledgerrecycle.Objective.
+// The associated Channel will be destroyed, since the ConsensusChannel takes over governance.
+func (e Engine) spawnConsensusChannelIfLedgerObjective(crankedObjective protocols.Objective) error {
+	var c *consensus_channel.ConsensusChannel
+	var err error
+	switch o := crankedObjective.(type) {
+	case *directfund.Objective:
+		c, err = o.CreateConsensusChannel()
+	case *ledgertopup.Objective:
+		c, err = o.CreateConsensusChannel()
+	case *ledgerrecycle.Objective:
+		c, err = o.CreateConsensusChannel()
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not create consensus channel for objective %s: %w", crankedObjective.Id(), err)
+	}
+	err = e.store.SetConsensusChannel(c)
+	if err != nil {
+		return fmt.Errorf("could not store consensus channel for objective %s: %w", crankedObjective.Id(), err)
+	}
+	// Destroy the channel since the consensus channel takes over governance:
+	err = e.store.DestroyChannel(c.Id)
+	if err != nil {
+		return fmt.Errorf("could not destroy consensus channel for objective %s: %w", crankedObjective.Id(), err)
+	}
+	return nil
+}
+
+// restoreConsensusChannel stores a ConsensusChannel for the ledger channel of a rejected objective, such as a top up or recycle, at the channel's latest supported state.
+// The associated Channel is destroyed, since the ConsensusChannel takes back governance.
+// An objective rejected before it was approved never took governance, so the ConsensusChannel it found is left as it is.
+func (e Engine) restoreConsensusChannel(rejected protocols.ConsensusChannelRestorer) error {
+	if _, err := e.store.GetConsensusChannelById(rejected.OwnsChannel()); err == nil {
+		return nil
+	}
+	c, err := rejected.RestoreConsensusChannel()
+	if err != nil {
+		return fmt.Errorf("could not restore consensus channel for objective %s: %w", rejected.Id(), err)
+	}
+	err = e.store.SetConsensusChannel(c)
+	if err != nil {
+		return fmt.Errorf("could not store consensus channel for objective %s: %w", rejected.Id(), err)
+	}
+	err = e.store.ReleaseChannelFromOwnership(c.Id)
+	if err != nil {
+		return fmt.Errorf("could not release channel for objective %s: %w", rejected.Id(), err)
+	}
+	err = e.store.DestroyChannel(c.Id)
+	if err != nil {
+		return fmt.Errorf("could not destroy channel for objective %s: %w", rejected.Id(), err)
+	}
+	return nil
+}
+
+// archiveChannel records in the archive that the channel of a completed funding objective has opened,
+// or that the channel of a completed defunding objective has closed.
+func (e Engine) archiveChannel(completed protocols.Objective) error {
+	var c *channel.Channel
+	var ledger bool
+	switch o := completed.(type) {
+	case *directfund.Objective:
+		c, ledger = o.C, true
+	case *virtualfund.Objective:
+		c = &o.V.Channel
+	case *directdefund.Objective:
+		c, ledger = o.C, true
+	case *virtualdefund.Objective:
+		c = &o.V.Channel
+	default:
+		return nil
+	}
+
+	a, ok := e.store.GetArchivedChannel(c.Id)
+	if !ok {
+		a = store.ArchivedChannel{ID: c.Id, Ledger: ledger, Participants: c.Participants}
+	}
+	switch o := completed.(type) {
+	case *directfund.Objective, *virtualfund.Objective:
+		a.OpenedAt = time.Now()
+	case *directdefund.Objective, *virtualdefund.Objective:
+		final, err := c.LatestSupportedState()
 		if err != nil {
-			return fmt.Errorf("could not store consensus channel for objective %s: %w", crankedObjective.Id(), err)
+			return fmt.Errorf("could not archive channel %s: %w", c.Id, err)
 		}
-		// Destroy the channel since the consensus channel takes over governance:
-		err = e.store.DestroyChannel(c.Id)
-		if err != nil {
-			return fmt.Errorf("could not destroy consensus channel for objective %s: %w", crankedObjective.Id(), err)
+		a.ClosedAt = time.Now()
+		a.FinalOutcome = final.Outcome
+		if vdfo, ok := o.(*virtualdefund.Objective); ok {
+			a.TotalPaid, _ = vdfo.V.GetPaidAndRemaining()
 		}
 	}
+	if err := e.store.SetArchivedChannel(a); err != nil {
+		return fmt.Errorf("could not archive channel %s: %w", c.Id, err)
+	}
 	return nil
 }
 
+// admitObjective asks an AdmissionPolicyMaker whether to construct the objective proposed by peer.
+// Objectives which are already stored are always admitted.
+func (e *Engine) admitObjective(id protocols.ObjectiveId, peer types.Address) (bool, string) {
+	policymaker, ok := e.policy().(AdmissionPolicyMaker)
+	if !ok {
+		return true, ""
+	}
+	if _, err := e.store.GetObjectiveById(id); !errors.Is(err, store.ErrNoSuchObjective) {
+		return true, ""
+	}
+	return policymaker.ShouldAdmit(id, peer)
+}
+
 // getOrCreateObjective retrieves the objective from the store.
 // If the objective does not exist, it creates the objective using the supplied payload and stores it in the store
 func (e *Engine) getOrCreateObjective(p protocols.ObjectivePayload) (protocols.Objective, error) {
@@ -735,12 +1639,16 @@ func (e *Engine) getOrCreateObjective(p protocols.ObjectivePayload) (protocols.O
 		if err != nil {
 			return nil, fmt.Errorf("error constructing objective from message: %w", err)
 		}
+		if err := e.chains.assignFundedChannel(newObj, p.ChainId); err != nil {
+			return nil, fmt.Errorf("error constructing objective from message: %w", err)
+		}
 
 		err = e.store.SetObjective(newObj)
 		if err != nil {
 			return nil, fmt.Errorf("error setting objective in store: %w", err)
 		}
-		e.logger.Info("Created new objective from message", "id", id)
+		e.logger.Info("Created new objective from message", logging.WithObjectiveIdAttribute(id))
+		e.metrics.RecordObjectiveStarted(id)
 
 		return newObj, nil
 
@@ -793,6 +1701,32 @@ func (e *Engine) constructObjectiveFromMessage(id protocols.ObjectiveId, p proto
 		}
 		return &ddfo, nil
 
+	case ledgertopup.IsLedgerTopUpObjective(id):
+		lto, err := ledgertopup.ConstructObjectiveFromPayload(p, false, e.store.GetConsensusChannelById)
+		if err != nil {
+			return &ledgertopup.Objective{}, fromMsgErr(id, err)
+		}
+		return &lto, nil
+
+	case ledgerrecycle.IsLedgerRecycleObjective(id):
+		lro, err := ledgerrecycle.ConstructObjectiveFromPayload(p, false, e.store.GetConsensusChannelById)
+		if err != nil {
+			return &ledgerrecycle.Objective{}, fromMsgErr(id, err)
+		}
+		return &lro, nil
+
+	case appupdate.IsAppUpdateObjective(id):
+		channelId, err := appupdate.GetChannelFromObjectiveId(id)
+		if err != nil {
+			return &appupdate.Objective{}, fromMsgErr(id, err)
+		}
+		paymentApp := e.chains.forChannel(channelId).GetVirtualPaymentAppAddress()
+		auo, err := appupdate.ConstructObjectiveFromPayload(p, false, paymentApp, e.store.GetChannelById)
+		if err != nil {
+			return &appupdate.Objective{}, fromMsgErr(id, err)
+		}
+		return &auo, nil
+
 	default:
 		return &directfund.Objective{}, errors.New("cannot handle unimplemented objective type")
 	}
@@ -828,14 +1762,27 @@ func getProposalObjectiveId(p consensus_channel.Proposal) protocols.ObjectiveId
 	}
 }
 
-// GetConsensusAppAddress returns the address of a deployed ConsensusApp (for ledger channels)
+// recordLastBlocksSeen records the last confirmed block of the default chain, and of each other chain
+func (e *Engine) recordLastBlocksSeen() error {
+	if err := e.store.SetLastBlockNumSeen(e.chains.defaultChain.GetLastConfirmedBlockNum()); err != nil {
+		return err
+	}
+	for _, other := range e.chains.others {
+		if err := e.store.SetLastBlockNumSeenOnChain(other.chainId, other.chain.GetLastConfirmedBlockNum()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetConsensusAppAddress returns the address of the ConsensusApp deployed on the default chain (for ledger channels)
 func (e *Engine) GetConsensusAppAddress() types.Address {
-	return e.chain.GetConsensusAppAddress()
+	return e.chains.defaultChain.GetConsensusAppAddress()
 }
 
-// GetVirtualPaymentAppAddress returns the address of a deployed VirtualPaymentApp
+// GetVirtualPaymentAppAddress returns the address of the VirtualPaymentApp deployed on the default chain
 func (e *Engine) GetVirtualPaymentAppAddress() types.Address {
-	return e.chain.GetVirtualPaymentAppAddress()
+	return e.chains.defaultChain.GetVirtualPaymentAppAddress()
 }
 
 type messageDirection string
@@ -856,7 +1803,7 @@ func (e *Engine) logMessage(msg protocols.Message, direction messageDirection) {
 
 func (e *Engine) checkError(err error) {
 	if err != nil {
-		e.logger.Error("error in run loop", "err", err)
+		e.logger.Error("error in run loop", "error", err)
 
 		for _, nonFatalError := range nonFatalErrors {
 			if errors.Is(err, nonFatalError) {
@@ -0,0 +1,818 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// countingChainService counts the transactions it is asked to submit
+type countingChainService struct {
+	*chainservice.MockChainService
+	mu  sync.Mutex
+	txs []protocols.ChainTransaction
+}
+
+func (ccs *countingChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	ccs.mu.Lock()
+	ccs.txs = append(ccs.txs, tx)
+	ccs.mu.Unlock()
+	return ccs.MockChainService.SendTransaction(tx)
+}
+
+// submissions returns how many transactions have been submitted so far
+func (ccs *countingChainService) submissions() int {
+	ccs.mu.Lock()
+	defer ccs.mu.Unlock()
+	return len(ccs.txs)
+}
+
+func TestTransactionsAreSubmittedOnce(t *testing.T) {
+	alice := testactors.Alice
+
+	s := store.NewMemStore(alice.PrivateKey)
+	chain := &countingChainService{MockChainService: chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address())}
+	msg := messageservice.NewTestMessageService(alice.Address(), messageservice.NewBroker(), 0)
+	e := New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &PermissivePolicy{}, func(EngineEvent) {}, nil, nil)
+	defer e.Close()
+
+	dfo := readyToDepositObjective(t, 1)
+
+	// Crank the same objective twice, as would happen after a restart or a duplicate event
+	for i := 0; i < 2; i++ {
+		if _, err := e.attemptProgress(&dfo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e.txWorkers.wait()
+
+	if len(chain.txs) != 1 {
+		t.Fatalf("expected 1 transaction to be submitted, got %d", len(chain.txs))
+	}
+	if _, isDeposit := chain.txs[0].(protocols.DepositTransaction); !isDeposit {
+		t.Fatalf("expected a deposit transaction, got %T", chain.txs[0])
+	}
+}
+
+func TestChainEventsDriveFunding(t *testing.T) {
+	alice := testactors.Alice
+
+	s := store.NewMemStore(alice.PrivateKey)
+	chain := chainservice.NewManualMockChain()
+	broker := messageservice.NewBroker()
+	msg := messageservice.NewTestMessageService(alice.Address(), broker, 0)
+	_ = messageservice.NewTestMessageService(testactors.Bob.Address(), broker, 0) // receives Alice's postfund state
+	progress := make(chan protocols.WaitingFor, 10)
+	handler := func(ee EngineEvent) {
+		for _, p := range ee.ObjectiveProgress {
+			progress <- p.WaitingFor
+		}
+	}
+	e := New(payments.NewVoucherManager(alice.Address(), s), msg, chainservice.NewMockChainService(chain, alice.Address()), s, &PermissivePolicy{}, handler, nil, nil)
+	defer e.Close()
+
+	dfo := readyToDepositObjective(t, 1)
+	if _, err := e.attemptProgress(&dfo); err != nil {
+		t.Fatal(err)
+	}
+	txs, err := chain.WaitForTransactions(1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, isDeposit := txs[0].(protocols.DepositTransaction); !isDeposit {
+		t.Fatalf("expected a deposit transaction, got %T", txs[0])
+	}
+
+	expectProgress := func(want protocols.WaitingFor) {
+		t.Helper()
+		select {
+		case got := <-progress:
+			if got != want {
+				t.Fatalf("expected the objective to be waiting for %s, got %s", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for the objective to wait for %s", want)
+		}
+	}
+
+	asset := common.Address{}
+	// Alice's deposit lands, so it is Bob's turn to fund
+	chain.EmitDeposit(dfo.OwnsChannel(), types.Funds{asset: big.NewInt(5)}, 3)
+	expectProgress(protocols.WaitingForCompleteFunding)
+	// Bob's deposit lands, so the channel is funded
+	chain.EmitDeposit(dfo.OwnsChannel(), types.Funds{asset: big.NewInt(10)}, 4)
+	expectProgress(protocols.WaitingForCompletePostFund)
+
+	if txs := chain.SubmittedTransactions(); len(txs) != 1 {
+		t.Fatalf("expected 1 transaction to be submitted, got %d", len(txs))
+	}
+}
+
+// readyToDepositObjective returns a directfund objective between Alice and Bob in which Alice is ready to deposit
+func readyToDepositObjective(t *testing.T, nonce uint64) directfund.Objective {
+	alice, bob := testactors.Alice, testactors.Bob
+
+	prefund := state.State{
+		Participants:      []types.Address{alice.Address(), bob.Address()},
+		ChannelNonce:      nonce,
+		AppDefinition:     common.Address{},
+		ChallengeDuration: 60,
+		Outcome: outcome.Exit{outcome.SingleAssetExit{Allocations: outcome.Allocations{
+			{Destination: alice.Destination(), Amount: big.NewInt(5)},
+			{Destination: bob.Destination(), Amount: big.NewInt(5)},
+		}}},
+	}
+	id := protocols.ObjectiveId(directfund.ObjectivePrefix + prefund.ChannelId().String())
+	op, err := protocols.CreateObjectivePayload(id, directfund.SignedStatePayload, state.NewSignedState(prefund))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dfo, err := directfund.ConstructFromPayload(true, op, alice.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pk := range [][]byte{alice.PrivateKey, bob.PrivateKey} {
+		sig, _ := dfo.C.PreFundState().Sign(pk)
+		dfo.C.AddStateWithSignature(dfo.C.PreFundState(), sig)
+	}
+	return dfo
+}
+
+// effectLog records the order in which side effects are executed
+type effectLog struct {
+	mu      sync.Mutex
+	effects []string
+}
+
+func (l *effectLog) record(effect string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.effects = append(l.effects, effect)
+}
+
+func (l *effectLog) recorded() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.effects...)
+}
+
+// slowChainService takes a while to submit each transaction, and records when it has
+type slowChainService struct {
+	*chainservice.MockChainService
+	log *effectLog
+}
+
+func (scs slowChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	time.Sleep(50 * time.Millisecond)
+	scs.log.record("transaction")
+	return scs.MockChainService.SendTransaction(tx)
+}
+
+// recordingMessageService records when it sends a message
+type recordingMessageService struct {
+	messageservice.TestMessageService
+	log *effectLog
+}
+
+func (rms recordingMessageService) Send(msg protocols.Message) error {
+	rms.log.record("message")
+	return rms.TestMessageService.Send(msg)
+}
+
+func TestSequencedSideEffectsAreExecutedInOrder(t *testing.T) {
+	alice, bob := testactors.Alice, testactors.Bob
+	s := store.NewMemStore(alice.PrivateKey)
+	log := &effectLog{}
+	chain := slowChainService{MockChainService: chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address()), log: log}
+	broker := messageservice.NewBroker()
+	_ = messageservice.NewTestMessageService(bob.Address(), broker, 0)
+	msg := recordingMessageService{TestMessageService: messageservice.NewTestMessageService(alice.Address(), broker, 0), log: log}
+	e := New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &PermissivePolicy{}, func(EngineEvent) {}, nil, nil)
+	defer e.Close()
+
+	dfo := readyToDepositObjective(t, 1)
+	deposit := protocols.NewDepositTransaction(dfo.OwnsChannel(), types.Funds{common.Address{}: big.NewInt(5)})
+	announcement := protocols.Message{To: bob.Address()}
+	err := e.executeSideEffects(protocols.SideEffects{Sequence: []protocols.SideEffect{
+		protocols.SubmitTransaction(deposit),
+		protocols.SendMessage(announcement),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.txWorkers.wait()
+
+	if got, want := log.recorded(), []string{"transaction", "message"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the deposit to be submitted before the message is sent, got %v", got)
+	}
+}
+
+func TestTransactionsDroppedFromASequenceAreNotMarkedSubmitted(t *testing.T) {
+	alice := testactors.Alice
+	s := store.NewMemStore(alice.PrivateKey)
+	chain := &revertingChainService{countingChainService: countingChainService{MockChainService: chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address())}}
+	msg := messageservice.NewTestMessageService(alice.Address(), messageservice.NewBroker(), 0)
+	e := New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &PermissivePolicy{}, func(EngineEvent) {}, nil, nil)
+	defer e.Close()
+
+	dfo := readyToDepositObjective(t, 1)
+	first := protocols.NewDepositTransaction(dfo.OwnsChannel(), types.Funds{common.Address{}: big.NewInt(5)})
+	second := protocols.NewDepositTransaction(dfo.OwnsChannel(), types.Funds{common.Address{1}: big.NewInt(5)})
+	err := e.executeSideEffects(protocols.SideEffects{Sequence: []protocols.SideEffect{
+		protocols.SubmitTransaction(first),
+		protocols.SubmitTransaction(second),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.txWorkers.wait()
+
+	chain.mu.Lock()
+	submissions := len(chain.txs)
+	chain.mu.Unlock()
+	if submissions != 1 {
+		t.Fatalf("expected the sequence to stop at the reverted transaction, got %d submissions", submissions)
+	}
+	submitted, err := s.IsTransactionSubmitted(dfo.OwnsChannel(), transactionKey(second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if submitted {
+		t.Errorf("expected the dropped transaction not to be recorded as submitted")
+	}
+}
+
+func TestCloseWaitsForTransactionsBeingSubmitted(t *testing.T) {
+	alice := testactors.Alice
+	s := store.NewMemStore(alice.PrivateKey)
+	log := &effectLog{}
+	chain := slowChainService{MockChainService: chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address()), log: log}
+	msg := messageservice.NewTestMessageService(alice.Address(), messageservice.NewBroker(), 0)
+	e := New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &PermissivePolicy{}, func(EngineEvent) {}, nil, nil)
+
+	dfo := readyToDepositObjective(t, 1)
+	deposit := protocols.NewDepositTransaction(dfo.OwnsChannel(), types.Funds{common.Address{}: big.NewInt(5)})
+	if err := e.executeSideEffects(protocols.SideEffects{TransactionsToSubmit: []protocols.ChainTransaction{deposit}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := log.recorded(), []string{"transaction"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the deposit to be submitted before the engine closed, got %v", got)
+	}
+}
+
+// revertingChainService fails to submit the first transaction it is asked to submit
+type revertingChainService struct {
+	countingChainService
+	reverted bool
+}
+
+func (rcs *revertingChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	rcs.mu.Lock()
+	rcs.txs = append(rcs.txs, tx)
+	revert := !rcs.reverted
+	rcs.reverted = true
+	rcs.mu.Unlock()
+	if revert {
+		return errors.New("execution reverted")
+	}
+	return rcs.MockChainService.SendTransaction(tx)
+}
+
+func TestFailedTransactionIsRetried(t *testing.T) {
+	transactionRetryDelay = 10 * time.Millisecond
+	defer func() { transactionRetryDelay = 5 * time.Second }()
+
+	alice := testactors.Alice
+	s := store.NewMemStore(alice.PrivateKey)
+	chain := &revertingChainService{countingChainService: countingChainService{MockChainService: chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address())}}
+	msg := messageservice.NewTestMessageService(alice.Address(), messageservice.NewBroker(), 0)
+	e := New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &PermissivePolicy{}, func(EngineEvent) {}, nil, nil)
+	defer e.Close()
+
+	dfo := readyToDepositObjective(t, 1)
+	if _, err := e.attemptProgress(&dfo); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		chain.mu.Lock()
+		submissions := len(chain.txs)
+		chain.mu.Unlock()
+		if submissions >= 2 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("expected the reverted deposit to be retried, got %d submissions", submissions)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	// The retried transaction is recorded as submitted once the chain service has accepted it
+	for {
+		submitted, err := s.IsTransactionSubmitted(dfo.OwnsChannel(), transactionKey(chain.txs[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if submitted {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("expected the retried transaction to be recorded as submitted")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestDepositsAreKeyedByTurnAndAssets(t *testing.T) {
+	channelId := types.Destination{1}
+	deposit := func(turnNum uint64, funds types.Funds) string {
+		tx := protocols.NewDepositTransaction(channelId, funds)
+		tx.TurnNum = turnNum
+		return transactionKey(tx)
+	}
+	eth, token := common.Address{}, common.Address{1}
+
+	// A top up of the same amount as the initial deposit is a different transaction
+	if deposit(0, types.Funds{eth: big.NewInt(5)}) == deposit(2, types.Funds{eth: big.NewInt(5)}) {
+		t.Errorf("expected deposits for different turns to have different keys")
+	}
+	// A deposit declared again with a different amount, for example once the holdings have changed, is the same transaction
+	if deposit(0, types.Funds{eth: big.NewInt(5)}) != deposit(0, types.Funds{eth: big.NewInt(3)}) {
+		t.Errorf("expected deposits for the same turn and asset to have the same key")
+	}
+	if deposit(0, types.Funds{eth: big.NewInt(5)}) == deposit(0, types.Funds{token: big.NewInt(5)}) {
+		t.Errorf("expected deposits of different assets to have different keys")
+	}
+	multi := types.Funds{eth: big.NewInt(5), token: big.NewInt(5)}
+	for i := 0; i < 10; i++ {
+		if deposit(0, multi) != deposit(0, multi.Clone()) {
+			t.Fatalf("expected the key of a deposit of several assets not to depend on map order")
+		}
+	}
+}
+
+// disconnectableChainService is a chain service whose connection to the chain can be dropped and restored
+type disconnectableChainService struct {
+	countingChainService
+	disconnected atomic.Bool
+}
+
+func (dcs *disconnectableChainService) Connected() bool {
+	return !dcs.disconnected.Load()
+}
+
+func TestObjectivesArePausedWhileChainIsDisconnected(t *testing.T) {
+	alice := testactors.Alice
+	s := store.NewMemStore(alice.PrivateKey)
+	chain := &disconnectableChainService{countingChainService: countingChainService{MockChainService: chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address())}}
+	chain.disconnected.Store(true)
+	msg := messageservice.NewTestMessageService(alice.Address(), messageservice.NewBroker(), 0)
+	e := New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &PermissivePolicy{}, func(EngineEvent) {}, nil, nil)
+	defer e.Close()
+
+	dfo := readyToDepositObjective(t, 1)
+	if _, err := e.attemptProgress(&dfo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetObjectiveById(dfo.Id()); err != nil {
+		t.Errorf("expected the paused objective to be stored: %v", err)
+	}
+
+	time.Sleep(2 * chainConnectionCheckInterval)
+	if n := chain.submissions(); n != 0 {
+		t.Fatalf("expected no deposit while the chain is disconnected, got %d submissions", n)
+	}
+
+	chain.disconnected.Store(false)
+	deadline := time.After(5 * time.Second)
+	for chain.submissions() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("expected the deposit to be submitted once the chain reconnected")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// idChainService is a chain service for a chain with its own id
+type idChainService struct {
+	countingChainService
+	chainId *big.Int
+}
+
+func (ics *idChainService) GetChainId() (*big.Int, error) { return ics.chainId, nil }
+
+func TestTransactionsAreRoutedToTheChannelsChain(t *testing.T) {
+	alice := testactors.Alice
+	s := store.NewMemStore(alice.PrivateKey)
+	// Both chains have the same app deployments, so the channel can only be routed by the chain it was opened on
+	newChain := func(chainId int64) *idChainService {
+		return &idChainService{
+			countingChainService: countingChainService{MockChainService: chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address())},
+			chainId:              big.NewInt(chainId),
+		}
+	}
+	newMultiChainEngine := func() (e Engine, chainA, chainB *idChainService) {
+		chainA, chainB = newChain(1), newChain(2)
+		msg := messageservice.NewTestMessageService(alice.Address(), messageservice.NewBroker(), 0)
+		// Chain B is the default, so the deposit only reaches chain A if it is routed by the channel's chain
+		e, err := NewMultiChain(payments.NewVoucherManager(alice.Address(), s), msg, []chainservice.ChainService{chainB, chainA}, s, &PermissivePolicy{}, func(EngineEvent) {}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e, chainA, chainB
+	}
+
+	e, chainA, chainB := newMultiChainEngine()
+	dfo := readyToDepositObjective(t, 1)
+	if err := e.chains.assign(dfo.OwnsChannel(), "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.chains.assign(dfo.OwnsChannel(), "3"); !errors.Is(err, ErrUnknownChain) {
+		t.Errorf("expected %v, got %v", ErrUnknownChain, err)
+	}
+	if _, err := e.attemptProgress(&dfo); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for chainA.submissions() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("expected the deposit to be submitted to chain A")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if n := chainB.submissions(); n != 0 {
+		t.Errorf("expected no transactions on chain B, got %d", n)
+	}
+
+	// The deposit's event is recorded against chain A's block cursor, not the default chain's
+	for {
+		last, err := s.GetLastBlockNumSeenOnChain("1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last > 0 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("expected chain A's last block seen to advance")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if last, err := s.GetLastBlockNumSeen(); err != nil || last != 0 {
+		t.Errorf("expected the default chain's last block seen to be 0, got %d (%v)", last, err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The channel's chain outlives the engine
+	e, chainA, _ = newMultiChainEngine()
+	defer e.Close()
+	if chainId := e.chains.chainOf(dfo.OwnsChannel()); chainId != "1" {
+		t.Errorf("expected the channel to stay on chain 1 after a restart, got %q", chainId)
+	}
+
+	msg := messageservice.NewTestMessageService(alice.Address(), messageservice.NewBroker(), 0)
+	if _, err := NewMultiChain(payments.NewVoucherManager(alice.Address(), s), msg, []chainservice.ChainService{chainA, chainA}, s, &PermissivePolicy{}, func(EngineEvent) {}, nil, nil); err == nil {
+		t.Error("expected an error when two chain services are connected to the same chain")
+	}
+}
+
+func TestCancelObjective(t *testing.T) {
+	alice, bob := testactors.Alice, testactors.Bob
+
+	setup := func() (*Engine, store.Store, *countingChainService, messageservice.TestMessageService, chan EngineEvent) {
+		s := store.NewMemStore(alice.PrivateKey)
+		chain := &countingChainService{MockChainService: chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address())}
+		broker := messageservice.NewBroker()
+		msg := messageservice.NewTestMessageService(alice.Address(), broker, 0)
+		bobMsg := messageservice.NewTestMessageService(bob.Address(), broker, 0)
+		events := make(chan EngineEvent, 10)
+		e := New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &PermissivePolicy{}, func(ee EngineEvent) { events <- ee }, nil, nil)
+		return &e, s, chain, bobMsg, events
+	}
+
+	t.Run("before any deposit", func(t *testing.T) {
+		e, s, chain, bobMsg, events := setup()
+		defer e.Close()
+
+		dfo := readyToDepositObjective(t, 1)
+		if err := s.SetObjective(&dfo); err != nil {
+			t.Fatal(err)
+		}
+
+		request := NewCancelRequest(dfo.Id())
+		e.CancelRequestsFromAPI <- request
+		if err := request.WaitForCancellation(); err != nil {
+			t.Fatalf("expected the objective to be cancelled, got %v", err)
+		}
+
+		select {
+		case ee := <-events:
+			if len(ee.CompletedObjectives) != 1 || ee.CompletedObjectives[0].Id() != dfo.Id() {
+				t.Errorf("expected the cancelled objective to be completed, got %+v", ee.CompletedObjectives)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected the cancelled objective to be completed")
+		}
+		select {
+		case m := <-bobMsg.P2PMessages():
+			if len(m.RejectedObjectives) != 1 || m.RejectedObjectives[0] != dfo.Id() {
+				t.Errorf("expected the counterparty to be told the objective was abandoned, got %+v", m)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected the counterparty to be told the objective was abandoned")
+		}
+
+		stored, err := s.GetObjectiveById(dfo.Id())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.GetStatus() != protocols.Rejected {
+			t.Errorf("expected the cancelled objective to be rejected, got status %d", stored.GetStatus())
+		}
+		if _, owned := s.GetObjectiveByChannelId(dfo.OwnsChannel()); owned {
+			t.Error("expected the channel to be released by the cancelled objective")
+		}
+		if n := chain.submissions(); n != 0 {
+			t.Errorf("expected no deposit for a cancelled objective, got %d submissions", n)
+		}
+
+		// A cancelled objective is no longer in progress
+		request = NewCancelRequest(dfo.Id())
+		e.CancelRequestsFromAPI <- request
+		if err := request.WaitForCancellation(); !errors.Is(err, protocols.ErrNotCancellable) {
+			t.Errorf("expected %v when cancelling twice, got %v", protocols.ErrNotCancellable, err)
+		}
+	})
+
+	t.Run("after a deposit is submitted", func(t *testing.T) {
+		e, s, _, _, _ := setup()
+		defer e.Close()
+
+		dfo := readyToDepositObjective(t, 1)
+		if _, err := e.attemptProgress(&dfo); err != nil {
+			t.Fatal(err)
+		}
+
+		request := NewCancelRequest(dfo.Id())
+		e.CancelRequestsFromAPI <- request
+		if err := request.WaitForCancellation(); !errors.Is(err, protocols.ErrNotCancellable) {
+			t.Fatalf("expected %v once a deposit is submitted, got %v", protocols.ErrNotCancellable, err)
+		}
+
+		stored, err := s.GetObjectiveById(dfo.Id())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.GetStatus() != protocols.Approved {
+			t.Errorf("expected the objective to remain in progress, got status %d", stored.GetStatus())
+		}
+	})
+}
+
+// queuedMessageService delivers the messages queued on it to the engine
+type queuedMessageService struct {
+	messageservice.TestMessageService
+	messages chan protocols.Message
+}
+
+func (qms queuedMessageService) P2PMessages() <-chan protocols.Message {
+	return qms.messages
+}
+
+func TestAPIRequestsAreHandledAheadOfMessages(t *testing.T) {
+	alice, bob := testactors.Alice, testactors.Bob
+	s := store.NewMemStore(alice.PrivateKey)
+	chain := chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address())
+	broker := messageservice.NewBroker()
+	_ = messageservice.NewTestMessageService(bob.Address(), broker, 0)
+	msg := queuedMessageService{TestMessageService: messageservice.NewTestMessageService(alice.Address(), broker, 0), messages: make(chan protocols.Message, 100)}
+
+	first, second := readyToDepositObjective(t, 1), readyToDepositObjective(t, 2)
+	for _, dfo := range []directfund.Objective{first, second} {
+		if err := s.SetObjective(&dfo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The engine is held up handling the first request while messages and the second request queue up
+	release := make(chan struct{})
+	queuedMessages := make(chan int, 1)
+	e := New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &PermissivePolicy{}, func(ee EngineEvent) {
+		switch ee.CompletedObjectives[0].Id() {
+		case first.Id():
+			<-release
+		case second.Id():
+			queuedMessages <- len(msg.messages)
+		}
+	}, nil, nil)
+	defer e.Close()
+
+	e.CancelRequestsFromAPI <- NewCancelRequest(first.Id())
+	for i := 0; i < cap(msg.messages); i++ {
+		msg.messages <- protocols.Message{To: alice.Address(), From: bob.Address()}
+	}
+	go func() { e.CancelRequestsFromAPI <- NewCancelRequest(second.Id()) }()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case n := <-queuedMessages:
+		if n != cap(msg.messages) {
+			t.Errorf("expected the API request to be handled before any queued message, but %d messages were handled first", cap(msg.messages)-n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the API request to be handled")
+	}
+}
+
+// signedPrefundPayload returns a directfund payload for the prefund state of a channel between Alice and Bob,
+// with each signature recorded for the participant it is keyed by, whoever signed it
+func signedPrefundPayload(t *testing.T, nonce uint64, signers map[uint][]byte) protocols.ObjectivePayload {
+	prefund := readyToDepositObjective(t, nonce).C.PreFundState()
+	sigs := map[uint]state.Signature{}
+	for i, pk := range signers {
+		sig, err := prefund.Sign(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs[i] = sig
+	}
+	data, err := json.Marshal(struct {
+		State state.State
+		Sigs  map[uint]state.Signature
+	}{prefund, sigs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := protocols.ObjectiveId(directfund.ObjectivePrefix + prefund.ChannelId().String())
+	return protocols.ObjectivePayload{PayloadData: data, ObjectiveId: id, Type: directfund.SignedStatePayload}
+}
+
+func TestMessagesWithInvalidSignaturesAreRejected(t *testing.T) {
+	alice, bob, irene := testactors.Alice, testactors.Bob, testactors.Irene
+	s := store.NewMemStore(alice.PrivateKey)
+	chain := chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address())
+	broker := messageservice.NewBroker()
+	_ = messageservice.NewTestMessageService(bob.Address(), broker, 0)
+	msg := queuedMessageService{TestMessageService: messageservice.NewTestMessageService(alice.Address(), broker, 0), messages: make(chan protocols.Message, 10)}
+	e := New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &PermissivePolicy{}, func(EngineEvent) {}, nil, nil)
+	defer e.Close()
+
+	wrongKey := signedPrefundPayload(t, 1, map[uint][]byte{1: irene.PrivateKey})
+	missingSignature := signedPrefundPayload(t, 2, map[uint][]byte{})
+	valid := signedPrefundPayload(t, 3, map[uint][]byte{1: bob.PrivateKey})
+	for _, p := range []protocols.ObjectivePayload{wrongKey, missingSignature, valid} {
+		msg.messages <- protocols.Message{To: alice.Address(), From: bob.Address(), ObjectivePayloads: []protocols.ObjectivePayload{p}}
+	}
+
+	// Messages are handled in order, so once the valid message has been handled so have the others
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := s.GetObjectiveById(valid.ObjectiveId); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected an objective to be created from the validly signed message")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for name, p := range map[string]protocols.ObjectivePayload{"signed by the wrong key": wrongKey, "missing a signature": missingSignature} {
+		if _, err := s.GetObjectiveById(p.ObjectiveId); err == nil {
+			t.Errorf("expected no objective to be created from a message %s", name)
+		}
+	}
+}
+
+// turnAwayPolicy turns away a single objective before it is constructed
+type turnAwayPolicy struct {
+	PermissivePolicy
+	id protocols.ObjectiveId
+}
+
+func (tp *turnAwayPolicy) ShouldAdmit(id protocols.ObjectiveId, peer types.Address) (bool, string) {
+	return id != tp.id, "turned away"
+}
+
+func TestPayloadsAfterATurnedAwayPayloadAreHandled(t *testing.T) {
+	alice, bob := testactors.Alice, testactors.Bob
+	s := store.NewMemStore(alice.PrivateKey)
+	chain := chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address())
+	broker := messageservice.NewBroker()
+	_ = messageservice.NewTestMessageService(bob.Address(), broker, 0)
+	msg := queuedMessageService{TestMessageService: messageservice.NewTestMessageService(alice.Address(), broker, 0), messages: make(chan protocols.Message, 1)}
+	turnedAway := signedPrefundPayload(t, 1, map[uint][]byte{1: bob.PrivateKey})
+	admitted := signedPrefundPayload(t, 2, map[uint][]byte{1: bob.PrivateKey})
+	e := New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &turnAwayPolicy{id: turnedAway.ObjectiveId}, func(EngineEvent) {}, nil, nil)
+	defer e.Close()
+
+	msg.messages <- protocols.Message{To: alice.Address(), From: bob.Address(), ObjectivePayloads: []protocols.ObjectivePayload{turnedAway, admitted}}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := s.GetObjectiveById(admitted.ObjectiveId); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected an objective to be created from the payload after the turned away payload")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := s.GetObjectiveById(turnedAway.ObjectiveId); err == nil {
+		t.Error("expected no objective to be created from the turned away payload")
+	}
+}
+
+func TestObjectiveDeadlinesAreKeptWhileWaitingOnCounterparties(t *testing.T) {
+	alice := testactors.Alice
+	s := store.NewMemStore(alice.PrivateKey)
+	newEngine := func() Engine {
+		chain := chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address())
+		msg := messageservice.NewTestMessageService(alice.Address(), messageservice.NewBroker(), 0)
+		return New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &DeadlinePolicy{Deadline: time.Hour}, func(EngineEvent) {}, nil, nil)
+	}
+	e := newEngine()
+
+	tracked := func(e Engine, id protocols.ObjectiveId) bool {
+		t.Helper()
+		stored, err := s.GetObjectiveDeadlines()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, inStore := stored[id]
+		_, inEngine := e.objectiveDeadlines[id]
+		if inStore != inEngine {
+			t.Fatalf("expected the engine's deadline for %s to be written through to the store", id)
+		}
+		return inEngine
+	}
+
+	dfo := readyToDepositObjective(t, 1)
+	if err := e.recordDeadlineProgress(dfo.Id(), directfund.WaitingForCompletePrefund); err != nil {
+		t.Fatal(err)
+	}
+	if !tracked(e, dfo.Id()) {
+		t.Fatal("expected an objective waiting on its counterparty to have a deadline")
+	}
+
+	// Funding waits on the chain, which a challenge would not hurry
+	if err := e.recordDeadlineProgress(dfo.Id(), directfund.WaitingForMyTurnToFund); err != nil {
+		t.Fatal(err)
+	}
+	if tracked(e, dfo.Id()) {
+		t.Fatal("expected an objective waiting on the chain to have no deadline")
+	}
+
+	// A stalled virtual channel is no reason to challenge the ledgers funding it
+	vfoId := protocols.ObjectiveId(virtualfund.ObjectivePrefix + dfo.OwnsChannel().String())
+	if err := e.recordDeadlineProgress(vfoId, virtualfund.WaitingForCompletePrefund); err != nil {
+		t.Fatal(err)
+	}
+	if tracked(e, vfoId) {
+		t.Fatal("expected a virtualfund objective to have no deadline")
+	}
+
+	// Deadlines survive a restart
+	if err := e.recordDeadlineProgress(dfo.Id(), directfund.WaitingForCompletePostFund); err != nil {
+		t.Fatal(err)
+	}
+	want := e.objectiveDeadlines[dfo.Id()]
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	restarted := newEngine()
+	defer restarted.Close()
+	if got := restarted.objectiveDeadlines[dfo.Id()]; !got.Deadline.Equal(want.Deadline) || got.WaitingFor != want.WaitingFor {
+		t.Errorf("expected the restarted engine to restore the deadline %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// idleChannelTimeout returns how long payment channels may stay idle before the engine closes them, or zero if they are never closed automatically
+func (e *Engine) idleChannelTimeout() time.Duration {
+	if policy, ok := e.policymaker.(IdleChannelPolicy); ok {
+		return policy.IdleChannelTimeout()
+	}
+	return 0
+}
+
+// idleChannelCheckInterval returns how often payment channels are checked for inactivity, given the idle channel timeout
+func idleChannelCheckInterval(timeout time.Duration) time.Duration {
+	return max(timeout/2, time.Millisecond)
+}
+
+// loadChannelActivity restores when payment channels were last active from the store, so that channels which fell idle before a restart are still closed
+func (e *Engine) loadChannelActivity() {
+	activity, err := e.store.GetChannelActivity()
+	if err != nil {
+		e.logger.Error("Could not load payment channel activity", "error", err)
+		activity = map[types.Destination]time.Time{}
+	}
+	e.channelActivity = activity
+}
+
+// recordChannelActivity notes that the payment channel was cranked by an objective or had a voucher sent or received, postponing its automatic closure.
+// The activity is written through to the store.
+func (e *Engine) recordChannelActivity(channelId types.Destination) error {
+	if e.idleChannelTimeout() <= 0 {
+		return nil
+	}
+	now := time.Now()
+	if err := e.store.SetChannelActivity(channelId, now); err != nil {
+		return fmt.Errorf("could not record activity of payment channel %s: %w", channelId, err)
+	}
+	e.channelActivity[channelId] = now
+	return nil
+}
+
+// recordObjectiveActivity records activity on each payment channel that the objective touches
+func (e *Engine) recordObjectiveActivity(o protocols.Objective) error {
+	for _, related := range o.Related() {
+		if v, ok := related.(*channel.VirtualChannel); ok {
+			if err := e.recordChannelActivity(v.Id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// forgetChannelActivity stops tracking the payment channel's activity
+func (e *Engine) forgetChannelActivity(channelId types.Destination) {
+	delete(e.channelActivity, channelId)
+	if err := e.store.RemoveChannelActivity(channelId); err != nil {
+		e.logger.Error("Could not forget payment channel activity", logging.WithChannelIdAttribute(channelId), "error", err)
+	}
+}
+
+// closeIdleChannels starts a virtualdefund objective for each payment channel which has had no activity for longer than the idle channel timeout.
+// Channels which are owned by an objective, for example because a participant has already started to close them, are left alone.
+func (e *Engine) closeIdleChannels() EngineEvent {
+	allCompleted := EngineEvent{}
+	timeout := e.idleChannelTimeout()
+	for channelId, lastActive := range e.channelActivity {
+		c, ok := e.store.GetChannelById(channelId)
+		if !ok || c.FinalCompleted() {
+			e.forgetChannelActivity(channelId)
+			continue
+		}
+		if time.Since(lastActive) < timeout {
+			continue
+		}
+		if _, pending := e.store.GetObjectiveByChannelId(channelId); pending {
+			continue
+		}
+
+		e.forgetChannelActivity(channelId)
+		e.logger.Info("Closing idle payment channel", logging.WithChannelIdAttribute(channelId), "idle-for", time.Since(lastActive))
+		progressEvent, err := e.handleObjectiveRequest(virtualdefund.NewObjectiveRequest(channelId))
+		allCompleted.Merge(progressEvent)
+		if err != nil {
+			// The channel can still be closed by hand, so a failed attempt does not stop the engine
+			e.logger.Error("Could not close idle payment channel", logging.WithChannelIdAttribute(channelId), "error", err)
+		}
+	}
+	return allCompleted
+}
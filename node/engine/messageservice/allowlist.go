@@ -0,0 +1,161 @@
+package messageservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// DroppedMessagesCounter is the name of the counter incremented whenever a message from an unlisted sender is dropped
+const DroppedMessagesCounter = "messages_dropped_from_unlisted_senders"
+
+// Counter counts events by name. An engine.MetricsApi is a Counter.
+type Counter interface {
+	IncrementCounter(name string)
+}
+
+// AllowlistMessageService wraps a MessageService, and drops messages from senders which are not on its allowlist before they reach the engine.
+// The allowlist may be updated while the service is running, for example as new channels are authorized.
+//
+// Since the From field of a message is set by its sender, a message is only delivered if the signers recovered from its vouchers and signed states are allowed too.
+// Messages which carry no such signatures, such as those with only ledger proposals, are judged by their From field, and their signatures are checked by the engine.
+type AllowlistMessageService struct {
+	MessageService
+
+	out       chan protocols.Message
+	quit      chan struct{}
+	closeOnce sync.Once
+	counter   Counter
+
+	mu      sync.RWMutex
+	allowed map[types.Address]bool
+}
+
+// NewAllowlistMessageService returns a running AllowlistMessageService which accepts messages from the allowed addresses.
+// If counter is not nil, it is incremented under DroppedMessagesCounter for every message dropped.
+func NewAllowlistMessageService(inner MessageService, counter Counter, allowed ...types.Address) *AllowlistMessageService {
+	ams := &AllowlistMessageService{
+		MessageService: inner,
+		out:            make(chan protocols.Message, 5),
+		quit:           make(chan struct{}),
+		counter:        counter,
+		allowed:        make(map[types.Address]bool, len(allowed)),
+	}
+	for _, a := range allowed {
+		ams.allowed[a] = true
+	}
+
+	go ams.filterMessages()
+	return ams
+}
+
+// Allow adds the addresses to the allowlist
+func (ams *AllowlistMessageService) Allow(addresses ...types.Address) {
+	ams.mu.Lock()
+	defer ams.mu.Unlock()
+	for _, a := range addresses {
+		ams.allowed[a] = true
+	}
+}
+
+// Disallow removes the addresses from the allowlist
+func (ams *AllowlistMessageService) Disallow(addresses ...types.Address) {
+	ams.mu.Lock()
+	defer ams.mu.Unlock()
+	for _, a := range addresses {
+		delete(ams.allowed, a)
+	}
+}
+
+// IsAllowed returns true if messages from the address are delivered
+func (ams *AllowlistMessageService) IsAllowed(address types.Address) bool {
+	ams.mu.RLock()
+	defer ams.mu.RUnlock()
+	return ams.allowed[address]
+}
+
+// filterMessages forwards messages from allowed senders to the engine until the service, or the wrapped service's chan, is closed
+func (ams *AllowlistMessageService) filterMessages() {
+	for {
+		select {
+		case <-ams.quit:
+			return
+		case msg, ok := <-ams.MessageService.P2PMessages():
+			if !ok {
+				return
+			}
+			if err := ams.checkSigners(msg); err != nil {
+				slog.Warn("dropped a message from an unlisted sender", "from", msg.From, "error", err)
+				if ams.counter != nil {
+					ams.counter.IncrementCounter(DroppedMessagesCounter)
+				}
+				continue
+			}
+			select {
+			case ams.out <- msg:
+			case <-ams.quit:
+				return
+			}
+		}
+	}
+}
+
+// checkSigners returns an error unless the message's From field is allowed, each of its vouchers is signed by an allowed address,
+// and each of its signed states carries the signature of an allowed address.
+func (ams *AllowlistMessageService) checkSigners(msg protocols.Message) error {
+	if !ams.IsAllowed(msg.From) {
+		return fmt.Errorf("sender %s is not allowed", msg.From)
+	}
+	for _, v := range msg.Payments {
+		signer, err := v.RecoverSigner()
+		if err != nil {
+			return fmt.Errorf("could not recover the signer of a voucher: %w", err)
+		}
+		if !ams.IsAllowed(signer) {
+			return fmt.Errorf("voucher signer %s is not allowed", signer)
+		}
+	}
+	for _, p := range msg.ObjectivePayloads {
+		if p.Type != protocols.SignedStatePayload {
+			continue
+		}
+		var ss state.SignedState
+		if err := json.Unmarshal(p.PayloadData, &ss); err != nil {
+			return fmt.Errorf("could not decode the signed state of objective %s: %w", p.ObjectiveId, err)
+		}
+		if !ams.signedByAllowed(ss) {
+			return fmt.Errorf("the signed state of objective %s is not signed by an allowed address", p.ObjectiveId)
+		}
+	}
+	return nil
+}
+
+// signedByAllowed returns true if one of the signatures on the state recovers to an allowed address
+func (ams *AllowlistMessageService) signedByAllowed(ss state.SignedState) bool {
+	for _, sig := range ss.Signatures() {
+		if sig.Equal(state.Signature{}) {
+			continue
+		}
+		signer, err := ss.State().RecoverSigner(sig)
+		if err == nil && ams.IsAllowed(signer) {
+			return true
+		}
+	}
+	return false
+}
+
+// P2PMessages returns a chan for receiving messages from allowed senders
+func (ams *AllowlistMessageService) P2PMessages() <-chan protocols.Message {
+	return ams.out
+}
+
+// Close stops filtering messages and closes the wrapped message service
+func (ams *AllowlistMessageService) Close() error {
+	ams.closeOnce.Do(func() { close(ams.quit) })
+	return ams.MessageService.Close()
+}
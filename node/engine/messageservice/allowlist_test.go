@@ -0,0 +1,117 @@
+package messageservice
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+type countingMetrics struct {
+	count atomic.Int32
+}
+
+func (c *countingMetrics) IncrementCounter(name string) {
+	if name == DroppedMessagesCounter {
+		c.count.Add(1)
+	}
+}
+
+func TestAllowlistDropsUnlistedSenders(t *testing.T) {
+	b := NewBroker()
+	alice, bob, carol := types.Address{'a'}, types.Address{'b'}, types.Address{'c'}
+	aliceMS := NewTestMessageService(alice, b, 0)
+	carolMS := NewTestMessageService(carol, b, 0)
+	metrics := &countingMetrics{}
+	bobMS := NewAllowlistMessageService(NewTestMessageService(bob, b, 0), metrics, alice)
+	defer bobMS.Close()
+
+	messageFrom := func(from types.Address, turnNum uint64) protocols.Message {
+		msg := protocols.CreateSignedProposalMessage(bob, consensus_channel.SignedProposal{
+			Proposal: consensus_channel.Proposal{LedgerID: types.Destination{1}},
+			TurnNum:  turnNum,
+		})
+		msg.From = from
+		return msg
+	}
+	expectMessage := func(turnNum uint64) {
+		t.Helper()
+		select {
+		case got := <-bobMS.P2PMessages():
+			if got.LedgerProposals[0].TurnNum != turnNum {
+				t.Fatalf("expected the message with turn number %d, got %d", turnNum, got.LedgerProposals[0].TurnNum)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected the message with turn number %d to be delivered", turnNum)
+		}
+	}
+
+	// Carol's message is dropped, so Alice's is the first to be delivered
+	if err := carolMS.Send(messageFrom(carol, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := aliceMS.Send(messageFrom(alice, 2)); err != nil {
+		t.Fatal(err)
+	}
+	expectMessage(2)
+	if got := metrics.count.Load(); got != 1 {
+		t.Errorf("expected 1 dropped message to be counted, got %d", got)
+	}
+
+	bobMS.Allow(carol)
+	if err := carolMS.Send(messageFrom(carol, 3)); err != nil {
+		t.Fatal(err)
+	}
+	expectMessage(3)
+
+	bobMS.Disallow(alice)
+	if err := aliceMS.Send(messageFrom(alice, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if err := carolMS.Send(messageFrom(carol, 5)); err != nil {
+		t.Fatal(err)
+	}
+	expectMessage(5)
+}
+
+func TestAllowlistDropsMessagesSignedByUnlistedSenders(t *testing.T) {
+	b := NewBroker()
+	alice, bob, ivan := testactors.Alice, testactors.Bob, testactors.Ivan
+	ivanMS := NewTestMessageService(ivan.Address(), b, 0)
+	metrics := &countingMetrics{}
+	bobMS := NewAllowlistMessageService(NewTestMessageService(bob.Address(), b, 0), metrics, alice.Address())
+	defer bobMS.Close()
+
+	paymentFrom := func(signer testactors.Actor, amount int64) protocols.Message {
+		v := payments.Voucher{ChannelId: types.Destination{1}, Amount: big.NewInt(amount)}
+		if err := v.Sign(signer.PrivateKey); err != nil {
+			t.Fatal(err)
+		}
+		// Ivan claims to be Alice, which only the signature of the voucher gives away
+		return protocols.Message{To: bob.Address(), From: alice.Address(), Payments: []payments.Voucher{v}}
+	}
+
+	if err := ivanMS.Send(paymentFrom(ivan, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ivanMS.Send(paymentFrom(alice, 2)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-bobMS.P2PMessages():
+		if got.Payments[0].Amount.Cmp(big.NewInt(2)) != 0 {
+			t.Fatalf("expected the voucher signed by Alice, got one for %s", got.Payments[0].Amount)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the voucher signed by Alice to be delivered")
+	}
+	if got := metrics.count.Load(); got != 1 {
+		t.Errorf("expected 1 dropped message to be counted, got %d", got)
+	}
+}
@@ -16,3 +16,9 @@ type MessageService interface {
 	// Close closes the message service
 	Close() error
 }
+
+// ConnectionReporter is implemented by message services which can tell whether they are able to exchange messages with peers
+type ConnectionReporter interface {
+	// Connected returns true if the message service is able to reach peers, and has not been closed
+	Connected() bool
+}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/libp2p/go-libp2p"
@@ -51,17 +53,20 @@ type MessageOpts struct {
 }
 
 // P2PMessageService is a rudimentary message service that uses TCP to send and receive messages.
+// Every message to a peer is written to the same stream, whichever objective it is for: the engine routes each message by the objectives it carries.
 type P2PMessageService struct {
 	initComplete    chan struct{}
 	toEngine        chan protocols.Message // for forwarding processed messages to the engine
 	dhtSignRequests chan SignatureRequest  // for forwarding signature requests to the engine
 	peers           *safesync.Map[peer.ID]
+	streams         *safesync.Map[*peerStream] // the stream to each peer, keyed by peer id
 
 	scAddr      types.Address
 	p2pHost     host.Host
 	dht         *dht.IpfsDHT
 	newPeerInfo chan basicPeerInfo
 	logger      *slog.Logger
+	closed      atomic.Bool
 
 	MultiAddr string
 }
@@ -74,6 +79,7 @@ func NewMessageService(opts MessageOpts) *P2PMessageService {
 		dhtSignRequests: make(chan SignatureRequest, 50),
 		newPeerInfo:     make(chan basicPeerInfo, BUFFER_SIZE),
 		peers:           &safesync.Map[peer.ID]{},
+		streams:         &safesync.Map[*peerStream]{},
 		scAddr:          opts.SCAddr,
 		logger:          logging.LoggerWithAddress(slog.Default(), opts.SCAddr),
 	}
@@ -161,6 +167,9 @@ func (ms *P2PMessageService) setupDht(bootPeers []string) error {
 	}
 	n.DisconnectedF = func(n network.Network, conn network.Conn) {
 		ms.logger.Debug("notification: disconnected from peer", "peerId", conn.RemotePeer().String(), "peerCount", len(ms.p2pHost.Network().Peers()))
+		if n.Connectedness(conn.RemotePeer()) != network.Connected {
+			ms.forgetStream(conn.RemotePeer())
+		}
 	}
 	ms.p2pHost.Network().Notify(n)
 	ms.connectBootPeers(bootAddrs)
@@ -252,27 +261,69 @@ func (ms *P2PMessageService) addScaddrDhtRecord(ctx context.Context) {
 	ms.logger.Info("Added state channel address to dht")
 }
 
-func (ms *P2PMessageService) msgStreamHandler(stream network.Stream) {
-	defer stream.Close()
+// peerStream is the stream which carries every message to a peer. It is opened when the first message is sent, and reopened if writing to it fails.
+type peerStream struct {
+	mu        sync.Mutex // held while a message is written, so that messages are not interleaved
+	stream    network.Stream
+	writer    *bufio.Writer
+	forgotten bool // set once the peer disconnects and the peerStream is removed from the streams map
+}
 
-	reader := bufio.NewReader(stream)
-	// Create a buffer stream for non blocking read and write.
-	raw, err := reader.ReadString(DELIMITER)
+// lockStream returns the peerStream to the peer, locked. The caller must unlock it.
+func (ms *P2PMessageService) lockStream(peerId peer.ID) *peerStream {
+	for {
+		ps, _ := ms.streams.LoadOrStore(peerId.String(), &peerStream{})
+		ps.mu.Lock()
+		if !ps.forgotten {
+			return ps
+		}
+		// The peer disconnected since the peerStream was loaded, so a fresh one is stored in its place
+		ps.mu.Unlock()
+	}
+}
 
-	// An EOF means the stream has been closed by the other side.
-	if errors.Is(err, io.EOF) {
+// forgetStream closes the stream to the peer, if there is one, and removes it from the streams map
+func (ms *P2PMessageService) forgetStream(peerId peer.ID) {
+	ps, ok := ms.streams.Load(peerId.String())
+	if !ok {
 		return
 	}
-	if err != nil {
-		ms.logger.Error("error reading from stream", "err", err)
-		return
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.forgotten {
+		return // a peerStream stored in its place must not be removed
 	}
-	m, err := protocols.DeserializeMessage(raw)
-	if err != nil {
-		ms.logger.Error("error deserializing message", "err", err)
-		return
+	if ps.stream != nil {
+		_ = ps.stream.Close()
+		ps.stream, ps.writer = nil, nil
+	}
+	ps.forgotten = true
+	ms.streams.Delete(peerId.String())
+}
+
+// msgStreamHandler reads delimited messages from the stream until it is closed by the other side
+func (ms *P2PMessageService) msgStreamHandler(stream network.Stream) {
+	defer stream.Close()
+
+	reader := bufio.NewReader(stream)
+	for {
+		raw, err := reader.ReadString(DELIMITER)
+
+		// An EOF means the stream has been closed by the other side.
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			ms.logger.Error("error reading from stream", "err", err)
+			return
+		}
+		m, err := protocols.DeserializeMessage([]byte(raw))
+		if err != nil {
+			ms.logger.Error("error deserializing message", "err", err)
+			continue
+		}
+		ms.toEngine <- m
 	}
-	ms.toEngine <- m
 }
 
 func (ms *P2PMessageService) getPeerIdFromDht(scaddr string) (peer.ID, error) {
@@ -299,7 +350,7 @@ func (ms *P2PMessageService) getPeerIdFromDht(scaddr string) (peer.ID, error) {
 
 // Send sends messages to other participants.
 // It blocks until the message is sent.
-// It will retry establishing a stream NUM_CONNECT_ATTEMPTS times before giving up
+// Messages to a peer share a single stream. It will retry establishing the stream NUM_CONNECT_ATTEMPTS times before giving up
 func (ms *P2PMessageService) Send(msg protocols.Message) error {
 	raw, err := msg.Serialize()
 	if err != nil {
@@ -320,22 +371,32 @@ func (ms *P2PMessageService) Send(msg protocols.Message) error {
 		ms.logger.Debug("found scAddr in local cache", "scAddr", msg.To.String(), "peerId", peerId)
 	}
 
+	ps := ms.lockStream(peerId)
+	defer ps.mu.Unlock()
+
 	for i := 0; i < NUM_CONNECT_ATTEMPTS; i++ {
-		s, err := ms.p2pHost.NewStream(context.Background(), peerId, GENERAL_MSG_PROTOCOL_ID)
-		if err == nil {
-			writer := bufio.NewWriter(s)
-			_, err = writer.WriteString(raw + string(DELIMITER)) // We don't care about the number of bytes written
+		if ps.stream == nil {
+			s, err := ms.p2pHost.NewStream(context.Background(), peerId, GENERAL_MSG_PROTOCOL_ID)
 			if err != nil {
-				return err
+				ms.logger.Warn("error opening stream", "err", err, "attempt", i, "to", msg.To.String())
+				time.Sleep(RETRY_SLEEP_DURATION)
+				continue
 			}
+			ps.stream, ps.writer = s, bufio.NewWriter(s)
+		}
 
-			writer.Flush()
-			s.Close()
+		_, err = ps.writer.Write(append(raw, DELIMITER)) // We don't care about the number of bytes written
+		if err == nil {
+			err = ps.writer.Flush()
+		}
+		if err == nil {
 			return nil
 		}
 
-		ms.logger.Warn("error opening stream", "err", err, "attempt", i, "to", msg.To.String())
-		time.Sleep(RETRY_SLEEP_DURATION)
+		// The peer may have closed the stream, for example by restarting, so the message is written to a new stream
+		ms.logger.Warn("error writing to stream", "err", err, "attempt", i, "to", msg.To.String())
+		_ = ps.stream.Reset()
+		ps.stream, ps.writer = nil, nil
 	}
 	return nil
 }
@@ -359,8 +420,14 @@ func (ms *P2PMessageService) SignRequests() <-chan SignatureRequest {
 	return ms.dhtSignRequests
 }
 
+// Connected returns true while the message service is listening for peers, until it is closed
+func (ms *P2PMessageService) Connected() bool {
+	return !ms.closed.Load() && len(ms.p2pHost.Network().ListenAddresses()) > 0
+}
+
 // Close closes the P2PMessageService
 func (ms *P2PMessageService) Close() error {
+	ms.closed.Store(true)
 	ms.p2pHost.RemoveStreamHandler(GENERAL_MSG_PROTOCOL_ID)
 	return ms.p2pHost.Close()
 }
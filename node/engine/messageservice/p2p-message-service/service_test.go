@@ -0,0 +1,151 @@
+package p2pms
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// newSigningMessageService returns a running message service whose dht records are signed with the actor's key, as the engine would sign them
+func newSigningMessageService(t *testing.T, actor testactors.Actor, port int, bootPeers ...string) *P2PMessageService {
+	ms := NewMessageService(MessageOpts{PkBytes: actor.PrivateKey, Port: port, PublicIp: "127.0.0.1", SCAddr: actor.Address(), BootPeers: bootPeers})
+	go func() {
+		for req := range ms.SignRequests() {
+			data, err := json.Marshal(req.Data)
+			if err != nil {
+				panic(err)
+			}
+			hash := sha256.Sum256(data)
+			sig, err := secp256k1.Sign(hash[:], actor.PrivateKey)
+			if err != nil {
+				panic(err)
+			}
+			req.ResponseChan <- sig
+		}
+	}()
+	t.Cleanup(func() { _ = ms.Close() })
+	return ms
+}
+
+func TestMessagesToAPeerShareOneStream(t *testing.T) {
+	bob := newSigningMessageService(t, testactors.Bob, 3901)
+	alice := newSigningMessageService(t, testactors.Alice, 3902, bob.MultiAddr)
+	for _, ms := range []*P2PMessageService{alice, bob} {
+		select {
+		case <-ms.InitComplete():
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the message services to initialize")
+		}
+	}
+
+	// Each objective's message is sent concurrently, as the engine does
+	const objectives = 100
+	errs := make(chan error, objectives)
+	for i := 0; i < objectives; i++ {
+		msg := protocols.Message{
+			To:                testactors.Bob.Address(),
+			From:              testactors.Alice.Address(),
+			ObjectivePayloads: []protocols.ObjectivePayload{{ObjectiveId: protocols.ObjectiveId(fmt.Sprintf("objective-%d", i))}},
+		}
+		go func() { errs <- alice.Send(msg) }()
+	}
+
+	received := map[protocols.ObjectiveId]int{}
+	for i := 0; i < objectives; i++ {
+		select {
+		case msg := <-bob.P2PMessages():
+			for _, p := range msg.ObjectivePayloads {
+				received[p.ObjectiveId]++
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out after receiving %d of %d messages", i, objectives)
+		}
+	}
+	for i := 0; i < objectives; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+		if id := protocols.ObjectiveId(fmt.Sprintf("objective-%d", i)); received[id] != 1 {
+			t.Errorf("expected the message for %s to be received once, got %d", id, received[id])
+		}
+	}
+
+	conns := alice.p2pHost.Network().ConnsToPeer(bob.Id())
+	if len(conns) != 1 {
+		t.Fatalf("expected one connection to the peer, got %d", len(conns))
+	}
+	msgStreams := 0
+	for _, s := range conns[0].GetStreams() {
+		if s.Protocol() == GENERAL_MSG_PROTOCOL_ID {
+			msgStreams++
+		}
+	}
+	if msgStreams != 1 {
+		t.Errorf("expected the messages to share one stream, got %d streams", msgStreams)
+	}
+}
+
+func TestStreamsAreForgottenWhenAPeerDisconnects(t *testing.T) {
+	bob := newSigningMessageService(t, testactors.Bob, 3904)
+	alice := newSigningMessageService(t, testactors.Alice, 3905, bob.MultiAddr)
+	for _, ms := range []*P2PMessageService{alice, bob} {
+		select {
+		case <-ms.InitComplete():
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the message services to initialize")
+		}
+	}
+
+	send := func() {
+		t.Helper()
+		msg := protocols.Message{To: testactors.Bob.Address(), From: testactors.Alice.Address()}
+		if err := alice.Send(msg); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-bob.P2PMessages():
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the message")
+		}
+	}
+	send()
+	if _, ok := alice.streams.Load(bob.Id().String()); !ok {
+		t.Fatal("expected a stream to the peer once a message is sent")
+	}
+
+	if err := alice.p2pHost.Network().ClosePeer(bob.Id()); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, ok := alice.streams.Load(bob.Id().String()); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the stream to be forgotten once the peer disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A later message opens a new stream
+	send()
+}
+
+func TestConnectedUntilClosed(t *testing.T) {
+	ms := newSigningMessageService(t, testactors.Irene, 3903)
+	if !ms.Connected() {
+		t.Fatal("expected a listening message service to be connected")
+	}
+	if err := ms.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if ms.Connected() {
+		t.Error("expected a closed message service not to be connected")
+	}
+}
@@ -86,7 +86,7 @@ func (t TestMessageService) dispatchMessage(message protocols.Message) {
 		if err != nil {
 			panic(`could not serialize message`)
 		}
-		peer.HandleMessage([]byte(serializedMsg))
+		peer.HandleMessage(serializedMsg)
 	} else {
 		panic(fmt.Sprintf("node %v has no connection to node %v",
 			t.address, message.To))
@@ -106,7 +106,7 @@ func (tms TestMessageService) Send(msg protocols.Message) error {
 
 // HandleMessage deserialize the message and feed it to the engine
 func (tms TestMessageService) HandleMessage(message []byte) {
-	msg, err := protocols.DeserializeMessage(string(message))
+	msg, err := protocols.DeserializeMessage(message)
 	if err != nil {
 		panic(fmt.Errorf("could not deserialize message :%w", err))
 	}
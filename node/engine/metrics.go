@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// Metric names recorded by the engine
+const (
+	MessagesReceivedMetric    = "messages_received"
+	MessagesSentMetric        = "messages_sent"
+	ChainEventsHandledMetric  = "chain_events_handled"
+	ObjectivesSpawnedMetric   = "objectives_spawned"
+	ObjectivesCompletedMetric = "objectives_completed"
+	ObjectivesRejectedMetric  = "objectives_rejected"
+	ObjectivesInFlightMetric  = "objectives_in_flight"
+	CrankDurationMetric       = "crank_duration"
+	ObjectiveDurationMetric   = "objective_duration"
+)
+
+// MetricsApi is an interface for recording engine metrics.
+// It can be backed by a metrics system such as Prometheus, or discarded with NoOpMetrics.
+type MetricsApi interface {
+	// IncrementCounter increments the counter with the given name by one
+	IncrementCounter(name string)
+	// SetGauge sets the gauge with the given name to the given value
+	SetGauge(name string, value float64)
+	// RecordDuration adds the duration to the histogram with the given name
+	RecordDuration(name string, d time.Duration)
+}
+
+// NoOpMetrics is a MetricsApi that discards every metric
+type NoOpMetrics struct{}
+
+func (NoOpMetrics) IncrementCounter(name string)                {}
+func (NoOpMetrics) SetGauge(name string, value float64)         {}
+func (NoOpMetrics) RecordDuration(name string, d time.Duration) {}
+
+// MetricsRecorder records metrics about the engine using a MetricsApi
+type MetricsRecorder struct {
+	metrics MetricsApi
+
+	startTimes map[protocols.ObjectiveId]time.Time // start times of the objectives currently in flight
+	mu         sync.Mutex
+}
+
+// NewMetricsRecorder creates a MetricsRecorder that records to the given MetricsApi.
+// If metrics is nil, all metrics are discarded.
+func NewMetricsRecorder(metrics MetricsApi) *MetricsRecorder {
+	if metrics == nil {
+		metrics = NoOpMetrics{}
+	}
+	return &MetricsRecorder{metrics: metrics, startTimes: make(map[protocols.ObjectiveId]time.Time)}
+}
+
+// RecordHandlerDuration starts timing the handler with the given name.
+// The returned function stops the timer and records the duration.
+func (mr *MetricsRecorder) RecordHandlerDuration(handler string) func() {
+	start := time.Now()
+	return func() {
+		mr.metrics.RecordDuration(handler+"_duration", time.Since(start))
+	}
+}
+
+// RecordCrankDuration records how long it took to crank an objective
+func (mr *MetricsRecorder) RecordCrankDuration(d time.Duration) {
+	mr.metrics.RecordDuration(CrankDurationMetric, d)
+}
+
+// RecordMessageReceived increments the received messages counter
+func (mr *MetricsRecorder) RecordMessageReceived() {
+	mr.metrics.IncrementCounter(MessagesReceivedMetric)
+}
+
+// RecordMessageSent increments the sent messages counter
+func (mr *MetricsRecorder) RecordMessageSent() {
+	mr.metrics.IncrementCounter(MessagesSentMetric)
+}
+
+// RecordChainEventHandled increments the handled chain events counter
+func (mr *MetricsRecorder) RecordChainEventHandled() {
+	mr.metrics.IncrementCounter(ChainEventsHandledMetric)
+}
+
+// RecordObjectiveStarted records that an objective has been spawned and is now in flight
+func (mr *MetricsRecorder) RecordObjectiveStarted(id protocols.ObjectiveId) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, ok := mr.startTimes[id]; ok {
+		return
+	}
+	mr.startTimes[id] = time.Now()
+	mr.metrics.IncrementCounter(ObjectivesSpawnedMetric)
+	mr.metrics.SetGauge(ObjectivesInFlightMetric, float64(len(mr.startTimes)))
+}
+
+// RecordObjectiveCompleted records that an objective has completed and how long it took to complete
+func (mr *MetricsRecorder) RecordObjectiveCompleted(id protocols.ObjectiveId) {
+	mr.recordObjectiveFinished(id, ObjectivesCompletedMetric)
+}
+
+// RecordObjectiveRejected records that an objective has been rejected
+func (mr *MetricsRecorder) RecordObjectiveRejected(id protocols.ObjectiveId) {
+	mr.recordObjectiveFinished(id, ObjectivesRejectedMetric)
+}
+
+// recordObjectiveFinished increments the given counter and removes the objective from the in flight objectives
+func (mr *MetricsRecorder) recordObjectiveFinished(id protocols.ObjectiveId, counter string) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.metrics.IncrementCounter(counter)
+	if start, ok := mr.startTimes[id]; ok {
+		mr.metrics.RecordDuration(ObjectiveDurationMetric, time.Since(start))
+		delete(mr.startTimes, id)
+	}
+	mr.metrics.SetGauge(ObjectivesInFlightMetric, float64(len(mr.startTimes)))
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// testMetrics is a MetricsApi that keeps every recorded metric in memory
+type testMetrics struct {
+	counters  map[string]int
+	gauges    map[string]float64
+	durations map[string][]time.Duration
+}
+
+func newTestMetrics() *testMetrics {
+	return &testMetrics{counters: map[string]int{}, gauges: map[string]float64{}, durations: map[string][]time.Duration{}}
+}
+
+func (tm *testMetrics) IncrementCounter(name string) { tm.counters[name]++ }
+
+func (tm *testMetrics) SetGauge(name string, value float64) { tm.gauges[name] = value }
+
+func (tm *testMetrics) RecordDuration(name string, d time.Duration) {
+	tm.durations[name] = append(tm.durations[name], d)
+}
+
+func TestMetricsRecorder(t *testing.T) {
+	tm := newTestMetrics()
+	mr := NewMetricsRecorder(tm)
+
+	a, b := protocols.ObjectiveId("a"), protocols.ObjectiveId("b")
+
+	mr.RecordObjectiveStarted(a)
+	mr.RecordObjectiveStarted(b)
+	mr.RecordObjectiveStarted(a) // starting an objective twice should not count twice
+	if tm.counters[ObjectivesSpawnedMetric] != 2 {
+		t.Fatalf("expected 2 objectives spawned, got %d", tm.counters[ObjectivesSpawnedMetric])
+	}
+	if tm.gauges[ObjectivesInFlightMetric] != 2 {
+		t.Fatalf("expected 2 objectives in flight, got %f", tm.gauges[ObjectivesInFlightMetric])
+	}
+
+	mr.RecordObjectiveCompleted(a)
+	mr.RecordObjectiveRejected(b)
+	if tm.counters[ObjectivesCompletedMetric] != 1 || tm.counters[ObjectivesRejectedMetric] != 1 {
+		t.Fatalf("expected one completed and one rejected objective, got %v", tm.counters)
+	}
+	if tm.gauges[ObjectivesInFlightMetric] != 0 {
+		t.Fatalf("expected no objectives in flight, got %f", tm.gauges[ObjectivesInFlightMetric])
+	}
+	if len(tm.durations[ObjectiveDurationMetric]) != 2 {
+		t.Fatalf("expected 2 objective durations, got %d", len(tm.durations[ObjectiveDurationMetric]))
+	}
+
+	stop := mr.RecordHandlerDuration("handle_message")
+	stop()
+	if len(tm.durations["handle_message_duration"]) != 1 {
+		t.Fatalf("expected a handler duration to be recorded")
+	}
+}
+
+func TestMetricsRecorderDefaultsToNoOp(t *testing.T) {
+	mr := NewMetricsRecorder(nil)
+	mr.RecordObjectiveStarted("a")
+	mr.RecordObjectiveCompleted("a")
+	mr.RecordMessageSent()
+	mr.RecordCrankDuration(time.Second)
+}
+
+func TestFailedObjectiveRequestsAreNotInFlight(t *testing.T) {
+	alice := testactors.Alice
+	s := store.NewMemStore(alice.PrivateKey)
+	chain := chainservice.NewMockChainService(chainservice.NewMockChain(), alice.Address())
+	msg := messageservice.NewTestMessageService(alice.Address(), messageservice.NewBroker(), 0)
+	e := New(payments.NewVoucherManager(alice.Address(), s), msg, chain, s, &PermissivePolicy{}, func(EngineEvent) {}, nil, nil)
+	defer e.Close()
+
+	// A top up of nothing fails before its objective is constructed
+	request := ledgertopup.NewObjectiveRequest(types.Destination{1}, big.NewInt(0), 1)
+	e.ObjectiveRequestsFromAPI <- request
+	request.WaitForObjectiveToStart()
+
+	e.metrics.mu.Lock()
+	defer e.metrics.mu.Unlock()
+	if n := len(e.metrics.startTimes); n != 0 {
+		t.Fatalf("expected no objectives in flight, got %d", n)
+	}
+	// Nor is a span left open for it
+	if n := len(e.tracer.spans); n != 0 {
+		t.Fatalf("expected no objective spans, got %d", n)
+	}
+}
@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/logging"
+	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+)
+
+// waitingOnChain are the steps at which an objective waits on the chain rather than on a counterparty.
+// Challenging the objective's channels would not help it past them, so they have no deadline.
+var waitingOnChain = map[protocols.WaitingFor]bool{
+	protocols.WaitingForMyTurnToFund:    true,
+	protocols.WaitingForCompleteFunding: true,
+	protocols.WaitingForDeposit:         true,
+	protocols.WaitingForFinalization:    true,
+	protocols.WaitingForWithdraw:        true,
+}
+
+// objectiveDeadline returns how long objectives may wait on the same step before their channels are challenged, or zero if they are never challenged automatically
+func (e *Engine) objectiveDeadline() time.Duration {
+	if policy, ok := e.policymaker.(ObjectiveDeadlinePolicy); ok {
+		return policy.ObjectiveDeadline()
+	}
+	return 0
+}
+
+// deadlineCheckInterval returns how often objectives are checked against their deadlines, given the objective deadline
+func deadlineCheckInterval(deadline time.Duration) time.Duration {
+	return max(deadline/4, time.Millisecond)
+}
+
+// loadObjectiveDeadlines restores the deadlines recorded in the store, so that objectives which stalled before a restart are still escalated
+func (e *Engine) loadObjectiveDeadlines() {
+	deadlines, err := e.store.GetObjectiveDeadlines()
+	if err != nil {
+		e.logger.Error("Could not load objective deadlines", "error", err)
+		deadlines = map[protocols.ObjectiveId]store.ObjectiveDeadline{}
+	}
+	e.objectiveDeadlines = deadlines
+}
+
+// recordDeadlineProgress notes what an objective is waiting for after being cranked.
+// The deadline is reset whenever the objective moves on to a new step, and the objective is no longer tracked once it completes
+// or while it waits on the chain. Virtually funded objectives are never tracked, since their only directly funded channels are
+// ledgers shared with other channels, which a stalled virtual channel is no reason to challenge.
+func (e *Engine) recordDeadlineProgress(id protocols.ObjectiveId, waitingFor protocols.WaitingFor) error {
+	deadline := e.objectiveDeadline()
+	if deadline <= 0 {
+		return nil
+	}
+	if waitingFor == protocols.WaitingForNothing || waitingOnChain[waitingFor] || virtualfund.IsVirtualFundObjective(id) {
+		return e.forgetObjectiveDeadline(id)
+	}
+	if d, ok := e.objectiveDeadlines[id]; ok && d.WaitingFor == waitingFor {
+		return nil
+	}
+	return e.setObjectiveDeadline(id, store.ObjectiveDeadline{WaitingFor: waitingFor, Deadline: time.Now().Add(deadline)})
+}
+
+// setObjectiveDeadline records the objective's deadline, and writes it through to the store
+func (e *Engine) setObjectiveDeadline(id protocols.ObjectiveId, d store.ObjectiveDeadline) error {
+	if err := e.store.SetObjectiveDeadline(id, d); err != nil {
+		return err
+	}
+	e.objectiveDeadlines[id] = d
+	return nil
+}
+
+// forgetObjectiveDeadline stops tracking the objective's deadline, if it has one
+func (e *Engine) forgetObjectiveDeadline(id protocols.ObjectiveId) error {
+	if _, ok := e.objectiveDeadlines[id]; !ok {
+		return nil
+	}
+	if err := e.store.RemoveObjectiveDeadline(id); err != nil {
+		return err
+	}
+	delete(e.objectiveDeadlines, id)
+	return nil
+}
+
+// escalateStalledObjectives challenges the directly funded channels of each objective which has waited on the same step past its deadline,
+// registering their latest supported states with the adjudicator. The counterparties must then respond on chain, or the channels finalize with those states.
+// Objectives are escalated once per step; they keep running, so they still complete if the counterparties respond.
+func (e *Engine) escalateStalledObjectives() error {
+	for id, d := range e.objectiveDeadlines {
+		if d.Escalated || time.Now().Before(d.Deadline) {
+			continue
+		}
+		objective, err := e.store.GetObjectiveById(id)
+		if err != nil || objective.GetStatus() != protocols.Approved {
+			if err := e.forgetObjectiveDeadline(id); err != nil {
+				return err
+			}
+			continue
+		}
+		d.Escalated = true
+		if err := e.setObjectiveDeadline(id, d); err != nil {
+			return err
+		}
+
+		challenges, err := e.challengeTransactions(objective)
+		if err != nil {
+			return err
+		}
+		if len(challenges) == 0 {
+			e.logger.Warn("Objective has passed its deadline, but has no directly funded channels to challenge", logging.WithObjectiveIdAttribute(id), logging.WithWaitingForAttribute(d.WaitingFor))
+			continue
+		}
+		e.logger.Warn("Objective has passed its deadline, challenging its channels on chain", logging.WithObjectiveIdAttribute(id), logging.WithWaitingForAttribute(d.WaitingFor))
+		if err := e.executeSideEffects(protocols.SideEffects{TransactionsToSubmit: challenges}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// challengeTransactions returns a transaction challenging each of the objective's channels which are funded on chain with their latest supported state
+func (e *Engine) challengeTransactions(objective protocols.Objective) ([]protocols.ChainTransaction, error) {
+	challenges := []protocols.ChainTransaction{}
+	for _, related := range objective.Related() {
+		var candidate state.SignedState
+		switch c := related.(type) {
+		case *channel.Channel:
+			if !c.OnChain.Holdings.IsNonZero() || !c.HasSupportedState() {
+				continue
+			}
+			supported, err := c.LatestSupportedSignedState()
+			if err != nil {
+				return nil, err
+			}
+			candidate = supported
+		case *consensus_channel.ConsensusChannel:
+			if !c.OnChainFunding.IsNonZero() {
+				continue
+			}
+			candidate = c.SupportedSignedState()
+		default:
+			continue
+		}
+
+		challengerSig, err := NitroAdjudicator.SignChallengeMessage(candidate.State(), *e.store.GetChannelSecretKey())
+		if err != nil {
+			return nil, fmt.Errorf("could not sign challenge for channel %s: %w", candidate.ChannelId(), err)
+		}
+		challenges = append(challenges, protocols.NewChallengeTransaction(candidate.ChannelId(), candidate, []state.SignedState{}, challengerSig))
+	}
+	return challenges, nil
+}
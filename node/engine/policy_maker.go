@@ -1,16 +1,327 @@
 package engine
 
-import "github.com/statechannels/go-nitro/protocols"
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
 
-// PolicyMaker is used to decide whether to approve or reject an objective
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+	"golang.org/x/time/rate"
+)
+
+// PolicyMaker is used to decide whether to approve or reject an objective proposed by a counterparty.
+// Objectives requested through the API are approved by the request itself, and are not put to the PolicyMaker.
 type PolicyMaker interface {
-	ShouldApprove(o protocols.Objective) bool
+	// ShouldApprove decides whether to approve o. If it does not, it gives the reason for rejecting o.
+	ShouldApprove(o protocols.Objective) (approve bool, reason string)
 }
 
 // PermissivePolicy is a policy maker that decides to approve every unapproved objective
 type PermissivePolicy struct{}
 
 // ShouldApprove decides to approve o if it is currently unapproved
-func (pp *PermissivePolicy) ShouldApprove(o protocols.Objective) bool {
-	return o.GetStatus() == protocols.Unapproved
+func (pp *PermissivePolicy) ShouldApprove(o protocols.Objective) (bool, string) {
+	if o.GetStatus() != protocols.Unapproved {
+		return false, fmt.Sprintf("objective is %v rather than unapproved", o.GetStatus())
+	}
+	return true, ""
+}
+
+// PeerPolicyMaker is implemented by policy makers whose decisions depend on which peer proposed the objective.
+// The engine uses ShouldApproveFrom rather than ShouldApprove for objectives proposed in a peer's message.
+type PeerPolicyMaker interface {
+	PolicyMaker
+	ShouldApproveFrom(o protocols.Objective, peer types.Address) (approve bool, reason string)
+}
+
+// AdmissionPolicyMaker is implemented by policy makers which turn away objectives proposed by a peer before they are constructed.
+// The engine asks ShouldAdmit before it constructs an objective it has not seen, and rejects an objective which is not admitted without storing it.
+type AdmissionPolicyMaker interface {
+	PolicyMaker
+	ShouldAdmit(id protocols.ObjectiveId, peer types.Address) (admit bool, reason string)
+}
+
+// ReallocationPolicyMaker is implemented by policy makers which may agree to give up some of our allocation in a ledger channel,
+// when a counterparty proposes recycling the channel. The engine rejects such proposals unless the policy maker approves them with ShouldApproveReallocation.
+type ReallocationPolicyMaker interface {
+	PolicyMaker
+	ShouldApproveReallocation(o protocols.Objective) (approve bool, reason string)
+}
+
+// RateLimitPolicy limits how often each peer may propose objectives, so that a misbehaving peer cannot flood the node with them.
+// Each peer has a token bucket which refills at the configured rate. Objectives beyond the limit are turned away before they are stored,
+// and objectives within the limit are decided by the wrapped Policy.
+type RateLimitPolicy struct {
+	Policy    PolicyMaker
+	limit     rate.Limit
+	burst     int
+	mu        sync.Mutex
+	limiters  map[types.Address]*rate.Limiter
+	lastSweep time.Time // when limiters was last swept of the buckets of idle peers
+}
+
+// NewRateLimitPolicy returns a RateLimitPolicy which lets each peer propose up to burst objectives at once, and perSecond objectives per second thereafter.
+func NewRateLimitPolicy(policy PolicyMaker, perSecond float64, burst int) *RateLimitPolicy {
+	return &RateLimitPolicy{Policy: policy, limit: rate.Limit(perSecond), burst: burst, limiters: map[types.Address]*rate.Limiter{}, lastSweep: time.Now()}
+}
+
+// ShouldAdmit turns away the objective if peer has exceeded its rate limit
+func (rp *RateLimitPolicy) ShouldAdmit(id protocols.ObjectiveId, peer types.Address) (bool, string) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	now := time.Now()
+	rp.sweep(now)
+	limiter, ok := rp.limiters[peer]
+	if !ok {
+		limiter = rate.NewLimiter(rp.limit, rp.burst)
+		rp.limiters[peer] = limiter
+	}
+	if !limiter.AllowN(now, 1) {
+		return false, fmt.Sprintf("peer %s has exceeded the limit of %v objectives per second", peer, rp.limit)
+	}
+	return true, ""
+}
+
+// sweep forgets the buckets of peers which have refilled, since a full bucket limits a peer no more than a new one.
+// The buckets are swept at most once in the time it takes one to refill, so that sweeping does not slow every objective down.
+func (rp *RateLimitPolicy) sweep(now time.Time) {
+	refill := time.Duration(float64(rp.burst) / float64(rp.limit) * float64(time.Second))
+	if now.Sub(rp.lastSweep) < refill {
+		return
+	}
+	rp.lastSweep = now
+	for peer, limiter := range rp.limiters {
+		if limiter.TokensAt(now) >= float64(rp.burst) {
+			delete(rp.limiters, peer)
+		}
+	}
+}
+
+// tracked returns the number of peers whose buckets are held
+func (rp *RateLimitPolicy) tracked() int {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return len(rp.limiters)
+}
+
+// ShouldApprove defers to the wrapped Policy
+func (rp *RateLimitPolicy) ShouldApprove(o protocols.Objective) (bool, string) {
+	return rp.Policy.ShouldApprove(o)
+}
+
+// ShouldApproveFrom defers to the wrapped Policy, telling it which peer proposed o if it asks
+func (rp *RateLimitPolicy) ShouldApproveFrom(o protocols.Objective, peer types.Address) (bool, string) {
+	if policy, ok := rp.Policy.(PeerPolicyMaker); ok {
+		return policy.ShouldApproveFrom(o, peer)
+	}
+	return rp.Policy.ShouldApprove(o)
+}
+
+// AllowlistPolicy only approves new channels whose counterparties are all on the allowlist.
+// Objectives for existing channels, such as closing them, are approved.
+type AllowlistPolicy struct {
+	Allowed []types.Address
+}
+
+// ShouldApprove decides to approve o unless it funds a channel with a counterparty who is not on the allowlist
+func (ap *AllowlistPolicy) ShouldApprove(o protocols.Objective) (bool, string) {
+	c, ok := fundedChannel(o)
+	if !ok {
+		return true, ""
+	}
+	for _, peer := range counterparties(c) {
+		if !slices.Contains(ap.Allowed, peer) {
+			return false, fmt.Sprintf("counterparty %s is not on the allowlist", peer)
+		}
+	}
+	return true, ""
+}
+
+// MaxChannelsPerPeerPolicy only approves new channels with counterparties who share fewer than Max open channels with us.
+// Both ledger and payment channels count towards the limit. Objectives for existing channels, such as closing them, are approved.
+type MaxChannelsPerPeerPolicy struct {
+	Store store.ReadOnlyStore
+	Max   int
+}
+
+// ShouldApprove decides to approve o unless it funds a channel with a counterparty who already has Max open channels with us
+func (mp *MaxChannelsPerPeerPolicy) ShouldApprove(o protocols.Objective) (bool, string) {
+	c, ok := fundedChannel(o)
+	if !ok {
+		return true, ""
+	}
+	ledgers, err := mp.Store.GetAllConsensusChannels()
+	if err != nil {
+		return false, fmt.Sprintf("could not count ledger channels: %v", err)
+	}
+	for _, peer := range counterparties(c) {
+		open := 0
+		for _, ledger := range ledgers {
+			if slices.Contains(ledger.Participants(), peer) {
+				open++
+			}
+		}
+		channels, err := mp.Store.GetChannelsByParticipant(peer)
+		if err != nil {
+			return false, fmt.Sprintf("could not count channels with %s: %v", peer, err)
+		}
+		for _, other := range channels {
+			if other.Id != c.Id && !other.FinalCompleted() {
+				open++
+			}
+		}
+		if open >= mp.Max {
+			return false, fmt.Sprintf("counterparty %s already has %d open channels", peer, open)
+		}
+	}
+	return true, ""
+}
+
+// MinDepositPolicy only approves new channels which allocate at least MinDeposit of each of its assets.
+// Objectives for existing channels, such as closing them, are approved.
+type MinDepositPolicy struct {
+	MinDeposit types.Funds
+}
+
+// ShouldApprove decides to approve o unless it funds a channel which allocates less than MinDeposit
+func (mp *MinDepositPolicy) ShouldApprove(o protocols.Objective) (bool, string) {
+	c, ok := fundedChannel(o)
+	if !ok {
+		return true, ""
+	}
+	deposit := c.PreFundState().Outcome.TotalAllocated()
+	for asset, min := range mp.MinDeposit {
+		amount, ok := deposit[asset]
+		if !ok || types.Lt(amount, min) {
+			return false, fmt.Sprintf("deposit of %v is less than the minimum of %v", deposit, mp.MinDeposit)
+		}
+	}
+	return true, ""
+}
+
+// Sane bounds on the challenge duration of a channel, in seconds, for chains with block times of a few seconds, such as Ethereum's 12 seconds.
+// An hour is several hundred blocks, which leaves time to respond to a challenge even when transactions are slow to be mined.
+// Funds are locked for at most the challenge duration after a challenge, so a month is a generous upper bound.
+const (
+	DefaultMinChallengeDuration uint32 = 60 * 60
+	DefaultMaxChallengeDuration uint32 = 30 * 24 * 60 * 60
+)
+
+// ChallengeDurationPolicy only approves new channels whose challenge duration is between Min and Max inclusive.
+// A channel with a shorter challenge duration may finalize before we can respond to a challenge, and a longer one locks our funds for longer once challenged.
+// A Max of zero does not bound the challenge duration from above. Objectives for existing channels, such as closing them, are approved.
+type ChallengeDurationPolicy struct {
+	Min uint32
+	Max uint32
+}
+
+// ShouldApprove decides to approve o unless it funds a channel whose challenge duration is out of bounds
+func (cp *ChallengeDurationPolicy) ShouldApprove(o protocols.Objective) (bool, string) {
+	c, ok := fundedChannel(o)
+	if !ok {
+		return true, ""
+	}
+	if c.ChallengeDuration < cp.Min {
+		return false, fmt.Sprintf("challenge duration of %d is less than the minimum of %d", c.ChallengeDuration, cp.Min)
+	}
+	if cp.Max != 0 && c.ChallengeDuration > cp.Max {
+		return false, fmt.Sprintf("challenge duration of %d is greater than the maximum of %d", c.ChallengeDuration, cp.Max)
+	}
+	return true, ""
+}
+
+// fundedChannel returns the channel that o funds, if o is a directfund or virtualfund objective
+func fundedChannel(o protocols.Objective) (*channel.Channel, bool) {
+	switch o := o.(type) {
+	case *directfund.Objective:
+		return o.C, true
+	case *virtualfund.Objective:
+		return &o.V.Channel, true
+	default:
+		return nil, false
+	}
+}
+
+// counterparties returns the participants of c other than ourselves
+func counterparties(c *channel.Channel) []types.Address {
+	peers := []types.Address{}
+	for i, p := range c.Participants {
+		if uint(i) != c.MyIndex {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// IdleChannelPolicy is implemented by policy makers which want payment channels closed once they fall idle, to reclaim the liquidity locked in them.
+type IdleChannelPolicy interface {
+	PolicyMaker
+	// IdleChannelTimeout is how long a payment channel may go without a voucher being sent or received before the engine closes it.
+	IdleChannelTimeout() time.Duration
+}
+
+// AutoClosePolicy approves every unapproved objective, and closes payment channels which have been idle for longer than Timeout
+type AutoClosePolicy struct {
+	PermissivePolicy
+	Timeout time.Duration
+}
+
+// IdleChannelTimeout returns the policy's Timeout
+func (ap *AutoClosePolicy) IdleChannelTimeout() time.Duration {
+	return ap.Timeout
+}
+
+// SpawnRetryPolicy is implemented by policy makers which want the objectives we request retried when a counterparty does not respond,
+// for example because they are briefly offline.
+type SpawnRetryPolicy interface {
+	PolicyMaker
+	// SpawnRetryTimeout is how long a ledger or payment channel we requested may wait on its first step before the first message is sent again.
+	SpawnRetryTimeout() time.Duration
+	// MaxSpawnRetries is how many times the first message is sent again before the objective fails.
+	MaxSpawnRetries() int
+}
+
+// RetryPolicy approves every unapproved objective, and sends the first message of the channels we request again every Timeout
+// until they progress, failing them after MaxRetries attempts.
+type RetryPolicy struct {
+	PermissivePolicy
+	Timeout    time.Duration
+	MaxRetries int
+}
+
+// SpawnRetryTimeout returns the policy's Timeout
+func (rp *RetryPolicy) SpawnRetryTimeout() time.Duration {
+	return rp.Timeout
+}
+
+// MaxSpawnRetries returns the policy's MaxRetries
+func (rp *RetryPolicy) MaxSpawnRetries() int {
+	return rp.MaxRetries
+}
+
+// ObjectiveDeadlinePolicy is implemented by policy makers which want stalled objectives escalated on chain,
+// rather than waiting forever on a counterparty which has stopped responding.
+type ObjectiveDeadlinePolicy interface {
+	PolicyMaker
+	// ObjectiveDeadline is how long an objective may wait on a counterparty at the same step before the engine challenges its directly funded channels on chain.
+	// Objectives waiting on the chain, and virtualfund objectives, are never challenged.
+	ObjectiveDeadline() time.Duration
+}
+
+// DeadlinePolicy approves every unapproved objective, and challenges the directly funded channels of objectives which make no progress for Deadline
+type DeadlinePolicy struct {
+	PermissivePolicy
+	Deadline time.Duration
+}
+
+// ObjectiveDeadline returns the policy's Deadline
+func (dp *DeadlinePolicy) ObjectiveDeadline() time.Duration {
+	return dp.Deadline
 }
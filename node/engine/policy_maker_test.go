@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestAllowlistPolicy(t *testing.T) {
+	dfo := readyToDepositObjective(t, 1)
+
+	if approve, reason := (&AllowlistPolicy{Allowed: []types.Address{testactors.Bob.Address()}}).ShouldApprove(&dfo); !approve {
+		t.Errorf("expected a channel with an allowed counterparty to be approved, but it was rejected: %s", reason)
+	}
+	if approve, _ := (&AllowlistPolicy{Allowed: []types.Address{testactors.Irene.Address()}}).ShouldApprove(&dfo); approve {
+		t.Error("expected a channel with a counterparty who is not allowed to be rejected")
+	}
+}
+
+func TestMaxChannelsPerPeerPolicy(t *testing.T) {
+	s := store.NewMemStore(testactors.Alice.PrivateKey)
+	policy := &MaxChannelsPerPeerPolicy{Store: store.ReadOnly(s), Max: 1}
+
+	dfo := readyToDepositObjective(t, 1)
+	// The channel being funded does not count towards the limit
+	if err := s.SetChannel(dfo.C); err != nil {
+		t.Fatal(err)
+	}
+	if approve, reason := policy.ShouldApprove(&dfo); !approve {
+		t.Errorf("expected the first channel with Bob to be approved, but it was rejected: %s", reason)
+	}
+
+	another := readyToDepositObjective(t, 2)
+	if approve, _ := policy.ShouldApprove(&another); approve {
+		t.Error("expected a second channel with Bob to be rejected")
+	}
+}
+
+func TestMinDepositPolicy(t *testing.T) {
+	dfo := readyToDepositObjective(t, 1) // allocates 10 of the native asset
+
+	testCases := []struct {
+		name        string
+		minDeposit  types.Funds
+		wantApprove bool
+	}{
+		{"deposit equal to the minimum", types.Funds{common.Address{}: big.NewInt(10)}, true},
+		{"deposit below the minimum", types.Funds{common.Address{}: big.NewInt(11)}, false},
+		{"deposit missing an asset", types.Funds{common.HexToAddress("0x01"): big.NewInt(1)}, false},
+	}
+	for _, tc := range testCases {
+		approve, reason := (&MinDepositPolicy{MinDeposit: tc.minDeposit}).ShouldApprove(&dfo)
+		if approve != tc.wantApprove {
+			t.Errorf("%s: expected approval %t, got %t (%s)", tc.name, tc.wantApprove, approve, reason)
+		}
+	}
+}
+
+func TestChallengeDurationPolicy(t *testing.T) {
+	dfo := readyToDepositObjective(t, 1) // has a challenge duration of 60
+
+	testCases := []struct {
+		name        string
+		min, max    uint32
+		wantApprove bool
+	}{
+		{"below the minimum", 61, 100, false},
+		{"above the maximum", 10, 59, false},
+		{"in range", 60, 60, true},
+		{"no maximum", 60, 0, true},
+	}
+	for _, tc := range testCases {
+		approve, reason := (&ChallengeDurationPolicy{Min: tc.min, Max: tc.max}).ShouldApprove(&dfo)
+		if approve != tc.wantApprove {
+			t.Errorf("%s: expected approval %t, got %t (%s)", tc.name, tc.wantApprove, approve, reason)
+		}
+	}
+
+	// Objectives for existing channels are approved whatever their challenge duration
+	if approve, reason := (&ChallengeDurationPolicy{Min: DefaultMinChallengeDuration, Max: DefaultMaxChallengeDuration}).ShouldApprove(&directdefund.Objective{}); !approve {
+		t.Errorf("expected an objective which does not fund a channel to be approved, but it was rejected: %s", reason)
+	}
+}
+
+func TestRateLimitPolicy(t *testing.T) {
+	const perSecond, burst = 20, 2
+	policy := NewRateLimitPolicy(&PermissivePolicy{}, perSecond, burst)
+	dfo := readyToDepositObjective(t, 1)
+	dfo.Status = protocols.Unapproved
+	bob, irene := testactors.Bob.Address(), testactors.Irene.Address()
+
+	for i := 0; i < burst; i++ {
+		if admit, reason := policy.ShouldAdmit(dfo.Id(), bob); !admit {
+			t.Fatalf("expected objective %d within the burst to be admitted, but it was turned away: %s", i, reason)
+		}
+	}
+	if admit, _ := policy.ShouldAdmit(dfo.Id(), bob); admit {
+		t.Error("expected an objective beyond the burst to be turned away")
+	}
+	if admit, reason := policy.ShouldAdmit(dfo.Id(), irene); !admit {
+		t.Errorf("expected other peers not to be limited, but their objective was turned away: %s", reason)
+	}
+	if approve, reason := policy.ShouldApproveFrom(&dfo, bob); !approve {
+		t.Errorf("expected admitted objectives to be decided by the wrapped policy, but it was rejected: %s", reason)
+	}
+
+	// Once the bucket refills, the peer may propose objectives again
+	time.Sleep(time.Second / perSecond)
+	if admit, reason := policy.ShouldAdmit(dfo.Id(), bob); !admit {
+		t.Errorf("expected an objective after the limit refilled to be admitted, but it was turned away: %s", reason)
+	}
+
+	// The buckets of peers who have stopped proposing objectives are forgotten
+	time.Sleep(burst * time.Second / perSecond)
+	if admit, reason := policy.ShouldAdmit(dfo.Id(), irene); !admit {
+		t.Fatalf("expected an objective from an idle peer to be admitted, but it was turned away: %s", reason)
+	}
+	if n := policy.tracked(); n != 1 {
+		t.Errorf("expected only the bucket of the peer which just proposed an objective to be held, but %d are", n)
+	}
+}
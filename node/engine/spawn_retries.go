@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// ErrObjectiveStalled is returned when an objective we requested is failed because its counterparties did not respond to it
+var ErrObjectiveStalled = errors.New("objective stalled waiting for counterparties")
+
+// spawnedObjective records the first step of an objective requested through the API, and the messages which started it
+type spawnedObjective struct {
+	waitingFor protocols.WaitingFor
+	messages   []protocols.Message
+	deadline   time.Time
+	retries    int
+}
+
+// spawnRetryPolicy returns how long spawned objectives may wait on their first step before they are retried, and how many times they are retried.
+// The timeout is zero if spawned objectives are never retried.
+func (e *Engine) spawnRetryPolicy() (timeout time.Duration, maxRetries int) {
+	if policy, ok := e.policymaker.(SpawnRetryPolicy); ok {
+		return policy.SpawnRetryTimeout(), policy.MaxSpawnRetries()
+	}
+	return 0, 0
+}
+
+// spawnRetryCheckInterval returns how often spawned objectives are checked for stalling, given the spawn retry timeout
+func spawnRetryCheckInterval(timeout time.Duration) time.Duration {
+	return max(timeout/4, time.Millisecond)
+}
+
+// attemptSpawnedProgress cranks an objective requested through the API for the first time.
+// If the policy maker retries spawned objectives, the objective is tracked until it progresses past its first step.
+func (e *Engine) attemptSpawnedProgress(objective protocols.Objective) (EngineEvent, error) {
+	if timeout, _ := e.spawnRetryPolicy(); timeout > 0 {
+		e.spawnedObjectives[objective.Id()] = &spawnedObjective{}
+	}
+	ee, err := e.attemptProgress(objective)
+	if err != nil {
+		delete(e.spawnedObjectives, objective.Id())
+	}
+	return ee, err
+}
+
+// recordSpawnedProgress notes what a tracked objective is waiting for after being cranked.
+// The first crank records the objective's first step and the messages it sent. Once the objective moves on from that step, it is no longer tracked.
+func (e *Engine) recordSpawnedProgress(id protocols.ObjectiveId, waitingFor protocols.WaitingFor, messages []protocols.Message) {
+	s, ok := e.spawnedObjectives[id]
+	if !ok {
+		return
+	}
+	if s.waitingFor == "" && waitingFor != protocols.WaitingForNothing {
+		timeout, _ := e.spawnRetryPolicy()
+		s.waitingFor, s.messages, s.deadline = waitingFor, messages, time.Now().Add(timeout)
+		return
+	}
+	if waitingFor != s.waitingFor {
+		delete(e.spawnedObjectives, id)
+	}
+}
+
+// retryStalledObjectives sends the first messages of each spawned objective which is still on its first step after the spawn retry timeout again.
+// Objectives which are still stalled once they have been retried the maximum number of times are rejected, and returned as failed.
+func (e *Engine) retryStalledObjectives() (EngineEvent, error) {
+	allFailed := EngineEvent{}
+	var stalled []error
+	timeout, maxRetries := e.spawnRetryPolicy()
+	for id, s := range e.spawnedObjectives {
+		if time.Now().Before(s.deadline) {
+			continue
+		}
+		objective, err := e.store.GetObjectiveById(id)
+		if err != nil || objective.GetStatus() != protocols.Approved {
+			delete(e.spawnedObjectives, id)
+			continue
+		}
+
+		if s.retries < maxRetries {
+			s.retries++
+			s.deadline = time.Now().Add(timeout)
+			e.logger.Warn("Objective has stalled, sending its first messages again", logging.WithObjectiveIdAttribute(id), logging.WithWaitingForAttribute(s.waitingFor), "retry", s.retries)
+			if err := e.executeSideEffects(protocols.SideEffects{MessagesToSend: s.messages}); err != nil {
+				return allFailed, err
+			}
+			continue
+		}
+
+		delete(e.spawnedObjectives, id)
+		if c, ok := objective.(protocols.Cancellable); !ok || !c.IsCancellable() {
+			// The objective can no longer be abandoned safely, so it is left to complete if the counterparties respond
+			continue
+		}
+		_, sideEffects, err := e.abandonObjective(objective)
+		if err != nil {
+			return allFailed, err
+		}
+		allFailed.FailedObjectives = append(allFailed.FailedObjectives, id)
+		stalled = append(stalled, fmt.Errorf("objective %s is still %s after %d retries: %w", id, s.waitingFor, s.retries, ErrObjectiveStalled))
+		if err := e.executeSideEffects(sideEffects); err != nil {
+			return allFailed, err
+		}
+	}
+	return allFailed, errors.Join(stalled...)
+}
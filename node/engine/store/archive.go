@@ -0,0 +1,35 @@
+package store
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ArchivedChannel summarises the lifetime of a channel, so that it can be accounted for once it has closed.
+// It is kept whether or not the channel itself is still stored.
+type ArchivedChannel struct {
+	ID           types.Destination
+	Ledger       bool // true for a ledger channel, false for a payment channel
+	Participants []types.Address
+	OpenedAt     time.Time // zero if the channel was opened before the archive recorded it
+	ClosedAt     time.Time // zero until the channel has closed
+	// FinalOutcome is the outcome of the channel's final state, once it has closed
+	FinalOutcome outcome.Exit
+	// TotalPaid is the amount paid to the payee of a closed payment channel. It is nil for a ledger channel.
+	TotalPaid *big.Int
+}
+
+// Closed returns true if the channel has closed
+func (a ArchivedChannel) Closed() bool {
+	return !a.ClosedAt.IsZero()
+}
+
+// ArchiveStore records a summary of every channel which is opened and closed
+type ArchiveStore interface {
+	GetArchivedChannel(id types.Destination) (a ArchivedChannel, ok bool)
+	GetArchivedChannels() ([]ArchivedChannel, error) // Returns every archived channel, whether or not it has closed
+	SetArchivedChannel(ArchivedChannel) error
+}
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"fmt"
+)
+
+// Codec converts the JSON encoding of a stored value to and from the format it is persisted in.
+// Objectives and channels marshal themselves to JSON, so codecs operate on JSON documents.
+type Codec interface {
+	Name() string // recorded alongside the store's schema version, so that a store is always read with the codec it was written with
+	Encode(jsonData []byte) ([]byte, error)
+	Decode(data []byte) ([]byte, error)
+}
+
+// CodecByName returns the codec with the given name
+func CodecByName(name string) (Codec, error) {
+	for _, c := range []Codec{JSONCodec{}} {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownCodec, name)
+}
+
+// JSONCodec persists values as JSON. It is the default codec, and the only one built in.
+type JSONCodec struct{}
+
+func (JSONCodec) Name() string { return "json" }
+
+func (JSONCodec) Encode(jsonData []byte) ([]byte, error) { return jsonData, nil }
+
+func (JSONCodec) Decode(data []byte) ([]byte, error) { return data, nil }
@@ -0,0 +1,55 @@
+package store_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/tidwall/buntdb"
+)
+
+// reversingCodec persists values as their JSON encoding reversed, so that values read without decoding them are not valid JSON
+type reversingCodec struct{}
+
+func (reversingCodec) Name() string { return "reversing" }
+
+func (reversingCodec) Encode(jsonData []byte) ([]byte, error) { return reversed(jsonData), nil }
+
+func (reversingCodec) Decode(data []byte) ([]byte, error) { return reversed(data), nil }
+
+func reversed(b []byte) []byte {
+	r := slices.Clone(b)
+	slices.Reverse(r)
+	return r
+}
+
+func TestStoreIsReadWithItsCodec(t *testing.T) {
+	dataFolder := t.TempDir()
+	vfo := td.Objectives.Virtualfund.GenericVFO()
+
+	s, err := store.NewDurableStoreWithCodec(ta.Alice.PrivateKey, dataFolder, buntdb.Config{}, reversingCodec{})
+	testhelpers.Ok(t, err)
+	testhelpers.Ok(t, s.SetObjective(&vfo))
+	testhelpers.Ok(t, s.Close())
+
+	if _, err := store.NewDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{}); !errors.Is(err, store.ErrCodecMismatch) {
+		t.Fatalf("expected opening a reversing store as JSON to fail with %v, got %v", store.ErrCodecMismatch, err)
+	}
+
+	s, err = store.NewDurableStoreWithCodec(ta.Alice.PrivateKey, dataFolder, buntdb.Config{}, reversingCodec{})
+	testhelpers.Ok(t, err)
+	testhelpers.DestroyOnCleanup(t, s)
+	got, err := s.GetObjectiveById(vfo.Id())
+	testhelpers.Ok(t, err)
+	if diff := compareObjectives(got, &vfo); diff != "" {
+		t.Errorf("expected no diff between set and retrieved objective, but found:\n%s", diff)
+	}
+
+	if _, err := store.CodecByName("xml"); !errors.Is(err, store.ErrUnknownCodec) {
+		t.Errorf("expected an unknown codec to be rejected with %v, got %v", store.ErrUnknownCodec, err)
+	}
+}
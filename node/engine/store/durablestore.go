@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel"
@@ -14,10 +15,7 @@ import (
 	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
-	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
-	"github.com/statechannels/go-nitro/protocols/virtualdefund"
-	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/types"
 	"github.com/tidwall/buntdb"
 )
@@ -28,17 +26,31 @@ type DurableStore struct {
 	consensusChannels  *buntdb.DB
 	channelToObjective *buntdb.DB
 	vouchers           *buntdb.DB
+	submittedTxs       *buntdb.DB
+	deadlines          *buntdb.DB
+	channelActivity    *buntdb.DB
+	channelChains      *buntdb.DB
+	archive            *buntdb.DB
 	lastBlockNumSeen   *buntdb.DB
+	schema             *buntdb.DB
 
-	key     string // the signing key of the store's engine
-	address string // the (Ethereum) address associated to the signing key
-	folder  string // the folder where the store's data is stored
+	codec   Codec    // the format that values are persisted in
+	key     string   // the signing key of the store's engine
+	address string   // the (Ethereum) address associated to the signing key
+	folder  string   // the folder where the store's data is stored
+	files   []string // the files holding the store's databases
 }
 
-// NewDurableStore creates a new DurableStore that uses the given folder to store its data
+// NewDurableStore creates a new DurableStore that uses the given folder to store its data as JSON
 // It will create the folder if it does not exist
 func NewDurableStore(key []byte, folder string, config buntdb.Config) (Store, error) {
-	ps := DurableStore{}
+	return NewDurableStoreWithCodec(key, folder, config, JSONCodec{})
+}
+
+// NewDurableStoreWithCodec creates a new DurableStore that uses the given folder to store its data in the codec's format.
+// A store which already holds data must be opened with the codec it was written with.
+func NewDurableStoreWithCodec(key []byte, folder string, config buntdb.Config, codec Codec) (Store, error) {
+	ps := DurableStore{codec: codec}
 
 	me := crypto.GetAddressFromSecretKeyBytes(key)
 	dataFolder := filepath.Join(folder, me.String())
@@ -73,19 +85,141 @@ func NewDurableStore(key []byte, folder string, config buntdb.Config) (Store, er
 		return nil, err
 	}
 
+	ps.submittedTxs, err = ps.openDB("submitted_transactions", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.deadlines, err = ps.openDB("objective_deadlines", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.channelActivity, err = ps.openDB("channel_activity", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.channelChains, err = ps.openDB("channel_chains", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.archive, err = ps.openDB("archive", config)
+	if err != nil {
+		return nil, err
+	}
+
 	ps.lastBlockNumSeen, err = ps.openDB("lastBlockNumSeen", config)
 	if err != nil {
 		return nil, err
 	}
 
+	ps.schema, err = ps.openDB("schema", config)
+	if err != nil {
+		return nil, err
+	}
+	err = ps.checkSchema()
+	if err != nil {
+		return nil, errors.Join(err, ps.Close())
+	}
+
 	return &ps, nil
 }
 
+// schemaVersion is the version of the layout in which the durable store persists its data
+const schemaVersion = 1
+
+const schemaKey = "schema"
+
+// schemaInfo describes how a durable store's data is persisted. It is itself always persisted as JSON.
+type schemaInfo struct {
+	Version int
+	Codec   string
+}
+
+// checkSchema records the store's schema version and codec if it has none, and otherwise checks that they match the recorded ones.
+// Stores written before the schema was recorded hold JSON.
+func (ds *DurableStore) checkSchema() error {
+	return ds.schema.Update(func(tx *buntdb.Tx) error {
+		recorded := schemaInfo{Version: schemaVersion, Codec: JSONCodec{}.Name()}
+		val, err := tx.Get(schemaKey)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal([]byte(val), &recorded); err != nil {
+				return err
+			}
+		case !errors.Is(err, buntdb.ErrNotFound):
+			return err
+		case ds.isEmpty():
+			recorded.Codec = ds.codec.Name()
+		}
+
+		if recorded.Version != schemaVersion {
+			return fmt.Errorf("%w: %d", ErrUnsupportedSchema, recorded.Version)
+		}
+		if recorded.Codec != ds.codec.Name() {
+			return fmt.Errorf("%w: written with %s, opened with %s", ErrCodecMismatch, recorded.Codec, ds.codec.Name())
+		}
+		schemaJSON, err := json.Marshal(recorded)
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(schemaKey, string(schemaJSON), nil)
+		return err
+	})
+}
+
+// isEmpty returns true if the store holds no values persisted with a codec
+func (ds *DurableStore) isEmpty() bool {
+	for _, db := range []*buntdb.DB{ds.objectives, ds.channels, ds.consensusChannels, ds.vouchers, ds.deadlines, ds.channelActivity, ds.archive} {
+		n := 0
+		_ = db.View(func(tx *buntdb.Tx) error {
+			n, _ = tx.Len()
+			return nil
+		})
+		if n > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// encode marshals v to JSON, and encodes it with the store's codec
+func (ds *DurableStore) encode(v any) (string, error) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	data, err := ds.codec.Encode(jsonData)
+	return string(data), err
+}
+
+// decode decodes data with the store's codec, and unmarshals the JSON into v
+func (ds *DurableStore) decode(data string, v any) error {
+	jsonData, err := ds.codec.Decode([]byte(data))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonData, v)
+}
+
+// decodeObjective decodes data with the store's codec, and unmarshals the JSON into the objective with the given id
+func (ds *DurableStore) decodeObjective(id protocols.ObjectiveId, data string) (protocols.Objective, error) {
+	jsonData, err := ds.codec.Decode([]byte(data))
+	if err != nil {
+		return nil, err
+	}
+	return decodeObjective(id, jsonData)
+}
+
 func (ds *DurableStore) openDB(name string, config buntdb.Config) (*buntdb.DB, error) {
-	db, err := buntdb.Open(fmt.Sprintf("%s/%s_%s.db", ds.folder, name, ds.address[2:7]))
+	file := fmt.Sprintf("%s/%s_%s.db", ds.folder, name, ds.address[2:7])
+	db, err := buntdb.Open(file)
 	if err != nil {
 		return nil, err
 	}
+	ds.files = append(ds.files, file)
 	err = db.SetConfig(config)
 	if err != nil {
 		return nil, err
@@ -93,24 +227,29 @@ func (ds *DurableStore) openDB(name string, config buntdb.Config) (*buntdb.DB, e
 	return db, nil
 }
 
+// Close syncs the store's databases to disk and closes their files, so that writes which the sync policy has not yet synced are not lost.
+// Closing a store which is already closed has no effect.
 func (ds *DurableStore) Close() error {
-	err := ds.channels.Close()
-	if err != nil {
-		return err
-	}
-	err = ds.objectives.Close()
-	if err != nil {
-		return err
+	var err error
+	for _, db := range []*buntdb.DB{ds.channels, ds.objectives, ds.consensusChannels, ds.channelToObjective, ds.submittedTxs, ds.deadlines, ds.channelActivity, ds.channelChains, ds.vouchers, ds.archive, ds.lastBlockNumSeen, ds.schema} {
+		if closeErr := db.Close(); !errors.Is(closeErr, buntdb.ErrDatabaseClosed) {
+			err = errors.Join(err, closeErr)
+		}
 	}
-	err = ds.consensusChannels.Close()
-	if err != nil {
+	return err
+}
+
+// Destroy closes the store and deletes its data from disk. Other stores sharing the store's folder are not affected.
+func (ds *DurableStore) Destroy() error {
+	if err := ds.Close(); err != nil {
 		return err
 	}
-	err = ds.channelToObjective.Close()
-	if err != nil {
-		return err
+	for _, file := range ds.files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
 	}
-	return ds.vouchers.Close()
+	return os.RemoveAll(filepath.Join(ds.folder, ds.address))
 }
 
 func (ds *DurableStore) GetAddress() *types.Address {
@@ -131,7 +270,7 @@ func (ds *DurableStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Ob
 			return err
 		}
 
-		obj, err = decodeObjective(id, []byte(objJSON))
+		obj, err = ds.decodeObjective(id, objJSON)
 		if err != nil {
 			return fmt.Errorf("error decoding objective %s: %w", id, err)
 		}
@@ -152,13 +291,13 @@ func (ds *DurableStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Ob
 
 func (ds *DurableStore) SetObjective(obj protocols.Objective) error {
 	// todo: locking
-	objJSON, err := obj.MarshalJSON()
+	objJSON, err := ds.encode(obj)
 	if err != nil {
 		return fmt.Errorf("error setting objective %s: %w", obj.Id(), err)
 	}
 
 	err = ds.objectives.Update(func(tx *buntdb.Tx) error {
-		_, _, err := tx.Set(string(obj.Id()), string(objJSON), nil)
+		_, _, err := tx.Set(string(obj.Id()), objJSON, nil)
 		return err
 	})
 
@@ -224,9 +363,18 @@ func (ds *DurableStore) SetObjective(obj protocols.Objective) error {
 
 // GetLastBlockNumSeen retrieves the last blockchain block processed by this node
 func (ds *DurableStore) GetLastBlockNumSeen() (uint64, error) {
+	return ds.getLastBlockNumSeen(lastBlockNumSeenKey)
+}
+
+// GetLastBlockNumSeenOnChain retrieves the last block of the chain processed by this node
+func (ds *DurableStore) GetLastBlockNumSeenOnChain(chainId string) (uint64, error) {
+	return ds.getLastBlockNumSeen(lastBlockNumSeenKey + ":" + chainId)
+}
+
+func (ds *DurableStore) getLastBlockNumSeen(key string) (uint64, error) {
 	var result uint64
 	err := ds.lastBlockNumSeen.View(func(tx *buntdb.Tx) error {
-		val, err := tx.Get(lastBlockNumSeenKey)
+		val, err := tx.Get(key)
 		if err != nil {
 			if errors.Is(err, buntdb.ErrNotFound) {
 				result = 0
@@ -242,21 +390,30 @@ func (ds *DurableStore) GetLastBlockNumSeen() (uint64, error) {
 
 // SetLastBlockNumSeen sets the last blockchain block processed by this node
 func (ds *DurableStore) SetLastBlockNumSeen(blockNumber uint64) error {
+	return ds.setLastBlockNumSeen(lastBlockNumSeenKey, blockNumber)
+}
+
+// SetLastBlockNumSeenOnChain sets the last block of the chain processed by this node
+func (ds *DurableStore) SetLastBlockNumSeenOnChain(chainId string, blockNumber uint64) error {
+	return ds.setLastBlockNumSeen(lastBlockNumSeenKey+":"+chainId, blockNumber)
+}
+
+func (ds *DurableStore) setLastBlockNumSeen(key string, blockNumber uint64) error {
 	return ds.lastBlockNumSeen.Update(func(tx *buntdb.Tx) error {
-		_, _, err := tx.Set(lastBlockNumSeenKey, strconv.FormatUint(blockNumber, 10), nil)
+		_, _, err := tx.Set(key, strconv.FormatUint(blockNumber, 10), nil)
 		return err
 	})
 }
 
 // SetChannel sets the channel in the store.
 func (ds *DurableStore) SetChannel(ch *channel.Channel) error {
-	chJSON, err := ch.MarshalJSON()
+	chJSON, err := ds.encode(ch)
 	if err != nil {
 		return err
 	}
 
 	err = ds.channels.Update(func(tx *buntdb.Tx) error {
-		_, _, err := tx.Set(ch.Id.String(), string(chJSON), nil)
+		_, _, err := tx.Set(ch.Id.String(), chJSON, nil)
 		return err
 	})
 	return err
@@ -275,13 +432,13 @@ func (ps *DurableStore) SetConsensusChannel(ch *consensus_channel.ConsensusChann
 	if ch.Id.IsZero() {
 		return fmt.Errorf("cannot store a channel with a zero id")
 	}
-	chJSON, err := ch.MarshalJSON()
+	chJSON, err := ps.encode(ch)
 	if err != nil {
 		return err
 	}
 
 	err = ps.consensusChannels.Update(func(tx *buntdb.Tx) error {
-		_, _, err := tx.Set(ch.Id.String(), string(chJSON), nil)
+		_, _, err := tx.Set(ch.Id.String(), chJSON, nil)
 		return err
 	})
 
@@ -319,7 +476,7 @@ func (ds *DurableStore) getChannelById(id types.Destination) (channel.Channel, e
 		return channel.Channel{}, ErrNoSuchChannel
 	}
 	var ch channel.Channel
-	err = ch.UnmarshalJSON([]byte(chJSON))
+	err = ds.decode(chJSON, &ch)
 
 	if err != nil {
 		return channel.Channel{}, fmt.Errorf("error unmarshaling channel %s", ch.Id)
@@ -339,7 +496,7 @@ func (ds *DurableStore) GetChannelsByIds(ids []types.Destination) ([]*channel.Ch
 	txError := ds.channels.View(func(tx *buntdb.Tx) error {
 		return tx.Ascend("", func(key, chJSON string) bool {
 			var ch channel.Channel
-			err = json.Unmarshal([]byte(chJSON), &ch)
+			err = ds.decode(chJSON, &ch)
 			if err != nil {
 				return false
 			}
@@ -374,7 +531,7 @@ func (ds *DurableStore) GetChannelsByAppDefinition(appDef types.Address) ([]*cha
 	err := ds.channels.View(func(tx *buntdb.Tx) error {
 		return tx.Ascend("", func(key, chJSON string) bool {
 			var ch channel.Channel
-			unmarshErr = json.Unmarshal([]byte(chJSON), &ch)
+			unmarshErr = ds.decode(chJSON, &ch)
 			if unmarshErr != nil {
 				return false
 			}
@@ -401,7 +558,7 @@ func (ds *DurableStore) GetChannelsByParticipant(participant types.Address) ([]*
 	err := ds.channels.View(func(tx *buntdb.Tx) error {
 		err := tx.Ascend("", func(key, chJSON string) bool {
 			var ch channel.Channel
-			err := json.Unmarshal([]byte(chJSON), &ch)
+			err := ds.decode(chJSON, &ch)
 			if err != nil {
 				return true // channel not found, continue looking
 			}
@@ -423,6 +580,63 @@ func (ds *DurableStore) GetChannelsByParticipant(participant types.Address) ([]*
 	return toReturn, nil
 }
 
+// GetAllObjectives returns every objective whose channels are still stored.
+// Objectives whose channels have since been destroyed are omitted.
+func (ds *DurableStore) GetAllObjectives() ([]protocols.Objective, error) {
+	objJSONs := map[protocols.ObjectiveId]string{}
+	err := ds.objectives.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, objJSON string) bool {
+			objJSONs[protocols.ObjectiveId(key)] = objJSON
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	toReturn := []protocols.Objective{}
+	for id, objJSON := range objJSONs {
+		obj, err := ds.decodeObjective(id, objJSON)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding objective %s: %w", id, err)
+		}
+		err = ds.populateChannelData(obj)
+		if errors.Is(err, ErrNoSuchChannel) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error populating channel data for objective %s: %w", id, err)
+		}
+		toReturn = append(toReturn, obj)
+	}
+	return toReturn, nil
+}
+
+// GetAllChannels returns every channel
+func (ds *DurableStore) GetAllChannels() ([]*channel.Channel, error) {
+	toReturn := []*channel.Channel{}
+	var unmarshErr error
+	err := ds.channels.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, chJSON string) bool {
+			var ch channel.Channel
+			unmarshErr = ds.decode(chJSON, &ch)
+			if unmarshErr != nil {
+				return false
+			}
+			toReturn = append(toReturn, &ch)
+			return true
+		})
+	})
+	if err != nil {
+		return []*channel.Channel{}, err
+	}
+
+	if unmarshErr != nil {
+		return []*channel.Channel{}, unmarshErr
+	}
+	return toReturn, nil
+}
+
 func (ds *DurableStore) GetAllConsensusChannels() ([]*consensus_channel.ConsensusChannel, error) {
 	toReturn := []*consensus_channel.ConsensusChannel{}
 	var unmarshErr error
@@ -430,7 +644,7 @@ func (ds *DurableStore) GetAllConsensusChannels() ([]*consensus_channel.Consensu
 		return tx.Ascend("", func(key, chJSON string) bool {
 			var ch consensus_channel.ConsensusChannel
 
-			unmarshErr = json.Unmarshal([]byte(chJSON), &ch)
+			unmarshErr = ds.decode(chJSON, &ch)
 			if unmarshErr != nil {
 				return false
 			}
@@ -459,7 +673,7 @@ func (ds *DurableStore) GetConsensusChannelById(id types.Destination) (channel *
 		}
 
 		ch = &consensus_channel.ConsensusChannel{}
-		err = ch.UnmarshalJSON([]byte(chJSON))
+		err = ds.decode(chJSON, ch)
 
 		if err != nil {
 			return fmt.Errorf("error unmarshaling channel %s", ch.Id)
@@ -478,7 +692,7 @@ func (ps *DurableStore) GetConsensusChannel(counterparty types.Address) (channel
 	err := ps.consensusChannels.View(func(tx *buntdb.Tx) error {
 		return tx.Ascend("", func(key, chJSON string) bool {
 			var ch consensus_channel.ConsensusChannel
-			err := json.Unmarshal([]byte(chJSON), &ch)
+			err := ps.decode(chJSON, &ch)
 			if err != nil {
 				return true // channel not found, continue looking
 			}
@@ -518,133 +732,260 @@ func (ps *DurableStore) GetObjectiveByChannelId(channelId types.Destination) (pr
 	return objective, err == nil
 }
 
-// populateChannelData fetches stored Channel data relevant to the given
-// objective and attaches it to the objective. The channel data is attached
-// in-place of the objectives existing channel pointers.
+// populateChannelData attaches the stored channel data relevant to the given objective
 func (ds *DurableStore) populateChannelData(obj protocols.Objective) error {
-	id := obj.Id()
+	return populateChannelData(obj, ds.getChannelById, ds.GetConsensusChannelById)
+}
 
-	switch o := obj.(type) {
-	case *directfund.Objective:
-		ch, err := ds.getChannelById(o.C.Id)
-		if err != nil {
-			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+func (ds *DurableStore) ReleaseChannelFromOwnership(channelId types.Destination) error {
+	return ds.channelToObjective.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(channelId.String())
+		// As in the MemStore, releasing an unowned channel is not an error: an objective which completes on its first crank,
+		// such as an app update accepted by the counterparty, never owned its channel
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
 		}
+		return err
+	})
+}
 
-		o.C = &ch
+func (ds *DurableStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
+	return ds.vouchers.Update(func(tx *buntdb.Tx) error {
+		vJSON, err := ds.encode(v)
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(channelId.String(), vJSON, nil)
 
-		return nil
-	case *directdefund.Objective:
+		return err
+	})
+}
 
-		ch, err := ds.getChannelById(o.C.Id)
+func (ds *DurableStore) GetVoucherInfo(channelId types.Destination) (*payments.VoucherInfo, error) {
+	v := &payments.VoucherInfo{}
+	err := ds.vouchers.View(func(tx *buntdb.Tx) error {
+		vJSON, err := tx.Get(channelId.String())
 		if err != nil {
-			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+			return fmt.Errorf("channelId %s: %w", channelId.String(), ErrLoadVouchers)
 		}
+		return ds.decode(vJSON, v)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
 
-		o.C = &ch
+func (ds *DurableStore) RemoveVoucherInfo(channelId types.Destination) error {
+	return ds.vouchers.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(channelId.String())
+		return err
+	})
+}
 
-		return nil
-	case *virtualfund.Objective:
-		v, err := ds.getChannelById(o.V.Id)
-		if err != nil {
-			return fmt.Errorf("error retrieving virtual channel data for objective %s: %w", id, err)
+func (ds *DurableStore) IsTransactionSubmitted(channelId types.Destination, txKey string) (bool, error) {
+	submitted := false
+	err := ds.submittedTxs.View(func(tx *buntdb.Tx) error {
+		_, err := tx.Get(submittedTxKey(channelId, txKey))
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
 		}
-		o.V = &channel.VirtualChannel{Channel: v}
+		submitted = err == nil
+		return err
+	})
+	return submitted, err
+}
 
-		zeroAddress := types.Destination{}
+func (ds *DurableStore) SetTransactionSubmitted(channelId types.Destination, txKey string) error {
+	return ds.submittedTxs.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(submittedTxKey(channelId, txKey), "", nil)
+		return err
+	})
+}
 
-		if o.ToMyLeft != nil &&
-			o.ToMyLeft.Channel != nil &&
-			o.ToMyLeft.Channel.Id != zeroAddress {
+func (ds *DurableStore) UnsetTransactionSubmitted(channelId types.Destination, txKey string) error {
+	return ds.submittedTxs.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(submittedTxKey(channelId, txKey))
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		return err
+	})
+}
 
-			left, err := ds.GetConsensusChannelById(o.ToMyLeft.Channel.Id)
-			if err != nil {
-				return fmt.Errorf("error retrieving left ledger channel data for objective %s: %w", id, err)
+func (ds *DurableStore) RemoveSubmittedTransactions(channelId types.Destination) error {
+	return ds.submittedTxs.Update(func(tx *buntdb.Tx) error {
+		keys := []string{}
+		err := tx.AscendKeys(submittedTxKey(channelId, "*"), func(key, _ string) bool {
+			keys = append(keys, key)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if _, err := tx.Delete(key); err != nil {
+				return err
 			}
-			o.ToMyLeft.Channel = left
 		}
+		return nil
+	})
+}
 
-		if o.ToMyRight != nil &&
-			o.ToMyRight.Channel != nil &&
-			o.ToMyRight.Channel.Id != zeroAddress {
-			right, err := ds.GetConsensusChannelById(o.ToMyRight.Channel.Id)
-			if err != nil {
-				return fmt.Errorf("error retrieving right ledger channel data for objective %s: %w", id, err)
+func (ds *DurableStore) GetObjectiveDeadlines() (map[protocols.ObjectiveId]ObjectiveDeadline, error) {
+	deadlines := map[protocols.ObjectiveId]ObjectiveDeadline{}
+	var unmarshErr error
+	err := ds.deadlines.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, dJSON string) bool {
+			var d ObjectiveDeadline
+			unmarshErr = ds.decode(dJSON, &d)
+			if unmarshErr != nil {
+				return false
 			}
-			o.ToMyRight.Channel = right
-		}
+			deadlines[protocols.ObjectiveId(key)] = d
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if unmarshErr != nil {
+		return nil, unmarshErr
+	}
+	return deadlines, nil
+}
 
-		return nil
-	case *virtualdefund.Objective:
-		v, err := ds.getChannelById(o.V.Id)
+func (ds *DurableStore) SetObjectiveDeadline(id protocols.ObjectiveId, d ObjectiveDeadline) error {
+	return ds.deadlines.Update(func(tx *buntdb.Tx) error {
+		dJSON, err := ds.encode(d)
 		if err != nil {
-			return fmt.Errorf("error retrieving virtual channel data for objective %s: %w", id, err)
+			return err
 		}
-		o.V = &channel.VirtualChannel{Channel: v}
-
-		zeroAddress := types.Destination{}
+		_, _, err = tx.Set(string(id), dJSON, nil)
+		return err
+	})
+}
 
-		if o.ToMyLeft != nil &&
-			o.ToMyLeft.Id != zeroAddress {
+func (ds *DurableStore) RemoveObjectiveDeadline(id protocols.ObjectiveId) error {
+	return ds.deadlines.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(string(id))
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		return err
+	})
+}
 
-			left, err := ds.GetConsensusChannelById(o.ToMyLeft.Id)
-			if err != nil {
-				return fmt.Errorf("error retrieving left ledger channel data for objective %s: %w", id, err)
+func (ds *DurableStore) GetChannelActivity() (map[types.Destination]time.Time, error) {
+	activity := map[types.Destination]time.Time{}
+	var unmarshErr error
+	err := ds.channelActivity.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, tJSON string) bool {
+			var lastActive time.Time
+			unmarshErr = ds.decode(tJSON, &lastActive)
+			if unmarshErr != nil {
+				return false
 			}
-			o.ToMyLeft = left
+			activity[types.Destination(common.HexToHash(key))] = lastActive
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if unmarshErr != nil {
+		return nil, unmarshErr
+	}
+	return activity, nil
+}
+
+func (ds *DurableStore) SetChannelActivity(channelId types.Destination, lastActive time.Time) error {
+	return ds.channelActivity.Update(func(tx *buntdb.Tx) error {
+		tJSON, err := ds.encode(lastActive)
+		if err != nil {
+			return err
 		}
+		_, _, err = tx.Set(channelId.String(), tJSON, nil)
+		return err
+	})
+}
 
-		if o.ToMyRight != nil &&
-			o.ToMyRight.Id != zeroAddress {
-			right, err := ds.GetConsensusChannelById(o.ToMyRight.Id)
-			if err != nil {
-				return fmt.Errorf("error retrieving right ledger channel data for objective %s: %w", id, err)
-			}
-			o.ToMyRight = right
+func (ds *DurableStore) RemoveChannelActivity(channelId types.Destination) error {
+	return ds.channelActivity.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(channelId.String())
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
 		}
-		return nil
-	default:
-		return fmt.Errorf("objective %s did not correctly represent a known Objective type", id)
+		return err
+	})
+}
+
+func (ds *DurableStore) GetChannelChains() (map[types.Destination]string, error) {
+	chains := map[types.Destination]string{}
+	err := ds.channelChains.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, chainId string) bool {
+			chains[types.Destination(common.HexToHash(key))] = chainId
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
+	return chains, nil
 }
 
-func (ds *DurableStore) ReleaseChannelFromOwnership(channelId types.Destination) error {
-	return ds.channelToObjective.Update(func(tx *buntdb.Tx) error {
-		_, err := tx.Delete(channelId.String())
+func (ds *DurableStore) SetChannelChain(channelId types.Destination, chainId string) error {
+	return ds.channelChains.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(channelId.String(), chainId, nil)
 		return err
 	})
 }
 
-func (ds *DurableStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
-	return ds.vouchers.Update(func(tx *buntdb.Tx) error {
-		vJSON, err := json.Marshal(v)
+func (ds *DurableStore) SetArchivedChannel(a ArchivedChannel) error {
+	return ds.archive.Update(func(tx *buntdb.Tx) error {
+		aJSON, err := ds.encode(a)
 		if err != nil {
 			return err
 		}
-		_, _, err = tx.Set(channelId.String(), string(vJSON), nil)
-
+		_, _, err = tx.Set(a.ID.String(), aJSON, nil)
 		return err
 	})
 }
 
-func (ds *DurableStore) GetVoucherInfo(channelId types.Destination) (*payments.VoucherInfo, error) {
-	v := &payments.VoucherInfo{}
-	err := ds.vouchers.View(func(tx *buntdb.Tx) error {
-		vJSON, err := tx.Get(channelId.String())
+func (ds *DurableStore) GetArchivedChannel(id types.Destination) (ArchivedChannel, bool) {
+	var a ArchivedChannel
+	err := ds.archive.View(func(tx *buntdb.Tx) error {
+		aJSON, err := tx.Get(id.String())
 		if err != nil {
-			return fmt.Errorf("channelId %s: %w", channelId.String(), ErrLoadVouchers)
+			return err
 		}
-		return json.Unmarshal([]byte(vJSON), v)
+		return ds.decode(aJSON, &a)
 	})
 	if err != nil {
-		return nil, err
+		return ArchivedChannel{}, false
 	}
-	return v, nil
+	return a, true
 }
 
-func (ds *DurableStore) RemoveVoucherInfo(channelId types.Destination) error {
-	return ds.vouchers.Update(func(tx *buntdb.Tx) error {
-		_, err := tx.Delete(channelId.String())
-		return err
+func (ds *DurableStore) GetArchivedChannels() ([]ArchivedChannel, error) {
+	toReturn := []ArchivedChannel{}
+	var unmarshErr error
+	err := ds.archive.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, aJSON string) bool {
+			var a ArchivedChannel
+			unmarshErr = ds.decode(aJSON, &a)
+			if unmarshErr != nil {
+				return false
+			}
+			toReturn = append(toReturn, a)
+			return true
+		})
 	})
+	if err != nil {
+		return []ArchivedChannel{}, err
+	}
+	if unmarshErr != nil {
+		return []ArchivedChannel{}, unmarshErr
+	}
+	return toReturn, nil
 }
@@ -2,8 +2,11 @@ package store
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel"
@@ -12,8 +15,11 @@ import (
 	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/appupdate"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgerrecycle"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/types"
@@ -30,6 +36,12 @@ type MemStore struct {
 	consensusChannels  safesync.Map[[]byte]
 	channelToObjective safesync.Map[protocols.ObjectiveId]
 	vouchers           safesync.Map[[]byte]
+	submittedTxs       safesync.Map[bool]
+	deadlines          safesync.Map[ObjectiveDeadline]
+	channelActivity    safesync.Map[time.Time]
+	channelChains      safesync.Map[string]
+	lastBlockOnChain   safesync.Map[uint64]
+	archive            safesync.Map[[]byte]
 	lastBlockSeen      blockData
 
 	key     string // the signing key of the store's engine
@@ -46,6 +58,12 @@ func NewMemStore(key []byte) Store {
 	ms.consensusChannels = safesync.Map[[]byte]{}
 	ms.channelToObjective = safesync.Map[protocols.ObjectiveId]{}
 	ms.vouchers = safesync.Map[[]byte]{}
+	ms.submittedTxs = safesync.Map[bool]{}
+	ms.deadlines = safesync.Map[ObjectiveDeadline]{}
+	ms.channelActivity = safesync.Map[time.Time]{}
+	ms.channelChains = safesync.Map[string]{}
+	ms.lastBlockOnChain = safesync.Map[uint64]{}
+	ms.archive = safesync.Map[[]byte]{}
 	ms.lastBlockSeen = blockData{}
 	return &ms
 }
@@ -55,6 +73,11 @@ func (ms *MemStore) Close() error {
 	return nil
 }
 
+func (ms *MemStore) Destroy() error {
+	// Since this is a memory store, its data is discarded along with the store
+	return ms.Close()
+}
+
 func (ms *MemStore) GetAddress() *types.Address {
 	address := common.HexToAddress(ms.address)
 	return &address
@@ -354,6 +377,49 @@ func (ms *MemStore) GetAllConsensusChannels() ([]*consensus_channel.ConsensusCha
 	return toReturn, nil
 }
 
+// GetAllObjectives returns every objective whose channels are still stored.
+// Objectives whose channels have since been destroyed are omitted.
+func (ms *MemStore) GetAllObjectives() ([]protocols.Objective, error) {
+	toReturn := []protocols.Objective{}
+	var err error
+	ms.objectives.Range(func(key string, _ []byte) bool {
+		var obj protocols.Objective
+		obj, err = ms.GetObjectiveById(protocols.ObjectiveId(key))
+		if errors.Is(err, ErrNoSuchChannel) {
+			err = nil
+			return true
+		}
+		if err != nil {
+			return false
+		}
+		toReturn = append(toReturn, obj)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toReturn, nil
+}
+
+// GetAllChannels returns every channel
+func (ms *MemStore) GetAllChannels() ([]*channel.Channel, error) {
+	toReturn := []*channel.Channel{}
+	var err error
+	ms.channels.Range(func(key string, chJSON []byte) bool {
+		var ch channel.Channel
+		err = json.Unmarshal(chJSON, &ch)
+		if err != nil {
+			return false
+		}
+		toReturn = append(toReturn, &ch)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toReturn, nil
+}
+
 func (ms *MemStore) GetObjectiveByChannelId(channelId types.Destination) (protocols.Objective, bool) {
 	// todo: locking
 	id, found := ms.channelToObjective.Load(channelId.String())
@@ -365,15 +431,21 @@ func (ms *MemStore) GetObjectiveByChannelId(channelId types.Destination) (protoc
 	return objective, err == nil
 }
 
+// populateChannelData attaches the stored channel data relevant to the given objective
+func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
+	return populateChannelData(obj, ms.getChannelById, ms.GetConsensusChannelById)
+}
+
 // populateChannelData fetches stored Channel data relevant to the given
 // objective and attaches it to the objective. The channel data is attached
 // in-place of the objectives existing channel pointers.
-func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
+// Channels are fetched with getChannel, and ledger channels with getConsensusChannel.
+func populateChannelData(obj protocols.Objective, getChannel func(types.Destination) (channel.Channel, error), getConsensusChannel func(types.Destination) (*consensus_channel.ConsensusChannel, error)) error {
 	id := obj.Id()
 
 	switch o := obj.(type) {
 	case *directfund.Objective:
-		ch, err := ms.getChannelById(o.C.Id)
+		ch, err := getChannel(o.C.Id)
 		if err != nil {
 			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
 		}
@@ -383,7 +455,34 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 		return nil
 	case *directdefund.Objective:
 
-		ch, err := ms.getChannelById(o.C.Id)
+		ch, err := getChannel(o.C.Id)
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
+		return nil
+	case *ledgertopup.Objective:
+		ch, err := getChannel(o.C.Id)
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
+		return nil
+	case *ledgerrecycle.Objective:
+		ch, err := getChannel(o.C.Id)
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
+		return nil
+	case *appupdate.Objective:
+		ch, err := getChannel(o.C.Id)
 		if err != nil {
 			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
 		}
@@ -392,7 +491,7 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 
 		return nil
 	case *virtualfund.Objective:
-		v, err := ms.getChannelById(o.V.Id)
+		v, err := getChannel(o.V.Id)
 		if err != nil {
 			return fmt.Errorf("error retrieving virtual channel data for objective %s: %w", id, err)
 		}
@@ -404,7 +503,7 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 			o.ToMyLeft.Channel != nil &&
 			o.ToMyLeft.Channel.Id != zeroAddress {
 
-			left, err := ms.GetConsensusChannelById(o.ToMyLeft.Channel.Id)
+			left, err := getConsensusChannel(o.ToMyLeft.Channel.Id)
 			if err != nil {
 				return fmt.Errorf("error retrieving left ledger channel data for objective %s: %w", id, err)
 			}
@@ -414,7 +513,7 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 		if o.ToMyRight != nil &&
 			o.ToMyRight.Channel != nil &&
 			o.ToMyRight.Channel.Id != zeroAddress {
-			right, err := ms.GetConsensusChannelById(o.ToMyRight.Channel.Id)
+			right, err := getConsensusChannel(o.ToMyRight.Channel.Id)
 			if err != nil {
 				return fmt.Errorf("error retrieving right ledger channel data for objective %s: %w", id, err)
 			}
@@ -423,7 +522,7 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 
 		return nil
 	case *virtualdefund.Objective:
-		v, err := ms.getChannelById(o.V.Id)
+		v, err := getChannel(o.V.Id)
 		if err != nil {
 			return fmt.Errorf("error retrieving virtual channel data for objective %s: %w", id, err)
 		}
@@ -434,7 +533,7 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 		if o.ToMyLeft != nil &&
 			o.ToMyLeft.Id != zeroAddress {
 
-			left, err := ms.GetConsensusChannelById(o.ToMyLeft.Id)
+			left, err := getConsensusChannel(o.ToMyLeft.Id)
 			if err != nil {
 				return fmt.Errorf("error retrieving left ledger channel data for objective %s: %w", id, err)
 			}
@@ -443,7 +542,7 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 
 		if o.ToMyRight != nil &&
 			o.ToMyRight.Id != zeroAddress {
-			right, err := ms.GetConsensusChannelById(o.ToMyRight.Id)
+			right, err := getConsensusChannel(o.ToMyRight.Id)
 			if err != nil {
 				return fmt.Errorf("error retrieving right ledger channel data for objective %s: %w", id, err)
 			}
@@ -468,6 +567,18 @@ func decodeObjective(id protocols.ObjectiveId, data []byte) (protocols.Objective
 		ddfo := directdefund.Objective{}
 		err := ddfo.UnmarshalJSON(data)
 		return &ddfo, err
+	case ledgertopup.IsLedgerTopUpObjective(id):
+		lto := ledgertopup.Objective{}
+		err := lto.UnmarshalJSON(data)
+		return &lto, err
+	case ledgerrecycle.IsLedgerRecycleObjective(id):
+		lro := ledgerrecycle.Objective{}
+		err := lro.UnmarshalJSON(data)
+		return &lro, err
+	case appupdate.IsAppUpdateObjective(id):
+		auo := appupdate.Objective{}
+		err := auo.UnmarshalJSON(data)
+		return &auo, err
 	case virtualfund.IsVirtualFundObjective(id):
 		vfo := virtualfund.Objective{}
 		err := vfo.UnmarshalJSON(data)
@@ -515,6 +626,138 @@ func (ms *MemStore) RemoveVoucherInfo(channelId types.Destination) error {
 	return nil
 }
 
+func (ms *MemStore) IsTransactionSubmitted(channelId types.Destination, txKey string) (bool, error) {
+	_, ok := ms.submittedTxs.Load(submittedTxKey(channelId, txKey))
+	return ok, nil
+}
+
+func (ms *MemStore) SetTransactionSubmitted(channelId types.Destination, txKey string) error {
+	ms.submittedTxs.Store(submittedTxKey(channelId, txKey), true)
+	return nil
+}
+
+func (ms *MemStore) UnsetTransactionSubmitted(channelId types.Destination, txKey string) error {
+	ms.submittedTxs.Delete(submittedTxKey(channelId, txKey))
+	return nil
+}
+
+func (ms *MemStore) RemoveSubmittedTransactions(channelId types.Destination) error {
+	prefix := submittedTxKey(channelId, "")
+	ms.submittedTxs.Range(func(key string, _ bool) bool {
+		if strings.HasPrefix(key, prefix) {
+			ms.submittedTxs.Delete(key)
+		}
+		return true
+	})
+	return nil
+}
+
+func (ms *MemStore) GetObjectiveDeadlines() (map[protocols.ObjectiveId]ObjectiveDeadline, error) {
+	deadlines := map[protocols.ObjectiveId]ObjectiveDeadline{}
+	ms.deadlines.Range(func(id string, d ObjectiveDeadline) bool {
+		deadlines[protocols.ObjectiveId(id)] = d
+		return true
+	})
+	return deadlines, nil
+}
+
+func (ms *MemStore) SetObjectiveDeadline(id protocols.ObjectiveId, d ObjectiveDeadline) error {
+	ms.deadlines.Store(string(id), d)
+	return nil
+}
+
+func (ms *MemStore) RemoveObjectiveDeadline(id protocols.ObjectiveId) error {
+	ms.deadlines.Delete(string(id))
+	return nil
+}
+
+func (ms *MemStore) GetChannelActivity() (map[types.Destination]time.Time, error) {
+	activity := map[types.Destination]time.Time{}
+	ms.channelActivity.Range(func(id string, lastActive time.Time) bool {
+		activity[types.Destination(common.HexToHash(id))] = lastActive
+		return true
+	})
+	return activity, nil
+}
+
+func (ms *MemStore) SetChannelActivity(channelId types.Destination, lastActive time.Time) error {
+	ms.channelActivity.Store(channelId.String(), lastActive)
+	return nil
+}
+
+func (ms *MemStore) RemoveChannelActivity(channelId types.Destination) error {
+	ms.channelActivity.Delete(channelId.String())
+	return nil
+}
+
+func (ms *MemStore) GetChannelChains() (map[types.Destination]string, error) {
+	chains := map[types.Destination]string{}
+	ms.channelChains.Range(func(id string, chainId string) bool {
+		chains[types.Destination(common.HexToHash(id))] = chainId
+		return true
+	})
+	return chains, nil
+}
+
+func (ms *MemStore) SetChannelChain(channelId types.Destination, chainId string) error {
+	ms.channelChains.Store(channelId.String(), chainId)
+	return nil
+}
+
+func (ms *MemStore) GetLastBlockNumSeenOnChain(chainId string) (uint64, error) {
+	blockNum, _ := ms.lastBlockOnChain.Load(chainId)
+	return blockNum, nil
+}
+
+func (ms *MemStore) SetLastBlockNumSeenOnChain(chainId string, blockNum uint64) error {
+	ms.lastBlockOnChain.Store(chainId, blockNum)
+	return nil
+}
+
+func (ms *MemStore) SetArchivedChannel(a ArchivedChannel) error {
+	jsonData, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	ms.archive.Store(a.ID.String(), jsonData)
+	return nil
+}
+
+func (ms *MemStore) GetArchivedChannel(id types.Destination) (ArchivedChannel, bool) {
+	data, ok := ms.archive.Load(id.String())
+	if !ok {
+		return ArchivedChannel{}, false
+	}
+	var a ArchivedChannel
+	if err := json.Unmarshal(data, &a); err != nil {
+		return ArchivedChannel{}, false
+	}
+	return a, true
+}
+
+func (ms *MemStore) GetArchivedChannels() ([]ArchivedChannel, error) {
+	toReturn := []ArchivedChannel{}
+	var unmarshErr error
+	ms.archive.Range(func(key string, data []byte) bool {
+		var a ArchivedChannel
+		unmarshErr = json.Unmarshal(data, &a)
+		if unmarshErr != nil {
+			return false
+		}
+		toReturn = append(toReturn, a)
+		return true
+	})
+	if unmarshErr != nil {
+		return []ArchivedChannel{}, unmarshErr
+	}
+	return toReturn, nil
+}
+
+// submittedTxKey returns the key under which a submitted transaction for the channel is stored
+func submittedTxKey(channelId types.Destination, txKey string) string {
+	return channelId.String() + ":" + txKey
+}
+
 // contains is a helper function which returns true if the given item is included in col
 func contains[T types.Destination | protocols.ObjectiveId](col []T, item T) bool {
 	for _, i := range col {
@@ -0,0 +1,393 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	ErrFollowerReadOnly = types.ConstError("store: a follower cannot be written to until it is promoted")
+	ErrFollowerPromoted = types.ConstError("store: the follower has been promoted")
+	ErrStreamBroken     = types.ConstError("store: the primary could not stream a write, and must be resynced")
+)
+
+// Primary is a Store which streams each of its writes to its followers.
+//
+// Every write is encoded as an incremental snapshot, in the format written by Export, holding only what the write changed.
+// The stream records which chain transactions have been submitted, so that a promoted follower does not submit them again.
+// Like a snapshot, it does not record the deadlines of stalled objectives, nor when payment channels were last active, nor the chains of channels on chains other than the default.
+//
+// If a write is applied to the store but cannot be streamed, the followers have missed it, so the stream is broken.
+// Every later write then fails with ErrStreamBroken, without being applied, until the primary is resynced.
+type Primary struct {
+	Store
+	mu     sync.Mutex // orders the writes in the stream as they are applied to the store
+	enc    *json.Encoder
+	broken error // why the stream broke, or nil if it has not
+}
+
+// NewPrimary returns a view of s which writes an incremental snapshot to w after each write to s.
+// Writes to the store block until w accepts the snapshot, so w should be buffered if followers may fall behind.
+func NewPrimary(s Store, w io.Writer) *Primary {
+	return &Primary{Store: s, enc: json.NewEncoder(w)}
+}
+
+// Resync exports the whole store to w, and streams later writes to w, repairing a broken stream.
+// A snapshot does not record deletions, so the follower reading w should start from an empty store.
+func (p *Primary) Resync(w io.Writer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := Export(p.Store, w); err != nil {
+		return fmt.Errorf("could not resync: %w", err)
+	}
+	p.enc = json.NewEncoder(w)
+	p.broken = nil
+	return nil
+}
+
+// write applies the write to the store and, if it succeeds, streams the incremental snapshot described by record
+func (p *Primary) write(apply func() error, record func(*snapshot) error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.broken != nil {
+		return fmt.Errorf("%w: %v", ErrStreamBroken, p.broken)
+	}
+	if err := apply(); err != nil {
+		return err
+	}
+	if err := p.stream(record); err != nil {
+		p.broken = err
+		return fmt.Errorf("the write was applied, but %w: %v", ErrStreamBroken, err)
+	}
+	return nil
+}
+
+// stream encodes the incremental snapshot described by record
+func (p *Primary) stream(record func(*snapshot) error) error {
+	lastBlockNumSeen, err := p.Store.GetLastBlockNumSeen()
+	if err != nil {
+		return err
+	}
+	snap := snapshot{
+		Version:          snapshotVersion,
+		Address:          *p.Store.GetAddress(),
+		LastBlockNumSeen: lastBlockNumSeen,
+	}
+	if err := record(&snap); err != nil {
+		return err
+	}
+	return p.enc.Encode(snap)
+}
+
+func (p *Primary) SetObjective(o protocols.Objective) error {
+	return p.write(func() error { return p.Store.SetObjective(o) }, func(snap *snapshot) error {
+		// The objective's channels are stored with it, so they are streamed with it
+		for _, rel := range o.Related() {
+			switch ch := rel.(type) {
+			case *channel.VirtualChannel:
+				snap.Channels = append(snap.Channels, &ch.Channel)
+			case *channel.Channel:
+				snap.Channels = append(snap.Channels, ch)
+			case *consensus_channel.ConsensusChannel:
+				snap.ConsensusChannels = append(snap.ConsensusChannels, ch)
+			default:
+				return fmt.Errorf("unexpected type: %T", rel)
+			}
+		}
+		so, err := newSnapshotObjective(o)
+		if err != nil {
+			return err
+		}
+		snap.Objectives = []snapshotObjective{so}
+		return nil
+	})
+}
+
+func (p *Primary) SetChannel(c *channel.Channel) error {
+	return p.write(func() error { return p.Store.SetChannel(c) }, func(snap *snapshot) error {
+		snap.Channels = []*channel.Channel{c}
+		return nil
+	})
+}
+
+func (p *Primary) DestroyChannel(id types.Destination) error {
+	return p.write(func() error { return p.Store.DestroyChannel(id) }, func(snap *snapshot) error {
+		snap.DestroyedChannels = []types.Destination{id}
+		return nil
+	})
+}
+
+func (p *Primary) ReleaseChannelFromOwnership(id types.Destination) error {
+	return p.write(func() error { return p.Store.ReleaseChannelFromOwnership(id) }, func(snap *snapshot) error {
+		snap.ReleasedChannels = []types.Destination{id}
+		return nil
+	})
+}
+
+func (p *Primary) SetLastBlockNumSeen(blockNum uint64) error {
+	// Every incremental snapshot carries the last block seen
+	return p.write(func() error { return p.Store.SetLastBlockNumSeen(blockNum) }, func(*snapshot) error { return nil })
+}
+
+func (p *Primary) SetConsensusChannel(c *consensus_channel.ConsensusChannel) error {
+	return p.write(func() error { return p.Store.SetConsensusChannel(c) }, func(snap *snapshot) error {
+		snap.ConsensusChannels = []*consensus_channel.ConsensusChannel{c}
+		return nil
+	})
+}
+
+func (p *Primary) DestroyConsensusChannel(id types.Destination) error {
+	return p.write(func() error { return p.Store.DestroyConsensusChannel(id) }, func(snap *snapshot) error {
+		snap.DestroyedConsensusChannels = []types.Destination{id}
+		return nil
+	})
+}
+
+func (p *Primary) SetTransactionSubmitted(channelId types.Destination, txKey string) error {
+	return p.write(func() error { return p.Store.SetTransactionSubmitted(channelId, txKey) }, func(snap *snapshot) error {
+		snap.SubmittedTransactions = []submittedTransaction{{ChannelId: channelId, TxKey: txKey}}
+		return nil
+	})
+}
+
+func (p *Primary) UnsetTransactionSubmitted(channelId types.Destination, txKey string) error {
+	return p.write(func() error { return p.Store.UnsetTransactionSubmitted(channelId, txKey) }, func(snap *snapshot) error {
+		snap.UnsubmittedTransactions = []submittedTransaction{{ChannelId: channelId, TxKey: txKey}}
+		return nil
+	})
+}
+
+func (p *Primary) RemoveSubmittedTransactions(channelId types.Destination) error {
+	return p.write(func() error { return p.Store.RemoveSubmittedTransactions(channelId) }, func(snap *snapshot) error {
+		snap.RemovedSubmittedTransactions = []types.Destination{channelId}
+		return nil
+	})
+}
+
+func (p *Primary) SetArchivedChannel(a ArchivedChannel) error {
+	return p.write(func() error { return p.Store.SetArchivedChannel(a) }, func(snap *snapshot) error {
+		snap.ArchivedChannels = []ArchivedChannel{a}
+		return nil
+	})
+}
+
+func (p *Primary) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
+	return p.write(func() error { return p.Store.SetVoucherInfo(channelId, v) }, func(snap *snapshot) error {
+		snap.Vouchers = map[types.Destination]payments.VoucherInfo{channelId: v}
+		return nil
+	})
+}
+
+func (p *Primary) RemoveVoucherInfo(channelId types.Destination) error {
+	return p.write(func() error { return p.Store.RemoveVoucherInfo(channelId) }, func(snap *snapshot) error {
+		snap.RemovedVouchers = []types.Destination{channelId}
+		return nil
+	})
+}
+
+// Follower is a read-only replica of a Primary's store, which mirrors the primary by applying the writes it streams.
+//
+// A follower answers queries, but rejects writes with ErrFollowerReadOnly, so it can serve reads without running an engine.
+// If the primary fails, the follower can be promoted to a writable store and handed to a new node.
+type Follower struct {
+	Store
+	mu       sync.Mutex // serialises applying snapshots with promotion
+	promoted bool
+}
+
+// NewFollower returns a follower which mirrors a primary into replica.
+// The replica must belong to the same address as the primary, and should start empty or hold a snapshot exported from the primary.
+func NewFollower(replica Store) *Follower {
+	return &Follower{Store: replica}
+}
+
+// Tail applies the incremental snapshots read from r, in order, until r is exhausted or the follower is promoted.
+// It returns ErrFollowerPromoted if the follower was promoted, and nil once r is exhausted.
+// A follower which is promoted while Tail is waiting for a snapshot only returns once r yields one or is closed.
+func (f *Follower) Tail(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	for {
+		var snap snapshot
+		if err := dec.Decode(&snap); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) {
+				return nil
+			}
+			return fmt.Errorf("could not decode snapshot: %w", err)
+		}
+		if err := f.apply(snap); err != nil {
+			return err
+		}
+	}
+}
+
+func (f *Follower) apply(snap snapshot) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.promoted {
+		return ErrFollowerPromoted
+	}
+	return importSnapshot(f.Store, snap)
+}
+
+// Promote stops the follower from mirroring its primary, and makes it writable.
+// The caller must make sure that the primary's node has stopped, since the two stores diverge from here on.
+func (f *Follower) Promote() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.promoted = true
+}
+
+// writable returns ErrFollowerReadOnly unless the follower has been promoted
+func (f *Follower) writable() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.promoted {
+		return ErrFollowerReadOnly
+	}
+	return nil
+}
+
+func (f *Follower) SetObjective(o protocols.Objective) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.SetObjective(o)
+}
+
+func (f *Follower) SetChannel(c *channel.Channel) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.SetChannel(c)
+}
+
+func (f *Follower) DestroyChannel(id types.Destination) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.DestroyChannel(id)
+}
+
+func (f *Follower) ReleaseChannelFromOwnership(id types.Destination) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.ReleaseChannelFromOwnership(id)
+}
+
+func (f *Follower) SetLastBlockNumSeen(blockNum uint64) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.SetLastBlockNumSeen(blockNum)
+}
+
+func (f *Follower) SetConsensusChannel(c *consensus_channel.ConsensusChannel) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.SetConsensusChannel(c)
+}
+
+func (f *Follower) DestroyConsensusChannel(id types.Destination) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.DestroyConsensusChannel(id)
+}
+
+func (f *Follower) SetTransactionSubmitted(channelId types.Destination, txKey string) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.SetTransactionSubmitted(channelId, txKey)
+}
+
+func (f *Follower) UnsetTransactionSubmitted(channelId types.Destination, txKey string) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.UnsetTransactionSubmitted(channelId, txKey)
+}
+
+func (f *Follower) RemoveSubmittedTransactions(channelId types.Destination) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.RemoveSubmittedTransactions(channelId)
+}
+
+func (f *Follower) SetObjectiveDeadline(id protocols.ObjectiveId, d ObjectiveDeadline) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.SetObjectiveDeadline(id, d)
+}
+
+func (f *Follower) RemoveObjectiveDeadline(id protocols.ObjectiveId) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.RemoveObjectiveDeadline(id)
+}
+
+func (f *Follower) SetChannelActivity(channelId types.Destination, lastActive time.Time) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.SetChannelActivity(channelId, lastActive)
+}
+
+func (f *Follower) RemoveChannelActivity(channelId types.Destination) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.RemoveChannelActivity(channelId)
+}
+
+func (f *Follower) SetChannelChain(channelId types.Destination, chainId string) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.SetChannelChain(channelId, chainId)
+}
+
+func (f *Follower) SetLastBlockNumSeenOnChain(chainId string, blockNum uint64) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.SetLastBlockNumSeenOnChain(chainId, blockNum)
+}
+
+func (f *Follower) SetArchivedChannel(a ArchivedChannel) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.SetArchivedChannel(a)
+}
+
+func (f *Follower) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.SetVoucherInfo(channelId, v)
+}
+
+func (f *Follower) RemoveVoucherInfo(channelId types.Destination) error {
+	if err := f.writable(); err != nil {
+		return err
+	}
+	return f.Store.RemoveVoucherInfo(channelId)
+}
@@ -0,0 +1,150 @@
+package store_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// eventually fails the test unless condition holds within a second
+func eventually(t *testing.T, condition func() bool, description string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s", description)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFollowerMirrorsPrimary(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	r, w := io.Pipe()
+	primary := store.NewPrimary(store.NewMemStore(sk), w)
+	follower := store.NewFollower(store.NewMemStore(sk))
+	tailed := make(chan error, 1)
+	go func() { tailed <- follower.Tail(r) }()
+
+	dfo := td.Objectives.Directfund.GenericDFO()
+	vfo := td.Objectives.Virtualfund.GenericVFO()
+	testhelpers.Ok(t, primary.SetObjective(&dfo))
+	testhelpers.Ok(t, primary.SetObjective(&vfo))
+	testhelpers.Ok(t, primary.SetLastBlockNumSeen(42))
+
+	eventually(t, func() bool {
+		last, err := follower.GetLastBlockNumSeen()
+		return err == nil && last == 42
+	}, "the follower to see the primary's last block")
+	for _, id := range []protocols.ObjectiveId{dfo.Id(), vfo.Id()} {
+		want, err := primary.GetObjectiveById(id)
+		testhelpers.Ok(t, err)
+		got, err := follower.GetObjectiveById(id)
+		testhelpers.Ok(t, err)
+		if diff := compareObjectives(got, want); diff != "" {
+			t.Errorf("expected the follower to mirror objective %s, but found:\n%s", id, diff)
+		}
+	}
+	ledgers, err := primary.GetAllConsensusChannels()
+	testhelpers.Ok(t, err)
+	mirrored, err := follower.GetAllConsensusChannels()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, len(ledgers), len(mirrored))
+
+	// Deletions are mirrored too
+	testhelpers.Ok(t, primary.DestroyChannel(dfo.C.Id))
+	eventually(t, func() bool {
+		_, ok := follower.GetChannelById(dfo.C.Id)
+		return !ok
+	}, "the follower to destroy the channel")
+
+	// So are the records of submitted transactions, so that a promoted follower does not submit them again
+	testhelpers.Ok(t, primary.SetTransactionSubmitted(vfo.V.Id, "deposit"))
+	testhelpers.Ok(t, primary.SetTransactionSubmitted(vfo.V.Id, "withdraw"))
+	testhelpers.Ok(t, primary.UnsetTransactionSubmitted(vfo.V.Id, "withdraw"))
+	eventually(t, func() bool {
+		deposited, err := follower.IsTransactionSubmitted(vfo.V.Id, "deposit")
+		testhelpers.Ok(t, err)
+		withdrawn, err := follower.IsTransactionSubmitted(vfo.V.Id, "withdraw")
+		testhelpers.Ok(t, err)
+		return deposited && !withdrawn
+	}, "the follower to record the submitted transaction")
+	testhelpers.Ok(t, primary.RemoveSubmittedTransactions(vfo.V.Id))
+	eventually(t, func() bool {
+		deposited, err := follower.IsTransactionSubmitted(vfo.V.Id, "deposit")
+		return err == nil && !deposited
+	}, "the follower to forget the submitted transactions")
+
+	// The follower only serves reads until it is promoted
+	if err := follower.SetLastBlockNumSeen(43); !errors.Is(err, store.ErrFollowerReadOnly) {
+		t.Fatalf("expected %v, got %v", store.ErrFollowerReadOnly, err)
+	}
+	follower.Promote()
+	testhelpers.Ok(t, follower.SetLastBlockNumSeen(43))
+
+	// The promoted follower stops mirroring the primary
+	testhelpers.Ok(t, primary.SetLastBlockNumSeen(44))
+	testhelpers.Equals(t, store.ErrFollowerPromoted, <-tailed)
+	last, err := follower.GetLastBlockNumSeen()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, uint64(43), last)
+}
+
+// failingWriter fails every write while failing is set
+type failingWriter struct {
+	io.Writer
+	failing bool
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.failing {
+		return 0, errors.New("follower unreachable")
+	}
+	return w.Writer.Write(p)
+}
+
+func TestPrimaryRefusesWritesOnceItsStreamBreaks(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	w := &failingWriter{Writer: io.Discard, failing: true}
+	primary := store.NewPrimary(store.NewMemStore(sk), w)
+
+	// The write the followers miss is applied, but reported, and later writes are refused
+	if err := primary.SetLastBlockNumSeen(42); !errors.Is(err, store.ErrStreamBroken) {
+		t.Fatalf("expected %v, got %v", store.ErrStreamBroken, err)
+	}
+	last, err := primary.GetLastBlockNumSeen()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, uint64(42), last)
+
+	w.failing = false
+	if err := primary.SetLastBlockNumSeen(43); !errors.Is(err, store.ErrStreamBroken) {
+		t.Fatalf("expected %v, got %v", store.ErrStreamBroken, err)
+	}
+	last, err = primary.GetLastBlockNumSeen()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, uint64(42), last)
+
+	// A fresh follower resynced from the primary mirrors everything, including later writes
+	r, resynced := io.Pipe()
+	follower := store.NewFollower(store.NewMemStore(sk))
+	tailed := make(chan error, 1)
+	go func() { tailed <- follower.Tail(r) }()
+	testhelpers.Ok(t, primary.Resync(resynced))
+	testhelpers.Ok(t, primary.SetLastBlockNumSeen(44))
+	eventually(t, func() bool {
+		last, err := follower.GetLastBlockNumSeen()
+		return err == nil && last == 44
+	}, "the resynced follower to see the primary's last block")
+
+	testhelpers.Ok(t, resynced.Close())
+	testhelpers.Ok(t, <-tailed)
+}
@@ -0,0 +1,228 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	ErrUnsupportedSnapshot = types.ConstError("store: unsupported snapshot version")
+	ErrSnapshotAddress     = types.ConstError("store: snapshot belongs to a different address")
+)
+
+// snapshotVersion is the version of the snapshot format written by Export
+const snapshotVersion = 1
+
+// snapshot is the portable form of a store's contents.
+//
+// A snapshot written by Export does not record which chain transactions have been submitted, so an objective which had submitted a transaction
+// may submit it again after being imported. The incremental snapshots which a Primary streams do record them.
+type snapshot struct {
+	Version           int
+	Address           types.Address
+	LastBlockNumSeen  uint64
+	Channels          []*channel.Channel
+	ConsensusChannels []*consensus_channel.ConsensusChannel
+	Objectives        []snapshotObjective
+	Vouchers          map[types.Destination]payments.VoucherInfo
+	ArchivedChannels  []ArchivedChannel `json:",omitempty"`
+
+	// Deletions are only recorded by the incremental snapshots which a Primary streams to its followers
+	DestroyedChannels          []types.Destination `json:",omitempty"`
+	DestroyedConsensusChannels []types.Destination `json:",omitempty"`
+	ReleasedChannels           []types.Destination `json:",omitempty"`
+	RemovedVouchers            []types.Destination `json:",omitempty"`
+
+	// As are the records of submitted chain transactions
+	SubmittedTransactions        []submittedTransaction `json:",omitempty"`
+	UnsubmittedTransactions      []submittedTransaction `json:",omitempty"`
+	RemovedSubmittedTransactions []types.Destination    `json:",omitempty"`
+}
+
+// submittedTransaction identifies a chain transaction submitted for a channel, as recorded by a SubmittedTransactionStore
+type submittedTransaction struct {
+	ChannelId types.Destination
+	TxKey     string
+}
+
+// snapshotObjective records an objective together with its id, which determines how the objective is decoded.
+// As in the store, the objective refers to its channels by id.
+type snapshotObjective struct {
+	Id        protocols.ObjectiveId
+	Objective json.RawMessage
+}
+
+func newSnapshotObjective(o protocols.Objective) (snapshotObjective, error) {
+	data, err := o.MarshalJSON()
+	if err != nil {
+		return snapshotObjective{}, err
+	}
+	return snapshotObjective{Id: o.Id(), Objective: data}, nil
+}
+
+// Export writes the objectives, channels, vouchers, archived channels and last block seen of the store to w, as versioned JSON.
+// The snapshot can be restored with Import, into any kind of store belonging to the same address.
+func Export(s ReadOnlyStore, w io.Writer) error {
+	lastBlockNumSeen, err := s.GetLastBlockNumSeen()
+	if err != nil {
+		return fmt.Errorf("could not export last block seen: %w", err)
+	}
+	channels, err := s.GetAllChannels()
+	if err != nil {
+		return fmt.Errorf("could not export channels: %w", err)
+	}
+	consensusChannels, err := s.GetAllConsensusChannels()
+	if err != nil {
+		return fmt.Errorf("could not export ledger channels: %w", err)
+	}
+	objectives, err := s.GetAllObjectives()
+	if err != nil {
+		return fmt.Errorf("could not export objectives: %w", err)
+	}
+	archived, err := s.GetArchivedChannels()
+	if err != nil {
+		return fmt.Errorf("could not export archived channels: %w", err)
+	}
+
+	snap := snapshot{
+		Version:           snapshotVersion,
+		Address:           *s.GetAddress(),
+		LastBlockNumSeen:  lastBlockNumSeen,
+		Channels:          channels,
+		ConsensusChannels: consensusChannels,
+		Objectives:        make([]snapshotObjective, len(objectives)),
+		Vouchers:          make(map[types.Destination]payments.VoucherInfo),
+		ArchivedChannels:  archived,
+	}
+	for i, o := range objectives {
+		so, err := newSnapshotObjective(o)
+		if err != nil {
+			return fmt.Errorf("could not export objective %s: %w", o.Id(), err)
+		}
+		snap.Objectives[i] = so
+	}
+	// Vouchers are kept for payment channels, which are among the stored channels
+	for _, c := range channels {
+		v, err := s.GetVoucherInfo(c.Id)
+		if errors.Is(err, ErrLoadVouchers) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not export vouchers for channel %s: %w", c.Id, err)
+		}
+		snap.Vouchers[c.Id] = *v
+	}
+
+	return json.NewEncoder(w).Encode(snap)
+}
+
+// Import restores a snapshot written by Export into s, overwriting any stored data for the same objectives and channels.
+// The snapshot must have been exported from a store belonging to the same address as s.
+func Import(s Store, r io.Reader) error {
+	var snap snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("could not decode snapshot: %w", err)
+	}
+	return importSnapshot(s, snap)
+}
+
+// importSnapshot restores the decoded snapshot into s, and then applies any deletions it records
+func importSnapshot(s Store, snap snapshot) error {
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedSnapshot, snap.Version)
+	}
+	if snap.Address != *s.GetAddress() {
+		return fmt.Errorf("%w: %s", ErrSnapshotAddress, snap.Address)
+	}
+
+	if err := s.SetLastBlockNumSeen(snap.LastBlockNumSeen); err != nil {
+		return fmt.Errorf("could not import last block seen: %w", err)
+	}
+	for _, c := range snap.Channels {
+		if err := s.SetChannel(c); err != nil {
+			return fmt.Errorf("could not import channel %s: %w", c.Id, err)
+		}
+	}
+	for _, c := range snap.ConsensusChannels {
+		if err := s.SetConsensusChannel(c); err != nil {
+			return fmt.Errorf("could not import ledger channel %s: %w", c.Id, err)
+		}
+	}
+	for channelId, v := range snap.Vouchers {
+		if err := s.SetVoucherInfo(channelId, v); err != nil {
+			return fmt.Errorf("could not import vouchers for channel %s: %w", channelId, err)
+		}
+	}
+	for _, a := range snap.ArchivedChannels {
+		if err := s.SetArchivedChannel(a); err != nil {
+			return fmt.Errorf("could not import archived channel %s: %w", a.ID, err)
+		}
+	}
+
+	// Objectives are imported last, since they are stored together with their channels
+	getChannel := func(id types.Destination) (channel.Channel, error) {
+		c, ok := s.GetChannelById(id)
+		if !ok {
+			return channel.Channel{}, ErrNoSuchChannel
+		}
+		return *c, nil
+	}
+	for _, so := range snap.Objectives {
+		o, err := decodeObjective(so.Id, so.Objective)
+		if err != nil {
+			return fmt.Errorf("could not import objective %s: %w", so.Id, err)
+		}
+		if err := populateChannelData(o, getChannel, s.GetConsensusChannelById); err != nil {
+			return fmt.Errorf("could not import objective %s: %w", so.Id, err)
+		}
+		if err := s.SetObjective(o); err != nil {
+			return fmt.Errorf("could not import objective %s: %w", so.Id, err)
+		}
+	}
+
+	for _, id := range snap.ReleasedChannels {
+		if err := s.ReleaseChannelFromOwnership(id); err != nil {
+			return fmt.Errorf("could not release channel %s: %w", id, err)
+		}
+	}
+	for _, id := range snap.RemovedVouchers {
+		if err := s.RemoveVoucherInfo(id); err != nil {
+			return fmt.Errorf("could not remove vouchers for channel %s: %w", id, err)
+		}
+	}
+	for _, id := range snap.DestroyedChannels {
+		if err := s.DestroyChannel(id); err != nil {
+			return fmt.Errorf("could not destroy channel %s: %w", id, err)
+		}
+	}
+	for _, id := range snap.DestroyedConsensusChannels {
+		if err := s.DestroyConsensusChannel(id); err != nil {
+			return fmt.Errorf("could not destroy ledger channel %s: %w", id, err)
+		}
+	}
+
+	for _, tx := range snap.SubmittedTransactions {
+		if err := s.SetTransactionSubmitted(tx.ChannelId, tx.TxKey); err != nil {
+			return fmt.Errorf("could not record transaction %s for channel %s: %w", tx.TxKey, tx.ChannelId, err)
+		}
+	}
+	for _, tx := range snap.UnsubmittedTransactions {
+		if err := s.UnsetTransactionSubmitted(tx.ChannelId, tx.TxKey); err != nil {
+			return fmt.Errorf("could not forget transaction %s for channel %s: %w", tx.TxKey, tx.ChannelId, err)
+		}
+	}
+	for _, id := range snap.RemovedSubmittedTransactions {
+		if err := s.RemoveSubmittedTransactions(id); err != nil {
+			return fmt.Errorf("could not forget the transactions for channel %s: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"log/slog"
 	"path/filepath"
+	"time"
 
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
@@ -16,32 +17,65 @@ import (
 )
 
 const (
-	ErrNoSuchObjective  = types.ConstError("store: no such objective")
-	ErrNoSuchChannel    = types.ConstError("store: failed to find required channel data")
-	ErrLoadVouchers     = types.ConstError("store: could not load vouchers")
-	lastBlockNumSeenKey = "lastBlockNumSeen"
+	ErrNoSuchObjective   = types.ConstError("store: no such objective")
+	ErrNoSuchChannel     = types.ConstError("store: failed to find required channel data")
+	ErrLoadVouchers      = types.ConstError("store: could not load vouchers")
+	ErrUnsupportedSchema = types.ConstError("store: unsupported schema version")
+	ErrCodecMismatch     = types.ConstError("store: the store was written with a different codec")
+	ErrUnknownCodec      = types.ConstError("store: unknown codec")
+	lastBlockNumSeenKey  = "lastBlockNumSeen"
 )
 
 // Store is responsible for persisting objectives, objective metadata, states, signatures, private keys and blockchain data
 type Store interface {
-	GetChannelSecretKey() *[]byte                                                 // Get a pointer to a secret key for signing channel updates
+	ReadOnlyStore
+	GetChannelSecretKey() *[]byte           // Get a pointer to a secret key for signing channel updates
+	SetObjective(protocols.Objective) error // Write an objective
+	SetChannel(*channel.Channel) error
+	DestroyChannel(id types.Destination) error
+	ReleaseChannelFromOwnership(types.Destination) error // Release channel from being owned by any objective. Releasing a channel which no objective owns is not an error.
+	SetLastBlockNumSeen(uint64) error
+
+	ConsensusChannelStore
+	SubmittedTransactionStore
+	ObjectiveDeadlineStore
+	ChannelActivityStore
+	MultiChainStore
+	ArchiveStore
+	payments.VoucherStore
+	io.Closer       // Close flushes the store's writes and releases its files. The node closes its store when it is closed.
+	Destroy() error // Close the store and delete its data, so that it does not outlive the store
+}
+
+// ReadOnlyStore is the subset of a Store which reads objectives and channels without modifying them.
+// It does not give access to the store's secret key.
+type ReadOnlyStore interface {
 	GetAddress() *types.Address                                                   // Get the (Ethereum) address associated with the ChannelSecretKey
 	GetObjectiveById(protocols.ObjectiveId) (protocols.Objective, error)          // Read an existing objective
 	GetObjectiveByChannelId(types.Destination) (obj protocols.Objective, ok bool) // Get the objective that currently owns the channel with the supplied ChannelId
-	SetObjective(protocols.Objective) error                                       // Write an objective
+	GetAllObjectives() ([]protocols.Objective, error)                             // Returns every objective whose channels are still stored
 	GetChannelsByIds(ids []types.Destination) ([]*channel.Channel, error)         // Returns a collection of channels with the given ids
 	GetChannelById(id types.Destination) (c *channel.Channel, ok bool)
+	GetAllChannels() ([]*channel.Channel, error)                                    // Returns every channel
 	GetChannelsByParticipant(participant types.Address) ([]*channel.Channel, error) // Returns any channels that includes the given participant
-	SetChannel(*channel.Channel) error
-	DestroyChannel(id types.Destination) error
-	GetChannelsByAppDefinition(appDef types.Address) ([]*channel.Channel, error) // Returns any channels that includes the given app definition
-	ReleaseChannelFromOwnership(types.Destination) error                         // Release channel from being owned by any objective
+	GetChannelsByAppDefinition(appDef types.Address) ([]*channel.Channel, error)    // Returns any channels that includes the given app definition
 	GetLastBlockNumSeen() (uint64, error)
-	SetLastBlockNumSeen(uint64) error
+	GetAllConsensusChannels() ([]*consensus_channel.ConsensusChannel, error)
+	GetConsensusChannel(counterparty types.Address) (channel *consensus_channel.ConsensusChannel, ok bool)
+	GetConsensusChannelById(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error)
+	GetVoucherInfo(channelId types.Destination) (v *payments.VoucherInfo, err error)
+	GetArchivedChannel(id types.Destination) (a ArchivedChannel, ok bool)
+	GetArchivedChannels() ([]ArchivedChannel, error)
+}
 
-	ConsensusChannelStore
-	payments.VoucherStore
-	io.Closer
+// readOnlyStore hides the methods of a Store which are not part of ReadOnlyStore, so that they cannot be reached with a type assertion
+type readOnlyStore struct {
+	ReadOnlyStore
+}
+
+// ReadOnly returns a view of the store which cannot be used to modify it
+func ReadOnly(s Store) ReadOnlyStore {
+	return readOnlyStore{s}
 }
 
 type ConsensusChannelStore interface {
@@ -52,10 +86,50 @@ type ConsensusChannelStore interface {
 	DestroyConsensusChannel(id types.Destination) error
 }
 
+// SubmittedTransactionStore records which chain transactions have been submitted for each channel, so that they are not submitted twice
+type SubmittedTransactionStore interface {
+	IsTransactionSubmitted(channelId types.Destination, txKey string) (bool, error)
+	SetTransactionSubmitted(channelId types.Destination, txKey string) error
+	UnsetTransactionSubmitted(channelId types.Destination, txKey string) error // Forget a transaction whose submission failed, so that it may be submitted again
+	RemoveSubmittedTransactions(channelId types.Destination) error             // Forget every submitted transaction for the channel
+}
+
+// ObjectiveDeadline records the step an approved objective is waiting on, when it must have moved on from it,
+// and whether its channels have been challenged on chain since
+type ObjectiveDeadline struct {
+	WaitingFor protocols.WaitingFor
+	Deadline   time.Time
+	Escalated  bool
+}
+
+// ObjectiveDeadlineStore records the deadlines of stalled objectives, so that a restarted node escalates them on time
+type ObjectiveDeadlineStore interface {
+	GetObjectiveDeadlines() (map[protocols.ObjectiveId]ObjectiveDeadline, error)
+	SetObjectiveDeadline(id protocols.ObjectiveId, d ObjectiveDeadline) error
+	RemoveObjectiveDeadline(id protocols.ObjectiveId) error // Forget the objective's deadline. Forgetting a deadline which is not stored has no effect
+}
+
+// ChannelActivityStore records when payment channels were last active, so that a restarted node still closes the channels which fell idle
+type ChannelActivityStore interface {
+	GetChannelActivity() (map[types.Destination]time.Time, error)
+	SetChannelActivity(channelId types.Destination, lastActive time.Time) error
+	RemoveChannelActivity(channelId types.Destination) error // Forget when the channel was last active. Forgetting a channel which is not stored has no effect
+}
+
+// MultiChainStore records which chain each channel is on, and the last block seen on each chain, for nodes which operate channels on several chains.
+// Channels with no recorded chain are on the node's default chain, whose last block seen is the one recorded by SetLastBlockNumSeen.
+type MultiChainStore interface {
+	GetChannelChains() (map[types.Destination]string, error) // Returns the id of the chain of each channel with a recorded chain
+	SetChannelChain(channelId types.Destination, chainId string) error
+	GetLastBlockNumSeenOnChain(chainId string) (uint64, error)
+	SetLastBlockNumSeenOnChain(chainId string, blockNum uint64) error
+}
+
 type StoreOpts struct {
 	PkBytes            []byte
 	UseDurableStore    bool
 	DurableStoreFolder string
+	DurableStoreCodec  Codec // the format the durable store persists values in. JSONCodec is used if it is unset
 	BuntDbConfig       buntdb.Config
 }
 
@@ -72,7 +146,11 @@ func NewStore(options StoreOpts) (Store, error) {
 		dataFolder := filepath.Join(options.DurableStoreFolder, me.String())
 
 		slog.Info("Initialising durable store...", "dataFolder", dataFolder)
-		ourStore, err = NewDurableStore(options.PkBytes, dataFolder, buntdb.Config{})
+		codec := options.DurableStoreCodec
+		if codec == nil {
+			codec = JSONCodec{}
+		}
+		ourStore, err = NewDurableStoreWithCodec(options.PkBytes, dataFolder, buntdb.Config{}, codec)
 		if err != nil {
 			return nil, err
 		}
@@ -1,8 +1,12 @@
 package store_test
 
 import (
+	"bytes"
+	"errors"
 	"math"
 	"math/big"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -116,6 +120,29 @@ func TestGetObjectiveByChannelId(t *testing.T) {
 	}
 }
 
+func TestReleasingAnUnownedChannelIsNotAnError(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	durableStore, err := store.NewDurableStore(sk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.DestroyOnCleanup(t, durableStore)
+
+	for _, s := range []store.Store{durableStore, store.NewMemStore(sk)} {
+		dfo := td.Objectives.Directfund.GenericDFO()
+		dfo.Status = protocols.Approved
+		testhelpers.Ok(t, s.SetObjective(&dfo))
+
+		testhelpers.Ok(t, s.ReleaseChannelFromOwnership(dfo.C.Id))
+		_, owned := s.GetObjectiveByChannelId(dfo.C.Id)
+		testhelpers.Assert(t, !owned, "expected the channel to be released")
+		testhelpers.Ok(t, s.ReleaseChannelFromOwnership(dfo.C.Id))
+	}
+}
+
 func TestGetChannelSecretKey(t *testing.T) {
 	// from state/test-fixtures.go
 	sk := common.Hex2Bytes("caab404f975b4620747174a75f08d98b4e5a7053b691b41bcfc0d839d48b7634")
@@ -242,6 +269,7 @@ func TestGetLastBlockNumSeenDurableStore(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	testhelpers.DestroyOnCleanup(t, durableStore)
 
 	want := uint64(15)
 	_ = durableStore.SetLastBlockNumSeen(want)
@@ -265,6 +293,7 @@ func TestBigNumberStorage(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	testhelpers.DestroyOnCleanup(t, durableStore)
 	memStore := store.NewMemStore(pk)
 
 	for _, store := range []store.Store{durableStore, memStore} {
@@ -304,3 +333,215 @@ func TestBigNumberStorage(t *testing.T) {
 		}
 	}
 }
+
+func TestSubmittedTransactionStore(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.DestroyOnCleanup(t, durableStore)
+	memStore := store.NewMemStore(pk)
+
+	channelId, otherChannelId := types.Destination{1}, types.Destination{2}
+
+	for _, s := range []store.Store{durableStore, memStore} {
+		testhelpers.Ok(t, s.SetTransactionSubmitted(channelId, "deposit"))
+		testhelpers.Ok(t, s.SetTransactionSubmitted(otherChannelId, "deposit"))
+
+		submitted, err := s.IsTransactionSubmitted(channelId, "deposit")
+		testhelpers.Ok(t, err)
+		testhelpers.Assert(t, submitted, "expected deposit to be submitted")
+
+		submitted, err = s.IsTransactionSubmitted(channelId, "withdrawAll")
+		testhelpers.Ok(t, err)
+		testhelpers.Assert(t, !submitted, "expected withdrawAll not to be submitted")
+
+		testhelpers.Ok(t, s.RemoveSubmittedTransactions(channelId))
+
+		submitted, err = s.IsTransactionSubmitted(channelId, "deposit")
+		testhelpers.Ok(t, err)
+		testhelpers.Assert(t, !submitted, "expected deposit to be forgotten")
+
+		submitted, err = s.IsTransactionSubmitted(otherChannelId, "deposit")
+		testhelpers.Ok(t, err)
+		testhelpers.Assert(t, submitted, "expected deposit for another channel to be kept")
+	}
+}
+
+func TestGetAllObjectivesAndChannels(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testhelpers.DestroyOnCleanup(t, durableStore)
+	memStore := store.NewMemStore(pk)
+
+	for _, s := range []store.Store{durableStore, memStore} {
+		dfo := td.Objectives.Directfund.GenericDFO()
+		vfo := td.Objectives.Virtualfund.GenericVFO()
+		testhelpers.Ok(t, s.SetObjective(&dfo))
+		testhelpers.Ok(t, s.SetObjective(&vfo))
+
+		objectives, err := s.GetAllObjectives()
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 2, len(objectives))
+
+		channels, err := s.GetAllChannels()
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 2, len(channels))
+
+		// Objectives whose channels have been destroyed are omitted
+		testhelpers.Ok(t, s.DestroyChannel(dfo.C.Id))
+		objectives, err = s.GetAllObjectives()
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 1, len(objectives))
+		testhelpers.Equals(t, vfo.Id(), objectives[0].Id())
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	ms := store.NewMemStore(sk)
+
+	ro := store.ReadOnly(ms)
+	if _, ok := ro.(store.Store); ok {
+		t.Error("expected a read-only store not to be usable as a store")
+	}
+	testhelpers.Equals(t, ms.GetAddress(), ro.GetAddress())
+}
+
+func TestImportRejectsForeignSnapshots(t *testing.T) {
+	var snapshot bytes.Buffer
+	if err := store.Export(store.NewMemStore(ta.Alice.PrivateKey), &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Import(store.NewMemStore(ta.Bob.PrivateKey), bytes.NewReader(snapshot.Bytes())); !errors.Is(err, store.ErrSnapshotAddress) {
+		t.Errorf("expected a snapshot of another address to be rejected with %v, got %v", store.ErrSnapshotAddress, err)
+	}
+
+	future := strings.Replace(snapshot.String(), `"Version":1`, `"Version":2`, 1)
+	if err := store.Import(store.NewMemStore(ta.Alice.PrivateKey), strings.NewReader(future)); !errors.Is(err, store.ErrUnsupportedSnapshot) {
+		t.Errorf("expected a snapshot of an unknown version to be rejected with %v, got %v", store.ErrUnsupportedSnapshot, err)
+	}
+}
+
+func TestDestroyDurableStore(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	aliceStore, err := store.NewDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	bobStore, err := store.NewDurableStore(ta.Bob.PrivateKey, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	testhelpers.DestroyOnCleanup(t, bobStore)
+	testhelpers.Ok(t, bobStore.SetLastBlockNumSeen(15))
+
+	// Destroying a store which has already been closed is safe
+	testhelpers.Ok(t, aliceStore.Close())
+	testhelpers.Ok(t, aliceStore.Destroy())
+	testhelpers.Ok(t, aliceStore.Destroy())
+
+	files, err := os.ReadDir(dataFolder)
+	testhelpers.Ok(t, err)
+	for _, f := range files {
+		testhelpers.Assert(t, !strings.HasSuffix(f.Name(), "_"+ta.Alice.Address().String()[2:7]+".db"), "expected %s to be deleted", f.Name())
+	}
+	testhelpers.Assert(t, len(files) > 0, "expected Bob's store to be kept")
+
+	got, err := bobStore.GetLastBlockNumSeen()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, uint64(15), got)
+}
+
+func TestPartiallySignedObjectiveSurvivesRestart(t *testing.T) {
+	for _, codec := range []store.Codec{store.JSONCodec{}, reversingCodec{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			dataFolder := t.TempDir()
+			s, err := store.NewDurableStoreWithCodec(ta.Alice.PrivateKey, dataFolder, buntdb.Config{}, codec)
+			testhelpers.Ok(t, err)
+
+			// Alice signs the prefund state, so the objective has collected one of its two signatures
+			dfo := td.Objectives.Directfund.GenericDFO()
+			o, se, waitingFor, err := dfo.Approve().Crank(&ta.Alice.PrivateKey)
+			testhelpers.Ok(t, err)
+			testhelpers.Equals(t, directfund.WaitingForCompletePrefund, waitingFor)
+			testhelpers.Equals(t, 1, len(se.MessagesToSend))
+			testhelpers.Ok(t, s.SetObjective(o))
+			testhelpers.Ok(t, s.Close())
+
+			s, err = store.NewDurableStoreWithCodec(ta.Alice.PrivateKey, dataFolder, buntdb.Config{}, codec)
+			testhelpers.Ok(t, err)
+			testhelpers.DestroyOnCleanup(t, s)
+			recovered, err := s.GetObjectiveById(dfo.Id())
+			testhelpers.Ok(t, err)
+			if diff := compareObjectives(recovered, o); diff != "" {
+				t.Fatalf("expected no diff between set and recovered objective, but found:\n%s", diff)
+			}
+
+			prefund := recovered.(*directfund.Objective).C.SignedPreFundState()
+			testhelpers.Assert(t, prefund.HasSignatureForParticipant(0), "expected the recovered prefund to hold alice's signature")
+			testhelpers.Assert(t, !prefund.HasSignatureForParticipant(1), "expected the recovered prefund not to hold bob's signature")
+
+			// The recovered objective does not sign or send the prefund again, and only waits for bob's signature
+			recovered, se, waitingFor, err = recovered.Crank(&ta.Alice.PrivateKey)
+			testhelpers.Ok(t, err)
+			testhelpers.Equals(t, directfund.WaitingForCompletePrefund, waitingFor)
+			testhelpers.Equals(t, 0, len(se.MessagesToSend))
+
+			bobSig, err := prefund.State().Sign(ta.Bob.PrivateKey)
+			testhelpers.Ok(t, err)
+			fromBob := state.NewSignedState(prefund.State())
+			testhelpers.Ok(t, fromBob.AddSignature(bobSig))
+			p, err := protocols.CreateObjectivePayload(dfo.Id(), directfund.SignedStatePayload, fromBob)
+			testhelpers.Ok(t, err)
+			recovered, err = recovered.Update(p)
+			testhelpers.Ok(t, err)
+			_, _, waitingFor, err = recovered.Crank(&ta.Alice.PrivateKey)
+			testhelpers.Ok(t, err)
+			if waitingFor == directfund.WaitingForCompletePrefund {
+				t.Fatalf("expected the prefund to be complete once bob has signed")
+			}
+		})
+	}
+}
+
+func TestObjectiveIsDurableAfterClose(t *testing.T) {
+	// The store syncs at most once a second, so the writes below are only synced by Close
+	config := buntdb.Config{SyncPolicy: buntdb.EverySecond}
+	dataFolder := t.TempDir()
+	s, err := store.NewDurableStore(ta.Alice.PrivateKey, dataFolder, config)
+	testhelpers.Ok(t, err)
+
+	dfo := td.Objectives.Directfund.GenericDFO()
+	testhelpers.Ok(t, s.SetObjective(&dfo))
+	testhelpers.Ok(t, s.SetLastBlockNumSeen(42))
+	testhelpers.Ok(t, s.Close())
+	if err := s.SetLastBlockNumSeen(43); err == nil {
+		t.Fatal("expected a closed store to refuse writes")
+	}
+
+	// The store is reopened from the same folder
+	s, err = store.NewDurableStore(ta.Alice.PrivateKey, dataFolder, config)
+	testhelpers.Ok(t, err)
+	testhelpers.DestroyOnCleanup(t, s)
+
+	got, err := s.GetObjectiveById(dfo.Id())
+	testhelpers.Ok(t, err)
+	if diff := compareObjectives(got, &dfo); diff != "" {
+		t.Errorf("expected the reopened store to hold the objective, but found:\n%s", diff)
+	}
+	if _, ok := s.GetChannelById(dfo.C.Id); !ok {
+		t.Error("expected the reopened store to hold the objective's channel")
+	}
+	last, err := s.GetLastBlockNumSeen()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, uint64(42), last)
+}
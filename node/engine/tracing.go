@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer which records the spans of objectives
+const TracerName = "github.com/statechannels/go-nitro/node/engine"
+
+// The names of the spans and span events recorded for each objective
+const (
+	ObjectiveSpanName             = "objective"
+	CrankSpanName                 = "crank"
+	MessageSentEventName          = "message sent"
+	MessageReceivedEventName      = "message received"
+	TransactionSubmittedEventName = "transaction submitted"
+)
+
+// The attributes of the spans recorded for each objective
+const (
+	ObjectiveIdAttribute = attribute.Key("nitro.objective_id")
+	WaitingForAttribute  = attribute.Key("nitro.waiting_for")
+	StatusAttribute      = attribute.Key("nitro.status")
+	PeerAttribute        = attribute.Key("nitro.peer")
+)
+
+// objectiveTracer records a span for the lifecycle of each objective, from when it is spawned until it completes or is rejected.
+// Each crank of the objective is recorded as a child span. The W3C trace context of an objective's span is sent along with its messages,
+// so that the span of the same objective at a peer is recorded as a child of ours, and a single trace follows the objective across nodes.
+// It is only used from the engine's run loop.
+type objectiveTracer struct {
+	tracer trace.Tracer
+	spans  map[protocols.ObjectiveId]trace.Span
+}
+
+// newObjectiveTracer returns an objectiveTracer whose spans are created with the provider. If the provider is nil, spans are discarded.
+func newObjectiveTracer(provider trace.TracerProvider) *objectiveTracer {
+	if provider == nil {
+		provider = trace.NewNoopTracerProvider()
+	}
+	return &objectiveTracer{tracer: provider.Tracer(TracerName), spans: make(map[protocols.ObjectiveId]trace.Span)}
+}
+
+// startObjective starts the span of the objective, unless it already has one.
+// If traceparent is the trace context of the objective's span at a peer, the span is a child of the peer's span.
+func (ot *objectiveTracer) startObjective(id protocols.ObjectiveId, traceparent string) {
+	if _, ok := ot.spans[id]; ok {
+		return
+	}
+	ctx := context.Background()
+	if traceparent != "" {
+		ctx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": traceparent})
+	}
+	_, span := ot.tracer.Start(ctx, ObjectiveSpanName, trace.WithAttributes(ObjectiveIdAttribute.String(string(id))))
+	ot.spans[id] = span
+}
+
+// startCrank starts a span for a crank of the objective, which is a child of the objective's span if the objective has one.
+// The caller must end the returned span.
+func (ot *objectiveTracer) startCrank(id protocols.ObjectiveId) trace.Span {
+	ctx := context.Background()
+	if span, ok := ot.spans[id]; ok {
+		ctx = trace.ContextWithSpan(ctx, span)
+	}
+	_, span := ot.tracer.Start(ctx, CrankSpanName, trace.WithAttributes(ObjectiveIdAttribute.String(string(id))))
+	return span
+}
+
+// addEvent adds the event to the span of the objective, if it has one
+func (ot *objectiveTracer) addEvent(id protocols.ObjectiveId, name string, attributes ...attribute.KeyValue) {
+	if span, ok := ot.spans[id]; ok {
+		span.AddEvent(name, trace.WithAttributes(attributes...))
+	}
+}
+
+// recordSideEffects adds an event to the objective's span for each message and transaction in the side effects,
+// and attaches the trace context of the objective's span to each message.
+func (ot *objectiveTracer) recordSideEffects(id protocols.ObjectiveId, sideEffects *protocols.SideEffects) {
+	span, ok := ot.spans[id]
+	if !ok {
+		return
+	}
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(trace.ContextWithSpan(context.Background(), span), carrier)
+	traceparent := carrier.Get("traceparent")
+
+	recordMessage := func(message *protocols.Message) {
+		span.AddEvent(MessageSentEventName, trace.WithAttributes(PeerAttribute.String(message.To.String())))
+		if traceparent == "" {
+			return
+		}
+		if message.TraceContext == nil {
+			message.TraceContext = make(map[protocols.ObjectiveId]string)
+		}
+		message.TraceContext[id] = traceparent
+	}
+	recordTransaction := func(tx protocols.ChainTransaction) {
+		span.AddEvent(TransactionSubmittedEventName, trace.WithAttributes(attribute.String("nitro.transaction_type", fmt.Sprintf("%T", tx))))
+	}
+
+	for i := range sideEffects.MessagesToSend {
+		recordMessage(&sideEffects.MessagesToSend[i])
+	}
+	for _, tx := range sideEffects.TransactionsToSubmit {
+		recordTransaction(tx)
+	}
+	for _, effect := range sideEffects.Sequence {
+		switch {
+		case effect.Message != nil:
+			recordMessage(effect.Message)
+		case effect.Transaction != nil:
+			recordTransaction(effect.Transaction)
+		}
+	}
+}
+
+// completeObjective ends the span of the objective, which has completed
+func (ot *objectiveTracer) completeObjective(id protocols.ObjectiveId) {
+	ot.endObjective(id, "completed")
+}
+
+// rejectObjective ends the span of the objective, which has been rejected
+func (ot *objectiveTracer) rejectObjective(id protocols.ObjectiveId) {
+	if span, ok := ot.spans[id]; ok {
+		span.SetStatus(codes.Error, "objective rejected")
+	}
+	ot.endObjective(id, "rejected")
+}
+
+// endObjective ends the span of the objective, recording the status it finished with
+func (ot *objectiveTracer) endObjective(id protocols.ObjectiveId, status string) {
+	span, ok := ot.spans[id]
+	if !ok {
+		return
+	}
+	span.SetAttributes(StatusAttribute.String(status))
+	span.End()
+	delete(ot.spans, id)
+}
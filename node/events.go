@@ -0,0 +1,177 @@
+package node
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// OverflowPolicy decides what happens to an event which the node reports on a full event chan
+type OverflowPolicy int
+
+const (
+	// Block waits until the consumer makes room for the event. No event is lost, but the engine stops handling
+	// messages, chain events and API requests while it waits, and the node cannot be closed, so a consumer must keep reading.
+	Block OverflowPolicy = iota
+	// DropOldest discards the oldest unread event to make room for the new one, and counts it in DroppedEvents.
+	// The engine never waits for a consumer, so a slow or absent consumer misses events rather than stalling the node.
+	DropOldest
+)
+
+// EventChannelConfig configures one of the chans the node reports events on
+type EventChannelConfig struct {
+	Size     int // how many unread events the chan holds
+	Overflow OverflowPolicy
+}
+
+// EventsConfig configures each of the chans the node reports events on
+type EventsConfig struct {
+	CompletedObjectives       EventChannelConfig
+	CompletedObjectiveDetails EventChannelConfig
+	ObjectiveProgress         EventChannelConfig
+	RejectedObjectives        EventChannelConfig
+	FailedObjectives          EventChannelConfig
+	ReceivedVouchers          EventChannelConfig
+}
+
+// DefaultEventsConfig returns the configuration a node's event chans have unless ConfigureEvents is called.
+// Failed objectives and received vouchers are never dropped, since a consumer which missed them could not recover them, so their chans block when full.
+// The other chans drop their oldest events when full. Progress and vouchers, which are reported most often, are buffered the most.
+func DefaultEventsConfig() EventsConfig {
+	return EventsConfig{
+		CompletedObjectives:       EventChannelConfig{Size: 100, Overflow: DropOldest},
+		CompletedObjectiveDetails: EventChannelConfig{Size: 100, Overflow: DropOldest},
+		ObjectiveProgress:         EventChannelConfig{Size: 1000, Overflow: DropOldest},
+		RejectedObjectives:        EventChannelConfig{Size: 100, Overflow: DropOldest},
+		FailedObjectives:          EventChannelConfig{Size: 100, Overflow: Block},
+		ReceivedVouchers:          EventChannelConfig{Size: 1000, Overflow: Block},
+	}
+}
+
+// DroppedEvents counts the events each of the node's event chans has dropped because it was full
+type DroppedEvents struct {
+	CompletedObjectives       uint64
+	CompletedObjectiveDetails uint64
+	ObjectiveProgress         uint64
+	RejectedObjectives        uint64
+	FailedObjectives          uint64
+	ReceivedVouchers          uint64
+}
+
+// events holds the chans the node reports events on
+type events struct {
+	completedObjectives       *eventChannel[protocols.ObjectiveId]
+	completedObjectiveDetails *eventChannel[query.CompletedObjectiveInfo]
+	objectiveProgress         *eventChannel[engine.ObjectiveProgressEvent]
+	rejectedObjectives        *eventChannel[engine.ObjectiveRejection]
+	failedObjectives          *eventChannel[protocols.ObjectiveId]
+	receivedVouchers          *eventChannel[payments.Voucher]
+}
+
+func newEvents(config EventsConfig) *events {
+	return &events{
+		completedObjectives:       newEventChannel[protocols.ObjectiveId](config.CompletedObjectives),
+		completedObjectiveDetails: newEventChannel[query.CompletedObjectiveInfo](config.CompletedObjectiveDetails),
+		objectiveProgress:         newEventChannel[engine.ObjectiveProgressEvent](config.ObjectiveProgress),
+		rejectedObjectives:        newEventChannel[engine.ObjectiveRejection](config.RejectedObjectives),
+		failedObjectives:          newEventChannel[protocols.ObjectiveId](config.FailedObjectives),
+		receivedVouchers:          newEventChannel[payments.Voucher](config.ReceivedVouchers),
+	}
+}
+
+func (e *events) configure(config EventsConfig) {
+	e.completedObjectives.configure(config.CompletedObjectives)
+	e.completedObjectiveDetails.configure(config.CompletedObjectiveDetails)
+	e.objectiveProgress.configure(config.ObjectiveProgress)
+	e.rejectedObjectives.configure(config.RejectedObjectives)
+	e.failedObjectives.configure(config.FailedObjectives)
+	e.receivedVouchers.configure(config.ReceivedVouchers)
+}
+
+func (e *events) dropped() DroppedEvents {
+	return DroppedEvents{
+		CompletedObjectives:       e.completedObjectives.dropped.Load(),
+		CompletedObjectiveDetails: e.completedObjectiveDetails.dropped.Load(),
+		ObjectiveProgress:         e.objectiveProgress.dropped.Load(),
+		RejectedObjectives:        e.rejectedObjectives.dropped.Load(),
+		FailedObjectives:          e.failedObjectives.dropped.Load(),
+		ReceivedVouchers:          e.receivedVouchers.dropped.Load(),
+	}
+}
+
+func (e *events) close() {
+	e.completedObjectives.close()
+	e.completedObjectiveDetails.close()
+	e.objectiveProgress.close()
+	e.rejectedObjectives.close()
+	e.failedObjectives.close()
+	e.receivedVouchers.close()
+}
+
+// eventChannel is a bounded chan of events, which either drops its oldest event or blocks its sender when it is full.
+// Events are sent only by the engine's event handler, so there is a single sender.
+type eventChannel[T any] struct {
+	mu       sync.RWMutex // guards c and overflow, which are replaced by configure
+	c        chan T
+	overflow OverflowPolicy
+	dropped  atomic.Uint64
+}
+
+func newEventChannel[T any](config EventChannelConfig) *eventChannel[T] {
+	return &eventChannel[T]{c: make(chan T, config.Size), overflow: config.Overflow}
+}
+
+// configure replaces the chan with one configured by config
+func (ec *eventChannel[T]) configure(config EventChannelConfig) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.c = make(chan T, config.Size)
+	ec.overflow = config.Overflow
+}
+
+func (ec *eventChannel[T]) channel() <-chan T {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	return ec.c
+}
+
+func (ec *eventChannel[T]) send(event T) {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+	if ec.overflow == Block {
+		ec.c <- event
+		return
+	}
+	// An unbuffered chan has no oldest event to drop, so the new one is dropped unless the consumer is waiting for it
+	if cap(ec.c) == 0 {
+		select {
+		case ec.c <- event:
+		default:
+			ec.dropped.Add(1)
+		}
+		return
+	}
+	for {
+		select {
+		case ec.c <- event:
+			return
+		default:
+		}
+		// The chan is full, so the oldest event makes room, unless the consumer has just read it
+		select {
+		case <-ec.c:
+			ec.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+func (ec *eventChannel[T]) close() {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	close(ec.c)
+}
@@ -6,8 +6,10 @@ import (
 	"log/slog"
 	"math/big"
 	"runtime/debug"
+	"sync"
 	"time"
 
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/node/engine"
@@ -18,12 +20,16 @@ import (
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/appupdate"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgerrecycle"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/rand"
 	"github.com/statechannels/go-nitro/types"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Node provides the interface for the consuming application
@@ -32,17 +38,38 @@ type Node struct {
 	Address         *types.Address
 	channelNotifier *notifier.ChannelNotifier
 
-	completedObjectivesForRPC chan protocols.ObjectiveId // This is only used by the RPC server
-	completedObjectives       *safesync.Map[chan struct{}]
-	failedObjectives          chan protocols.ObjectiveId
-	receivedVouchers          chan payments.Voucher
-	chainId                   *big.Int
-	store                     store.Store
-	vm                        *payments.VoucherManager
+	events              *events // the chans events are reported on, such as completed objectives and received vouchers
+	completedObjectives *safesync.Map[chan struct{}]
+	waitingFor          *safesync.Map[protocols.WaitingFor] // what each running objective was waiting for when it was last cranked
+	chainId             *big.Int
+	chainservice        chainservice.ChainService
+	msg                 messageservice.MessageService
+	store               store.Store
+	vm                  *payments.VoucherManager
+	rng                 rand.Generator // generates the nonces of new channels and objectives
+	nextChannelNonce    *reservedNonce // the nonce of the next channel, once a simulation has drawn it
+	closer              *closeOnce
+}
+
+// closeOnce runs a node's shutdown once, and reports its error to every call of Close
+type closeOnce struct {
+	once sync.Once
+	err  error
+}
+
+// reservedNonce holds a channel nonce which has been drawn but not yet used
+type reservedNonce struct {
+	mu       sync.Mutex
+	nonce    uint64
+	reserved bool
 }
 
 // New is the constructor for a Node. It accepts a messaging service, a chain service, and a store as injected dependencies.
-func New(messageService messageservice.MessageService, chainservice chainservice.ChainService, store store.Store, policymaker engine.PolicyMaker) Node {
+// The policymaker approves objectives; a policymaker implementing engine.IdleChannelPolicy (such as engine.AutoClosePolicy) also closes idle payment channels,
+// and one implementing engine.ObjectiveDeadlinePolicy (such as engine.DeadlinePolicy) challenges the channels of stalled objectives on chain.
+// An optional metricsApi may be supplied to record engine metrics; if it is nil, metrics are discarded.
+// An optional outcomeValidator may be supplied to vet the channel outcomes proposed by counterparties; if it is nil, outcomes must conserve funds.
+func New(messageService messageservice.MessageService, chainservice chainservice.ChainService, store store.Store, policymaker engine.PolicyMaker, metricsApi engine.MetricsApi, outcomeValidator outcome.Validator) Node {
 	n := Node{}
 	n.Address = store.GetAddress()
 
@@ -51,41 +78,88 @@ func New(messageService messageservice.MessageService, chainservice chainservice
 		panic(err)
 	}
 	n.chainId = chainId
+	n.chainservice = chainservice
+	n.msg = messageService
 	n.store = store
 	n.vm = payments.NewVoucherManager(*store.GetAddress(), store)
+	n.rng = rand.Secure
+	n.nextChannelNonce = &reservedNonce{}
+	n.closer = &closeOnce{}
 
-	n.engine = engine.New(n.vm, messageService, chainservice, store, policymaker, n.handleEngineEvent)
+	n.engine = engine.New(n.vm, messageService, chainservice, store, policymaker, n.handleEngineEvent, metricsApi, outcomeValidator)
 	n.completedObjectives = &safesync.Map[chan struct{}]{}
-	n.completedObjectivesForRPC = make(chan protocols.ObjectiveId, 100)
-
-	n.failedObjectives = make(chan protocols.ObjectiveId, 100)
-	// Using a larger buffer since payments can be sent frequently.
-	n.receivedVouchers = make(chan payments.Voucher, 1000)
+	n.waitingFor = &safesync.Map[protocols.WaitingFor]{}
+	n.events = newEvents(DefaultEventsConfig())
 
 	n.channelNotifier = notifier.NewChannelNotifier(store, n.vm)
 
 	return n
 }
 
+// SetPolicy replaces the policymaker which approves objectives proposed by counterparties, for example to stop accepting new channels during maintenance.
+// Objectives received afterwards are judged by the new policymaker. It is safe to call while the node is running.
+func (n *Node) SetPolicy(policymaker engine.PolicyMaker) {
+	n.engine.SetPolicy(policymaker)
+}
+
+// SetAssetCodeCheck decides whether channels proposed by counterparties are rejected unless each of their assets, other than the native token, has code deployed on chain.
+// It is disabled by default, for chain services which cannot read code, and returns an error if the node's chain service cannot.
+func (n *Node) SetAssetCodeCheck(enabled bool) error {
+	return n.engine.SetAssetCodeCheck(enabled)
+}
+
+// ConfigureEvents replaces the chans the node reports events on, such as CompletedObjectives and ReceivedVouchers, with chans of the configured sizes
+// which, when full, either drop their oldest events or block the engine. By default, only the chans of failed objectives and received vouchers block (see DefaultEventsConfig).
+// It must be called before the node is used, and before any of its event chans are read.
+func (n *Node) ConfigureEvents(config EventsConfig) {
+	n.events.configure(config)
+}
+
+// DroppedEvents counts the events each of the node's event chans has dropped because no consumer read them before the chan was full
+func (n *Node) DroppedEvents() DroppedEvents {
+	return n.events.dropped()
+}
+
+// SetRandomness replaces the generator of the nonces used for new channels and objectives, which is backed by crypto/rand by default.
+// Tests may supply a seeded generator so that a failing run can be replayed. It must be called before the node is used.
+func (n *Node) SetRandomness(rng rand.Generator) {
+	n.rng = rng
+}
+
+// SetTracerProvider replaces the provider of the OpenTelemetry tracer which records a span for the lifecycle of each objective,
+// with a child span for each crank. Spans are discarded by default. It must be called before the node is used.
+func (n *Node) SetTracerProvider(provider trace.TracerProvider) {
+	n.engine.SetTracerProvider(provider)
+}
+
 // handleEngineEvents dispatches events to the necessary node chan.
 func (n *Node) handleEngineEvent(update engine.EngineEvent) {
+	// Progress is dispatched first, so that it is available by the time an objective is reported as completed
+	for _, progress := range update.ObjectiveProgress {
+		n.waitingFor.Store(string(progress.ObjectiveId), progress.WaitingFor)
+		n.events.objectiveProgress.send(progress)
+	}
+	// Rejections are also dispatched before completions, so that the reason is available once a rejected objective is reported as completed
+	for _, rejection := range update.RejectedObjectives {
+		n.events.rejectedObjectives.send(rejection)
+	}
+
 	for _, completed := range update.CompletedObjectives {
+		n.waitingFor.Delete(string(completed.Id()))
 		d, _ := n.completedObjectives.LoadOrStore(string(completed.Id()), make(chan struct{}))
 		close(d)
 
-		// use a nonblocking send to the RPC Client in case no one is listening
-		select {
-		case n.completedObjectivesForRPC <- completed.Id():
-		default:
-		}
+		n.events.completedObjectives.send(completed.Id())
+		n.events.completedObjectiveDetails.send(query.ConstructCompletedObjectiveInfo(completed))
 	}
 
 	for _, erred := range update.FailedObjectives {
-		n.failedObjectives <- erred
+		n.waitingFor.Delete(string(erred))
+		n.events.failedObjectives.send(erred)
 	}
 
 	for _, payment := range update.ReceivedVouchers {
-		n.receivedVouchers <- payment
+		n.events.receivedVouchers.send(payment)
 	}
 
 	for _, updated := range update.LedgerChannelUpdates {
@@ -102,6 +176,41 @@ func (n *Node) handleEngineEvent(update engine.EngineEvent) {
 
 // Begin API
 
+// HealthCheckTimeout is how long Health waits for the chain to report its id before it reports the chain as disconnected
+var HealthCheckTimeout = 5 * time.Second
+
+// Health reports whether the node is connected to the chain and to its peers, and can read from its store.
+// A message service which cannot report whether it is connected is assumed to be.
+func (n *Node) Health() query.HealthInfo {
+	_, storeErr := n.store.GetAllConsensusChannels()
+	h := query.HealthInfo{ChainConnected: n.chainReachable() && n.chainservice.Connected(), MessagingConnected: true, StoreOpen: storeErr == nil}
+	if reporter, ok := n.msg.(messageservice.ConnectionReporter); ok {
+		h.MessagingConnected = reporter.Connected()
+	}
+	h.Ready = h.ChainConnected && h.MessagingConnected && h.StoreOpen
+	return h
+}
+
+// chainReachable returns true if the chain reports its id within the HealthCheckTimeout
+func (n *Node) chainReachable() bool {
+	reached := make(chan bool, 1)
+	go func() {
+		_, err := n.chainservice.GetChainId()
+		reached <- err == nil
+	}()
+	select {
+	case ok := <-reached:
+		return ok
+	case <-time.After(HealthCheckTimeout):
+		return false
+	}
+}
+
+// ChainId returns the id of the chain the node is connected to
+func (n *Node) ChainId() *big.Int {
+	return new(big.Int).Set(n.chainId)
+}
+
 // Version returns the go-nitro version
 func (n *Node) Version() string {
 	info, _ := debug.ReadBuildInfo()
@@ -126,8 +235,26 @@ func (n *Node) Version() string {
 }
 
 // CompletedObjectives returns a chan that receives a objective id whenever that objective is completed. Not suitable fo multiple subscribers.
+// The kind of each completed objective is given by its id's Type.
 func (n *Node) CompletedObjectives() <-chan protocols.ObjectiveId {
-	return n.completedObjectivesForRPC
+	return n.events.completedObjectives.channel()
+}
+
+// CompletedObjectiveDetails returns a chan that receives the type, channel and final outcome of an objective whenever that objective is completed.
+// Not suitable for multiple subscribers.
+func (n *Node) CompletedObjectiveDetails() <-chan query.CompletedObjectiveInfo {
+	return n.events.completedObjectiveDetails.channel()
+}
+
+// ObjectiveProgress returns a chan that receives what an objective is waiting for whenever that objective is cranked. Not suitable for multiple subscribers.
+func (n *Node) ObjectiveProgress() <-chan engine.ObjectiveProgressEvent {
+	return n.events.objectiveProgress.channel()
+}
+
+// RejectedObjectives returns a chan that receives the id of an objective and the reason it was rejected, whenever our policymaker
+// or a counterparty rejects an objective. Not suitable for multiple subscribers.
+func (n *Node) RejectedObjectives() <-chan engine.ObjectiveRejection {
+	return n.events.rejectedObjectives.channel()
 }
 
 // LedgerUpdates returns a chan that receives ledger channel info whenever that ledger channel is updated. Not suitable for multiple subscribers.
@@ -158,12 +285,12 @@ func (n *Node) PaymentChannelUpdatedChan(ledgerId types.Destination) <-chan quer
 
 // FailedObjectives returns a chan that receives an objective id whenever that objective has failed
 func (n *Node) FailedObjectives() <-chan protocols.ObjectiveId {
-	return n.failedObjectives
+	return n.events.failedObjectives.channel()
 }
 
 // ReceivedVouchers returns a chan that receives a voucher every time we receive a payment voucher
 func (n *Node) ReceivedVouchers() <-chan payments.Voucher {
-	return n.receivedVouchers
+	return n.events.receivedVouchers.channel()
 }
 
 // CreateVoucher creates and returns a voucher for the given channelId which increments the redeemable balance by amount.
@@ -199,9 +326,15 @@ func (n *Node) CreatePaymentChannel(Intermediaries []types.Address, CounterParty
 		CounterParty,
 		ChallengeDuration,
 		Outcome,
-		rand.Uint64(),
+		n.takeChannelNonce(),
 		n.engine.GetVirtualPaymentAppAddress(),
 	)
+	if err := objectiveRequest.Validate(*n.Address); err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
+	if err := n.checkLedgerCapacity(Intermediaries, CounterParty, Outcome); err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
 
 	// Send the event to the engine
 	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
@@ -210,6 +343,38 @@ func (n *Node) CreatePaymentChannel(Intermediaries []types.Address, CounterParty
 	return objectiveRequest.Response(*n.Address), nil
 }
 
+// checkLedgerCapacity returns an error if the ledger channel with the first hop towards the counterparty cannot guarantee the payment channel's outcome.
+// Both of us lock funds in the guarantee: this node locks its own allocation, and the first hop locks the counterparty's.
+// A missing ledger channel, or a malformed outcome, is left for the virtualfund objective to report.
+func (n *Node) checkLedgerCapacity(intermediaries []types.Address, counterparty types.Address, o outcome.Exit) error {
+	if o.Validate() != nil {
+		return nil
+	}
+	firstHop := counterparty
+	if len(intermediaries) > 0 {
+		firstHop = intermediaries[0]
+	}
+	for _, sae := range o {
+		if len(sae.Allocations) < 2 {
+			continue
+		}
+		balance, ok, err := query.AvailableLiquidity(n.store, firstHop, sae.Asset)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if need, have := sae.Allocations[0].Amount, balance.MyBalance.ToInt(); have.Cmp(need) < 0 {
+			return fmt.Errorf("%w: need %s, have %s of asset %s in the ledger channel with %s", virtualfund.ErrInsufficientCapacity, need, have, sae.Asset, firstHop)
+		}
+		if need, have := sae.Allocations[1].Amount, balance.TheirBalance.ToInt(); have.Cmp(need) < 0 {
+			return fmt.Errorf("%w: need %s, have %s of asset %s held by %s in their ledger channel with this node", virtualfund.ErrInsufficientCapacity, need, have, sae.Asset, firstHop)
+		}
+	}
+	return nil
+}
+
 // ClosePaymentChannel attempts to close and defund the given virtually funded channel.
 func (n *Node) ClosePaymentChannel(channelId types.Destination) (protocols.ObjectiveId, error) {
 	objectiveRequest := virtualdefund.NewObjectiveRequest(channelId)
@@ -227,10 +392,13 @@ func (n *Node) CreateLedgerChannel(Counterparty types.Address, ChallengeDuration
 		Counterparty,
 		ChallengeDuration,
 		outcome,
-		rand.Uint64(),
+		n.takeChannelNonce(),
 		n.engine.GetConsensusAppAddress(),
 		// Appdata implicitly zero
 	)
+	if err := objectiveRequest.Validate(*n.Address); err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
 
 	// Check store to see if there is an existing channel with this counterparty
 	channelExists, err := directfund.ChannelsExistWithCounterparty(Counterparty, n.store.GetChannelsByParticipant, n.store.GetConsensusChannel)
@@ -250,8 +418,77 @@ func (n *Node) CreateLedgerChannel(Counterparty types.Address, ChallengeDuration
 	return objectiveRequest.Response(*n.Address, n.chainId), nil
 }
 
+// SimulateCreateLedgerChannel describes the ledger channel which CreateLedgerChannel would create with the same arguments, without sending any messages or submitting any transactions.
+// The simulated channel's nonce is reserved, so that the next channel the node creates is the one simulated.
+func (n *Node) SimulateCreateLedgerChannel(Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (query.SimulatedObjectiveInfo, error) {
+	objectiveRequest := directfund.NewObjectiveRequest(
+		Counterparty,
+		ChallengeDuration,
+		outcome,
+		n.peekChannelNonce(),
+		n.engine.GetConsensusAppAddress(),
+	)
+	if err := objectiveRequest.Validate(*n.Address); err != nil {
+		return query.SimulatedObjectiveInfo{}, err
+	}
+	dfo, err := directfund.NewObjective(objectiveRequest, true, *n.Address, n.chainId, n.store.GetChannelsByParticipant, n.store.GetConsensusChannel)
+	if err != nil {
+		return query.SimulatedObjectiveInfo{}, err
+	}
+	return query.SimulateObjective(&dfo, n.store.GetChannelSecretKey())
+}
+
+// SimulateCreatePaymentChannel describes the payment channel which CreatePaymentChannel would create with the same arguments, without sending any messages or updating any ledger channels.
+// The simulated channel's nonce is reserved, so that the next channel the node creates is the one simulated.
+func (n *Node) SimulateCreatePaymentChannel(Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (query.SimulatedObjectiveInfo, error) {
+	objectiveRequest := virtualfund.NewObjectiveRequest(
+		Intermediaries,
+		CounterParty,
+		ChallengeDuration,
+		Outcome,
+		n.peekChannelNonce(),
+		n.engine.GetVirtualPaymentAppAddress(),
+	)
+	if err := objectiveRequest.Validate(*n.Address); err != nil {
+		return query.SimulatedObjectiveInfo{}, err
+	}
+	vfo, err := virtualfund.NewObjective(objectiveRequest, true, *n.Address, n.chainId, n.store.GetConsensusChannel)
+	if err != nil {
+		return query.SimulatedObjectiveInfo{}, err
+	}
+	return query.SimulateObjective(&vfo, n.store.GetChannelSecretKey())
+}
+
+// peekChannelNonce returns the nonce the next channel will be created with, drawing and reserving it if need be
+func (n *Node) peekChannelNonce() uint64 {
+	n.nextChannelNonce.mu.Lock()
+	defer n.nextChannelNonce.mu.Unlock()
+	if !n.nextChannelNonce.reserved {
+		n.nextChannelNonce.nonce, n.nextChannelNonce.reserved = n.rng.Uint64(), true
+	}
+	return n.nextChannelNonce.nonce
+}
+
+// takeChannelNonce returns the nonce for a new channel: the reserved nonce if a simulation drew one, or a new one otherwise
+func (n *Node) takeChannelNonce() uint64 {
+	n.nextChannelNonce.mu.Lock()
+	defer n.nextChannelNonce.mu.Unlock()
+	if n.nextChannelNonce.reserved {
+		n.nextChannelNonce.reserved = false
+		return n.nextChannelNonce.nonce
+	}
+	return n.rng.Uint64()
+}
+
 // CloseLedgerChannel attempts to close and defund the given directly funded channel.
+// The ledger channel must not be funding any payment channels, since defunding it would strand their funds.
 func (n *Node) CloseLedgerChannel(channelId types.Destination) (protocols.ObjectiveId, error) {
+	if cc, err := n.store.GetConsensusChannelById(channelId); err == nil {
+		if targets := cc.FundingTargets(); len(targets) != 0 {
+			return "", fmt.Errorf("ledger channel %s funds payment channels %v which must be closed first: %w", channelId, targets, directdefund.ErrNotEmpty)
+		}
+	}
+
 	objectiveRequest := directdefund.NewObjectiveRequest(channelId)
 
 	// Send the event to the engine
@@ -260,18 +497,91 @@ func (n *Node) CloseLedgerChannel(channelId types.Destination) (protocols.Object
 	return objectiveRequest.Id(*n.Address, n.chainId), nil
 }
 
+// TopUpLedgerChannel deposits the given amount into the given ledger channel and allocates it to this node, keeping the channel open.
+// The ledger channel must not be funding any payment channels while it is topped up.
+func (n *Node) TopUpLedgerChannel(channelId types.Destination, amount *big.Int) (protocols.ObjectiveId, error) {
+	if cc, err := n.store.GetConsensusChannelById(channelId); err == nil {
+		if targets := cc.FundingTargets(); len(targets) != 0 {
+			return "", fmt.Errorf("ledger channel %s funds payment channels %v which must be closed first: %w", channelId, targets, ledgertopup.ErrNotEmpty)
+		}
+	}
+
+	objectiveRequest := ledgertopup.NewObjectiveRequest(channelId, amount, n.rng.Uint64())
+
+	// Send the event to the engine
+	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
+	objectiveRequest.WaitForObjectiveToStart()
+	return objectiveRequest.Id(*n.Address, n.chainId), nil
+}
+
+// RecycleLedgerChannel reallocates the funds the given ledger channel holds on chain according to the outcome, keeping the channel open.
+// No transaction is submitted: the outcome must allocate the same total of the channel's asset, to the channel's participants in the same order,
+// and the channel must not be funding any payment channels while it is recycled.
+func (n *Node) RecycleLedgerChannel(channelId types.Destination, outcome outcome.Exit) (protocols.ObjectiveId, error) {
+	if cc, err := n.store.GetConsensusChannelById(channelId); err == nil {
+		if targets := cc.FundingTargets(); len(targets) != 0 {
+			return "", fmt.Errorf("ledger channel %s funds payment channels %v which must be closed first: %w", channelId, targets, ledgerrecycle.ErrNotEmpty)
+		}
+	}
+
+	objectiveRequest := ledgerrecycle.NewObjectiveRequest(channelId, outcome, n.rng.Uint64())
+
+	// Send the event to the engine
+	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
+	objectiveRequest.WaitForObjectiveToStart()
+	return objectiveRequest.Id(*n.Address, n.chainId), nil
+}
+
+// UpdateAppState moves the application channel of the given state to that state, once every participant has signed it.
+// The state must have a greater turn number than the channel's latest supported state, and allocate the same total of each asset.
+func (n *Node) UpdateAppState(s state.State) (protocols.ObjectiveId, error) {
+	objectiveRequest := appupdate.NewObjectiveRequest(s)
+
+	// Send the event to the engine
+	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
+	objectiveRequest.WaitForObjectiveToStart()
+	return objectiveRequest.Id(*n.Address, n.chainId), nil
+}
+
 // Pay will send a signed voucher to the payee that they can redeem for the given amount.
 func (n *Node) Pay(channelId types.Destination, amount *big.Int) {
 	// Send the event to the engine
 	n.engine.PaymentRequestsFromAPI <- engine.PaymentRequest{ChannelId: channelId, Amount: amount}
 }
 
+// CancelObjective abandons an objective which is in progress, notifying its counterparties and completing it.
+// Only direct fund objectives with no on chain deposits, ledger top ups whose deposit has not been submitted and virtual fund objectives with an incomplete prefund round can be cancelled.
+// Any other objective is past the point of no return, and protocols.ErrNotCancellable is returned.
+func (n *Node) CancelObjective(id protocols.ObjectiveId) error {
+	request := engine.NewCancelRequest(id)
+	n.engine.CancelRequestsFromAPI <- request
+	return request.WaitForCancellation()
+}
+
+// QueryStore calls query with a read-only view of the node's store, for reporting on objectives and channels
+// which are not covered by the other query methods. The error returned by query is returned.
+func (n *Node) QueryStore(query func(store.ReadOnlyStore) error) error {
+	return query(store.ReadOnly(n.store))
+}
+
 // GetPaymentChannel returns the payment channel with the given id.
 // If no ledger channel exists with the given id an error is returned.
 func (n *Node) GetPaymentChannel(id types.Destination) (query.PaymentChannelInfo, error) {
 	return query.GetPaymentChannelInfo(id, n.store, n.vm)
 }
 
+// GetVoucherBalance returns the amount of the largest voucher on the given payment channel as the Total,
+// and how much that amount has increased since the caller last queried the balance as the Delta.
+// The caller is a label chosen by whoever queries the balance, so that each of several callers sees the increase since its own last query.
+// As each query is recorded, the RPC server only serves it to clients whose token may sign.
+func (n *Node) GetVoucherBalance(channelId types.Destination, caller string) (payments.ReceiveVoucherSummary, error) {
+	total, delta, err := n.vm.QueryBalance(channelId, caller)
+	if err != nil {
+		return payments.ReceiveVoucherSummary{}, err
+	}
+	return payments.ReceiveVoucherSummary{Total: total, Delta: delta}, nil
+}
+
 // GetPaymentChannelsByLedger returns all active payment channels that are funded by the given ledger channel.
 func (n *Node) GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error) {
 	return query.GetPaymentChannelsByLedger(ledgerId, n.store, n.vm)
@@ -282,6 +592,31 @@ func (n *Node) GetAllLedgerChannels() ([]query.LedgerChannelInfo, error) {
 	return query.GetAllLedgerChannels(n.store, n.engine.GetConsensusAppAddress())
 }
 
+// TotalAvailableLiquidity returns how much of the asset is free to fund new payment channels, summed across all open ledger channels.
+func (n *Node) TotalAvailableLiquidity(asset types.Address) (*big.Int, error) {
+	return query.TotalAvailableLiquidity(n.store, asset)
+}
+
+// FindRoute returns intermediaries through which a payment channel with the given deposit of the asset could be funded to the payee.
+// Only this node's ledger channels are known to it, so ledger channels reported by potential intermediaries should be supplied as knownLedgers.
+func (n *Node) FindRoute(payee types.Address, asset types.Address, amount *big.Int, knownLedgers ...query.LedgerChannelInfo) ([]types.Address, error) {
+	ledgers, err := n.GetAllLedgerChannels()
+	if err != nil {
+		return nil, err
+	}
+	return query.FindRoute(*n.Address, payee, asset, amount, append(ledgers, knownLedgers...))
+}
+
+// GetObjectiveByChannelId returns the objective which is currently operating on the given channel, or query.NoObjective if there is none
+func (n *Node) GetObjectiveByChannelId(channelId types.Destination) (query.ObjectiveStatusInfo, error) {
+	info, err := query.GetObjectiveByChannelId(channelId, n.store)
+	if err != nil || info == query.NoObjective {
+		return info, err
+	}
+	info.WaitingFor, _ = n.waitingFor.Load(string(info.ID))
+	return info, nil
+}
+
 // GetLastBlockNum returns last confirmed blockNum read from store
 func (n *Node) GetLastBlockNum() (uint64, error) {
 	return n.store.GetLastBlockNumSeen()
@@ -293,8 +628,30 @@ func (n *Node) GetLedgerChannel(id types.Destination) (query.LedgerChannelInfo,
 	return query.GetLedgerChannelInfo(id, n.store)
 }
 
-// Close stops the node from responding to any input.
+// GetChannelFinalizationTime returns when the given channel becomes finalizable on chain, if it has been challenged
+func (n *Node) GetChannelFinalizationTime(id types.Destination) (query.ChannelFinalizationInfo, error) {
+	return query.GetChannelFinalizationTime(id, n.store)
+}
+
+// GetLatestSignedState returns the latest state of the given channel which every participant has signed, which the node would challenge with
+func (n *Node) GetLatestSignedState(id types.Destination) (state.SignedState, error) {
+	return query.GetLatestSignedState(id, n.store)
+}
+
+// GetClosedChannels returns the ledger and payment channels which have closed and are selected by the filter, in the order they closed
+func (n *Node) GetClosedChannels(filter query.ClosedChannelFilter) ([]query.ClosedChannelInfo, error) {
+	return query.GetClosedChannels(n.store, filter)
+}
+
+// Close stops the node from responding to any input: it stops the engine, and closes the message service, chain service and store.
+// The channels the node reports events on are closed, so that loops ranging over them terminate.
+// Only the first call closes the node; later calls return the same error.
 func (n *Node) Close() error {
+	n.closer.once.Do(func() { n.closer.err = n.close() })
+	return n.closer.err
+}
+
+func (n *Node) close() error {
 	if err := n.engine.Close(); err != nil {
 		return err
 	}
@@ -304,7 +661,7 @@ func (n *Node) Close() error {
 
 	// If there are blocking consumers (for or select channel statements) on any channel for which the node is a producer,
 	// those channels need to be closed.
-	close(n.completedObjectivesForRPC)
+	n.events.close()
 
 	return n.store.Close()
 }
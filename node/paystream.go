@@ -0,0 +1,98 @@
+package node
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// PayStreamOpts configures how often a PaymentStream emits vouchers.
+type PayStreamOpts struct {
+	// Interval is the longest an added amount waits before a voucher is sent for it.
+	Interval time.Duration
+	// MinIncrement is the accumulated amount which causes a voucher to be sent immediately.
+	MinIncrement *big.Int
+}
+
+// PaymentStream accumulates small payments on a payment channel and batches them into fewer vouchers.
+// A voucher is sent once the accumulated amount reaches MinIncrement, or once Interval has passed since
+// the first unsent amount was added, whichever comes first.
+type PaymentStream struct {
+	channelId types.Destination
+	opts      PayStreamOpts
+	pay       func(channelId types.Destination, amount *big.Int)
+
+	mu         sync.Mutex
+	pending    *big.Int
+	timer      *time.Timer
+	generation uint64 // incremented by every flush, so that a timer which fires after its batch was flushed does not flush the next batch early
+}
+
+// PayStream returns a PaymentStream which pays on the given channel using the supplied batching options.
+func (n *Node) PayStream(channelId types.Destination, opts PayStreamOpts) *PaymentStream {
+	return newPaymentStream(channelId, opts, n.Pay)
+}
+
+func newPaymentStream(channelId types.Destination, opts PayStreamOpts, pay func(types.Destination, *big.Int)) *PaymentStream {
+	return &PaymentStream{channelId: channelId, opts: opts, pay: pay, pending: big.NewInt(0)}
+}
+
+// Add accumulates amount, sending a voucher if the accumulated amount has reached MinIncrement.
+func (s *PaymentStream) Add(amount *big.Int) {
+	s.mu.Lock()
+	s.pending.Add(s.pending, amount)
+	if s.opts.MinIncrement != nil && s.pending.Cmp(s.opts.MinIncrement) >= 0 {
+		batch := s.take()
+		s.mu.Unlock()
+		s.send(batch)
+		return
+	}
+	if s.timer == nil {
+		generation := s.generation
+		s.timer = time.AfterFunc(s.opts.Interval, func() { s.flushGeneration(generation) })
+	}
+	s.mu.Unlock()
+}
+
+// Flush immediately sends a voucher for any accumulated amount.
+// It should be called once the stream is no longer needed, so that no amount is left unpaid.
+func (s *PaymentStream) Flush() {
+	s.mu.Lock()
+	batch := s.take()
+	s.mu.Unlock()
+	s.send(batch)
+}
+
+// flushGeneration flushes the batch started in the given generation, unless it has already been flushed
+func (s *PaymentStream) flushGeneration(generation uint64) {
+	s.mu.Lock()
+	if generation != s.generation {
+		s.mu.Unlock()
+		return
+	}
+	batch := s.take()
+	s.mu.Unlock()
+	s.send(batch)
+}
+
+// take stops the timer, starts a new generation and returns the accumulated amount. The caller must hold s.mu.
+func (s *PaymentStream) take() *big.Int {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.generation++
+	batch := s.pending
+	s.pending = big.NewInt(0)
+	return batch
+}
+
+// send pays the batch, unless it is empty. It is called without holding s.mu, so that adding to the stream does not wait for payments.
+func (s *PaymentStream) send(batch *big.Int) {
+	if batch.Sign() == 0 {
+		return
+	}
+	s.pay(s.channelId, batch)
+}
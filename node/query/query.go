@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/statechannels/go-nitro/channel"
@@ -60,25 +62,39 @@ func getLatestSupportedOrPreFund(channel *channel.Channel) (state.State, error)
 	return channel.PreFundState(), nil
 }
 
-// getLedgerBalanceFromState returns the balance of the ledger channel from the given state
-func getLedgerBalanceFromState(latest state.State, myAddress types.Address) (LedgerChannelBalance, error) {
-	// TODO: We assume single asset outcomes
-	outcome := latest.Outcome[0]
+// getLedgerBalancesFromState returns the balance of each asset in the ledger channel from the given state
+func getLedgerBalancesFromState(latest state.State, myAddress types.Address) ([]LedgerChannelBalance, error) {
+	balances := make([]LedgerChannelBalance, 0, len(latest.Outcome))
+	for _, sae := range latest.Outcome {
+		balance, err := getLedgerBalance(sae, latest.Participants, myAddress)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+	if len(balances) == 0 {
+		return nil, fmt.Errorf("ledger channel outcome has no assets")
+	}
+	return balances, nil
+}
+
+// getLedgerBalance returns the balance of a single asset of a ledger channel
+func getLedgerBalance(outcome outcome.SingleAssetExit, participants []types.Address, myAddress types.Address) (LedgerChannelBalance, error) {
 	asset := outcome.Asset
 
 	var them types.Address
 	var myBalance, theirBalance *big.Int
 
-	if latest.Participants[0] == myAddress {
-		them = latest.Participants[1]
+	if participants[0] == myAddress {
+		them = participants[1]
 		theirBalance = outcome.Allocations[1].Amount
 		myBalance = outcome.Allocations[0].Amount
-	} else if latest.Participants[1] == myAddress {
-		them = latest.Participants[0]
+	} else if participants[1] == myAddress {
+		them = participants[0]
 		theirBalance = outcome.Allocations[0].Amount
 		myBalance = outcome.Allocations[1].Amount
 	} else {
-		return LedgerChannelBalance{}, fmt.Errorf("could not find my address %v in participants %v", myAddress, latest.Participants)
+		return LedgerChannelBalance{}, fmt.Errorf("could not find my address %v in participants %v", myAddress, participants)
 	}
 
 	return LedgerChannelBalance{
@@ -102,6 +118,76 @@ func GetVirtualFundObjective(id types.Destination, store store.Store) (*virtualf
 	return o.(*virtualfund.Objective), true
 }
 
+// GetObjectiveByChannelId returns the objective which is currently operating on the given channel, or NoObjective if there is none.
+// The store does not record what objectives are waiting for, so WaitingFor is left empty.
+func GetObjectiveByChannelId(channelId types.Destination, store store.Store) (ObjectiveStatusInfo, error) {
+	o, ok := store.GetObjectiveByChannelId(channelId)
+	if !ok {
+		return NoObjective, nil
+	}
+	objectiveType, err := o.Id().Type()
+	if err != nil {
+		return ObjectiveStatusInfo{}, err
+	}
+	return ObjectiveStatusInfo{ID: o.Id(), Type: objectiveType}, nil
+}
+
+// ConstructCompletedObjectiveInfo describes a completed objective using the channel that it owns
+func ConstructCompletedObjectiveInfo(o protocols.Objective) CompletedObjectiveInfo {
+	info := CompletedObjectiveInfo{ID: o.Id(), ChannelId: o.OwnsChannel()}
+	// Every objective is constructed by one of the protocols, so its id is prefixed by a known type
+	info.Type, _ = o.Id().Type()
+
+	if c, ok := ownedChannel(o); ok {
+		if latest, err := c.LatestSupportedState(); err == nil {
+			info.Outcome = latest.Outcome.Clone()
+		}
+	}
+	return info
+}
+
+// SimulateObjective cranks a newly constructed objective once, and describes what creating it would do.
+// The cranked objective and its side effects are discarded, so no messages are sent and no transactions are submitted.
+func SimulateObjective(o protocols.Objective, secretKey *[]byte) (SimulatedObjectiveInfo, error) {
+	c, ok := ownedChannel(o)
+	if !ok {
+		return SimulatedObjectiveInfo{}, fmt.Errorf("objective %s does not own a channel", o.Id())
+	}
+	_, sideEffects, waitingFor, err := o.Crank(secretKey)
+	if err != nil {
+		return SimulatedObjectiveInfo{}, err
+	}
+
+	info := SimulatedObjectiveInfo{
+		ID:         o.Id(),
+		ChannelId:  c.Id,
+		Outcome:    c.PreFundState().Outcome.Clone(),
+		Deposit:    c.PreFundState().Outcome.TotalAllocatedFor(c.MyDestination()),
+		WaitingFor: waitingFor,
+	}
+	for _, msg := range sideEffects.MessagesToSend {
+		info.MessageRecipients = append(info.MessageRecipients, msg.To)
+	}
+	return info, nil
+}
+
+// ownedChannel returns the channel or virtual channel which the objective owns, if it is among the objective's related storables
+func ownedChannel(o protocols.Objective) (*channel.Channel, bool) {
+	for _, related := range o.Related() {
+		var c *channel.Channel
+		switch r := related.(type) {
+		case *channel.Channel:
+			c = r
+		case *channel.VirtualChannel:
+			c = &r.Channel
+		}
+		if c != nil && c.Id == o.OwnsChannel() {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
 // GetVoucherBalance returns the amount paid and remaining for a given channel based on vouchers received.
 // If not vouchers are received for the channel, it returns 0 for paid and remaining.
 func GetVoucherBalance(id types.Destination, vm *payments.VoucherManager) (paid, remaining *big.Int, err error) {
@@ -182,6 +268,57 @@ func GetAllLedgerChannels(store store.Store, consensusAppDefinition types.Addres
 	return toReturn, err
 }
 
+// AvailableLiquidity returns how much of the asset this node and the counterparty each hold in their ledger channel, free to fund new payment channels.
+// An asset which the ledger channel does not hold has no liquidity. ok is false if there is no ledger channel with the counterparty.
+func AvailableLiquidity(store store.Store, counterparty, asset types.Address) (balance LedgerChannelBalance, ok bool, err error) {
+	con, ok := store.GetConsensusChannel(counterparty)
+	if !ok {
+		return LedgerChannelBalance{}, false, nil
+	}
+	myAddress := *store.GetAddress()
+	balances, err := getLedgerBalancesFromState(con.ConsensusVars().AsState(con.FixedPart()), myAddress)
+	if err != nil {
+		return LedgerChannelBalance{}, true, fmt.Errorf("could not get the balances of ledger channel %s: %w", con.Id, err)
+	}
+	for _, balance := range balances {
+		if balance.AssetAddress == asset {
+			return balance, true, nil
+		}
+	}
+	return LedgerChannelBalance{
+		AssetAddress: asset,
+		Me:           myAddress,
+		Them:         counterparty,
+		MyBalance:    (*hexutil.Big)(big.NewInt(0)),
+		TheirBalance: (*hexutil.Big)(big.NewInt(0)),
+	}, true, nil
+}
+
+// TotalAvailableLiquidity returns how much of the asset this node holds, free to fund new payment channels, across all of its open ledger channels.
+// Amounts locked in guarantees for payment channels are allocated to those guarantees rather than to this node, so they are not counted.
+func TotalAvailableLiquidity(store store.Store, asset types.Address) (*big.Int, error) {
+	myAddress := *store.GetAddress()
+
+	allConsensus, err := store.GetAllConsensusChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	total := big.NewInt(0)
+	for _, con := range allConsensus {
+		balances, err := getLedgerBalancesFromState(con.ConsensusVars().AsState(con.FixedPart()), myAddress)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the balances of ledger channel %s: %w", con.Id, err)
+		}
+		for _, balance := range balances {
+			if balance.AssetAddress == asset {
+				total.Add(total, balance.MyBalance.ToInt())
+			}
+		}
+	}
+	return total, nil
+}
+
 // GetPaymentChannelsByLedger returns a `PaymentChannelInfo` for each active payment channel funded by the given ledger channel.
 func GetPaymentChannelsByLedger(ledgerId types.Destination, s store.Store, vm *payments.VoucherManager) ([]PaymentChannelInfo, error) {
 	// If a ledger channel is actively funding payment channels it must be in the form of a consensus channel
@@ -235,17 +372,90 @@ func GetLedgerChannelInfo(id types.Destination, store store.Store) (LedgerChanne
 	return ConstructLedgerInfoFromConsensus(con, myAddress)
 }
 
+// GetChannelFinalizationTime returns when the given ledger or payment channel becomes finalizable, if it has been challenged
+func GetChannelFinalizationTime(id types.Destination, store store.Store) (ChannelFinalizationInfo, error) {
+	var challengeBlock, finalizesAt uint64
+	if c, ok := store.GetChannelById(id); ok {
+		challengeBlock, finalizesAt = c.OnChain.ChallengeBlock, c.OnChain.FinalizesAt
+	} else {
+		con, err := store.GetConsensusChannelById(id)
+		if err != nil {
+			return ChannelFinalizationInfo{}, err
+		}
+		challengeBlock, finalizesAt = con.ChallengeBlock, con.FinalizesAt
+	}
+	return ChannelFinalizationInfo{
+		ID:             id,
+		Challenged:     challengeBlock != 0,
+		ChallengeBlock: challengeBlock,
+		FinalizesAt:    finalizesAt,
+	}, nil
+}
+
+// GetLatestSignedState returns the latest state of the given ledger or payment channel which every participant has signed.
+// It is the state the node would challenge with, so it can be kept as evidence of the channel's outcome.
+func GetLatestSignedState(id types.Destination, store store.Store) (state.SignedState, error) {
+	if c, ok := store.GetChannelById(id); ok {
+		supported, err := c.LatestSupportedSignedState()
+		if err != nil {
+			return state.SignedState{}, fmt.Errorf("channel %s has no state signed by every participant: %w", id, err)
+		}
+		return supported, nil
+	}
+	con, err := store.GetConsensusChannelById(id)
+	if err != nil {
+		return state.SignedState{}, err
+	}
+	return con.SupportedSignedState(), nil
+}
+
+// GetClosedChannels returns the archived channels which have closed and are selected by the filter, in the order they closed
+func GetClosedChannels(store store.Store, filter ClosedChannelFilter) ([]ClosedChannelInfo, error) {
+	archived, err := store.GetArchivedChannels()
+	if err != nil {
+		return []ClosedChannelInfo{}, err
+	}
+	closed := []ClosedChannelInfo{}
+	for _, a := range archived {
+		switch {
+		case !a.Closed():
+			continue
+		case filter.Participant != (types.Address{}) && !slices.Contains(a.Participants, filter.Participant):
+			continue
+		case !filter.ClosedAfter.IsZero() && a.ClosedAt.Before(filter.ClosedAfter):
+			continue
+		case !filter.ClosedBefore.IsZero() && a.ClosedAt.After(filter.ClosedBefore):
+			continue
+		}
+		info := ClosedChannelInfo{
+			ID:           a.ID,
+			Ledger:       a.Ledger,
+			Participants: a.Participants,
+			OpenedAt:     a.OpenedAt,
+			ClosedAt:     a.ClosedAt,
+			Outcome:      a.FinalOutcome,
+		}
+		if a.TotalPaid != nil {
+			info.TotalPaid = (*hexutil.Big)(a.TotalPaid)
+		}
+		closed = append(closed, info)
+	}
+	sort.Slice(closed, func(i, j int) bool { return closed[i].ClosedAt.Before(closed[j].ClosedAt) })
+	return closed, nil
+}
+
 func ConstructLedgerInfoFromConsensus(con *consensus_channel.ConsensusChannel, myAddress types.Address) (LedgerChannelInfo, error) {
 	latest := con.ConsensusVars().AsState(con.FixedPart())
-	balance, err := getLedgerBalanceFromState(latest, myAddress)
+	balances, err := getLedgerBalancesFromState(latest, myAddress)
 	if err != nil {
 		return LedgerChannelInfo{}, fmt.Errorf("failed to construct ledger channel info from consensus channel: %w", err)
 	}
 
 	return LedgerChannelInfo{
-		ID:      con.Id,
-		Status:  Open,
-		Balance: balance,
+		ID:       con.Id,
+		Status:   Open,
+		Balance:  balances[0],
+		Balances: balances,
 	}, nil
 }
 
@@ -254,15 +464,16 @@ func ConstructLedgerInfoFromChannel(c *channel.Channel, myAddress types.Address)
 	if err != nil {
 		return LedgerChannelInfo{}, err
 	}
-	balance, err := getLedgerBalanceFromState(latest, myAddress)
+	balances, err := getLedgerBalancesFromState(latest, myAddress)
 	if err != nil {
 		return LedgerChannelInfo{}, fmt.Errorf("failed to construct ledger channel info from channel: %w", err)
 	}
 
 	return LedgerChannelInfo{
-		ID:      c.Id,
-		Status:  getStatusFromChannel(c),
-		Balance: balance,
+		ID:       c.Id,
+		Status:   getStatusFromChannel(c),
+		Balance:  balances[0],
+		Balances: balances,
 	}, nil
 }
 
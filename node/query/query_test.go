@@ -0,0 +1,135 @@
+package query
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestLedgerInfoReportsAsset(t *testing.T) {
+	alice, bob := testactors.Alice, testactors.Bob
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+	s := state.State{
+		Participants:      []types.Address{alice.Address(), bob.Address()},
+		ChannelNonce:      1,
+		ChallengeDuration: 60,
+		Outcome:           testdata.Outcomes.Create(alice.Address(), bob.Address(), 5, 7, token),
+	}
+	c, err := channel.New(s, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := ConstructLedgerInfoFromChannel(c, alice.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Balance.AssetAddress != token {
+		t.Errorf("channel: expected asset %s, got %s", token, info.Balance.AssetAddress)
+	}
+
+	lo := consensus_channel.NewLedgerOutcome(token,
+		consensus_channel.NewBalance(alice.Destination(), big.NewInt(5)),
+		consensus_channel.NewBalance(bob.Destination(), big.NewInt(7)),
+		[]consensus_channel.Guarantee{})
+	consensusState := s.Clone()
+	consensusState.TurnNum = 1
+	consensusState.Outcome = lo.AsOutcome()
+	aliceSig, _ := consensusState.Sign(alice.PrivateKey)
+	bobSig, _ := consensusState.Sign(bob.PrivateKey)
+	con, err := consensus_channel.NewLeaderChannel(s.FixedPart(), 1, *lo, [2]state.Signature{aliceSig, bobSig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err = ConstructLedgerInfoFromConsensus(&con, bob.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Balance.AssetAddress != token {
+		t.Errorf("consensus channel: expected asset %s, got %s", token, info.Balance.AssetAddress)
+	}
+	if info.Balance.MyBalance.ToInt().Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("consensus channel: expected my balance of 7, got %s", info.Balance.MyBalance.ToInt())
+	}
+}
+
+func TestMultiAssetLedgerInfo(t *testing.T) {
+	alice, bob := testactors.Alice, testactors.Bob
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+	ethOutcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), 5, 7, types.Address{})
+	tokenOutcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), 100, 200, token)
+	s := state.State{
+		Participants:      []types.Address{alice.Address(), bob.Address()},
+		ChannelNonce:      1,
+		ChallengeDuration: 60,
+		Outcome:           append(ethOutcome, tokenOutcome...),
+	}
+	c, err := channel.New(s, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ConstructLedgerInfoFromChannel(c, bob.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Balances) != 2 {
+		t.Fatalf("expected a balance for each of the 2 assets, got %d", len(info.Balances))
+	}
+	if !info.Balance.Equal(info.Balances[0]) {
+		t.Errorf("expected Balance to be the balance of the first asset, got %+v", info.Balance)
+	}
+
+	for asset, want := range map[types.Address][2]int64{{}: {7, 5}, token: {200, 100}} {
+		balance, ok := info.BalanceFor(asset)
+		if !ok {
+			t.Fatalf("expected a balance for asset %s", asset)
+		}
+		if balance.Me != bob.Address() || balance.Them != alice.Address() {
+			t.Errorf("asset %s: unexpected participants %+v", asset, balance)
+		}
+		if balance.MyBalance.ToInt().Int64() != want[0] || balance.TheirBalance.ToInt().Int64() != want[1] {
+			t.Errorf("asset %s: expected balances %v, got %s and %s", asset, want, balance.MyBalance.ToInt(), balance.TheirBalance.ToInt())
+		}
+	}
+
+	if _, ok := info.BalanceFor(common.HexToAddress("0x01")); ok {
+		t.Errorf("expected no balance for an asset the channel does not hold")
+	}
+}
+
+func TestGetObjectiveByChannelId(t *testing.T) {
+	s := store.NewMemStore(testactors.Alice.PrivateKey)
+	dfo := testdata.Objectives.Directfund.GenericDFO()
+	dfo.Status = protocols.Approved
+	if err := s.SetObjective(&dfo); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GetObjectiveByChannelId(dfo.C.Id, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ObjectiveStatusInfo{ID: dfo.Id(), Type: "DirectFunding"}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	got, err = GetObjectiveByChannelId(types.Destination{1}, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != NoObjective {
+		t.Errorf("expected no objective for an unknown channel, got %+v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package query
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrNoRoute is returned when no path of open ledger channels can carry a payment channel's deposit
+const ErrNoRoute = types.ConstError("no route with sufficient capacity")
+
+// FindRoute returns the intermediaries of the shortest path of open ledger channels from the payer to the payee,
+// where each hop can lock amount of the asset from the side closer to the payer. An empty slice means the payer and payee share a ledger channel.
+// Ledger channels which do not hold the asset are not considered.
+//
+// A node only knows about its own ledger channels, so ledgers should include those reported by the intermediaries being considered.
+func FindRoute(from, to types.Address, asset types.Address, amount *big.Int, ledgers []LedgerChannelInfo) ([]types.Address, error) {
+	if from == to {
+		return nil, fmt.Errorf("payer and payee must differ: %s", from)
+	}
+
+	// capacity[a][b] is the largest amount a can lock in its ledger channel with b
+	capacity := make(map[types.Address]map[types.Address]*big.Int)
+	// neighbours preserves the order in which ledger channels were supplied, so that routes are deterministic
+	neighbours := make(map[types.Address][]types.Address)
+	addEdge := func(a, b types.Address, balance *big.Int) {
+		if capacity[a] == nil {
+			capacity[a] = make(map[types.Address]*big.Int)
+		}
+		if _, ok := capacity[a][b]; !ok {
+			neighbours[a] = append(neighbours[a], b)
+		}
+		capacity[a][b] = balance
+	}
+	for _, l := range ledgers {
+		if l.Status != Open {
+			continue
+		}
+		b, ok := l.BalanceFor(asset)
+		if !ok {
+			continue
+		}
+		addEdge(b.Me, b.Them, b.MyBalance.ToInt())
+		addEdge(b.Them, b.Me, b.TheirBalance.ToInt())
+	}
+
+	// Breadth first search, so that the route with the fewest intermediaries is found
+	previous := map[types.Address]types.Address{from: from}
+	queue := []types.Address{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range neighbours[current] {
+			if _, visited := previous[next]; visited || capacity[current][next].Cmp(amount) < 0 {
+				continue
+			}
+			previous[next] = current
+			if next == to {
+				return intermediariesTo(to, from, previous), nil
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return nil, fmt.Errorf("no route from %s to %s for %s of asset %s: %w", from, to, amount, asset, ErrNoRoute)
+}
+
+// intermediariesTo walks back from the payee to the payer, returning the participants strictly between them in payment order
+func intermediariesTo(to, from types.Address, previous map[types.Address]types.Address) []types.Address {
+	intermediaries := []types.Address{}
+	for hop := previous[to]; hop != from; hop = previous[hop] {
+		intermediaries = append([]types.Address{hop}, intermediaries...)
+	}
+	return intermediaries
+}
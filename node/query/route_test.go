@@ -0,0 +1,92 @@
+package query
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestFindRoute(t *testing.T) {
+	alice, bob, irene, ivan := testactors.Alice.Address(), testactors.Bob.Address(), testactors.Irene.Address(), testactors.Ivan.Address()
+
+	asset, otherAsset := types.Address{}, common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	balance := func(asset, me, them types.Address, myBalance, theirBalance int64) LedgerChannelBalance {
+		return LedgerChannelBalance{
+			AssetAddress: asset,
+			Me:           me,
+			Them:         them,
+			MyBalance:    (*hexutil.Big)(big.NewInt(myBalance)),
+			TheirBalance: (*hexutil.Big)(big.NewInt(theirBalance)),
+		}
+	}
+	ledger := func(me, them types.Address, myBalance, theirBalance int64) LedgerChannelInfo {
+		b := balance(asset, me, them, myBalance, theirBalance)
+		return LedgerChannelInfo{Status: Open, Balance: b, Balances: []LedgerChannelBalance{b}}
+	}
+
+	ledgers := []LedgerChannelInfo{
+		ledger(alice, irene, 10, 10),
+		ledger(irene, ivan, 10, 10),
+		// Bob reports his ledger with Ivan from his own perspective
+		ledger(bob, ivan, 10, 10),
+		// A shorter route through Ivan which can only carry small payments
+		ledger(alice, ivan, 1, 10),
+	}
+
+	testCases := []struct {
+		name   string
+		amount int64
+		want   []types.Address
+	}{
+		{"prefers the fewest intermediaries", 1, []types.Address{ivan}},
+		{"avoids hops without capacity", 5, []types.Address{irene, ivan}},
+		{"uses the full capacity of each hop", 10, []types.Address{irene, ivan}},
+	}
+	for _, tc := range testCases {
+		got, err := FindRoute(alice, bob, asset, big.NewInt(tc.amount), ledgers)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: expected route %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	got, err := FindRoute(alice, irene, asset, big.NewInt(5), ledgers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no intermediaries for a direct ledger channel, got %v", got)
+	}
+
+	if _, err := FindRoute(alice, bob, asset, big.NewInt(11), ledgers); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("expected %v when no hop has enough capacity, got %v", ErrNoRoute, err)
+	}
+
+	closing := append([]LedgerChannelInfo{}, ledgers[:3]...)
+	closing[0].Status = Closing
+	if _, err := FindRoute(alice, bob, asset, big.NewInt(5), closing); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("expected %v when a hop is not open, got %v", ErrNoRoute, err)
+	}
+
+	// A ledger channel whose first asset has capacity can only route that asset
+	multiAsset := append([]LedgerChannelInfo{}, ledgers[:3]...)
+	multiAsset[0].Balance = balance(otherAsset, alice, irene, 10, 10)
+	multiAsset[0].Balances = []LedgerChannelBalance{multiAsset[0].Balance, balance(asset, alice, irene, 1, 10)}
+	if _, err := FindRoute(alice, bob, asset, big.NewInt(5), multiAsset); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("expected %v when a hop lacks capacity in the requested asset, got %v", ErrNoRoute, err)
+	}
+	if got, err := FindRoute(alice, bob, asset, big.NewInt(1), multiAsset); err != nil || !reflect.DeepEqual(got, []types.Address{irene, ivan}) {
+		t.Errorf("expected route %v in the requested asset, got %v (%v)", []types.Address{irene, ivan}, got, err)
+	}
+	if _, err := FindRoute(alice, bob, otherAsset, big.NewInt(1), multiAsset); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("expected %v when later hops do not hold the requested asset, got %v", ErrNoRoute, err)
+	}
+}
@@ -1,7 +1,11 @@
 package query
 
 import (
+	"time"
+
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -15,6 +19,89 @@ const (
 	Complete ChannelStatus = "Complete"
 )
 
+// ObjectiveStatusInfo describes the objective which is currently operating on a channel
+type ObjectiveStatusInfo struct {
+	ID protocols.ObjectiveId
+	// Type is the kind of objective, such as "DirectDefunding"
+	Type string
+	// WaitingFor is what the objective was waiting for when it was last cranked, if known
+	WaitingFor protocols.WaitingFor
+}
+
+// NoObjective is returned when no objective is operating on a channel
+var NoObjective = ObjectiveStatusInfo{}
+
+// SimulatedObjectiveInfo describes what creating a channel would do, without the channel being created
+type SimulatedObjectiveInfo struct {
+	ID        protocols.ObjectiveId
+	ChannelId types.Destination
+	// Outcome is the channel's initial outcome
+	Outcome outcome.Exit
+	// Deposit is the funds we would lock in the channel: deposited on chain for a ledger channel, or guaranteed by our ledger channel for a payment channel
+	Deposit types.Funds
+	// WaitingFor is what the objective would wait for once it had started
+	WaitingFor protocols.WaitingFor
+	// MessageRecipients are the participants who would be sent our signature on the channel's prefund state
+	MessageRecipients []types.Address
+}
+
+// CompletedObjectiveInfo describes a completed objective and the channel it operated on
+type CompletedObjectiveInfo struct {
+	ID protocols.ObjectiveId
+	// Type is the kind of objective, such as "DirectFunding"
+	Type      string
+	ChannelId types.Destination
+	// Outcome is the outcome of the channel's latest supported state when the objective completed
+	Outcome outcome.Exit
+}
+
+// ChannelFinalizationInfo describes when a challenged channel becomes finalizable on chain, so that a participant can decide whether to respond to the challenge
+type ChannelFinalizationInfo struct {
+	ID types.Destination
+	// Challenged is false if no challenge of the channel has been registered, or the latest was cleared, in which case the other fields are zero
+	Challenged bool
+	// ChallengeBlock is the block at which the latest challenge was registered
+	ChallengeBlock uint64
+	// FinalizesAt is when the channel becomes finalizable unless the challenge is cleared first: the time of the challenge plus the channel's challenge duration.
+	// It is a unix timestamp in seconds on Ethereum chains, and a block number on the mock chain.
+	FinalizesAt uint64
+}
+
+// ClosedChannelInfo summarises a ledger or payment channel which has closed, for reconciliation and reporting
+type ClosedChannelInfo struct {
+	ID           types.Destination
+	Ledger       bool // true for a ledger channel, false for a payment channel
+	Participants []types.Address
+	// OpenedAt is zero if the channel was opened before the node archived channels
+	OpenedAt time.Time
+	ClosedAt time.Time
+	// Outcome is the outcome of the channel's final state
+	Outcome outcome.Exit
+	// TotalPaid is the amount paid to the payee of a payment channel. It is nil for a ledger channel.
+	TotalPaid *hexutil.Big
+}
+
+// ClosedChannelFilter selects closed channels. Fields which are zero do not constrain the selection.
+type ClosedChannelFilter struct {
+	// Participant selects channels which include the participant
+	Participant types.Address
+	// ClosedAfter and ClosedBefore select channels which closed within the interval, inclusively
+	ClosedAfter  time.Time
+	ClosedBefore time.Time
+}
+
+// HealthInfo reports whether a node is ready to handle requests
+type HealthInfo struct {
+	// ChainConnected is true if the node's chain service can reach the chain and is subscribed to chain events
+	ChainConnected bool
+	// MessagingConnected is true if the node's message service is able to exchange messages with peers
+	MessagingConnected bool
+	// StoreOpen is true if the node's store can be read
+	StoreOpen bool
+	// Ready is true if the node is connected to the chain and to its peers, and its store is open
+	Ready bool
+}
+
 // PaymentChannelBalance contains the balance of a uni-directional payment channel
 type PaymentChannelBalance struct {
 	AssetAddress   types.Address
@@ -33,9 +120,22 @@ type PaymentChannelInfo struct {
 
 // LedgerChannelInfo contains balance and status info about a ledger channel
 type LedgerChannelInfo struct {
-	ID      types.Destination
-	Status  ChannelStatus
+	ID     types.Destination
+	Status ChannelStatus
+	// Balance is the balance of the first asset in the channel's outcome, which is the only asset for single-asset channels
 	Balance LedgerChannelBalance
+	// Balances contains the balance of every asset in the channel's outcome, in outcome order
+	Balances []LedgerChannelBalance
+}
+
+// BalanceFor returns the balance of the given asset, or false if the channel does not hold the asset.
+func (li LedgerChannelInfo) BalanceFor(asset types.Address) (LedgerChannelBalance, bool) {
+	for _, b := range li.Balances {
+		if b.AssetAddress == asset {
+			return b, true
+		}
+	}
+	return LedgerChannelBalance{}, false
 }
 
 // LedgerChannelBalance contains the balance of a ledger channel
@@ -58,7 +158,15 @@ func (lcb LedgerChannelBalance) Equal(other LedgerChannelBalance) bool {
 
 // Equal returns true if the other LedgerChannelInfo is equal to this one
 func (li LedgerChannelInfo) Equal(other LedgerChannelInfo) bool {
-	return li.ID == other.ID && li.Status == other.Status && li.Balance.Equal(other.Balance)
+	if li.ID != other.ID || li.Status != other.Status || !li.Balance.Equal(other.Balance) || len(li.Balances) != len(other.Balances) {
+		return false
+	}
+	for i := range li.Balances {
+		if !li.Balances[i].Equal(other.Balances[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 // Equal returns true if the other PaymentChannelInfo is equal to this one
@@ -0,0 +1,94 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// storeFundedAppChannel stores each participant's view of an application channel which has been funded outside of the node
+func storeFundedAppChannel(t *testing.T, s state.State, stores ...store.Store) {
+	for i, st := range stores {
+		c, err := channel.New(s, uint(i))
+		testhelpers.Ok(t, err)
+		for _, actor := range []testactors.Actor{testactors.Alice, testactors.Bob} {
+			for _, s := range []state.State{c.PreFundState(), c.PostFundState()} {
+				sig, err := s.Sign(actor.PrivateKey)
+				testhelpers.Ok(t, err)
+				testhelpers.Assert(t, c.AddStateWithSignature(s, sig), "could not add signed state")
+			}
+		}
+		c.OnChain.Holdings = s.Outcome.TotalAllocated()
+		testhelpers.Ok(t, st.SetChannel(c))
+	}
+}
+
+func TestSequentialAppUpdates(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_sequential_app_updates.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, storeA := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, storeB := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	s := state.State{
+		Participants:      []types.Address{testactors.Alice.Address(), testactors.Bob.Address()},
+		ChannelNonce:      7,
+		AppDefinition:     common.HexToAddress("0x5e29E5Ab8EF33F050c7cc10B5a0456D975C5F88d"),
+		ChallengeDuration: 60,
+		AppData:           []byte{},
+		Outcome:           td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), 10, 10, types.Address{}),
+		TurnNum:           channel.PostFundTurnNum,
+	}
+	storeFundedAppChannel(t, s, storeA, storeB)
+
+	// Alice proposes the first update and Bob proposes the second, each moving the channel on by one turn
+	updates := []struct {
+		proposer           node.Node
+		appData            []byte
+		aBalance, bBalance uint64
+	}{
+		{nodeA, []byte{1}, 5, 15},
+		{nodeB, []byte{2}, 12, 8},
+	}
+	for _, u := range updates {
+		s.TurnNum += 1
+		s.AppData = u.appData
+		s.Outcome = td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), u.aBalance, u.bBalance, types.Address{})
+
+		id, err := u.proposer.UpdateAppState(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{id})
+
+		for _, st := range []store.Store{storeA, storeB} {
+			c, ok := st.GetChannelById(s.ChannelId())
+			testhelpers.Assert(t, ok, "expected the channel to be stored")
+			latest, err := c.LatestSupportedState()
+			testhelpers.Ok(t, err)
+			testhelpers.Assert(t, latest.Equal(s), "expected the latest supported state to be the update at turn %d, got turn %d", s.TurnNum, latest.TurnNum)
+			testhelpers.Equals(t, types.Funds{types.Address{}: big.NewInt(20)}, c.OnChain.Holdings)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package node_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+)
+
+func TestChannelsInAssetsWithoutCodeAreRejected(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_asset_code_check.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeI, _ := setupNode(testactors.Irene.PrivateKey, chainservice.NewMockChainService(chain, testactors.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	testhelpers.Ok(t, nodeB.SetAssetCodeCheck(true))
+
+	// An externally owned account has no code, so it is not a token
+	eoa := testactors.Ivan.Address()
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, eoa))
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case rejection := <-nodeA.RejectedObjectives():
+		testhelpers.Equals(t, response.Id, rejection.ObjectiveId)
+		testhelpers.Equals(t, *nodeB.Address, rejection.By)
+		testhelpers.Assert(t, strings.Contains(rejection.Reason, "no code"), "expected the rejection to name the asset without code, got %q", rejection.Reason)
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected objective %s to be rejected", response.Id)
+	}
+
+	// A channel in a deployed token is approved
+	token := common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	chain.DeployCode(token)
+	openLedgerChannel(t, nodeI, nodeB, token)
+}
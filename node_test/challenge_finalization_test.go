@@ -0,0 +1,99 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestChannelFinalizationTime(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_channel_finalization_time.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	chainServiceA := chainservice.NewMockChainService(chain, ta.Alice.Address())
+	nodeA, storeA := setupNode(ta.Alice.PrivateKey, chainServiceA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	const challengeDuration = 60
+	response, err := nodeA.CreateLedgerChannel(ta.Bob.Address(), challengeDuration, initialLedgerOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-nodeA.ObjectiveCompleteChan(response.Id)
+	<-nodeB.ObjectiveCompleteChan(response.Id)
+
+	info, err := nodeA.GetChannelFinalizationTime(response.ChannelId)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, query.ChannelFinalizationInfo{ID: response.ChannelId}, info)
+
+	// Alice challenges the ledger channel with its supported state
+	ledger, err := storeA.GetConsensusChannelById(response.ChannelId)
+	testhelpers.Ok(t, err)
+	candidate := ledger.SupportedSignedState()
+	challengerSig, err := NitroAdjudicator.SignChallengeMessage(candidate.State(), ta.Alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	challengeBlock := chainServiceA.GetLastConfirmedBlockNum() + 1
+	err = chain.SubmitTransaction(protocols.NewChallengeTransaction(response.ChannelId, candidate, []state.SignedState{}, challengerSig))
+	testhelpers.Ok(t, err)
+
+	want := query.ChannelFinalizationInfo{
+		ID:             response.ChannelId,
+		Challenged:     true,
+		ChallengeBlock: challengeBlock,
+		FinalizesAt:    challengeBlock + challengeDuration,
+	}
+	nodes := []struct {
+		name string
+		get  func(types.Destination) (query.ChannelFinalizationInfo, error)
+	}{{"alice", nodeA.GetChannelFinalizationTime}, {"bob", nodeB.GetChannelFinalizationTime}}
+	for _, n := range nodes {
+		deadline := time.Now().Add(defaultTimeout)
+		for {
+			info, err = n.get(response.ChannelId)
+			testhelpers.Ok(t, err)
+			if info.Challenged || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if info != want {
+			t.Fatalf("%s: expected %+v, got %+v", n.name, want, info)
+		}
+	}
+
+	// Once the challenge is cleared, the channel no longer finalizes
+	chain.EmitChallengeCleared(response.ChannelId, challengeBlock+1)
+	want = query.ChannelFinalizationInfo{ID: response.ChannelId}
+	for _, n := range nodes {
+		deadline := time.Now().Add(defaultTimeout)
+		for {
+			info, err = n.get(response.ChannelId)
+			testhelpers.Ok(t, err)
+			if !info.Challenged || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if info != want {
+			t.Fatalf("%s: expected %+v after the challenge was cleared, got %+v", n.name, want, info)
+		}
+	}
+}
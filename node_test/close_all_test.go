@@ -0,0 +1,92 @@
+package node_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestCloseAllChannels(t *testing.T) {
+	// The simulated chain is used rather than the mock chain, since the mock gives the consensus and payment apps the same address,
+	// and so does not tell ledger channels from payment channels
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeSimulatedChain(t, sim)
+	chains := make([]chainservice.ChainService, len(ethAccounts))
+	for i, account := range ethAccounts {
+		chains[i], err = chainservice.NewSimulatedBackendChainService(sim, bindings, account)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	aliceClient, ireneClient, bobClient, cleanup := setupNitroClientsOnChain(t, "test_close_all_channels.log", chains[0], chains[1], chains[2])
+	defer cleanup()
+
+	// Alice has a payment channel through Irene, and another funded by a ledger channel with Bob
+	viaIrene := createChannelData(t, aliceClient, ireneClient, bobClient)
+
+	ledgerAB, err := aliceClient.CreateLedgerChannel(ta.Bob.Address(), 100, simpleOutcome(ta.Alice.Address(), ta.Bob.Address(), 500, 500))
+	checkError(t, err, "client.CreateLedgerChannel")
+	<-aliceClient.ObjectiveCompleteChan(ledgerAB.Id)
+	<-bobClient.ObjectiveCompleteChan(ledgerAB.Id)
+
+	direct, err := aliceClient.CreatePaymentChannel([]common.Address{}, ta.Bob.Address(), 100, simpleOutcome(ta.Alice.Address(), ta.Bob.Address(), 500, 0))
+	checkError(t, err, "client.CreatePaymentChannel")
+	<-aliceClient.ObjectiveCompleteChan(direct.Id)
+	<-bobClient.ObjectiveCompleteChan(direct.Id)
+
+	closeAll := func(client rpc.RpcClientApi, wantClosed int) {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+		defer cancel()
+
+		ids, err := client.CloseAllChannels(ctx)
+		checkError(t, err, "client.CloseAllChannels")
+		if len(ids) != wantClosed {
+			t.Fatalf("expected %d channels to be closed, got %v", wantClosed, ids)
+		}
+		for _, id := range ids {
+			select {
+			case <-client.ObjectiveCompleteChan(id):
+			case <-time.After(defaultTimeout):
+				t.Fatalf("timed out waiting for %s to complete", id)
+			}
+		}
+	}
+
+	// Alice closes both payment channels and both of her ledger channels. Irene closes her ledger channel with Bob, which leaves Bob nothing to close.
+	closeAll(aliceClient, 4)
+	closeAll(ireneClient, 1)
+	closeAll(bobClient, 0)
+
+	for _, id := range []types.Destination{viaIrene, direct.ChannelId} {
+		info, err := aliceClient.GetPaymentChannel(id)
+		checkError(t, err, "client.GetPaymentChannel")
+		if info.Status != query.Complete {
+			t.Errorf("expected payment channel %s to be closed, got %s", id, info.Status)
+		}
+	}
+	for _, client := range []rpc.RpcClientApi{aliceClient, ireneClient, bobClient} {
+		ledgers, err := client.GetAllLedgerChannels()
+		checkError(t, err, "client.GetAllLedgerChannels")
+		for _, ledger := range ledgers {
+			if ledger.Status != query.Complete {
+				t.Errorf("expected ledger channel %s to be closed, got %s", ledger.ID, ledger.Status)
+			}
+		}
+	}
+
+	// Once everything is closed there is nothing left to close
+	if ids, err := aliceClient.CloseAllChannels(context.Background()); err != nil || len(ids) != 0 {
+		t.Errorf("expected closing all channels again to close nothing, got %v, %v", ids, err)
+	}
+}
@@ -0,0 +1,63 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestCloseLedgerChannelWithActivePaymentChannel(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_close_ledger_with_payment_channel.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeI, _ := setupNode(testactors.Irene.PrivateKey, chainservice.NewMockChainService(chain, testactors.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+
+	ledgerAI := openLedgerChannel(t, nodeA, nodeI, types.Address{})
+	ledgerIB := openLedgerChannel(t, nodeI, nodeB, types.Address{})
+
+	outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), virtualChannelDeposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{testactors.Irene.Address()}, testactors.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{response.Id})
+
+	// Neither ledger can be closed while it funds the payment channel
+	if _, err := nodeA.CloseLedgerChannel(ledgerAI); !errors.Is(err, directdefund.ErrNotEmpty) {
+		t.Fatalf("expected closing a ledger funding a payment channel to fail with %v, got %v", directdefund.ErrNotEmpty, err)
+	}
+	if _, err := nodeI.CloseLedgerChannel(ledgerIB); !errors.Is(err, directdefund.ErrNotEmpty) {
+		t.Fatalf("expected closing a ledger funding a payment channel to fail with %v, got %v", directdefund.ErrNotEmpty, err)
+	}
+
+	// Once the payment channel is closed the ledgers can be closed
+	closeId, err := nodeA.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{closeId})
+
+	closeLedgerChannel(t, nodeA, nodeI, ledgerAI)
+	closeLedgerChannel(t, nodeI, nodeB, ledgerIB)
+}
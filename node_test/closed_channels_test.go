@@ -0,0 +1,96 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestClosedChannelsAreArchived(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_closed_channels_are_archived.log", slog.LevelDebug)
+
+	const (
+		deposit = 100
+		paid    = 30
+	)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), deposit, 0, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+
+	// Open channels are not listed
+	for _, n := range []node.Node{nodeA, nodeB} {
+		closed, err := n.GetClosedChannels(query.ClosedChannelFilter{})
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 0, len(closed))
+	}
+
+	nodeA.Pay(response.ChannelId, big.NewInt(paid))
+	<-nodeB.ReceivedVouchers()
+
+	closeId, err := nodeA.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{closeId})
+	closeLedgerChannel(t, nodeA, nodeB, ledgerId)
+
+	participants := []types.Address{testactors.Alice.Address(), testactors.Bob.Address()}
+	for _, n := range []node.Node{nodeA, nodeB} {
+		closed, err := n.GetClosedChannels(query.ClosedChannelFilter{})
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 2, len(closed))
+
+		// The payment channel closed first, having paid bob
+		payment, ledger := closed[0], closed[1]
+		testhelpers.Equals(t, response.ChannelId, payment.ID)
+		testhelpers.Assert(t, !payment.Ledger, "expected the first channel to close to be the payment channel")
+		testhelpers.Equals(t, participants, payment.Participants)
+		testhelpers.Assert(t, payment.TotalPaid != nil && payment.TotalPaid.ToInt().Cmp(big.NewInt(paid)) == 0, "expected a total of %d to have been paid, got %v", paid, payment.TotalPaid)
+		testhelpers.Assert(t, payment.Outcome.Equal(td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), deposit-paid, paid, types.Address{})), "unexpected final outcome of the payment channel %v", payment.Outcome)
+		testhelpers.Assert(t, !payment.OpenedAt.IsZero() && payment.OpenedAt.Before(payment.ClosedAt), "expected the payment channel to have opened at %v, before it closed at %v", payment.OpenedAt, payment.ClosedAt)
+
+		// The ledger channel closed with the payment settled into bob's balance
+		testhelpers.Equals(t, ledgerId, ledger.ID)
+		testhelpers.Assert(t, ledger.Ledger, "expected the second channel to close to be the ledger channel")
+		testhelpers.Equals(t, participants, ledger.Participants)
+		testhelpers.Assert(t, ledger.TotalPaid == nil, "expected no payments to be recorded for a ledger channel, got %v", ledger.TotalPaid)
+		testhelpers.Assert(t, ledger.Outcome.Equal(td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), ledgerChannelDeposit-paid, ledgerChannelDeposit+paid, types.Address{})), "unexpected final outcome of the ledger channel %v", ledger.Outcome)
+		testhelpers.Assert(t, !ledger.OpenedAt.IsZero() && ledger.OpenedAt.Before(payment.OpenedAt), "expected the ledger channel to have opened before the payment channel")
+
+		// Channels can be selected by when they closed and who they include
+		afterPayment, err := n.GetClosedChannels(query.ClosedChannelFilter{ClosedAfter: ledger.ClosedAt})
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 1, len(afterPayment))
+		testhelpers.Equals(t, ledgerId, afterPayment[0].ID)
+		withIrene, err := n.GetClosedChannels(query.ClosedChannelFilter{Participant: testactors.Irene.Address()})
+		testhelpers.Ok(t, err)
+		testhelpers.Equals(t, 0, len(withIrene))
+	}
+}
@@ -0,0 +1,47 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestCompletedObjectiveDetails(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_completed_objective_details.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+
+	select {
+	case info := <-nodeA.CompletedObjectiveDetails():
+		if info.ChannelId != ledgerId {
+			t.Errorf("expected the completed objective to report channel %s, got %s", ledgerId, info.ChannelId)
+		}
+		if string(info.ID) != directfund.ObjectivePrefix+ledgerId.String() || info.Type != "DirectFunding" {
+			t.Errorf("expected the direct fund objective to complete, got %s of type %s", info.ID, info.Type)
+		}
+		if want := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}); !info.Outcome.Equal(want) {
+			t.Errorf("incorrect final outcome:\n%s", info.Outcome.Diff(want))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the details of the completed objective to be reported")
+	}
+}
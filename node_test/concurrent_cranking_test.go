@@ -0,0 +1,94 @@
+package node_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// blockingChainService blocks the first deposit it is asked to submit until it is released.
+type blockingChainService struct {
+	*chainservice.MockChainService
+
+	once          sync.Once
+	blocked       chan types.Destination // receives the id of the channel whose deposit is blocked
+	release       chan struct{}
+	blockedTxDone chan struct{}
+}
+
+func (bcs *blockingChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	if _, isDeposit := tx.(protocols.DepositTransaction); isDeposit {
+		block := false
+		bcs.once.Do(func() { block = true })
+		if block {
+			bcs.blocked <- tx.ChannelId()
+			<-bcs.release
+			defer close(bcs.blockedTxDone)
+		}
+	}
+	return bcs.MockChainService.SendTransaction(tx)
+}
+
+func TestBlockedObjectiveDoesNotStallOthers(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	chainA := &blockingChainService{
+		MockChainService: chainservice.NewMockChainService(chain, testactors.Alice.Address()),
+		blocked:          make(chan types.Destination, 1),
+		release:          make(chan struct{}),
+		blockedTxDone:    make(chan struct{}),
+	}
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	nodeI, _ := setupNode(testactors.Irene.PrivateKey, chainservice.NewMockChainService(chain, testactors.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	defer closeNode(t, &nodeB)
+	defer closeNode(t, &nodeI)
+
+	responseB, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseI, err := nodeA.CreateLedgerChannel(*nodeI.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeI.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockedChannel := <-chainA.blocked
+	blockedObjective, freeObjective := responseB.Id, responseI.Id
+	if blockedChannel == responseI.ChannelId {
+		blockedObjective, freeObjective = responseI.Id, responseB.Id
+	}
+
+	select {
+	case <-nodeA.ObjectiveCompleteChan(freeObjective):
+	case <-time.After(5 * time.Second):
+		t.Fatal("objective did not complete while another objective was blocked on a chain transaction")
+	}
+
+	select {
+	case <-nodeA.ObjectiveCompleteChan(blockedObjective):
+		t.Fatal("objective completed while its deposit was blocked")
+	default:
+	}
+
+	close(chainA.release)
+	<-chainA.blockedTxDone
+
+	select {
+	case <-nodeA.ObjectiveCompleteChan(blockedObjective):
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked objective did not complete once released")
+	}
+}
@@ -49,7 +49,7 @@ func TestCrashTolerance(t *testing.T) {
 		t.Fatal(err)
 	}
 	messageserviceA := messageservice.NewTestMessageService(ta.Alice.Address(), broker, 0)
-	nodeA := node.New(messageserviceA, chainA, storeA, &engine.PermissivePolicy{})
+	nodeA := node.New(messageserviceA, chainA, storeA, &engine.PermissivePolicy{}, nil, nil)
 
 	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
 	defer closeNode(t, &nodeB)
@@ -75,7 +75,7 @@ func TestCrashTolerance(t *testing.T) {
 		anotherClientA := node.New(
 			anotherMessageserviceA,
 			anotherChainA,
-			anotherStoreA, &engine.PermissivePolicy{})
+			anotherStoreA, &engine.PermissivePolicy{}, nil, nil)
 		defer closeNode(t, &anotherClientA)
 
 		closeLedgerChannel(t, anotherClientA, nodeB, channelId)
@@ -0,0 +1,77 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+)
+
+func TestERC20LedgerChannel(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_erc20_ledger_channel.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeSimulatedChain(t, sim)
+
+	chainA, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainB, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainB, broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	token := bindings.Token.Address
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, token)
+
+	// The ledger is funded with the token rather than with ETH
+	holdings, err := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, token, ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewInt(2 * ledgerChannelDeposit); holdings.Cmp(want) != 0 {
+		t.Fatalf("expected token holdings of %s, got %s", want, holdings)
+	}
+	ethHoldings, err := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, [20]byte{}, ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ethHoldings.Sign() != 0 {
+		t.Fatalf("expected no ETH holdings, got %s", ethHoldings)
+	}
+
+	// Queries report the token as the ledger's asset
+	checkLedgerChannel(t, ledgerId, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, token), query.Open, nodeA, nodeB)
+
+	// Defunding pays out the token to each participant
+	closeLedgerChannel(t, nodeA, nodeB, ledgerId)
+	for _, participant := range []testactors.Actor{testactors.Alice, testactors.Bob} {
+		balance, err := bindings.Token.Contract.BalanceOf(&bind.CallOpts{}, participant.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := big.NewInt(ledgerChannelDeposit); balance.Cmp(want) != 0 {
+			t.Fatalf("expected %s to receive %s tokens, got %s", participant.Name, want, balance)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestUnreadEventsDoNotStallTheEngine(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_unread_events.log", slog.LevelDebug)
+
+	const payments = 5
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	// Nobody reads Bob's received vouchers or completed objectives, which hold a single event each
+	config := node.DefaultEventsConfig()
+	config.ReceivedVouchers = node.EventChannelConfig{Size: 1, Overflow: node.DropOldest}
+	config.CompletedObjectives = node.EventChannelConfig{Size: 1, Overflow: node.DropOldest}
+	nodeB.ConfigureEvents(config)
+
+	openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), 100, 0, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+
+	for i := 0; i < payments; i++ {
+		nodeA.Pay(response.ChannelId, big.NewInt(1))
+	}
+
+	// Bob's engine keeps handling payments although nobody reads its events, dropping all but the last voucher it received
+	deadline := time.After(defaultTimeout)
+	for {
+		info, err := nodeB.GetPaymentChannel(response.ChannelId)
+		testhelpers.Ok(t, err)
+		if info.Balance.PaidSoFar.ToInt().Cmp(big.NewInt(payments)) == 0 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for bob to receive %d payments: received %s", payments, info.Balance.PaidSoFar.ToInt())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	dropped := nodeB.DroppedEvents()
+	// Two objectives completed, the ledger and payment channels' funding, so one was dropped
+	testhelpers.Equals(t, uint64(1), dropped.CompletedObjectives)
+
+	// The chan holds the voucher received last, which is for the whole amount paid.
+	// Vouchers which arrived after a larger one are stale and are not reported, so fewer than all the others may have been dropped.
+	select {
+	case v := <-nodeB.ReceivedVouchers():
+		testhelpers.Assert(t, v.Amount.Cmp(big.NewInt(payments)) == 0, "expected the last voucher to be for %d, got %s", payments, v.Amount)
+	case <-time.After(defaultTimeout):
+		t.Fatal("expected bob's chan to hold the last voucher")
+	}
+	testhelpers.Equals(t, 0, len(nodeB.ReceivedVouchers()))
+}
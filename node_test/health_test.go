@@ -0,0 +1,88 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/tidwall/buntdb"
+)
+
+// flakyChainService is a chain service whose connection to the chain can be dropped, hung and restored
+type flakyChainService struct {
+	*chainservice.MockChainService
+	disconnected atomic.Bool
+	hung         atomic.Bool
+}
+
+func (f *flakyChainService) GetChainId() (*big.Int, error) {
+	if f.hung.Load() {
+		select {}
+	}
+	if f.disconnected.Load() {
+		return nil, errors.New("not connected to chain")
+	}
+	return f.MockChainService.GetChainId()
+}
+
+// flakyMessageService is a message service whose connection to its peers can be dropped and restored
+type flakyMessageService struct {
+	messageservice.TestMessageService
+	disconnected atomic.Bool
+}
+
+func (f *flakyMessageService) Connected() bool {
+	return !f.disconnected.Load()
+}
+
+func TestHealth(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_health.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+	cs := &flakyChainService{MockChainService: chainservice.NewMockChainService(chain, testactors.Alice.Address())}
+	ms := &flakyMessageService{TestMessageService: messageservice.NewTestMessageService(testactors.Alice.Address(), broker, 0)}
+	s, err := store.NewDurableStore(testactors.Alice.PrivateKey, dataFolder, buntdb.Config{})
+	testhelpers.Ok(t, err)
+	nodeA := node.New(ms, cs, s, &engine.PermissivePolicy{}, nil, nil)
+	defer closeNode(t, &nodeA)
+
+	cs.disconnected.Store(true)
+	if got, want := nodeA.Health(), (query.HealthInfo{ChainConnected: false, MessagingConnected: true, StoreOpen: true, Ready: false}); got != want {
+		t.Errorf("expected health %+v while disconnected from the chain, got %+v", want, got)
+	}
+
+	cs.disconnected.Store(false)
+	if got, want := nodeA.Health(), (query.HealthInfo{ChainConnected: true, MessagingConnected: true, StoreOpen: true, Ready: true}); got != want {
+		t.Errorf("expected health %+v once connected to the chain, got %+v", want, got)
+	}
+
+	ms.disconnected.Store(true)
+	if got, want := nodeA.Health(), (query.HealthInfo{ChainConnected: true, MessagingConnected: false, StoreOpen: true, Ready: false}); got != want {
+		t.Errorf("expected health %+v while disconnected from peers, got %+v", want, got)
+	}
+	ms.disconnected.Store(false)
+
+	// A chain which does not answer is reported as disconnected once the health check times out
+	defer func(timeout time.Duration) { node.HealthCheckTimeout = timeout }(node.HealthCheckTimeout)
+	node.HealthCheckTimeout = 50 * time.Millisecond
+	cs.hung.Store(true)
+	if got, want := nodeA.Health(), (query.HealthInfo{ChainConnected: false, MessagingConnected: true, StoreOpen: true, Ready: false}); got != want {
+		t.Errorf("expected health %+v while the chain hangs, got %+v", want, got)
+	}
+}
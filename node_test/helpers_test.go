@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"math/big"
+	"os"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/statechannels/go-nitro/internal/logging"
 	"github.com/statechannels/go-nitro/internal/testactors"
 	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
 	"github.com/statechannels/go-nitro/node"
 	"github.com/statechannels/go-nitro/node/engine"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
@@ -23,10 +25,29 @@ import (
 	"github.com/statechannels/go-nitro/node/engine/store"
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/rand"
 	"github.com/statechannels/go-nitro/types"
 	"github.com/tidwall/buntdb"
 )
 
+// testSeed seeds the generators of the nonces used by the nodes under test.
+// It is printed when a test fails, and can be fixed with testhelpers.SeedEnvVar to replay the failing run.
+var testSeed = testhelpers.Seed()
+
+// testRand returns a generator for the node or client with the given label, seeded from testSeed.
+// Each gets its own generator, since the nodes of a test run concurrently.
+func testRand(label string) rand.Generator {
+	return rand.NewSeeded(rand.DeriveSeed(testSeed, label))
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if code != 0 {
+		fmt.Printf("random seed was %d, set %s=%d to replay\n", testSeed, testhelpers.SeedEnvVar, testSeed)
+	}
+	os.Exit(code)
+}
+
 // setupNode is a helper function that constructs a nitro node and returns the new node and its store.
 func setupNode(pk []byte, chain chainservice.ChainService, msgBroker messageservice.Broker, meanMessageDelay time.Duration, dataFolder string) (node.Node, store.Store) {
 	myAddress := crypto.GetAddressFromSecretKeyBytes(pk)
@@ -36,7 +57,9 @@ func setupNode(pk []byte, chain chainservice.ChainService, msgBroker messageserv
 	if err != nil {
 		panic(err)
 	}
-	return node.New(messageservice, chain, storeA, &engine.PermissivePolicy{}), storeA
+	n := node.New(messageservice, chain, storeA, &engine.PermissivePolicy{}, nil, nil)
+	n.SetRandomness(testRand(myAddress.Hex()))
+	return n, storeA
 }
 
 func closeNode(t *testing.T, node *node.Node) {
@@ -120,7 +143,8 @@ func setupIntegrationNode(tc TestCase, tp TestParticipant, si sharedTestInfrastr
 	messageService, multiAddr := setupMessageService(tc, tp, si, bootPeers)
 	cs := setupChainService(tc, tp, si)
 	store := setupStore(tc, tp, si, dataFolder)
-	n := node.New(messageService, cs, store, &engine.PermissivePolicy{})
+	n := node.New(messageService, cs, store, &engine.PermissivePolicy{}, nil, nil)
+	n.SetRandomness(testRand(string(tp.Name)))
 	return n, messageService, multiAddr
 }
 
@@ -246,42 +270,48 @@ func checkPaymentChannel(t *testing.T, id types.Destination, o outcome.Exit, sta
 
 // createLedgerInfo constructs a LedgerChannelInfo so we can easily compare it to the result of GetLedgerChannel
 func createLedgerInfo(id types.Destination, outcome outcome.Exit, status query.ChannelStatus, user types.Address) query.LedgerChannelInfo {
-	firstParticipant, err := outcome[0].Allocations[0].Destination.ToAddress()
-	if err != nil {
-		panic(err)
-	}
-	secondParticipant, err := outcome[0].Allocations[1].Destination.ToAddress()
-	if err != nil {
-		panic(err)
-	}
+	balances := make([]query.LedgerChannelBalance, len(outcome))
+	for i, sae := range outcome {
+		firstParticipant, err := sae.Allocations[0].Destination.ToAddress()
+		if err != nil {
+			panic(err)
+		}
+		secondParticipant, err := sae.Allocations[1].Destination.ToAddress()
+		if err != nil {
+			panic(err)
+		}
 
-	var me, them types.Address
-	var myBalance, theirBalance *big.Int
-
-	if user == firstParticipant {
-		me = firstParticipant
-		myBalance = outcome[0].Allocations[0].Amount
-		them = secondParticipant
-		theirBalance = outcome[0].Allocations[1].Amount
-	} else if user == secondParticipant {
-		me = secondParticipant
-		myBalance = outcome[0].Allocations[1].Amount
-		them = firstParticipant
-		theirBalance = outcome[0].Allocations[0].Amount
-	} else {
-		panic("User not in channel") // test helper - panic OK
-	}
+		var me, them types.Address
+		var myBalance, theirBalance *big.Int
+
+		if user == firstParticipant {
+			me = firstParticipant
+			myBalance = sae.Allocations[0].Amount
+			them = secondParticipant
+			theirBalance = sae.Allocations[1].Amount
+		} else if user == secondParticipant {
+			me = secondParticipant
+			myBalance = sae.Allocations[1].Amount
+			them = firstParticipant
+			theirBalance = sae.Allocations[0].Amount
+		} else {
+			panic("User not in channel") // test helper - panic OK
+		}
 
-	return query.LedgerChannelInfo{
-		ID:     id,
-		Status: status,
-		Balance: query.LedgerChannelBalance{
-			AssetAddress: types.Address{},
+		balances[i] = query.LedgerChannelBalance{
+			AssetAddress: sae.Asset,
 			Me:           me,
 			Them:         them,
 			MyBalance:    (*hexutil.Big)(myBalance),
 			TheirBalance: (*hexutil.Big)(theirBalance),
-		},
+		}
+	}
+
+	return query.LedgerChannelInfo{
+		ID:       id,
+		Status:   status,
+		Balance:  balances[0],
+		Balances: balances,
 	}
 }
 
@@ -345,7 +375,7 @@ func createPaychInfo(id types.Destination, outcome outcome.Exit, status query.Ch
 		ID:     id,
 		Status: status,
 		Balance: query.PaymentChannelBalance{
-			AssetAddress:   types.Address{},
+			AssetAddress:   outcome[0].Asset,
 			Payee:          payee,
 			Payer:          payer,
 			RemainingFunds: (*hexutil.Big)(outcome[0].Allocations[0].Amount),
@@ -0,0 +1,110 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+func TestIdlePaymentChannelIsClosed(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_idle_payment_channel.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	const idleTimeout = 300 * time.Millisecond
+	storeA := store.NewMemStore(testactors.Alice.PrivateKey)
+	msgA := messageservice.NewTestMessageService(crypto.GetAddressFromSecretKeyBytes(testactors.Alice.PrivateKey), broker, 0)
+	nodeA := node.New(msgA, chainservice.NewMockChainService(chain, testactors.Alice.Address()), storeA, &engine.AutoClosePolicy{Timeout: idleTimeout}, nil, nil)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+
+	outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), virtualChannelDeposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+
+	closeId := virtualdefund.NewObjectiveRequest(response.ChannelId).Id(testactors.Alice.Address(), nil)
+	closed := nodeA.ObjectiveCompleteChan(closeId)
+
+	// Paying more often than the timeout keeps the channel open
+	for i := 0; i < 6; i++ {
+		nodeA.Pay(response.ChannelId, big.NewInt(1))
+		select {
+		case <-closed:
+			t.Fatal("expected a payment channel in use not to be closed")
+		case <-time.After(idleTimeout / 3):
+		}
+	}
+
+	// Once payments stop, the channel is closed
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{closeId})
+
+	closeLedgerChannel(t, nodeA, nodeB, ledgerId)
+}
+
+func TestIdlePaymentChannelIsClosedAfterRestart(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_idle_payment_channel_restart.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	const idleTimeout = 300 * time.Millisecond
+	startAlice := func() node.Node {
+		storeA, err := store.NewDurableStore(testactors.Alice.PrivateKey, dataFolder, buntdb.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgA := messageservice.NewTestMessageService(testactors.Alice.Address(), broker, 0)
+		return node.New(msgA, chainservice.NewMockChainService(chain, testactors.Alice.Address()), storeA, &engine.AutoClosePolicy{Timeout: idleTimeout}, nil, nil)
+	}
+	nodeA := startAlice()
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), virtualChannelDeposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+
+	// Alice stops before the channel falls idle, and restarts after it has
+	closeNode(t, &nodeA)
+	time.Sleep(idleTimeout)
+	nodeA = startAlice()
+	defer closeNode(t, &nodeA)
+
+	closeId := virtualdefund.NewObjectiveRequest(response.ChannelId).Id(testactors.Alice.Address(), nil)
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{closeId})
+
+	closeLedgerChannel(t, nodeA, nodeB, ledgerId)
+}
@@ -166,9 +166,16 @@ func RunIntegrationTestCase(tc TestCase, t *testing.T) {
 			}
 		}
 
-		// Wait for all the vouchers to be received by bob
-		for i := 0; i < len(virtualIds)*int(tc.NumOfPayments); i++ {
-			<-clientB.ReceivedVouchers()
+		// Wait for bob to receive the last voucher of each channel. Earlier vouchers which arrive after it are stale, and are not reported.
+		unpaid := map[types.Destination]bool{}
+		for _, id := range virtualIds {
+			unpaid[id] = true
+		}
+		for len(unpaid) > 0 {
+			v := <-clientB.ReceivedVouchers()
+			if v.Amount.Cmp(big.NewInt(int64(tc.NumOfPayments))) == 0 {
+				delete(unpaid, v.ChannelId)
+			}
 		}
 
 		// Check the payment channels have the correct outcome after the payments
@@ -226,8 +233,9 @@ func RunIntegrationTestCase(tc TestCase, t *testing.T) {
 			chainLastConfirmedBlockNum = latestBlock.NumberU64() - chainservice.REQUIRED_BLOCK_CONFIRMATIONS
 		}
 
-		waitForClientBlockNum(t, clientA, chainLastConfirmedBlockNum, 10*time.Second)
-		waitForClientBlockNum(t, clientB, chainLastConfirmedBlockNum, 10*time.Second)
+		// Chain events for other nodes' channels are dropped, so the clients may only record the block num on the engine's next periodic save
+		waitForClientBlockNum(t, clientA, chainLastConfirmedBlockNum, 20*time.Second)
+		waitForClientBlockNum(t, clientB, chainLastConfirmedBlockNum, 20*time.Second)
 	})
 }
 
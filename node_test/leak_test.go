@@ -0,0 +1,56 @@
+package node_test
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestClosedNodesLeaveNoGoroutinesRunning(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+
+	openLedgerChannel(t, nodeA, nodeB, types.Address{})
+
+	closeNode(t, &nodeA)
+	closeNode(t, &nodeB)
+	// Closing a node again is harmless
+	closeNode(t, &nodeA)
+
+	// Ranging over the node's events terminates once it is closed
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range nodeA.CompletedObjectives() {
+		}
+		for range nodeA.CompletedObjectiveDetails() {
+		}
+		for range nodeA.ObjectiveProgress() {
+		}
+		for range nodeA.FailedObjectives() {
+		}
+		for range nodeA.ReceivedVouchers() {
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(defaultTimeout):
+		t.Fatal("expected the closed node's event channels to be closed")
+	}
+}
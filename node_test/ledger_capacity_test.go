@@ -0,0 +1,71 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestPaymentChannelsBeyondLedgerCapacityFailFast(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_ledger_capacity.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+	nodeI, _ := setupNode(testactors.Irene.PrivateKey, chainservice.NewMockChainService(chain, testactors.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeI, types.Address{})
+	openLedgerChannel(t, nodeI, nodeB, types.Address{})
+
+	testCases := []struct {
+		name        string
+		aliceAmount uint64
+		bobAmount   uint64
+		want        string
+	}{
+		{"alice over-allocates", ledgerChannelDeposit + 1, 0, "need 5000001, have 5000000"},
+		{"irene cannot guarantee bob's allocation", 0, ledgerChannelDeposit + 1, "need 5000001, have 5000000"},
+	}
+	for _, tc := range testCases {
+		outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), tc.aliceAmount, tc.bobAmount, types.Address{})
+		_, err := nodeA.CreatePaymentChannel([]types.Address{testactors.Irene.Address()}, testactors.Bob.Address(), 0, outcome)
+		if !errors.Is(err, virtualfund.ErrInsufficientCapacity) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, virtualfund.ErrInsufficientCapacity, err)
+		}
+		testhelpers.Assert(t, strings.Contains(err.Error(), tc.want), "%s: expected the error to give the capacity, got %q", tc.name, err)
+	}
+
+	// No objective was started, so the ledger channel is untouched and funds no payment channels
+	channels, err := nodeA.GetPaymentChannelsByLedger(ledgerId)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, 0, len(channels))
+	ledger, err := nodeA.GetLedgerChannel(ledgerId)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, uint64(ledgerChannelDeposit), ledger.Balance.MyBalance.ToInt().Uint64())
+
+	// A payment channel within the ledger's capacity is funded
+	outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), ledgerChannelDeposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{testactors.Irene.Address()}, testactors.Bob.Address(), 0, outcome)
+	testhelpers.Ok(t, err)
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{response.Id})
+}
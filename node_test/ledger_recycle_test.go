@@ -0,0 +1,137 @@
+package node_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestRecycleLedgerChannel(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_recycle_ledger_channel.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	transactions := len(chain.SubmittedTransactions())
+
+	// Alice's funds are recycled to bob, keeping the same total
+	const recycled = ledgerChannelDeposit / 2
+	recycledOutcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), ledgerChannelDeposit-recycled, ledgerChannelDeposit+recycled, types.Address{})
+	id, err := nodeA.RecycleLedgerChannel(ledgerId, recycledOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{id})
+	checkLedgerChannel(t, ledgerId, recycledOutcome, query.Open, nodeA, nodeB)
+
+	// The holdings already covered the recycled outcome, so nothing was deposited or withdrawn
+	testhelpers.Equals(t, transactions, len(chain.SubmittedTransactions()))
+
+	// An outcome with a different total cannot be recycled into the channel
+	id, err = nodeB.RecycleLedgerChannel(ledgerId, td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit+recycled, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case failed := <-nodeB.FailedObjectives():
+		testhelpers.Equals(t, id, failed)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected objective %s to fail", id)
+	}
+	checkLedgerChannel(t, ledgerId, recycledOutcome, query.Open, nodeA, nodeB)
+
+	// The recycled channel can still fund payment channels
+	outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), virtualChannelDeposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+
+	closeId, err := nodeA.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{closeId})
+
+	closeLedgerChannel(t, nodeA, nodeB, ledgerId)
+}
+
+// reallocatingPolicy approves every unapproved objective, including recycles which lower our allocation
+type reallocatingPolicy struct {
+	engine.PermissivePolicy
+}
+
+func (rp *reallocatingPolicy) ShouldApproveReallocation(o protocols.Objective) (bool, string) {
+	return true, ""
+}
+
+func TestRecycleWhichLowersOurAllocation(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_recycle_which_lowers_our_allocation.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	initialOutcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit, types.Address{})
+
+	// Bob proposes taking half of alice's funds, which a permissive policy does not approve
+	const recycled = ledgerChannelDeposit / 2
+	recycledOutcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), ledgerChannelDeposit-recycled, ledgerChannelDeposit+recycled, types.Address{})
+	id, err := nodeB.RecycleLedgerChannel(ledgerId, recycledOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []node.Node{nodeA, nodeB} {
+		select {
+		case rejection := <-n.RejectedObjectives():
+			testhelpers.Equals(t, id, rejection.ObjectiveId)
+			if !strings.Contains(rejection.Reason, "lowers our allocation") {
+				t.Errorf("expected the recycle to be rejected for lowering alice's allocation, got %q", rejection.Reason)
+			}
+		case <-time.After(defaultTimeout):
+			t.Fatalf("timed out waiting for objective %s to be rejected", id)
+		}
+	}
+	// Bob takes back governance of the ledger channel once he hears of the rejection
+	checkLedgerChannel(t, ledgerId, initialOutcome, query.Open, nodeA, nodeB)
+
+	// Once alice's policy agrees to reallocations, the same recycle goes through
+	nodeA.SetPolicy(&reallocatingPolicy{})
+	id, err = nodeB.RecycleLedgerChannel(ledgerId, recycledOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{id})
+	checkLedgerChannel(t, ledgerId, recycledOutcome, query.Open, nodeA, nodeB)
+}
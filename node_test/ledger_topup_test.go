@@ -0,0 +1,190 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestTopUpLedgerChannel(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_top_up_ledger_channel.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+
+	const aliceTopUp, bobTopUp = 1_000, 2_000
+
+	// The leader tops up the channel
+	id, err := nodeA.TopUpLedgerChannel(ledgerId, big.NewInt(aliceTopUp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{id})
+	checkLedgerChannel(t, ledgerId,
+		td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), ledgerChannelDeposit+aliceTopUp, ledgerChannelDeposit, types.Address{}),
+		query.Open, nodeA, nodeB)
+
+	// The follower tops up the channel
+	id, err = nodeB.TopUpLedgerChannel(ledgerId, big.NewInt(bobTopUp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{id})
+	checkLedgerChannel(t, ledgerId,
+		td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), ledgerChannelDeposit+aliceTopUp, ledgerChannelDeposit+bobTopUp, types.Address{}),
+		query.Open, nodeA, nodeB)
+
+	// The topped up channel can still fund payment channels
+	outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), virtualChannelDeposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+
+	closeId, err := nodeA.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{closeId})
+
+	closeLedgerChannel(t, nodeA, nodeB, ledgerId)
+}
+
+// topUpRejectingPolicy approves every objective but ledger top ups
+type topUpRejectingPolicy struct {
+	engine.PermissivePolicy
+}
+
+func (p *topUpRejectingPolicy) ShouldApprove(o protocols.Objective) (bool, string) {
+	if _, ok := o.(*ledgertopup.Objective); ok {
+		return false, "top ups are not accepted"
+	}
+	return true, ""
+}
+
+func TestRejectedTopUpLeavesTheLedgerChannelUsable(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_rejected_top_up.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	initialOutcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit, types.Address{})
+
+	nodeB.SetPolicy(&topUpRejectingPolicy{})
+	id, err := nodeA.TopUpLedgerChannel(ledgerId, big.NewInt(1_000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []node.Node{nodeA, nodeB} {
+		select {
+		case rejection := <-n.RejectedObjectives():
+			testhelpers.Equals(t, id, rejection.ObjectiveId)
+		case <-time.After(defaultTimeout):
+			t.Fatalf("timed out waiting for objective %s to be rejected", id)
+		}
+	}
+
+	// Alice takes back governance of the ledger channel once she hears of the rejection, so it can still be defunded
+	checkLedgerChannel(t, ledgerId, initialOutcome, query.Open, nodeA, nodeB)
+	closeLedgerChannel(t, nodeA, nodeB, ledgerId)
+}
+
+// depositDroppingChainService drops every deposit transaction while dropping is set, as if none of them landed
+type depositDroppingChainService struct {
+	*chainservice.MockChainService
+	dropping atomic.Bool
+}
+
+func (dcs *depositDroppingChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	if _, isDeposit := tx.(protocols.DepositTransaction); isDeposit && dcs.dropping.Load() {
+		return nil
+	}
+	return dcs.MockChainService.SendTransaction(tx)
+}
+
+func TestTopUpIsCancellableUntilTheDepositLands(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_cancel_top_up.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	chainServiceA := &depositDroppingChainService{MockChainService: chainservice.NewMockChainService(chain, testactors.Alice.Address())}
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainServiceA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	// The ledger channel is funded before alice's deposits start being dropped
+	ledgerId := openLedgerChannel(t, nodeB, nodeA, types.Address{})
+	initialOutcome := td.Outcomes.Create(testactors.Bob.Address(), testactors.Alice.Address(), ledgerChannelDeposit, ledgerChannelDeposit, types.Address{})
+
+	// Alice's deposit never lands, so bob is left waiting for it
+	chainServiceA.dropping.Store(true)
+	id, err := nodeA.TopUpLedgerChannel(ledgerId, big.NewInt(1_000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nodeA.CancelObjective(id); !errors.Is(err, protocols.ErrNotCancellable) {
+		t.Fatalf("expected the depositor to be unable to cancel once its deposit is submitted, got %v", err)
+	}
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		err := nodeB.CancelObjective(id)
+		if err == nil {
+			break
+		}
+		// Bob may not have heard of the top up yet
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case rejection := <-nodeA.RejectedObjectives():
+		testhelpers.Equals(t, id, rejection.ObjectiveId)
+	case <-time.After(defaultTimeout):
+		t.Fatalf("timed out waiting for objective %s to be rejected", id)
+	}
+
+	checkLedgerChannel(t, ledgerId, initialOutcome, query.Open, nodeA, nodeB)
+	closeLedgerChannel(t, nodeB, nodeA, ledgerId)
+}
@@ -0,0 +1,60 @@
+package node_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestTotalAvailableLiquidity(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	expectLiquidity := func(n node.Node, asset types.Address, want int64) {
+		t.Helper()
+		got, err := n.TotalAvailableLiquidity(asset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("expected %s to have %d of asset %s available, got %s", n.Address, want, asset, got)
+		}
+	}
+
+	asset := types.Address{}
+	expectLiquidity(nodeI, asset, 0)
+
+	openLedgerChannel(t, nodeA, nodeI, asset)
+	openLedgerChannel(t, nodeI, nodeB, asset)
+	expectLiquidity(nodeI, asset, 2*ledgerChannelDeposit)
+
+	response, err := nodeA.CreatePaymentChannel([]types.Address{*nodeI.Address}, *nodeB.Address, 0, initialPaymentOutcome(*nodeA.Address, *nodeB.Address, asset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{response.Id})
+
+	// The intermediary guarantees the payer's deposit in its ledger channel with the payee
+	expectLiquidity(nodeI, asset, 2*ledgerChannelDeposit-virtualChannelDeposit)
+	expectLiquidity(nodeA, asset, ledgerChannelDeposit-virtualChannelDeposit)
+	expectLiquidity(nodeB, asset, ledgerChannelDeposit)
+	expectLiquidity(nodeI, common.HexToAddress("0x01"), 0)
+}
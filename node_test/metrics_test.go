@@ -0,0 +1,91 @@
+package node_test
+
+import (
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/metrics"
+	interRpc "github.com/statechannels/go-nitro/internal/rpc"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	httpTransport "github.com/statechannels/go-nitro/rpc/transport/http"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_metrics_endpoint.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	prometheusMetrics := metrics.NewPrometheusMetrics()
+	metricsServer, err := prometheusMetrics.Serve(0)
+	checkError(t, err, "serve metrics")
+	defer metricsServer.Close()
+
+	storeA := store.NewMemStore(testactors.Alice.PrivateKey)
+	msgA := messageservice.NewTestMessageService(crypto.GetAddressFromSecretKeyBytes(testactors.Alice.PrivateKey), broker, 0)
+	nodeA := node.New(msgA, chainservice.NewMockChainService(chain, testactors.Alice.Address()), storeA, &engine.PermissivePolicy{}, prometheusMetrics, nil)
+
+	cert, err := tls.LoadX509KeyPair("../tls/statechannels.org.pem", "../tls/statechannels.org_key.pem")
+	checkError(t, err, "load certificate")
+	rpcServer, err := interRpc.InitializeRpcServer(&nodeA, 4391, transport.Http, &cert, prometheusMetrics)
+	checkError(t, err, "start rpc server")
+	defer rpcServer.Close()
+
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	clientConnection, err := httpTransport.NewHttpTransportAsClient(rpcServer.Url(), 10*time.Millisecond)
+	checkError(t, err, "connect to rpc server")
+	client, err := rpc.NewRpcClient(clientConnection)
+	checkError(t, err, "create rpc client")
+	defer client.Close()
+
+	response, err := client.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	checkError(t, err, "create ledger channel")
+	select {
+	case <-client.ObjectiveCompleteChan(response.Id):
+	case <-time.After(defaultTimeout):
+		t.Fatal("timed out waiting for the ledger channel to be funded")
+	}
+
+	res, err := http.Get(metricsServer.Url())
+	checkError(t, err, "get metrics")
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	checkError(t, err, "read metrics")
+
+	for _, name := range []string{
+		"nitro_objectives_spawned_total",
+		"nitro_objectives_completed_total",
+		"nitro_objective_duration_seconds_bucket",
+		"nitro_messages_sent_total",
+		"nitro_messages_received_total",
+		"nitro_chain_events_handled_total",
+		"nitro_crank_duration_seconds_bucket",
+		"nitro_rpc_requests_total",
+		"nitro_rpc_request_duration_seconds_bucket",
+	} {
+		if !strings.Contains(string(body), "\n"+name) {
+			t.Errorf("expected the metrics endpoint to serve %s", name)
+		}
+	}
+}
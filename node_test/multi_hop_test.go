@@ -0,0 +1,94 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestVirtualFundThroughTwoIntermediaries funds a payment channel from Alice to Bob via Irene and then Ivan,
+// and checks that every hop's ledger channel locks the payment channel's deposit.
+func TestVirtualFundThroughTwoIntermediaries(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_multi_hop.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+	newNode := func(actor testactors.Actor) node.Node {
+		n, _ := setupNode(actor.PrivateKey, chainservice.NewMockChainService(chain, actor.Address()), broker, 0, dataFolder)
+		return n
+	}
+
+	nodeA := newNode(testactors.Alice)
+	defer closeNode(t, &nodeA)
+	nodeIrene := newNode(testactors.Irene)
+	defer closeNode(t, &nodeIrene)
+	nodeIvan := newNode(testactors.Ivan)
+	defer closeNode(t, &nodeIvan)
+	nodeB := newNode(testactors.Bob)
+	defer closeNode(t, &nodeB)
+
+	// Each hop is a ledger channel, from the payer's side to the payee's side
+	hops := []struct {
+		left, right node.Node
+		id          types.Destination
+	}{
+		{left: nodeA, right: nodeIrene},
+		{left: nodeIrene, right: nodeIvan},
+		{left: nodeIvan, right: nodeB},
+	}
+	for i := range hops {
+		hops[i].id = openLedgerChannel(t, hops[i].left, hops[i].right, types.Address{})
+	}
+
+	outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), virtualChannelDeposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel(
+		[]types.Address{testactors.Irene.Address(), testactors.Ivan.Address()},
+		testactors.Bob.Address(),
+		0,
+		outcome,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intermediaries := []node.Node{nodeIrene, nodeIvan}
+	waitForObjectives(t, nodeA, nodeB, intermediaries, []protocols.ObjectiveId{response.Id})
+
+	checkPaymentChannel(t, response.ChannelId, initialPaymentOutcome(*nodeA.Address, *nodeB.Address, types.Address{}), query.Open, nodeA, nodeB)
+
+	for _, hop := range hops {
+		// The left participant's funds guarantee the payment channel on this hop
+		lockedOutcome := td.Outcomes.Create(*hop.left.Address, *hop.right.Address, ledgerChannelDeposit-virtualChannelDeposit, ledgerChannelDeposit, types.Address{})
+		checkLedgerChannel(t, hop.id, lockedOutcome, query.Open, hop.left, hop.right)
+
+		funded, err := hop.left.GetPaymentChannelsByLedger(hop.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(funded) != 1 || funded[0].ID != response.ChannelId {
+			t.Errorf("expected ledger %s to fund only payment channel %s, got %+v", hop.id, response.ChannelId, funded)
+		}
+	}
+
+	closeId, err := nodeA.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, intermediaries, []protocols.ObjectiveId{closeId})
+
+	for _, hop := range hops {
+		checkLedgerChannel(t, hop.id, initialLedgerOutcome(*hop.left.Address, *hop.right.Address, types.Address{}), query.Open, hop.left, hop.right)
+	}
+}
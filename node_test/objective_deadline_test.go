@@ -0,0 +1,95 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// silentPeerMessageService drops every message sent once silenced, as if the peers they were sent to had stopped responding
+type silentPeerMessageService struct {
+	messageservice.TestMessageService
+	silenced atomic.Bool
+}
+
+func (s *silentPeerMessageService) Send(msg protocols.Message) error {
+	if s.silenced.Load() {
+		return nil
+	}
+	return s.TestMessageService.Send(msg)
+}
+
+func TestStalledObjectiveIsEscalatedOnChain(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_objective_deadline.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+	broker := messageservice.NewBroker()
+
+	challenges := make(chan chainservice.ChallengeRegisteredEvent, 10)
+	go func() {
+		for event := range chain.SubscribeToEvents(types.Address{}) {
+			if challenge, ok := event.(chainservice.ChallengeRegisteredEvent); ok {
+				challenges <- challenge
+			}
+		}
+	}()
+
+	deadline := 300 * time.Millisecond
+	msgA := &silentPeerMessageService{TestMessageService: messageservice.NewTestMessageService(ta.Alice.Address(), broker, 0)}
+	nodeA := node.New(msgA, chainservice.NewMockChainService(chain, ta.Alice.Address()), store.NewMemStore(ta.Alice.PrivateKey), &engine.DeadlinePolicy{Deadline: deadline}, nil, nil)
+	defer closeNode(t, &nodeA)
+	nodeB := node.New(messageservice.NewTestMessageService(ta.Bob.Address(), broker, 0), chainservice.NewMockChainService(chain, ta.Bob.Address()), store.NewMemStore(ta.Bob.PrivateKey), &engine.PermissivePolicy{}, nil, nil)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	ledger, err := nodeA.GetLedgerChannel(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Bob stops responding, so Alice's attempt to top up the ledger stalls
+	msgA.silenced.Store(true)
+	topUpStarted := time.Now()
+	topUpId, err := nodeA.TopUpLedgerChannel(ledgerId, big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case challenge := <-challenges:
+		if challenge.ChannelID() != ledgerId {
+			t.Fatalf("expected ledger channel %s to be challenged, got %s", ledgerId, challenge.ChannelID())
+		}
+		if escalatedAfter := time.Since(topUpStarted); escalatedAfter < deadline {
+			t.Errorf("expected the challenge to be registered after the %s deadline, but it was registered after %s", deadline, escalatedAfter)
+		}
+		// The latest state supported by both participants is the one before Alice proposed to top up the ledger
+		if got, want := challenge.Outcome()[0].Allocations[0].Amount, ledger.Balance.MyBalance.ToInt(); got.Cmp(want) != 0 {
+			t.Errorf("expected the challenge to register Alice's balance of %s, got %s", want, got)
+		}
+	case <-nodeA.ObjectiveCompleteChan(topUpId):
+		t.Fatal("expected topping up the ledger to stall while Bob is silent")
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the stalled objective to be escalated on chain")
+	}
+
+	// The objective is escalated once
+	select {
+	case challenge := <-challenges:
+		t.Errorf("expected a single challenge, got another for channel %s", challenge.ChannelID())
+	case <-time.After(3 * deadline):
+	}
+}
@@ -0,0 +1,159 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestObjectiveProgressIsEmittedInOrder(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_objective_progress.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+	chainServiceA := chainservice.NewMockChainService(chain, testactors.Alice.Address())
+	chainServiceB := chainservice.NewMockChainService(chain, testactors.Bob.Address())
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainServiceA, broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainServiceB, broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-nodeA.ObjectiveCompleteChan(response.Id)
+
+	progress := []engine.ObjectiveProgressEvent{}
+	for len(nodeA.ObjectiveProgress()) > 0 {
+		if p := <-nodeA.ObjectiveProgress(); p.ObjectiveId == response.Id {
+			progress = append(progress, p)
+		}
+	}
+	if len(progress) == 0 {
+		t.Fatal("expected progress to be emitted for the direct-fund objective")
+	}
+
+	stages := []protocols.WaitingFor{
+		directfund.WaitingForCompletePrefund,
+		directfund.WaitingForMyTurnToFund,
+		directfund.WaitingForCompleteFunding,
+		directfund.WaitingForCompletePostFund,
+		directfund.WaitingForNothing,
+	}
+	stage := func(w protocols.WaitingFor) int {
+		for i, s := range stages {
+			if s == w {
+				return i
+			}
+		}
+		t.Fatalf("unexpected waiting for %s", w)
+		return -1
+	}
+	for i := 1; i < len(progress); i++ {
+		if stage(progress[i].WaitingFor) < stage(progress[i-1].WaitingFor) {
+			t.Errorf("progress went backwards from %s to %s", progress[i-1].WaitingFor, progress[i].WaitingFor)
+		}
+		if progress[i].Timestamp.Before(progress[i-1].Timestamp) {
+			t.Errorf("progress %d has an earlier timestamp than the progress before it", i)
+		}
+	}
+	if last := progress[len(progress)-1].WaitingFor; last != directfund.WaitingForNothing {
+		t.Errorf("expected the final progress to be %s, got %s", directfund.WaitingForNothing, last)
+	}
+}
+
+func TestEachObjectiveReportsItsPhases(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_objective_phases.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	topUpId, err := nodeA.TopUpLedgerChannel(ledgerId, big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{topUpId})
+	outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), virtualChannelDeposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+	closeId, err := nodeA.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{closeId})
+	closeLedgerChannel(t, nodeA, nodeB, ledgerId)
+
+	// The phases of each protocol, in the order they are passed through
+	phases := map[string][]protocols.WaitingFor{
+		directfund.ObjectivePrefix:    {protocols.WaitingForCompletePrefund, protocols.WaitingForMyTurnToFund, protocols.WaitingForCompleteFunding, protocols.WaitingForCompletePostFund, protocols.WaitingForNothing},
+		ledgertopup.ObjectivePrefix:   {protocols.WaitingForDeposit, protocols.WaitingForCompleteTopUp, protocols.WaitingForNothing},
+		virtualfund.ObjectivePrefix:   {protocols.WaitingForCompletePrefund, protocols.WaitingForCompleteFunding, protocols.WaitingForCompletePostFund, protocols.WaitingForNothing},
+		virtualdefund.ObjectivePrefix: {protocols.WaitingForFinalStateFromAlice, protocols.WaitingForSupportedFinalState, protocols.WaitingForDefundingOnMyLeft, protocols.WaitingForDefundingOnMyRight, protocols.WaitingForNothing},
+		"DirectDefunding-":            {protocols.WaitingForFinalization, protocols.WaitingForWithdraw, protocols.WaitingForNothing},
+	}
+	phaseOf := func(id protocols.ObjectiveId, w protocols.WaitingFor) int {
+		for prefix, ps := range phases {
+			if !strings.HasPrefix(string(id), prefix) {
+				continue
+			}
+			for i, p := range ps {
+				if p == w {
+					return i
+				}
+			}
+		}
+		t.Fatalf("objective %s reported unexpected phase %s", id, w)
+		return -1
+	}
+
+	last := map[protocols.ObjectiveId]protocols.WaitingFor{}
+	for len(nodeA.ObjectiveProgress()) > 0 {
+		p := <-nodeA.ObjectiveProgress()
+		if previous, ok := last[p.ObjectiveId]; ok && phaseOf(p.ObjectiveId, p.WaitingFor) < phaseOf(p.ObjectiveId, previous) {
+			t.Errorf("objective %s went back from %s to %s", p.ObjectiveId, previous, p.WaitingFor)
+		}
+		phaseOf(p.ObjectiveId, p.WaitingFor)
+		last[p.ObjectiveId] = p.WaitingFor
+	}
+	if len(last) != len(phases) {
+		t.Errorf("expected progress from %d objectives, got %d", len(phases), len(last))
+	}
+	for id, w := range last {
+		if w != protocols.WaitingForNothing {
+			t.Errorf("expected objective %s to finish %s, but it was last %s", id, protocols.WaitingForNothing, w)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// rejectingValidator rejects every proposed outcome, recording whether the outcome conserved funds
+type rejectingValidator struct {
+	calls     atomic.Int64
+	conserved atomic.Bool
+}
+
+func (rv *rejectingValidator) Validate(current, proposed outcome.Exit) error {
+	rv.calls.Add(1)
+	rv.conserved.Store(outcome.ConservationValidator{}.Validate(current, proposed) == nil)
+	return errors.New("the application does not allow channels to be closed")
+}
+
+func TestCustomOutcomeValidator(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_outcome_validator.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+
+	validator := &rejectingValidator{}
+	storeB := store.NewMemStore(testactors.Bob.PrivateKey)
+	msgB := messageservice.NewTestMessageService(crypto.GetAddressFromSecretKeyBytes(testactors.Bob.PrivateKey), broker, 0)
+	nodeB := node.New(msgB, chainservice.NewMockChainService(chain, testactors.Bob.Address()), storeB, &engine.PermissivePolicy{}, nil, validator)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	closeId, err := nodeA.CloseLedgerChannel(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-nodeB.ObjectiveCompleteChan(closeId):
+		t.Fatal("expected the ledger channel not to close once its final outcome was rejected")
+	case <-time.After(time.Second):
+	}
+	if validator.calls.Load() == 0 {
+		t.Fatal("expected the custom validator to be asked about the final outcome")
+	}
+	if !validator.conserved.Load() {
+		t.Error("expected the final outcome proposed by Alice to conserve funds")
+	}
+}
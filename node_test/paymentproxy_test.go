@@ -101,7 +101,7 @@ func TestPaymentProxy(t *testing.T) {
 
 	// Using the same voucher again should result in a payment required response
 	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/resource?channelId=%s&amount=%d&signature=%s", proxyAddress, voucher.ChannelId, voucher.Amount.Int64(), voucher.Signature.ToHexString()))
-	checkResponse(t, resp, expectedPaymentErrorMessage(5, 0), http.StatusPaymentRequired)
+	checkResponse(t, resp, payments.ErrStaleVoucher.Error(), http.StatusPaymentRequired)
 
 	// Not providing a voucher should result in a payment required response
 	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/resource", proxyAddress))
@@ -233,11 +233,21 @@ func getResponseInfo(t *testing.T, resp *http.Response) (body string, statusCode
 func setupNitroClients(t *testing.T, logFile string) (alice, irene, bob rpc.RpcClientApi, cleanup func()) {
 	chain := chainservice.NewMockChain()
 
+	aliceClient, ireneClient, bobClient, clientsCleanup := setupNitroClientsOnChain(t, logFile,
+		chainservice.NewMockChainService(chain, ta.Alice.Address()),
+		chainservice.NewMockChainService(chain, ta.Irene.Address()),
+		chainservice.NewMockChainService(chain, ta.Bob.Address()),
+	)
+	return aliceClient, ireneClient, bobClient, func() {
+		clientsCleanup()
+		chain.Close()
+	}
+}
+
+// setupNitroClientsOnChain sets up rpc clients for Alice, Irene and Bob whose nodes use the supplied chain services
+func setupNitroClientsOnChain(t *testing.T, logFile string, aliceChainService, ireneChainService, bobChainService chainservice.ChainService) (alice, irene, bob rpc.RpcClientApi, cleanup func()) {
 	logging.SetupDefaultFileLogger(logFile, slog.LevelDebug)
 
-	aliceChainService := chainservice.NewMockChainService(chain, ta.Alice.Address())
-	bobChainService := chainservice.NewMockChainService(chain, ta.Bob.Address())
-	ireneChainService := chainservice.NewMockChainService(chain, ta.Irene.Address())
 	ireneClient, msgIrene, ireneCleanup := setupNitroNodeWithRPCClient(t, ta.Irene.PrivateKey, 3106, 4106, ireneChainService, transport.Http, []string{})
 	bootPeers := []string{msgIrene.MultiAddr}
 	aliceClient, msgAlice, aliceCleanup := setupNitroNodeWithRPCClient(t, ta.Alice.PrivateKey, 3105, 4105, aliceChainService, transport.Http, bootPeers)
@@ -253,7 +263,6 @@ func setupNitroClients(t *testing.T, logFile string) (alice, irene, bob rpc.RpcC
 		aliceCleanup()
 		ireneCleanup()
 		bobCleanup()
-		chain.Close()
 	}
 }
 
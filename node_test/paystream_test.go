@@ -0,0 +1,64 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestPayStream(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_pay_stream.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), virtualChannelDeposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+
+	const adds = 1000
+	stream := nodeA.PayStream(response.ChannelId, node.PayStreamOpts{Interval: time.Second, MinIncrement: big.NewInt(100)})
+	for i := 0; i < adds; i++ {
+		stream.Add(big.NewInt(1))
+	}
+	stream.Flush()
+
+	received := 0
+	timeout := time.After(defaultTimeout)
+	for paid := big.NewInt(0); paid.Cmp(big.NewInt(adds)) != 0; {
+		select {
+		case v := <-nodeB.ReceivedVouchers():
+			received++
+			paid = v.Amount
+		case <-timeout:
+			t.Fatalf("timed out waiting for vouchers, received %d", received)
+		}
+	}
+
+	if received > adds/100 {
+		t.Errorf("expected at most %d vouchers to be sent, got %d", adds/100, received)
+	}
+}
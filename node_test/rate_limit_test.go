@@ -0,0 +1,74 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestPeerObjectivesAreRateLimited(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_rate_limit.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, storeA := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+
+	// Bob accepts a ledger channel and one payment channel from Alice at once, and one more objective every half second
+	const perSecond, burst = 2, 2
+	storeB := store.NewMemStore(testactors.Bob.PrivateKey)
+	msgB := messageservice.NewTestMessageService(crypto.GetAddressFromSecretKeyBytes(testactors.Bob.PrivateKey), broker, 0)
+	nodeB := node.New(msgB, chainservice.NewMockChainService(chain, testactors.Bob.Address()), storeB, engine.NewRateLimitPolicy(&engine.PermissivePolicy{}, perSecond, burst), nil, nil)
+	defer closeNode(t, &nodeB)
+
+	openLedgerChannel(t, nodeA, nodeB, types.Address{})
+
+	createPaymentChannel := func() protocols.ObjectiveId {
+		outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), 1, 0, types.Address{})
+		response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, outcome)
+		if err != nil {
+			t.Fatal(err)
+		}
+		<-nodeA.ObjectiveCompleteChan(response.Id)
+		return response.Id
+	}
+	status := func(id protocols.ObjectiveId) protocols.ObjectiveStatus {
+		o, err := storeA.GetObjectiveById(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return o.GetStatus()
+	}
+
+	if id := createPaymentChannel(); status(id) != protocols.Completed {
+		t.Errorf("expected the payment channel within the limit to be funded, but its objective is %v", status(id))
+	}
+	if id := createPaymentChannel(); status(id) != protocols.Rejected {
+		t.Errorf("expected the payment channel beyond the limit to be rejected, but its objective is %v", status(id))
+	} else if _, err := storeB.GetObjectiveById(id); !errors.Is(err, store.ErrNoSuchObjective) {
+		t.Errorf("expected the objective beyond the limit not to be stored, but got %v", err)
+	}
+
+	time.Sleep(time.Second / perSecond)
+	if id := createPaymentChannel(); status(id) != protocols.Completed {
+		t.Errorf("expected the payment channel after the limit refilled to be funded, but its objective is %v", status(id))
+	}
+}
@@ -0,0 +1,68 @@
+package node_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestRejectionReasonReachesRequester(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_rejection_reason.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+
+	// Bob accepts channels from nobody
+	storeB := store.NewMemStore(testactors.Bob.PrivateKey)
+	msgB := messageservice.NewTestMessageService(crypto.GetAddressFromSecretKeyBytes(testactors.Bob.PrivateKey), broker, 0)
+	nodeB := node.New(msgB, chainservice.NewMockChainService(chain, testactors.Bob.Address()), storeB, &engine.AllowlistPolicy{}, nil, nil)
+	defer closeNode(t, &nodeB)
+
+	outcome := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForRejection := func(n node.Node) engine.ObjectiveRejection {
+		t.Helper()
+		select {
+		case rejection := <-n.RejectedObjectives():
+			return rejection
+		case <-time.After(defaultTimeout):
+			t.Fatalf("timed out waiting for %s to report the rejection", n.Address)
+			return engine.ObjectiveRejection{}
+		}
+	}
+
+	for _, n := range []node.Node{nodeB, nodeA} {
+		rejection := waitForRejection(n)
+		if rejection.ObjectiveId != response.Id {
+			t.Errorf("expected objective %s to be rejected, got %s", response.Id, rejection.ObjectiveId)
+		}
+		if rejection.By != *nodeB.Address {
+			t.Errorf("expected the objective to be rejected by %s, got %s", nodeB.Address, rejection.By)
+		}
+		if !strings.Contains(rejection.Reason, "not on the allowlist") {
+			t.Errorf("expected the policy's reason for the rejection, got %q", rejection.Reason)
+		}
+	}
+}
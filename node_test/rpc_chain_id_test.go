@@ -0,0 +1,87 @@
+package node_test
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/logging"
+	interRpc "github.com/statechannels/go-nitro/internal/rpc"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	httpTransport "github.com/statechannels/go-nitro/rpc/transport/http"
+)
+
+func TestRpcClientForAnotherChainIsRejected(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_rpc_client_for_another_chain.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	storeA := store.NewMemStore(testactors.Alice.PrivateKey)
+	msgA := messageservice.NewTestMessageService(crypto.GetAddressFromSecretKeyBytes(testactors.Alice.PrivateKey), messageservice.NewBroker(), 0)
+	nodeA := node.New(msgA, chainservice.NewMockChainService(chain, testactors.Alice.Address()), storeA, &engine.PermissivePolicy{}, nil, nil)
+
+	cert, err := tls.LoadX509KeyPair("../tls/statechannels.org.pem", "../tls/statechannels.org_key.pem")
+	checkError(t, err, "load certificate")
+	rpcServer, err := interRpc.InitializeRpcServer(&nodeA, 4392, transport.Http, &cert, nil)
+	checkError(t, err, "start rpc server")
+	defer rpcServer.Close()
+
+	connect := func(chainId *big.Int) (rpc.RpcClientApi, error) {
+		clientConnection, err := httpTransport.NewHttpTransportAsClient(rpcServer.Url(), 10*time.Millisecond)
+		checkError(t, err, "connect to rpc server")
+		type result struct {
+			client rpc.RpcClientApi
+			err    error
+		}
+		done := make(chan result, 1)
+		go func() {
+			client, err := rpc.NewRpcClientForChain(clientConnection, chainId)
+			if err != nil {
+				clientConnection.Close()
+			}
+			done <- result{client, err}
+		}()
+		select {
+		case r := <-done:
+			return r.client, r.err
+		case <-time.After(defaultTimeout):
+			t.Fatalf("timed out connecting a client for chain %s", chainId)
+			return nil, nil
+		}
+	}
+
+	// A client for another chain is rejected with an error naming both chains
+	_, err = connect(big.NewInt(chainservice.TEST_CHAIN_ID + 1))
+	var jsonErr serde.JsonRpcError
+	if !errors.As(err, &jsonErr) || jsonErr.Code != serde.ChainIdMismatchError.Code {
+		t.Fatalf("expected the client to be rejected with %v, got %v", serde.ChainIdMismatchError, err)
+	}
+	for _, id := range []int64{chainservice.TEST_CHAIN_ID + 1, chainservice.TEST_CHAIN_ID} {
+		if !strings.Contains(jsonErr.Message, fmt.Sprint(id)) {
+			t.Errorf("expected the rejection %q to name chain %d", jsonErr.Message, id)
+		}
+	}
+
+	// A client for the node's chain sends its chain id with every request
+	client, err := connect(big.NewInt(chainservice.TEST_CHAIN_ID))
+	checkError(t, err, "create rpc client")
+	defer client.Close()
+	if chainId := client.ChainId(); chainId.Int64() != chainservice.TEST_CHAIN_ID {
+		t.Fatalf("expected chain id %d, got %s", chainservice.TEST_CHAIN_ID, chainId)
+	}
+	_, err = client.GetAllLedgerChannels()
+	checkError(t, err, "client.GetAllLedgerChannels")
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"math/big"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,12 +26,14 @@ import (
 	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
 	"github.com/statechannels/go-nitro/node/engine/store"
 	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/rpc"
 	"github.com/statechannels/go-nitro/rpc/transport"
 	"github.com/statechannels/go-nitro/rpc/transport/http"
 	natstrans "github.com/statechannels/go-nitro/rpc/transport/nats"
+	"github.com/statechannels/go-nitro/rpc/transport/ws"
 	"github.com/statechannels/go-nitro/types"
 
 	"github.com/statechannels/go-nitro/crypto"
@@ -52,6 +55,12 @@ func TestRpcWithHttp(t *testing.T) {
 	}
 }
 
+func TestRpcWithWebSocket(t *testing.T) {
+	for _, n := range []int{2, 3, 4} {
+		executeNRpcTestWrapper(t, transport.Ws, n, false)
+	}
+}
+
 func TestRPCWithManualVoucherExchange(t *testing.T) {
 	executeNRpcTestWrapper(t, transport.Http, 4, true)
 	executeNRpcTestWrapper(t, transport.Nats, 4, true)
@@ -144,6 +153,25 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 		}
 	}
 
+	slog.Info("Verify that each rpc client fetches the chain id and is connected")
+	for i := 0; i < n; i++ {
+		if chainId := clients[i].ChainId(); chainId.Int64() != chainservice.TEST_CHAIN_ID {
+			t.Fatalf("expected chain id %d, got %s", chainservice.TEST_CHAIN_ID, chainId)
+		}
+		if !clients[i].Connected() {
+			t.Fatalf("expected client %d to be connected", i)
+		}
+	}
+
+	slog.Info("Verify that each rpc client reports a ready node")
+	for i := 0; i < n; i++ {
+		health, err := clients[i].Health()
+		checkError(t, err, "client.Health")
+		if !health.Ready {
+			t.Fatalf("expected node %d to be ready, got %+v", i, health)
+		}
+	}
+
 	waitForPeerInfoExchange(msgServices...)
 	slog.Info("Peer exchange complete")
 
@@ -202,6 +230,28 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 		}
 	}
 
+	// Once funded, no objective is operating on the ledger channels
+	for i := 0; i < n-1; i++ {
+		info, err := clients[i].GetObjectiveByChannelId(ledgerChannels[i].ChannelId)
+		checkError(t, err, "client.GetObjectiveByChannelId")
+		if info != query.NoObjective {
+			t.Fatalf("expected no objective to be operating on ledger channel %s, got %+v", ledgerChannels[i].ChannelId, info)
+		}
+	}
+
+	// Each client's liquidity is its balance summed across its ledger channels
+	for i, client := range clients {
+		want := int64(200)
+		if i == 0 || i == n-1 {
+			want = 100
+		}
+		liquidity, err := client.TotalAvailableLiquidity(types.Address{})
+		checkError(t, err, "client.TotalAvailableLiquidity")
+		if liquidity.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("expected client %d to have %d available, got %s", i, want, liquidity)
+		}
+	}
+
 	t.Log("Ledger channels queried")
 
 	//////////////////////////////////////////////////////////////////
@@ -222,6 +272,13 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 
 	initialOutcome := simpleOutcome(actors[0].Address(), actors[n-1].Address(), 100, 0)
 
+	simulated, err := aliceClient.SimulateCreatePaymentChannel(
+		intermediaries,
+		bob.Address(),
+		100,
+		initialOutcome,
+	)
+	checkError(t, err, "client.SimulateCreatePaymentChannel")
 	vabCreateResponse, err := aliceClient.CreatePaymentChannel(
 		intermediaries,
 		bob.Address(),
@@ -229,6 +286,9 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 		initialOutcome,
 	)
 	checkError(t, err, "client.CreatePaymentChannel")
+	if simulated.ChannelId != vabCreateResponse.ChannelId || simulated.ID != vabCreateResponse.Id {
+		t.Errorf("expected the simulation to predict channel %s, got %s", vabCreateResponse.ChannelId, simulated.ChannelId)
+	}
 	expectedVirtualChannel := createPaychInfo(
 		vabCreateResponse.ChannelId,
 		initialOutcome,
@@ -279,11 +339,27 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 			t.Errorf("expected a delta of 1 got %d", rxVoucher.Delta)
 		}
 
-		rxVoucher, err = bobClient.ReceiveVoucher(v)
-		checkError(t, err, "bobClient.ReceiveVoucher")
+		_, err = bobClient.ReceiveVoucher(v)
+		if err == nil || !strings.Contains(err.Error(), payments.ErrStaleVoucher.Error()) {
+			t.Errorf("expected adding the same voucher to fail with %v, got %v", payments.ErrStaleVoucher, err)
+		}
+
+		balance, err := bobClient.GetVoucherBalance(vabCreateResponse.ChannelId, "ui")
+		checkError(t, err, "bobClient.GetVoucherBalance")
+		if balance.Total.Cmp(big.NewInt(1)) != 0 || balance.Delta.Cmp(big.NewInt(1)) != 0 {
+			t.Errorf("expected a total of 1 and a delta of 1, got %d and %d", balance.Total, balance.Delta)
+		}
 
-		if rxVoucher.Delta.Cmp(big.NewInt(0)) != 0 {
-			t.Errorf("adding the same voucher should result in a delta of 0, got %d", rxVoucher.Delta)
+		balance, err = bobClient.GetVoucherBalance(vabCreateResponse.ChannelId, "ui")
+		checkError(t, err, "bobClient.GetVoucherBalance")
+		if balance.Total.Cmp(big.NewInt(1)) != 0 || balance.Delta.Cmp(big.NewInt(0)) != 0 {
+			t.Errorf("expected a total of 1 and a delta of 0 on a repeated query, got %d and %d", balance.Total, balance.Delta)
+		}
+
+		balance, err = bobClient.GetVoucherBalance(vabCreateResponse.ChannelId, "")
+		checkError(t, err, "bobClient.GetVoucherBalance")
+		if balance.Delta.Cmp(big.NewInt(1)) != 0 {
+			t.Errorf("expected another caller's first query to have a delta of 1, got %d", balance.Delta)
 		}
 	} else {
 		_, err = aliceClient.Pay(vabCreateResponse.ChannelId, 1)
@@ -393,7 +469,7 @@ func setupNitroNodeWithRPCClient(
 	pkBytes []byte,
 	msgPort int,
 	rpcPort int,
-	chain *chainservice.MockChainService,
+	chain chainservice.ChainService,
 	connectionType transport.TransportType,
 	bootPeers []string,
 ) (rpc.RpcClientApi, *p2pms.P2PMessageService, func()) {
@@ -420,25 +496,15 @@ func setupNitroNodeWithRPCClient(
 		messageService,
 		chain,
 		ourStore,
-		&engine.PermissivePolicy{})
-
-	var useNats bool
-	switch connectionType {
-	case transport.Nats:
-		useNats = true
-	case transport.Http:
-		useNats = false
-	default:
-		err = fmt.Errorf("unknown connection type %v", connectionType)
-		panic(err)
-	}
+		&engine.PermissivePolicy{}, nil, nil)
+	node.SetRandomness(testRand(ourStore.GetAddress().Hex()))
 
 	cert, err := tls.LoadX509KeyPair("../tls/statechannels.org.pem", "../tls/statechannels.org_key.pem")
 	if err != nil {
 		panic(err)
 	}
 
-	rpcServer, err := interRpc.InitializeRpcServer(&node, rpcPort, useNats, &cert)
+	rpcServer, err := interRpc.InitializeRpcServer(&node, rpcPort, connectionType, &cert, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -457,12 +523,18 @@ func setupNitroNodeWithRPCClient(
 		if err != nil {
 			panic(err)
 		}
+	case transport.Ws:
+
+		clientConnection, err = ws.NewWsTransportAsClient(rpcServer.Url(), 10*time.Millisecond)
+		if err != nil {
+			panic(err)
+		}
 	default:
 		err = fmt.Errorf("unknown connection type %v", connectionType)
 		panic(err)
 	}
 
-	rpcClient, err := rpc.NewRpcClient(clientConnection)
+	rpcClient, err := rpc.NewRpcClientWithRandomness(clientConnection, testRand("rpc client of "+ourStore.GetAddress().Hex()))
 	if err != nil {
 		panic(err)
 	}
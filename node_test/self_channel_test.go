@@ -0,0 +1,57 @@
+package node_test
+
+import (
+	"errors"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestSelfChannelsAreRejected(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+
+	alice, irene, bob := ta.Alice.Address(), ta.Irene.Address(), ta.Bob.Address()
+
+	ledgerOutcome := initialLedgerOutcome(alice, alice, types.Address{})
+	if _, err := nodeA.CreateLedgerChannel(alice, 0, ledgerOutcome); !errors.Is(err, directfund.ErrSelfChannel) {
+		t.Errorf("expected a ledger channel with myself to be rejected with %v, got %v", directfund.ErrSelfChannel, err)
+	}
+	if _, err := nodeA.SimulateCreateLedgerChannel(alice, 0, ledgerOutcome); !errors.Is(err, directfund.ErrSelfChannel) {
+		t.Errorf("expected simulating a ledger channel with myself to be rejected with %v, got %v", directfund.ErrSelfChannel, err)
+	}
+
+	testCases := []struct {
+		name           string
+		intermediaries []types.Address
+		counterparty   types.Address
+		want           error
+	}{
+		{"counterparty is me", []types.Address{irene}, alice, virtualfund.ErrSelfChannel},
+		{"intermediary is me", []types.Address{alice}, bob, virtualfund.ErrIntermediaryIsEndpoint},
+		{"intermediary is the counterparty", []types.Address{irene, bob}, bob, virtualfund.ErrIntermediaryIsEndpoint},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			paymentOutcome := initialPaymentOutcome(alice, tc.counterparty, types.Address{})
+			if _, err := nodeA.CreatePaymentChannel(tc.intermediaries, tc.counterparty, 0, paymentOutcome); !errors.Is(err, tc.want) {
+				t.Errorf("expected the payment channel to be rejected with %v, got %v", tc.want, err)
+			}
+			if _, err := nodeA.SimulateCreatePaymentChannel(tc.intermediaries, tc.counterparty, 0, paymentOutcome); !errors.Is(err, tc.want) {
+				t.Errorf("expected simulating the payment channel to be rejected with %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package node_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestPolicyCanBeReplacedWhileRunning(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_policy_can_be_replaced.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	// Bob starts out approving everything
+	openLedgerChannel(t, nodeA, nodeB, types.Address{})
+
+	// Bob then stops accepting channels from anybody
+	nodeB.SetPolicy(&engine.AllowlistPolicy{})
+
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), 1, 0, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case rejection := <-nodeB.RejectedObjectives():
+		if rejection.ObjectiveId != response.Id {
+			t.Errorf("expected objective %s to be rejected, got %s", response.Id, rejection.ObjectiveId)
+		}
+		if !strings.Contains(rejection.Reason, "not on the allowlist") {
+			t.Errorf("expected the new policy's reason for the rejection, got %q", rejection.Reason)
+		}
+	case <-time.After(defaultTimeout):
+		t.Fatalf("timed out waiting for objective %s to be rejected", response.Id)
+	}
+}
@@ -0,0 +1,83 @@
+package node_test
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/logging"
+	interRpc "github.com/statechannels/go-nitro/internal/rpc"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	httpTransport "github.com/statechannels/go-nitro/rpc/transport/http"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestLatestSignedStateCanBeExported(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_latest_signed_state.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	// Alice's node is closed by its rpc server
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	cert, err := tls.LoadX509KeyPair("../tls/statechannels.org.pem", "../tls/statechannels.org_key.pem")
+	checkError(t, err, "load certificate")
+	rpcServer, err := interRpc.InitializeRpcServer(&nodeA, 4393, transport.Http, &cert, nil)
+	checkError(t, err, "start rpc server")
+	defer rpcServer.Close()
+	clientConnection, err := httpTransport.NewHttpTransportAsClient(rpcServer.Url(), 10*time.Millisecond)
+	checkError(t, err, "connect to rpc server")
+	client, err := rpc.NewRpcClient(clientConnection)
+	checkError(t, err, "create rpc client")
+	defer client.Close()
+
+	// checkSignedState checks that the exported state of the channel has the turn number and every participant's signature
+	checkSignedState := func(channelId types.Destination, turnNum uint64) state.SignedState {
+		t.Helper()
+		ss, err := client.GetLatestSignedState(channelId)
+		checkError(t, err, "client.GetLatestSignedState")
+		testhelpers.Equals(t, channelId, ss.ChannelId())
+		testhelpers.Equals(t, turnNum, ss.State().TurnNum)
+		testhelpers.Assert(t, ss.HasAllSignatures(), "expected every participant to have signed the state of channel %s", channelId)
+		testhelpers.Ok(t, ss.VerifySignatures())
+		return ss
+	}
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	checkSignedState(ledgerId, 1)
+
+	// Funding a payment channel updates the ledger channel
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, initialPaymentOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+
+	ledger := checkSignedState(ledgerId, 2)
+	testhelpers.Assert(t, len(ledger.State().Outcome[0].Allocations) == 3, "expected the exported ledger state to guarantee the payment channel, got %v", ledger.State().Outcome)
+	checkSignedState(response.ChannelId, 1)
+
+	// Both participants hold the same evidence
+	theirs, err := nodeB.GetLatestSignedState(ledgerId)
+	testhelpers.Ok(t, err)
+	testhelpers.Assert(t, theirs.State().Equal(ledger.State()), "expected both participants to export the same ledger state")
+
+	if _, err := client.GetLatestSignedState(types.Destination{1}); err == nil {
+		t.Errorf("expected no state to be exported for an unknown channel")
+	}
+}
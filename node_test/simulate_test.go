@@ -0,0 +1,92 @@
+package node_test
+
+import (
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestSimulateCreateChannel(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeI, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeI)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerOutcome := initialLedgerOutcome(*nodeA.Address, *nodeI.Address, types.Address{})
+	simulated, err := nodeA.SimulateCreateLedgerChannel(*nodeI.Address, 0, ledgerOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !directfund.IsDirectFundObjective(simulated.ID) {
+		t.Errorf("expected a direct fund objective, got %s", simulated.ID)
+	}
+	if want := ledgerOutcome.TotalAllocatedFor(types.AddressToDestination(*nodeA.Address)); !simulated.Deposit.Equal(want) {
+		t.Errorf("expected a deposit of %v, got %v", want, simulated.Deposit)
+	}
+	if !simulated.Outcome.Equal(ledgerOutcome) {
+		t.Errorf("incorrect simulated outcome:\n%s", simulated.Outcome.Diff(ledgerOutcome))
+	}
+	if len(simulated.MessageRecipients) != 1 || simulated.MessageRecipients[0] != *nodeI.Address {
+		t.Errorf("expected the counterparty to be messaged, got %v", simulated.MessageRecipients)
+	}
+
+	// Nothing is sent while simulating
+	if info, err := nodeI.GetObjectiveByChannelId(simulated.ChannelId); err != nil || info != query.NoObjective {
+		t.Fatalf("expected the counterparty to know nothing of the simulated channel, got %+v, %v", info, err)
+	}
+	if _, err := nodeA.GetLedgerChannel(simulated.ChannelId); err == nil {
+		t.Fatal("expected a simulated ledger channel not to be stored")
+	}
+
+	// Simulating again predicts the same channel, which is the one that is then created
+	again, err := nodeA.SimulateCreateLedgerChannel(*nodeI.Address, 0, ledgerOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ChannelId != simulated.ChannelId {
+		t.Errorf("expected repeated simulations to predict channel %s, got %s", simulated.ChannelId, again.ChannelId)
+	}
+	created, err := nodeA.CreateLedgerChannel(*nodeI.Address, 0, ledgerOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ChannelId != simulated.ChannelId || created.Id != simulated.ID {
+		t.Errorf("expected the simulated channel %s to be created, got %s", simulated.ChannelId, created.ChannelId)
+	}
+	<-nodeA.ObjectiveCompleteChan(created.Id)
+	<-nodeI.ObjectiveCompleteChan(created.Id)
+	openLedgerChannel(t, nodeI, nodeB, types.Address{})
+
+	paymentOutcome := initialPaymentOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+	simulatedPayment, err := nodeA.SimulateCreatePaymentChannel([]types.Address{*nodeI.Address}, *nodeB.Address, 0, paymentOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(simulatedPayment.MessageRecipients) != 2 {
+		t.Errorf("expected the intermediary and counterparty to be messaged, got %v", simulatedPayment.MessageRecipients)
+	}
+	response, err := nodeA.CreatePaymentChannel([]types.Address{*nodeI.Address}, *nodeB.Address, 0, paymentOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ChannelId != simulatedPayment.ChannelId || response.Id != simulatedPayment.ID {
+		t.Errorf("expected the simulated channel %s to be created, got %s", simulatedPayment.ChannelId, response.ChannelId)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{nodeI}, []protocols.ObjectiveId{response.Id})
+}
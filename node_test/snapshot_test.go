@@ -0,0 +1,93 @@
+package node_test
+
+import (
+	"bytes"
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestRestoreNodeFromSnapshot(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_restore_from_snapshot.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, storeA := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	outcome := td.Outcomes.Create(ta.Alice.Address(), ta.Bob.Address(), virtualChannelDeposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, ta.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+	nodeA.Pay(response.ChannelId, big.NewInt(1))
+	<-nodeB.ReceivedVouchers()
+
+	ledgerBefore, err := nodeA.GetLedgerChannel(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paymentChannelBefore, err := nodeA.GetPaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var snapshot bytes.Buffer
+	if err := store.Export(storeA, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	closeNode(t, &nodeA)
+
+	// Restore Alice's node into a different kind of store
+	restoredStore := store.NewMemStore(ta.Alice.PrivateKey)
+	if err := store.Import(restoredStore, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	restoredA := node.New(messageservice.NewTestMessageService(ta.Alice.Address(), broker, 0), chainservice.NewMockChainService(chain, ta.Alice.Address()), restoredStore, &engine.PermissivePolicy{}, nil, nil)
+	defer closeNode(t, &restoredA)
+
+	ledgerAfter, err := restoredA.GetLedgerChannel(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(ledgerBefore, ledgerAfter, cmp.AllowUnexported(big.Int{})); diff != "" {
+		t.Errorf("ledger channel diff mismatch (-want +got):\n%s", diff)
+	}
+	paymentChannelAfter, err := restoredA.GetPaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(paymentChannelBefore, paymentChannelAfter, cmp.AllowUnexported(big.Int{})); diff != "" {
+		t.Errorf("payment channel diff mismatch (-want +got):\n%s", diff)
+	}
+
+	// The restored node carries on using its channels
+	restoredA.Pay(response.ChannelId, big.NewInt(1))
+	<-nodeB.ReceivedVouchers()
+	closeId, err := restoredA.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, restoredA, nodeB, []node.Node{}, []protocols.ObjectiveId{closeId})
+	closeLedgerChannel(t, restoredA, nodeB, ledgerId)
+}
@@ -0,0 +1,113 @@
+package node_test
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// offlinePeerMessageService drops the first messages sent, as if the peer they were sent to was offline
+type offlinePeerMessageService struct {
+	messageservice.TestMessageService
+	offlineFor int32
+	sent       atomic.Int32
+}
+
+func (o *offlinePeerMessageService) Send(msg protocols.Message) error {
+	if o.sent.Add(1) <= o.offlineFor {
+		return nil
+	}
+	return o.TestMessageService.Send(msg)
+}
+
+func setupRetryingNode(chain *chainservice.MockChain, broker messageservice.Broker, offlineFor int32) (node.Node, *offlinePeerMessageService) {
+	msg := &offlinePeerMessageService{
+		TestMessageService: messageservice.NewTestMessageService(testactors.Alice.Address(), broker, 0),
+		offlineFor:         offlineFor,
+	}
+	policy := &engine.RetryPolicy{Timeout: 100 * time.Millisecond, MaxRetries: 3}
+	n := node.New(msg, chainservice.NewMockChainService(chain, testactors.Alice.Address()), store.NewMemStore(testactors.Alice.PrivateKey), policy, nil, nil)
+	return n, msg
+}
+
+func TestObjectiveIsRetriedWhenCounterpartyComesOnline(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_spawn_retry.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	// Bob misses the first message and the first retry
+	nodeA, msgA := setupRetryingNode(chain, broker, 2)
+	defer closeNode(t, &nodeA)
+	nodeB := node.New(messageservice.NewTestMessageService(testactors.Bob.Address(), broker, 0), chainservice.NewMockChainService(chain, testactors.Bob.Address()), store.NewMemStore(testactors.Bob.PrivateKey), &engine.PermissivePolicy{}, nil, nil)
+	defer closeNode(t, &nodeB)
+
+	outcome := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+	response, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-nodeA.ObjectiveCompleteChan(response.Id):
+	case failed := <-nodeA.FailedObjectives():
+		t.Fatalf("expected the objective to complete once the counterparty came online, but %s failed", failed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the objective to complete once the counterparty came online")
+	}
+	<-nodeB.ObjectiveCompleteChan(response.Id)
+
+	if sent := msgA.sent.Load(); sent <= 2 {
+		t.Errorf("expected the first message to be sent again, but only %d messages were sent", sent)
+	}
+}
+
+func TestStalledObjectiveFails(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_spawn_retry.log", slog.LevelDebug)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	// Bob never comes online
+	nodeA, msgA := setupRetryingNode(chain, broker, 1000)
+	defer closeNode(t, &nodeA)
+
+	outcome := initialLedgerOutcome(*nodeA.Address, testactors.Bob.Address(), types.Address{})
+	response, err := nodeA.CreateLedgerChannel(testactors.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case failed := <-nodeA.FailedObjectives():
+		if failed != response.Id {
+			t.Errorf("expected objective %s to fail, got %s", response.Id, failed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the stalled objective to fail")
+	}
+
+	// The first message and three retries
+	if sent := msgA.sent.Load(); sent < 4 {
+		t.Errorf("expected the first message to be sent 4 times, got %d", sent)
+	}
+	info, err := nodeA.GetObjectiveByChannelId(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info != query.NoObjective {
+		t.Errorf("expected the failed objective to release the channel, but %s still operates on it", info.ID)
+	}
+}
@@ -0,0 +1,111 @@
+package node_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// objectiveSpans returns the objective span and the crank spans recorded for the objective
+func objectiveSpans(t *testing.T, recorder *tracetest.SpanRecorder, id protocols.ObjectiveId) (sdktrace.ReadOnlySpan, []sdktrace.ReadOnlySpan) {
+	t.Helper()
+	var objective sdktrace.ReadOnlySpan
+	var cranks []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		forObjective := false
+		for _, a := range span.Attributes() {
+			if a.Key == engine.ObjectiveIdAttribute && a.Value.AsString() == string(id) {
+				forObjective = true
+			}
+		}
+		if !forObjective {
+			continue
+		}
+		switch span.Name() {
+		case engine.ObjectiveSpanName:
+			objective = span
+		case engine.CrankSpanName:
+			cranks = append(cranks, span)
+		}
+	}
+	if objective == nil {
+		t.Fatalf("no span was recorded for objective %s", id)
+	}
+	if len(cranks) == 0 {
+		t.Fatalf("no crank spans were recorded for objective %s", id)
+	}
+	return objective, cranks
+}
+
+func hasEvent(span sdktrace.ReadOnlySpan, name string) bool {
+	for _, e := range span.Events() {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestObjectiveSpansAreLinkedAcrossNodes(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_objective_spans.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	recorderA, recorderB := tracetest.NewSpanRecorder(), tracetest.NewSpanRecorder()
+	nodeA.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorderA)))
+	nodeB.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorderB)))
+
+	channelId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	id := protocols.ObjectiveId(directfund.ObjectivePrefix + channelId.String())
+
+	objectiveA, cranksA := objectiveSpans(t, recorderA, id)
+	objectiveB, cranksB := objectiveSpans(t, recorderB, id)
+
+	if objectiveA.Parent().IsValid() {
+		t.Errorf("expected the objective span of the node which spawned the objective to be a root span")
+	}
+	if objectiveB.Parent().SpanID() != objectiveA.SpanContext().SpanID() || !objectiveB.Parent().IsRemote() {
+		t.Errorf("expected the peer's objective span to be a child of the spawning node's objective span")
+	}
+	if objectiveB.SpanContext().TraceID() != objectiveA.SpanContext().TraceID() {
+		t.Errorf("expected the objective spans of both nodes to belong to the same trace")
+	}
+	for objective, cranks := range map[sdktrace.ReadOnlySpan][]sdktrace.ReadOnlySpan{objectiveA: cranksA, objectiveB: cranksB} {
+		for _, crank := range cranks {
+			if crank.Parent().SpanID() != objective.SpanContext().SpanID() {
+				t.Errorf("expected every crank span to be a child of its objective span")
+			}
+		}
+	}
+
+	for _, objective := range []sdktrace.ReadOnlySpan{objectiveA, objectiveB} {
+		for _, event := range []string{engine.MessageSentEventName, engine.TransactionSubmittedEventName} {
+			if !hasEvent(objective, event) {
+				t.Errorf("expected the objective span to record a %q event", event)
+			}
+		}
+	}
+	if !hasEvent(objectiveB, engine.MessageReceivedEventName) {
+		t.Errorf("expected the peer's objective span to record a %q event", engine.MessageReceivedEventName)
+	}
+}
@@ -0,0 +1,62 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestVirtualDefundRefundsUnspentBalance checks that closing a partially spent payment channel
+// returns the unspent portion of the deposit to the payer's side of the ledger channel.
+func TestVirtualDefundRefundsUnspentBalance(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_virtual_defund_refund.log", slog.LevelDebug)
+
+	const (
+		deposit = 100
+		paid    = 30
+	)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ledgerId := openLedgerChannel(t, nodeA, nodeB, types.Address{})
+
+	outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), deposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+	checkLedgerChannel(t, ledgerId, td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), ledgerChannelDeposit-deposit, ledgerChannelDeposit, types.Address{}), query.Open, nodeA, nodeB)
+
+	nodeA.Pay(response.ChannelId, big.NewInt(paid))
+	<-nodeB.ReceivedVouchers()
+
+	closeId, err := nodeA.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{closeId})
+
+	checkPaymentChannel(t, response.ChannelId, td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), deposit-paid, paid, types.Address{}), query.Complete, nodeA, nodeB)
+	checkLedgerChannel(t, ledgerId, td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), ledgerChannelDeposit-paid, ledgerChannelDeposit+paid, types.Address{}), query.Open, nodeA, nodeB)
+}
@@ -0,0 +1,84 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestVoucherReplayAfterRestart(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_voucher_replay.log", slog.LevelDebug)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	nodeA, _ := setupNode(testactors.Alice.PrivateKey, chainservice.NewMockChainService(chain, testactors.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+
+	openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	outcome := td.Outcomes.Create(testactors.Alice.Address(), testactors.Bob.Address(), virtualChannelDeposit, 0, types.Address{})
+	response, err := nodeA.CreatePaymentChannel([]types.Address{}, testactors.Bob.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, nodeA, nodeB, []node.Node{}, []protocols.ObjectiveId{response.Id})
+
+	first, err := nodeA.CreateVoucher(response.ChannelId, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := nodeA.CreateVoucher(response.ChannelId, big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := nodeB.ReceiveVoucher(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Total.Cmp(big.NewInt(3)) != 0 {
+		t.Fatalf("expected a total of 3, got %s", summary.Total)
+	}
+
+	// Restart the payee, reusing its store
+	closeNode(t, &nodeB)
+	nodeB, _ = setupNode(testactors.Bob.PrivateKey, chainservice.NewMockChainService(chain, testactors.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	for _, v := range []payments.Voucher{first, second} {
+		if _, err := nodeB.ReceiveVoucher(v); !errors.Is(err, payments.ErrStaleVoucher) {
+			t.Errorf("expected voucher for %s to be rejected with %v, got %v", v.Amount, payments.ErrStaleVoucher, err)
+		}
+	}
+
+	// Stale vouchers which reach the payee in a message are not reported as received, so the next voucher reported is the fresh one
+	replayer := messageservice.NewTestMessageService(testactors.Irene.Address(), broker, 0)
+	testhelpers.Ok(t, replayer.Send(protocols.Message{To: testactors.Bob.Address(), From: testactors.Alice.Address(), Payments: []payments.Voucher{first, second}}))
+	nodeA.Pay(response.ChannelId, big.NewInt(1))
+	if received := <-nodeB.ReceivedVouchers(); received.Amount.Cmp(big.NewInt(4)) != 0 {
+		t.Errorf("expected the payee to report the voucher for 4, got one for %s", received.Amount)
+	}
+
+	info, err := nodeB.GetPaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paid := info.Balance.PaidSoFar.ToInt(); paid.Cmp(big.NewInt(4)) != 0 {
+		t.Errorf("expected the restarted payee to have been paid 4, got %s", paid)
+	}
+}
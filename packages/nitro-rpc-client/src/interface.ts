@@ -5,6 +5,7 @@ import {
   PaymentChannelInfo,
   PaymentPayload,
   ReceiveVoucherResult,
+  HealthInfo,
   Voucher,
 } from "./types";
 
@@ -26,6 +27,14 @@ interface ledgerChannelApi {
    * @returns The ID of the objective that was created
    */
   CloseLedgerChannel(channelId: string): Promise<string>;
+  /**
+   * TopUpLedgerChannel deposits additional funds into a directly funded ledger channel, keeping it open.
+   *
+   * @param channelId - The ID of the channel to top up
+   * @param amount - The amount to deposit and allocate to this node
+   * @returns The ID of the objective that was created
+   */
+  TopUpLedgerChannel(channelId: string, amount: number): Promise<string>;
   /**
    * GetLedgerChannel queries the RPC server for a payment channel.
    *
@@ -90,6 +99,16 @@ interface paymentApi {
    * @returns The total amount of the channel and the delta of the voucher
    */
   ReceiveVoucher(voucher: Voucher): Promise<ReceiveVoucherResult>;
+  /**
+   * GetVoucherBalance queries the go-nitro node for the largest voucher received on a payment channel.
+   * @param channelId The payment channel to query
+   * @param caller Identifies the caller, so that the increase is measured from its own last query
+   * @returns The total amount of the largest voucher and its increase since the caller last queried the balance
+   */
+  GetVoucherBalance(
+    channelId: string,
+    caller?: string
+  ): Promise<ReceiveVoucherResult>;
   /**
    * Pay sends a payment on a virtual payment chanel.
    *
@@ -143,6 +162,12 @@ export interface RpcClientApi
    * @returns The version of the RPC server
    */
   GetVersion(): Promise<string>;
+  /**
+   * GetHealth queries the RPC server for whether its node is ready to handle requests.
+   *
+   * @returns Whether the node is connected to the chain and its store is open
+   */
+  GetHealth(): Promise<HealthInfo>;
   /**
    * GetAddress queries the RPC server for it's state channel address.
    *
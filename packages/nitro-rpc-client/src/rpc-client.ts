@@ -1,5 +1,6 @@
 import {
   DefundObjectiveRequest,
+  TopUpObjectiveRequest,
   DirectFundPayload,
   LedgerChannelInfo,
   PaymentChannelInfo,
@@ -10,6 +11,7 @@ import {
   ObjectiveResponse,
   Voucher,
   ReceiveVoucherResult,
+  HealthInfo,
   ChannelStatus,
   LedgerChannelUpdatedNotification,
   PaymentChannelUpdatedNotification,
@@ -59,6 +61,16 @@ export class NitroRpcClient implements RpcClientApi {
     return getAndValidateResult(res, "receive_voucher");
   }
 
+  public async GetVoucherBalance(
+    channelId: string,
+    caller = ""
+  ): Promise<ReceiveVoucherResult> {
+    return this.sendRequest("get_voucher_balance", {
+      Id: channelId,
+      Caller: caller,
+    });
+  }
+
   public async WaitForLedgerChannelStatus(
     channelId: string,
     status: ChannelStatus
@@ -176,6 +188,17 @@ export class NitroRpcClient implements RpcClientApi {
     return this.sendRequest("close_ledger_channel", payload);
   }
 
+  public async TopUpLedgerChannel(
+    channelId: string,
+    amount: number
+  ): Promise<string> {
+    const payload: TopUpObjectiveRequest = {
+      ChannelId: channelId,
+      Amount: amount,
+    };
+    return this.sendRequest("top_up_ledger_channel", payload);
+  }
+
   public async ClosePaymentChannel(channelId: string): Promise<string> {
     const payload: DefundObjectiveRequest = { ChannelId: channelId };
     return this.sendRequest("close_payment_channel", payload);
@@ -185,6 +208,10 @@ export class NitroRpcClient implements RpcClientApi {
     return this.sendRequest("version", {});
   }
 
+  public async GetHealth(): Promise<HealthInfo> {
+    return this.sendRequest("get_health", {});
+  }
+
   public async GetAddress(): Promise<string> {
     if (this.myAddress) {
       return this.myAddress;
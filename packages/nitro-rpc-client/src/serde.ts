@@ -2,6 +2,7 @@ import Ajv, { JTDDataType } from "ajv/dist/jtd";
 
 import {
   ChannelStatus,
+  LedgerChannelBalance,
   LedgerChannelInfo,
   PaymentChannelInfo,
   RPCNotification,
@@ -43,19 +44,24 @@ type ObjectiveSchemaType = JTDDataType<typeof objectiveSchema>;
 const stringSchema = { type: "string" } as const;
 type StringSchemaType = JTDDataType<typeof stringSchema>;
 
+const ledgerChannelBalanceSchema = {
+  properties: {
+    AssetAddress: { type: "string" },
+    Them: { type: "string" },
+    Me: { type: "string" },
+    MyBalance: { type: "string" },
+    TheirBalance: { type: "string" },
+  },
+} as const;
+
 const ledgerChannelSchema = {
   properties: {
     ID: { type: "string" },
     Status: { type: "string" },
-    Balance: {
-      properties: {
-        AssetAddress: { type: "string" },
-        Them: { type: "string" },
-        Me: { type: "string" },
-        MyBalance: { type: "string" },
-        TheirBalance: { type: "string" },
-      },
-    },
+    Balance: ledgerChannelBalanceSchema,
+  },
+  optionalProperties: {
+    Balances: { elements: ledgerChannelBalanceSchema },
   },
 } as const;
 type LedgerChannelSchemaType = JTDDataType<typeof ledgerChannelSchema>;
@@ -119,6 +125,17 @@ const receiveVoucherSchema = {
 
 type ReceiveVoucherSchemaType = JTDDataType<typeof receiveVoucherSchema>;
 
+const healthSchema = {
+  properties: {
+    ChainConnected: { type: "boolean" },
+    MessagingConnected: { type: "boolean" },
+    StoreOpen: { type: "boolean" },
+    Ready: { type: "boolean" },
+  },
+} as const;
+
+type HealthSchemaType = JTDDataType<typeof healthSchema>;
+
 type ResponseSchema =
   | typeof objectiveSchema
   | typeof stringSchema
@@ -128,7 +145,8 @@ type ResponseSchema =
   | typeof paymentChannelsSchema
   | typeof paymentSchema
   | typeof voucherSchema
-  | typeof receiveVoucherSchema;
+  | typeof receiveVoucherSchema
+  | typeof healthSchema;
 
 type ResponseSchemaType =
   | ObjectiveSchemaType
@@ -139,7 +157,8 @@ type ResponseSchemaType =
   | PaymentChannelsSchemaType
   | PaymentSchemaType
   | VoucherSchemaType
-  | ReceiveVoucherSchemaType;
+  | ReceiveVoucherSchemaType
+  | HealthSchemaType;
 
 /**
  * Validates that the response is a valid JSON RPC response with a valid result
@@ -165,6 +184,7 @@ export function getAndValidateResult<T extends RequestMethod>(
       );
     case "get_auth_token":
     case "close_ledger_channel":
+    case "top_up_ledger_channel":
     case "version":
     case "get_address":
     case "close_payment_channel":
@@ -173,6 +193,12 @@ export function getAndValidateResult<T extends RequestMethod>(
         result,
         (result: StringSchemaType) => result
       );
+    case "get_health":
+      return validateAndConvertResult(
+        healthSchema,
+        result,
+        (result: HealthSchemaType) => result
+      );
     case "get_ledger_channel":
       return validateAndConvertResult(
         ledgerChannelSchema,
@@ -205,6 +231,7 @@ export function getAndValidateResult<T extends RequestMethod>(
         (result: PaymentSchemaType) => result
       );
     case "receive_voucher":
+    case "get_voucher_balance":
       return validateAndConvertResult(
         receiveVoucherSchema,
         result,
@@ -300,11 +327,18 @@ function convertToInternalLedgerChannelType(
   return {
     ...result,
     Status: result.Status as ChannelStatus,
-    Balance: {
-      ...result.Balance,
-      TheirBalance: BigInt(result.Balance.TheirBalance),
-      MyBalance: BigInt(result.Balance.MyBalance),
-    },
+    Balance: convertToInternalLedgerBalanceType(result.Balance),
+    Balances: result.Balances?.map(convertToInternalLedgerBalanceType),
+  };
+}
+
+function convertToInternalLedgerBalanceType(
+  balance: LedgerChannelSchemaType["Balance"]
+): LedgerChannelBalance {
+  return {
+    ...balance,
+    TheirBalance: BigInt(balance.TheirBalance),
+    MyBalance: BigInt(balance.MyBalance),
   };
 }
 
@@ -11,9 +11,13 @@ import {
 
 import { Transport } from ".";
 
-const NITRO_REQUEST_TOPIC = "nitro-request";
 const NITRO_NOTIFICATION_TOPIC = "nitro-notify";
 
+// subject returns the subject on which requests to the method are made. It must match serde.Subject in go-nitro.
+function subject(method: RequestMethod): string {
+  return `nitro.${method}`;
+}
+
 export class NatsTransport {
   private natsConn: NatsConnection;
 
@@ -73,7 +77,7 @@ export class NatsTransport {
     req: RPCRequestAndResponses[K][0]
   ): Promise<RPCRequestAndResponses[K][1]> {
     const natsRes = await this.natsConn?.request(
-      NITRO_REQUEST_TOPIC,
+      subject(req.method),
       JSONCodec().encode(req)
     );
 
@@ -63,12 +63,21 @@ type GetChannelRequest = {
   Id: string;
 };
 
+type GetVoucherBalancePayload = {
+  Id: string;
+  Caller?: string;
+};
+
 type GetByLedgerRequest = {
   LedgerId: string;
 };
 export type DefundObjectiveRequest = {
   ChannelId: string;
 };
+export type TopUpObjectiveRequest = {
+  ChannelId: string;
+  Amount: number;
+};
 export type ObjectiveResponse = {
   Id: string;
   ChannelId: string;
@@ -78,6 +87,13 @@ export type ReceiveVoucherResult = {
   Delta: bigint;
 };
 
+export type HealthInfo = {
+  ChainConnected: boolean;
+  MessagingConnected: boolean;
+  StoreOpen: boolean;
+  Ready: boolean;
+};
+
 /**
  * RPC Requests
  */
@@ -116,10 +132,15 @@ export type GetPaymentChannelsByLedgerRequest = JsonRpcRequest<
 >;
 
 export type VersionRequest = JsonRpcRequest<"version", Record<string, never>>;
+export type HealthRequest = JsonRpcRequest<"get_health", Record<string, never>>;
 export type DirectDefundRequest = JsonRpcRequest<
   "close_ledger_channel",
   DefundObjectiveRequest
 >;
+export type LedgerTopUpRequest = JsonRpcRequest<
+  "top_up_ledger_channel",
+  TopUpObjectiveRequest
+>;
 export type VirtualDefundRequest = JsonRpcRequest<
   "close_payment_channel",
   DefundObjectiveRequest
@@ -131,6 +152,10 @@ export type CreateVoucherRequest = JsonRpcRequest<
 >;
 
 export type ReceiveVoucherRequest = JsonRpcRequest<"receive_voucher", Voucher>;
+export type GetVoucherBalanceRequest = JsonRpcRequest<
+  "get_voucher_balance",
+  GetVoucherBalancePayload
+>;
 
 /**
  * RPC Responses
@@ -141,9 +166,11 @@ export type PaymentResponse = JsonRpcResponse<PaymentPayload>;
 export type GetLedgerChannelResponse = JsonRpcResponse<LedgerChannelInfo>;
 export type VirtualFundResponse = JsonRpcResponse<ObjectiveResponse>;
 export type VersionResponse = JsonRpcResponse<string>;
+export type HealthResponse = JsonRpcResponse<HealthInfo>;
 export type GetAddressResponse = JsonRpcResponse<string>;
 export type DirectFundResponse = JsonRpcResponse<ObjectiveResponse>;
 export type DirectDefundResponse = JsonRpcResponse<string>;
+export type LedgerTopUpResponse = JsonRpcResponse<string>;
 export type VirtualDefundResponse = JsonRpcResponse<string>;
 export type GetAllLedgerChannelsResponse = JsonRpcResponse<LedgerChannelInfo[]>;
 export type GetPaymentChannelsByLedgerResponse = JsonRpcResponse<
@@ -151,6 +178,7 @@ export type GetPaymentChannelsByLedgerResponse = JsonRpcResponse<
 >;
 export type CreateVoucherResponse = JsonRpcResponse<Voucher>;
 export type ReceiveVoucherResponse = JsonRpcResponse<ReceiveVoucherResult>;
+export type GetVoucherBalanceResponse = JsonRpcResponse<ReceiveVoucherResult>;
 /**
  * RPC Request/Response map
  * This is a map of all the RPC methods to their request and response types
@@ -159,7 +187,9 @@ export type RPCRequestAndResponses = {
   get_auth_token: [GetAuthTokenRequest, GetAuthTokenResponse];
   create_ledger_channel: [DirectFundRequest, DirectFundResponse];
   close_ledger_channel: [DirectDefundRequest, DirectDefundResponse];
+  top_up_ledger_channel: [LedgerTopUpRequest, LedgerTopUpResponse];
   version: [VersionRequest, VersionResponse];
+  get_health: [HealthRequest, HealthResponse];
   create_payment_channel: [VirtualFundRequest, VirtualFundResponse];
   get_address: [GetAddressRequest, GetAddressResponse];
   get_ledger_channel: [GetLedgerChannelRequest, GetLedgerChannelResponse];
@@ -176,6 +206,7 @@ export type RPCRequestAndResponses = {
   ];
   create_voucher: [CreateVoucherRequest, CreateVoucherResponse];
   receive_voucher: [ReceiveVoucherRequest, ReceiveVoucherResponse];
+  get_voucher_balance: [GetVoucherBalanceRequest, GetVoucherBalanceResponse];
 };
 
 export type RequestMethod = keyof RPCRequestAndResponses;
@@ -215,7 +246,10 @@ export type ObjectiveCompleteNotification = JsonRpcNotification<
 export type LedgerChannelInfo = {
   ID: string;
   Status: ChannelStatus;
+  // Balance is the balance of the first asset in the channel
   Balance: LedgerChannelBalance;
+  // Balances contains the balance of every asset in the channel
+  Balances?: LedgerChannelBalance[];
 };
 
 export type LedgerChannelBalance = {
@@ -1,11 +1,13 @@
 package payments
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sync"
 	"testing"
 
 	"github.com/statechannels/go-nitro/internal/safesync"
@@ -115,9 +117,9 @@ func TestPaymentManager(t *testing.T) {
 	Equals(t, received, payment)
 	Equals(t, delta, payment)
 	Equals(t, onePaymentMade, getBalance(receiptMgr))
-	// Receiving a voucher is idempotent
+	// Receiving the same voucher again is rejected
 	received, delta, err = receiptMgr.Receive(firstVoucher)
-	Ok(t, err)
+	Assert(t, errors.Is(err, ErrStaleVoucher), "expected a replayed voucher to be rejected")
 	Equals(t, received, payment)
 	Equals(t, delta, big.NewInt(0))
 	Equals(t, onePaymentMade, getBalance(receiptMgr))
@@ -144,9 +146,9 @@ func TestPaymentManager(t *testing.T) {
 	Assert(t, err != nil, "expected register to fail")
 	Equals(t, twoPaymentsMade, getBalance(receiptMgr))
 
-	// Receiving old vouchers is ok
+	// Receiving old vouchers is rejected, without reducing the amount received
 	received, delta, err = receiptMgr.Receive(firstVoucher)
-	Ok(t, err)
+	Assert(t, errors.Is(err, ErrStaleVoucher), "expected an old voucher to be rejected")
 	Equals(t, doublePayment, received)
 	Equals(t, delta, big.NewInt(0))
 	Equals(t, twoPaymentsMade, getBalance(receiptMgr))
@@ -171,7 +173,12 @@ func TestPaymentManager(t *testing.T) {
 	voucher := testVoucher(channelId, payment, testactors.Alice)
 	voucher.Amount = triplePayment
 	_, _, err = receiptMgr.Receive(voucher)
-	Assert(t, err != nil, "expected an error")
+	Assert(t, errors.Is(err, ErrWrongSigner), "expected a voucher with the wrong signature to be rejected")
+	Equals(t, twoPaymentsMade, getBalance(receiptMgr))
+
+	// Receiving a voucher signed by someone other than the payer fails
+	_, _, err = receiptMgr.Receive(testVoucher(channelId, triplePayment, testactors.Bob))
+	Assert(t, errors.Is(err, ErrWrongSigner), "expected a voucher signed by the payee to be rejected")
 	Equals(t, twoPaymentsMade, getBalance(receiptMgr))
 }
 
@@ -214,3 +221,105 @@ func Equals(tb testing.TB, want, got interface{}) {
 		tb.FailNow()
 	}
 }
+
+func TestNonIncreasingVouchersAreNotSigned(t *testing.T) {
+	channelId := types.Destination{1}
+	paymentMgr := NewVoucherManager(testactors.Alice.Address(), newSimpleVoucherStore())
+	Ok(t, paymentMgr.Register(channelId, testactors.Alice.Address(), testactors.Bob.Address(), big.NewInt(1000)))
+
+	voucher, err := paymentMgr.Pay(channelId, big.NewInt(5), testactors.Alice.PrivateKey)
+	Ok(t, err)
+	Equals(t, big.NewInt(5), voucher.Amount)
+
+	// A "payment" which would sign a voucher for a total of 3, or for the same total again, is rejected
+	for _, amount := range []*big.Int{big.NewInt(-2), big.NewInt(0)} {
+		_, err = paymentMgr.Pay(channelId, amount, testactors.Alice.PrivateKey)
+		Assert(t, errors.Is(err, ErrNonIncreasingVoucher), "expected paying %s to be rejected with %v, got %v", amount, ErrNonIncreasingVoucher, err)
+	}
+
+	paid, err := paymentMgr.Paid(channelId)
+	Ok(t, err)
+	Equals(t, big.NewInt(5), paid)
+}
+
+func TestBalanceQueriesAreScopedToTheCaller(t *testing.T) {
+	channelId := types.Destination{1}
+	store := newSimpleVoucherStore()
+	paymentMgr := NewVoucherManager(testactors.Alice.Address(), newSimpleVoucherStore())
+	receiptMgr := NewVoucherManager(testactors.Bob.Address(), store)
+	for _, m := range []*VoucherManager{paymentMgr, receiptMgr} {
+		Ok(t, m.Register(channelId, testactors.Alice.Address(), testactors.Bob.Address(), big.NewInt(1000)))
+	}
+	pay := func(amount int64) {
+		t.Helper()
+		voucher, err := paymentMgr.Pay(channelId, big.NewInt(amount), testactors.Alice.PrivateKey)
+		Ok(t, err)
+		_, _, err = receiptMgr.Receive(voucher)
+		Ok(t, err)
+	}
+	query := func(m *VoucherManager, caller string, wantTotal, wantDelta int64) {
+		t.Helper()
+		total, delta, err := m.QueryBalance(channelId, caller)
+		Ok(t, err)
+		Assert(t, total.Cmp(big.NewInt(wantTotal)) == 0 && delta.Cmp(big.NewInt(wantDelta)) == 0,
+			"expected %s to see a total of %d and a delta of %d, got %s and %s", caller, wantTotal, wantDelta, total, delta)
+	}
+
+	pay(5)
+	query(receiptMgr, "ui", 5, 5)
+	query(receiptMgr, "ui", 5, 0)
+	pay(3)
+	query(receiptMgr, "ui", 8, 3)
+	// Another caller sees the increase since its own first query
+	query(receiptMgr, "billing", 8, 8)
+
+	// A voucher manager over the same store picks up where the queries left off
+	restarted := NewVoucherManager(testactors.Bob.Address(), store)
+	pay(2)
+	query(restarted, "ui", 10, 2)
+	query(restarted, "billing", 10, 2)
+}
+
+func TestConcurrentBalanceQueriesSeeEachIncreaseOnce(t *testing.T) {
+	channelId := types.Destination{1}
+	paymentMgr := NewVoucherManager(testactors.Alice.Address(), newSimpleVoucherStore())
+	receiptMgr := NewVoucherManager(testactors.Bob.Address(), newSimpleVoucherStore())
+	for _, m := range []*VoucherManager{paymentMgr, receiptMgr} {
+		Ok(t, m.Register(channelId, testactors.Alice.Address(), testactors.Bob.Address(), big.NewInt(1000)))
+	}
+
+	const payments, queriers = 50, 4
+	deltas := make(chan *big.Int, payments*queriers)
+	var wg sync.WaitGroup
+	for i := 0; i < queriers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < payments; j++ {
+				_, delta, err := receiptMgr.QueryBalance(channelId, "ui")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				deltas <- delta
+			}
+		}()
+	}
+	for i := 0; i < payments; i++ {
+		voucher, err := paymentMgr.Pay(channelId, big.NewInt(1), testactors.Alice.PrivateKey)
+		Ok(t, err)
+		_, _, err = receiptMgr.Receive(voucher)
+		Ok(t, err)
+	}
+	wg.Wait()
+	close(deltas)
+
+	// However the queries interleave with each other and the payments, every unit paid is reported to exactly one of them
+	_, last, err := receiptMgr.QueryBalance(channelId, "ui")
+	Ok(t, err)
+	sum := big.NewInt(0).Set(last)
+	for delta := range deltas {
+		sum.Add(sum, delta)
+	}
+	Assert(t, sum.Cmp(big.NewInt(payments)) == 0, "expected the deltas to sum to %d, got %s", payments, sum)
+}
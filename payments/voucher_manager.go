@@ -3,11 +3,21 @@ package payments
 import (
 	"fmt"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/types"
 )
 
+const (
+	// ErrStaleVoucher is returned when a received voucher does not pay more than the largest voucher already received on the channel.
+	ErrStaleVoucher = types.ConstError("voucher does not exceed the largest voucher received")
+	// ErrWrongSigner is returned when a received voucher is not signed by the channel's payer.
+	ErrWrongSigner = types.ConstError("voucher is not signed by the channel payer")
+	// ErrNonIncreasingVoucher is returned when a payment would sign a voucher which does not exceed the largest voucher already signed on the channel.
+	ErrNonIncreasingVoucher = types.ConstError("voucher does not exceed the largest voucher signed")
+)
+
 // VoucherStore is an interface for storing voucher information that the voucher manager expects.
 // To avoid import cycles, this interface is defined in the payments package, but implemented in the store package.
 type VoucherStore interface {
@@ -21,18 +31,21 @@ type VoucherStore interface {
 type VoucherManager struct {
 	store VoucherStore
 	me    common.Address
+	mu    sync.Mutex // serialises the updates to each channel's voucher info, which are read, modified and written back
 }
 
 // NewVoucherManager creates a new voucher manager
 func NewVoucherManager(me types.Address, store VoucherStore) *VoucherManager {
-	return &VoucherManager{store, me}
+	return &VoucherManager{store: store, me: me}
 }
 
 // Register registers a channel for use, given the payer, payee and starting balance of the channel
 func (vm *VoucherManager) Register(channelId types.Destination, payer common.Address, payee common.Address, startingBalance *big.Int) error {
 	voucher := Voucher{ChannelId: channelId, Amount: big.NewInt(0)}
-	data := VoucherInfo{payer, payee, big.NewInt(0).Set(startingBalance), voucher}
+	data := VoucherInfo{ChannelPayer: payer, ChannelPayee: payee, StartingBalance: big.NewInt(0).Set(startingBalance), LargestVoucher: voucher}
 
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
 	if v, _ := vm.store.GetVoucherInfo(channelId); v != nil {
 		return fmt.Errorf("channel already registered")
 	}
@@ -51,6 +64,9 @@ func (vm *VoucherManager) Remove(channelId types.Destination) error {
 // Pay will deduct amount from balance and add it to paid, returning a signed voucher for the
 // total amount paid.
 func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, pk []byte) (Voucher, error) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
 	vInfo, err := vm.store.GetVoucherInfo(channelId)
 	if err != nil {
 		return Voucher{}, fmt.Errorf("channel not registered: %w", err)
@@ -64,14 +80,17 @@ func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, pk [
 		return Voucher{}, fmt.Errorf("can only sign vouchers if we're the payer")
 	}
 	newAmount := big.NewInt(0).Add(vInfo.LargestVoucher.Amount, amount)
-	voucher := Voucher{Amount: big.NewInt(0).Set(newAmount), ChannelId: channelId}
-
-	vInfo.LargestVoucher = voucher
-
-	if err := voucher.Sign(pk); err != nil {
+	// A payee rejects a voucher which does not pay more than the last, so one is never signed
+	if !types.Gt(newAmount, vInfo.LargestVoucher.Amount) {
+		return Voucher{}, fmt.Errorf("paying %s would sign a voucher for %s, but a voucher for %s has been signed: %w", amount, newAmount, vInfo.LargestVoucher.Amount, ErrNonIncreasingVoucher)
+	}
+	voucher, err := SignVoucher(channelId, newAmount, pk)
+	if err != nil {
 		return voucher, err
 	}
 
+	vInfo.LargestVoucher = voucher
+
 	err = vm.store.SetVoucherInfo(channelId, *vInfo)
 	if err != nil {
 		return Voucher{}, err
@@ -79,8 +98,12 @@ func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, pk [
 	return voucher, nil
 }
 
-// Receive validates the incoming voucher, and returns the total amount received so far as well as the amount received from the voucher
+// Receive validates the incoming voucher, and returns the total amount received so far as well as the amount received from the voucher.
+// A voucher which does not exceed the largest voucher already received is rejected with ErrStaleVoucher.
 func (vm *VoucherManager) Receive(voucher Voucher) (total *big.Int, delta *big.Int, err error) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
 	vInfo, err := vm.store.GetVoucherInfo(voucher.ChannelId)
 	if err != nil {
 		return &big.Int{}, &big.Int{}, fmt.Errorf("channel not registered: %w", err)
@@ -95,9 +118,10 @@ func (vm *VoucherManager) Receive(voucher Voucher) (total *big.Int, delta *big.I
 		return &big.Int{}, &big.Int{}, fmt.Errorf("channel has insufficient funds")
 	}
 
+	// Rejecting vouchers which do not increase the amount paid prevents the payer from replaying old vouchers
 	total = vInfo.LargestVoucher.Amount
 	if !types.Gt(voucher.Amount, total) {
-		return total, big.NewInt(0), nil
+		return total, big.NewInt(0), fmt.Errorf("voucher for %s on channel %s, already received %s: %w", voucher.Amount, voucher.ChannelId, total, ErrStaleVoucher)
 	}
 
 	signer, err := voucher.RecoverSigner()
@@ -105,7 +129,7 @@ func (vm *VoucherManager) Receive(voucher Voucher) (total *big.Int, delta *big.I
 		return &big.Int{}, &big.Int{}, err
 	}
 	if signer != vInfo.ChannelPayer {
-		return &big.Int{}, &big.Int{}, fmt.Errorf("wrong signer: %+v, %+v", signer, vInfo.ChannelPayer)
+		return &big.Int{}, &big.Int{}, fmt.Errorf("wrong signer: %+v, %+v: %w", signer, vInfo.ChannelPayer, ErrWrongSigner)
 	}
 	// Check the difference between our largest voucher and this new one
 	delta = big.NewInt(0).Sub(voucher.Amount, total)
@@ -120,6 +144,36 @@ func (vm *VoucherManager) Receive(voucher Voucher) (total *big.Int, delta *big.I
 	return total, delta, nil
 }
 
+// QueryBalance returns the amount of the largest voucher on a channel, along with how much it has increased since the caller last queried it.
+// Each caller's last query is recorded with the channel's voucher info, so callers do not see each other's queries, and a restart does not reset them.
+func (vm *VoucherManager) QueryBalance(channelId types.Destination, caller string) (total *big.Int, delta *big.Int, err error) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	vInfo, err := vm.store.GetVoucherInfo(channelId)
+	if err != nil {
+		return &big.Int{}, &big.Int{}, fmt.Errorf("channel not registered: %w", err)
+	}
+	total = big.NewInt(0).Set(vInfo.LargestVoucher.Amount)
+	delta = big.NewInt(0).Set(total)
+	if last, ok := vInfo.QueriedBalances[caller]; ok {
+		delta.Sub(total, last)
+	}
+
+	// The map may be shared with the store's copy of the voucher info, so it is replaced rather than modified
+	queried := make(map[string]*big.Int, len(vInfo.QueriedBalances)+1)
+	for c, amount := range vInfo.QueriedBalances {
+		queried[c] = amount
+	}
+	queried[caller] = total
+	vInfo.QueriedBalances = queried
+
+	if err := vm.store.SetVoucherInfo(channelId, *vInfo); err != nil {
+		return nil, nil, err
+	}
+	return total, delta, nil
+}
+
 // ChannelRegistered returns  whether a channel has been registered with the voucher manager or not
 func (vm *VoucherManager) ChannelRegistered(channelId types.Destination) bool {
 	_, err := vm.store.GetVoucherInfo(channelId)
@@ -35,6 +35,8 @@ type VoucherInfo struct {
 	ChannelPayee    common.Address
 	StartingBalance *big.Int
 	LargestVoucher  Voucher
+	// QueriedBalances records the amount of the largest voucher when each caller last queried the channel's balance, keyed by caller
+	QueriedBalances map[string]*big.Int `json:",omitempty"`
 }
 
 type ReceiveVoucherSummary struct {
@@ -42,17 +44,53 @@ type ReceiveVoucherSummary struct {
 	Delta *big.Int
 }
 
-func (v *Voucher) Hash() (types.Bytes32, error) {
+// SignVoucher constructs a voucher paying amount on the given channel, signed with the supplied secret key.
+func SignVoucher(channelId types.Destination, amount *big.Int, sk []byte) (Voucher, error) {
+	v := Voucher{ChannelId: channelId, Amount: big.NewInt(0).Set(amount)}
+	if err := v.Sign(sk); err != nil {
+		return Voucher{}, err
+	}
+	return v, nil
+}
+
+// Encode returns the canonical encoding of the voucher: its channel id and amount, abi encoded as (bytes32, uint256).
+// The signature is not part of the encoding.
+func (v *Voucher) Encode() ([]byte, error) {
 	encoded, err := abi.Arguments{
 		{Type: nitroAbi.Destination},
 		{Type: nitroAbi.Uint256},
 	}.Pack(v.ChannelId, v.Amount)
 	if err != nil {
-		return types.Bytes32{}, fmt.Errorf("failed to encode voucher: %w", err)
+		return nil, fmt.Errorf("failed to encode voucher: %w", err)
+	}
+	return encoded, nil
+}
+
+// DecodeVoucher decodes a voucher from its canonical encoding. The returned voucher is unsigned.
+func DecodeVoucher(encoded []byte) (Voucher, error) {
+	decoded, err := abi.Arguments{
+		{Type: nitroAbi.Destination},
+		{Type: nitroAbi.Uint256},
+	}.Unpack(encoded)
+	if err != nil {
+		return Voucher{}, fmt.Errorf("failed to decode voucher: %w", err)
+	}
+	return Voucher{
+		ChannelId: types.Destination(decoded[0].([32]byte)),
+		Amount:    decoded[1].(*big.Int),
+	}, nil
+}
+
+// Hash returns the keccak256 hash of the voucher's canonical encoding.
+func (v *Voucher) Hash() (types.Bytes32, error) {
+	encoded, err := v.Encode()
+	if err != nil {
+		return types.Bytes32{}, err
 	}
 	return crypto.Keccak256Hash(encoded), nil
 }
 
+// Sign signs the voucher's hash as an ethereum message with the supplied secret key.
 func (v *Voucher) Sign(pk []byte) error {
 	hash, err := v.Hash()
 	if err != nil {
@@ -69,6 +107,7 @@ func (v *Voucher) Sign(pk []byte) error {
 	return nil
 }
 
+// RecoverSigner returns the address which signed the voucher.
 func (v *Voucher) RecoverSigner() (types.Address, error) {
 	h, error := v.Hash()
 	if error != nil {
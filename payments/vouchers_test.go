@@ -0,0 +1,65 @@
+package payments
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestVoucherEncoding(t *testing.T) {
+	voucher := Voucher{ChannelId: types.Destination{1}, Amount: big.NewInt(2)}
+
+	wantEncoding := common.Hex2Bytes("0100000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000002")
+	wantHash := common.HexToHash("0x658f07e301f0aac5173c60919ae7d50657d290acd47c34402bfb13e50a725418")
+
+	t.Run("Encode", func(t *testing.T) {
+		got, err := voucher.Encode()
+		Ok(t, err)
+		Equals(t, wantEncoding, got)
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		got, err := DecodeVoucher(wantEncoding)
+		Ok(t, err)
+		if !reflect.DeepEqual(got, voucher) {
+			t.Fatalf("incorrect decoding, expected %+v got %+v", voucher, got)
+		}
+
+		_, err = DecodeVoucher(wantEncoding[:32])
+		Assert(t, err != nil, "expected decoding a truncated voucher to fail")
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		got, err := voucher.Hash()
+		Ok(t, err)
+		Equals(t, wantHash, got)
+	})
+}
+
+func TestSignVoucher(t *testing.T) {
+	wantSignature := crypto.Signature{
+		R: common.Hex2Bytes("164efcb4f11cbc1dbd955e6aa59ae298c2503480411b3d07119057a894186b27"),
+		S: common.Hex2Bytes("2bc9f200f8f03f667e41cdbbdd15edf7c8741a22eb1e257c90029a3d1b4cf4ea"),
+		V: byte(27),
+	}
+
+	voucher, err := SignVoucher(types.Destination{1}, big.NewInt(2), testactors.Alice.PrivateKey)
+	Ok(t, err)
+	Assert(t, voucher.Signature.Equal(wantSignature), "incorrect signature, expected %+v got %+v", wantSignature, voucher.Signature)
+
+	signer, err := voucher.RecoverSigner()
+	Ok(t, err)
+	Equals(t, testactors.Alice.Address(), signer)
+
+	// Changing the amount invalidates the signature
+	voucher.Amount = big.NewInt(3)
+	signer, err = voucher.RecoverSigner()
+	Ok(t, err)
+	Assert(t, signer != testactors.Alice.Address(), "expected a tampered voucher to recover a different signer")
+}
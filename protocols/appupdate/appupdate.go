@@ -0,0 +1,327 @@
+// Package appupdate implements a protocol to move a funded application channel to a new state, without changing how it is funded.
+package appupdate // import "github.com/statechannels/go-nitro/appupdate"
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	WaitingForCompleteAppUpdate = protocols.WaitingForCompleteAppUpdate
+	WaitingForNothing           = protocols.WaitingForNothing // Finished
+)
+
+const (
+	SignedStatePayload = protocols.SignedStatePayload
+)
+
+const ObjectivePrefix = "AppUpdate-"
+
+const (
+	ErrNoSuchChannel    = types.ConstError("can only update the app state of a known channel")
+	ErrPaymentChannel   = types.ConstError("the app state of a payment channel is updated with vouchers")
+	ErrNotFunded        = types.ConstError("can only update the app state of a funded channel")
+	ErrChannelFinalized = types.ConstError("can only update the app state of a channel which is not finalized")
+	ErrStaleTurnNum     = types.ConstError("the turn number of an app update must be greater than that of the latest supported state")
+	ErrFinalState       = types.ConstError("an app update cannot finalize the channel")
+	ErrFundingChanged   = types.ConstError("an app update cannot change the total allocated to the channel")
+	ErrInvalidAppUpdate = types.ConstError("state does not match the app update")
+)
+
+// GetChannelByIdFunction specifies a function that can be used to retrieve channels from a store.
+type GetChannelByIdFunction func(id types.Destination) (channel *channel.Channel, ok bool)
+
+// Objective is a cache of data computed by reading from the store. It stores (potentially) infinite data
+type Objective struct {
+	Status protocols.ObjectiveStatus
+	C      *channel.Channel
+
+	updateState state.State // the state which the channel is moving to
+}
+
+// NewObjective creates a new app update objective from a given request. The requesting participant signs the new state first.
+// paymentApp is the address of the VirtualPaymentApp, whose channels cannot be updated by this protocol.
+func NewObjective(request ObjectiveRequest, preApprove bool, paymentApp types.Address, getChannel GetChannelByIdFunction) (Objective, error) {
+	return newObjective(preApprove, request.State, paymentApp, getChannel)
+}
+
+// ConstructObjectiveFromPayload takes in a new state signed by the proposer and constructs an objective from it.
+func ConstructObjectiveFromPayload(
+	p protocols.ObjectivePayload,
+	preapprove bool,
+	paymentApp types.Address,
+	getChannel GetChannelByIdFunction,
+) (Objective, error) {
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	s := ss.State()
+
+	if id := objectiveId(s.ChannelId(), s.TurnNum); id != p.ObjectiveId {
+		return Objective{}, fmt.Errorf("objective %s does not match state %s: %w", p.ObjectiveId, id, ErrInvalidAppUpdate)
+	}
+
+	return newObjective(preapprove, s, paymentApp, getChannel)
+}
+
+// newObjective constructs an objective which moves the supplied channel to the update state.
+func newObjective(preApprove bool, updateState state.State, paymentApp types.Address, getChannel GetChannelByIdFunction) (Objective, error) {
+	c, ok := getChannel(updateState.ChannelId())
+	if !ok {
+		return Objective{}, fmt.Errorf("channel %s: %w", updateState.ChannelId(), ErrNoSuchChannel)
+	}
+	err := validateUpdate(c, updateState, paymentApp)
+	if err != nil {
+		return Objective{}, err
+	}
+
+	init := Objective{}
+	if preApprove {
+		init.Status = protocols.Approved
+	} else {
+		init.Status = protocols.Unapproved
+	}
+	init.C = c.Clone()
+	init.updateState = updateState.Clone()
+
+	return init, nil
+}
+
+// validateUpdate returns an error unless s is a valid app update of the channel: the channel must be funded and not finalized,
+// and s must be a later, non final state which allocates the same total of each asset as the latest supported state.
+func validateUpdate(c *channel.Channel, s state.State, paymentApp types.Address) error {
+	if c.AppDefinition == paymentApp {
+		return ErrPaymentChannel
+	}
+	if !c.PostFundComplete() {
+		return ErrNotFunded
+	}
+	latest, err := c.LatestSupportedState()
+	if err != nil {
+		return fmt.Errorf("could not get latest supported state of channel %s: %w", c.Id, err)
+	}
+	if latest.IsFinal {
+		return ErrChannelFinalized
+	}
+	if s.TurnNum <= latest.TurnNum {
+		return fmt.Errorf("turn number %d is not greater than %d: %w", s.TurnNum, latest.TurnNum, ErrStaleTurnNum)
+	}
+	if s.IsFinal {
+		return ErrFinalState
+	}
+	if err := s.Outcome.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAppUpdate, err)
+	}
+	if !s.Outcome.TotalAllocated().Equal(latest.Outcome.TotalAllocated()) {
+		return ErrFundingChanged
+	}
+	return nil
+}
+
+// Id returns the unique id of the objective
+func (o *Objective) Id() protocols.ObjectiveId {
+	return objectiveId(o.C.Id, o.updateState.TurnNum)
+}
+
+func (o *Objective) Approve() protocols.Objective {
+	updated := o.clone()
+	// todo: consider case of o.Status == Rejected
+	updated.Status = protocols.Approved
+
+	return &updated
+}
+
+func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+	updated := o.clone()
+	updated.Status = protocols.Rejected
+
+	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), o.otherParticipants()...)}
+	return &updated, sideEffects
+}
+
+// OwnsChannel returns the channel that the objective is updating.
+func (o *Objective) OwnsChannel() types.Destination {
+	return o.C.Id
+}
+
+// GetStatus returns the status of the objective.
+func (o *Objective) GetStatus() protocols.ObjectiveStatus {
+	return o.Status
+}
+
+func (o *Objective) Related() []protocols.Storable {
+	return []protocols.Storable{o.C}
+}
+
+// Update receives an ObjectivePayload, applies all applicable data to the Objective,
+// and returns the updated objective
+func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
+	if o.Id() != p.ObjectiveId {
+		return o, fmt.Errorf("event and objective Ids do not match: %s and %s respectively", string(p.ObjectiveId), string(o.Id()))
+	}
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return o, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	if len(ss.Signatures()) == 0 {
+		return o, fmt.Errorf("event does not contain a signed state")
+	}
+	if !ss.State().Equal(o.updateState) {
+		return o, ErrInvalidAppUpdate
+	}
+
+	updated := o.clone()
+	updated.C.AddSignedState(ss)
+	return &updated, nil
+}
+
+// ProposedOutcome returns the outcome of the channel's latest supported state and the outcome of the update state in the payload
+func (o *Objective) ProposedOutcome(p protocols.ObjectivePayload) (current, proposed outcome.Exit, ok bool, err error) {
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	supported, err := o.C.LatestSupportedState()
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("could not get latest supported state: %w", err)
+	}
+	return supported.Outcome, ss.State().Outcome, true, nil
+}
+
+// Crank inspects the extended state and declares a list of Effects to be executed
+func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := o.clone()
+
+	sideEffects := protocols.SideEffects{}
+
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
+	}
+
+	// Every participant signs the update state as soon as they have approved it, since it does not change the funding of the channel
+	if !updated.updateSignedByMe() {
+		ss, err := updated.C.SignAndAddState(updated.updateState, secretKey)
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteAppUpdate, fmt.Errorf("could not sign app update state %w", err)
+		}
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.Id(), ss, SignedStatePayload, updated.otherParticipants()...)
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteAppUpdate, fmt.Errorf("could not create payload message %w", err)
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+	}
+
+	if !updated.updateComplete() {
+		return &updated, sideEffects, WaitingForCompleteAppUpdate, nil
+	}
+
+	// Completion
+	updated.Status = protocols.Completed
+	return &updated, sideEffects, WaitingForNothing, nil
+}
+
+// IsAppUpdateObjective inspects a objective id and returns true if the objective id is for an app update objective.
+func IsAppUpdateObjective(id protocols.ObjectiveId) bool {
+	return strings.HasPrefix(string(id), ObjectivePrefix)
+}
+
+//  Private methods on the Objective
+
+// updateSignedByMe returns true if I have signed the update state.
+func (o *Objective) updateSignedByMe() bool {
+	ss, ok := o.C.OffChain.SignedStateForTurnNum[o.updateState.TurnNum]
+	return ok && ss.HasSignatureForParticipant(o.C.MyIndex)
+}
+
+// updateComplete returns true if the update state has been signed by every participant.
+func (o *Objective) updateComplete() bool {
+	ss, ok := o.C.OffChain.SignedStateForTurnNum[o.updateState.TurnNum]
+	return ok && ss.HasAllSignatures()
+}
+
+// otherParticipants returns the participants in the channel that are not the current participant.
+func (o *Objective) otherParticipants() []types.Address {
+	others := make([]types.Address, 0)
+	for i, p := range o.C.Participants {
+		if i != int(o.C.MyIndex) {
+			others = append(others, p)
+		}
+	}
+	return others
+}
+
+// clone returns a deep copy of the receiver.
+func (o *Objective) clone() Objective {
+	clone := Objective{}
+	clone.Status = o.Status
+	clone.C = o.C.Clone()
+	clone.updateState = o.updateState.Clone()
+	return clone
+}
+
+// GetChannelFromObjectiveId returns the id of the channel which the app update objective with the given id updates.
+func GetChannelFromObjectiveId(id protocols.ObjectiveId) (types.Destination, error) {
+	if !IsAppUpdateObjective(id) {
+		return types.Destination{}, fmt.Errorf("id %s does not have prefix %s", id, ObjectivePrefix)
+	}
+	raw, _, ok := strings.Cut(string(id)[len(ObjectivePrefix):], "-")
+	if !ok {
+		return types.Destination{}, fmt.Errorf("id %s does not have a turn number", id)
+	}
+	return types.Destination(common.HexToHash(raw)), nil
+}
+
+// objectiveId returns the id of the app update objective which moves the channel to the given turn number.
+func objectiveId(channelId types.Destination, turnNum uint64) protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + channelId.String() + "-" + strconv.FormatUint(turnNum, 10))
+}
+
+// ObjectiveRequest represents a request to create a new app update objective.
+type ObjectiveRequest struct {
+	State            state.State
+	objectiveStarted chan struct{}
+}
+
+// NewObjectiveRequest creates a new ObjectiveRequest which moves the channel of the given state to it.
+func NewObjectiveRequest(s state.State) ObjectiveRequest {
+	return ObjectiveRequest{
+		State:            s,
+		objectiveStarted: make(chan struct{}),
+	}
+}
+
+// SignalObjectiveStarted is used by the engine to signal the objective has been started.
+func (r ObjectiveRequest) SignalObjectiveStarted() {
+	close(r.objectiveStarted)
+}
+
+// WaitForObjectiveToStart blocks until the objective starts
+func (r ObjectiveRequest) WaitForObjectiveToStart() {
+	<-r.objectiveStarted
+}
+
+// Id returns the objective id for the request.
+func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return objectiveId(r.State.ChannelId(), r.State.TurnNum)
+}
+
+// getSignedStatePayload takes in a serialized signed state payload and returns the deserialized SignedState.
+func getSignedStatePayload(b []byte) (state.SignedState, error) {
+	ss := state.SignedState{}
+	err := json.Unmarshal(b, &ss)
+	if err != nil {
+		return ss, fmt.Errorf("could not unmarshal signed state: %w", err)
+	}
+	return ss, nil
+}
@@ -0,0 +1,169 @@
+package appupdate
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var alice, bob testactors.Actor = testactors.Alice, testactors.Bob
+
+var (
+	paymentApp = types.Address{}
+	someApp    = common.HexToAddress("0x5e29E5Ab8EF33F050c7cc10B5a0456D975C5F88d")
+)
+
+// newTestChannel returns alice's and bob's views of a funded application channel.
+func newTestChannel(t *testing.T) (aliceView, bobView *channel.Channel) {
+	s := state.State{
+		Participants:      []types.Address{alice.Address(), bob.Address()},
+		ChannelNonce:      37140676580,
+		AppDefinition:     someApp,
+		ChallengeDuration: 60,
+		AppData:           []byte{},
+		Outcome:           td.Outcomes.Create(alice.Address(), bob.Address(), 6, 4, types.Address{}),
+	}
+	views := make([]*channel.Channel, 2)
+	for i := range views {
+		c, err := channel.New(s, uint(i))
+		testhelpers.Ok(t, err)
+		for _, pk := range [][]byte{alice.PrivateKey, bob.PrivateKey} {
+			for _, s := range []state.State{c.PreFundState(), c.PostFundState()} {
+				sig, err := s.Sign(pk)
+				testhelpers.Ok(t, err)
+				testhelpers.Assert(t, c.AddStateWithSignature(s, sig), "could not add signed state")
+			}
+		}
+		c.OnChain.Holdings = types.Funds{types.Address{}: big.NewInt(10)}
+		views[i] = c
+	}
+	return views[0], views[1]
+}
+
+func lookup(c *channel.Channel) GetChannelByIdFunction {
+	return func(id types.Destination) (*channel.Channel, bool) { return c, id == c.Id }
+}
+
+// nextState returns the latest supported state of the channel, moved on by one turn with the given app data and outcome
+func nextState(t *testing.T, c *channel.Channel, appData []byte, aBalance, bBalance uint64) state.State {
+	s, err := c.LatestSupportedState()
+	testhelpers.Ok(t, err)
+	s.TurnNum += 1
+	s.AppData = appData
+	s.Outcome = td.Outcomes.Create(alice.Address(), bob.Address(), aBalance, bBalance, types.Address{})
+	return s
+}
+
+// update runs an app update objective proposed by alice to completion, and returns both views of the updated channel
+func update(t *testing.T, aliceView, bobView *channel.Channel, s state.State) (*channel.Channel, *channel.Channel) {
+	request := NewObjectiveRequest(s)
+	aliceObj, err := NewObjective(request, true, paymentApp, lookup(aliceView))
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, request.Id(alice.Address(), nil), aliceObj.Id())
+
+	// The proposer signs the new state straight away
+	o, se, waitingFor, err := aliceObj.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForCompleteAppUpdate, waitingFor)
+	testhelpers.Equals(t, 1, len(se.MessagesToSend))
+	testhelpers.Equals(t, 0, len(se.TransactionsToSubmit))
+	toBob := se.MessagesToSend[0].ObjectivePayloads[0]
+
+	// The counterparty signs once it has approved the update
+	bobObj, err := ConstructObjectiveFromPayload(toBob, false, paymentApp, lookup(bobView))
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, aliceObj.Id(), bobObj.Id())
+	current, proposed, ok, err := bobObj.ProposedOutcome(toBob)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, true, ok)
+	supported, err := bobView.LatestSupportedState()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, supported.Outcome, current)
+	testhelpers.Equals(t, s.Outcome, proposed)
+	bobUpdated, err := bobObj.Update(toBob)
+	testhelpers.Ok(t, err)
+	_, _, _, err = bobUpdated.Crank(&bob.PrivateKey)
+	testhelpers.Assert(t, errors.Is(err, protocols.ErrNotApproved), "expected %v, got %v", protocols.ErrNotApproved, err)
+	bobDone, se, waitingFor, err := bobUpdated.Approve().Crank(&bob.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForNothing, waitingFor)
+	testhelpers.Equals(t, protocols.Completed, bobDone.GetStatus())
+	toAlice := se.MessagesToSend[0].ObjectivePayloads[0]
+
+	aliceUpdated, err := o.Update(toAlice)
+	testhelpers.Ok(t, err)
+	aliceDone, _, waitingFor, err := aliceUpdated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForNothing, waitingFor)
+	testhelpers.Equals(t, protocols.Completed, aliceDone.GetStatus())
+
+	aliceView, bobView = aliceDone.(*Objective).C, bobDone.(*Objective).C
+	for _, c := range []*channel.Channel{aliceView, bobView} {
+		latest, err := c.LatestSupportedState()
+		testhelpers.Ok(t, err)
+		testhelpers.Assert(t, latest.Equal(s), "expected the latest supported state to be the update state")
+		testhelpers.Equals(t, types.Funds{types.Address{}: big.NewInt(10)}, c.OnChain.Holdings)
+	}
+	return aliceView, bobView
+}
+
+func TestSequentialAppUpdates(t *testing.T) {
+	aliceView, bobView := newTestChannel(t)
+
+	first := nextState(t, aliceView, []byte{1}, 5, 5)
+	aliceView, bobView = update(t, aliceView, bobView, first)
+	testhelpers.Equals(t, uint64(2), first.TurnNum)
+
+	second := nextState(t, aliceView, []byte{2}, 3, 7)
+	aliceView, _ = update(t, aliceView, bobView, second)
+	testhelpers.Equals(t, uint64(3), second.TurnNum)
+
+	// The first update cannot be replayed
+	_, err := NewObjective(NewObjectiveRequest(first), true, paymentApp, lookup(aliceView))
+	testhelpers.Assert(t, errors.Is(err, ErrStaleTurnNum), "expected %v, got %v", ErrStaleTurnNum, err)
+}
+
+func TestInvalidAppUpdates(t *testing.T) {
+	aliceView, _ := newTestChannel(t)
+
+	sameTurn := nextState(t, aliceView, []byte{1}, 5, 5)
+	sameTurn.TurnNum = channel.PostFundTurnNum
+	final := nextState(t, aliceView, []byte{1}, 5, 5)
+	final.IsFinal = true
+	overdrawn := nextState(t, aliceView, []byte{1}, 6, 5)
+	unknown := nextState(t, aliceView, []byte{1}, 5, 5)
+	unknown.ChannelNonce += 1
+	negative := nextState(t, aliceView, []byte{1}, 0, 0)
+	negative.Outcome[0].Allocations[0].Amount = big.NewInt(-1)
+	negative.Outcome[0].Allocations[1].Amount = big.NewInt(11)
+
+	cases := []struct {
+		name string
+		s    state.State
+		app  types.Address
+		want error
+	}{
+		{"turn number does not increase", sameTurn, paymentApp, ErrStaleTurnNum},
+		{"final state", final, paymentApp, ErrFinalState},
+		{"funding changes", overdrawn, paymentApp, ErrFundingChanged},
+		{"negative allocation", negative, paymentApp, outcome.ErrNegativeAllocation},
+		{"unknown channel", unknown, paymentApp, ErrNoSuchChannel},
+		{"payment channel", nextState(t, aliceView, []byte{1}, 5, 5), someApp, ErrPaymentChannel},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewObjective(NewObjectiveRequest(c.s), true, c.app, lookup(aliceView))
+			testhelpers.Assert(t, errors.Is(err, c.want), "expected %v, got %v", c.want, err)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package appupdate
+
+import (
+	"encoding/json"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// jsonObjective replaces the appupdate.Objective's channel pointer with the
+// channel's ID, making jsonObjective suitable for serialization
+type jsonObjective struct {
+	Status protocols.ObjectiveStatus
+	C      types.Destination
+
+	UpdateState state.State
+}
+
+// MarshalJSON returns a JSON representation of the AppUpdateObjective
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o Objective) MarshalJSON() ([]byte, error) {
+	jsonAUO := jsonObjective{
+		o.Status,
+		o.C.Id,
+		o.updateState,
+	}
+	return json.Marshal(jsonAUO)
+}
+
+// UnmarshalJSON populates the calling AppUpdateObjective with the
+// json-encoded data
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o *Objective) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var jsonAUO jsonObjective
+	err := json.Unmarshal(data, &jsonAUO)
+	if err != nil {
+		return err
+	}
+
+	o.C = &channel.Channel{}
+	o.C.Id = jsonAUO.C
+
+	o.Status = jsonAUO.Status
+	o.updateState = jsonAUO.UpdateState
+
+	return nil
+}
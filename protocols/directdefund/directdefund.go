@@ -12,18 +12,19 @@ import (
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
 
 const (
-	WaitingForFinalization protocols.WaitingFor = "WaitingForFinalization"
-	WaitingForWithdraw     protocols.WaitingFor = "WaitingForWithdraw"
-	WaitingForNothing      protocols.WaitingFor = "WaitingForNothing" // Finished
+	WaitingForFinalization = protocols.WaitingForFinalization
+	WaitingForWithdraw     = protocols.WaitingForWithdraw
+	WaitingForNothing      = protocols.WaitingForNothing // Finished
 )
 
 const (
-	SignedStatePayload protocols.PayloadType = "SignedStatePayload"
+	SignedStatePayload = protocols.SignedStatePayload
 )
 
 const ObjectivePrefix = "DirectDefunding-"
@@ -80,8 +81,8 @@ func NewObjective(
 		return Objective{}, fmt.Errorf("could not find channel %s; %w", request.ChannelId, err)
 	}
 
-	if len(cc.FundingTargets()) != 0 {
-		return Objective{}, ErrNotEmpty
+	if targets := cc.FundingTargets(); len(targets) != 0 {
+		return Objective{}, fmt.Errorf("channel %s funds %v: %w", request.ChannelId, targets, ErrNotEmpty)
 	}
 
 	c, err := CreateChannelFromConsensusChannel(*cc)
@@ -208,6 +209,17 @@ func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, e
 		if o.finalTurnNum != ss.State().TurnNum {
 			return o, fmt.Errorf("expected state with turn number %d, received turn number %d", o.finalTurnNum, ss.State().TurnNum)
 		}
+		supported, err := o.C.LatestSupportedState()
+		if err != nil {
+			return o, fmt.Errorf("could not get latest supported state: %w", err)
+		}
+		final := ss.State().Outcome
+		if err := final.Validate(); err != nil {
+			return o, fmt.Errorf("invalid final outcome: %w", err)
+		}
+		if err := final.ValidateTotals(supported.Outcome.TotalAllocated()); err != nil {
+			return o, fmt.Errorf("invalid final outcome: %w", err)
+		}
 	} else {
 		return o, fmt.Errorf("event does not contain a signed state")
 	}
@@ -218,6 +230,19 @@ func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, e
 	return &updated, nil
 }
 
+// ProposedOutcome returns the outcome of the channel's latest supported state and the outcome of the final state in the payload
+func (o *Objective) ProposedOutcome(p protocols.ObjectivePayload) (current, proposed outcome.Exit, ok bool, err error) {
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	supported, err := o.C.LatestSupportedState()
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("could not get latest supported state: %w", err)
+	}
+	return supported.Outcome, ss.State().Outcome, true, nil
+}
+
 // Crank inspects the extended state and declares a list of Effects to be executed
 func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
 	updated := o.clone()
@@ -299,6 +324,15 @@ func (o *Objective) fullyWithdrawn() bool {
 	return !o.C.OnChain.Holdings.IsNonZero()
 }
 
+// RetryTransaction returns an updated objective which declares its withdrawAll transaction again when next cranked, if tx is that transaction.
+func (o *Objective) RetryTransaction(tx protocols.ChainTransaction) protocols.TransactionRetrier {
+	updated := o.clone()
+	if _, ok := tx.(protocols.WithdrawAllTransaction); ok {
+		updated.withdrawTransactionSubmitted = false
+	}
+	return &updated
+}
+
 // clone returns a deep copy of the receiver.
 func (o *Objective) clone() Objective {
 	clone := Objective{}
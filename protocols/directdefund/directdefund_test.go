@@ -2,6 +2,7 @@ package directdefund
 
 import (
 	"encoding/json"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -126,6 +127,51 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdateRejectsInvalidFinalOutcome(t *testing.T) {
+	negative := func(e outcome.Exit) {
+		e[0].Allocations[0].Amount = big.NewInt(-1)
+		e[0].Allocations[1].Amount.Add(e[0].Allocations[1].Amount, big.NewInt(1))
+	}
+	inflated := func(e outcome.Exit) {
+		e[0].Allocations[1].Amount.Add(e[0].Allocations[1].Amount, big.NewInt(1))
+	}
+
+	testCases := []struct {
+		name   string
+		modify func(outcome.Exit)
+		want   error
+	}{
+		{"a negative allocation", negative, outcome.ErrNegativeAllocation},
+		{"allocations which do not sum to the channel's funds", inflated, outcome.ErrFundsNotConserved},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, _ := newTestObjective()
+			o.C.MyIndex = 1
+
+			supported, err := o.C.LatestSupportedState()
+			testhelpers.Ok(t, err)
+			finalState := supported.Clone()
+			finalState.TurnNum = 2
+			finalState.IsFinal = true
+			tc.modify(finalState.Outcome)
+			ss, _ := signedTestState(finalState, []bool{true, false})
+			op, err := protocols.CreateObjectivePayload(o.Id(), SignedStatePayload, ss)
+			testhelpers.Ok(t, err)
+
+			updated, err := o.Update(op)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+
+			// The rejected state is not stored, so bob never countersigns it
+			latest, err := updated.(*Objective).C.LatestSignedState()
+			testhelpers.Ok(t, err)
+			testhelpers.Assert(t, !latest.State().IsFinal, "expected the rejected final state not to be stored")
+		})
+	}
+}
+
 func compareSideEffect(a, b protocols.SideEffects) string {
 	return cmp.Diff(a, b, cmp.AllowUnexported(a, state.SignedState{}, consensus_channel.Add{}, consensus_channel.Remove{}, consensus_channel.Guarantee{}, protocols.Message{}, payments.Voucher{}))
 }
@@ -216,7 +262,10 @@ func TestCrankBob(t *testing.T) {
 	o.C.MyIndex = 1
 
 	// Update the objective with Alice's final state
-	finalState := testState.Clone()
+	finalState, err := o.C.LatestSupportedState()
+	if err != nil {
+		t.Fatal(err)
+	}
 	finalState.TurnNum = 2
 	finalState.IsFinal = true
 	finalStateSignedByAlice, _ := signedTestState(finalState, []bool{true, false})
@@ -17,17 +17,18 @@ import (
 )
 
 var ErrLedgerChannelExists error = errors.New("directfund: ledger channel already exists")
+var ErrSelfChannel error = errors.New("directfund: counterparty is the node's own address")
 
 const (
-	WaitingForCompletePrefund  protocols.WaitingFor = "WaitingForCompletePrefund"
-	WaitingForMyTurnToFund     protocols.WaitingFor = "WaitingForMyTurnToFund"
-	WaitingForCompleteFunding  protocols.WaitingFor = "WaitingForCompleteFunding"
-	WaitingForCompletePostFund protocols.WaitingFor = "WaitingForCompletePostFund"
-	WaitingForNothing          protocols.WaitingFor = "WaitingForNothing" // Finished
+	WaitingForCompletePrefund  = protocols.WaitingForCompletePrefund
+	WaitingForMyTurnToFund     = protocols.WaitingForMyTurnToFund
+	WaitingForCompleteFunding  = protocols.WaitingForCompleteFunding
+	WaitingForCompletePostFund = protocols.WaitingForCompletePostFund
+	WaitingForNothing          = protocols.WaitingForNothing // Finished
 )
 
 const (
-	SignedStatePayload protocols.PayloadType = "SignedStatePayload"
+	SignedStatePayload = protocols.SignedStatePayload
 )
 
 const ObjectivePrefix = "DirectFunding-"
@@ -134,6 +135,9 @@ func ConstructFromPayload(
 	if initialState.IsFinal {
 		return Objective{}, errors.New("attempted to initiate new direct-funding objective with IsFinal == true")
 	}
+	if err := initialState.Outcome.Validate(); err != nil {
+		return Objective{}, fmt.Errorf("invalid initial outcome: %w", err)
+	}
 
 	init := Objective{}
 
@@ -143,21 +147,13 @@ func ConstructFromPayload(
 		init.Status = protocols.Unapproved
 	}
 
-	var myIndex uint
-	foundMyAddress := false
-	for i, v := range initialState.Participants {
-		if v == myAddress {
-			myIndex = uint(i)
-			foundMyAddress = true
-			break
-		}
-	}
-	if !foundMyAddress {
-		return Objective{}, errors.New("my address not found in participants")
+	myIndex, _, err := channel.RoleFor(myAddress, initialState.Participants)
+	if err != nil {
+		return Objective{}, fmt.Errorf("my address not found in participants: %w", err)
 	}
 
 	init.C = &channel.Channel{}
-	init.C, err = channel.New(initialState, myIndex)
+	init.C, err = channel.New(initialState, uint(myIndex))
 
 	if err != nil {
 		return Objective{}, fmt.Errorf("failed to initialize channel for direct-fund objective: %w", err)
@@ -256,6 +252,12 @@ func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
 	return &updated, sideEffects
 }
 
+// IsCancellable returns true until a deposit has been submitted or seen on chain.
+// Once the channel holds funds, abandoning the objective would leave them to be recovered by challenging.
+func (o *Objective) IsCancellable() bool {
+	return !o.transactionSubmitted && !o.C.OnChain.Holdings.IsNonZero()
+}
+
 // Update receives an ObjectivePayload, applies all applicable data to the DirectFundingObjectiveState,
 // and returns the updated state
 func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
@@ -324,6 +326,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 
 	if !fundingComplete && safeToDeposit && amountToDeposit.IsNonZero() && !updated.transactionSubmitted {
 		deposit := protocols.NewDepositTransaction(updated.C.Id, amountToDeposit)
+		deposit.TurnNum = channel.PreFundTurnNum
 		updated.transactionSubmitted = true
 		sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, deposit)
 	}
@@ -411,6 +414,15 @@ func (o *Objective) amountToDeposit() types.Funds {
 	return deposits
 }
 
+// RetryTransaction returns an updated objective which declares its deposit transaction again when next cranked, if tx is that transaction.
+func (o *Objective) RetryTransaction(tx protocols.ChainTransaction) protocols.TransactionRetrier {
+	updated := o.clone()
+	if _, ok := tx.(protocols.DepositTransaction); ok {
+		updated.transactionSubmitted = false
+	}
+	return &updated
+}
+
 // clone returns a deep copy of the receiver.
 func (o *Objective) clone() Objective {
 	clone := Objective{}
@@ -439,7 +451,9 @@ type ObjectiveRequest struct {
 	AppDefinition     types.Address
 	AppData           types.Bytes
 	Nonce             uint64
-	objectiveStarted  chan struct{}
+	// ChainId is the id of the chain to open the channel on. If nil, the channel is opened on the engine's default chain.
+	ChainId          *big.Int `json:",omitempty"`
+	objectiveStarted chan struct{}
 }
 
 // NewObjectiveRequest creates a new ObjectiveRequest.
@@ -460,6 +474,14 @@ func NewObjectiveRequest(
 	}
 }
 
+// Validate returns an error if the request could never produce an objective which completes, such as one for a channel with myself.
+func (r ObjectiveRequest) Validate(myAddress types.Address) error {
+	if r.CounterParty == myAddress {
+		return fmt.Errorf("counterparty %s: %w", r.CounterParty, ErrSelfChannel)
+	}
+	return nil
+}
+
 // SignalObjectiveStarted is used by the engine to signal the objective has been started.
 func (r ObjectiveRequest) SignalObjectiveStarted() {
 	close(r.objectiveStarted)
@@ -471,15 +493,9 @@ func (r ObjectiveRequest) WaitForObjectiveToStart() {
 }
 
 // Id returns the objective id for the request.
+// It matches the id of the objective constructed from the request, so it must account for every field of the channel's fixed part.
 func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
-	fixedPart := state.FixedPart{
-		Participants:      []types.Address{myAddress, r.CounterParty},
-		ChannelNonce:      r.Nonce,
-		ChallengeDuration: r.ChallengeDuration,
-	}
-
-	channelId := fixedPart.ChannelId()
-	return protocols.ObjectiveId(ObjectivePrefix + channelId.String())
+	return r.Response(myAddress, chainId).Id
 }
 
 // ObjectiveResponse is the type returned across the API in response to the ObjectiveRequest.
@@ -2,6 +2,7 @@ package directfund
 
 import (
 	"encoding/json"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -109,6 +110,15 @@ func TestConstructFromPayload(t *testing.T) {
 	if _, err := ConstructFromPayload(false, op, nonParticipant); err == nil {
 		t.Error("expected an error when constructing with a participant not in the channel, but got nil")
 	}
+
+	// Construct a prefund state with a negative allocation, which is rejected before it is countersigned
+	negative := testState.Clone()
+	negative.Outcome[0].Allocations[0].Amount = big.NewInt(-5)
+	op, err = protocols.CreateObjectivePayload(id, SignedStatePayload, state.NewSignedState(negative))
+	testhelpers.Ok(t, err)
+	if _, err := ConstructFromPayload(false, op, testState.Participants[0]); !errors.Is(err, outcome.ErrNegativeAllocation) {
+		t.Errorf("expected %v when constructing with a negative allocation, got %v", outcome.ErrNegativeAllocation, err)
+	}
 }
 
 func TestUpdate(t *testing.T) {
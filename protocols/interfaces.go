@@ -3,15 +3,24 @@ package protocols
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
+	"slices"
+	"strings"
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/types"
 )
 
-var ErrNotApproved = errors.New("objective not approved")
+var (
+	ErrNotApproved    = errors.New("objective not approved")
+	ErrNotCancellable = errors.New("objective cannot be cancelled")
+	// ErrUnknownObjectiveType is returned when an objective id is not prefixed by the type of any known objective
+	ErrUnknownObjectiveType = errors.New("unknown objective type")
+)
 
 // ChainTransaction defines the interface that every transaction must implement
 type ChainTransaction interface {
@@ -30,6 +39,7 @@ func (cct ChainTransactionBase) ChannelId() types.Destination {
 type DepositTransaction struct {
 	ChainTransaction
 	Deposit types.Funds
+	TurnNum uint64 // the turn number of the state which the deposit funds, so that deposits of the same assets into a channel can be told apart
 }
 
 func NewDepositTransaction(channelId types.Destination, deposit types.Funds) DepositTransaction {
@@ -67,15 +77,76 @@ func NewChallengeTransaction(
 }
 
 // SideEffects are effects to be executed by an imperative shell
+// MessagesToSend and TransactionsToSubmit are executed independently of one another, so no order between a message and a transaction is guaranteed.
+// Effects which must be executed in a particular order, such as announcing a deposit only once it has been submitted, are declared in Sequence.
 type SideEffects struct {
 	MessagesToSend       []Message
 	TransactionsToSubmit []ChainTransaction
 	ProposalsToProcess   []consensus_channel.Proposal
+	// Sequence contains effects which are executed one after another, in order.
+	// Each transaction is submitted before any later effect is executed.
+	Sequence []SideEffect
+}
+
+// SideEffect is a single message to send or chain transaction to submit. Exactly one of its fields is set.
+type SideEffect struct {
+	Message     *Message
+	Transaction ChainTransaction
+}
+
+// SendMessage returns a SideEffect which sends the message
+func SendMessage(message Message) SideEffect {
+	return SideEffect{Message: &message}
+}
+
+// SubmitTransaction returns a SideEffect which submits the transaction
+func SubmitTransaction(tx ChainTransaction) SideEffect {
+	return SideEffect{Transaction: tx}
 }
 
 // WaitingFor is an enumerable "pause-point" computed from an Objective. It describes how the objective is blocked on actions by third parties (i.e. co-participants or the blockchain).
 type WaitingFor string
 
+// The pause-points of every protocol. Each objective waits for the subset of them which its protocol passes through.
+const (
+	// directfund and virtualfund
+	WaitingForCompletePrefund  WaitingFor = "WaitingForCompletePrefund"
+	WaitingForMyTurnToFund     WaitingFor = "WaitingForMyTurnToFund"
+	WaitingForCompleteFunding  WaitingFor = "WaitingForCompleteFunding"
+	WaitingForCompletePostFund WaitingFor = "WaitingForCompletePostFund"
+
+	// directdefund
+	WaitingForFinalization WaitingFor = "WaitingForFinalization"
+	WaitingForWithdraw     WaitingFor = "WaitingForWithdraw"
+
+	// virtualdefund
+	WaitingForFinalStateFromAlice WaitingFor = "WaitingForFinalStateFromAlice"
+	WaitingForSupportedFinalState WaitingFor = "WaitingForSupportedFinalState"
+	WaitingForDefundingOnMyLeft   WaitingFor = "WaitingForDefundingOnMyLeft"
+	WaitingForDefundingOnMyRight  WaitingFor = "WaitingForDefundingOnMyRight"
+
+	// ledgertopup
+	WaitingForDeposit       WaitingFor = "WaitingForDeposit"
+	WaitingForCompleteTopUp WaitingFor = "WaitingForCompleteTopUp"
+
+	// ledgerrecycle
+	WaitingForCompleteRecycle WaitingFor = "WaitingForCompleteRecycle"
+
+	// appupdate
+	WaitingForCompleteAppUpdate WaitingFor = "WaitingForCompleteAppUpdate"
+
+	// WaitingForNothing means that the objective is complete
+	WaitingForNothing WaitingFor = "WaitingForNothing"
+)
+
+// String returns the name of the pause-point, or "Unknown" for the zero value, which no objective waits for
+func (w WaitingFor) String() string {
+	if w == "" {
+		return "Unknown"
+	}
+	return string(w)
+}
+
 // AdjudicationStatus mirrors the on chain adjudication status of a particular channel.
 // Everything that is stored on chain, other than holdings.
 type AdjudicationStatus struct {
@@ -123,9 +194,54 @@ type ProposalReceiver interface {
 	ReceiveProposal(signedProposal consensus_channel.SignedProposal) (ProposalReceiver, error)
 }
 
+// TransactionRetrier is an Objective that records which chain transactions it has submitted.
+type TransactionRetrier interface {
+	Objective
+	// RetryTransaction returns an updated objective which declares the failed transaction again when next cranked.
+	RetryTransaction(tx ChainTransaction) TransactionRetrier
+}
+
+// OutcomeReceiver is an Objective which accepts a channel outcome proposed by a counterparty.
+type OutcomeReceiver interface {
+	Objective
+	// ProposedOutcome returns the channel's current outcome and the outcome proposed by the payload.
+	// ok is false if the payload does not propose an outcome.
+	ProposedOutcome(p ObjectivePayload) (current, proposed outcome.Exit, ok bool, err error)
+}
+
+// Cancellable is an Objective which can be abandoned part way through its protocol.
+// Objectives which do not implement Cancellable can never be abandoned.
+type Cancellable interface {
+	Objective
+	// IsCancellable returns true while the objective can be abandoned without putting any participant's funds at risk.
+	IsCancellable() bool
+}
+
+// ConsensusChannelRestorer is an Objective which governs a ledger channel in place of its ConsensusChannel while it runs.
+// If the objective is abandoned, the ConsensusChannel it replaced is restored so that the ledger channel can be used again.
+type ConsensusChannelRestorer interface {
+	Objective
+	// RestoreConsensusChannel creates a ConsensusChannel from the ledger channel's latest supported state.
+	RestoreConsensusChannel() (*consensus_channel.ConsensusChannel, error)
+}
+
 // ObjectiveId is a unique identifier for an Objective.
+// It is prefixed by the objective's type, such as "DirectFunding-".
 type ObjectiveId string
 
+// objectiveTypes are the types of the objectives in the protocols' subpackages, each of which prefixes its objective ids with its type and "-"
+var objectiveTypes = []string{"DirectFunding", "DirectDefunding", "VirtualFund", "VirtualDefund", "LedgerTopUp", "LedgerRecycle", "AppUpdate"}
+
+// Type returns the type of the objective with this id, such as "DirectFunding", so that the kind of a completed objective can be told from its id alone.
+// It returns ErrUnknownObjectiveType if the id is not prefixed by a known type.
+func (id ObjectiveId) Type() (string, error) {
+	prefix, _, found := strings.Cut(string(id), "-")
+	if found && slices.Contains(objectiveTypes, prefix) {
+		return prefix, nil
+	}
+	return "", fmt.Errorf("objective id %q: %w", id, ErrUnknownObjectiveType)
+}
+
 type ObjectiveStatus int8
 
 const (
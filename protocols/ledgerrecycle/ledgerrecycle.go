@@ -0,0 +1,445 @@
+// Package ledgerrecycle implements a protocol to recycle an existing ledger channel, reallocating the funds it holds on chain between its participants.
+//
+// Recycling replaces the ledger channel's outcome with a new one off chain, in a single state signed by both participants,
+// so that the channel funds a new allocation without a withdrawal or a deposit. It is only safe when:
+//   - the ledger channel funds no other channels and has no pending proposals, so no guarantee depends on the old outcome,
+//   - the new outcome allocates only the channel's asset, and only to its two participants,
+//   - the new outcome allocates the same total as the old one, and
+//   - the channel's on chain holdings cover that total, so the new outcome is fully funded the moment it is signed.
+//
+// Neither participant gives up funds without signing the new outcome, so the counterparty's policy must approve the objective.
+// A recycle which lowers the counterparty's allocation must be approved explicitly, by a policy which decides on reallocations.
+package ledgerrecycle // import "github.com/statechannels/go-nitro/ledgerrecycle"
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	WaitingForCompleteRecycle = protocols.WaitingForCompleteRecycle
+	WaitingForNothing         = protocols.WaitingForNothing // Finished
+)
+
+const (
+	SignedStatePayload = protocols.SignedStatePayload
+)
+
+const ObjectivePrefix = "LedgerRecycle-"
+
+const (
+	ErrNotEmpty                = types.ConstError("can only recycle a ledger channel which has no running guarantees")
+	ErrChannelUpdateInProgress = types.ConstError("can only recycle a ledger channel which has no pending proposals")
+	ErrInvalidRecycledState    = types.ConstError("state does not describe a valid recycling of the ledger channel")
+	ErrInvalidOutcome          = types.ConstError("a recycled ledger channel must allocate its asset to its two participants only")
+	ErrTotalChanged            = types.ConstError("a recycled ledger channel must allocate the same total as before")
+	ErrNotFunded               = types.ConstError("the on chain holdings of the ledger channel do not cover the recycled outcome")
+	ErrAllocationLowered       = types.ConstError("a recycle which lowers our allocation must be approved explicitly")
+)
+
+// GetConsensusChannel describes functions which return a ConsensusChannel ledger channel for a channel id.
+type GetConsensusChannel func(channelId types.Destination) (ledger *consensus_channel.ConsensusChannel, err error)
+
+// Objective is a cache of data computed by reading from the store. It stores (potentially) infinite data
+type Objective struct {
+	Status protocols.ObjectiveStatus
+	C      *channel.Channel
+
+	nonce         uint64
+	recycledState state.State // the ledger state which allocates the channel's holdings according to the new outcome
+}
+
+// NewObjective creates a new recycling objective from a given request.
+func NewObjective(request ObjectiveRequest, preApprove bool, getConsensusChannel GetConsensusChannel) (Objective, error) {
+	cc, err := getConsensusChannel(request.ChannelId)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", request.ChannelId, err)
+	}
+
+	recycledState := cc.ConsensusVars().AsState(cc.FixedPart())
+	recycledState.TurnNum += 1
+	recycledState.Outcome = request.Outcome.Clone()
+
+	return newObjective(preApprove, request.Nonce, recycledState, cc)
+}
+
+// ConstructObjectiveFromPayload takes in a recycled state signed by the proposer and constructs an objective from it.
+func ConstructObjectiveFromPayload(
+	p protocols.ObjectivePayload,
+	preapprove bool,
+	getConsensusChannel GetConsensusChannel,
+) (Objective, error) {
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	s := ss.State()
+
+	channelId, nonce, err := parseObjectiveId(p.ObjectiveId)
+	if err != nil {
+		return Objective{}, err
+	}
+	if channelId != s.ChannelId() {
+		return Objective{}, fmt.Errorf("objective %s does not match channel %s", p.ObjectiveId, s.ChannelId())
+	}
+
+	cc, err := getConsensusChannel(channelId)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", channelId, err)
+	}
+
+	return newObjective(preapprove, nonce, s, cc)
+}
+
+// newObjective constructs an objective which moves the supplied ledger channel to the recycled state, once it has checked that doing so is safe.
+func newObjective(preApprove bool, nonce uint64, recycledState state.State, cc *consensus_channel.ConsensusChannel) (Objective, error) {
+	if targets := cc.FundingTargets(); len(targets) != 0 {
+		return Objective{}, fmt.Errorf("channel %s funds %v: %w", cc.Id, targets, ErrNotEmpty)
+	}
+	if len(cc.ProposalQueue()) != 0 {
+		return Objective{}, ErrChannelUpdateInProgress
+	}
+	if err := validateRecycledState(recycledState, cc); err != nil {
+		return Objective{}, err
+	}
+
+	c, err := directdefund.CreateChannelFromConsensusChannel(*cc)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not create Channel from ConsensusChannel; %w", err)
+	}
+
+	init := Objective{}
+	if preApprove {
+		init.Status = protocols.Approved
+	} else {
+		init.Status = protocols.Unapproved
+	}
+	init.C = c
+	init.nonce = nonce
+	init.recycledState = recycledState
+
+	return init, nil
+}
+
+// validateRecycledState returns an error unless the state is the ledger channel's next state, and allocates what the channel holds to its participants.
+func validateRecycledState(s state.State, cc *consensus_channel.ConsensusChannel) error {
+	// Only the outcome may differ from the channel's next state, so the state is not final
+	expected := cc.ConsensusVars().AsState(cc.FixedPart())
+	expected.TurnNum += 1
+	expected.Outcome = s.Outcome
+	if !expected.Equal(s) {
+		return ErrInvalidRecycledState
+	}
+
+	if err := s.Outcome.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOutcome, err)
+	}
+	current := cc.ConsensusVars().AsState(cc.FixedPart()).Outcome
+	if len(s.Outcome) != 1 || len(current) != 1 || s.Outcome[0].Asset != current[0].Asset {
+		return ErrInvalidOutcome
+	}
+	allocations := s.Outcome[0].Allocations
+	if len(allocations) != 2 {
+		return ErrInvalidOutcome
+	}
+	for i, participant := range cc.Participants() {
+		a := allocations[i]
+		if a.Destination != types.AddressToDestination(participant) || a.AllocationType != outcome.NormalAllocationType || len(a.Metadata) != 0 {
+			return ErrInvalidOutcome
+		}
+	}
+
+	total := allocations.Total()
+	if total.Cmp(current[0].TotalAllocated()) != 0 {
+		return fmt.Errorf("channel %s allocates %s, not %s: %w", cc.Id, current[0].TotalAllocated(), total, ErrTotalChanged)
+	}
+	holding, ok := cc.OnChainFunding[s.Outcome[0].Asset]
+	if !ok {
+		holding = big.NewInt(0)
+	}
+	if holding.Cmp(total) < 0 {
+		return fmt.Errorf("channel %s holds %s of %s on chain: %w", cc.Id, holding, total, ErrNotFunded)
+	}
+	return nil
+}
+
+// Id returns the unique id of the objective
+func (o *Objective) Id() protocols.ObjectiveId {
+	return objectiveId(o.C.Id, o.nonce)
+}
+
+func (o *Objective) Approve() protocols.Objective {
+	updated := o.clone()
+	// todo: consider case of o.Status == Rejected
+	updated.Status = protocols.Approved
+
+	return &updated
+}
+
+func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+	updated := o.clone()
+	updated.Status = protocols.Rejected
+	peer := o.C.Participants[1-o.C.MyIndex]
+
+	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), peer)}
+	return &updated, sideEffects
+}
+
+// OwnsChannel returns the channel that the objective is recycling.
+func (o *Objective) OwnsChannel() types.Destination {
+	return o.C.Id
+}
+
+// GetStatus returns the status of the objective.
+func (o *Objective) GetStatus() protocols.ObjectiveStatus {
+	return o.Status
+}
+
+func (o *Objective) Related() []protocols.Storable {
+	return []protocols.Storable{o.C}
+}
+
+// Update receives an ObjectivePayload, applies all applicable data to the Objective,
+// and returns the updated objective
+func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
+	if o.Id() != p.ObjectiveId {
+		return o, fmt.Errorf("event and objective Ids do not match: %s and %s respectively", string(p.ObjectiveId), string(o.Id()))
+	}
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return o, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	if len(ss.Signatures()) == 0 {
+		return o, fmt.Errorf("event does not contain a signed state")
+	}
+	if !ss.State().Equal(o.recycledState) {
+		return o, ErrInvalidRecycledState
+	}
+
+	updated := o.clone()
+	updated.C.AddSignedState(ss)
+	return &updated, nil
+}
+
+// ProposedOutcome returns the outcome of the ledger channel's latest supported state and the recycled outcome in the payload
+func (o *Objective) ProposedOutcome(p protocols.ObjectivePayload) (current, proposed outcome.Exit, ok bool, err error) {
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	supported, err := o.C.LatestSupportedState()
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("could not get latest supported state: %w", err)
+	}
+	return supported.Outcome, ss.State().Outcome, true, nil
+}
+
+// LowersMyAllocation returns true if the recycled outcome allocates me less than the ledger channel's latest supported state
+func (o *Objective) LowersMyAllocation() bool {
+	supported, err := o.C.LatestSupportedState()
+	if err != nil {
+		return false
+	}
+	me := types.AddressToDestination(o.C.Participants[o.C.MyIndex])
+	recycled := o.recycledState.Outcome.TotalAllocatedFor(me)
+	for asset, amount := range supported.Outcome.TotalAllocatedFor(me) {
+		if r, ok := recycled[asset]; !ok || r.Cmp(amount) < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Crank inspects the extended state and declares a list of Effects to be executed
+func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := o.clone()
+
+	sideEffects := protocols.SideEffects{}
+
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
+	}
+
+	// The recycled state is signed straight away, since the holdings already cover it and nothing is deposited or withdrawn
+	if !updated.recycledSignedByMe() {
+		ss, err := updated.C.SignAndAddState(updated.recycledState, secretKey)
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteRecycle, fmt.Errorf("could not sign recycled state %w", err)
+		}
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.Id(), ss, SignedStatePayload, updated.otherParticipants()...)
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteRecycle, fmt.Errorf("could not create payload message %w", err)
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+	}
+
+	if !updated.recycleComplete() {
+		return &updated, sideEffects, WaitingForCompleteRecycle, nil
+	}
+
+	// Completion
+	updated.Status = protocols.Completed
+	return &updated, sideEffects, WaitingForNothing, nil
+}
+
+// CreateConsensusChannel creates a ConsensusChannel from the Objective using the fully signed recycled state.
+func (o *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChannel, error) {
+	if !o.recycleComplete() {
+		return nil, fmt.Errorf("expected recycling of channel %s to be complete", o.C.Id)
+	}
+	return o.consensusChannelFrom(o.C.OffChain.SignedStateForTurnNum[o.recycledState.TurnNum])
+}
+
+// RestoreConsensusChannel creates a ConsensusChannel from the ledger channel's latest supported state,
+// so that the channel can be governed by it again once the recycle has been rejected.
+func (o *Objective) RestoreConsensusChannel() (*consensus_channel.ConsensusChannel, error) {
+	supported, err := o.C.LatestSupportedSignedState()
+	if err != nil {
+		return nil, fmt.Errorf("could not get latest supported state: %w", err)
+	}
+	return o.consensusChannelFrom(supported)
+}
+
+// consensusChannelFrom creates a ConsensusChannel governed by the supplied signed state of the ledger channel.
+func (o *Objective) consensusChannelFrom(ss state.SignedState) (*consensus_channel.ConsensusChannel, error) {
+	leaderSig, err := ss.GetParticipantSignature(uint(consensus_channel.Leader))
+	if err != nil {
+		return nil, fmt.Errorf("could not get leader signature: %w", err)
+	}
+	followerSig, err := ss.GetParticipantSignature(uint(consensus_channel.Follower))
+	if err != nil {
+		return nil, fmt.Errorf("could not get follower signature: %w", err)
+	}
+	signatures := [2]state.Signature{leaderSig, followerSig}
+
+	s := ss.State()
+	outcome, err := consensus_channel.FromExit(s.Outcome[0])
+	if err != nil {
+		return nil, fmt.Errorf("could not create ledger outcome from channel exit: %w", err)
+	}
+
+	var con consensus_channel.ConsensusChannel
+	if o.C.MyIndex == uint(consensus_channel.Leader) {
+		con, err = consensus_channel.NewLeaderChannel(o.C.FixedPart, s.TurnNum, outcome, signatures)
+	} else {
+		con, err = consensus_channel.NewFollowerChannel(o.C.FixedPart, s.TurnNum, outcome, signatures)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create consensus channel: %w", err)
+	}
+	con.OnChainFunding = o.C.OnChain.Holdings.Clone()
+	return &con, nil
+}
+
+// IsLedgerRecycleObjective inspects a objective id and returns true if the objective id is for a ledger recycle objective.
+func IsLedgerRecycleObjective(id protocols.ObjectiveId) bool {
+	return strings.HasPrefix(string(id), ObjectivePrefix)
+}
+
+//  Private methods on the Objective
+
+// recycledSignedByMe returns true if I have signed the recycled state.
+func (o *Objective) recycledSignedByMe() bool {
+	ss, ok := o.C.OffChain.SignedStateForTurnNum[o.recycledState.TurnNum]
+	return ok && ss.HasSignatureForParticipant(o.C.MyIndex)
+}
+
+// recycleComplete returns true if the recycled state has been signed by every participant.
+func (o *Objective) recycleComplete() bool {
+	ss, ok := o.C.OffChain.SignedStateForTurnNum[o.recycledState.TurnNum]
+	return ok && ss.HasAllSignatures()
+}
+
+// otherParticipants returns the participants in the channel that are not the current participant.
+func (o *Objective) otherParticipants() []types.Address {
+	others := make([]types.Address, 0)
+	for i, p := range o.C.Participants {
+		if i != int(o.C.MyIndex) {
+			others = append(others, p)
+		}
+	}
+	return others
+}
+
+// clone returns a deep copy of the receiver.
+func (o *Objective) clone() Objective {
+	clone := Objective{}
+	clone.Status = o.Status
+	clone.C = o.C.Clone()
+	clone.nonce = o.nonce
+	clone.recycledState = o.recycledState.Clone()
+	return clone
+}
+
+// objectiveId returns the id of the recycle objective for the channel with the given nonce.
+func objectiveId(channelId types.Destination, nonce uint64) protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + channelId.String() + "-" + strconv.FormatUint(nonce, 10))
+}
+
+// parseObjectiveId returns the channel id and nonce encoded in a recycle objective id.
+func parseObjectiveId(id protocols.ObjectiveId) (types.Destination, uint64, error) {
+	channelId, nonce, found := strings.Cut(strings.TrimPrefix(string(id), ObjectivePrefix), "-")
+	if !IsLedgerRecycleObjective(id) || !found {
+		return types.Destination{}, 0, fmt.Errorf("%s is not a ledger recycle objective id", id)
+	}
+	n, err := strconv.ParseUint(nonce, 10, 64)
+	if err != nil {
+		return types.Destination{}, 0, fmt.Errorf("could not parse nonce of objective %s: %w", id, err)
+	}
+	return types.Destination(common.HexToHash(channelId)), n, nil
+}
+
+// ObjectiveRequest represents a request to create a new ledger recycle objective.
+type ObjectiveRequest struct {
+	ChannelId        types.Destination
+	Outcome          outcome.Exit
+	Nonce            uint64
+	objectiveStarted chan struct{}
+}
+
+// NewObjectiveRequest creates a new ObjectiveRequest.
+func NewObjectiveRequest(channelId types.Destination, outcome outcome.Exit, nonce uint64) ObjectiveRequest {
+	return ObjectiveRequest{
+		ChannelId:        channelId,
+		Outcome:          outcome,
+		Nonce:            nonce,
+		objectiveStarted: make(chan struct{}),
+	}
+}
+
+// SignalObjectiveStarted is used by the engine to signal the objective has been started.
+func (r ObjectiveRequest) SignalObjectiveStarted() {
+	close(r.objectiveStarted)
+}
+
+// WaitForObjectiveToStart blocks until the objective starts
+func (r ObjectiveRequest) WaitForObjectiveToStart() {
+	<-r.objectiveStarted
+}
+
+// Id returns the objective id for the request.
+func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return objectiveId(r.ChannelId, r.Nonce)
+}
+
+// getSignedStatePayload takes in a serialized signed state payload and returns the deserialized SignedState.
+func getSignedStatePayload(b []byte) (state.SignedState, error) {
+	ss := state.SignedState{}
+	err := json.Unmarshal(b, &ss)
+	if err != nil {
+		return ss, fmt.Errorf("could not unmarshal signed state: %w", err)
+	}
+	return ss, nil
+}
@@ -0,0 +1,163 @@
+package ledgerrecycle
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var alice, bob, irene testactors.Actor = testactors.Alice, testactors.Bob, testactors.Irene
+
+// newTestLedger returns the leader (alice) and follower (bob) views of a funded ledger channel.
+func newTestLedger(t *testing.T) (leader, follower *consensus_channel.ConsensusChannel) {
+	fp := state.FixedPart{
+		Participants:      []types.Address{alice.Address(), bob.Address()},
+		ChannelNonce:      9001,
+		ChallengeDuration: 60,
+	}
+	outcome := consensus_channel.NewLedgerOutcome(
+		types.Address{},
+		consensus_channel.NewBalance(alice.Destination(), big.NewInt(100)),
+		consensus_channel.NewBalance(bob.Destination(), big.NewInt(200)),
+		[]consensus_channel.Guarantee{},
+	)
+	vars := consensus_channel.Vars{Outcome: *outcome, TurnNum: 1}
+	aliceSig, _ := vars.AsState(fp).Sign(alice.PrivateKey)
+	bobSig, _ := vars.AsState(fp).Sign(bob.PrivateKey)
+	sigs := [2]state.Signature{aliceSig, bobSig}
+
+	l, err := consensus_channel.NewLeaderChannel(fp, 1, *outcome, sigs)
+	testhelpers.Ok(t, err)
+	f, err := consensus_channel.NewFollowerChannel(fp, 1, *outcome, sigs)
+	testhelpers.Ok(t, err)
+	l.OnChainFunding = types.Funds{types.Address{}: big.NewInt(300)}
+	f.OnChainFunding = types.Funds{types.Address{}: big.NewInt(300)}
+	return &l, &f
+}
+
+func lookup(cc *consensus_channel.ConsensusChannel) GetConsensusChannel {
+	return func(types.Destination) (*consensus_channel.ConsensusChannel, error) { return cc, nil }
+}
+
+// exit returns a single asset outcome allocating the amounts to alice and bob.
+func exit(asset types.Address, aliceAmount, bobAmount int64) outcome.Exit {
+	return outcome.Exit{outcome.SingleAssetExit{
+		Asset: asset,
+		Allocations: outcome.Allocations{
+			outcome.Allocation{Destination: alice.Destination(), Amount: big.NewInt(aliceAmount)},
+			outcome.Allocation{Destination: bob.Destination(), Amount: big.NewInt(bobAmount)},
+		},
+	}}
+}
+
+func TestRecycle(t *testing.T) {
+	leader, follower := newTestLedger(t)
+
+	request := NewObjectiveRequest(leader.Id, exit(types.Address{}, 250, 50), 1)
+	aliceObj, err := NewObjective(request, true, lookup(leader))
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, request.Id(alice.Address(), nil), aliceObj.Id())
+
+	// The proposer signs the recycled state, and nothing is submitted to the chain
+	o, se, waitingFor, err := aliceObj.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForCompleteRecycle, waitingFor)
+	testhelpers.Equals(t, 1, len(se.MessagesToSend))
+	testhelpers.Equals(t, 0, len(se.TransactionsToSubmit))
+	aliceObj = *o.(*Objective)
+	toBob := se.MessagesToSend[0].ObjectivePayloads[0]
+
+	// The counterparty countersigns straight away, since the holdings cover the recycled state
+	bobObj, err := ConstructObjectiveFromPayload(toBob, true, lookup(follower))
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, aliceObj.Id(), bobObj.Id())
+
+	// The recycle moves funds from bob to alice, so only bob gives up some of his allocation
+	testhelpers.Equals(t, false, aliceObj.LowersMyAllocation())
+	testhelpers.Equals(t, true, bobObj.LowersMyAllocation())
+	current, proposed, ok, err := bobObj.ProposedOutcome(toBob)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, true, ok)
+	testhelpers.Equals(t, exit(types.Address{}, 100, 200), current)
+	testhelpers.Equals(t, request.Outcome, proposed)
+
+	updated, err := bobObj.Update(toBob)
+	testhelpers.Ok(t, err)
+	o, se, waitingFor, err = updated.Crank(&bob.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForNothing, waitingFor)
+	testhelpers.Equals(t, 1, len(se.MessagesToSend))
+	testhelpers.Equals(t, 0, len(se.TransactionsToSubmit))
+	testhelpers.Equals(t, protocols.Completed, o.GetStatus())
+	toAlice := se.MessagesToSend[0].ObjectivePayloads[0]
+
+	updated, err = aliceObj.Update(toAlice)
+	testhelpers.Ok(t, err)
+	o, se, waitingFor, err = updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForNothing, waitingFor)
+	testhelpers.Equals(t, 0, len(se.TransactionsToSubmit))
+
+	cc, err := o.(*Objective).CreateConsensusChannel()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, leader.Id, cc.Id)
+	testhelpers.Equals(t, uint64(2), cc.ConsensusTurnNum())
+	testhelpers.Equals(t, types.Funds{types.Address{}: big.NewInt(300)}, cc.OnChainFunding)
+	ledgerOutcome := cc.ConsensusVars().Outcome
+	testhelpers.Equals(t, consensus_channel.NewBalance(alice.Destination(), big.NewInt(250)), ledgerOutcome.Leader())
+	testhelpers.Equals(t, consensus_channel.NewBalance(bob.Destination(), big.NewInt(50)), ledgerOutcome.Follower())
+}
+
+func TestRecycleSafetyConditions(t *testing.T) {
+	toIrene := exit(types.Address{}, 100, 200)
+	toIrene[0].Allocations[1].Destination = irene.Destination()
+	swapped := exit(types.Address{}, 200, 100)
+	swapped[0].Allocations[0], swapped[0].Allocations[1] = swapped[0].Allocations[1], swapped[0].Allocations[0]
+
+	testCases := []struct {
+		name     string
+		outcome  outcome.Exit
+		holdings int64
+		want     error
+	}{
+		{"more funds than before", exit(types.Address{}, 200, 200), 300, ErrTotalChanged},
+		{"fewer funds than before", exit(types.Address{}, 100, 100), 300, ErrTotalChanged},
+		{"another asset", exit(common.HexToAddress("0x01"), 100, 200), 300, ErrInvalidOutcome},
+		{"a non-participant", toIrene, 300, ErrInvalidOutcome},
+		{"the participants out of order", swapped, 300, ErrInvalidOutcome},
+		{"a negative allocation", exit(types.Address{}, -100, 400), 300, ErrInvalidOutcome},
+		{"holdings which do not cover the outcome", exit(types.Address{}, 150, 150), 299, ErrNotFunded},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			leader, _ := newTestLedger(t)
+			leader.OnChainFunding[types.Address{}] = big.NewInt(tc.holdings)
+			if _, err := NewObjective(NewObjectiveRequest(leader.Id, tc.outcome, 1), true, lookup(leader)); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	// A final state cannot recycle the channel, since it would close it
+	leader, follower := newTestLedger(t)
+	s := leader.ConsensusVars().AsState(leader.FixedPart())
+	s.TurnNum += 1
+	s.IsFinal = true
+	ss := state.NewSignedState(s)
+	sig, _ := s.Sign(alice.PrivateKey)
+	testhelpers.Ok(t, ss.AddSignature(sig))
+	payload, err := protocols.CreateObjectivePayload(objectiveId(leader.Id, 1), SignedStatePayload, ss)
+	testhelpers.Ok(t, err)
+	if _, err := ConstructObjectiveFromPayload(payload, true, lookup(follower)); !errors.Is(err, ErrInvalidRecycledState) {
+		t.Fatalf("expected %v, got %v", ErrInvalidRecycledState, err)
+	}
+}
@@ -0,0 +1,58 @@
+package ledgerrecycle
+
+import (
+	"encoding/json"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// jsonObjective replaces the ledgerrecycle.Objective's channel pointer with the
+// channel's ID, making jsonObjective suitable for serialization
+type jsonObjective struct {
+	Status protocols.ObjectiveStatus
+	C      types.Destination
+
+	Nonce         uint64
+	RecycledState state.State
+}
+
+// MarshalJSON returns a JSON representation of the LedgerRecycleObjective
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o Objective) MarshalJSON() ([]byte, error) {
+	jsonLRO := jsonObjective{
+		o.Status,
+		o.C.Id,
+		o.nonce,
+		o.recycledState,
+	}
+	return json.Marshal(jsonLRO)
+}
+
+// UnmarshalJSON populates the calling LedgerRecycleObjective with the
+// json-encoded data
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o *Objective) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var jsonLRO jsonObjective
+	err := json.Unmarshal(data, &jsonLRO)
+	if err != nil {
+		return err
+	}
+
+	o.C = &channel.Channel{}
+	o.C.Id = jsonLRO.C
+
+	o.Status = jsonLRO.Status
+	o.nonce = jsonLRO.Nonce
+	o.recycledState = jsonLRO.RecycledState
+
+	return nil
+}
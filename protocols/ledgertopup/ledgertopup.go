@@ -0,0 +1,484 @@
+// Package ledgertopup implements a protocol to deposit additional funds into an existing ledger channel.
+package ledgertopup // import "github.com/statechannels/go-nitro/ledgertopup"
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	WaitingForDeposit       = protocols.WaitingForDeposit
+	WaitingForCompleteTopUp = protocols.WaitingForCompleteTopUp
+	WaitingForNothing       = protocols.WaitingForNothing // Finished
+)
+
+const (
+	SignedStatePayload = protocols.SignedStatePayload
+)
+
+const ObjectivePrefix = "LedgerTopUp-"
+
+const (
+	ErrNotEmpty                = types.ConstError("can only top up a ledger channel which has no running guarantees")
+	ErrChannelUpdateInProgress = types.ConstError("can only top up a ledger channel which has no pending proposals")
+	ErrInvalidTopUpState       = types.ConstError("state does not describe a valid top up of the ledger channel")
+	ErrInvalidAmount           = types.ConstError("top up amount must be positive")
+)
+
+// GetConsensusChannel describes functions which return a ConsensusChannel ledger channel for a channel id.
+type GetConsensusChannel func(channelId types.Destination) (ledger *consensus_channel.ConsensusChannel, err error)
+
+// Objective is a cache of data computed by reading from the store. It stores (potentially) infinite data
+type Objective struct {
+	Status protocols.ObjectiveStatus
+	C      *channel.Channel
+
+	nonce                uint64
+	depositor            uint        // the index of the participant making the deposit
+	topUpState           state.State // the ledger state which allocates the deposited funds to the depositor
+	fullyFundedThreshold types.Funds // if the on chain holdings are equal to this amount the top up state is funded
+	transactionSubmitted bool        // whether the deposit transaction has been submitted or not
+}
+
+// NewObjective creates a new top up objective from a given request. The requesting participant makes the deposit.
+func NewObjective(request ObjectiveRequest, preApprove bool, getConsensusChannel GetConsensusChannel) (Objective, error) {
+	if request.Amount == nil || request.Amount.Sign() <= 0 {
+		return Objective{}, ErrInvalidAmount
+	}
+
+	cc, err := getConsensusChannel(request.ChannelId)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", request.ChannelId, err)
+	}
+
+	topUpState := cc.ConsensusVars().AsState(cc.FixedPart())
+	topUpState.TurnNum += 1
+	myAllocation, err := allocationFor(topUpState, cc.Participants()[cc.MyIndex])
+	if err != nil {
+		return Objective{}, err
+	}
+	myAllocation.Add(myAllocation, request.Amount)
+	if err := topUpState.Outcome.Validate(); err != nil {
+		return Objective{}, fmt.Errorf("%w: %w", ErrInvalidAmount, err)
+	}
+
+	return newObjective(preApprove, request.Nonce, uint(cc.MyIndex), topUpState, cc)
+}
+
+// ConstructObjectiveFromPayload takes in a top up state signed by the depositor and constructs an objective from it.
+func ConstructObjectiveFromPayload(
+	p protocols.ObjectivePayload,
+	preapprove bool,
+	getConsensusChannel GetConsensusChannel,
+) (Objective, error) {
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	s := ss.State()
+	if err := s.Outcome.Validate(); err != nil {
+		return Objective{}, fmt.Errorf("%w: %w", ErrInvalidTopUpState, err)
+	}
+
+	channelId, nonce, err := parseObjectiveId(p.ObjectiveId)
+	if err != nil {
+		return Objective{}, err
+	}
+	if channelId != s.ChannelId() {
+		return Objective{}, fmt.Errorf("objective %s does not match channel %s", p.ObjectiveId, s.ChannelId())
+	}
+
+	cc, err := getConsensusChannel(channelId)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", channelId, err)
+	}
+
+	// The depositor is the participant whose allocation has increased, which must be the sender
+	depositor := uint(1 - cc.MyIndex)
+	expected := cc.ConsensusVars().AsState(cc.FixedPart())
+	expected.TurnNum += 1
+	if len(s.Outcome) != 1 || len(expected.Outcome) != 1 || len(s.Outcome[0].Allocations) != len(expected.Outcome[0].Allocations) {
+		return Objective{}, ErrInvalidTopUpState
+	}
+	depositorAllocation, err := allocationFor(expected, cc.Participants()[depositor])
+	if err != nil {
+		return Objective{}, err
+	}
+	proposedAllocation, err := allocationFor(s, cc.Participants()[depositor])
+	if err != nil {
+		return Objective{}, err
+	}
+	if proposedAllocation.Cmp(depositorAllocation) <= 0 {
+		return Objective{}, ErrInvalidTopUpState
+	}
+	depositorAllocation.Set(proposedAllocation)
+	if !expected.Equal(s) {
+		return Objective{}, ErrInvalidTopUpState
+	}
+
+	return newObjective(preapprove, nonce, depositor, s, cc)
+}
+
+// newObjective constructs an objective which moves the supplied ledger channel to the top up state.
+func newObjective(preApprove bool, nonce uint64, depositor uint, topUpState state.State, cc *consensus_channel.ConsensusChannel) (Objective, error) {
+	if targets := cc.FundingTargets(); len(targets) != 0 {
+		return Objective{}, fmt.Errorf("channel %s funds %v: %w", cc.Id, targets, ErrNotEmpty)
+	}
+	if len(cc.ProposalQueue()) != 0 {
+		return Objective{}, ErrChannelUpdateInProgress
+	}
+
+	c, err := directdefund.CreateChannelFromConsensusChannel(*cc)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not create Channel from ConsensusChannel; %w", err)
+	}
+
+	init := Objective{}
+	if preApprove {
+		init.Status = protocols.Approved
+	} else {
+		init.Status = protocols.Unapproved
+	}
+	init.C = c
+	init.nonce = nonce
+	init.depositor = depositor
+	init.topUpState = topUpState
+	init.fullyFundedThreshold = topUpState.Outcome.TotalAllocated()
+
+	return init, nil
+}
+
+// Id returns the unique id of the objective
+func (o *Objective) Id() protocols.ObjectiveId {
+	return objectiveId(o.C.Id, o.nonce)
+}
+
+func (o *Objective) Approve() protocols.Objective {
+	updated := o.clone()
+	// todo: consider case of o.Status == Rejected
+	updated.Status = protocols.Approved
+
+	return &updated
+}
+
+func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+	updated := o.clone()
+	updated.Status = protocols.Rejected
+	peer := o.C.Participants[1-o.C.MyIndex]
+
+	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), peer)}
+	return &updated, sideEffects
+}
+
+// IsCancellable returns true until the deposit has been submitted or seen on chain.
+// Once the deposit lands, abandoning the objective would leave the deposited funds unallocated.
+func (o *Objective) IsCancellable() bool {
+	return !o.transactionSubmitted && !o.fundingComplete()
+}
+
+// OwnsChannel returns the channel that the objective is topping up.
+func (o *Objective) OwnsChannel() types.Destination {
+	return o.C.Id
+}
+
+// GetStatus returns the status of the objective.
+func (o *Objective) GetStatus() protocols.ObjectiveStatus {
+	return o.Status
+}
+
+func (o *Objective) Related() []protocols.Storable {
+	return []protocols.Storable{o.C}
+}
+
+// Update receives an ObjectivePayload, applies all applicable data to the Objective,
+// and returns the updated objective
+func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
+	if o.Id() != p.ObjectiveId {
+		return o, fmt.Errorf("event and objective Ids do not match: %s and %s respectively", string(p.ObjectiveId), string(o.Id()))
+	}
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return o, fmt.Errorf("could not get signed state payload: %w", err)
+	}
+	if len(ss.Signatures()) == 0 {
+		return o, fmt.Errorf("event does not contain a signed state")
+	}
+	if !ss.State().Equal(o.topUpState) {
+		return o, ErrInvalidTopUpState
+	}
+
+	updated := o.clone()
+	updated.C.AddSignedState(ss)
+	return &updated, nil
+}
+
+// Crank inspects the extended state and declares a list of Effects to be executed
+func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := o.clone()
+
+	sideEffects := protocols.SideEffects{}
+
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
+	}
+
+	fundingComplete := updated.fundingComplete()
+
+	// The depositor signs the top up state straight away, since it only allocates more funds to them.
+	// The other participant only signs once the deposit has landed, since until then the state is underfunded.
+	if !updated.topUpSignedByMe() && (updated.isDepositor() || fundingComplete) {
+		ss, err := updated.C.SignAndAddState(updated.topUpState, secretKey)
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteTopUp, fmt.Errorf("could not sign top up state %w", err)
+		}
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.Id(), ss, SignedStatePayload, updated.otherParticipants()...)
+		if err != nil {
+			return &updated, protocols.SideEffects{}, WaitingForCompleteTopUp, fmt.Errorf("could not create payload message %w", err)
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+	}
+
+	// Funding
+	if !fundingComplete && updated.isDepositor() && !updated.transactionSubmitted {
+		deposit := protocols.NewDepositTransaction(updated.C.Id, updated.amountToDeposit())
+		deposit.TurnNum = updated.topUpState.TurnNum
+		updated.transactionSubmitted = true
+		sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, deposit)
+	}
+
+	if !fundingComplete {
+		return &updated, sideEffects, WaitingForDeposit, nil
+	}
+
+	if !updated.topUpComplete() {
+		return &updated, sideEffects, WaitingForCompleteTopUp, nil
+	}
+
+	// Completion
+	updated.Status = protocols.Completed
+	return &updated, sideEffects, WaitingForNothing, nil
+}
+
+// CreateConsensusChannel creates a ConsensusChannel from the Objective using the fully signed top up state.
+func (o *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChannel, error) {
+	if !o.topUpComplete() {
+		return nil, fmt.Errorf("expected top up for channel %s to be complete", o.C.Id)
+	}
+	return o.consensusChannelFrom(o.C.OffChain.SignedStateForTurnNum[o.topUpState.TurnNum])
+}
+
+// RestoreConsensusChannel creates a ConsensusChannel from the ledger channel's latest supported state,
+// so that the channel can be governed by it again once the top up has been rejected.
+func (o *Objective) RestoreConsensusChannel() (*consensus_channel.ConsensusChannel, error) {
+	supported, err := o.C.LatestSupportedSignedState()
+	if err != nil {
+		return nil, fmt.Errorf("could not get latest supported state: %w", err)
+	}
+	return o.consensusChannelFrom(supported)
+}
+
+// consensusChannelFrom creates a ConsensusChannel governed by the supplied signed state of the ledger channel.
+func (o *Objective) consensusChannelFrom(ss state.SignedState) (*consensus_channel.ConsensusChannel, error) {
+	leaderSig, err := ss.GetParticipantSignature(uint(consensus_channel.Leader))
+	if err != nil {
+		return nil, fmt.Errorf("could not get leader signature: %w", err)
+	}
+	followerSig, err := ss.GetParticipantSignature(uint(consensus_channel.Follower))
+	if err != nil {
+		return nil, fmt.Errorf("could not get follower signature: %w", err)
+	}
+	signatures := [2]state.Signature{leaderSig, followerSig}
+
+	s := ss.State()
+	outcome, err := consensus_channel.FromExit(s.Outcome[0])
+	if err != nil {
+		return nil, fmt.Errorf("could not create ledger outcome from channel exit: %w", err)
+	}
+
+	var con consensus_channel.ConsensusChannel
+	if o.C.MyIndex == uint(consensus_channel.Leader) {
+		con, err = consensus_channel.NewLeaderChannel(o.C.FixedPart, s.TurnNum, outcome, signatures)
+	} else {
+		con, err = consensus_channel.NewFollowerChannel(o.C.FixedPart, s.TurnNum, outcome, signatures)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create consensus channel: %w", err)
+	}
+	con.OnChainFunding = o.C.OnChain.Holdings.Clone()
+	return &con, nil
+}
+
+// IsLedgerTopUpObjective inspects a objective id and returns true if the objective id is for a ledger top up objective.
+func IsLedgerTopUpObjective(id protocols.ObjectiveId) bool {
+	return strings.HasPrefix(string(id), ObjectivePrefix)
+}
+
+//  Private methods on the Objective
+
+// isDepositor returns true if I am the participant making the deposit.
+func (o *Objective) isDepositor() bool {
+	return o.C.MyIndex == o.depositor
+}
+
+// topUpSignedByMe returns true if I have signed the top up state.
+func (o *Objective) topUpSignedByMe() bool {
+	ss, ok := o.C.OffChain.SignedStateForTurnNum[o.topUpState.TurnNum]
+	return ok && ss.HasSignatureForParticipant(o.C.MyIndex)
+}
+
+// topUpComplete returns true if the top up state has been signed by every participant.
+func (o *Objective) topUpComplete() bool {
+	ss, ok := o.C.OffChain.SignedStateForTurnNum[o.topUpState.TurnNum]
+	return ok && ss.HasAllSignatures()
+}
+
+// fundingComplete returns true if the recorded OnChainHoldings are greater than or equal to the threshold for being fully funded.
+func (o *Objective) fundingComplete() bool {
+	for asset, threshold := range o.fullyFundedThreshold {
+		chainHolding, ok := o.C.OnChain.Holdings[asset]
+
+		if !ok {
+			return false
+		}
+
+		if types.Gt(threshold, chainHolding) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// amountToDeposit computes the deposit which brings the recorded OnChainHoldings up to the fully funded threshold.
+func (o *Objective) amountToDeposit() types.Funds {
+	deposits := make(types.Funds, len(o.fullyFundedThreshold))
+
+	for asset, target := range o.fullyFundedThreshold {
+		holding, ok := o.C.OnChain.Holdings[asset]
+		if !ok {
+			holding = big.NewInt(0)
+		}
+		deposits[asset] = big.NewInt(0).Sub(target, holding)
+	}
+
+	return deposits
+}
+
+// otherParticipants returns the participants in the channel that are not the current participant.
+func (o *Objective) otherParticipants() []types.Address {
+	others := make([]types.Address, 0)
+	for i, p := range o.C.Participants {
+		if i != int(o.C.MyIndex) {
+			others = append(others, p)
+		}
+	}
+	return others
+}
+
+// RetryTransaction returns an updated objective which declares its deposit transaction again when next cranked, if tx is that transaction.
+func (o *Objective) RetryTransaction(tx protocols.ChainTransaction) protocols.TransactionRetrier {
+	updated := o.clone()
+	if _, ok := tx.(protocols.DepositTransaction); ok {
+		updated.transactionSubmitted = false
+	}
+	return &updated
+}
+
+// clone returns a deep copy of the receiver.
+func (o *Objective) clone() Objective {
+	clone := Objective{}
+	clone.Status = o.Status
+	clone.C = o.C.Clone()
+	clone.nonce = o.nonce
+	clone.depositor = o.depositor
+	clone.topUpState = o.topUpState.Clone()
+	clone.fullyFundedThreshold = o.fullyFundedThreshold.Clone()
+	clone.transactionSubmitted = o.transactionSubmitted
+	return clone
+}
+
+// allocationFor returns the (single asset) allocation of the state to the given participant.
+func allocationFor(s state.State, participant types.Address) (*big.Int, error) {
+	if len(s.Outcome) != 1 {
+		return nil, fmt.Errorf("a ledger channel only supports a single asset")
+	}
+	destination := types.AddressToDestination(participant)
+	for i := range s.Outcome[0].Allocations {
+		if s.Outcome[0].Allocations[i].Destination == destination {
+			return s.Outcome[0].Allocations[i].Amount, nil
+		}
+	}
+	return nil, fmt.Errorf("no allocation for participant %s", participant)
+}
+
+// objectiveId returns the id of the top up objective for the channel with the given nonce.
+func objectiveId(channelId types.Destination, nonce uint64) protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + channelId.String() + "-" + strconv.FormatUint(nonce, 10))
+}
+
+// parseObjectiveId returns the channel id and nonce encoded in a top up objective id.
+func parseObjectiveId(id protocols.ObjectiveId) (types.Destination, uint64, error) {
+	channelId, nonce, found := strings.Cut(strings.TrimPrefix(string(id), ObjectivePrefix), "-")
+	if !IsLedgerTopUpObjective(id) || !found {
+		return types.Destination{}, 0, fmt.Errorf("%s is not a ledger top up objective id", id)
+	}
+	n, err := strconv.ParseUint(nonce, 10, 64)
+	if err != nil {
+		return types.Destination{}, 0, fmt.Errorf("could not parse nonce of objective %s: %w", id, err)
+	}
+	return types.Destination(common.HexToHash(channelId)), n, nil
+}
+
+// ObjectiveRequest represents a request to create a new ledger top up objective.
+type ObjectiveRequest struct {
+	ChannelId        types.Destination
+	Amount           *big.Int
+	Nonce            uint64
+	objectiveStarted chan struct{}
+}
+
+// NewObjectiveRequest creates a new ObjectiveRequest.
+func NewObjectiveRequest(channelId types.Destination, amount *big.Int, nonce uint64) ObjectiveRequest {
+	return ObjectiveRequest{
+		ChannelId:        channelId,
+		Amount:           amount,
+		Nonce:            nonce,
+		objectiveStarted: make(chan struct{}),
+	}
+}
+
+// SignalObjectiveStarted is used by the engine to signal the objective has been started.
+func (r ObjectiveRequest) SignalObjectiveStarted() {
+	close(r.objectiveStarted)
+}
+
+// WaitForObjectiveToStart blocks until the objective starts
+func (r ObjectiveRequest) WaitForObjectiveToStart() {
+	<-r.objectiveStarted
+}
+
+// Id returns the objective id for the request.
+func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return objectiveId(r.ChannelId, r.Nonce)
+}
+
+// getSignedStatePayload takes in a serialized signed state payload and returns the deserialized SignedState.
+func getSignedStatePayload(b []byte) (state.SignedState, error) {
+	ss := state.SignedState{}
+	err := json.Unmarshal(b, &ss)
+	if err != nil {
+		return ss, fmt.Errorf("could not unmarshal signed state: %w", err)
+	}
+	return ss, nil
+}
@@ -0,0 +1,136 @@
+package ledgertopup
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var alice, bob testactors.Actor = testactors.Alice, testactors.Bob
+
+// newTestLedger returns the leader (alice) and follower (bob) views of a funded ledger channel.
+func newTestLedger(t *testing.T) (leader, follower *consensus_channel.ConsensusChannel) {
+	fp := state.FixedPart{
+		Participants:      []types.Address{alice.Address(), bob.Address()},
+		ChannelNonce:      9001,
+		ChallengeDuration: 60,
+	}
+	outcome := consensus_channel.NewLedgerOutcome(
+		types.Address{},
+		consensus_channel.NewBalance(alice.Destination(), big.NewInt(100)),
+		consensus_channel.NewBalance(bob.Destination(), big.NewInt(200)),
+		[]consensus_channel.Guarantee{},
+	)
+	vars := consensus_channel.Vars{Outcome: *outcome, TurnNum: 1}
+	aliceSig, _ := vars.AsState(fp).Sign(alice.PrivateKey)
+	bobSig, _ := vars.AsState(fp).Sign(bob.PrivateKey)
+	sigs := [2]state.Signature{aliceSig, bobSig}
+
+	l, err := consensus_channel.NewLeaderChannel(fp, 1, *outcome, sigs)
+	testhelpers.Ok(t, err)
+	f, err := consensus_channel.NewFollowerChannel(fp, 1, *outcome, sigs)
+	testhelpers.Ok(t, err)
+	l.OnChainFunding = types.Funds{types.Address{}: big.NewInt(300)}
+	f.OnChainFunding = types.Funds{types.Address{}: big.NewInt(300)}
+	return &l, &f
+}
+
+func lookup(cc *consensus_channel.ConsensusChannel) GetConsensusChannel {
+	return func(types.Destination) (*consensus_channel.ConsensusChannel, error) { return cc, nil }
+}
+
+func TestTopUp(t *testing.T) {
+	leader, follower := newTestLedger(t)
+
+	if _, err := NewObjective(NewObjectiveRequest(leader.Id, big.NewInt(0), 1), true, lookup(leader)); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("expected %v, got %v", ErrInvalidAmount, err)
+	}
+	// The ledger channel cannot allocate more than a uint256
+	if _, err := NewObjective(NewObjectiveRequest(leader.Id, math.MaxBig256, 1), true, lookup(leader)); !errors.Is(err, outcome.ErrAllocationOverflow) {
+		t.Fatalf("expected %v, got %v", outcome.ErrAllocationOverflow, err)
+	}
+
+	request := NewObjectiveRequest(leader.Id, big.NewInt(50), 1)
+	aliceObj, err := NewObjective(request, true, lookup(leader))
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, request.Id(alice.Address(), nil), aliceObj.Id())
+	testhelpers.Assert(t, aliceObj.IsCancellable(), "expected the top up to be cancellable before the deposit is submitted")
+
+	// The depositor signs the top up state and deposits straight away
+	o, se, waitingFor, err := aliceObj.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForDeposit, waitingFor)
+	testhelpers.Equals(t, 1, len(se.MessagesToSend))
+	testhelpers.Equals(t, 1, len(se.TransactionsToSubmit))
+	deposit, ok := se.TransactionsToSubmit[0].(protocols.DepositTransaction)
+	testhelpers.Assert(t, ok, "expected a deposit transaction")
+	testhelpers.Equals(t, types.Funds{types.Address{}: big.NewInt(50)}, deposit.Deposit)
+	aliceObj = *o.(*Objective)
+	testhelpers.Assert(t, !aliceObj.IsCancellable(), "expected the top up not to be cancellable once the deposit is submitted")
+	toBob := se.MessagesToSend[0].ObjectivePayloads[0]
+
+	// The counterparty waits for the deposit before signing
+	bobObj, err := ConstructObjectiveFromPayload(toBob, true, lookup(follower))
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, aliceObj.Id(), bobObj.Id())
+	updated, err := bobObj.Update(toBob)
+	testhelpers.Ok(t, err)
+	o, se, waitingFor, err = updated.Crank(&bob.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForDeposit, waitingFor)
+	testhelpers.Equals(t, 0, len(se.MessagesToSend))
+	testhelpers.Equals(t, 0, len(se.TransactionsToSubmit))
+	bobObj = *o.(*Objective)
+	testhelpers.Assert(t, bobObj.IsCancellable(), "expected the top up to be cancellable before the deposit lands")
+
+	bobObj.C.OnChain.Holdings[types.Address{}] = big.NewInt(350)
+	testhelpers.Assert(t, !bobObj.IsCancellable(), "expected the top up not to be cancellable once the deposit lands")
+	o, se, waitingFor, err = bobObj.Crank(&bob.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForNothing, waitingFor)
+	testhelpers.Equals(t, 1, len(se.MessagesToSend))
+	testhelpers.Equals(t, protocols.Completed, o.GetStatus())
+	toAlice := se.MessagesToSend[0].ObjectivePayloads[0]
+
+	aliceObj.C.OnChain.Holdings[types.Address{}] = big.NewInt(350)
+	updated, err = aliceObj.Update(toAlice)
+	testhelpers.Ok(t, err)
+	o, _, waitingFor, err = updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForNothing, waitingFor)
+
+	cc, err := o.(*Objective).CreateConsensusChannel()
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, leader.Id, cc.Id)
+	testhelpers.Equals(t, uint64(2), cc.ConsensusTurnNum())
+	ledgerOutcome := cc.ConsensusVars().Outcome
+	testhelpers.Equals(t, consensus_channel.NewBalance(alice.Destination(), big.NewInt(150)), ledgerOutcome.Leader())
+	testhelpers.Equals(t, consensus_channel.NewBalance(bob.Destination(), big.NewInt(200)), ledgerOutcome.Follower())
+}
+
+func TestConstructRejectsInvalidTopUp(t *testing.T) {
+	leader, follower := newTestLedger(t)
+
+	// A state which allocates more funds to the recipient is not a top up by the sender
+	s := follower.ConsensusVars().AsState(follower.FixedPart())
+	s.TurnNum += 1
+	s.Outcome[0].Allocations[1].Amount = big.NewInt(250)
+	ss := state.NewSignedState(s)
+	sig, _ := s.Sign(alice.PrivateKey)
+	testhelpers.Ok(t, ss.AddSignature(sig))
+
+	payload, err := protocols.CreateObjectivePayload(objectiveId(leader.Id, 1), SignedStatePayload, ss)
+	testhelpers.Ok(t, err)
+	if _, err := ConstructObjectiveFromPayload(payload, true, lookup(follower)); !errors.Is(err, ErrInvalidTopUpState) {
+		t.Fatalf("expected %v, got %v", ErrInvalidTopUpState, err)
+	}
+}
@@ -0,0 +1,67 @@
+package ledgertopup
+
+import (
+	"encoding/json"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// jsonObjective replaces the ledgertopup.Objective's channel pointer with the
+// channel's ID, making jsonObjective suitable for serialization
+type jsonObjective struct {
+	Status protocols.ObjectiveStatus
+	C      types.Destination
+
+	Nonce                uint64
+	Depositor            uint
+	TopUpState           state.State
+	FullyFundedThreshold types.Funds
+	TransactionSubmitted bool
+}
+
+// MarshalJSON returns a JSON representation of the LedgerTopUpObjective
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o Objective) MarshalJSON() ([]byte, error) {
+	jsonLTO := jsonObjective{
+		o.Status,
+		o.C.Id,
+		o.nonce,
+		o.depositor,
+		o.topUpState,
+		o.fullyFundedThreshold,
+		o.transactionSubmitted,
+	}
+	return json.Marshal(jsonLTO)
+}
+
+// UnmarshalJSON populates the calling LedgerTopUpObjective with the
+// json-encoded data
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o *Objective) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var jsonLTO jsonObjective
+	err := json.Unmarshal(data, &jsonLTO)
+	if err != nil {
+		return err
+	}
+
+	o.C = &channel.Channel{}
+	o.C.Id = jsonLTO.C
+
+	o.Status = jsonLTO.Status
+	o.nonce = jsonLTO.Nonce
+	o.depositor = jsonLTO.Depositor
+	o.topUpState = jsonLTO.TopUpState
+	o.fullyFundedThreshold = jsonLTO.FullyFundedThreshold
+	o.transactionSubmitted = jsonLTO.TransactionSubmitted
+
+	return nil
+}
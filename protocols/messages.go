@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -19,10 +21,34 @@ type ObjectivePayload struct {
 	// Type is the type of the payload the message contains.
 	// This is useful when a protocol wants to handle different types of payloads.
 	Type PayloadType
+	// ChainId is the id of the chain the objective's channel is on. It is only set by nodes which operate channels on several chains.
+	ChainId string `json:",omitempty"`
 }
 
 type PayloadType string
 
+// SignedStatePayload is the type of the payloads with which every protocol sends signed states
+const SignedStatePayload PayloadType = "SignedStatePayload"
+
+// ErrInvalidSignatures is returned for payloads carrying signed states whose signatures are missing or are not from the participants they are recorded for
+var ErrInvalidSignatures = errors.New("payload has invalid signatures")
+
+// VerifyPayloadSignatures returns an error wrapping ErrInvalidSignatures unless the payload's signed state is signed by the participants its signatures are recorded for.
+// Payloads which do not carry a signed state are not checked.
+func VerifyPayloadSignatures(p ObjectivePayload) error {
+	if p.Type != SignedStatePayload {
+		return nil
+	}
+	var ss state.SignedState
+	if err := json.Unmarshal(p.PayloadData, &ss); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignatures, err)
+	}
+	if err := ss.VerifySignatures(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignatures, err)
+	}
+	return nil
+}
+
 // CreateObjectivePayload generates an objective message from the given objective id and payload.
 // CreateObjectivePayload handles serializing `p` into json.
 func CreateObjectivePayload(id ObjectiveId, payloadType PayloadType, p interface{}) (ObjectivePayload, error) {
@@ -49,12 +75,18 @@ type Message struct {
 	Payments []payments.Voucher
 	// RejectedObjectives is a collection of objectives that have been rejected.
 	RejectedObjectives []ObjectiveId
+	// RejectionReasons explains why some of the RejectedObjectives were rejected. It is omitted from the encoding when empty.
+	RejectionReasons map[ObjectiveId]string `json:",omitempty"`
+	// TraceContext holds the W3C traceparent of the span which the sender records for each objective, so that the recipient's spans join the same trace.
+	// It is omitted from the encoding when empty.
+	TraceContext map[ObjectiveId]string `json:",omitempty"`
 }
 
-// Serialize serializes the message into a string.
-func (m Message) Serialize() (string, error) {
-	bytes, err := json.Marshal(m)
-	return string(bytes), err
+// Serialize returns the canonical encoding of the message, which is used on the wire.
+// The encoding is stable: fields appear in declaration order, lists keep their order, amounts are encoded as decimal integers
+// ("null" for a nil amount) and signatures as hex strings, so equal messages always have identical encodings.
+func (m Message) Serialize() ([]byte, error) {
+	return json.Marshal(m)
 }
 
 // Merge accepts a SideEffects struct that is merged into the the existing SideEffects.
@@ -62,6 +94,7 @@ func (se *SideEffects) Merge(other SideEffects) {
 	se.MessagesToSend = append(se.MessagesToSend, other.MessagesToSend...)
 	se.TransactionsToSubmit = append(se.TransactionsToSubmit, other.TransactionsToSubmit...)
 	se.ProposalsToProcess = append(se.ProposalsToProcess, other.ProposalsToProcess...)
+	se.Sequence = append(se.Sequence, other.Sequence...)
 }
 
 // GetProposalObjectiveId returns the objectiveId for a proposal.
@@ -103,8 +136,7 @@ func CreateObjectivePayloadMessage(id ObjectiveId, p interface{}, payloadType Pa
 	return messages, nil
 }
 
-// CreateSignedProposalMessage returns a signed proposal message addressed to the counterparty in the given ledger
-// It contains the provided signed proposals and any proposals in the proposal queue.
+// CreateRejectionNoticeMessage returns a message for each recipient that notifies them that the objective has been rejected.
 func CreateRejectionNoticeMessage(oId ObjectiveId, recipients ...types.Address) []Message {
 	messages := make([]Message, 0)
 	for _, recipient := range recipients {
@@ -115,6 +147,22 @@ func CreateRejectionNoticeMessage(oId ObjectiveId, recipients ...types.Address)
 	return messages
 }
 
+// AddRejectionReason records the reason the objective was rejected on each of the messages which notify a recipient of its rejection.
+func AddRejectionReason(messages []Message, oId ObjectiveId, reason string) {
+	if reason == "" {
+		return
+	}
+	for i := range messages {
+		if !slices.Contains(messages[i].RejectedObjectives, oId) {
+			continue
+		}
+		if messages[i].RejectionReasons == nil {
+			messages[i].RejectionReasons = make(map[ObjectiveId]string)
+		}
+		messages[i].RejectionReasons[oId] = reason
+	}
+}
+
 // CreateSignedProposalMessage returns a signed proposal message addressed to the counterparty in the given ledger channel.
 // The proposals MUST be sorted by turnNum
 // since the ledger protocol relies on the message receipient processing the proposals in that order. See ADR 4.
@@ -133,10 +181,10 @@ func CreateVoucherMessage(voucher payments.Voucher, recipients ...types.Address)
 	return messages
 }
 
-// DeserializeMessage deserializes the passed string into a protocols.Message.
-func DeserializeMessage(s string) (Message, error) {
+// DeserializeMessage decodes a message from the encoding returned by Serialize.
+func DeserializeMessage(data []byte) (Message, error) {
 	msg := Message{}
-	err := json.Unmarshal([]byte(s), &msg)
+	err := json.Unmarshal(data, &msg)
 
 	return msg, err
 }
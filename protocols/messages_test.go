@@ -1,13 +1,16 @@
 package protocols
 
 import (
+	"bytes"
 	"encoding/json"
 	"math/big"
 	"reflect"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -83,13 +86,13 @@ func TestMessage(t *testing.T) {
 			t.Error(err)
 		}
 		want := msgString
-		if got != want {
+		if string(got) != want {
 			t.Fatalf("incorrect serialization: got:\n%v\nwanted:\n%v", got, want)
 		}
 	})
 
 	t.Run(`deserialize`, func(t *testing.T) {
-		got, err := DeserializeMessage(msgString)
+		got, err := DeserializeMessage([]byte(msgString))
 		want := msg
 		if err != nil {
 			t.Error(err)
@@ -99,3 +102,44 @@ func TestMessage(t *testing.T) {
 		}
 	})
 }
+
+func FuzzMessageRoundTrip(f *testing.F) {
+	f.Add([]byte{'a'}, "say-hello-to-my-little-friend", []byte(`{"hello":"world"}`), int64(123), uint64(0), make([]byte, 65))
+	f.Add([]byte{}, "", []byte{}, int64(-1), uint64(1<<63), bytes.Repeat([]byte{0xff}, 65))
+
+	f.Fuzz(func(t *testing.T, to []byte, objectiveId string, payload []byte, amount int64, turnNum uint64, sig []byte) {
+		signature := state.Signature{}
+		if len(sig) >= 65 {
+			signature = crypto.SplitSignature(sig[:65])
+		}
+		proposal := addProposal(types.Destination{'l'}, turnNum)
+		proposal.Signature = signature
+		msg := Message{
+			To:                 common.BytesToAddress(to),
+			ObjectivePayloads:  []ObjectivePayload{{ObjectiveId: ObjectiveId(objectiveId), PayloadData: payload}},
+			LedgerProposals:    []consensus_channel.SignedProposal{proposal, removeProposal(types.Destination{'l'}, turnNum)},
+			Payments:           []payments.Voucher{{ChannelId: types.Destination{'d'}, Amount: big.NewInt(amount), Signature: signature}},
+			RejectedObjectives: []ObjectiveId{ObjectiveId(objectiveId)},
+			RejectionReasons:   map[ObjectiveId]string{ObjectiveId(objectiveId): objectiveId},
+		}
+
+		encoded, err := msg.Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := DeserializeMessage(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reencoded, err := decoded.Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(encoded, reencoded) {
+			t.Fatalf("encoding is not stable: got:\n%s\nwanted:\n%s", reencoded, encoded)
+		}
+		if decoded.Payments[0].Amount.Int64() != amount || decoded.LedgerProposals[0].TurnNum != turnNum {
+			t.Fatalf("incorrect round trip: got %+v, wanted %+v", decoded, msg)
+		}
+	})
+}
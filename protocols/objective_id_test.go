@@ -0,0 +1,134 @@
+package protocols_test
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/appupdate"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgerrecycle"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// The expected channel ids in these vectors are keccak256(abi.encode(address[] participants, uint256 channelNonce, address appDefinition, uint48 challengeDuration)),
+// which is how the TypeScript nitro-protocol implementation (getChannelId) computes them.
+// They were computed independently of this codebase. If any of them change, objective ids will no longer agree with other implementations.
+var (
+	alice   = common.HexToAddress("0xAAA6628Ec44A8a742987EF3A114dDFE2D4F7aDCE")
+	bob     = common.HexToAddress("0xBBB676f9cFF8D242e9eaC39D063848807d3D1D94")
+	irene   = common.HexToAddress("0x111A00868581f73AB42FEEF67D235Ca09ca1E8db")
+	ivan    = common.HexToAddress("0xA8d2D06aCE9c7FFc24Ee785C2695678aeCDfd7A0")
+	appDef  = common.HexToAddress("0x5e29E5Ab8EF33F050c7cc10B5a0456D975C5F88d")
+	chainId = big.NewInt(1337)
+)
+
+func TestDirectFundObjectiveIdVectors(t *testing.T) {
+	vectors := []struct {
+		me, counterparty  types.Address
+		nonce             uint64
+		appDefinition     types.Address
+		challengeDuration uint32
+		want              string
+	}{
+		{alice, bob, 0, types.Address{}, 0, "0xa43f6f6624a4159b0c2b80e045cb24411ed043d3bc6bbc98c43eb56f66a34166"},
+		{alice, bob, 37140676580, appDef, 60, "0xe50a5b831f59ea1161c2ccb517e1ce3f8cb366abdfa5daf924797ecba3715058"},
+		{bob, alice, 1, types.Address{}, 3600, "0x78e0183b85f697aad0524afd85f9b6a5aa2fcfbc9e825e95724c4aee9f12bead"},
+	}
+
+	for _, v := range vectors {
+		r := directfund.NewObjectiveRequest(v.counterparty, v.challengeDuration, outcome.Exit{}, v.nonce, v.appDefinition)
+		want := protocols.ObjectiveId(directfund.ObjectivePrefix + v.want)
+
+		if got := r.Id(v.me, chainId); got != want {
+			t.Errorf("Id: expected %s, got %s", want, got)
+		}
+		response := r.Response(v.me, chainId)
+		if response.Id != want {
+			t.Errorf("Response.Id: expected %s, got %s", want, response.Id)
+		}
+		if response.ChannelId.String() != v.want {
+			t.Errorf("Response.ChannelId: expected %s, got %s", v.want, response.ChannelId)
+		}
+	}
+}
+
+func TestVirtualFundObjectiveIdVectors(t *testing.T) {
+	// The app definition is not part of the virtual channel's fixed part, so it must not affect the id
+	vectors := []struct {
+		me             types.Address
+		intermediaries []types.Address
+		counterparty   types.Address
+		nonce          uint64
+		appDefinition  types.Address
+		want           string
+	}{
+		{alice, []types.Address{irene}, bob, 9001, types.Address{}, "0xf72dc771471f73ccec6e4e392bfcd062e6ea517d541bfcf9b2f9977077294e81"},
+		{alice, []types.Address{irene}, bob, 9001, appDef, "0xf72dc771471f73ccec6e4e392bfcd062e6ea517d541bfcf9b2f9977077294e81"},
+	}
+
+	for _, v := range vectors {
+		r := virtualfund.NewObjectiveRequest(v.intermediaries, v.counterparty, 0, outcome.Exit{}, v.nonce, v.appDefinition)
+		want := protocols.ObjectiveId(virtualfund.ObjectivePrefix + v.want)
+
+		if got := r.Id(v.me, chainId); got != want {
+			t.Errorf("Id: expected %s, got %s", want, got)
+		}
+		if got := r.Response(v.me).Id; got != want {
+			t.Errorf("Response.Id: expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestDefundObjectiveIdVectors(t *testing.T) {
+	// Defunding objective ids only depend on the channel id, so every participant derives the same id
+	channelId := types.Destination(common.HexToHash("0x8bb3ffa543102b0ccc73203f1b50b7bcad55425f01050858c6f2ba94b0a724e6"))
+
+	for _, me := range []types.Address{alice, irene, ivan, bob} {
+		want := protocols.ObjectiveId(directdefund.ObjectivePrefix + channelId.String())
+		if got := directdefund.NewObjectiveRequest(channelId).Id(me, chainId); got != want {
+			t.Errorf("directdefund: expected %s, got %s", want, got)
+		}
+		want = protocols.ObjectiveId(virtualdefund.ObjectivePrefix + channelId.String())
+		if got := virtualdefund.NewObjectiveRequest(channelId).Id(me, chainId); got != want {
+			t.Errorf("virtualdefund: expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestObjectiveIdType(t *testing.T) {
+	channelId := "0xa43f6f6624a4159b0c2b80e045cb24411ed043d3bc6bbc98c43eb56f66a34166"
+	for _, prefix := range []string{
+		directfund.ObjectivePrefix,
+		directdefund.ObjectivePrefix,
+		virtualfund.ObjectivePrefix,
+		virtualdefund.ObjectivePrefix,
+		ledgertopup.ObjectivePrefix,
+		ledgerrecycle.ObjectivePrefix,
+		appupdate.ObjectivePrefix,
+	} {
+		id := protocols.ObjectiveId(prefix + channelId)
+		got, err := id.Type()
+		if err != nil {
+			t.Errorf("%s: %v", id, err)
+			continue
+		}
+		if want := strings.TrimSuffix(prefix, "-"); got != want {
+			t.Errorf("%s: expected type %s, got %s", id, want, got)
+		}
+	}
+
+	for _, id := range []protocols.ObjectiveId{"", "DirectFunding", "Unknown-" + protocols.ObjectiveId(channelId), "directfunding-" + protocols.ObjectiveId(channelId)} {
+		if got, err := id.Type(); !errors.Is(err, protocols.ErrUnknownObjectiveType) {
+			t.Errorf("%q: expected %v, got type %q and error %v", id, protocols.ErrUnknownObjectiveType, got, err)
+		}
+	}
+}
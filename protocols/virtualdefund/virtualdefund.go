@@ -17,16 +17,16 @@ import (
 )
 
 const (
-	WaitingForFinalStateFromAlice protocols.WaitingFor = "WaitingForFinalStateFromAlice"
-	WaitingForSupportedFinalState protocols.WaitingFor = "WaitingForSupportedFinalState" // Round 1
-	WaitingForDefundingOnMyLeft   protocols.WaitingFor = "WaitingForDefundingOnMyLeft"   // Round 2
-	WaitingForDefundingOnMyRight  protocols.WaitingFor = "WaitingForDefundingOnMyRight"  // Round 2
-	WaitingForNothing             protocols.WaitingFor = "WaitingForNothing"             // Finished
+	WaitingForFinalStateFromAlice = protocols.WaitingForFinalStateFromAlice
+	WaitingForSupportedFinalState = protocols.WaitingForSupportedFinalState // Round 1
+	WaitingForDefundingOnMyLeft   = protocols.WaitingForDefundingOnMyLeft   // Round 2
+	WaitingForDefundingOnMyRight  = protocols.WaitingForDefundingOnMyRight  // Round 2
+	WaitingForNothing             = protocols.WaitingForNothing             // Finished
 )
 
 const (
 	// SignedStatePayload indicates that the payload is a json serialized signed state
-	SignedStatePayload protocols.PayloadType = "SignedStatePayload"
+	SignedStatePayload = protocols.SignedStatePayload
 	// RequestFinalStatePayload indicates that the payload is a request for the final state
 	// The actual payload is simply the channel id that the final state is for
 	RequestFinalStatePayload protocols.PayloadType = "RequestFinalStatePayload"
@@ -542,6 +542,18 @@ func (o *Objective) Update(op protocols.ObjectivePayload) (protocols.Objective,
 	}
 }
 
+// ProposedOutcome returns the outcome of V's postfund state and the final outcome in the payload, if it carries one
+func (o *Objective) ProposedOutcome(p protocols.ObjectivePayload) (current, proposed outcome.Exit, ok bool, err error) {
+	if p.Type != SignedStatePayload {
+		return nil, nil, false, nil
+	}
+	ss, err := getSignedStatePayload(p.PayloadData)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return outcome.Exit{o.initialOutcome()}, ss.State().Outcome, true, nil
+}
+
 // ReceiveProposal receives a signed proposal and returns an updated VirtualDefund objective.
 func (o *Objective) ReceiveProposal(sp consensus_channel.SignedProposal) (protocols.ProposalReceiver, error) {
 	var toMyLeftId types.Destination
@@ -615,6 +627,13 @@ func GetVirtualChannelFromObjectiveId(id protocols.ObjectiveId) (types.Destinati
 
 // validateFinalOutcome is a helper function that validates a final outcome from Alice is valid.
 func validateFinalOutcome(vFixed state.FixedPart, initialOutcome outcome.SingleAssetExit, finalOutcome outcome.SingleAssetExit, me types.Address, minAmount *big.Int) error {
+	if err := finalOutcome.Validate(); err != nil {
+		return err
+	}
+	if err := (outcome.Exit{finalOutcome}).ValidateTotals(outcome.Exit{initialOutcome}.TotalAllocated()); err != nil {
+		return err
+	}
+
 	// Check the outcome participants are correct
 	alice, bob := vFixed.Participants[0], vFixed.Participants[len(vFixed.Participants)-1]
 	if initialOutcome.Allocations[0].Destination != types.AddressToDestination(alice) {
@@ -2,7 +2,6 @@
 package virtualfund // import "github.com/statechannels/go-nitro/virtualfund"
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,18 +17,24 @@ import (
 )
 
 const (
-	WaitingForCompletePrefund  protocols.WaitingFor = "WaitingForCompletePrefund"  // Round 1
-	WaitingForCompleteFunding  protocols.WaitingFor = "WaitingForCompleteFunding"  // Round 2
-	WaitingForCompletePostFund protocols.WaitingFor = "WaitingForCompletePostFund" // Round 3
-	WaitingForNothing          protocols.WaitingFor = "WaitingForNothing"          // Finished
+	WaitingForCompletePrefund  = protocols.WaitingForCompletePrefund  // Round 1
+	WaitingForCompleteFunding  = protocols.WaitingForCompleteFunding  // Round 2
+	WaitingForCompletePostFund = protocols.WaitingForCompletePostFund // Round 3
+	WaitingForNothing          = protocols.WaitingForNothing          // Finished
 )
 
 const (
-	SignedStatePayload protocols.PayloadType = "SignedStatePayload"
+	SignedStatePayload = protocols.SignedStatePayload
 )
 
 const ObjectivePrefix = "VirtualFund-"
 
+var (
+	ErrSelfChannel            = errors.New("virtualfund: counterparty is the node's own address")
+	ErrIntermediaryIsEndpoint = errors.New("virtualfund: intermediary is an endpoint of the channel")
+	ErrInsufficientCapacity   = errors.New("virtualfund: insufficient ledger capacity")
+)
+
 // GuaranteeInfo contains the information used to generate the expected guarantees.
 type GuaranteeInfo struct {
 	Left                 types.Destination
@@ -186,16 +191,14 @@ func constructFromState(
 	}
 
 	// Infer MyRole
-	found := false
-	for i, addr := range initialStateOfV.Participants {
-		if bytes.Equal(addr[:], myAddress[:]) {
-			init.MyRole = uint(i)
-			found = true
-			continue
-		}
+	myRole, _, err := channel.RoleFor(myAddress, initialStateOfV.Participants)
+	if err != nil {
+		return Objective{}, fmt.Errorf("not a participant in V: %w", err)
 	}
-	if !found {
-		return Objective{}, errors.New("not a participant in V")
+	init.MyRole = uint(myRole)
+
+	if err := initialStateOfV.Outcome.Validate(); err != nil {
+		return Objective{}, fmt.Errorf("invalid initial outcome: %w", err)
 	}
 
 	// Initialize virtual channel
@@ -300,6 +303,12 @@ func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
 	return &updated, sideEffects
 }
 
+// IsCancellable returns true until the prefund round is complete.
+// After that a guarantee for V may have been added to a ledger channel, which can only be removed by defunding V.
+func (o *Objective) IsCancellable() bool {
+	return !o.V.PreFundComplete()
+}
+
 // OwnsChannel returns the channel that the objective is funding.
 func (o *Objective) OwnsChannel() types.Destination {
 	return o.V.Id
@@ -759,6 +768,20 @@ func NewObjectiveRequest(intermediaries []types.Address,
 	}
 }
 
+// Validate returns an error if the request could never produce an objective which completes:
+// one for a channel with myself, or one routed through either of the channel's endpoints.
+func (r ObjectiveRequest) Validate(myAddress types.Address) error {
+	if r.CounterParty == myAddress {
+		return fmt.Errorf("counterparty %s: %w", r.CounterParty, ErrSelfChannel)
+	}
+	for _, intermediary := range r.Intermediaries {
+		if intermediary == myAddress || intermediary == r.CounterParty {
+			return fmt.Errorf("intermediary %s: %w", intermediary, ErrIntermediaryIsEndpoint)
+		}
+	}
+	return nil
+}
+
 // Id returns the objective id for the request.
 func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
 	idStr := r.channelID(myAddress).String()
@@ -142,3 +142,27 @@ func TestApproveReject(t *testing.T) {
 		t.Errorf("Expected to send two messages")
 	}
 }
+
+func TestIsCancellable(t *testing.T) {
+	td := newTestData()
+	lookup := td.leaderLedgers
+
+	o, err := constructFromState(
+		false,
+		td.vPreFund,
+		alice.Address(),
+		lookup[alice.Destination()].left,
+		lookup[alice.Destination()].right,
+	)
+	testhelpers.Ok(t, err)
+	if !o.IsCancellable() {
+		t.Error("Expected the objective to be cancellable before the prefund round is complete")
+	}
+
+	o.V = cloneAndSignSetupStateByPeers(*o.V, alice.Role, true)
+	sig, _ := o.V.PreFundState().Sign(alice.PrivateKey)
+	o.V.AddStateWithSignature(o.V.PreFundState(), sig)
+	if o.IsCancellable() {
+		t.Error("Expected the objective not to be cancellable once the prefund round is complete")
+	}
+}
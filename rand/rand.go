@@ -4,18 +4,70 @@
 package rand
 
 import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"hash/fnv"
 	"math/rand"
+	"sync"
 	"time"
 )
 
+// Generator produces random numbers, such as channel nonces and request ids
+type Generator interface {
+	Uint64() uint64
+}
+
+// Secure is a Generator backed by crypto/rand, so that the numbers it produces cannot be predicted
+var Secure Generator = secureGenerator{}
+
+type secureGenerator struct{}
+
+func (secureGenerator) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// seededGenerator is a Generator which produces the same sequence of numbers for the same seed.
+// It is safe for concurrent use.
+type seededGenerator struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// NewSeeded returns a Generator which produces the same sequence of numbers for the same seed, so that test runs can be replayed.
+// It must not be used to generate production nonces.
+func NewSeeded(seed int64) Generator {
+	return &seededGenerator{r: rand.New(rand.NewSource(seed))}
+}
+
+func (g *seededGenerator) Uint64() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.r.Uint64()
+}
+
+// DeriveSeed derives a seed for the generator labelled label from the seed of a whole run.
+// Giving each concurrent user of a run, such as each node, its own generator keeps the run reproducible,
+// since the order in which concurrent users draw from a shared generator varies between runs.
+func DeriveSeed(seed int64, label string) int64 {
+	h := fnv.New64a()
+	_ = binary.Write(h, binary.BigEndian, seed)
+	_, _ = h.Write([]byte(label))
+	return int64(h.Sum64())
+}
+
 // getRandGenerator seeds a random number generator based on current time
 func getRandGenerator() *rand.Rand {
 	source := rand.NewSource(time.Now().UnixNano())
 	return rand.New(source)
 }
 
+// Uint64 returns a random number from the Secure generator
 func Uint64() uint64 {
-	return getRandGenerator().Uint64()
+	return Secure.Uint64()
 }
 
 func Int63n(i int64) int64 {
@@ -0,0 +1,27 @@
+package rand
+
+import "testing"
+
+func TestSeededGeneratorsAreReproducible(t *testing.T) {
+	a, b := NewSeeded(42), NewSeeded(42)
+	for i := 0; i < 5; i++ {
+		if x, y := a.Uint64(), b.Uint64(); x != y {
+			t.Fatalf("expected generators with the same seed to agree, but draw %d was %d and %d", i, x, y)
+		}
+	}
+	if NewSeeded(1).Uint64() == NewSeeded(2).Uint64() {
+		t.Error("expected generators with different seeds to differ")
+	}
+}
+
+func TestDerivedSeedsDependOnSeedAndLabel(t *testing.T) {
+	if DeriveSeed(42, "alice") != DeriveSeed(42, "alice") {
+		t.Error("expected the same seed and label to derive the same seed")
+	}
+	if DeriveSeed(42, "alice") == DeriveSeed(42, "bob") {
+		t.Error("expected different labels to derive different seeds")
+	}
+	if DeriveSeed(1, "alice") == DeriveSeed(2, "alice") {
+		t.Error("expected different seeds to derive different seeds")
+	}
+}
@@ -3,35 +3,82 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math/big"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/internal/logging"
 	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/appupdate"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/rand"
 	"github.com/statechannels/go-nitro/rpc/serde"
 	"github.com/statechannels/go-nitro/rpc/transport"
 	"github.com/statechannels/go-nitro/rpc/transport/http"
+	"github.com/statechannels/go-nitro/rpc/transport/ws"
 	"github.com/statechannels/go-nitro/types"
 )
 
-// RpcClientApi provides various functions to make RPC API calls to a nitro RPC server
+// ErrClientClosed is returned by requests made after the RpcClient is closed, or which were still in flight when it was closed
+var ErrClientClosed = errors.New("client closed")
+
+// ErrConnectionLost is returned by requests whose connection to the server was lost before they were answered, and which were not replayed.
+// The request may or may not have reached the node, so the caller should check before retrying a request which is not replay safe.
+var ErrConnectionLost = errors.New("connection lost, retry the request")
+
+// ErrUnexpectedResponseId is returned when the server answers a request with a response to a different request
+var ErrUnexpectedResponseId = errors.New("response id does not match request id")
+
+// IdGenerator generates the ids of the client's requests, and the nonces of the channels and objectives it creates.
+// Deployments which coordinate nonces across processes, and tests which need reproducible ids, may supply their own.
+type IdGenerator interface {
+	NextRequestId() uint64
+	NextChannelNonce() uint64
+}
+
+// RandomIds returns an IdGenerator which draws both request ids and channel nonces from rng
+func RandomIds(rng rand.Generator) IdGenerator {
+	return randomIds{rng}
+}
+
+type randomIds struct {
+	rng rand.Generator
+}
+
+func (r randomIds) NextRequestId() uint64 { return r.rng.Uint64() }
+
+func (r randomIds) NextChannelNonce() uint64 { return r.rng.Uint64() }
+
+// RpcClientApi provides various functions to make RPC API calls to a nitro RPC server.
+// Implementations are safe for concurrent use by multiple goroutines.
 type RpcClientApi interface {
 	// Address returns the address of the nitro node
 	Address() (common.Address, error)
 
+	// ChainId returns the id of the chain the nitro node is connected to
+	ChainId() *big.Int
+
+	// Connected reports whether the client is open and, if its transport holds a connection to the server, whether that connection is established
+	Connected() bool
+
+	// Health reports whether the nitro node is connected to the chain and its store is open
+	Health() (query.HealthInfo, error)
+
 	// CreateVoucher creates a voucher for the given channelId and amount and returns it.
 	// It is the responsibility of the caller to send the voucher to the payee.
 	CreateVoucher(chId types.Destination, amount uint64) (payments.Voucher, error)
@@ -44,31 +91,61 @@ type RpcClientApi interface {
 	// GetPaymentChannel returns the payment channel information for the given channelId
 	GetPaymentChannel(chId types.Destination) (query.PaymentChannelInfo, error)
 
+	// GetVoucherBalance returns the largest voucher amount on the given payment channel and its increase since the caller last queried it
+	GetVoucherBalance(chId types.Destination, caller string) (payments.ReceiveVoucherSummary, error)
+
 	// CreatePaymentChannel creates a new virtual payment channel with the specified intermediaries, counterparty, ChallengeDuration, and outcome
 	CreatePaymentChannel(intermediaries []types.Address, counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (virtualfund.ObjectiveResponse, error)
 
+	// SimulateCreatePaymentChannel describes the payment channel which CreatePaymentChannel would create with the same arguments, without creating it
+	SimulateCreatePaymentChannel(intermediaries []types.Address, counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (query.SimulatedObjectiveInfo, error)
+
 	// ClosePaymentChannel attempts to close the payment channel with the specified channelId
 	ClosePaymentChannel(id types.Destination) (protocols.ObjectiveId, error)
 
 	// GetLedgerChannel returns the ledger channel information for the given channelId
 	GetLedgerChannel(id types.Destination) (query.LedgerChannelInfo, error)
 
+	// GetObjectiveByChannelId returns the objective which is currently operating on the given channel, or query.NoObjective if there is none
+	GetObjectiveByChannelId(chId types.Destination) (query.ObjectiveStatusInfo, error)
+
 	// GetAllLedgerChannels returns information about all ledger channels
 	GetAllLedgerChannels() ([]query.LedgerChannelInfo, error)
 
 	// GetPaymentChannelsByLedger returns all active payment channels for a given ledger channel
 	GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error)
 
+	// TotalAvailableLiquidity returns how much of the asset is free to fund new payment channels, summed across all ledger channels
+	TotalAvailableLiquidity(asset types.Address) (*big.Int, error)
+
+	// GetLatestSignedState returns the latest state of the given ledger or payment channel which every participant has signed.
+	// It is the state the node would challenge with, so it can be kept as evidence with which to defend the channel without the node.
+	GetLatestSignedState(channelId types.Destination) (state.SignedState, error)
+
 	// CreateLedgerChannel creates a new ledger channel with the specified counterparty, ChallengeDuration, and outcome
 	CreateLedgerChannel(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error)
 
+	// SimulateCreateLedgerChannel describes the ledger channel which CreateLedgerChannel would create with the same arguments, without creating it
+	SimulateCreateLedgerChannel(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (query.SimulatedObjectiveInfo, error)
+
 	// CloseLedgerChannel attempts to close the ledger channel with the specified channelId
 	CloseLedgerChannel(id types.Destination) (protocols.ObjectiveId, error)
 
+	// CloseAllChannels closes every open payment channel the node is an endpoint of and then every open ledger channel,
+	// and returns the ids of the objectives closing them. Failures to close individual channels are returned together once every channel has been tried.
+	CloseAllChannels(ctx context.Context) ([]protocols.ObjectiveId, error)
+
+	// TopUpLedgerChannel deposits the specified amount into the ledger channel with the specified channelId, keeping it open
+	TopUpLedgerChannel(id types.Destination, amount *big.Int) (protocols.ObjectiveId, error)
+
+	// UpdateAppState moves the application channel of the given state to that state, once every participant has signed it
+	UpdateAppState(s state.State) (protocols.ObjectiveId, error)
+
 	// Pay uses the specified channel to pay the specified amount
 	Pay(id types.Destination, amount uint64) (serde.PaymentRequest, error)
 
-	// Close shuts down the RpcClient and closes the underlying transport
+	// Close shuts down the RpcClient and closes the underlying transport.
+	// Requests which are in flight return ErrClientClosed. Closing a client which is already closed has no effect.
 	Close() error
 
 	// ObjectiveCompleteChan returns a channel that receives an empty struct when the objective with the given id is completed
@@ -87,11 +164,17 @@ type rpcClient struct {
 	completedObjectives   *safesync.Map[chan struct{}]
 	ledgerChannelUpdates  *safesync.Map[chan query.LedgerChannelInfo]
 	paymentChannelUpdates *safesync.Map[chan query.PaymentChannelInfo]
+	ctx                   context.Context
 	cancel                context.CancelFunc
 	routineTracker        *sync.WaitGroup
+	closeMu               sync.RWMutex // guards closed, so that no request is tracked once Close has started waiting
+	closed                bool
 	nodeAddress           common.Address
+	chainId               *big.Int
 	logger                *slog.Logger
 	authToken             string
+	ids                   IdGenerator
+	replayTimeout         time.Duration // how long to wait for the transport to reconnect before replaying a request, or 0 to never replay
 }
 
 // response includes a payload or an error.
@@ -102,9 +185,42 @@ type response[T serde.ResponsePayload] struct {
 
 // NewRpcClient creates a new RpcClient
 func NewRpcClient(trans transport.Requester) (RpcClientApi, error) {
+	return NewRpcClientWithIdGenerator(trans, RandomIds(rand.Secure))
+}
+
+// NewRpcClientWithRandomness is like NewRpcClient, but generates nonces and request ids with rng rather than crypto/rand.
+// Tests may supply a seeded generator so that a failing run can be replayed.
+func NewRpcClientWithRandomness(trans transport.Requester, rng rand.Generator) (RpcClientApi, error) {
+	return NewRpcClientWithIdGenerator(trans, RandomIds(rng))
+}
+
+// NewRpcClientWithIdGenerator is like NewRpcClient, but takes its request ids and channel nonces from ids.
+func NewRpcClientWithIdGenerator(trans transport.Requester, ids IdGenerator) (RpcClientApi, error) {
+	return newRpcClient(trans, ids, nil, 0)
+}
+
+// NewRpcClientForChain is like NewRpcClient, but only connects to a node on the chain with the given id.
+// If the node is on another chain, the server rejects the client's first request with serde.ChainIdMismatchError.
+func NewRpcClientForChain(trans transport.Requester, chainId *big.Int) (RpcClientApi, error) {
+	return newRpcClient(trans, RandomIds(rand.Secure), chainId, 0)
+}
+
+// NewRpcClientWithReplay is like NewRpcClient, but replays replay safe requests which were in flight when the connection to the server was lost,
+// once the transport reconnects within reconnectTimeout. Only queries, and requests for objectives whose ids are determined by the request, are replay safe.
+// Other requests, such as Pay, fail with ErrConnectionLost however long the transport takes to reconnect.
+func NewRpcClientWithReplay(trans transport.Requester, reconnectTimeout time.Duration) (RpcClientApi, error) {
+	return newRpcClient(trans, RandomIds(rand.Secure), nil, reconnectTimeout)
+}
+
+// newRpcClient creates a client which, if chainId is not nil, expects the node to be on that chain.
+// Otherwise, the client adopts the node's chain. Once it is known, the chain id is sent with every request, so that the server
+// rejects requests rather than computing objective ids for another chain.
+// If replayTimeout is positive, replay safe requests are replayed when the transport reconnects within it.
+func newRpcClient(trans transport.Requester, ids IdGenerator, chainId *big.Int, replayTimeout time.Duration) (RpcClientApi, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	c := &rpcClient{
 		transport:             trans,
+		ctx:                   ctx,
 		completedObjectives:   &safesync.Map[chan struct{}]{},
 		ledgerChannelUpdates:  &safesync.Map[chan query.LedgerChannelInfo]{},
 		paymentChannelUpdates: &safesync.Map[chan query.PaymentChannelInfo]{},
@@ -112,6 +228,11 @@ func NewRpcClient(trans transport.Requester) (RpcClientApi, error) {
 		routineTracker:        &sync.WaitGroup{},
 		nodeAddress:           common.Address{},
 		logger:                slog.Default(),
+		ids:                   ids,
+		replayTimeout:         replayTimeout,
+	}
+	if chainId != nil {
+		c.chainId = new(big.Int).Set(chainId)
 	}
 
 	// Retrieve the address and set it on the rpcClient
@@ -121,6 +242,15 @@ func NewRpcClient(trans transport.Requester) (RpcClientApi, error) {
 	}
 	c.nodeAddress = res
 
+	nodeChainId, err := WaitForRequestNoAuth[serde.NoPayloadRequest, string](c, serde.GetChainIdMethod, serde.NoPayloadRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	if c.chainId, ok = new(big.Int).SetString(nodeChainId, 10); !ok {
+		return nil, fmt.Errorf("could not parse chain id %q", nodeChainId)
+	}
+
 	// Update the logger so we output the address
 	c.logger = logging.LoggerWithAddress(c.logger, c.nodeAddress)
 
@@ -129,7 +259,7 @@ func NewRpcClient(trans transport.Requester) (RpcClientApi, error) {
 		return nil, err
 	}
 	c.routineTracker.Add(1)
-	go c.subscribeToNotifications(ctx, notificationChan)
+	go c.subscribeToNotifications(notificationChan)
 
 	authToken, err := WaitForRequestNoAuth[serde.NoPayloadRequest, string](c, serde.GetAuthTokenMethod, serde.NoPayloadRequest{})
 	c.authToken = authToken
@@ -146,11 +276,43 @@ func NewHttpRpcClient(rpcServerUrl string) (RpcClientApi, error) {
 	return NewRpcClient(transport)
 }
 
+// NewWsRpcClient creates a new rpcClient using a websocket transport, which carries both requests and notifications
+func NewWsRpcClient(rpcServerUrl string) (RpcClientApi, error) {
+	transport, err := ws.NewWsTransportAsClient(rpcServerUrl, 10*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	return NewRpcClient(transport)
+}
+
 // Address returns the address of the the nitro node
 func (rc *rpcClient) Address() (common.Address, error) {
 	return rc.nodeAddress, nil
 }
 
+// ChainId returns the id of the chain the nitro node is connected to
+func (rc *rpcClient) ChainId() *big.Int {
+	return new(big.Int).Set(rc.chainId)
+}
+
+// Connected reports whether the client is open and, if its transport holds a connection to the server, whether that connection is established
+func (rc *rpcClient) Connected() bool {
+	rc.closeMu.RLock()
+	defer rc.closeMu.RUnlock()
+	if rc.closed {
+		return false
+	}
+	if reporter, ok := rc.transport.(transport.ConnectionReporter); ok {
+		return reporter.Connected()
+	}
+	return true
+}
+
+// Health reports whether the nitro node is connected to the chain and its store is open
+func (rc *rpcClient) Health() (query.HealthInfo, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, query.HealthInfo](rc, serde.HealthMethod, serde.NoPayloadRequest{})
+}
+
 // CreateVoucher creates a voucher for the given channelId and amount and returns it.
 // It is the responsibility of the caller to send the voucher to the payee.
 func (rc *rpcClient) CreateVoucher(chId types.Destination, amount uint64) (payments.Voucher, error) {
@@ -171,6 +333,13 @@ func (rc *rpcClient) GetPaymentChannel(chId types.Destination) (query.PaymentCha
 	return waitForAuthorizedRequest[serde.GetPaymentChannelRequest, query.PaymentChannelInfo](rc, serde.GetPaymentChannelRequestMethod, req)
 }
 
+// GetVoucherBalance returns the largest voucher amount on the given payment channel and its increase since the caller last queried it
+func (rc *rpcClient) GetVoucherBalance(chId types.Destination, caller string) (payments.ReceiveVoucherSummary, error) {
+	req := serde.GetVoucherBalanceRequest{Id: chId, Caller: caller}
+
+	return waitForAuthorizedRequest[serde.GetVoucherBalanceRequest, payments.ReceiveVoucherSummary](rc, serde.GetVoucherBalanceRequestMethod, req)
+}
+
 // CreatePaymentChannel creates a new virtual payment channel
 func (rc *rpcClient) CreatePaymentChannel(intermediaries []types.Address, counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (virtualfund.ObjectiveResponse, error) {
 	objReq := virtualfund.NewObjectiveRequest(
@@ -178,12 +347,25 @@ func (rc *rpcClient) CreatePaymentChannel(intermediaries []types.Address, counte
 		counterparty,
 		100,
 		outcome,
-		rand.Uint64(),
+		rc.ids.NextChannelNonce(),
 		common.Address{})
 
 	return waitForAuthorizedRequest[virtualfund.ObjectiveRequest, virtualfund.ObjectiveResponse](rc, serde.CreatePaymentChannelRequestMethod, objReq)
 }
 
+// SimulateCreatePaymentChannel describes the payment channel which CreatePaymentChannel would create, without creating it
+func (rc *rpcClient) SimulateCreatePaymentChannel(intermediaries []types.Address, counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (query.SimulatedObjectiveInfo, error) {
+	objReq := virtualfund.NewObjectiveRequest(
+		intermediaries,
+		counterparty,
+		100,
+		outcome,
+		rc.ids.NextChannelNonce(),
+		common.Address{})
+
+	return waitForAuthorizedRequest[virtualfund.ObjectiveRequest, query.SimulatedObjectiveInfo](rc, serde.SimulateCreatePaymentChannelMethod, objReq)
+}
+
 // ClosePaymentChannel attempts to close the payment channel with supplied id
 func (rc *rpcClient) ClosePaymentChannel(id types.Destination) (protocols.ObjectiveId, error) {
 	objReq := virtualdefund.NewObjectiveRequest(
@@ -198,6 +380,13 @@ func (rc *rpcClient) GetLedgerChannel(id types.Destination) (query.LedgerChannel
 	return waitForAuthorizedRequest[serde.GetLedgerChannelRequest, query.LedgerChannelInfo](rc, serde.GetLedgerChannelRequestMethod, req)
 }
 
+// GetObjectiveByChannelId returns the objective which is currently operating on the given channel, or query.NoObjective if there is none
+func (rc *rpcClient) GetObjectiveByChannelId(chId types.Destination) (query.ObjectiveStatusInfo, error) {
+	req := serde.GetObjectiveByChannelIdRequest{ChannelId: chId}
+
+	return waitForAuthorizedRequest[serde.GetObjectiveByChannelIdRequest, query.ObjectiveStatusInfo](rc, serde.GetObjectiveByChannelIdMethod, req)
+}
+
 // GetAllLedgerChannels returns all ledger channels
 func (rc *rpcClient) GetAllLedgerChannels() ([]query.LedgerChannelInfo, error) {
 	return waitForAuthorizedRequest[serde.NoPayloadRequest, []query.LedgerChannelInfo](rc, serde.GetAllLedgerChannelsMethod, struct{}{})
@@ -208,18 +397,44 @@ func (rc *rpcClient) GetPaymentChannelsByLedger(ledgerId types.Destination) ([]q
 	return waitForAuthorizedRequest[serde.GetPaymentChannelsByLedgerRequest, []query.PaymentChannelInfo](rc, serde.GetPaymentChannelsByLedgerMethod, serde.GetPaymentChannelsByLedgerRequest{LedgerId: ledgerId})
 }
 
+// TotalAvailableLiquidity returns how much of the asset is free to fund new payment channels, summed across all ledger channels
+func (rc *rpcClient) TotalAvailableLiquidity(asset types.Address) (*big.Int, error) {
+	liquidity, err := waitForAuthorizedRequest[serde.GetTotalAvailableLiquidityRequest, *hexutil.Big](rc, serde.GetTotalAvailableLiquidityMethod, serde.GetTotalAvailableLiquidityRequest{Asset: asset})
+	if err != nil {
+		return nil, err
+	}
+	return liquidity.ToInt(), nil
+}
+
+// GetLatestSignedState returns the latest state of the given channel which every participant has signed
+func (rc *rpcClient) GetLatestSignedState(channelId types.Destination) (state.SignedState, error) {
+	return waitForAuthorizedRequest[serde.GetLatestSignedStateRequest, state.SignedState](rc, serde.GetLatestSignedStateMethod, serde.GetLatestSignedStateRequest{ChannelId: channelId})
+}
+
 // CreateLedger creates a new ledger channel
 func (rc *rpcClient) CreateLedgerChannel(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error) {
 	objReq := directfund.NewObjectiveRequest(
 		counterparty,
 		100,
 		outcome,
-		rand.Uint64(),
+		rc.ids.NextChannelNonce(),
 		common.Address{})
 
 	return waitForAuthorizedRequest[directfund.ObjectiveRequest, directfund.ObjectiveResponse](rc, serde.CreateLedgerChannelRequestMethod, objReq)
 }
 
+// SimulateCreateLedgerChannel describes the ledger channel which CreateLedgerChannel would create, without creating it
+func (rc *rpcClient) SimulateCreateLedgerChannel(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (query.SimulatedObjectiveInfo, error) {
+	objReq := directfund.NewObjectiveRequest(
+		counterparty,
+		100,
+		outcome,
+		rc.ids.NextChannelNonce(),
+		common.Address{})
+
+	return waitForAuthorizedRequest[directfund.ObjectiveRequest, query.SimulatedObjectiveInfo](rc, serde.SimulateCreateLedgerChannelMethod, objReq)
+}
+
 // CloseLedger closes a ledger channel
 func (rc *rpcClient) CloseLedgerChannel(id types.Destination) (protocols.ObjectiveId, error) {
 	objReq := directdefund.NewObjectiveRequest(id)
@@ -227,23 +442,116 @@ func (rc *rpcClient) CloseLedgerChannel(id types.Destination) (protocols.Objecti
 	return waitForAuthorizedRequest[directdefund.ObjectiveRequest, protocols.ObjectiveId](rc, serde.CloseLedgerChannelRequestMethod, objReq)
 }
 
+// CloseAllChannels closes the node's open payment channels, waits for them to close and then closes its open ledger channels, which
+// can only be defunded once they fund no payment channels. The ledger channel objectives may still be running when it returns.
+// Payment channels the node is an intermediary of are left for their endpoints to close, so the ledger channels funding them fail to close.
+func (rc *rpcClient) CloseAllChannels(ctx context.Context) ([]protocols.ObjectiveId, error) {
+	ledgers, err := rc.GetAllLedgerChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []protocols.ObjectiveId{}
+	errs := []error{}
+	paymentsClosed := []<-chan struct{}{}
+	for _, ledger := range ledgers {
+		if ledger.Status != query.Open {
+			continue
+		}
+		payments, err := rc.GetPaymentChannelsByLedger(ledger.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ledger channel %s: %w", ledger.ID, err))
+			continue
+		}
+		for _, payment := range payments {
+			isEndpoint := payment.Balance.Payer == rc.nodeAddress || payment.Balance.Payee == rc.nodeAddress
+			if payment.Status != query.Open || !isEndpoint {
+				continue
+			}
+			id, err := rc.ClosePaymentChannel(payment.ID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("payment channel %s: %w", payment.ID, err))
+				continue
+			}
+			ids = append(ids, id)
+			paymentsClosed = append(paymentsClosed, rc.ObjectiveCompleteChan(id))
+		}
+	}
+
+	for _, closed := range paymentsClosed {
+		select {
+		case <-closed:
+		case <-ctx.Done():
+			return ids, errors.Join(append(errs, ctx.Err())...)
+		}
+	}
+
+	for _, ledger := range ledgers {
+		if ledger.Status != query.Open {
+			continue
+		}
+		id, err := rc.CloseLedgerChannel(ledger.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ledger channel %s: %w", ledger.ID, err))
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, errors.Join(errs...)
+}
+
+// TopUpLedgerChannel deposits additional funds into a ledger channel
+func (rc *rpcClient) TopUpLedgerChannel(id types.Destination, amount *big.Int) (protocols.ObjectiveId, error) {
+	objReq := ledgertopup.NewObjectiveRequest(id, amount, rc.ids.NextChannelNonce())
+
+	return waitForAuthorizedRequest[ledgertopup.ObjectiveRequest, protocols.ObjectiveId](rc, serde.TopUpLedgerChannelRequestMethod, objReq)
+}
+
+// UpdateAppState moves an application channel to a new state, without changing its funding
+func (rc *rpcClient) UpdateAppState(s state.State) (protocols.ObjectiveId, error) {
+	objReq := appupdate.NewObjectiveRequest(s)
+
+	return waitForAuthorizedRequest[appupdate.ObjectiveRequest, protocols.ObjectiveId](rc, serde.UpdateAppStateRequestMethod, objReq)
+}
+
 // Pay uses the specified channel to pay the specified amount
 func (rc *rpcClient) Pay(id types.Destination, amount uint64) (serde.PaymentRequest, error) {
 	pReq := serde.PaymentRequest{Amount: amount, Channel: id}
 	return waitForAuthorizedRequest[serde.PaymentRequest, serde.PaymentRequest](rc, serde.PayRequestMethod, pReq)
 }
 
+// Close shuts down the RpcClient and closes the underlying transport.
+// Requests which are in flight return ErrClientClosed. Closing a client which is already closed has no effect.
 func (rc *rpcClient) Close() error {
+	rc.closeMu.Lock()
+	if rc.closed {
+		rc.closeMu.Unlock()
+		return nil
+	}
+	rc.closed = true
+	rc.closeMu.Unlock()
+
 	rc.cancel()
 	rc.routineTracker.Wait()
 	return rc.transport.Close()
 }
 
-func (rc *rpcClient) subscribeToNotifications(ctx context.Context, notificationChan <-chan []byte) {
+// trackRequest registers a request with the routine tracker, unless the client is closed
+func (rc *rpcClient) trackRequest() error {
+	rc.closeMu.RLock()
+	defer rc.closeMu.RUnlock()
+	if rc.closed {
+		return ErrClientClosed
+	}
+	rc.routineTracker.Add(1)
+	return nil
+}
+
+func (rc *rpcClient) subscribeToNotifications(notificationChan <-chan []byte) {
 	rc.logger.Debug("Subscribed to notifications")
 	for {
 		select {
-		case <-ctx.Done():
+		case <-rc.ctx.Done():
 			rc.routineTracker.Done()
 			return
 		case data := <-notificationChan:
@@ -315,16 +623,100 @@ func waitForAuthorizedRequest[T serde.RequestPayload, U serde.ResponsePayload](r
 	return waitForRequest[T, U](rc, method, requestData, rc.authToken)
 }
 
+// waitForRequest sends a request and waits for its response, or for the client to be closed.
+// Each request carries its own id and waits on its own response, so requests may be made concurrently.
 func waitForRequest[T serde.RequestPayload, U serde.ResponsePayload](rc *rpcClient, method serde.RequestMethod, requestData T, authToken string) (U, error) {
-	rc.routineTracker.Add(1)
+	var empty U
+	if err := rc.trackRequest(); err != nil {
+		return empty, err
+	}
 	defer rc.routineTracker.Done()
 
-	res, err := sendRequest[T, U](rc.transport, method, requestData, rc.authToken, rc.logger, rc.routineTracker)
-	if err != nil {
-		panic(err)
+	type result struct {
+		res response[U]
+		err error
 	}
+	// The transport's Request blocks, so it runs in its own goroutine which returns once the transport is closed
+	results := make(chan result, 1)
+	requestId := rc.ids.NextRequestId()
+	go func() {
+		var lostAt time.Time
+		for {
+			res, err := sendRequest[T, U](rc.transport, method, requestId, requestData, authToken, rc.chainId, rc.logger)
+			if !errors.Is(err, transport.ErrConnectionLost) {
+				results <- result{res, err}
+				return
+			}
+			if lostAt.IsZero() {
+				lostAt = time.Now()
+			}
+			if rc.replayTimeout <= 0 || !isReplaySafe(method) || !rc.awaitReconnection(lostAt.Add(rc.replayTimeout)) {
+				results <- result{err: fmt.Errorf("%w: %s request %d: %w", ErrConnectionLost, method, requestId, err)}
+				return
+			}
+			// The replay keeps the request's id, so that the server and the transport see the same request
+			rc.logger.Info("replaying request after reconnecting", "method", string(method), "id", requestId)
+		}
+	}()
+
+	select {
+	case <-rc.ctx.Done():
+		return empty, ErrClientClosed
+	case r := <-results:
+		if r.err != nil {
+			return empty, r.err
+		}
+		return r.res.Payload, r.res.Error
+	}
+}
 
-	return res.Payload, res.Error
+// awaitReconnection blocks until the transport reports that it is connected. It returns false if the deadline passes,
+// the client is closed, or the transport cannot report its connection, first.
+func (rc *rpcClient) awaitReconnection(deadline time.Time) bool {
+	reporter, ok := rc.transport.(transport.ConnectionReporter)
+	if !ok {
+		return false
+	}
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for !reporter.Connected() {
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		select {
+		case <-rc.ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// isReplaySafe returns true if a request with the given method may be sent again when it is not known whether the node received it.
+// Queries are replay safe, as are requests for objectives whose ids are determined by the request, as a replayed request cannot start a second objective.
+// Requests which create channels or top them up are not, as the node chooses their nonces. Nor are payments, nor voucher requests whose responses depend on earlier requests.
+func isReplaySafe(method serde.RequestMethod) bool {
+	switch method {
+	case serde.GetAuthTokenMethod,
+		serde.GetAddressMethod,
+		serde.GetChainIdMethod,
+		serde.VersionMethod,
+		serde.HealthMethod,
+		serde.GetPaymentChannelRequestMethod,
+		serde.GetLedgerChannelRequestMethod,
+		serde.GetPaymentChannelsByLedgerMethod,
+		serde.GetAllLedgerChannelsMethod,
+		serde.GetObjectiveByChannelIdMethod,
+		serde.SimulateCreateLedgerChannelMethod,
+		serde.SimulateCreatePaymentChannelMethod,
+		serde.GetTotalAvailableLiquidityMethod,
+		serde.GetLatestSignedStateMethod,
+		serde.CloseLedgerChannelRequestMethod,
+		serde.ClosePaymentChannelRequestMethod,
+		serde.UpdateAppStateRequestMethod:
+		return true
+	}
+	return false
 }
 
 // sendRequest uses the supplied transport and payload to send a JSONRPC request.
@@ -332,11 +724,13 @@ func waitForRequest[T serde.RequestPayload, U serde.ResponsePayload](rc *rpcClie
 //     [1] the request fails to send
 //     [2] the response cannot be parsed
 //   - Otherwise, returns the JSONRPC server's response
-func sendRequest[T serde.RequestPayload, U serde.ResponsePayload](trans transport.Requester, method serde.RequestMethod, reqPayload T,
-	authToken string, logger *slog.Logger, wg *sync.WaitGroup,
+func sendRequest[T serde.RequestPayload, U serde.ResponsePayload](trans transport.Requester, method serde.RequestMethod, requestId uint64, reqPayload T,
+	authToken string, chainId *big.Int, logger *slog.Logger,
 ) (response[U], error) {
-	requestId := rand.Uint64()
 	message := serde.NewJsonRpcSpecificRequest(requestId, method, reqPayload, authToken)
+	if chainId != nil {
+		message.Params.ChainId = chainId.String()
+	}
 	data, err := json.Marshal(message)
 	if err != nil {
 		return response[U]{}, err
@@ -356,6 +750,8 @@ func sendRequest[T serde.RequestPayload, U serde.ResponsePayload](trans transpor
 		return response[U]{}, err
 	} else if jsonResponse.Error != (serde.JsonRpcError{}) {
 		return response[U]{Error: jsonResponse.Error}, nil
+	} else if jsonResponse.Id != requestId {
+		return response[U]{}, fmt.Errorf("%w: sent %d, received %d", ErrUnexpectedResponseId, requestId, jsonResponse.Id)
 	}
 
 	// Now convert response.Result into the specific type for this request, and return that
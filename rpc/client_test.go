@@ -0,0 +1,364 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// mockRequester answers requests for the node's address, chain id, auth token and ledger channels.
+// If blocking, ledger channel requests are not answered until the requester is closed.
+type mockRequester struct {
+	blocking  bool
+	requested chan struct{} // receives a value whenever a ledger channel request is made
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newMockRequester(blocking bool) *mockRequester {
+	return &mockRequester{blocking: blocking, requested: make(chan struct{}, 100), closed: make(chan struct{})}
+}
+
+func (m *mockRequester) Close() error {
+	m.closeOnce.Do(func() { close(m.closed) })
+	return nil
+}
+
+func (m *mockRequester) Subscribe() (<-chan []byte, error) {
+	return make(chan []byte), nil
+}
+
+func (m *mockRequester) Request(data []byte) ([]byte, error) {
+	var req serde.JsonRpcSpecificRequest[serde.GetLedgerChannelRequest]
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	switch serde.RequestMethod(req.Method) {
+	case serde.GetAddressMethod:
+		return json.Marshal(serde.NewJsonRpcResponse(req.Id, common.HexToAddress("0x1")))
+	case serde.GetChainIdMethod:
+		return json.Marshal(serde.NewJsonRpcResponse(req.Id, "1337"))
+	case serde.GetAuthTokenMethod:
+		return json.Marshal(serde.NewJsonRpcResponse(req.Id, "token"))
+	case serde.GetLedgerChannelRequestMethod:
+		m.requested <- struct{}{}
+		if m.blocking {
+			<-m.closed
+			return nil, errors.New("connection closed")
+		}
+		return json.Marshal(serde.NewJsonRpcResponse(req.Id, query.LedgerChannelInfo{ID: req.Params.Payload.Id}))
+	}
+	return nil, errors.New("unexpected method " + req.Method)
+}
+
+func TestClientAccessors(t *testing.T) {
+	c, err := NewRpcClient(newMockRequester(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainId := c.ChainId()
+	if chainId.Cmp(big.NewInt(1337)) != 0 {
+		t.Errorf("expected chain id 1337, got %s", chainId)
+	}
+	chainId.SetInt64(1)
+	if c.ChainId().Cmp(big.NewInt(1337)) != 0 {
+		t.Errorf("expected the client's chain id not to change when the returned value is modified")
+	}
+
+	if !c.Connected() {
+		t.Errorf("expected an open client to be connected")
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Connected() {
+		t.Errorf("expected a closed client not to be connected")
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	c, err := NewRpcClient(newMockRequester(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(id types.Destination) {
+			defer wg.Done()
+			info, err := c.GetLedgerChannel(id)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if info.ID != id {
+				t.Errorf("expected the response for channel %s, got %s", id, info.ID)
+			}
+		}(types.Destination{byte(i)})
+	}
+	wg.Wait()
+}
+
+func TestCloseUnblocksRequests(t *testing.T) {
+	requester := newMockRequester(true)
+	c, err := NewRpcClient(requester)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error)
+	go func() {
+		_, err := c.GetLedgerChannel(types.Destination{1})
+		errs <- err
+	}()
+	<-requester.requested
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected the outstanding request to fail with %v, got %v", ErrClientClosed, err)
+	}
+
+	if _, err := c.GetLedgerChannel(types.Destination{1}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected a request after closing to fail with %v, got %v", ErrClientClosed, err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("expected closing twice to have no effect, got %v", err)
+	}
+}
+
+// sequentialIds is an IdGenerator which counts request ids up from 100, and channel nonces up from 1
+type sequentialIds struct {
+	mu               sync.Mutex
+	requestId, nonce uint64
+}
+
+func (s *sequentialIds) NextRequestId() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestId++
+	return 100 + s.requestId
+}
+
+func (s *sequentialIds) NextChannelNonce() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nonce++
+	return s.nonce
+}
+
+// recordingRequester records the ids of the requests it answers, and the nonces of the ledger channels it is asked to simulate.
+// It answers requests with responseIdOffset added to their ids.
+type recordingRequester struct {
+	*mockRequester
+	responseIdOffset uint64
+	requestIds       []uint64
+	nonces           []uint64
+}
+
+func (r *recordingRequester) Request(data []byte) ([]byte, error) {
+	var req serde.JsonRpcSpecificRequest[directfund.ObjectiveRequest]
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	r.requestIds = append(r.requestIds, req.Id)
+	switch serde.RequestMethod(req.Method) {
+	case serde.GetAddressMethod, serde.GetChainIdMethod, serde.GetAuthTokenMethod:
+		return r.mockRequester.Request(data)
+	case serde.SimulateCreateLedgerChannelMethod:
+		r.nonces = append(r.nonces, req.Params.Payload.Nonce)
+		return json.Marshal(serde.NewJsonRpcResponse(req.Id+r.responseIdOffset, query.SimulatedObjectiveInfo{}))
+	}
+	return nil, errors.New("unexpected method " + req.Method)
+}
+
+func TestClientUsesInjectedIds(t *testing.T) {
+	requester := &recordingRequester{mockRequester: newMockRequester(false)}
+	c, err := NewRpcClientWithIdGenerator(requester, &sequentialIds{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.SimulateCreateLedgerChannel(common.HexToAddress("0x2"), 0, outcome.Exit{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The address, chain id and auth token are requested first
+	wantIds := []uint64{101, 102, 103, 104, 105}
+	if len(requester.requestIds) != len(wantIds) {
+		t.Fatalf("expected request ids %v, got %v", wantIds, requester.requestIds)
+	}
+	for i, id := range wantIds {
+		if requester.requestIds[i] != id {
+			t.Fatalf("expected request ids %v, got %v", wantIds, requester.requestIds)
+		}
+	}
+	if len(requester.nonces) != 2 || requester.nonces[0] != 1 || requester.nonces[1] != 2 {
+		t.Errorf("expected channel nonces [1 2], got %v", requester.nonces)
+	}
+}
+
+func TestResponsesToOtherRequestsAreRejected(t *testing.T) {
+	requester := &recordingRequester{mockRequester: newMockRequester(false)}
+	c, err := NewRpcClientWithIdGenerator(requester, &sequentialIds{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	requester.responseIdOffset = 1
+	if _, err := c.SimulateCreateLedgerChannel(common.HexToAddress("0x2"), 0, outcome.Exit{}); !errors.Is(err, ErrUnexpectedResponseId) {
+		t.Errorf("expected a response to another request to be rejected with %v, got %v", ErrUnexpectedResponseId, err)
+	}
+}
+
+// droppingRequester drops its connection the first time it is sent each request, other than those made when the client is created.
+// It reconnects after reconnectAfter, or never if reconnectAfter is negative.
+type droppingRequester struct {
+	*mockRequester
+	reconnectAfter time.Duration
+	connected      atomic.Bool
+	mu             sync.Mutex
+	dropped        map[uint64]bool             // the ids of the requests whose connection was dropped
+	sent           map[serde.RequestMethod]int // how many times each method was sent
+}
+
+func newDroppingRequester(reconnectAfter time.Duration) *droppingRequester {
+	r := &droppingRequester{mockRequester: newMockRequester(false), reconnectAfter: reconnectAfter, dropped: map[uint64]bool{}, sent: map[serde.RequestMethod]int{}}
+	r.connected.Store(true)
+	return r
+}
+
+func (r *droppingRequester) Connected() bool {
+	return r.connected.Load()
+}
+
+func (r *droppingRequester) timesSent(method serde.RequestMethod) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sent[method]
+}
+
+func (r *droppingRequester) Request(data []byte) ([]byte, error) {
+	var req struct {
+		Id     uint64 `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	method := serde.RequestMethod(req.Method)
+	switch method {
+	case serde.GetAddressMethod, serde.GetChainIdMethod, serde.GetAuthTokenMethod:
+		return r.mockRequester.Request(data)
+	}
+
+	r.mu.Lock()
+	r.sent[method]++
+	drop := !r.dropped[req.Id]
+	r.dropped[req.Id] = true
+	r.mu.Unlock()
+	if drop {
+		r.connected.Store(false)
+		if r.reconnectAfter >= 0 {
+			time.AfterFunc(r.reconnectAfter, func() { r.connected.Store(true) })
+		}
+		return nil, fmt.Errorf("%w: dropped while awaiting the response to request %d", transport.ErrConnectionLost, req.Id)
+	}
+
+	switch method {
+	case serde.GetLedgerChannelRequestMethod:
+		return r.mockRequester.Request(data)
+	case serde.CloseLedgerChannelRequestMethod:
+		return json.Marshal(serde.NewJsonRpcResponse(req.Id, protocols.ObjectiveId("DirectDefunding-0x01")))
+	case serde.PayRequestMethod:
+		return json.Marshal(serde.NewJsonRpcResponse(req.Id, serde.PaymentRequest{Amount: 1}))
+	}
+	return nil, errors.New("unexpected method " + req.Method)
+}
+
+func TestRequestsInFlightWhenTheConnectionIsLost(t *testing.T) {
+	testCases := []struct {
+		name           string
+		replay         bool
+		reconnectAfter time.Duration
+		method         serde.RequestMethod
+		request        func(c RpcClientApi) error
+		wantReplayed   bool
+	}{
+		{
+			"a query is replayed", true, 20 * time.Millisecond, serde.GetLedgerChannelRequestMethod,
+			func(c RpcClientApi) error { _, err := c.GetLedgerChannel(types.Destination{1}); return err }, true,
+		},
+		{
+			"a request keyed by its objective id is replayed", true, 20 * time.Millisecond, serde.CloseLedgerChannelRequestMethod,
+			func(c RpcClientApi) error { _, err := c.CloseLedgerChannel(types.Destination{1}); return err }, true,
+		},
+		{
+			"a payment is not replayed", true, 20 * time.Millisecond, serde.PayRequestMethod,
+			func(c RpcClientApi) error { _, err := c.Pay(types.Destination{1}, 1); return err }, false,
+		},
+		{
+			"requests are not replayed unless the client replays them", false, 20 * time.Millisecond, serde.GetLedgerChannelRequestMethod,
+			func(c RpcClientApi) error { _, err := c.GetLedgerChannel(types.Destination{1}); return err }, false,
+		},
+		{
+			"requests are not replayed if the transport does not reconnect", true, -1, serde.GetLedgerChannelRequestMethod,
+			func(c RpcClientApi) error { _, err := c.GetLedgerChannel(types.Destination{1}); return err }, false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requester := newDroppingRequester(tc.reconnectAfter)
+			var c RpcClientApi
+			var err error
+			if tc.replay {
+				c, err = NewRpcClientWithReplay(requester, 200*time.Millisecond)
+			} else {
+				c, err = NewRpcClient(requester)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			err = tc.request(c)
+			if tc.wantReplayed {
+				if err != nil {
+					t.Fatalf("expected the replayed request to succeed, got %v", err)
+				}
+				if sent := requester.timesSent(tc.method); sent != 2 {
+					t.Errorf("expected the request to be sent twice, but it was sent %d times", sent)
+				}
+				return
+			}
+			if !errors.Is(err, ErrConnectionLost) || !errors.Is(err, transport.ErrConnectionLost) {
+				t.Fatalf("expected the request to fail with %v, got %v", ErrConnectionLost, err)
+			}
+			if sent := requester.timesSent(tc.method); sent != 1 {
+				t.Errorf("expected the request to be sent once, but it was sent %d times", sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// PaymentClientConfig describes the channels a PaymentClient opens when it has none with which to pay a payee
+type PaymentClientConfig struct {
+	// Asset is the asset payments are made in
+	Asset types.Address
+	// Intermediaries are the intermediaries of the payment channels the client opens. If empty, payment channels are funded by a ledger channel with the payee.
+	Intermediaries []types.Address
+	// LedgerDeposit is the amount deposited into a ledger channel with the first hop when it is opened, or topped up when it cannot fund a payment channel
+	LedgerDeposit uint64
+	// ChannelDeposit is the amount deposited into each payment channel, or the payment if that is larger
+	ChannelDeposit uint64
+	// ChallengeDuration is the challenge duration of the channels the client opens
+	ChallengeDuration uint32
+}
+
+// PaymentClient pays payees through a nitro node, opening the ledger and payment channels it needs to do so.
+// The payment channel opened for each payee is reused for later payments, until it no longer holds enough funds, when it is closed and replaced.
+// It is safe for concurrent use. Payments to the same payee are made one at a time, as is the funding of ledger channels with the same counterparty.
+type PaymentClient struct {
+	client      RpcClientApi
+	config      PaymentClientConfig
+	mu          sync.Mutex                          // guards the maps below
+	channels    map[types.Address]types.Destination // the payment channel most recently used to pay each payee
+	payeeLocks  map[types.Address]*sync.Mutex       // serialise the payments to each payee
+	ledgerLocks map[types.Address]*sync.Mutex       // serialise the funding of the ledger channel with each counterparty
+}
+
+// NewPaymentClient creates a PaymentClient which makes its requests with client
+func NewPaymentClient(client RpcClientApi, config PaymentClientConfig) *PaymentClient {
+	return &PaymentClient{
+		client:      client,
+		config:      config,
+		channels:    make(map[types.Address]types.Destination),
+		payeeLocks:  make(map[types.Address]*sync.Mutex),
+		ledgerLocks: make(map[types.Address]*sync.Mutex),
+	}
+}
+
+// lock locks the mutex held in locks for the address, creating it if needed, and returns a function which unlocks it
+func (pc *PaymentClient) lock(locks map[types.Address]*sync.Mutex, address types.Address) func() {
+	pc.mu.Lock()
+	l, ok := locks[address]
+	if !ok {
+		l = &sync.Mutex{}
+		locks[address] = l
+	}
+	pc.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// Pay pays the payee the given amount, first opening the channels needed to do so.
+// It returns the id of the payment channel the payment was made with.
+func (pc *PaymentClient) Pay(ctx context.Context, payee types.Address, amount uint64) (types.Destination, error) {
+	defer pc.lock(pc.payeeLocks, payee)()
+
+	channelId, err := pc.ensureChannel(ctx, payee, amount)
+	if err != nil {
+		return types.Destination{}, err
+	}
+	if _, err := pc.client.Pay(channelId, amount); err != nil {
+		return types.Destination{}, fmt.Errorf("could not pay %s with payment channel %s: %w", payee, channelId, err)
+	}
+	return channelId, nil
+}
+
+// EnsureChannel returns the id of an open payment channel which can pay the payee the given amount, opening it, and a ledger channel to fund it, if needed.
+func (pc *PaymentClient) EnsureChannel(ctx context.Context, payee types.Address, amount uint64) (types.Destination, error) {
+	defer pc.lock(pc.payeeLocks, payee)()
+	return pc.ensureChannel(ctx, payee, amount)
+}
+
+// ensureChannel must be called with the payee's lock held
+func (pc *PaymentClient) ensureChannel(ctx context.Context, payee types.Address, amount uint64) (types.Destination, error) {
+	pc.mu.Lock()
+	channelId, ok := pc.channels[payee]
+	pc.mu.Unlock()
+	if ok {
+		info, err := pc.client.GetPaymentChannel(channelId)
+		if err != nil {
+			return types.Destination{}, err
+		}
+		if info.Status == query.Open && info.Balance.RemainingFunds.ToInt().Cmp(new(big.Int).SetUint64(amount)) >= 0 {
+			return channelId, nil
+		}
+		// The channel cannot make the payment, so it is closed, returning its remaining funds to the ledger channel, before it is replaced
+		if info.Status == query.Open {
+			closeId, err := pc.client.ClosePaymentChannel(channelId)
+			if err != nil {
+				return types.Destination{}, fmt.Errorf("could not close payment channel %s with %s: %w", channelId, payee, err)
+			}
+			if err := pc.waitForObjective(ctx, closeId); err != nil {
+				return types.Destination{}, err
+			}
+		}
+		pc.mu.Lock()
+		delete(pc.channels, payee)
+		pc.mu.Unlock()
+	}
+
+	deposit := max(pc.config.ChannelDeposit, amount)
+	if err := pc.ensureLedger(ctx, pc.firstHop(payee), deposit); err != nil {
+		return types.Destination{}, err
+	}
+
+	me, err := pc.client.Address()
+	if err != nil {
+		return types.Destination{}, err
+	}
+	response, err := pc.client.CreatePaymentChannel(pc.config.Intermediaries, payee, pc.config.ChallengeDuration, pc.outcome(me, payee, deposit))
+	if err != nil {
+		return types.Destination{}, fmt.Errorf("could not open a payment channel with %s: %w", payee, err)
+	}
+	if err := pc.waitForObjective(ctx, response.Id); err != nil {
+		return types.Destination{}, err
+	}
+	pc.mu.Lock()
+	pc.channels[payee] = response.ChannelId
+	pc.mu.Unlock()
+	return response.ChannelId, nil
+}
+
+// ensureLedger makes sure that the node has the deposit to spare in an open ledger channel with the counterparty.
+// It tops up the ledger channel if the node's balance falls short, by the shortfall or LedgerDeposit if that is larger, and opens one if there is none.
+func (pc *PaymentClient) ensureLedger(ctx context.Context, counterparty types.Address, deposit uint64) error {
+	defer pc.lock(pc.ledgerLocks, counterparty)()
+
+	ledgers, err := pc.client.GetAllLedgerChannels()
+	if err != nil {
+		return err
+	}
+	for _, ledger := range ledgers {
+		balance, ok := ledger.BalanceFor(pc.config.Asset)
+		if ledger.Status != query.Open || !ok || balance.Them != counterparty {
+			continue
+		}
+		shortfall := new(big.Int).Sub(new(big.Int).SetUint64(deposit), balance.MyBalance.ToInt())
+		if shortfall.Sign() <= 0 {
+			return nil
+		}
+		topUp := new(big.Int).SetUint64(pc.config.LedgerDeposit)
+		if topUp.Cmp(shortfall) < 0 {
+			topUp = shortfall
+		}
+		topUpId, err := pc.client.TopUpLedgerChannel(ledger.ID, topUp)
+		if err != nil {
+			return fmt.Errorf("could not top up ledger channel %s with %s: %w", ledger.ID, counterparty, err)
+		}
+		return pc.waitForObjective(ctx, topUpId)
+	}
+
+	me, err := pc.client.Address()
+	if err != nil {
+		return err
+	}
+	response, err := pc.client.CreateLedgerChannel(counterparty, pc.config.ChallengeDuration, pc.outcome(me, counterparty, max(pc.config.LedgerDeposit, deposit)))
+	if err != nil {
+		return fmt.Errorf("could not open a ledger channel with %s: %w", counterparty, err)
+	}
+	return pc.waitForObjective(ctx, response.Id)
+}
+
+// firstHop returns the counterparty of the ledger channel which funds payment channels with the payee
+func (pc *PaymentClient) firstHop(payee types.Address) types.Address {
+	if len(pc.config.Intermediaries) > 0 {
+		return pc.config.Intermediaries[0]
+	}
+	return payee
+}
+
+// outcome allocates the amount of the client's asset to me, and nothing to them
+func (pc *PaymentClient) outcome(me, them types.Address, amount uint64) outcome.Exit {
+	return outcome.Exit{outcome.SingleAssetExit{
+		Asset: pc.config.Asset,
+		Allocations: outcome.Allocations{
+			outcome.Allocation{Destination: types.AddressToDestination(me), Amount: new(big.Int).SetUint64(amount)},
+			outcome.Allocation{Destination: types.AddressToDestination(them), Amount: big.NewInt(0)},
+		},
+	}}
+}
+
+func (pc *PaymentClient) waitForObjective(ctx context.Context, id protocols.ObjectiveId) error {
+	select {
+	case <-pc.client.ObjectiveCompleteChan(id):
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("objective %s did not complete: %w", id, ctx.Err())
+	}
+}
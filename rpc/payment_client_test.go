@@ -0,0 +1,235 @@
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// fakePaymentNode is an RpcClientApi which opens channels as soon as they are requested, and records the payments it is asked to make.
+// Objectives complete at once, unless they open a ledger channel with held, which completes once release is closed.
+// Methods which PaymentClient does not use are left unimplemented.
+type fakePaymentNode struct {
+	RpcClientApi
+	address         common.Address
+	mu              sync.Mutex
+	ledgers         []query.LedgerChannelInfo
+	paymentChannels map[types.Destination]query.PaymentChannelInfo
+	payments        []serde.PaymentRequest
+	topUps          []*big.Int
+	closed          []types.Destination
+
+	held    common.Address
+	release chan struct{}
+	heldIds map[protocols.ObjectiveId]bool
+}
+
+func newFakePaymentNode() *fakePaymentNode {
+	return &fakePaymentNode{
+		address:         common.HexToAddress("0xa"),
+		paymentChannels: map[types.Destination]query.PaymentChannelInfo{},
+		release:         make(chan struct{}),
+		heldIds:         map[protocols.ObjectiveId]bool{},
+	}
+}
+
+func (f *fakePaymentNode) Address() (common.Address, error) { return f.address, nil }
+
+func (f *fakePaymentNode) GetAllLedgerChannels() ([]query.LedgerChannelInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.ledgers, nil
+}
+
+func (f *fakePaymentNode) CreateLedgerChannel(counterparty types.Address, challengeDuration uint32, o outcome.Exit) (directfund.ObjectiveResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := types.Destination{byte(len(f.ledgers) + 1)}
+	balance := query.LedgerChannelBalance{
+		AssetAddress: o[0].Asset,
+		Me:           f.address,
+		Them:         counterparty,
+		MyBalance:    (*hexutil.Big)(o[0].Allocations[0].Amount),
+		TheirBalance: (*hexutil.Big)(o[0].Allocations[1].Amount),
+	}
+	f.ledgers = append(f.ledgers, query.LedgerChannelInfo{ID: id, Status: query.Open, Balance: balance, Balances: []query.LedgerChannelBalance{balance}})
+	objectiveId := protocols.ObjectiveId("DirectFunding-" + id.String())
+	f.heldIds[objectiveId] = counterparty == f.held
+	return directfund.ObjectiveResponse{Id: objectiveId, ChannelId: id}, nil
+}
+
+func (f *fakePaymentNode) TopUpLedgerChannel(id types.Destination, amount *big.Int) (protocols.ObjectiveId, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	myBalance := f.ledger(id).Balance.MyBalance.ToInt()
+	myBalance.Add(myBalance, amount)
+	f.topUps = append(f.topUps, amount)
+	return protocols.ObjectiveId("LedgerTopUp-" + id.String()), nil
+}
+
+// ledger returns the ledger channel with the given id, or with the given counterparty
+func (f *fakePaymentNode) ledger(id types.Destination) query.LedgerChannelInfo {
+	for _, l := range f.ledgers {
+		if l.ID == id || types.AddressToDestination(l.Balance.Them) == id {
+			return l
+		}
+	}
+	panic("no such ledger channel")
+}
+
+func (f *fakePaymentNode) CreatePaymentChannel(intermediaries []types.Address, counterparty types.Address, challengeDuration uint32, o outcome.Exit) (virtualfund.ObjectiveResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := types.Destination{0xff, byte(len(f.paymentChannels) + 1)}
+	deposit := o[0].Allocations[0].Amount
+	f.paymentChannels[id] = query.PaymentChannelInfo{ID: id, Status: query.Open, Balance: query.PaymentChannelBalance{
+		AssetAddress:   o[0].Asset,
+		Payer:          f.address,
+		Payee:          counterparty,
+		PaidSoFar:      (*hexutil.Big)(big.NewInt(0)),
+		RemainingFunds: (*hexutil.Big)(new(big.Int).Set(deposit)),
+	}}
+	// The ledger with the payee funds the payment channel
+	myBalance := f.ledger(types.AddressToDestination(counterparty)).Balance.MyBalance.ToInt()
+	myBalance.Sub(myBalance, deposit)
+	return virtualfund.ObjectiveResponse{Id: protocols.ObjectiveId("VirtualFund-" + id.String()), ChannelId: id}, nil
+}
+
+func (f *fakePaymentNode) ClosePaymentChannel(id types.Destination) (protocols.ObjectiveId, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info := f.paymentChannels[id]
+	// The remaining funds return to the ledger with the payee
+	myBalance := f.ledger(types.AddressToDestination(info.Balance.Payee)).Balance.MyBalance.ToInt()
+	myBalance.Add(myBalance, info.Balance.RemainingFunds.ToInt())
+	info.Status = query.Complete
+	f.paymentChannels[id] = info
+	f.closed = append(f.closed, id)
+	return protocols.ObjectiveId("VirtualDefund-" + id.String()), nil
+}
+
+func (f *fakePaymentNode) GetPaymentChannel(id types.Destination) (query.PaymentChannelInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.paymentChannels[id], nil
+}
+
+func (f *fakePaymentNode) Pay(id types.Destination, amount uint64) (serde.PaymentRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	remaining := f.paymentChannels[id].Balance.RemainingFunds.ToInt()
+	remaining.Sub(remaining, new(big.Int).SetUint64(amount))
+	f.payments = append(f.payments, serde.PaymentRequest{Channel: id, Amount: amount})
+	return serde.PaymentRequest{Channel: id, Amount: amount}, nil
+}
+
+func (f *fakePaymentNode) ObjectiveCompleteChan(id protocols.ObjectiveId) <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.heldIds[id] {
+		return f.release
+	}
+	c := make(chan struct{})
+	close(c)
+	return c
+}
+
+func TestPaymentClientReusesChannels(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	bob := common.HexToAddress("0xb")
+	n := newFakePaymentNode()
+	pc := NewPaymentClient(n, PaymentClientConfig{LedgerDeposit: 100, ChannelDeposit: 10})
+
+	first, err := pc.Pay(ctx, bob, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := pc.Pay(ctx, bob, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("expected the second payment to reuse payment channel %s, but it was made with %s", first, second)
+	}
+	if len(n.ledgers) != 1 || len(n.paymentChannels) != 1 {
+		t.Fatalf("expected one ledger and one payment channel to be opened, got %d and %d", len(n.ledgers), len(n.paymentChannels))
+	}
+	if len(n.payments) != 2 || n.payments[0].Amount != 5 || n.payments[1].Amount != 5 {
+		t.Errorf("expected two payments of 5, got %v", n.payments)
+	}
+
+	// The payment channel is spent, so another is opened with the same ledger
+	third, err := pc.Pay(ctx, bob, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third == first {
+		t.Errorf("expected a payment larger than the remaining funds to open a new payment channel")
+	}
+	if len(n.ledgers) != 1 || len(n.paymentChannels) != 2 {
+		t.Fatalf("expected the ledger channel to fund a second payment channel, got %d ledgers and %d payment channels", len(n.ledgers), len(n.paymentChannels))
+	}
+	if remaining := n.paymentChannels[third].Balance.RemainingFunds.ToInt(); remaining.Sign() != 0 {
+		t.Errorf("expected the new payment channel to be funded with the payment, but %s remains", remaining)
+	}
+
+	if len(n.closed) != 1 || n.closed[0] != first {
+		t.Errorf("expected the spent payment channel %s to be closed before it was replaced, but %v were closed", first, n.closed)
+	}
+
+	// A payment the ledger channel cannot fund tops it up
+	ledgerBalance := n.ledgers[0].Balance.MyBalance.ToInt().Uint64()
+	if _, err := pc.Pay(ctx, bob, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.ledgers) != 1 || len(n.topUps) != 1 || n.topUps[0].Uint64() != 1000-ledgerBalance {
+		t.Errorf("expected the ledger channel to be topped up by its shortfall of %d, but it was topped up by %v", 1000-ledgerBalance, n.topUps)
+	}
+}
+
+func TestPaymentClientPaysPayeesConcurrently(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	bob, carol := common.HexToAddress("0xb"), common.HexToAddress("0xc")
+	n := newFakePaymentNode()
+	n.held = bob
+	pc := NewPaymentClient(n, PaymentClientConfig{LedgerDeposit: 100, ChannelDeposit: 10})
+
+	// Opening the ledger channel with Bob stalls, which does not hold up paying Carol
+	paidBob := make(chan error, 1)
+	go func() {
+		_, err := pc.Pay(ctx, bob, 5)
+		paidBob <- err
+	}()
+	for ledgers, _ := n.GetAllLedgerChannels(); len(ledgers) == 0; ledgers, _ = n.GetAllLedgerChannels() {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := pc.Pay(ctx, carol, 5); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-paidBob:
+		t.Fatalf("expected paying Bob to wait for the ledger channel, but it returned %v", err)
+	default:
+	}
+
+	close(n.release)
+	if err := <-paidBob; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,12 +2,16 @@ package serde
 
 import (
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/appupdate"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/types"
@@ -16,22 +20,63 @@ import (
 type RequestMethod string
 
 const (
-	GetAuthTokenMethod                RequestMethod = "get_auth_token"
-	GetAddressMethod                  RequestMethod = "get_address"
-	VersionMethod                     RequestMethod = "version"
-	CreateLedgerChannelRequestMethod  RequestMethod = "create_ledger_channel"
-	CloseLedgerChannelRequestMethod   RequestMethod = "close_ledger_channel"
-	CreatePaymentChannelRequestMethod RequestMethod = "create_payment_channel"
-	ClosePaymentChannelRequestMethod  RequestMethod = "close_payment_channel"
-	PayRequestMethod                  RequestMethod = "pay"
-	GetPaymentChannelRequestMethod    RequestMethod = "get_payment_channel"
-	GetLedgerChannelRequestMethod     RequestMethod = "get_ledger_channel"
-	GetPaymentChannelsByLedgerMethod  RequestMethod = "get_payment_channels_by_ledger"
-	GetAllLedgerChannelsMethod        RequestMethod = "get_all_ledger_channels"
-	CreateVoucherRequestMethod        RequestMethod = "create_voucher"
-	ReceiveVoucherRequestMethod       RequestMethod = "receive_voucher"
+	GetAuthTokenMethod                 RequestMethod = "get_auth_token"
+	GetAddressMethod                   RequestMethod = "get_address"
+	GetChainIdMethod                   RequestMethod = "get_chain_id"
+	VersionMethod                      RequestMethod = "version"
+	HealthMethod                       RequestMethod = "get_health"
+	CreateLedgerChannelRequestMethod   RequestMethod = "create_ledger_channel"
+	CloseLedgerChannelRequestMethod    RequestMethod = "close_ledger_channel"
+	TopUpLedgerChannelRequestMethod    RequestMethod = "top_up_ledger_channel"
+	UpdateAppStateRequestMethod        RequestMethod = "update_app_state"
+	CreatePaymentChannelRequestMethod  RequestMethod = "create_payment_channel"
+	ClosePaymentChannelRequestMethod   RequestMethod = "close_payment_channel"
+	PayRequestMethod                   RequestMethod = "pay"
+	GetPaymentChannelRequestMethod     RequestMethod = "get_payment_channel"
+	GetLedgerChannelRequestMethod      RequestMethod = "get_ledger_channel"
+	GetPaymentChannelsByLedgerMethod   RequestMethod = "get_payment_channels_by_ledger"
+	GetAllLedgerChannelsMethod         RequestMethod = "get_all_ledger_channels"
+	CreateVoucherRequestMethod         RequestMethod = "create_voucher"
+	ReceiveVoucherRequestMethod        RequestMethod = "receive_voucher"
+	GetVoucherBalanceRequestMethod     RequestMethod = "get_voucher_balance"
+	GetObjectiveByChannelIdMethod      RequestMethod = "get_objective_by_channel_id"
+	SimulateCreateLedgerChannelMethod  RequestMethod = "simulate_create_ledger_channel"
+	SimulateCreatePaymentChannelMethod RequestMethod = "simulate_create_payment_channel"
+	GetTotalAvailableLiquidityMethod   RequestMethod = "get_total_available_liquidity"
+	GetLatestSignedStateMethod         RequestMethod = "get_latest_signed_state"
 )
 
+// AllRequestMethods returns every method that the rpc server handles.
+// A new RequestMethod must be added here too, so that tests check that it is handled.
+func AllRequestMethods() []RequestMethod {
+	return []RequestMethod{
+		GetAuthTokenMethod,
+		GetAddressMethod,
+		GetChainIdMethod,
+		VersionMethod,
+		HealthMethod,
+		CreateLedgerChannelRequestMethod,
+		CloseLedgerChannelRequestMethod,
+		TopUpLedgerChannelRequestMethod,
+		UpdateAppStateRequestMethod,
+		CreatePaymentChannelRequestMethod,
+		ClosePaymentChannelRequestMethod,
+		PayRequestMethod,
+		GetPaymentChannelRequestMethod,
+		GetLedgerChannelRequestMethod,
+		GetPaymentChannelsByLedgerMethod,
+		GetAllLedgerChannelsMethod,
+		CreateVoucherRequestMethod,
+		ReceiveVoucherRequestMethod,
+		GetVoucherBalanceRequestMethod,
+		GetObjectiveByChannelIdMethod,
+		SimulateCreateLedgerChannelMethod,
+		SimulateCreatePaymentChannelMethod,
+		GetTotalAvailableLiquidityMethod,
+		GetLatestSignedStateMethod,
+	}
+}
+
 type NotificationMethod string
 
 const (
@@ -59,9 +104,22 @@ type GetPaymentChannelRequest struct {
 type GetLedgerChannelRequest struct {
 	Id types.Destination
 }
+type GetVoucherBalanceRequest struct {
+	Id     types.Destination
+	Caller string `json:",omitempty"` // Identifies whose last query the delta is measured from
+}
+type GetObjectiveByChannelIdRequest struct {
+	ChannelId types.Destination
+}
 type GetPaymentChannelsByLedgerRequest struct {
 	LedgerId types.Destination
 }
+type GetTotalAvailableLiquidityRequest struct {
+	Asset types.Address
+}
+type GetLatestSignedStateRequest struct {
+	ChannelId types.Destination
+}
 
 type (
 	NoPayloadRequest = struct{}
@@ -70,6 +128,8 @@ type (
 type RequestPayload interface {
 	directfund.ObjectiveRequest |
 		directdefund.ObjectiveRequest |
+		ledgertopup.ObjectiveRequest |
+		appupdate.ObjectiveRequest |
 		virtualfund.ObjectiveRequest |
 		virtualdefund.ObjectiveRequest |
 		AuthRequest |
@@ -77,6 +137,10 @@ type RequestPayload interface {
 		GetLedgerChannelRequest |
 		GetPaymentChannelRequest |
 		GetPaymentChannelsByLedgerRequest |
+		GetVoucherBalanceRequest |
+		GetObjectiveByChannelIdRequest |
+		GetTotalAvailableLiquidityRequest |
+		GetLatestSignedStateRequest |
 		NoPayloadRequest |
 		payments.Voucher
 }
@@ -89,7 +153,9 @@ type NotificationPayload interface {
 
 type Params[T RequestPayload | NotificationPayload] struct {
 	AuthToken string `json:"authtoken"`
-	Payload   T      `json:"payload"`
+	// ChainId is the decimal id of the chain the request is meant for. If it is set, the server rejects the request unless its node is on that chain.
+	ChainId string `json:"chainid,omitempty"`
+	Payload T      `json:"payload"`
 }
 
 type JsonRpcSpecificRequest[T RequestPayload | NotificationPayload] struct {
@@ -116,7 +182,12 @@ type ResponsePayload interface {
 		payments.Voucher |
 		common.Address |
 		string |
-		payments.ReceiveVoucherSummary
+		payments.ReceiveVoucherSummary |
+		query.HealthInfo |
+		query.ObjectiveStatusInfo |
+		query.SimulatedObjectiveInfo |
+		state.SignedState |
+		*hexutil.Big
 }
 
 type JsonRpcSuccessResponse[T ResponsePayload] struct {
@@ -189,4 +260,5 @@ var (
 	RequestUnmarshalError = JsonRpcError{Code: -32010, Message: "Could not unmarshal request object"}
 	ParamsUnmarshalError  = JsonRpcError{Code: -32009, Message: "Could not unmarshal params object"}
 	InvalidAuthTokenError = JsonRpcError{Code: -32008, Message: "Invalid auth token"}
+	ChainIdMismatchError  = JsonRpcError{Code: -32007, Message: "Chain id does not match the node's chain"}
 )
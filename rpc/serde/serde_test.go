@@ -2,6 +2,7 @@ package serde
 
 import (
 	"encoding/json"
+	"regexp"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -56,3 +57,17 @@ func TestUnmarshalJSON(t *testing.T) {
 		t.Fatalf("TestUnmarshalJSON: mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestAllRequestMethods(t *testing.T) {
+	wellFormed := regexp.MustCompile(`^[a-z]+(_[a-z]+)*$`)
+	seen := map[RequestMethod]bool{}
+	for _, method := range AllRequestMethods() {
+		if !wellFormed.MatchString(string(method)) {
+			t.Errorf("method %q is not snake case", method)
+		}
+		if seen[method] {
+			t.Errorf("method %q is listed more than once", method)
+		}
+		seen[method] = true
+	}
+}
@@ -0,0 +1,22 @@
+package serde
+
+// subjectPrefix is the first token of the NATS subject of every request method
+const subjectPrefix = "nitro"
+
+// Subject returns the NATS subject on which requests to the method are made.
+// Both the clients and the server build subjects with it, so that they agree on where each request is routed.
+func Subject(method RequestMethod) string {
+	return subjectPrefix + "." + string(method)
+}
+
+// ResponseSubject returns the NATS subject on which the server publishes the successful responses to requests to the method
+// which were published without a reply subject. Requests made with a reply subject are answered there instead.
+func ResponseSubject(method RequestMethod) string {
+	return Subject(method) + ".response"
+}
+
+// ErrorSubject returns the NATS subject on which the server publishes the error responses to requests to the method
+// which were published without a reply subject. Requests made with a reply subject are answered there instead.
+func ErrorSubject(method RequestMethod) string {
+	return Subject(method) + ".error"
+}
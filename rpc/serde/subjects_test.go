@@ -0,0 +1,29 @@
+package serde
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubjects(t *testing.T) {
+	seen := map[string]RequestMethod{}
+	for _, method := range AllRequestMethods() {
+		t.Run(string(method), func(t *testing.T) {
+			for _, subject := range []string{Subject(method), ResponseSubject(method), ErrorSubject(method)} {
+				// A subject to publish to is made of non-empty tokens separated by dots, without wildcards or whitespace
+				for _, token := range strings.Split(subject, ".") {
+					if token == "" || strings.ContainsAny(token, " \t\r\n*>") {
+						t.Errorf("subject %q is not a valid subject to publish to", subject)
+					}
+				}
+				if other, ok := seen[subject]; ok {
+					t.Errorf("subject %q is used by both %s and %s", subject, other, method)
+				}
+				seen[subject] = method
+			}
+			if got, want := Subject(method), "nitro."+string(method); got != want {
+				t.Errorf("expected requests to be made on %q, got %q", want, got)
+			}
+		})
+	}
+}
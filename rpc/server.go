@@ -3,18 +3,25 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"math/big"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/internal/logging"
 	nitro "github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/appupdate"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/rand"
@@ -23,6 +30,12 @@ import (
 	"github.com/statechannels/go-nitro/types"
 )
 
+// Metric names recorded by the rpc server
+const (
+	RequestsMetric        = "rpc_requests"
+	RequestDurationMetric = "rpc_request_duration"
+)
+
 // RpcServer handles nitro rpc requests and executes them on the nitro node
 type RpcServer struct {
 	transport transport.Responder
@@ -30,6 +43,7 @@ type RpcServer struct {
 	logger    *slog.Logger
 	cancel    context.CancelFunc
 	wg        *sync.WaitGroup
+	metrics   engine.MetricsApi
 }
 
 func (rs *RpcServer) Url() string {
@@ -63,6 +77,7 @@ func newRpcServerWithoutNotifications(nitroNode *nitro.Node, trans transport.Res
 		cancel:    func() {},
 		wg:        &sync.WaitGroup{},
 		logger:    logger,
+		metrics:   engine.NoOpMetrics{},
 	}
 
 	err := rs.registerHandlers()
@@ -74,6 +89,15 @@ func newRpcServerWithoutNotifications(nitroNode *nitro.Node, trans transport.Res
 }
 
 func NewRpcServer(nitroNode *nitro.Node, trans transport.Responder) (*RpcServer, error) {
+	return NewRpcServerWithMetrics(nitroNode, trans, nil)
+}
+
+// NewRpcServerWithMetrics creates a new rpc server which records how many requests it handles, and how long they take, to the metricsApi.
+// If metricsApi is nil, the metrics are discarded.
+func NewRpcServerWithMetrics(nitroNode *nitro.Node, trans transport.Responder, metricsApi engine.MetricsApi) (*RpcServer, error) {
+	if metricsApi == nil {
+		metricsApi = engine.NoOpMetrics{}
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	rs := &RpcServer{
 		transport: trans,
@@ -81,6 +105,7 @@ func NewRpcServer(nitroNode *nitro.Node, trans transport.Responder) (*RpcServer,
 		cancel:    cancel,
 		wg:        &sync.WaitGroup{},
 		logger:    logging.LoggerWithAddress(slog.Default(), *nitroNode.Address),
+		metrics:   metricsApi,
 	}
 
 	rs.wg.Add(1)
@@ -104,6 +129,12 @@ func NewRpcServer(nitroNode *nitro.Node, trans transport.Responder) (*RpcServer,
 // registerHandlers registers the handlers for the rpc server
 func (rs *RpcServer) registerHandlers() (err error) {
 	handlerV1 := func(requestData []byte) []byte {
+		start := time.Now()
+		defer func() {
+			rs.metrics.IncrementCounter(RequestsMetric)
+			rs.metrics.RecordDuration(RequestDurationMetric, time.Since(start))
+		}()
+
 		if !json.Valid(requestData) {
 			rs.logger.Error("request is not valid json")
 			errRes := serde.NewJsonRpcErrorResponse(0, serde.ParseError)
@@ -131,14 +162,27 @@ func (rs *RpcServer) registerHandlers() (err error) {
 			return processRequest(rs, permRead, requestData, func(req payments.Voucher) (payments.ReceiveVoucherSummary, error) {
 				return rs.node.ReceiveVoucher(req)
 			})
+		case serde.GetVoucherBalanceRequestMethod:
+			// Querying the balance records the caller's last query, so it needs the permission which is required to change the node's state
+			return processRequest(rs, permSign, requestData, func(req serde.GetVoucherBalanceRequest) (payments.ReceiveVoucherSummary, error) {
+				return rs.node.GetVoucherBalance(req.Id, req.Caller)
+			})
 		case serde.GetAddressMethod:
 			return processRequest(rs, permNone, requestData, func(req serde.NoPayloadRequest) (string, error) {
 				return rs.node.Address.Hex(), nil
 			})
+		case serde.GetChainIdMethod:
+			return processRequest(rs, permNone, requestData, func(req serde.NoPayloadRequest) (string, error) {
+				return rs.node.ChainId().String(), nil
+			})
 		case serde.VersionMethod:
 			return processRequest(rs, permNone, requestData, func(req serde.NoPayloadRequest) (string, error) {
 				return rs.node.Version(), nil
 			})
+		case serde.HealthMethod:
+			return processRequest(rs, permNone, requestData, func(req serde.NoPayloadRequest) (query.HealthInfo, error) {
+				return rs.node.Health(), nil
+			})
 		case serde.CreateLedgerChannelRequestMethod:
 			return processRequest(rs, permSign, requestData, func(req directfund.ObjectiveRequest) (directfund.ObjectiveResponse, error) {
 				return rs.node.CreateLedgerChannel(req.CounterParty, req.ChallengeDuration, req.Outcome)
@@ -147,6 +191,14 @@ func (rs *RpcServer) registerHandlers() (err error) {
 			return processRequest(rs, permSign, requestData, func(req directdefund.ObjectiveRequest) (protocols.ObjectiveId, error) {
 				return rs.node.CloseLedgerChannel(req.ChannelId)
 			})
+		case serde.TopUpLedgerChannelRequestMethod:
+			return processRequest(rs, permSign, requestData, func(req ledgertopup.ObjectiveRequest) (protocols.ObjectiveId, error) {
+				return rs.node.TopUpLedgerChannel(req.ChannelId, req.Amount)
+			})
+		case serde.UpdateAppStateRequestMethod:
+			return processRequest(rs, permSign, requestData, func(req appupdate.ObjectiveRequest) (protocols.ObjectiveId, error) {
+				return rs.node.UpdateAppState(req.State)
+			})
 		case serde.CreatePaymentChannelRequestMethod:
 			return processRequest(rs, permSign, requestData, func(req virtualfund.ObjectiveRequest) (virtualfund.ObjectiveResponse, error) {
 				return rs.node.CreatePaymentChannel(req.Intermediaries, req.CounterParty, req.ChallengeDuration, req.Outcome)
@@ -174,10 +226,31 @@ func (rs *RpcServer) registerHandlers() (err error) {
 			return processRequest(rs, permRead, requestData, func(req serde.GetLedgerChannelRequest) (query.LedgerChannelInfo, error) {
 				return rs.node.GetLedgerChannel(req.Id)
 			})
+		case serde.GetObjectiveByChannelIdMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.GetObjectiveByChannelIdRequest) (query.ObjectiveStatusInfo, error) {
+				return rs.node.GetObjectiveByChannelId(req.ChannelId)
+			})
+		case serde.SimulateCreateLedgerChannelMethod:
+			return processRequest(rs, permRead, requestData, func(req directfund.ObjectiveRequest) (query.SimulatedObjectiveInfo, error) {
+				return rs.node.SimulateCreateLedgerChannel(req.CounterParty, req.ChallengeDuration, req.Outcome)
+			})
+		case serde.SimulateCreatePaymentChannelMethod:
+			return processRequest(rs, permRead, requestData, func(req virtualfund.ObjectiveRequest) (query.SimulatedObjectiveInfo, error) {
+				return rs.node.SimulateCreatePaymentChannel(req.Intermediaries, req.CounterParty, req.ChallengeDuration, req.Outcome)
+			})
 		case serde.GetAllLedgerChannelsMethod:
 			return processRequest(rs, permRead, requestData, func(req serde.NoPayloadRequest) ([]query.LedgerChannelInfo, error) {
 				return rs.node.GetAllLedgerChannels()
 			})
+		case serde.GetTotalAvailableLiquidityMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.GetTotalAvailableLiquidityRequest) (*hexutil.Big, error) {
+				liquidity, err := rs.node.TotalAvailableLiquidity(req.Asset)
+				return (*hexutil.Big)(liquidity), err
+			})
+		case serde.GetLatestSignedStateMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.GetLatestSignedStateRequest) (state.SignedState, error) {
+				return rs.node.GetLatestSignedState(req.ChannelId)
+			})
 		case serde.GetPaymentChannelsByLedgerMethod:
 			return processRequest(rs, permRead, requestData, func(req serde.GetPaymentChannelsByLedgerRequest) ([]query.PaymentChannelInfo, error) {
 				if err := serde.ValidateGetPaymentChannelsByLedgerRequest(req); err != nil {
@@ -205,6 +278,13 @@ func processRequest[T serde.RequestPayload, U serde.ResponsePayload](rs *RpcServ
 		return marshalResponse(response)
 	}
 
+	if chainId := rpcRequest.Params.ChainId; chainId != "" && chainId != rs.node.ChainId().String() {
+		response := serde.ChainIdMismatchError
+		response.Message = fmt.Sprintf("%s: the request is for chain %s but the node is on chain %s", response.Message, chainId, rs.node.ChainId())
+		rs.logger.Warn(response.Message)
+		return marshalResponse(serde.NewJsonRpcErrorResponse(rpcRequest.Id, response))
+	}
+
 	err = checkTokenValidity(rpcRequest.Params.AuthToken, permission, 7*24*time.Hour)
 	if err != nil {
 		response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, serde.InvalidAuthTokenError)
@@ -179,3 +179,28 @@ func TestRpcPayInvalidParam(t *testing.T) {
 	expectedError := serde.InvalidParamsError
 	sendRequestAndExpectError(t, jsonRequest, expectedError)
 }
+
+func TestRpcGetVoucherBalanceRequiresSignPermission(t *testing.T) {
+	authToken, err := generateAuthToken("1", []permission{permRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := serde.JsonRpcSpecificRequest[serde.GetVoucherBalanceRequest]{
+		Jsonrpc: "2.0", Id: 2, Method: "get_voucher_balance", Params: serde.Params[serde.GetVoucherBalanceRequest]{AuthToken: authToken},
+	}
+	jsonRequest, err := json.Marshal(request)
+	if err != nil {
+		t.Error(err)
+	}
+	sendRequestAndExpectError(t, jsonRequest, serde.InvalidAuthTokenError)
+}
+
+func TestRpcAllMethodsHandled(t *testing.T) {
+	for _, method := range serde.AllRequestMethods() {
+		// Malformed params are rejected by a method's handler, before the node is used
+		request := []byte(`{"jsonrpc":"2.0","id":2,"method":"` + string(method) + `","params":5}`)
+		t.Run(string(method), func(t *testing.T) {
+			sendRequestAndExpectError(t, request, serde.ParamsUnmarshalError)
+		})
+	}
+}
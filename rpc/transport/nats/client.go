@@ -1,10 +1,14 @@
 package nats
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
 )
 
 type natsTransportClient struct {
@@ -22,19 +26,31 @@ func NewNatsTransportAsClient(url string) (*natsTransportClient, error) {
 	}, nil
 }
 
+// Request sends the json-rpc request on the subject of its method, and returns the response
 func (c *natsTransportClient) Request(data []byte) ([]byte, error) {
+	var request struct {
+		Method serde.RequestMethod `json:"method"`
+	}
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("could not read the method of the request: %w", err)
+	}
 	requestFn := func(data []byte) (*nats.Msg, error) {
-		return c.nc.Request(nitroRequestTopic+apiVersionPath, data, 10*time.Second)
+		return c.nc.Request(serde.Subject(request.Method), data, 10*time.Second)
 	}
 
 	numTries := 2
 	var err error
 	var msg *nats.Msg
 	for i := 0; i < numTries; i++ {
+		reconnects := c.nc.Stats().Reconnects
 		msg, err = requestFn(data)
 		if msg != nil && err == nil {
 			return msg.Data, nil
 		}
+		// The server may have received the request, so it is not sent again
+		if c.connectionLost(reconnects, err) {
+			return nil, fmt.Errorf("%w: %w", transport.ErrConnectionLost, err)
+		}
 
 		// Skip sleep after the last try
 		if lastTry := i == numTries-1; lastTry {
@@ -47,6 +63,14 @@ func (c *natsTransportClient) Request(data []byte) ([]byte, error) {
 	return nil, fmt.Errorf("received nill data for request %v with error %w", string(data), err)
 }
 
+// connectionLost returns true if the connection to the NATS server closed, dropped, or reconnected since it had reconnected the given number of times
+func (c *natsTransportClient) connectionLost(reconnects uint64, err error) bool {
+	if errors.Is(err, nats.ErrConnectionClosed) || errors.Is(err, nats.ErrDisconnected) {
+		return true
+	}
+	return !c.nc.IsConnected() || c.nc.Stats().Reconnects != reconnects
+}
+
 func (c *natsTransportClient) Subscribe() (<-chan []byte, error) {
 	if c.notificationChan != nil {
 		return c.notificationChan, nil
@@ -60,6 +84,11 @@ func (c *natsTransportClient) Subscribe() (<-chan []byte, error) {
 	return c.notificationChan, err
 }
 
+// Connected reports whether the connection to the NATS server is currently established
+func (c *natsTransportClient) Connected() bool {
+	return c.nc.IsConnected()
+}
+
 func (c *natsTransportClient) Close() error {
 	err := c.natsTransport.Close()
 	if err != nil {
@@ -1,16 +1,21 @@
 package nats
 
 import (
+	"encoding/json"
+	"fmt"
 	"log/slog"
 
 	"github.com/nats-io/nats-server/v2/server"
 	"github.com/nats-io/nats.go"
+	"github.com/statechannels/go-nitro/rpc/serde"
 )
 
 const (
-	nitroRequestTopic      = "nitro-request"
 	nitroNotificationTopic = "nitro-notify"
-	apiVersionPath         = "/api/v1"
+	// apiVersion is the version of the API served on the subjects of the request methods
+	apiVersion = "v1"
+	// DefaultMaxMessageSize is the largest request or notification, in bytes, that the transport accepts by default
+	DefaultMaxMessageSize = 1 << 20
 )
 
 type natsTransport struct {
@@ -58,8 +63,10 @@ func (c *natsTransport) unsubscribeFromTopic(sub *nats.Subscription, retries uin
 	return err
 }
 
-func NewNatsTransportAsServer(rpcPort int) (*natsTransportServer, error) {
-	opts := &server.Options{Port: rpcPort}
+// NewNatsTransportAsServer starts a NATS server which accepts messages of up to maxMessageSize bytes.
+// Larger messages are rejected by the NATS server before they reach the transport's request handler.
+func NewNatsTransportAsServer(rpcPort int, maxMessageSize int) (*natsTransportServer, error) {
+	opts := &server.Options{Port: rpcPort, MaxPayload: int32(maxMessageSize)}
 	ns, err := server.NewServer(opts)
 	if err != nil {
 		return nil, err
@@ -78,17 +85,42 @@ func NewNatsTransportAsServer(rpcPort int) (*natsTransportServer, error) {
 	return con, nil
 }
 
-func (c *natsTransportServer) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
-	sub, err := c.nc.Subscribe(nitroRequestTopic+"/api/"+apiVersion, func(msg *nats.Msg) {
-		responseData := handler(msg.Data)
-		err := c.nc.Publish(msg.Reply, responseData)
+// RegisterRequestHandler handles the requests made on the subject of each request method (see serde.Subject).
+// A request is answered on its reply subject or, if it has none, on the method's response or error subject.
+func (c *natsTransportServer) RegisterRequestHandler(version string, handler func([]byte) []byte) error {
+	if version != apiVersion {
+		return fmt.Errorf("the nats transport only serves version %s of the API, not %s", apiVersion, version)
+	}
+	for _, method := range serde.AllRequestMethods() {
+		method := method
+		sub, err := c.nc.Subscribe(serde.Subject(method), func(msg *nats.Msg) {
+			responseData := handler(msg.Data)
+			reply := msg.Reply
+			if reply == "" {
+				reply = serde.ResponseSubject(method)
+				if isErrorResponse(responseData) {
+					reply = serde.ErrorSubject(method)
+				}
+			}
+			err := c.nc.Publish(reply, responseData)
+			if err != nil {
+				panic(err)
+			}
+		})
 		if err != nil {
-			panic(err)
+			return err
 		}
-	})
-	c.natsSubscriptions = append(c.natsSubscriptions, sub)
+		c.natsSubscriptions = append(c.natsSubscriptions, sub)
+	}
+	return nil
+}
 
-	return err
+// isErrorResponse returns true if the response is a json-rpc error response
+func isErrorResponse(response []byte) bool {
+	var r struct {
+		Error *json.RawMessage `json:"error"`
+	}
+	return json.Unmarshal(response, &r) == nil && r.Error != nil
 }
 
 func (c *natsTransportServer) Notify(data []byte) error {
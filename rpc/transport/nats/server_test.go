@@ -0,0 +1,96 @@
+package nats
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/statechannels/go-nitro/rpc/serde"
+)
+
+func TestOversizedRequestsAreRejected(t *testing.T) {
+	const maxMessageSize = 1024
+
+	// A port of -1 lets the NATS server choose a free port
+	server, err := NewNatsTransportAsServer(-1, maxMessageSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	var handled atomic.Int32
+	err = server.RegisterRequestHandler(apiVersion, func(data []byte) []byte {
+		handled.Add(1)
+		return data
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nc, err := nats.Connect(server.Url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	if _, err := nc.Request(serde.Subject(serde.VersionMethod), make([]byte, maxMessageSize), time.Second); err != nil {
+		t.Fatalf("expected a request within the limit to be handled, got %v", err)
+	}
+
+	_, err = nc.Request(serde.Subject(serde.VersionMethod), make([]byte, 10*maxMessageSize), time.Second)
+	if !errors.Is(err, nats.ErrMaxPayload) {
+		t.Errorf("expected an oversized request to be rejected with %v, got %v", nats.ErrMaxPayload, err)
+	}
+	if got := handled.Load(); got != 1 {
+		t.Errorf("expected only the request within the limit to be handled, got %d requests", got)
+	}
+}
+
+func TestRequestsWithoutAReplyAreAnsweredOnTheMethodsSubjects(t *testing.T) {
+	server, err := NewNatsTransportAsServer(-1, DefaultMaxMessageSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	// The handler fails every request but those for the version
+	err = server.RegisterRequestHandler(apiVersion, func(data []byte) []byte {
+		if string(data) == `{"method":"version"}` {
+			return []byte(`{"result":"v"}`)
+		}
+		return []byte(`{"error":{"code":-32601,"message":"Method not found"}}`)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nc, err := nats.Connect(server.Url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	for _, tc := range []struct {
+		method  serde.RequestMethod
+		subject string
+	}{
+		{serde.VersionMethod, serde.ResponseSubject(serde.VersionMethod)},
+		{serde.GetAddressMethod, serde.ErrorSubject(serde.GetAddressMethod)},
+	} {
+		sub, err := nc.SubscribeSync(tc.subject)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := nc.Publish(serde.Subject(tc.method), []byte(`{"method":"`+string(tc.method)+`"}`)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sub.NextMsg(time.Second); err != nil {
+			t.Errorf("expected the response to a %s request to be published on %s, got %v", tc.method, tc.subject, err)
+		}
+		if err := sub.Unsubscribe(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
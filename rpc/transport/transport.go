@@ -1,10 +1,17 @@
 package transport
 
+import "errors"
+
+// ErrConnectionLost is wrapped by the errors of requests whose connection to the server was lost before they were answered.
+// Such a request may or may not have reached the server.
+var ErrConnectionLost = errors.New("connection to the server lost")
+
 type TransportType string
 
 const (
 	Nats TransportType = "nats"
 	Http TransportType = "http"
+	Ws   TransportType = "ws"
 )
 
 // Requester is a transport that can send requests and subscribe to notifications
@@ -12,13 +19,19 @@ type Requester interface {
 	// Close closes the connection
 	Close() error
 
-	// Request sends a blocking request and returns the response data or an error
+	// Request sends a blocking request and returns the response data or an error.
+	// Transports which hold a connection return an error wrapping ErrConnectionLost if the connection is lost before the response arrives.
 	Request([]byte) ([]byte, error)
 	// Subscribe provides a notification channel.
 	// If subscription to notifications fails, it returns an error.
 	Subscribe() (<-chan []byte, error)
 }
 
+// ConnectionReporter is implemented by transports which hold a connection to the server, and can report whether it is currently established
+type ConnectionReporter interface {
+	Connected() bool
+}
+
 // Responder is a transport that can respond to requests and send notifications
 type Responder interface {
 	// Close closes the connection
@@ -0,0 +1,230 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	urlUtil "net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/statechannels/go-nitro/internal/safesync"
+	"github.com/statechannels/go-nitro/rpc/transport"
+)
+
+// ErrConnectionClosed is returned for requests which are made, or are awaiting a response, when the connection to the server closes
+var ErrConnectionClosed = fmt.Errorf("websocket connection closed: %w", transport.ErrConnectionLost)
+
+type clientWsTransport struct {
+	logger           *slog.Logger
+	conn             *websocket.Conn
+	writeMu          sync.Mutex
+	notificationChan chan []byte
+	pending          safesync.Map[chan []byte]
+	done             chan struct{}
+	connected        atomic.Bool
+	wg               *sync.WaitGroup
+
+	// Notifications are queued by the read loop and forwarded to notificationChan by forwardNotifications,
+	// so that a subscriber which falls behind does not hold up the responses read after them
+	queueMu  sync.Mutex
+	queue    [][]byte
+	queued   chan struct{} // signalled when a notification is queued
+	stopping chan struct{} // closed by Close to stop forwarding notifications
+}
+
+// NewWsTransportAsClient creates a transport which sends requests, and receives their responses and notifications, over a websocket connection.
+// Initialization will block for 10 retries until the server endpoint is ready
+func NewWsTransportAsClient(url string, retryTimeout time.Duration) (*clientWsTransport, error) {
+	err := blockUntilServerIsReady(url, retryTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	wsUrl, err := urlUtil.JoinPath("wss://", url)
+	if err != nil {
+		return nil, err
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(wsUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &clientWsTransport{
+		logger:           slog.Default(),
+		conn:             conn,
+		notificationChan: make(chan []byte, 10),
+		done:             make(chan struct{}),
+		wg:               &sync.WaitGroup{},
+		queued:           make(chan struct{}, 1),
+		stopping:         make(chan struct{}),
+	}
+	t.connected.Store(true)
+
+	t.wg.Add(2)
+	go t.readMessages()
+	go t.forwardNotifications()
+
+	return t, nil
+}
+
+// Request sends the request and blocks until the server responds with the same json-rpc id
+func (t *clientWsTransport) Request(data []byte) ([]byte, error) {
+	var request struct {
+		Id uint64 `json:"id"`
+	}
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("could not read the id of the request: %w", err)
+	}
+	key := strconv.FormatUint(request.Id, 10)
+	responseChan := make(chan []byte, 1)
+	if _, loaded := t.pending.LoadOrStore(key, responseChan); loaded {
+		return nil, fmt.Errorf("a request with id %d is already awaiting a response", request.Id)
+	}
+	defer t.pending.Delete(key)
+
+	t.writeMu.Lock()
+	err := t.conn.WriteMessage(websocket.TextMessage, data)
+	t.writeMu.Unlock()
+	if err != nil {
+		if !t.Connected() {
+			return nil, fmt.Errorf("%w: %w", ErrConnectionClosed, err)
+		}
+		return nil, err
+	}
+
+	select {
+	case response := <-responseChan:
+		return response, nil
+	case <-t.done:
+		return nil, ErrConnectionClosed
+	}
+}
+
+func (t *clientWsTransport) Subscribe() (<-chan []byte, error) {
+	return t.notificationChan, nil
+}
+
+// Connected returns true until the connection to the server closes
+func (t *clientWsTransport) Connected() bool {
+	return t.connected.Load()
+}
+
+func (t *clientWsTransport) Close() error {
+	// This will also cause the go-routine to unblock waiting on `ReadMessage` and thus serves as a signal to exit
+	err := t.conn.Close()
+	if err != nil {
+		return err
+	}
+	close(t.stopping)
+	t.wg.Wait()
+
+	close(t.notificationChan)
+	return nil
+}
+
+// readMessages routes each message from the server: json-rpc notifications, which have a method, are queued for the notification channel
+// and responses are returned to the request with the same id.
+func (t *clientWsTransport) readMessages() {
+	defer t.wg.Done()
+	defer close(t.done)
+	defer t.connected.Store(false)
+
+	t.logger.Debug("Starting to read websocket messages")
+	for {
+		_, data, err := t.conn.ReadMessage()
+		if err != nil {
+			t.logger.Info("Websocket read error", "error", err)
+			return
+		}
+		t.logger.Debug("Websocket received message", "data", string(data))
+
+		var message struct {
+			Id     uint64 `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.logger.Warn("Websocket received a message which is not json-rpc", "error", err)
+			continue
+		}
+		if message.Method != "" {
+			t.enqueueNotification(data)
+			continue
+		}
+		responseChan, ok := t.pending.Load(strconv.FormatUint(message.Id, 10))
+		if !ok {
+			t.logger.Warn("Websocket received a response to an unknown request", "id", message.Id)
+			continue
+		}
+		responseChan <- data
+	}
+}
+
+// enqueueNotification queues the notification for forwardNotifications without blocking
+func (t *clientWsTransport) enqueueNotification(data []byte) {
+	t.queueMu.Lock()
+	t.queue = append(t.queue, data)
+	t.queueMu.Unlock()
+	select {
+	case t.queued <- struct{}{}:
+	default: // forwardNotifications has yet to see an earlier signal, and will find this notification too
+	}
+}
+
+// forwardNotifications sends the queued notifications on the notification channel, in the order they were received, until the transport is closed
+func (t *clientWsTransport) forwardNotifications() {
+	defer t.wg.Done()
+	for {
+		t.queueMu.Lock()
+		if len(t.queue) == 0 {
+			t.queueMu.Unlock()
+			select {
+			case <-t.queued:
+				continue
+			case <-t.stopping:
+				return
+			}
+		}
+		next := t.queue[0]
+		t.queue = t.queue[1:]
+		t.queueMu.Unlock()
+
+		select {
+		case t.notificationChan <- next:
+		case <-t.stopping:
+			return
+		}
+	}
+}
+
+// blockUntilServerIsReady pings the health endpoint until the server is ready
+func blockUntilServerIsReady(url string, retryTimeout time.Duration) error {
+	waitForServer := func(iteration int) {
+		time.Sleep(retryTimeout * time.Duration(math.Pow(2, float64(iteration))))
+	}
+
+	healthUrl, err := urlUtil.JoinPath("https://", url, "health")
+	if err != nil {
+		return err
+	}
+	numAttempts := 10
+	for i := 0; i < numAttempts; i++ {
+		resp, err := http.Get(healthUrl)
+		if err != nil {
+			waitForServer(i)
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		waitForServer(i)
+	}
+	return fmt.Errorf("websocket server %v not ready after %d attempts", healthUrl, numAttempts)
+}
@@ -0,0 +1,95 @@
+package ws
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// freePort returns a port which was free when it was checked
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+func TestResponsesAreNotHeldUpByUnreadNotifications(t *testing.T) {
+	cert, err := tls.LoadX509KeyPair("../../../tls/statechannels.org.pem", "../../../tls/statechannels.org_key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewWsTransportAsServer(freePort(t), &cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	err = server.RegisterRequestHandler("v1", func(data []byte) []byte {
+		var request struct {
+			Id uint64 `json:"id"`
+		}
+		if err := json.Unmarshal(data, &request); err != nil {
+			panic(err)
+		}
+		return []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"ok"}`, request.Id))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewWsTransportAsClient(server.Url(), 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	notifications, err := client.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first response shows that the server has accepted the connection, so that it is sent the notifications
+	if _, err := client.Request([]byte(`{"jsonrpc":"2.0","id":1,"method":"version"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Far more notifications are sent than the notification channel buffers, and none are read until the response arrives
+	const sent = 100
+	for i := 0; i < sent; i++ {
+		if err := server.Notify([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","method":"objective_completed","params":%d}`, i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	responded := make(chan error, 1)
+	go func() {
+		_, err := client.Request([]byte(`{"jsonrpc":"2.0","id":2,"method":"version"}`))
+		responded <- err
+	}()
+	select {
+	case err := <-responded:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the response to arrive while the notifications are unread")
+	}
+
+	// The notifications are still delivered, in order
+	for i := 0; i < sent; i++ {
+		var notification struct {
+			Params int `json:"params"`
+		}
+		if err := json.Unmarshal(<-notifications, &notification); err != nil {
+			t.Fatal(err)
+		}
+		if notification.Params != i {
+			t.Fatalf("expected notification %d, got %d", i, notification.Params)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package ws implements a transport which carries json-rpc requests, responses and notifications over a single websocket connection.
+package ws
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/statechannels/go-nitro/internal/safesync"
+	"github.com/statechannels/go-nitro/rand"
+)
+
+const (
+	wsServerAddress = "127.0.0.1:"
+	maxRequestSize  = 8192
+	apiVersionPath  = "/api/v1"
+)
+
+// connection is a websocket connection to a client. Requests from the client are handled concurrently, so writes are serialized by writeMu.
+type connection struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+func (c *connection) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+type serverWsTransport struct {
+	httpServer      *http.Server
+	requestHandlers safesync.Map[func([]byte) []byte]
+	port            string
+	connections     safesync.Map[*connection]
+	logger          *slog.Logger
+
+	// closeMu orders the connections accepted by serveConnection with Close, so that each is either closed by Close or refused
+	closeMu sync.Mutex
+	closed  bool
+	wg      *sync.WaitGroup
+}
+
+// NewWsTransportAsServer starts a server which accepts websocket connections on the api version path.
+// Each connection carries json-rpc requests from the client, and the responses and notifications sent to it.
+func NewWsTransportAsServer(port string, cert *tls.Certificate) (*serverWsTransport, error) {
+	transport := &serverWsTransport{port: port, logger: slog.Default(), wg: &sync.WaitGroup{}}
+
+	var serveMux http.ServeMux
+
+	// Used to check if the server is ready
+	serveMux.HandleFunc(path.Join(apiVersionPath, "health"), func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("OK"))
+		if err != nil {
+			panic(err)
+		}
+	})
+	serveMux.HandleFunc(apiVersionPath, transport.serveConnection)
+	transport.httpServer = &http.Server{
+		Addr:              ":" + port,
+		Handler:           &serveMux,
+		ReadHeaderTimeout: time.Second * 10,
+	}
+
+	var listener net.Listener
+	var err error
+
+	if cert == nil {
+		listener, err = net.Listen("tcp", ":"+port)
+	} else {
+		listener, err = tls.Listen("tcp", ":"+port, &tls.Config{Certificates: []tls.Certificate{*cert}})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transport.wg.Add(1)
+	go transport.serve(listener)
+	return transport, nil
+}
+
+func (t *serverWsTransport) serve(listener net.Listener) {
+	defer t.wg.Done()
+
+	err := t.httpServer.Serve(listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		panic(err)
+	}
+}
+
+func (t *serverWsTransport) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
+	t.requestHandlers.Store(apiVersion, handler)
+	return nil
+}
+
+// Notify sends the notification to every connected client
+func (t *serverWsTransport) Notify(data []byte) error {
+	t.connections.Range(func(key string, c *connection) bool {
+		if err := c.write(data); err != nil {
+			t.logger.Warn("Websocket transport could not send a notification", "error", err)
+		}
+		return true
+	})
+	return nil
+}
+
+func (t *serverWsTransport) Close() error {
+	// Shutdown does not close hijacked connections, so they are closed here to end their read loops
+	err := t.httpServer.Shutdown(context.Background())
+	if err != nil {
+		return err
+	}
+	t.closeMu.Lock()
+	t.closed = true
+	t.connections.Range(func(key string, c *connection) bool {
+		c.conn.Close()
+		return true
+	})
+	t.closeMu.Unlock()
+
+	t.wg.Wait()
+	return nil
+}
+
+func (t *serverWsTransport) Url() string {
+	return wsServerAddress + t.port + apiVersionPath
+}
+
+var upgrader = websocket.Upgrader{
+	// TODO: We currently allow connections from any origins. We should probably use a whitelist.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// serveConnection upgrades the request to a websocket connection, and handles each request received on it until the connection is closed
+func (t *serverWsTransport) serveConnection(w http.ResponseWriter, r *http.Request) {
+	// Pull api version from the url and determine if the version is supported
+	pathSegments := strings.Split(r.URL.Path, "/")
+	if len(pathSegments) < 3 {
+		http.Error(w, "Invalid API version", http.StatusBadRequest)
+		return
+	}
+	handler, ok := t.requestHandlers.Load(pathSegments[2]) // first segment is an empty string
+	if !ok {
+		http.Error(w, "Invalid API version", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied to the client with an error
+		t.logger.Warn("Websocket transport could not upgrade a connection", "error", err)
+		return
+	}
+	conn.SetReadLimit(maxRequestSize)
+
+	c := &connection{conn: conn}
+	key := strconv.Itoa(int(rand.Uint64()))
+	t.closeMu.Lock()
+	if t.closed {
+		t.closeMu.Unlock()
+		conn.Close()
+		return
+	}
+	t.wg.Add(1)
+	t.connections.Store(key, c)
+	t.closeMu.Unlock()
+	defer t.wg.Done()
+	t.logger.Debug("Websocket transport accepted a connection")
+	defer func() {
+		t.connections.Delete(key)
+		conn.Close()
+	}()
+
+	requests := &sync.WaitGroup{}
+	defer requests.Wait()
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			t.logger.Debug("Websocket transport closed a connection", "error", err)
+			return
+		}
+		// Requests such as creating a channel block until the objective has started, so they are handled concurrently
+		requests.Add(1)
+		go func() {
+			defer requests.Done()
+			if err := c.write(handler(msg)); err != nil {
+				t.logger.Warn("Websocket transport could not send a response", "error", err)
+			}
+		}()
+	}
+}
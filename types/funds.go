@@ -1,6 +1,22 @@
 package types
 
-import "math/big"
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrFundsUnderflow = errors.New("funds would become negative")
+
+// IsZero returns true if every asset in the Funds has a zero amount. Empty Funds are zero.
+func (f Funds) IsZero() bool {
+	for _, v := range f {
+		if v.Sign() != 0 {
+			return false
+		}
+	}
+	return true
+}
 
 // IsNonZero returns true if the Holdings structure has any non-zero asset
 func (h Funds) IsNonZero() bool {
@@ -35,6 +51,27 @@ func (h Funds) Add(a ...Funds) Funds {
 	return Sum(a...)
 }
 
+// Sub returns a new Funds object with all of the asset keys from the receiver and g,
+// each having the receiver's amount of that asset less g's amount. An asset missing from either is treated as a zero amount.
+// It returns an error if g has more of any asset than the receiver.
+//
+// e.g. {[0x0a,0x03][0x0b,0x01]} - {[0x0a,0x02]} = {[0x0a,0x01][0x0b,0x01]}
+func (f Funds) Sub(g Funds) (Funds, error) {
+	difference := f.Clone()
+
+	for asset, amount := range g {
+		if difference[asset] == nil {
+			difference[asset] = big.NewInt(0)
+		}
+		difference[asset].Sub(difference[asset], amount)
+		if difference[asset].Sign() < 0 {
+			return nil, fmt.Errorf("%w: %s less %s of asset %s", ErrFundsUnderflow, f[asset], amount, asset)
+		}
+	}
+
+	return difference, nil
+}
+
 // Sum returns a new Funds object with all of the asset keys from the supplied Funds objects,
 // each having an amount summed across that asset's amount in each input object.
 //
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 
@@ -213,3 +214,61 @@ func TestFundsClone(t *testing.T) {
 		t.Fatalf("Clone: mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestSub(t *testing.T) {
+	type testCase struct {
+		a, b Funds
+		want Funds
+	}
+	testCases := []testCase{
+		{testData["ab"], testData["a"], testData["b"]},
+		{testData["abcd"], testData["ac"], Funds{
+			common.HexToAddress("0x00"): big.NewInt(1),
+			common.HexToAddress("0x01"): big.NewInt(0),
+			common.HexToAddress("0x02"): big.NewInt(1),
+		}},
+		{testData["a"], testData["zeros"], testData["a"]},
+		{testData["blank"], testData["blank"], testData["blank"]},
+	}
+
+	for _, tc := range testCases {
+		got, err := tc.a.Sub(tc.b)
+		if err != nil {
+			t.Fatalf("%s - %s: unexpected error %v", tc.a, tc.b, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Fatalf("%s - %s: expected %s, got %s", tc.a, tc.b, tc.want, got)
+		}
+	}
+
+	// The receiver is not modified
+	a := testData["ab"].Clone()
+	_, _ = a.Sub(testData["a"])
+	if !a.Equal(testData["ab"]) {
+		t.Fatalf("expected the receiver to be unchanged, got %s", a)
+	}
+
+	underflows := []fundsPair{
+		{testData["a"], testData["ab"]},
+		{testData["a"], testData["c"]},
+		{testData["blank"], testData["a"]},
+	}
+	for _, p := range underflows {
+		if _, err := p.a.Sub(p.b); !errors.Is(err, ErrFundsUnderflow) {
+			t.Fatalf("%s - %s: expected error %v, got %v", p.a, p.b, ErrFundsUnderflow, err)
+		}
+	}
+}
+
+func TestIsZero(t *testing.T) {
+	for _, name := range []string{"blank", "zeros"} {
+		if !testData[name].IsZero() {
+			t.Fatalf("expected %s to be zero", testData[name])
+		}
+	}
+	for _, name := range []string{"a", "e", "abcd"} {
+		if testData[name].IsZero() {
+			t.Fatalf("expected %s not to be zero", testData[name])
+		}
+	}
+}